@@ -28,6 +28,7 @@ import (
 	dbpkg "github.com/memodb-io/Acontext/internal/infra/db"
 	"github.com/memodb-io/Acontext/internal/modules/handler"
 	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/memodb-io/Acontext/internal/pkg/tokenizer"
 	"github.com/memodb-io/Acontext/internal/router"
 	"github.com/memodb-io/Acontext/internal/telemetry"
@@ -93,32 +94,44 @@ func main() {
 	sandboxHandler := do.MustInvoke[*handler.SandboxHandler](inj)
 	learningSpaceHandler := do.MustInvoke[*handler.LearningSpaceHandler](inj)
 	sessionEventHandler := do.MustInvoke[*handler.SessionEventHandler](inj)
+	sessionBulkHandler := do.MustInvoke[*handler.SessionBulkHandler](inj)
+	messageFeedbackHandler := do.MustInvoke[*handler.MessageFeedbackHandler](inj)
 	projectHandler := do.MustInvoke[*handler.ProjectHandler](inj)
 	materialHandler := do.MustInvoke[*handler.MaterialHandler](inj)
+	blobHandler := do.MustInvoke[*handler.BlobHandler](inj)
+	uploadHandler := do.MustInvoke[*handler.UploadHandler](inj)
 
 	// build admin-specific handlers
 	adminHandler := do.MustInvoke[*handler.AdminHandler](inj)
 	metricsHandler := do.MustInvoke[*handler.MetricsHandler](inj)
+	assetGCHandler := do.MustInvoke[*handler.AssetGCHandler](inj)
+	assetRefCheckHandler := do.MustInvoke[*handler.AssetRefCheckHandler](inj)
 
 	engine := router.NewAdminRouter(router.AdminRouterDeps{
 		RouterDeps: router.RouterDeps{
-			Config:               cfg,
-			DB:                   db,
-			Log:                  log,
-			SessionHandler:       sessionHandler,
-			DiskHandler:          diskHandler,
-			ArtifactHandler:      artifactHandler,
-			TaskHandler:          taskHandler,
-			AgentSkillsHandler:   agentSkillsHandler,
-			UserHandler:          userHandler,
-			SandboxHandler:       sandboxHandler,
-			LearningSpaceHandler: learningSpaceHandler,
-			SessionEventHandler:  sessionEventHandler,
-			ProjectHandler:       projectHandler,
-			MaterialHandler:      materialHandler,
+			Config:                 cfg,
+			DB:                     db,
+			Log:                    log,
+			SessionHandler:         sessionHandler,
+			DiskHandler:            diskHandler,
+			ArtifactHandler:        artifactHandler,
+			TaskHandler:            taskHandler,
+			AgentSkillsHandler:     agentSkillsHandler,
+			UserHandler:            userHandler,
+			SandboxHandler:         sandboxHandler,
+			LearningSpaceHandler:   learningSpaceHandler,
+			SessionEventHandler:    sessionEventHandler,
+			SessionBulkHandler:     sessionBulkHandler,
+			MessageFeedbackHandler: messageFeedbackHandler,
+			ProjectHandler:         projectHandler,
+			MaterialHandler:        materialHandler,
+			BlobHandler:            blobHandler,
+			UploadHandler:          uploadHandler,
 		},
-		AdminHandler:   adminHandler,
-		MetricsHandler: metricsHandler,
+		AdminHandler:         adminHandler,
+		MetricsHandler:       metricsHandler,
+		AssetGCHandler:       assetGCHandler,
+		AssetRefCheckHandler: assetRefCheckHandler,
 	})
 
 	addr := fmt.Sprintf("%s:%d", cfg.App.Host, cfg.App.Port)
@@ -134,6 +147,10 @@ func main() {
 	assetRefBuffer := do.MustInvoke[repo.AssetRefBuffer](inj)
 	assetRefBuffer.Start()
 
+	// Start retrying message asset uploads that have not been stored yet.
+	assetOutbox := do.MustInvoke[service.AssetOutboxService](inj)
+	assetOutbox.Start()
+
 	go func() {
 		log.Sugar().Infow("starting admin http server", "addr", addr)
 		log.Sugar().Infow("swagger url", "url", addr+"/swagger/index.html")
@@ -155,5 +172,6 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Sugar().Errorw("server shutdown", "err", err)
 	}
+	assetOutbox.Stop()
 	log.Sugar().Info("admin server exited")
 }
@@ -28,6 +28,7 @@ import (
 	dbpkg "github.com/memodb-io/Acontext/internal/infra/db"
 	"github.com/memodb-io/Acontext/internal/modules/handler"
 	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/memodb-io/Acontext/internal/pkg/tokenizer"
 	"github.com/memodb-io/Acontext/internal/router"
 	"github.com/memodb-io/Acontext/internal/telemetry"
@@ -93,24 +94,32 @@ func main() {
 	sandboxHandler := do.MustInvoke[*handler.SandboxHandler](inj)
 	learningSpaceHandler := do.MustInvoke[*handler.LearningSpaceHandler](inj)
 	sessionEventHandler := do.MustInvoke[*handler.SessionEventHandler](inj)
+	sessionBulkHandler := do.MustInvoke[*handler.SessionBulkHandler](inj)
+	messageFeedbackHandler := do.MustInvoke[*handler.MessageFeedbackHandler](inj)
 	projectHandler := do.MustInvoke[*handler.ProjectHandler](inj)
 	materialHandler := do.MustInvoke[*handler.MaterialHandler](inj)
+	blobHandler := do.MustInvoke[*handler.BlobHandler](inj)
+	uploadHandler := do.MustInvoke[*handler.UploadHandler](inj)
 	engine := router.NewRouter(router.RouterDeps{
-		Config:               cfg,
-		DB:                   db,
-		Redis:                rdb,
-		Log:                  log,
-		SessionHandler:       sessionHandler,
-		DiskHandler:          diskHandler,
-		ArtifactHandler:      artifactHandler,
-		TaskHandler:          taskHandler,
-		AgentSkillsHandler:   agentSkillsHandler,
-		UserHandler:          userHandler,
-		SandboxHandler:       sandboxHandler,
-		LearningSpaceHandler: learningSpaceHandler,
-		SessionEventHandler:  sessionEventHandler,
-		ProjectHandler:       projectHandler,
-		MaterialHandler:      materialHandler,
+		Config:                 cfg,
+		DB:                     db,
+		Redis:                  rdb,
+		Log:                    log,
+		SessionHandler:         sessionHandler,
+		DiskHandler:            diskHandler,
+		ArtifactHandler:        artifactHandler,
+		TaskHandler:            taskHandler,
+		AgentSkillsHandler:     agentSkillsHandler,
+		UserHandler:            userHandler,
+		SandboxHandler:         sandboxHandler,
+		LearningSpaceHandler:   learningSpaceHandler,
+		SessionEventHandler:    sessionEventHandler,
+		SessionBulkHandler:     sessionBulkHandler,
+		MessageFeedbackHandler: messageFeedbackHandler,
+		ProjectHandler:         projectHandler,
+		MaterialHandler:        materialHandler,
+		BlobHandler:            blobHandler,
+		UploadHandler:          uploadHandler,
 	})
 
 	addr := fmt.Sprintf("%s:%d", cfg.App.Host, cfg.App.Port)
@@ -126,6 +135,18 @@ func main() {
 	assetRefBuffer := do.MustInvoke[repo.AssetRefBuffer](inj)
 	assetRefBuffer.Start()
 
+	// Start the background orphaned asset sweeps, if enabled.
+	assetGC := do.MustInvoke[service.AssetGCService](inj)
+	assetGC.Start()
+
+	// Start discarding expired resumable uploads.
+	uploadSweep := do.MustInvoke[service.UploadService](inj)
+	uploadSweep.Start()
+
+	// Start retrying message asset uploads that have not been stored yet.
+	assetOutbox := do.MustInvoke[service.AssetOutboxService](inj)
+	assetOutbox.Start()
+
 	go func() {
 		log.Sugar().Infow("starting http server", "addr", addr)
 		log.Sugar().Infow("swagger url", "url", addr+"/swagger/index.html")
@@ -142,12 +163,15 @@ func main() {
 
 	// Stop the asset reference buffer first (final flush to DB).
 	assetRefBuffer.Stop()
+	assetGC.Stop()
+	uploadSweep.Stop()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Sugar().Errorw("server shutdown", "err", err)
 	}
+	assetOutbox.Stop()
 
 	log.Sugar().Info("server exited")
 }
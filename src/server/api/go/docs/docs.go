@@ -15,6 +15,70 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/v1/asset_gc": {
+            "post": {
+                "description": "Delete assets whose reference count is zero and that have not been referenced for longer than the grace period (assetGC.gracePeriodSeconds unless given), removing their stored objects in batches. Scope the sweep to one project with project_id, and set dry_run to only report what would be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Sweep orphaned assets",
+                "parameters": [
+                    {
+                        "description": "SweepAssets payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SweepAssetsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AssetGCOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Another sweep is running",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/v1/project": {
             "post": {
                 "description": "Create a new project with a randomly generated secret key",
@@ -72,6 +136,75 @@ const docTemplate = `{
                 }
             }
         },
+        "/admin/v1/project/asset_refs/check": {
+            "post": {
+                "description": "Recompute the project's expected asset reference counts from its messages (parts JSON and the assets of their parts), artifacts, artifact versions and disk snapshots, and compare them with the recorded counts and the stored objects. Reports wrong counts, objects missing for referenced assets, and stored objects nothing references that are older than the grace period (assetGC.gracePeriodSeconds unless given) and not held by an upload that can still be attached or committed. With repair, wrong counts are set to the expected ones and leaked objects deleted; when some parts JSON cannot be read, counts are only raised and nothing is deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Check asset references",
+                "parameters": [
+                    {
+                        "description": "CheckAssetRefs payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CheckAssetRefsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AssetRefCheckOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "An asset sweep is running",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
         "/admin/v1/project/{project_id}": {
             "delete": {
                 "description": "Delete a project by ID",
@@ -285,11 +418,6 @@ const docTemplate = `{
         },
         "/agent_skills": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "List all agent skills under a project",
                 "consumes": [
                     "application/json"
@@ -348,6 +476,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -362,11 +495,6 @@ const docTemplate = `{
                 ]
             },
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Upload a zip file containing agent skill. The zip file must contain a SKILL.md file (case-insensitive) with YAML format containing 'name' and 'description' fields. The name and description will be extracted from SKILL.md. Files are stored as Disk Artifacts. Optionally associate with a user identifier.",
                 "consumes": [
                     "multipart/form-data"
@@ -420,6 +548,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -436,11 +569,6 @@ const docTemplate = `{
         },
         "/agent_skills/{id}": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Get agent skill by its UUID",
                 "consumes": [
                     "application/json"
@@ -481,6 +609,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -495,11 +628,6 @@ const docTemplate = `{
                 ]
             },
             "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Delete agent skill and all associated files",
                 "consumes": [
                     "application/json"
@@ -528,6 +656,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -544,11 +677,6 @@ const docTemplate = `{
         },
         "/agent_skills/{id}/download_to_sandbox": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Download all files from an agent skill to a sandbox environment. Files are placed at /skills/{skill_name}/.",
                 "consumes": [
                     "application/json"
@@ -604,6 +732,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -620,11 +753,6 @@ const docTemplate = `{
         },
         "/agent_skills/{id}/download_zip": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Download all files from an agent skill as a ZIP archive. Files are streamed directly with their relative paths preserved.",
                 "consumes": [
                     "application/json"
@@ -653,6 +781,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -669,11 +802,6 @@ const docTemplate = `{
         },
         "/agent_skills/{id}/file": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Get file content or download URL from agent skill. If the file is text-based (parseable), returns parsed content. Otherwise, returns a presigned download URL.",
                 "consumes": [
                     "application/json"
@@ -727,6 +855,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -741,58 +874,158 @@ const docTemplate = `{
                 ]
             }
         },
-        "/disk": {
+        "/blob/{key}": {
             "get": {
-                "security": [
+                "description": "Serve an object of the local blob store. The URL comes from a presigned download and carries its own HMAC signature, so no authentication header is required. Range requests are supported.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "blob"
+                ],
+                "summary": "Download a blob through a signed URL",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Object key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry as a Unix timestamp",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
                     }
                 ],
-                "description": "List all disks under a project",
+                "responses": {
+                    "200": {
+                        "description": "Object content"
+                    },
+                    "403": {
+                        "description": "Signature is invalid or expired",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Object not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store the request body as an object of the local blob store, replacing any existing one. The URL comes from a presigned upload and carries its own HMAC signature; the request's Content-Type must match the one it was signed for.",
                 "consumes": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "disk"
+                    "blob"
                 ],
-                "summary": "List disks",
+                "summary": "Upload a blob through a signed URL",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "alice@acontext.io",
-                        "description": "User identifier to filter disks",
-                        "name": "user",
-                        "in": "query"
+                        "description": "Object key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Limit of disks to return, default 20. Max 200.",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Expiry as a Unix timestamp",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
-                        "name": "cursor",
+                        "description": "Content type the URL was signed for",
+                        "name": "content_type",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
-                        "name": "time_desc",
-                        "in": "query"
+                        "type": "string",
+                        "description": "URL signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Object stored; its ETag is returned in the ETag header"
+                    },
+                    "403": {
+                        "description": "Signature is invalid or expired, or Content-Type does not match",
                         "schema": {
-                            "allOf": [
-                                {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/disk": {
+            "get": {
+                "description": "List all disks under a project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "disk"
+                ],
+                "summary": "List disks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "alice@acontext.io",
+                        "description": "User identifier to filter disks",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of disks to return, default 20. Max 200.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
                                     "$ref": "#/definitions/serializer.Response"
                                 },
                                 {
@@ -807,6 +1040,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -821,11 +1059,6 @@ const docTemplate = `{
                 ]
             },
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Create a disk group under a project. Optionally associate with a user identifier.",
                 "consumes": [
                     "application/json"
@@ -868,6 +1101,11 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -882,14 +1120,60 @@ const docTemplate = `{
                 ]
             }
         },
-        "/disk/{disk_id}": {
-            "delete": {
+        "/disk/clone": {
+            "post": {
+                "description": "Create a new disk holding the artifacts captured by a snapshot. Assets are shared with the snapshot, so no content is copied.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "disk"
+                ],
+                "summary": "Clone disk from snapshot",
+                "parameters": [
+                    {
+                        "description": "CloneDisk payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CloneDiskReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Disk"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Delete a disk by its UUID",
+                ]
+            }
+        },
+        "/disk/{disk_id}": {
+            "delete": {
+                "description": "Delete a disk by its UUID. Rejected with 423 while another owner holds a lease on any of its artifacts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -909,6 +1193,12 @@ const docTemplate = `{
                         "name": "disk_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the disk has leases",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -917,8 +1207,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
+                    },
+                    "423": {
+                        "description": "Disk is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -935,12 +1236,7 @@ const docTemplate = `{
         },
         "/disk/{disk_id}/artifact": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get artifact information by path and filename. Optionally include a presigned URL for downloading and parsed file content.",
+                "description": "Get artifact information by path and filename. Optionally include a presigned URL for downloading and parsed file content. For PDF, DOCX, XLSX, PPTX and HTML files the content is their extracted text, with each XLSX sheet as CSV. Pass version to read a previous version retained in the artifact's history.",
                 "consumes": [
                     "application/json"
                 ],
@@ -989,6 +1285,13 @@ const docTemplate = `{
                         "description": "Expire time in seconds for presigned URL (default: 3600)",
                         "name": "expire",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "description": "Version to read (default: the current version)",
+                        "name": "version",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1008,9 +1311,20 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the artifact content"
+                            }
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -1025,11 +1339,6 @@ const docTemplate = `{
                 ]
             },
             "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Update an artifact's metadata (user-defined metadata only)",
                 "consumes": [
                     "application/json"
@@ -1059,6 +1368,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handler.UpdateArtifactReq"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update if the current artifact's ETag (or SHA256) is listed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1078,9 +1399,32 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the artifact content"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Artifact does not match If-Match or If-None-Match",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -1095,12 +1439,7 @@ const docTemplate = `{
                 ]
             },
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Upload a file and create or update an artifact record under a disk. File size must not exceed the configured maximum upload size limit (default: 16MB).",
+                "description": "Upload a file and create or update an artifact record under a disk. File size must not exceed the configured maximum upload size limit (default: 1GB). Instead of sending the file, upload_id may reference a completed resumable upload with target \"disk\" (see POST /upload), which is how large files are sent over unreliable connections.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -1129,16 +1468,39 @@ const docTemplate = `{
                     },
                     {
                         "type": "file",
-                        "description": "File to upload (size must not exceed configured limit)",
+                        "description": "File to upload (size must not exceed configured limit); required unless upload_id is given",
                         "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a completed resumable upload to use as the file",
+                        "name": "upload_id",
+                        "in": "formData"
                     },
                     {
                         "type": "string",
                         "description": "Custom metadata as JSON string (optional, system metadata will be stored under '__artifact_info__' key)",
                         "name": "meta",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only write if the current artifact's ETag (or SHA256) is listed, or exists for '*'",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only write if the current artifact's ETag is not listed; '*' creates the artifact only if it does not exist",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1158,6 +1520,18 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the written content"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Artifact does not match If-Match or If-None-Match",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
                         }
                     },
                     "413": {
@@ -1165,8 +1539,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -1181,12 +1566,7 @@ const docTemplate = `{
                 ]
             },
             "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete an artifact by path and filename",
+                "description": "Delete an artifact by path and filename. With recursive=true and a file_path ending with '/', delete the directory and every artifact below it; the response then reports how many artifacts were removed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1210,20 +1590,68 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "example": "/documents/report.pdf",
-                        "description": "File path including filename",
+                        "description": "File path including filename, or a directory path ending with '/'",
                         "name": "file_path",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Delete a directory and everything below it",
+                        "name": "recursive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete if the current artifact's ETag (or SHA256) is listed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.DeleteArtifactResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "Artifact does not match If-Match or If-None-Match",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
@@ -1238,53 +1666,123 @@ const docTemplate = `{
                 ]
             }
         },
-        "/disk/{disk_id}/artifact/download": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/disk/{disk_id}/artifact/commit": {
+            "post": {
+                "description": "Create or update an artifact from a file uploaded through POST /disk/{disk_id}/artifact/upload_url. The uploaded file's size and SHA256 must match the ones given; a file that does not match is discarded and has to be uploaded again. Its MIME type is detected and text is extracted for grep as for any other upload.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Download raw artifact file content. Decrypts content if encryption is enabled.",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
                     "artifact"
                 ],
-                "summary": "Download artifact content",
+                "summary": "Commit uploaded artifact",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
                         "description": "Disk ID",
                         "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "File path including filename",
-                        "name": "file_path",
-                        "in": "query",
-                        "required": true
+                        "description": "Commit artifact request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CommitArtifactReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only write if the current artifact's ETag (or SHA256) is listed, or exists for '*'",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only write if the current artifact's ETag is not listed; '*' creates the artifact only if it does not exist",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "File content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Artifact"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the written content"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or the uploaded file does not match size or sha256",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Disk not found, or nothing was uploaded to upload_key",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Artifact does not match If-Match or If-None-Match",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "File size exceeds maximum allowed size",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
-                }
-            }
-        },
-        "/disk/{disk_id}/artifact/download_to_sandbox": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Download an artifact from disk storage to a sandbox environment",
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/copy": {
+            "post": {
+                "description": "Copy a single artifact, or every artifact under a directory when from ends with '/', optionally to another disk of the same project. Copies share the stored content instead of re-uploading it. With on_conflict=overwrite an existing destination keeps its history and gets the copied content as a new version.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1294,7 +1792,7 @@ const docTemplate = `{
                 "tags": [
                     "artifact"
                 ],
-                "summary": "Download artifact to sandbox",
+                "summary": "Copy artifacts",
                 "parameters": [
                     {
                         "type": "string",
@@ -1306,13 +1804,19 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Download to sandbox request",
+                        "description": "Copy request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.DownloadToSandboxReq"
+                            "$ref": "#/definitions/handler.TransferArtifactsReq"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1327,46 +1831,61 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.DownloadToSandboxResp"
+                                            "$ref": "#/definitions/service.TransferArtifactsOutput"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "404": {
+                        "description": "Disk or source artifact not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Destination exists and on_conflict is fail",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Download artifact to sandbox\nresult = client.disks.artifacts.download_to_sandbox(\n    disk_id='disk-uuid',\n    file_path='/documents/',\n    filename='report.pdf',\n    sandbox_id='sandbox-uuid',\n    sandbox_path='/home/user/'\n)\nprint(f\"Success: {result.success}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Copy a file to another disk\nresult = client.disks.artifacts.copy(\n    disk_id='disk-uuid',\n    from_path='/templates/report.md',\n    to_path='/reports/2024.md',\n    dest_disk_id='other-disk-uuid',\n    on_conflict='skip'\n)\nprint(f\"Copied {len(result.artifacts)}, skipped {len(result.skipped)}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Download artifact to sandbox\nconst result = await client.disks.artifacts.downloadToSandbox('disk-uuid', {\n  filePath: '/documents/',\n  filename: 'report.pdf',\n  sandboxId: 'sandbox-uuid',\n  sandboxPath: '/home/user/'\n});\nconsole.log(` + "`" + `Success: ${result.success}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Copy a file to another disk\nconst result = await client.disks.artifacts.copy('disk-uuid', {\n  from: '/templates/report.md',\n  to: '/reports/2024.md',\n  destDiskId: 'other-disk-uuid',\n  onConflict: 'skip'\n});\nconsole.log(` + "`" + `Copied ${result.artifacts.length}, skipped ${result.skipped.length}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/disk/{disk_id}/artifact/glob": {
+        "/disk/{disk_id}/artifact/download": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Search through artifact file paths using glob patterns (*, ?, etc.)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download raw artifact file content. Decrypts content if encryption is enabled. The content is streamed, and byte Range requests are answered with 206 Partial Content.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
                     "artifact"
                 ],
-                "summary": "Search artifact paths with glob patterns",
+                "summary": "Download artifact content",
                 "parameters": [
                     {
                         "type": "string",
@@ -1378,51 +1897,45 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Glob pattern (e.g., '**/*.py', '*.txt')",
-                        "name": "query",
+                        "description": "File path including filename",
+                        "name": "file_path",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Maximum number of results (default 100, max 1000)",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/serializer.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Artifact"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                        "description": "File content",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the artifact content"
+                            }
                         }
+                    },
+                    "206": {
+                        "description": "Requested byte range of the file content"
+                    },
+                    "416": {
+                        "description": "Range not satisfiable"
                     }
-                }
-            }
-        },
-        "/disk/{disk_id}/artifact/grep": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Search through text-based artifact content using regex patterns",
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/download_to_sandbox": {
+            "post": {
+                "description": "Download an artifact from disk storage to a sandbox environment",
                 "consumes": [
                     "application/json"
                 ],
@@ -1432,28 +1945,25 @@ const docTemplate = `{
                 "tags": [
                     "artifact"
                 ],
-                "summary": "Search artifact content with regex",
+                "summary": "Download artifact to sandbox",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
                         "description": "Disk ID",
                         "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Regex pattern to search for",
-                        "name": "query",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum number of results (default 100, max 1000)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Download to sandbox request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DownloadToSandboxReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1468,27 +1978,36 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Artifact"
-                                            }
+                                            "$ref": "#/definitions/handler.DownloadToSandboxResp"
                                         }
                                     }
                                 }
                             ]
                         }
                     }
-                }
-            }
-        },
-        "/disk/{disk_id}/artifact/ls": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "List artifacts in a specific path or all artifacts in a disk",
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Download artifact to sandbox\nresult = client.disks.artifacts.download_to_sandbox(\n    disk_id='disk-uuid',\n    file_path='/documents/',\n    filename='report.pdf',\n    sandbox_id='sandbox-uuid',\n    sandbox_path='/home/user/'\n)\nprint(f\"Success: {result.success}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Download artifact to sandbox\nconst result = await client.disks.artifacts.downloadToSandbox('disk-uuid', {\n  filePath: '/documents/',\n  filename: 'report.pdf',\n  sandboxId: 'sandbox-uuid',\n  sandboxPath: '/home/user/'\n});\nconsole.log(` + "`" + `Success: ${result.success}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/du": {
+            "get": {
+                "description": "Report the number of files and total bytes stored under a directory and under each of its subdirectories, like du. max_depth limits how many levels of subdirectories are reported (0 = unlimited); totals always include everything below.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1498,7 +2017,7 @@ const docTemplate = `{
                 "tags": [
                     "artifact"
                 ],
-                "summary": "List artifacts",
+                "summary": "Summarize directory sizes",
                 "parameters": [
                     {
                         "type": "string",
@@ -1511,9 +2030,17 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Path filter (optional, defaults to root '/')",
+                        "example": "/documents/",
+                        "description": "Directory to summarize (defaults to root '/')",
                         "name": "path",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Subdirectory levels to report (0 = unlimited)",
+                        "name": "max_depth",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1528,7 +2055,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.ListArtifactsResp"
+                                            "$ref": "#/definitions/handler.DiskUsageResp"
                                         }
                                     }
                                 }
@@ -1536,28 +2063,16 @@ const docTemplate = `{
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List artifacts in a path\nresult = client.disks.list_artifacts(\n    disk_id='disk-uuid',\n    path='/documents/'\n)\nprint(f\"Found {len(result.artifacts)} artifacts\")\nfor artifact in result.artifacts:\n    print(f\"  - {artifact.path}{artifact.filename}\")\nprint(f\"Subdirectories: {', '.join(result.directories)}\")\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List artifacts in a path\nconst result = await client.disks.listArtifacts('disk-uuid', {\n  path: '/documents/'\n});\nconsole.log(` + "`" + `Found ${result.artifacts.length} artifacts` + "`" + `);\nfor (const artifact of result.artifacts) {\n  console.log(` + "`" + `  - ${artifact.path}${artifact.filename}` + "`" + `);\n}\nconsole.log(` + "`" + `Subdirectories: ${result.directories.join(', ')}` + "`" + `);\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/disk/{disk_id}/artifact/upload_from_sandbox": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Upload a file from a sandbox environment to disk storage as an artifact",
+        "/disk/{disk_id}/artifact/edit": {
+            "patch": {
+                "description": "Edit a text artifact on the server without downloading and re-uploading it. Operations run in order against the current content: replace (old_text must occur exactly once unless replace_all is set), insert (text before line, or one past the last line to append), delete (start_line to end_line, inclusive) and patch (a unified diff). Lines are numbered from 1. The result is stored as a new version and keeps the artifact's meta. If another writer changes the artifact meanwhile, the edit fails with 412 and can be retried. Documents such as PDF and DOCX files cannot be edited; HTML files are edited as markup.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1567,7 +2082,7 @@ const docTemplate = `{
                 "tags": [
                     "artifact"
                 ],
-                "summary": "Upload file from sandbox to disk",
+                "summary": "Edit text artifact",
                 "parameters": [
                     {
                         "type": "string",
@@ -1579,18 +2094,30 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Upload from sandbox request",
+                        "description": "Edit artifact request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UploadFromSandboxReq"
+                            "$ref": "#/definitions/handler.EditArtifactReq"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only edit if the current artifact's ETag (or SHA256) is listed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1605,31 +2132,73 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the edited content"
+                            }
                         }
-                    }
-                },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Upload file from sandbox to disk\nartifact = client.disks.artifacts.upload_from_sandbox(\n    disk_id='disk-uuid',\n    sandbox_id='sandbox-uuid',\n    sandbox_path='/home/user/',\n    sandbox_filename='output.txt',\n    file_path='/results/'\n)\nprint(f\"Created: {artifact.path}{artifact.filename}\")\n"
                     },
-                    {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Upload file from sandbox to disk\nconst artifact = await client.disks.artifacts.uploadFromSandbox('disk-uuid', {\n  sandboxId: 'sandbox-uuid',\n  sandboxPath: '/home/user/',\n  sandboxFilename: 'output.txt',\n  filePath: '/results/'\n});\nconsole.log(` + "`" + `Created: ${artifact.path}${artifact.filename}` + "`" + `);\n"
-                    }
-                ]
-            }
-        },
-        "/learning_spaces": {
-            "get": {
+                    "400": {
+                        "description": "Malformed edit, such as a line out of range",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Artifact not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Content does not match the edit, such as old_text not found or a hunk that does not apply",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Artifact does not match If-Match, or changed during the edit",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Artifact is not a text file",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "List learning spaces with optional user, meta filter, and cursor pagination.",
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Fix a line and append another without re-uploading the file\nartifact = client.disks.artifacts.edit(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md',\n    edits=[\n        {'op': 'replace', 'old_text': '- [ ] ship', 'new_text': '- [x] ship'},\n        {'op': 'insert', 'line': 10, 'text': '- [ ] celebrate\\n'},\n    ]\n)\nprint(f\"Now at version {artifact.version}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Fix a line and append another without re-uploading the file\nconst artifact = await client.disks.artifacts.edit('disk-uuid', {\n  filePath: '/notes/todo.md',\n  edits: [\n    { op: 'replace', oldText: '- [ ] ship', newText: '- [x] ship' },\n    { op: 'insert', line: 10, text: '- [ ] celebrate\\n' },\n  ]\n});\nconsole.log(` + "`" + `Now at version ${artifact.version}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/glob": {
+            "get": {
+                "description": "Search artifact file paths with shell-style globs. ` + "`" + `*` + "`" + ` matches within one path segment, ` + "`" + `**` + "`" + ` spans directories, ` + "`" + `?` + "`" + ` matches one character; character classes (` + "`" + `[a-z]` + "`" + `, ` + "`" + `[!0-9]` + "`" + `) and ` + "`" + `{a,b}` + "`" + ` alternation are supported. Results are ordered by path.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1637,38 +2206,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "List learning spaces",
+                "summary": "Search artifact paths with glob patterns",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by user identifier",
-                        "name": "user",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit per page, default 20, max 200",
-                        "name": "limit",
-                        "in": "query"
+                        "format": "uuid",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Cursor for pagination",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Order by created_at descending",
-                        "name": "time_desc",
-                        "in": "query"
+                        "description": "Glob pattern (e.g., '**/*.py', '*.txt')",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "URL-encoded JSON for JSONB containment filter",
-                        "name": "filter_by_meta",
+                        "type": "integer",
+                        "description": "Maximum number of results (default 100, max 1000)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -1684,34 +2244,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.ListLearningSpacesOutput"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Artifact"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
-                    }
-                },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List learning spaces\nresult = client.learning_spaces.list(limit=20, time_desc=True)\nfor space in result.items:\n    print(f\"{space.id}: {space.meta}\")\n\n# List with user filter\nresult = client.learning_spaces.list(user='alice@acontext.io')\n\n# List with meta filter\nresult = client.learning_spaces.list(\n    filter_by_meta={'version': '1.0'}\n)\n"
                     },
-                    {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List learning spaces\nconst result = await client.learningSpaces.list({ limit: 20, timeDesc: true });\nfor (const space of result.items) {\n  console.log(` + "`" + `${space.id}: ${JSON.stringify(space.meta)}` + "`" + `);\n}\n\n// List with user filter\nconst userSpaces = await client.learningSpaces.list({ user: 'alice@acontext.io' });\n\n// List with meta filter\nconst filtered = await client.learningSpaces.list({\n  filterByMeta: { version: '1.0' }\n});\n"
+                    "400": {
+                        "description": "Invalid glob pattern",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
-                ]
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new learning space. Optionally associate with a user.",
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/grep": {
+            "get": {
+                "description": "Search artifact text line by line, like ripgrep. Text files are searched as well as the text extracted from PDF, DOCX, XLSX, PPTX and HTML documents. Each result is the matching artifact with the line numbers and text of its matching lines, plus optional context lines. Matching is case-insensitive unless case_sensitive is set; fixed_strings matches query literally. include and exclude take path globs and may be repeated: a glob without '/' matches the file name, otherwise the full path.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1719,23 +2278,83 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Create learning space",
+                "summary": "Search artifact content with regex",
                 "parameters": [
                     {
-                        "description": "Create learning space request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.CreateLearningSpaceReq"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Regex pattern to search for",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of matching artifacts (default 100, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match case exactly (default false)",
+                        "name": "case_sensitive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Treat query as a literal string instead of a regex (default false)",
+                        "name": "fixed_strings",
+                        "in": "query"
+                    },
+                    {
+                        "type": "[]string",
+                        "description": "Only search paths matching one of these globs",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "[]string",
+                        "description": "Skip paths matching any of these globs",
+                        "name": "exclude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lines of context before each match (max 20, defaults to context)",
+                        "name": "before_context",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lines of context after each match (max 20, defaults to context)",
+                        "name": "after_context",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lines of context before and after each match (max 20)",
+                        "name": "context",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum matching lines reported per artifact (default 100, max 1000)",
+                        "name": "max_count",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1745,7 +2364,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpace"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.ArtifactGrepResult"
+                                            }
                                         }
                                     }
                                 }
@@ -1753,28 +2375,16 @@ const docTemplate = `{
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a learning space\nspace = client.learning_spaces.create(\n    user='alice@acontext.io',\n    meta={'version': '1.0'}\n)\nprint(f\"Created space: {space.id}\")\n\n# Create a learning space without a user\nspace = client.learning_spaces.create()\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a learning space\nconst space = await client.learningSpaces.create({\n  user: 'alice@acontext.io',\n  meta: { version: '1.0' }\n});\nconsole.log(` + "`" + `Created space: ${space.id}` + "`" + `);\n\n// Create a learning space without a user\nconst space2 = await client.learningSpaces.create();\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a learning space by ID.",
+        "/disk/{disk_id}/artifact/lock": {
+            "post": {
+                "description": "Acquire an advisory lease on a file, or on everything under a directory when path ends with '/'. While the lease is live, writes to covered artifacts are rejected with 423 unless they send the owner token in the X-Lease-Owner header. Acquiring again with the same owner and path extends the lease.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1782,21 +2392,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Get learning space",
+                "summary": "Acquire artifact lease",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Lease request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ArtifactLeaseReq"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -1806,34 +2427,40 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpace"
+                                            "$ref": "#/definitions/model.ArtifactLease"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "409": {
+                        "description": "An overlapping lease is held by another owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get a learning space by ID\nspace = client.learning_spaces.get('space-uuid')\nprint(f\"Space: {space.id}\")\nprint(f\"Meta: {space.meta}\")\nprint(f\"Created at: {space.created_at}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Lock a directory for 5 minutes\nlease = client.disks.artifacts.lock(\n    disk_id='disk-uuid',\n    path='/notes/',\n    owner='agent-7f3a',\n    ttl_seconds=300\n)\nprint(f\"Locked until {lease.expires_at}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get a learning space by ID\nconst space = await client.learningSpaces.get('space-uuid');\nconsole.log(` + "`" + `Space: ${space.id}` + "`" + `);\nconsole.log(` + "`" + `Meta: ${JSON.stringify(space.meta)}` + "`" + `);\nconsole.log(` + "`" + `Created at: ${space.created_at}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Lock a directory for 5 minutes\nconst lease = await client.disks.artifacts.lock('disk-uuid', {\n  path: '/notes/',\n  owner: 'agent-7f3a',\n  ttlSeconds: 300\n});\nconsole.log(` + "`" + `Locked until ${lease.expiresAt}` + "`" + `);\n"
                     }
                 ]
             },
             "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete a learning space. Junction records are cascade-deleted by the DB.",
+                "description": "Release a lease held by owner, letting other owners write the covered artifacts right away.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1841,46 +2468,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Delete learning space",
+                "summary": "Release artifact lease",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    }
-                },
-                "x-code-samples": [
+                    },
                     {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a learning space\nclient.learning_spaces.delete('space-uuid')\n"
+                        "type": "string",
+                        "example": "/notes/",
+                        "description": "Path the lease was taken on",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a learning space\nawait client.learningSpaces.delete('space-uuid');\n"
+                        "type": "string",
+                        "example": "agent-7f3a",
+                        "description": "Owner token of the lease",
+                        "name": "owner",
+                        "in": "query",
+                        "required": true
                     }
-                ]
-            },
-            "patch": {
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Owner holds no live lease on the path",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Merge provided meta into existing meta. Existing keys not in the request are preserved.",
+                ]
+            }
+        },
+        "/disk/{disk_id}/artifact/lock/renew": {
+            "post": {
+                "description": "Extend a live lease held by owner by ttl_seconds from now. A lease that has already expired cannot be renewed, since another owner may have taken the path in the meantime; acquire it again instead.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1888,24 +2529,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Update learning space (patch meta)",
+                "summary": "Renew artifact lease",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Patch meta request",
+                        "description": "Lease request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UpdateLearningSpaceReq"
+                            "$ref": "#/definitions/handler.ArtifactLeaseReq"
                         }
                     }
                 ],
@@ -1921,36 +2564,30 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpace"
+                                            "$ref": "#/definitions/model.ArtifactLease"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "404": {
+                        "description": "Owner holds no live lease on the path",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Update a learning space's meta (patch semantics)\nspace = client.learning_spaces.update(\n    'space-uuid',\n    meta={'version': '2.0', 'environment': 'production'}\n)\nprint(f\"Updated meta: {space.meta}\")\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Update a learning space's meta (patch semantics)\nconst space = await client.learningSpaces.update('space-uuid', {\n  meta: { version: '2.0', environment: 'production' }\n});\nconsole.log(` + "`" + `Updated meta: ${JSON.stringify(space.meta)}` + "`" + `);\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}/learn": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create an async learning record from a session. Initially stays in pending status.",
+        "/disk/{disk_id}/artifact/ls": {
+            "get": {
+                "description": "List artifacts in a specific path or all artifacts in a disk. With recursive=true, artifacts in all subdirectories are listed too, in path order and paginated; max_depth limits how many directory levels are descended (1 lists only the path itself, 0 means unlimited).",
                 "consumes": [
                     "application/json"
                 ],
@@ -1958,30 +2595,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Learn from session",
+                "summary": "List artifacts",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Learn request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.LearnReq"
-                        }
+                        "type": "string",
+                        "description": "Path filter (optional, defaults to root '/')",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Also list artifacts in subdirectories",
+                        "name": "recursive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "description": "Directory levels to descend in a recursive listing (0 = unlimited)",
+                        "name": "max_depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 100,
+                        "description": "Page size of a recursive listing (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination of a recursive listing",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1991,48 +2654,36 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpaceSession"
+                                            "$ref": "#/definitions/handler.ListArtifactsResp"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Learn from a session\nrecord = client.learning_spaces.learn(\n    'space-uuid',\n    session_id='session-uuid'\n)\nprint(f\"Learning record: {record.id}, status: {record.status}\")\n\n# Wait for learning to complete\nresult = client.learning_spaces.wait_for_learning(\n    'space-uuid',\n    session_id='session-uuid',\n    timeout=120.0\n)\nprint(f\"Final status: {result.status}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List artifacts in a path\nresult = client.disks.list_artifacts(\n    disk_id='disk-uuid',\n    path='/documents/'\n)\nprint(f\"Found {len(result.artifacts)} artifacts\")\nfor artifact in result.artifacts:\n    print(f\"  - {artifact.path}{artifact.filename}\")\nprint(f\"Subdirectories: {', '.join(result.directories)}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Learn from a session\nconst record = await client.learningSpaces.learn({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid'\n});\nconsole.log(` + "`" + `Learning record: ${record.id}, status: ${record.status}` + "`" + `);\n\n// Wait for learning to complete\nconst result = await client.learningSpaces.waitForLearning({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid',\n  timeout: 120\n});\nconsole.log(` + "`" + `Final status: ${result.status}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List artifacts in a path\nconst result = await client.disks.listArtifacts('disk-uuid', {\n  path: '/documents/'\n});\nconsole.log(` + "`" + `Found ${result.artifacts.length} artifacts` + "`" + `);\nfor (const artifact of result.artifacts) {\n  console.log(` + "`" + `  - ${artifact.path}${artifact.filename}` + "`" + `);\n}\nconsole.log(` + "`" + `Subdirectories: ${result.directories.join(', ')}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}/sessions": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "List all learning session records for a space, including their processing status.",
+        "/disk/{disk_id}/artifact/move": {
+            "post": {
+                "description": "Move or rename a single artifact, or every artifact under a directory when from ends with '/'. The move is atomic and keeps each artifact's version history. With on_conflict=overwrite an existing destination keeps its history and gets the moved content as a new version.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2040,16 +2691,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "List sessions in space",
+                "summary": "Move or rename artifacts",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Move request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TransferArtifactsReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -2064,39 +2732,54 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.LearningSpaceSession"
-                                            }
+                                            "$ref": "#/definitions/service.TransferArtifactsOutput"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "404": {
+                        "description": "Disk or source artifact not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Destination exists and on_conflict is fail",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all learning sessions in a space\nsessions = client.learning_spaces.list_sessions('space-uuid')\nfor s in sessions:\n    print(f\"Session {s.session_id}: {s.status}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rename a directory\nresult = client.disks.artifacts.move(\n    disk_id='disk-uuid',\n    from_path='/drafts/',\n    to_path='/published/',\n    on_conflict='fail'\n)\nprint(f\"Moved {len(result.artifacts)} artifacts\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all learning sessions in a space\nconst sessions = await client.learningSpaces.listSessions('space-uuid');\nfor (const s of sessions) {\n  console.log(` + "`" + `Session ${s.session_id}: ${s.status}` + "`" + `);\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rename a directory\nconst result = await client.disks.artifacts.move('disk-uuid', {\n  from: '/drafts/',\n  to: '/published/',\n  onConflict: 'fail'\n});\nconsole.log(` + "`" + `Moved ${result.artifacts.length} artifacts` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}/sessions/{session_id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a single learning session record by session ID within a learning space.",
+        "/disk/{disk_id}/artifact/restore": {
+            "post": {
+                "description": "Make a previous version of an artifact current again. The restore creates a new version, so the content it replaces is kept in the history.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2104,23 +2787,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Get session in space",
+                "summary": "Restore artifact version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Restore artifact request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RestoreArtifactReq"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Session UUID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -2135,48 +2828,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpaceSession"
+                                            "$ref": "#/definitions/model.Artifact"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Artifact or version not found",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "423": {
+                        "description": "Path is locked by another lease owner",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get a learning session record\nsession = client.learning_spaces.get_session(\n    'space-uuid',\n    session_id='session-uuid'\n)\nprint(f\"Session {session.session_id}: {session.status}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Roll an artifact back to a previous version\nartifact = client.disks.artifacts.restore(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md',\n    version=2\n)\nprint(f\"Now at version {artifact.version}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get a learning session record\nconst session = await client.learningSpaces.getSession({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid'\n});\nconsole.log(` + "`" + `Session ${session.session_id}: ${session.status}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Roll an artifact back to a previous version\nconst artifact = await client.disks.artifacts.restore('disk-uuid', {\n  filePath: '/notes/todo.md',\n  version: 2\n});\nconsole.log(` + "`" + `Now at version ${artifact.version}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}/skills": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "List all skills associated with a learning space. Returns full skill data.",
+        "/disk/{disk_id}/artifact/upload_from_sandbox": {
+            "post": {
+                "description": "Upload a file from a sandbox environment to disk storage as an artifact",
                 "consumes": [
                     "application/json"
                 ],
@@ -2184,21 +2877,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "List skills in space",
+                "summary": "Upload file from sandbox to disk",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Upload from sandbox request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UploadFromSandboxReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -2208,37 +2918,42 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.AgentSkills"
-                                            }
+                                            "$ref": "#/definitions/model.Artifact"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all skills in a learning space\nskills = client.learning_spaces.list_skills('space-uuid')\nfor skill in skills:\n    print(f\"{skill.name}: {skill.description}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Upload file from sandbox to disk\nartifact = client.disks.artifacts.upload_from_sandbox(\n    disk_id='disk-uuid',\n    sandbox_id='sandbox-uuid',\n    sandbox_path='/home/user/',\n    sandbox_filename='output.txt',\n    file_path='/results/'\n)\nprint(f\"Created: {artifact.path}{artifact.filename}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all skills in a learning space\nconst skills = await client.learningSpaces.listSkills('space-uuid');\nfor (const skill of skills) {\n  console.log(` + "`" + `${skill.name}: ${skill.description}` + "`" + `);\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Upload file from sandbox to disk\nconst artifact = await client.disks.artifacts.uploadFromSandbox('disk-uuid', {\n  sandboxId: 'sandbox-uuid',\n  sandboxPath: '/home/user/',\n  sandboxFilename: 'output.txt',\n  filePath: '/results/'\n});\nconsole.log(` + "`" + `Created: ${artifact.path}${artifact.filename}` + "`" + `);\n"
                     }
                 ]
-            },
+            }
+        },
+        "/disk/{disk_id}/artifact/upload_url": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Add a skill to a learning space.",
+                "description": "Get a presigned URL to PUT a file to storage directly, so large files do not pass through the API. The PUT must send the returned content_type as its Content-Type header. Once uploaded, commit the file with POST /disk/{disk_id}/artifact/commit before the URL expires. Uploads that are never committed are deleted once no upload URL can still be valid, 7 days after they were written.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2246,24 +2961,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Include skill in space",
+                "summary": "Create artifact upload URL",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Include skill request",
+                        "description": "Upload URL request",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.IncludeSkillReq"
+                            "$ref": "#/definitions/handler.CreateArtifactUploadURLReq"
                         }
                     }
                 ],
@@ -2279,7 +2995,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.LearningSpaceSkill"
+                                            "$ref": "#/definitions/service.PresignedUpload"
                                         }
                                     }
                                 }
@@ -2287,40 +3003,22 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
+                        "description": "Disk not found",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Include a skill in a learning space\nrecord = client.learning_spaces.include_skill(\n    'space-uuid',\n    skill_id='skill-uuid'\n)\nprint(f\"Linked skill {record.skill_id} to space {record.learning_space_id}\")\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Include a skill in a learning space\nconst record = await client.learningSpaces.includeSkill({\n  spaceId: 'space-uuid',\n  skillId: 'skill-uuid'\n});\nconsole.log(` + "`" + `Linked skill ${record.skill_id} to space ${record.learning_space_id}` + "`" + `);\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/learning_spaces/{id}/skills/{skill_id}": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Remove a skill from a learning space. Idempotent — silently succeeds if not associated.",
+        "/disk/{disk_id}/artifact/versions": {
+            "get": {
+                "description": "List the previous versions retained for an artifact, newest first. A version is kept every time the artifact is overwritten, subject to the configured retention policy.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2328,22 +3026,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "learning_spaces"
+                    "artifact"
                 ],
-                "summary": "Exclude skill from space",
+                "summary": "List artifact versions",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Learning space UUID",
-                        "name": "id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Skill UUID",
-                        "name": "skill_id",
-                        "in": "path",
+                        "example": "/notes/todo.md",
+                        "description": "File path including filename",
+                        "name": "file_path",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -2351,100 +3052,198 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/serializer.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ListArtifactVersionsResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Exclude a skill from a learning space (idempotent)\nclient.learning_spaces.exclude_skill(\n    'space-uuid',\n    skill_id='skill-uuid'\n)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List previous versions of an artifact\nresult = client.disks.artifacts.list_versions(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md'\n)\nprint(f\"Current version: {result.current_version}\")\nfor v in result.versions:\n    print(f\"  - v{v.version} archived at {v.archived_at}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Exclude a skill from a learning space (idempotent)\nawait client.learningSpaces.excludeSkill({\n  spaceId: 'space-uuid',\n  skillId: 'skill-uuid'\n});\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List previous versions of an artifact\nconst result = await client.disks.artifacts.listVersions('disk-uuid', {\n  filePath: '/notes/todo.md'\n});\nconsole.log(` + "`" + `Current version: ${result.currentVersion}` + "`" + `);\nfor (const v of result.versions) {\n  console.log(` + "`" + `  - v${v.version} archived at ${v.archivedAt}` + "`" + `);\n}\n"
                     }
                 ]
             }
         },
-        "/material/{token}": {
+        "/disk/{disk_id}/export": {
             "get": {
-                "description": "Download file content via a material token. No authentication required. Returns the file content with appropriate Content-Type header.",
+                "description": "Stream every artifact under a directory as a zip or tar.gz archive. Entry names are relative to the exported directory. Content is decrypted if encryption is enabled.",
                 "produces": [
-                    "application/octet-stream"
+                    "application/zip",
+                    "application/gzip"
                 ],
                 "tags": [
-                    "material"
+                    "disk"
                 ],
-                "summary": "Serve material content",
+                "summary": "Export disk as an archive",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Material token (64-char hex)",
-                        "name": "token",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "zip",
+                        "description": "Archive format (zip or tar.gz, defaults to zip)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "/documents/",
+                        "description": "Directory to export (defaults to root '/')",
+                        "name": "path",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "File content"
-                    },
-                    "404": {
-                        "description": "Token not found or expired",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
+                        "description": "Archive content"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/metrics/v1": {
+        "/disk/{disk_id}/import": {
             "post": {
-                "description": "Create storage metrics, fetch metrics, push to external API, and store quota status in Redis",
+                "description": "Unpack an uploaded zip or tar.gz archive into artifacts under a directory. A single top-level folder wrapping all files is stripped, macOS metadata is skipped and paths escaping the archive root are rejected. The archive must not exceed the configured maximum archive size (default: 256MB) and each file the maximum upload size.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "metrics"
+                    "disk"
+                ],
+                "summary": "Import an archive into a disk",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Archive to import",
+                        "name": "archive",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Destination directory (defaults to root '/')",
+                        "name": "path",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Archive format (zip or tar.gz), inferred from the file name when omitted",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "What to do when a file exists: fail, skip or overwrite (defaults to fail)",
+                        "name": "on_conflict",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lease owner token, required when the path is covered by a lease",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Push metrics to external API",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/serializer.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ImportArtifactsOutput"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid archive",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "429": {
-                        "description": "Too Many Requests",
+                    "409": {
+                        "description": "A file exists and on_conflict is fail",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "413": {
+                        "description": "Archive exceeds maximum allowed size",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "Path is locked by another lease owner",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/metrics/v1/:project_id/quota": {
-            "get": {
-                "description": "Get quota information for a project based on path and method",
+        "/disk/{disk_id}/restore/{snapshot_id}": {
+            "post": {
+                "description": "Roll the disk back to one of its snapshots in a single transaction. Artifacts that differ from the snapshot are overwritten (their current content is kept in the artifact's version history), artifacts not in the snapshot are deleted, and missing ones are recreated. Since a restore may touch any path, it is rejected with 423 while another owner holds a lease anywhere on the disk.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2452,66 +3251,103 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "metrics"
+                    "disk"
                 ],
-                "summary": "Get project quota",
+                "summary": "Restore disk snapshot",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project ID",
-                        "name": "project_id",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Path",
-                        "name": "path",
-                        "in": "query",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Snapshot ID",
+                        "name": "snapshot_id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Method",
-                        "name": "method",
-                        "in": "query",
-                        "required": true
+                        "description": "Lease owner token, required when the disk has leases",
+                        "name": "X-Lease-Owner",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/serializer.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repo.DiskSnapshotRestoreStats"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "423": {
+                        "description": "Disk is locked by another lease owner",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
-                }
-            }
-        },
-        "/project/configs": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/disk/{disk_id}/snapshot": {
+            "post": {
+                "description": "Capture an immutable manifest of all artifacts on the disk (path, filename, asset SHA256 and meta). Content is not copied; the snapshot only adds references to the existing assets.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Returns the project-level configuration (stored under the \"project_config\" key).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "disk"
+                ],
+                "summary": "Create disk snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "CreateDiskSnapshot payload",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateDiskSnapshotReq"
+                        }
+                    }
                 ],
-                "summary": "Get project configs",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -2521,23 +3357,22 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/model.DiskSnapshot"
                                         }
                                     }
                                 }
                             ]
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Merges the provided keys into the project-level configuration. Keys with null values are deleted (reset to default).",
+                ]
+            },
+            "get": {
+                "description": "List the snapshots of a disk, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2545,19 +3380,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "disk"
                 ],
-                "summary": "Patch project configs",
+                "summary": "List disk snapshots",
                 "parameters": [
                     {
-                        "description": "Config keys to merge",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2572,157 +3406,75 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.DiskSnapshot"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
                     }
-                }
-            }
-        },
-        "/project/decrypt": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/disk/{disk_id}/snapshot/{snapshot_id}": {
+            "delete": {
+                "description": "Delete a snapshot and release its asset references. The disk itself is not changed.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Decrypts all existing S3 data for the project and disables encryption for future writes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "disk"
                 ],
-                "summary": "Disable project encryption",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
+                "summary": "Delete disk snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Disk ID",
+                        "name": "disk_id",
+                        "in": "path",
+                        "required": true
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/project/encrypt": {
-            "post": {
-                "security": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "description": "Snapshot ID",
+                        "name": "snapshot_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "Encrypts all existing S3 data for the project and enables encryption for future writes.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Project"
-                ],
-                "summary": "Enable project encryption",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
                     }
-                }
-            }
-        },
-        "/sandbox": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create and start a new sandbox for the project",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "sandbox"
-                ],
-                "summary": "Create a new sandbox",
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/serializer.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/httpclient.SandboxRuntimeInfo"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    }
-                },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a new sandbox\nsandbox = client.sandboxes.create()\nprint(f\"Sandbox ID: {sandbox.sandbox_id}\")\nprint(f\"Status: {sandbox.sandbox_status}\")\n"
-                    },
-                    {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a new sandbox\nconst sandbox = await client.sandboxes.create();\nconsole.log(` + "`" + `Sandbox ID: ${sandbox.sandbox_id}` + "`" + `);\nconsole.log(` + "`" + `Status: ${sandbox.sandbox_status}` + "`" + `);\n"
-                    }
                 ]
             }
         },
-        "/sandbox/logs": {
+        "/learning_spaces": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get sandbox logs for the project with cursor-based pagination",
+                "description": "List learning spaces with optional user, meta filter, and cursor pagination.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2730,28 +3482,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sandbox"
+                    "learning_spaces"
                 ],
-                "summary": "Get sandbox logs",
+                "summary": "List learning spaces",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user identifier",
+                        "name": "user",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Limit of sandbox logs to return, default 20. Max 200.",
+                        "description": "Limit per page, default 20, max 200",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "description": "Cursor for pagination",
                         "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "description": "Order by created_at descending",
                         "name": "time_desc",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL-encoded JSON for JSONB containment filter",
+                        "name": "filter_by_meta",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2766,7 +3529,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.GetSandboxLogsOutput"
+                                            "$ref": "#/definitions/service.ListLearningSpacesOutput"
                                         }
                                     }
                                 }
@@ -2774,28 +3537,26 @@ const docTemplate = `{
                         }
                     }
                 },
-                "x-code-samples": [
+                "security": [
                     {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get sandbox logs\nlogs = client.sandboxes.get_logs(limit=20, time_desc=True)\nfor log in logs.items:\n    print(f\"Log {log.id}: {log.backend_type}\")\n\n# If there are more logs, use the cursor for pagination\nif logs.has_more:\n    next_logs = client.sandboxes.get_logs(\n        limit=20,\n        cursor=logs.next_cursor\n    )\n"
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List learning spaces\nresult = client.learning_spaces.list(limit=20, time_desc=True)\nfor space in result.items:\n    print(f\"{space.id}: {space.meta}\")\n\n# List with user filter\nresult = client.learning_spaces.list(user='alice@acontext.io')\n\n# List with meta filter\nresult = client.learning_spaces.list(\n    filter_by_meta={'version': '1.0'}\n)\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get sandbox logs\nconst logs = await client.sandboxes.getLogs({ limit: 20, timeDesc: true });\nfor (const log of logs.items) {\n  console.log(` + "`" + `Log ${log.id}: ${log.backend_type}` + "`" + `);\n}\n\n// If there are more logs, use the cursor for pagination\nif (logs.hasMore) {\n  const nextLogs = await client.sandboxes.getLogs({\n    limit: 20,\n    cursor: logs.nextCursor\n  });\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List learning spaces\nconst result = await client.learningSpaces.list({ limit: 20, timeDesc: true });\nfor (const space of result.items) {\n  console.log(` + "`" + `${space.id}: ${JSON.stringify(space.meta)}` + "`" + `);\n}\n\n// List with user filter\nconst userSpaces = await client.learningSpaces.list({ user: 'alice@acontext.io' });\n\n// List with meta filter\nconst filtered = await client.learningSpaces.list({\n  filterByMeta: { version: '1.0' }\n});\n"
                     }
                 ]
-            }
-        },
-        "/sandbox/{sandbox_id}": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Kill a running sandbox",
+            },
+            "post": {
+                "description": "Create a new learning space. Optionally associate with a user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2803,21 +3564,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sandbox"
+                    "learning_spaces"
                 ],
-                "summary": "Kill a sandbox",
+                "summary": "Create learning space",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Sandbox ID",
-                        "name": "sandbox_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Create learning space request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateLearningSpaceReq"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -2827,7 +3590,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/httpclient.FlagResponse"
+                                            "$ref": "#/definitions/model.LearningSpace"
                                         }
                                     }
                                 }
@@ -2835,28 +3598,28 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Kill a sandbox\nresult = client.sandboxes.kill(sandbox_id='sandbox-uuid')\nprint(f\"Status: {result.status}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a learning space\nspace = client.learning_spaces.create(\n    user='alice@acontext.io',\n    meta={'version': '1.0'}\n)\nprint(f\"Created space: {space.id}\")\n\n# Create a learning space without a user\nspace = client.learning_spaces.create()\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Kill a sandbox\nconst result = await client.sandboxes.kill('sandbox-uuid');\nconsole.log(` + "`" + `Status: ${result.status}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a learning space\nconst space = await client.learningSpaces.create({\n  user: 'alice@acontext.io',\n  meta: { version: '1.0' }\n});\nconsole.log(` + "`" + `Created space: ${space.id}` + "`" + `);\n\n// Create a learning space without a user\nconst space2 = await client.learningSpaces.create();\n"
                     }
                 ]
             }
         },
-        "/sandbox/{sandbox_id}/exec": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Execute a shell command in the specified sandbox",
+        "/learning_spaces/{id}": {
+            "get": {
+                "description": "Get a learning space by ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2864,25 +3627,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sandbox"
+                    "learning_spaces"
                 ],
-                "summary": "Execute command in sandbox",
+                "summary": "Get learning space",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sandbox ID",
-                        "name": "sandbox_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Command to execute",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.ExecCommandReq"
-                        }
                     }
                 ],
                 "responses": {
@@ -2897,7 +3651,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/httpclient.SandboxCommandOutput"
+                                            "$ref": "#/definitions/model.LearningSpace"
                                         }
                                     }
                                 }
@@ -2905,28 +3659,26 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Execute a command in the sandbox\nresult = client.sandboxes.exec_command(\n    sandbox_id='sandbox-uuid',\n    command='ls -la'\n)\nprint(f\"stdout: {result.stdout}\")\nprint(f\"stderr: {result.stderr}\")\nprint(f\"exit_code: {result.exit_code}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get a learning space by ID\nspace = client.learning_spaces.get('space-uuid')\nprint(f\"Space: {space.id}\")\nprint(f\"Meta: {space.meta}\")\nprint(f\"Created at: {space.created_at}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Execute a command in the sandbox\nconst result = await client.sandboxes.execCommand({\n  sandboxId: 'sandbox-uuid',\n  command: 'ls -la'\n});\nconsole.log(` + "`" + `stdout: ${result.stdout}` + "`" + `);\nconsole.log(` + "`" + `stderr: ${result.stderr}` + "`" + `);\nconsole.log(` + "`" + `exit_code: ${result.exit_code}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get a learning space by ID\nconst space = await client.learningSpaces.get('space-uuid');\nconsole.log(` + "`" + `Space: ${space.id}` + "`" + `);\nconsole.log(` + "`" + `Meta: ${JSON.stringify(space.meta)}` + "`" + `);\nconsole.log(` + "`" + `Created at: ${space.created_at}` + "`" + `);\n"
                     }
                 ]
-            }
-        },
-        "/session": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all sessions under a project, optionally filtered by user or configs",
+            },
+            "delete": {
+                "description": "Delete a learning space. Junction records are cascade-deleted by the DB.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2934,41 +3686,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Get sessions",
+                "summary": "Delete learning space",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "alice@acontext.io",
-                        "description": "User identifier to filter sessions",
-                        "name": "user",
-                        "in": "query"
-                    },
+                        "description": "Learning space UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "JSON-encoded object for JSONB containment filter. Example: {\\",
-                        "name": "filter_by_configs",
-                        "in": "query"
-                    },
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
                     {
-                        "type": "integer",
-                        "description": "Limit of sessions to return, default 20. Max 200.",
-                        "name": "limit",
-                        "in": "query"
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a learning space\nclient.learning_spaces.delete('space-uuid')\n"
                     },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a learning space\nawait client.learningSpaces.delete('space-uuid');\n"
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Merge provided meta into existing meta. Existing keys not in the request are preserved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "learning_spaces"
+                ],
+                "summary": "Update learning space (patch meta)",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
-                        "name": "cursor",
-                        "in": "query"
+                        "description": "Learning space UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
-                        "name": "time_desc",
-                        "in": "query"
+                        "description": "Patch meta request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateLearningSpaceReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -2983,7 +3766,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.ListSessionsOutput"
+                                            "$ref": "#/definitions/model.LearningSpace"
                                         }
                                     }
                                 }
@@ -2991,26 +3774,28 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List sessions\nsessions = client.sessions.list(\n    limit=20,\n    time_desc=True\n)\nfor session in sessions.items:\n    print(f\"{session.id}\")\n\n# List sessions for a specific user\nsessions = client.sessions.list(user='alice@acontext.io', limit=20)\n\n# List sessions filtered by configs\nsessions = client.sessions.list(\n    limit=20,\n    filter_by_configs={\"agent\": \"bot1\"}\n)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Update a learning space's meta (patch semantics)\nspace = client.learning_spaces.update(\n    'space-uuid',\n    meta={'version': '2.0', 'environment': 'production'}\n)\nprint(f\"Updated meta: {space.meta}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List sessions\nconst sessions = await client.sessions.list({\n  limit: 20,\n  timeDesc: true\n});\nfor (const session of sessions.items) {\n  console.log(` + "`" + `${session.id}` + "`" + `);\n}\n\n// List sessions for a specific user\nconst userSessions = await client.sessions.list({ user: 'alice@acontext.io', limit: 20 });\n\n// List sessions filtered by configs\nconst filteredSessions = await client.sessions.list({\n  limit: 20,\n  filterByConfigs: { agent: 'bot1' }\n});\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Update a learning space's meta (patch semantics)\nconst space = await client.learningSpaces.update('space-uuid', {\n  meta: { version: '2.0', environment: 'production' }\n});\nconsole.log(` + "`" + `Updated meta: ${JSON.stringify(space.meta)}` + "`" + `);\n"
                     }
                 ]
-            },
+            }
+        },
+        "/learning_spaces/{id}/learn": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new session. Optionally associate with a user identifier. You can also specify a custom UUID using use_uuid.",
+                "description": "Create an async learning record from a session. Initially stays in pending status.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3018,17 +3803,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Create session",
+                "summary": "Learn from session",
                 "parameters": [
                     {
-                        "description": "CreateSession payload",
-                        "name": "payload",
+                        "type": "string",
+                        "description": "Learning space UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Learn request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateSessionReq"
+                            "$ref": "#/definitions/handler.LearnReq"
                         }
                     }
                 ],
@@ -3044,48 +3836,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Session"
+                                            "$ref": "#/definitions/model.LearningSpaceSession"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid UUID format",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
                     "409": {
-                        "description": "Session with this UUID already exists",
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a session\nsession = client.sessions.create()\nprint(f\"Created session: {session.id}\")\n\n# Create a session for a specific user\nsession = client.sessions.create(user='alice@acontext.io')\n\n# Create a session with a specific UUID\nsession = client.sessions.create(use_uuid='123e4567-e89b-12d3-a456-426614174000')\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Learn from a session\nrecord = client.learning_spaces.learn(\n    'space-uuid',\n    session_id='session-uuid'\n)\nprint(f\"Learning record: {record.id}, status: {record.status}\")\n\n# Wait for learning to complete\nresult = client.learning_spaces.wait_for_learning(\n    'space-uuid',\n    session_id='session-uuid',\n    timeout=120.0\n)\nprint(f\"Final status: {result.status}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a session\nconst session = await client.sessions.create();\nconsole.log(` + "`" + `Created session: ${session.id}` + "`" + `);\n\n// Create a session for a specific user\nconst userSession = await client.sessions.create({ user: 'alice@acontext.io' });\n\n// Create a session with a specific UUID\nconst customSession = await client.sessions.create({ useUuid: '123e4567-e89b-12d3-a456-426614174000' });\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Learn from a session\nconst record = await client.learningSpaces.learn({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid'\n});\nconsole.log(` + "`" + `Learning record: ${record.id}, status: ${record.status}` + "`" + `);\n\n// Wait for learning to complete\nconst result = await client.learningSpaces.waitForLearning({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid',\n  timeout: 120\n});\nconsole.log(` + "`" + `Final status: ${result.status}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete a session by id",
+        "/learning_spaces/{id}/sessions": {
+            "get": {
+                "description": "List all learning session records for a space, including their processing status.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3093,15 +3885,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Delete session",
+                "summary": "List sessions in space",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -3110,86 +3901,68 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/serializer.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.LearningSpaceSession"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a session\nclient.sessions.delete(session_id='session-uuid')\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all learning sessions in a space\nsessions = client.learning_spaces.list_sessions('space-uuid')\nfor s in sessions:\n    print(f\"Session {s.session_id}: {s.status}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a session\nawait client.sessions.delete('session-uuid');\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all learning sessions in a space\nconst sessions = await client.learningSpaces.listSessions('space-uuid');\nfor (const s of sessions) {\n  console.log(` + "`" + `Session ${s.session_id}: ${s.status}` + "`" + `);\n}\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/asset/download": {
+        "/learning_spaces/{id}/sessions/{session_id}": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                "description": "Get a single learning session record by session ID within a learning space.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Download a session asset (file attachment) by its S3 key. Decrypts if encryption is enabled.",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Download session asset",
+                "summary": "Get session in space",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "S3 key of the asset",
-                        "name": "s3_key",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Asset content"
-                    }
-                }
-            }
-        },
-        "/session/{session_id}/configs": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get session configs by id",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "session"
-                ],
-                "summary": "Get session configs",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
+                        "description": "Session UUID",
                         "name": "session_id",
                         "in": "path",
                         "required": true
@@ -3207,34 +3980,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Session"
+                                            "$ref": "#/definitions/model.LearningSpaceSession"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get session configs\nsession = client.sessions.get_configs(session_id='session-uuid')\nprint(session.configs)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get a learning session record\nsession = client.learning_spaces.get_session(\n    'space-uuid',\n    session_id='session-uuid'\n)\nprint(f\"Session {session.session_id}: {session.status}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get session configs\nconst session = await client.sessions.getConfigs('session-uuid');\nconsole.log(session.configs);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get a learning session record\nconst session = await client.learningSpaces.getSession({\n  spaceId: 'space-uuid',\n  sessionId: 'session-uuid'\n});\nconsole.log(` + "`" + `Session ${session.session_id}: ${session.status}` + "`" + `);\n"
                     }
                 ]
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update session configs by id",
+            }
+        },
+        "/learning_spaces/{id}/skills": {
+            "get": {
+                "description": "List all skills associated with a learning space. Returns full skill data.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3242,56 +4029,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Update session configs",
+                "summary": "List skills in space",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "UpdateSessionConfigs payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UpdateSessionConfigsReq"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/serializer.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.AgentSkills"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Update session configs\nclient.sessions.update_configs(\n    session_id='session-uuid'\n)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all skills in a learning space\nskills = client.learning_spaces.list_skills('space-uuid')\nfor skill in skills:\n    print(f\"{skill.name}: {skill.description}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Update session configs\nawait client.sessions.updateConfigs('session-uuid');\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all skills in a learning space\nconst skills = await client.learningSpaces.listSkills('space-uuid');\nfor (const skill of skills) {\n  console.log(` + "`" + `${skill.name}: ${skill.description}` + "`" + `);\n}\n"
                     }
                 ]
             },
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update session configs using patch semantics. Only updates keys present in the request. Pass null as value to delete a key. Returns the complete configs after patch.",
+            "post": {
+                "description": "Add a skill to a learning space.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3299,31 +4091,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Patch session configs",
+                "summary": "Include skill in space",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "PatchSessionConfigs payload",
-                        "name": "payload",
+                        "description": "Include skill request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.PatchSessionConfigsReq"
+                            "$ref": "#/definitions/handler.IncludeSkillReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -3333,48 +4124,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.PatchSessionConfigsResp"
+                                            "$ref": "#/definitions/model.LearningSpaceSkill"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "404": {
-                        "description": "Session not found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Patch session configs (adds/updates keys, use None to delete)\nupdated_configs = client.sessions.patch_configs(\n    session_id='session-uuid',\n    configs={'agent': 'bot2', 'old_key': None}  # None deletes the key\n)\nprint(updated_configs)  # {'existing_key': 'value', 'agent': 'bot2'}\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Include a skill in a learning space\nrecord = client.learning_spaces.include_skill(\n    'space-uuid',\n    skill_id='skill-uuid'\n)\nprint(f\"Linked skill {record.skill_id} to space {record.learning_space_id}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Patch session configs (adds/updates keys, use null to delete)\nconst updatedConfigs = await client.sessions.patchConfigs(\n  'session-uuid',\n  { agent: 'bot2', old_key: null }  // null deletes the key\n);\nconsole.log(updatedConfigs);  // { existing_key: 'value', agent: 'bot2' }\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Include a skill in a learning space\nconst record = await client.learningSpaces.includeSkill({\n  spaceId: 'space-uuid',\n  skillId: 'skill-uuid'\n});\nconsole.log(` + "`" + `Linked skill ${record.skill_id} to space ${record.learning_space_id}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/copy": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a complete copy of a session with all its messages and tasks. The copied session will be independent and can be modified without affecting the original.",
+        "/learning_spaces/{id}/skills/{skill_id}": {
+            "delete": {
+                "description": "Remove a skill from a learning space. Idempotent — silently succeeds if not associated.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3382,15 +4173,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "learning_spaces"
                 ],
-                "summary": "Copy session",
+                "summary": "Exclude skill from space",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Learning space UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Skill UUID",
+                        "name": "skill_id",
                         "in": "path",
                         "required": true
                     }
@@ -3399,68 +4196,117 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/serializer.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/handler.CopySessionResp"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/serializer.Response"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Exclude a skill from a learning space (idempotent)\nclient.learning_spaces.exclude_skill(\n    'space-uuid',\n    skill_id='skill-uuid'\n)\n"
                     },
-                    "400": {
-                        "description": "Invalid session ID",
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Exclude a skill from a learning space (idempotent)\nawait client.learningSpaces.excludeSkill({\n  spaceId: 'space-uuid',\n  skillId: 'skill-uuid'\n});\n"
+                    }
+                ]
+            }
+        },
+        "/material/{token}": {
+            "get": {
+                "description": "Download file content via a material token. No authentication required. Returns the file content with appropriate Content-Type header. The content is streamed, and byte Range requests are answered with 206 Partial Content.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "material"
+                ],
+                "summary": "Serve material content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Material token (64-char hex)",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File content"
+                    },
+                    "206": {
+                        "description": "Requested byte range of the file content"
+                    },
+                    "404": {
+                        "description": "Token not found or expired",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "404": {
-                        "description": "Session not found",
+                    "416": {
+                        "description": "Range not satisfiable"
+                    }
+                }
+            }
+        },
+        "/metrics/v1": {
+            "post": {
+                "description": "Create storage metrics, fetch metrics, push to external API, and store quota status in Redis",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Push metrics to external API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "413": {
-                        "description": "Session exceeds maximum copyable size",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     },
-                    "500": {
-                        "description": "Failed to copy session",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
-                    }
-                },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Copy a session\nresult = client.sessions.copy(session_id='session-uuid')\nprint(f\"Copied session: {result.new_session_id}\")\nprint(f\"Original session: {result.old_session_id}\")\n"
                     },
-                    {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Copy a session\nconst result = await client.sessions.copy('session-uuid');\nconsole.log(` + "`" + `Copied session: ${result.newSessionId}` + "`" + `);\nconsole.log(` + "`" + `Original session: ${result.oldSessionId}` + "`" + `);\n"
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
-                ]
+                }
             }
         },
-        "/session/{session_id}/events": {
+        "/metrics/v1/:project_id/quota": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get events for a session with cursor-based pagination.",
+                "description": "Get quota information for a project based on path and method",
                 "consumes": [
                     "application/json"
                 ],
@@ -3468,38 +4314,58 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "metrics"
                 ],
-                "summary": "Get events for session",
+                "summary": "Get project quota",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Project ID",
+                        "name": "project_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit of events to return, default 50. Max 200.",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Path",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Cursor for pagination.",
-                        "name": "cursor",
-                        "in": "query"
+                        "description": "Method",
+                        "name": "method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     },
-                    {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
-                        "name": "time_desc",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
+                }
+            }
+        },
+        "/project/configs": {
+            "get": {
+                "description": "Returns the project-level configuration (stored under the \"project_config\" key).",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get project configs",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -3512,7 +4378,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.ListEventsOutput"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -3520,26 +4387,14 @@ const docTemplate = `{
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get events for a session\nevents = client.sessions.get_events(session_id, limit=50)\nfor event in events.items:\n    print(f\"{event.type}: {event.data}\")\n"
-                    },
-                    {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get events for a session\nconst events = await client.sessions.getEvents(sessionId, { limit: 50 });\nfor (const event of events.items) {\n  console.log(` + "`" + `${event.type}: ${JSON.stringify(event.data)}` + "`" + `);\n}\n"
-                    }
-                ]
-            },
-            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Add a structured event to a session. Events are stored alongside messages and can be retrieved chronologically.",
+                ]
+            },
+            "patch": {
+                "description": "Merges the provided keys into the project-level configuration. Keys with null values are deleted (reset to default).",
                 "consumes": [
                     "application/json"
                 ],
@@ -3547,25 +4402,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "Project"
                 ],
-                "summary": "Add event to session",
+                "summary": "Patch project configs",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "AddEvent payload",
-                        "name": "payload",
+                        "description": "Config keys to merge",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.AddEventReq"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 ],
@@ -3581,7 +4429,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.SessionEvent"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -3589,102 +4438,96 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "Session not found",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\nfrom acontext.event import DiskEvent, TextEvent\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Add a disk event\nclient.sessions.add_event(session_id, DiskEvent(disk_id='xxxx', path='/data/report.csv', note='Uploaded report'))\n\n# Add a text event\nclient.sessions.add_event(session_id, TextEvent(text='User switched to dark mode'))\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient, DiskEvent, TextEvent } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Add a disk event\nawait client.sessions.addEvent(sessionId, new DiskEvent({ diskId: 'xxxx', path: '/data/report.csv', note: 'Uploaded report' }));\n\n// Add a text event\nawait client.sessions.addEvent(sessionId, new TextEvent({ text: 'User switched to dark mode' }));\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/session/{session_id}/flush": {
+        "/project/decrypt": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Flush the session buffer for a given session",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Decrypts all existing S3 data for the project and disables encryption for future writes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "session"
-                ],
-                "summary": "Flush session",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Project"
                 ],
+                "summary": "Disable project encryption",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/serializer.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/httpclient.FlagResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
-                "x-code-samples": [
-                    {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Flush session buffer\nresult = client.sessions.flush(session_id='session-uuid')\nprint(result.status)\n"
-                    },
+                "security": [
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Flush session buffer\nconst result = await client.sessions.flush('session-uuid');\nconsole.log(result.status);\n"
+                        "BearerAuth": []
                     }
                 ]
             }
         },
-        "/session/{session_id}/messages": {
-            "get": {
+        "/project/encrypt": {
+            "post": {
+                "description": "Encrypts all existing S3 data for the project and enables encryption for future writes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Enable project encryption",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get messages from session. Default format is openai. Can convert to acontext (original), anthropic, or gemini format.",
+                ]
+            }
+        },
+        "/project/feedback": {
+            "get": {
+                "description": "List message feedback across all sessions of the project with cursor-based pagination, together with a summary (counts, thumbs totals, average score and label counts) over all matching feedback.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3692,75 +4535,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "Project"
                 ],
-                "summary": "Get messages from session",
+                "summary": "Get project feedback",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only feedback for this message",
+                        "name": "message_id",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit of messages to return. Max 200. If limit is 0 or not provided, all messages will be returned. \n\nWARNING!\n Use ` + "`" + `limit` + "`" + ` only for read-only/display purposes (pagination, viewing). Do NOT use ` + "`" + `limit` + "`" + ` to truncate messages before sending to LLM as it may cause tool-call and tool-result unpairing issues. Instead, use the ` + "`" + `token_limit` + "`" + ` edit strategy in ` + "`" + `edit_strategies` + "`" + ` parameter to safely manage message context size.",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Only feedback submitted by this user identifier",
+                        "name": "user",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "up",
+                            "down"
+                        ],
                         "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
-                        "name": "cursor",
+                        "description": "Only feedback with this thumbs value",
+                        "name": "thumbs",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "example": true,
-                        "description": "Whether to return asset public url, default is true",
-                        "name": "with_asset_public_url",
+                        "type": "string",
+                        "description": "Comma-separated labels; only feedback carrying all of them",
+                        "name": "labels",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Whether to include session events in the response, default is false",
-                        "name": "with_events",
+                        "type": "string",
+                        "description": "Only feedback submitted at or after this RFC3339 time",
+                        "name": "created_after",
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "acontext",
-                            "openai",
-                            "anthropic",
-                            "gemini"
-                        ],
                         "type": "string",
-                        "description": "Format to convert messages to: acontext (original), openai (default), anthropic, gemini.",
-                        "name": "format",
+                        "description": "Only feedback submitted before this RFC3339 time",
+                        "name": "created_before",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
-                        "name": "time_desc",
+                        "type": "integer",
+                        "description": "Limit of feedback to return, default 50. Max 200.",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "[{\"type\":\"remove_tool_result\",\"params\":{\"keep_recent_n_tool_results\":3}}]",
-                        "description": "JSON array of edit strategies to apply before format conversion",
-                        "name": "edit_strategies",
+                        "description": "Cursor for pagination.",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "example": "",
-                        "description": "Message ID to pin editing strategies at. When provided, strategies are only applied to messages up to and including this message ID, keeping subsequent messages unchanged. This helps maintain prompt cache stability by preserving a stable prefix. The response will include edit_at_message_id indicating where strategies were applied.",
-                        "name": "pin_editing_strategies_at_message",
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
                         "in": "query"
                     }
                 ],
@@ -3776,76 +4612,52 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/converter.GetMessagesOutput"
+                                            "$ref": "#/definitions/service.ListFeedbackOutput"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get messages from session\nmessages = client.sessions.get_messages(\n    session_id='session-uuid',\n    limit=50,\n    format='acontext',\n    time_desc=True\n)\nfor message in messages.items:\n    print(f\"{message.role}: {message.parts}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Negative feedback from the last week\nresult = client.project.get_feedback(thumbs='down', created_after='2025-01-01T00:00:00Z')\nprint(result.summary.count, result.summary.label_counts)\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get messages from session\nconst messages = await client.sessions.getMessages('session-uuid', {\n  limit: 50,\n  format: 'acontext',\n  timeDesc: true\n});\nfor (const message of messages.items) {\n  console.log(` + "`" + `${message.role}: ${JSON.stringify(message.parts)}` + "`" + `);\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Negative feedback from the last week\nconst result = await client.project.getFeedback({ thumbs: 'down', createdAfter: '2025-01-01T00:00:00Z' });\nconsole.log(result.summary.count, result.summary.label_counts);\n"
                     }
                 ]
-            },
+            }
+        },
+        "/sandbox": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Supports JSON and multipart/form-data. In multipart mode: the payload is a JSON string placed in a form field. The format parameter indicates the format of the input message (default: openai, same as GET). The blob field should be a complete message object: for openai, use OpenAI ChatCompletionMessageParam format (with role and content); for anthropic, use Anthropic MessageParam format (with role and content); for acontext (internal), use {role, parts} format. The optional meta field allows attaching user-provided metadata to the message, which can be retrieved via get_messages().metas or updated via patch_message_meta().",
+                "description": "Create and start a new sandbox for the project",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "session"
-                ],
-                "summary": "Store message to session",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "StoreMessage payload (Content-Type: application/json)",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.StoreMessageReq"
-                        }
-                    },
-                    {
-                        "type": "string",
-                        "description": "StoreMessage payload (Content-Type: multipart/form-data)",
-                        "name": "payload",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "When uploading files, the field name must correspond to parts[*].file_field.",
-                        "name": "file",
-                        "in": "formData"
-                    }
+                    "sandbox"
                 ],
+                "summary": "Create a new sandbox",
                 "responses": {
                     "201": {
                         "description": "Created",
@@ -3858,7 +4670,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Message"
+                                            "$ref": "#/definitions/httpclient.SandboxRuntimeInfo"
                                         }
                                     }
                                 }
@@ -3866,28 +4678,28 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\nfrom acontext.messages import build_acontext_message\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Store a message in OpenAI format with user metadata\nclient.sessions.store_message(\n    session_id='session-uuid',\n    blob={'role': 'user', 'content': 'Hello!'},\n    format='openai',\n    meta={'source': 'web', 'request_id': 'abc123'}\n)\n\n# Store a message in Acontext format\nmessage = build_acontext_message(role='user', parts=['Hello!'])\nclient.sessions.store_message(\n    session_id='session-uuid',\n    blob=message,\n    format='acontext'\n)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a new sandbox\nsandbox = client.sandboxes.create()\nprint(f\"Sandbox ID: {sandbox.sandbox_id}\")\nprint(f\"Status: {sandbox.sandbox_status}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient, MessagePart } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Store a message in OpenAI format with user metadata\nawait client.sessions.storeMessage(\n  'session-uuid',\n  { role: 'user', content: 'Hello!' },\n  { format: 'openai', meta: { source: 'web', request_id: 'abc123' } }\n);\n\n// Store a message in Acontext format\nawait client.sessions.storeMessage(\n  'session-uuid',\n  {\n    role: 'user',\n    parts: [MessagePart.textPart('Hello!')]\n  },\n  { format: 'acontext' }\n);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a new sandbox\nconst sandbox = await client.sandboxes.create();\nconsole.log(` + "`" + `Sandbox ID: ${sandbox.sandbox_id}` + "`" + `);\nconsole.log(` + "`" + `Status: ${sandbox.sandbox_status}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/messages/{message_id}/meta": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update message metadata using patch semantics. Only updates keys present in the request. Pass null as value to delete a key.",
+        "/sandbox/logs": {
+            "get": {
+                "description": "Get sandbox logs for the project with cursor-based pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -3895,34 +4707,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "sandbox"
                 ],
-                "summary": "Patch message metadata",
+                "summary": "Get sandbox logs",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Limit of sandbox logs to return, default 20. Max 200.",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Message ID",
-                        "name": "message_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
-                        "description": "PatchMessageMeta payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.PatchMessageMetaReq"
-                        }
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3937,48 +4743,36 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.PatchMessageMetaResp"
+                                            "$ref": "#/definitions/service.GetSandboxLogsOutput"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "Message not found",
-                        "schema": {
-                            "$ref": "#/definitions/serializer.Response"
-                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Patch message meta (adds/updates keys, use None to delete)\nupdated_meta = client.sessions.patch_message_meta(\n    session_id='session-uuid',\n    message_id='message-uuid',\n    meta={'status': 'processed', 'old_key': None}  # None deletes the key\n)\nprint(updated_meta)  # {'existing_key': 'value', 'status': 'processed'}\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get sandbox logs\nlogs = client.sandboxes.get_logs(limit=20, time_desc=True)\nfor log in logs.items:\n    print(f\"Log {log.id}: {log.backend_type}\")\n\n# If there are more logs, use the cursor for pagination\nif logs.has_more:\n    next_logs = client.sandboxes.get_logs(\n        limit=20,\n        cursor=logs.next_cursor\n    )\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Patch message meta (adds/updates keys, use null to delete)\nconst updatedMeta = await client.sessions.patchMessageMeta(\n  'session-uuid',\n  'message-uuid',\n  { status: 'processed', old_key: null }  // null deletes the key\n);\nconsole.log(updatedMeta);  // { existing_key: 'value', status: 'processed' }\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get sandbox logs\nconst logs = await client.sandboxes.getLogs({ limit: 20, timeDesc: true });\nfor (const log of logs.items) {\n  console.log(` + "`" + `Log ${log.id}: ${log.backend_type}` + "`" + `);\n}\n\n// If there are more logs, use the cursor for pagination\nif (logs.hasMore) {\n  const nextLogs = await client.sandboxes.getLogs({\n    limit: 20,\n    cursor: logs.nextCursor\n  });\n}\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/observing_status": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Returns the count of observed, in_process, and pending messages",
+        "/sandbox/{sandbox_id}": {
+            "delete": {
+                "description": "Kill a running sandbox",
                 "consumes": [
                     "application/json"
                 ],
@@ -3986,15 +4780,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "sandbox"
                 ],
-                "summary": "Get message observing status for a session",
+                "summary": "Kill a sandbox",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Sandbox ID",
+                        "name": "sandbox_id",
                         "in": "path",
                         "required": true
                     }
@@ -4011,7 +4804,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.MessageObservingStatus"
+                                            "$ref": "#/definitions/httpclient.FlagResponse"
                                         }
                                     }
                                 }
@@ -4019,28 +4812,28 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get message observing status\nresult = client.sessions.messages_observing_status(session_id='session-uuid')\nprint(f\"Observed: {result.observed}, In Process: {result.in_process}, Pending: {result.pending}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Kill a sandbox\nresult = client.sandboxes.kill(sandbox_id='sandbox-uuid')\nprint(f\"Status: {result.status}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get message observing status\nconst result = await client.sessions.messagesObservingStatus('session-uuid');\nconsole.log(` + "`" + `Observed: ${result.observed}, In Process: ${result.in_process}, Pending: ${result.pending}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Kill a sandbox\nconst result = await client.sandboxes.kill('sandbox-uuid');\nconsole.log(` + "`" + `Status: ${result.status}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/task": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get tasks from session with cursor-based pagination",
+        "/sandbox/{sandbox_id}/exec": {
+            "post": {
+                "description": "Execute a shell command in the specified sandbox",
                 "consumes": [
                     "application/json"
                 ],
@@ -4048,36 +4841,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "sandbox"
                 ],
-                "summary": "Get tasks from session",
+                "summary": "Execute command in sandbox",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
+                        "description": "Sandbox ID",
+                        "name": "sandbox_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit of tasks to return, default 20. Max 200.",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
-                        "name": "time_desc",
-                        "in": "query"
+                        "description": "Command to execute",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ExecCommandReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -4092,7 +4874,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.GetTasksOutput"
+                                            "$ref": "#/definitions/httpclient.SandboxCommandOutput"
                                         }
                                     }
                                 }
@@ -4100,28 +4882,28 @@ const docTemplate = `{
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get tasks from a session\ntasks = client.sessions.get_tasks(\n    session_id='session-uuid',\n    limit=20,\n    time_desc=False\n)\nprint(f\"Found {len(tasks.items)} tasks\")\nfor task in tasks.items:\n    print(f\"Task {task.id}: {task.status}\")\n\n# If there are more tasks, use the cursor for pagination\nif tasks.has_more:\n    next_tasks = client.sessions.get_tasks(\n        session_id='session-uuid',\n        limit=20,\n        cursor=tasks.next_cursor\n    )\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Execute a command in the sandbox\nresult = client.sandboxes.exec_command(\n    sandbox_id='sandbox-uuid',\n    command='ls -la'\n)\nprint(f\"stdout: {result.stdout}\")\nprint(f\"stderr: {result.stderr}\")\nprint(f\"exit_code: {result.exit_code}\")\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get tasks from a session\nconst tasks = await client.sessions.getTasks('session-uuid', {\n  limit: 20,\n  timeDesc: false\n});\nconsole.log(` + "`" + `Found ${tasks.items.length} tasks` + "`" + `);\nfor (const task of tasks.items) {\n  console.log(` + "`" + `Task ${task.id}: ${task.status}` + "`" + `);\n}\n\n// If there are more tasks, use the cursor for pagination\nif (tasks.hasMore) {\n  const nextTasks = await client.sessions.getTasks('session-uuid', {\n    limit: 20,\n    cursor: tasks.nextCursor\n  });\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Execute a command in the sandbox\nconst result = await client.sandboxes.execCommand({\n  sandboxId: 'sandbox-uuid',\n  command: 'ls -la'\n});\nconsole.log(` + "`" + `stdout: ${result.stdout}` + "`" + `);\nconsole.log(` + "`" + `stderr: ${result.stderr}` + "`" + `);\nconsole.log(` + "`" + `exit_code: ${result.exit_code}` + "`" + `);\n"
                     }
                 ]
             }
         },
-        "/session/{session_id}/token_counts": {
+        "/session": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get total token counts for all text and tool-call parts in a session",
+                "description": "Get all sessions under a project, optionally filtered by user, configs, time ranges, message counts, task status, learning space membership, tags, title or archive state",
                 "consumes": [
                     "application/json"
                 ],
@@ -4131,86 +4913,140 @@ const docTemplate = `{
                 "tags": [
                     "session"
                 ],
-                "summary": "Get token counts for session",
+                "summary": "Get sessions",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "example": "alice@acontext.io",
+                        "description": "User identifier to filter sessions",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON-encoded object for JSONB containment filter. Example: {\\",
+                        "name": "filter_by_configs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON-encoded array of conditions on top-level configs keys. op is one of exists, not_exists, eq, ne, gt, gte, lt, lte. Example: [{\\",
+                        "name": "config_conditions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only sessions created at or after this RFC3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only sessions created before this RFC3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only sessions updated at or after this RFC3339 time",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only sessions updated before this RFC3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only sessions with (true) or without (false) messages",
+                        "name": "has_messages",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only sessions with at least this many messages",
+                        "name": "min_messages",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only sessions with at most this many messages",
+                        "name": "max_messages",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failed",
+                            "running",
+                            "pending"
+                        ],
+                        "type": "string",
+                        "description": "Only sessions having at least one task in this status",
+                        "name": "task_status",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Session ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/serializer.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/handler.TokenCountsResp"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    }
-                },
-                "x-code-samples": [
+                        "description": "Only sessions that belong to this learning space",
+                        "name": "learning_space_id",
+                        "in": "query"
+                    },
                     {
-                        "label": "Python",
-                        "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get token counts\nresult = client.sessions.get_token_counts(session_id='session-uuid')\nprint(f\"Total tokens: {result.total_tokens}\")\n"
+                        "type": "boolean",
+                        "description": "Only sessions that belong (true) or do not belong (false) to any learning space",
+                        "name": "in_learning_space",
+                        "in": "query"
                     },
                     {
-                        "label": "JavaScript",
-                        "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get token counts\nconst result = await client.sessions.getTokenCounts('session-uuid');\nconsole.log(` + "`" + `Total tokens: ${result.total_tokens}` + "`" + `);\n"
-                    }
-                ]
-            }
-        },
-        "/user/ls": {
-            "get": {
-                "security": [
+                        "type": "string",
+                        "example": "travel,draft",
+                        "description": "Comma-separated tags; only sessions carrying all of them",
+                        "name": "tags",
+                        "in": "query"
+                    },
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all users under a project. If limit is not provided or 0, all users will be returned.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "user"
-                ],
-                "summary": "List users",
-                "parameters": [
+                        "type": "string",
+                        "description": "Case-insensitive substring match on the session title",
+                        "name": "title_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only archived (true) or unarchived (false) sessions; omit to include both",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "updated_at",
+                            "last_message_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, default created_at. last_message_at falls back to created_at for sessions without messages.",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Limit of users to return. Max 200. If limit is 0 or not provided, all users will be returned.",
+                        "description": "Limit of sessions to return, default 20. Max 200.",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page, with the same sort_by.",
                         "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "boolean",
                         "example": false,
-                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "description": "Order by the sort key descending if true, ascending if false (default false)",
                         "name": "time_desc",
                         "in": "query"
                     }
@@ -4227,36 +5063,115 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.ListUsersOutput"
+                                            "$ref": "#/definitions/service.ListSessionsOutput"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all users\nusers = client.users.list()\nfor user in users.items:\n    print(f\"{user.identifier}: {user.id}\")\n\n# List users with pagination\nusers = client.users.list(limit=20, time_desc=True)\nif users.has_more:\n    next_users = client.users.list(limit=20, cursor=users.next_cursor)\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List sessions\nsessions = client.sessions.list(\n    limit=20,\n    time_desc=True\n)\nfor session in sessions.items:\n    print(f\"{session.id}\")\n\n# List sessions for a specific user\nsessions = client.sessions.list(user='alice@acontext.io', limit=20)\n\n# List sessions filtered by configs\nsessions = client.sessions.list(\n    limit=20,\n    filter_by_configs={\"agent\": \"bot1\"}\n)\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all users\nconst users = await client.users.list();\nfor (const user of users.items) {\n  console.log(` + "`" + `${user.identifier}: ${user.id}` + "`" + `);\n}\n\n// List users with pagination\nconst paginatedUsers = await client.users.list({ limit: 20, timeDesc: true });\nif (paginatedUsers.hasMore) {\n  const nextUsers = await client.users.list({ limit: 20, cursor: paginatedUsers.nextCursor });\n}\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List sessions\nconst sessions = await client.sessions.list({\n  limit: 20,\n  timeDesc: true\n});\nfor (const session of sessions.items) {\n  console.log(` + "`" + `${session.id}` + "`" + `);\n}\n\n// List sessions for a specific user\nconst userSessions = await client.sessions.list({ user: 'alice@acontext.io', limit: 20 });\n\n// List sessions filtered by configs\nconst filteredSessions = await client.sessions.list({\n  limit: 20,\n  filterByConfigs: { agent: 'bot1' }\n});\n"
                     }
                 ]
-            }
-        },
-        "/user/{identifier}": {
-            "delete": {
+            },
+            "post": {
+                "description": "Create a new session. Optionally associate with a user identifier. You can also specify a custom UUID using use_uuid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Create session",
+                "parameters": [
+                    {
+                        "description": "CreateSession payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateSessionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Session"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid UUID format",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Session with this UUID already exists",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a user by identifier and cascade delete all associated resources (Session, Disk, Skill)",
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Create a session\nsession = client.sessions.create()\nprint(f\"Created session: {session.id}\")\n\n# Create a session for a specific user\nsession = client.sessions.create(user='alice@acontext.io')\n\n# Create a session with a specific UUID\nsession = client.sessions.create(use_uuid='123e4567-e89b-12d3-a456-426614174000')\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Create a session\nconst session = await client.sessions.create();\nconsole.log(` + "`" + `Created session: ${session.id}` + "`" + `);\n\n// Create a session for a specific user\nconst userSession = await client.sessions.create({ user: 'alice@acontext.io' });\n\n// Create a session with a specific UUID\nconst customSession = await client.sessions.create({ useUuid: '123e4567-e89b-12d3-a456-426614174000' });\n"
+                    }
+                ]
+            }
+        },
+        "/session/archive": {
+            "post": {
+                "description": "Archive or unarchive multiple sessions at once. Sessions that do not belong to the project are ignored. Returns the number of sessions updated.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4264,48 +5179,137 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "session"
                 ],
-                "summary": "Delete user",
+                "summary": "Bulk archive sessions",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User identifier string",
-                        "name": "identifier",
-                        "in": "path",
-                        "required": true
+                        "description": "BulkArchiveSessions payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkArchiveSessionsReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BulkUpdateSessionsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/serializer.Response"
                         }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a user and all associated resources\nclient.users.delete('alice@acontext.io')\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Archive several sessions at once\nresult = client.sessions.bulk_archive(\n    session_ids=['session-uuid-1', 'session-uuid-2'],\n    archived=True\n)\nprint(result.updated)\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a user and all associated resources\nawait client.users.delete('alice@acontext.io');\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Archive several sessions at once\nconst result = await client.sessions.bulkArchive({\n  sessionIds: ['session-uuid-1', 'session-uuid-2'],\n  archived: true\n});\nconsole.log(result.updated);\n"
                     }
                 ]
             }
         },
-        "/user/{identifier}/resources": {
-            "get": {
+        "/session/bulk": {
+            "post": {
+                "description": "Start an asynchronous job applying one action (delete, archive, patch_configs, copy or learn) to sessions selected by an explicit session_ids list or by a filter on user, configs and time range. Returns the job, whose status and per-item results can be polled via GET /session/bulk/{job_id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Run a bulk session operation",
+                "parameters": [
+                    {
+                        "description": "BulkSessions payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkSessionsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SessionBulkJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the resource counts (Sessions, Disks, Skills) associated with a user",
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete all sessions of a test user\njob = client.sessions.bulk(\n    action='delete',\n    filter={'user': 'test@acontext.io'}\n)\n\n# Poll for progress\njob = client.sessions.get_bulk_job(job.id)\nprint(f\"{job.status}: {job.succeeded}/{job.total}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete all sessions of a test user\nlet job = await client.sessions.bulk({\n  action: 'delete',\n  filter: { user: 'test@acontext.io' }\n});\n\n// Poll for progress\njob = await client.sessions.getBulkJob(job.id);\nconsole.log(` + "`" + `${job.status}: ${job.succeeded}/${job.total}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/session/bulk/{job_id}": {
+            "get": {
+                "description": "Get the status, progress counters and per-item results of a bulk session job.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4313,14 +5317,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "session"
                 ],
-                "summary": "Get user resources",
+                "summary": "Get bulk session job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User identifier string",
-                        "name": "identifier",
+                        "format": "uuid",
+                        "description": "Bulk job ID",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
@@ -4337,132 +5342,2902 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/service.GetUserResourcesOutput"
+                                            "$ref": "#/definitions/model.SessionBulkJob"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "x-code-samples": [
                     {
                         "label": "Python",
                         "lang": "python",
-                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get user resource counts\nresources = client.users.get_resources('alice@acontext.io')\nprint(f\"Sessions: {resources.counts.sessions_count}\")\nprint(f\"Disks: {resources.counts.disks_count}\")\nprint(f\"Skills: {resources.counts.skills_count}\")\n"
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\njob = client.sessions.get_bulk_job('job-uuid')\nfor item in job.results:\n    if item.status == 'failed':\n        print(item.session_id, item.error)\n"
                     },
                     {
                         "label": "JavaScript",
                         "lang": "javascript",
-                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get user resource counts\nconst resources = await client.users.getResources('alice@acontext.io');\nconsole.log(` + "`" + `Sessions: ${resources.counts.sessions_count}` + "`" + `);\nconsole.log(` + "`" + `Disks: ${resources.counts.disks_count}` + "`" + `);\nconsole.log(` + "`" + `Skills: ${resources.counts.skills_count}` + "`" + `);\n"
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\nconst job = await client.sessions.getBulkJob('job-uuid');\nfor (const item of job.results) {\n  if (item.status === 'failed') console.log(item.session_id, item.error);\n}\n"
                     }
                 ]
             }
-        }
-    },
-    "definitions": {
-        "converter.GetMessagesOutput": {
-            "type": "object",
-            "properties": {
-                "edit_at_message_id": {
-                    "description": "Message ID where edit strategies were applied",
-                    "type": "string"
-                },
-                "events": {
-                    "description": "Session events within the messages time window",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.SessionEvent"
+        },
+        "/session/tags": {
+            "post": {
+                "description": "Add and/or remove tags on multiple sessions at once. Sessions that do not belong to the project are ignored. Returns the number of sessions updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Bulk tag sessions",
+                "parameters": [
+                    {
+                        "description": "BulkTagSessions payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkTagSessionsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BulkUpdateSessionsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
                     }
                 },
-                "has_more": {
-                    "description": "Whether there are more messages",
-                    "type": "boolean"
-                },
-                "ids": {
-                    "description": "Message IDs corresponding to items",
-                    "type": "array",
-                    "items": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Tag several sessions at once\nresult = client.sessions.bulk_tag(\n    session_ids=['session-uuid-1', 'session-uuid-2'],\n    add=['travel'],\n    remove=['draft']\n)\nprint(result.updated)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Tag several sessions at once\nconst result = await client.sessions.bulkTag({\n  sessionIds: ['session-uuid-1', 'session-uuid-2'],\n  add: ['travel'],\n  remove: ['draft']\n});\nconsole.log(result.updated);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}": {
+            "delete": {
+                "description": "Delete a session by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Delete session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a session\nclient.sessions.delete(session_id='session-uuid')\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a session\nawait client.sessions.delete('session-uuid');\n"
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Overwrite the title, tags and/or archived flag of a session. Omitted fields are left unchanged; tags replaces the whole tag set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Update session title, tags or archive state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateSession payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateSessionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Session"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rename and tag a session\nsession = client.sessions.update(\n    session_id='session-uuid',\n    title='Trip planning',\n    tags=['travel', 'draft']\n)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rename and tag a session\nconst session = await client.sessions.update('session-uuid', {\n  title: 'Trip planning',\n  tags: ['travel', 'draft']\n});\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/asset/download": {
+            "get": {
+                "description": "Download a session asset (file attachment) by its S3 key. Decrypts if encryption is enabled.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Download session asset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "S3 key of the asset",
+                        "name": "s3_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Asset content"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/configs": {
+            "get": {
+                "description": "Get session configs by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get session configs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Session"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get session configs\nsession = client.sessions.get_configs(session_id='session-uuid')\nprint(session.configs)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get session configs\nconst session = await client.sessions.getConfigs('session-uuid');\nconsole.log(session.configs);\n"
+                    }
+                ]
+            },
+            "put": {
+                "description": "Update session configs by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Update session configs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateSessionConfigs payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateSessionConfigsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Update session configs\nclient.sessions.update_configs(\n    session_id='session-uuid'\n)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Update session configs\nawait client.sessions.updateConfigs('session-uuid');\n"
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Update session configs using patch semantics. Only updates keys present in the request. Pass null as value to delete a key. Returns the complete configs after patch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Patch session configs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "PatchSessionConfigs payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PatchSessionConfigsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.PatchSessionConfigsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Patch session configs (adds/updates keys, use None to delete)\nupdated_configs = client.sessions.patch_configs(\n    session_id='session-uuid',\n    configs={'agent': 'bot2', 'old_key': None}  # None deletes the key\n)\nprint(updated_configs)  # {'existing_key': 'value', 'agent': 'bot2'}\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Patch session configs (adds/updates keys, use null to delete)\nconst updatedConfigs = await client.sessions.patchConfigs(\n  'session-uuid',\n  { agent: 'bot2', old_key: null }  // null deletes the key\n);\nconsole.log(updatedConfigs);  // { existing_key: 'value', agent: 'bot2' }\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/copy": {
+            "post": {
+                "description": "Create a complete copy of a session with all its messages and tasks. The copied session will be independent and can be modified without affecting the original.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Copy session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.CopySessionResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid session ID",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Session exceeds maximum copyable size",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to copy session",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Copy a session\nresult = client.sessions.copy(session_id='session-uuid')\nprint(f\"Copied session: {result.new_session_id}\")\nprint(f\"Original session: {result.old_session_id}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Copy a session\nconst result = await client.sessions.copy('session-uuid');\nconsole.log(` + "`" + `Copied session: ${result.newSessionId}` + "`" + `);\nconsole.log(` + "`" + `Original session: ${result.oldSessionId}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/events": {
+            "get": {
+                "description": "Get events for a session with cursor-based pagination.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get events for session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of events to return, default 50. Max 200.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ListEventsOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get events for a session\nevents = client.sessions.get_events(session_id, limit=50)\nfor event in events.items:\n    print(f\"{event.type}: {event.data}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get events for a session\nconst events = await client.sessions.getEvents(sessionId, { limit: 50 });\nfor (const event of events.items) {\n  console.log(` + "`" + `${event.type}: ${JSON.stringify(event.data)}` + "`" + `);\n}\n"
+                    }
+                ]
+            },
+            "post": {
+                "description": "Add a structured event to a session. Events are stored alongside messages and can be retrieved chronologically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Add event to session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "AddEvent payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddEventReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SessionEvent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\nfrom acontext.event import DiskEvent, TextEvent\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Add a disk event\nclient.sessions.add_event(session_id, DiskEvent(disk_id='xxxx', path='/data/report.csv', note='Uploaded report'))\n\n# Add a text event\nclient.sessions.add_event(session_id, TextEvent(text='User switched to dark mode'))\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient, DiskEvent, TextEvent } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Add a disk event\nawait client.sessions.addEvent(sessionId, new DiskEvent({ diskId: 'xxxx', path: '/data/report.csv', note: 'Uploaded report' }));\n\n// Add a text event\nawait client.sessions.addEvent(sessionId, new TextEvent({ text: 'User switched to dark mode' }));\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/feedback": {
+            "get": {
+                "description": "List feedback submitted for the messages of a session with cursor-based pagination, together with a summary (counts, thumbs totals, average score and label counts) over all matching feedback.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get session feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Only feedback for this message",
+                        "name": "message_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only feedback submitted by this user identifier",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "up",
+                            "down"
+                        ],
+                        "type": "string",
+                        "description": "Only feedback with this thumbs value",
+                        "name": "thumbs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated labels; only feedback carrying all of them",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only feedback submitted at or after this RFC3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only feedback submitted before this RFC3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of feedback to return, default 50. Max 200.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ListFeedbackOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\nresult = client.sessions.get_feedback(session_id='session-uuid')\nprint(f\"{result.summary.thumbs_up} up / {result.summary.thumbs_down} down\")\nfor fb in result.items:\n    print(fb.message_id, fb.thumbs, fb.comment)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\nconst result = await client.sessions.getFeedback('session-uuid');\nconsole.log(` + "`" + `${result.summary.thumbs_up} up / ${result.summary.thumbs_down} down` + "`" + `);\nfor (const fb of result.items) {\n  console.log(fb.message_id, fb.thumbs, fb.comment);\n}\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/flush": {
+            "post": {
+                "description": "Flush the session buffer for a given session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Flush session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/httpclient.FlagResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Flush session buffer\nresult = client.sessions.flush(session_id='session-uuid')\nprint(result.status)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Flush session buffer\nconst result = await client.sessions.flush('session-uuid');\nconsole.log(result.status);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/messages": {
+            "get": {
+                "description": "Get messages from session. Default format is openai. Can convert to acontext (original), anthropic, or gemini format.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get messages from session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of messages to return. Max 200. If limit is 0 or not provided, all messages will be returned. \n\nWARNING!\n Use ` + "`" + `limit` + "`" + ` only for read-only/display purposes (pagination, viewing). Do NOT use ` + "`" + `limit` + "`" + ` to truncate messages before sending to LLM as it may cause tool-call and tool-result unpairing issues. Instead, use the ` + "`" + `token_limit` + "`" + ` edit strategy in ` + "`" + `edit_strategies` + "`" + ` parameter to safely manage message context size.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "description": "Whether to return asset public url, default is true",
+                        "name": "with_asset_public_url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Whether to include session events in the response, default is false",
+                        "name": "with_events",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "acontext",
+                            "openai",
+                            "anthropic",
+                            "gemini"
+                        ],
+                        "type": "string",
+                        "description": "Format to convert messages to: acontext (original), openai (default), anthropic, gemini.",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "[{\"type\":\"remove_tool_result\",\"params\":{\"keep_recent_n_tool_results\":3}}]",
+                        "description": "JSON array of edit strategies to apply before format conversion",
+                        "name": "edit_strategies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "",
+                        "description": "Message ID to pin editing strategies at. When provided, strategies are only applied to messages up to and including this message ID, keeping subsequent messages unchanged. This helps maintain prompt cache stability by preserving a stable prefix. The response will include edit_at_message_id indicating where strategies were applied.",
+                        "name": "pin_editing_strategies_at_message",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/converter.GetMessagesOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get messages from session\nmessages = client.sessions.get_messages(\n    session_id='session-uuid',\n    limit=50,\n    format='acontext',\n    time_desc=True\n)\nfor message in messages.items:\n    print(f\"{message.role}: {message.parts}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get messages from session\nconst messages = await client.sessions.getMessages('session-uuid', {\n  limit: 50,\n  format: 'acontext',\n  timeDesc: true\n});\nfor (const message of messages.items) {\n  console.log(` + "`" + `${message.role}: ${JSON.stringify(message.parts)}` + "`" + `);\n}\n"
+                    }
+                ]
+            },
+            "post": {
+                "description": "Supports JSON and multipart/form-data. In multipart mode: the payload is a JSON string placed in a form field. The format parameter indicates the format of the input message (default: openai, same as GET). The blob field should be a complete message object: for openai, use OpenAI ChatCompletionMessageParam format (with role and content); for anthropic, use Anthropic MessageParam format (with role and content); for acontext (internal), use {role, parts} format. The optional meta field allows attaching user-provided metadata to the message, which can be retrieved via get_messages().metas or updated via patch_message_meta().",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Store message to session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "StoreMessage payload (Content-Type: application/json)",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.StoreMessageReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "StoreMessage payload (Content-Type: multipart/form-data)",
+                        "name": "payload",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "When uploading files, the field name must correspond to parts[*].file_field.",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Message"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\nfrom acontext.messages import build_acontext_message\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Store a message in OpenAI format with user metadata\nclient.sessions.store_message(\n    session_id='session-uuid',\n    blob={'role': 'user', 'content': 'Hello!'},\n    format='openai',\n    meta={'source': 'web', 'request_id': 'abc123'}\n)\n\n# Store a message in Acontext format\nmessage = build_acontext_message(role='user', parts=['Hello!'])\nclient.sessions.store_message(\n    session_id='session-uuid',\n    blob=message,\n    format='acontext'\n)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient, MessagePart } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Store a message in OpenAI format with user metadata\nawait client.sessions.storeMessage(\n  'session-uuid',\n  { role: 'user', content: 'Hello!' },\n  { format: 'openai', meta: { source: 'web', request_id: 'abc123' } }\n);\n\n// Store a message in Acontext format\nawait client.sessions.storeMessage(\n  'session-uuid',\n  {\n    role: 'user',\n    parts: [MessagePart.textPart('Hello!')]\n  },\n  { format: 'acontext' }\n);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/messages/{message_id}/feedback": {
+            "post": {
+                "description": "Record a rating for a message: thumbs up/down, a score between 0 and 1, a free-form comment and labels. Every submission is stored as its own record, so concurrent raters never overwrite each other. At least one of thumbs, score, comment or labels is required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Submit message feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Message ID",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SubmitFeedback payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SubmitFeedbackReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.MessageFeedback"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or message not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rate an assistant message\nfeedback = client.sessions.submit_feedback(\n    session_id='session-uuid',\n    message_id='message-uuid',\n    user='alice@acontext.io',\n    thumbs='down',\n    score=0.4,\n    comment='Accurate, but too verbose',\n    labels=['verbose']\n)\nprint(feedback.id)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rate an assistant message\nconst feedback = await client.sessions.submitFeedback('session-uuid', 'message-uuid', {\n  user: 'alice@acontext.io',\n  thumbs: 'down',\n  score: 0.4,\n  comment: 'Accurate, but too verbose',\n  labels: ['verbose']\n});\nconsole.log(feedback.id);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/messages/{message_id}/meta": {
+            "patch": {
+                "description": "Update message metadata using patch semantics. Only updates keys present in the request. Pass null as value to delete a key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Patch message metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Message ID",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "PatchMessageMeta payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PatchMessageMetaReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.PatchMessageMetaResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Patch message meta (adds/updates keys, use None to delete)\nupdated_meta = client.sessions.patch_message_meta(\n    session_id='session-uuid',\n    message_id='message-uuid',\n    meta={'status': 'processed', 'old_key': None}  # None deletes the key\n)\nprint(updated_meta)  # {'existing_key': 'value', 'status': 'processed'}\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Patch message meta (adds/updates keys, use null to delete)\nconst updatedMeta = await client.sessions.patchMessageMeta(\n  'session-uuid',\n  'message-uuid',\n  { status: 'processed', old_key: null }  // null deletes the key\n);\nconsole.log(updatedMeta);  // { existing_key: 'value', status: 'processed' }\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/observing_status": {
+            "get": {
+                "description": "Returns the count of observed, in_process, and pending messages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get message observing status for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.MessageObservingStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get message observing status\nresult = client.sessions.messages_observing_status(session_id='session-uuid')\nprint(f\"Observed: {result.observed}, In Process: {result.in_process}, Pending: {result.pending}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get message observing status\nconst result = await client.sessions.messagesObservingStatus('session-uuid');\nconsole.log(` + "`" + `Observed: ${result.observed}, In Process: ${result.in_process}, Pending: ${result.pending}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/pending_uploads": {
+            "get": {
+                "description": "List the assets of the session's messages that are not in storage yet: their parts JSON and files are uploaded after the message is stored and retried with backoff when an upload fails. Until then they are still read from the queue. Each entry shows its status (pending, or failed once it ran out of attempts), the attempts made, the last error and when it is retried next. An empty list means everything is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get pending message asset uploads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.PendingAssetUpload"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/stats": {
+            "get": {
+                "description": "Get aggregated statistics of a session: message counts by role, part type histogram, tool-call counts by tool name, tool error rate, total and per-role tokens, asset bytes, first/last message time and task counts by status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get session stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.SessionStatsOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get session stats\nstats = client.sessions.get_stats(session_id='session-uuid')\nprint(f\"Messages: {stats.message_count}, Tool error rate: {stats.tool_error_rate}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get session stats\nconst stats = await client.sessions.getStats('session-uuid');\nconsole.log(` + "`" + `Messages: ${stats.message_count}, Tool error rate: ${stats.tool_error_rate}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/task": {
+            "get": {
+                "description": "Get tasks from session with cursor-based pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Get tasks from session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of tasks to return, default 20. Max 200.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.GetTasksOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get tasks from a session\ntasks = client.sessions.get_tasks(\n    session_id='session-uuid',\n    limit=20,\n    time_desc=False\n)\nprint(f\"Found {len(tasks.items)} tasks\")\nfor task in tasks.items:\n    print(f\"Task {task.id}: {task.status}\")\n\n# If there are more tasks, use the cursor for pagination\nif tasks.has_more:\n    next_tasks = client.sessions.get_tasks(\n        session_id='session-uuid',\n        limit=20,\n        cursor=tasks.next_cursor\n    )\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get tasks from a session\nconst tasks = await client.sessions.getTasks('session-uuid', {\n  limit: 20,\n  timeDesc: false\n});\nconsole.log(` + "`" + `Found ${tasks.items.length} tasks` + "`" + `);\nfor (const task of tasks.items) {\n  console.log(` + "`" + `Task ${task.id}: ${task.status}` + "`" + `);\n}\n\n// If there are more tasks, use the cursor for pagination\nif (tasks.hasMore) {\n  const nextTasks = await client.sessions.getTasks('session-uuid', {\n    limit: 20,\n    cursor: tasks.nextCursor\n  });\n}\n"
+                    }
+                ]
+            }
+        },
+        "/session/{session_id}/token_counts": {
+            "get": {
+                "description": "Get total token counts for all text and tool-call parts in a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get token counts for session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.TokenCountsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get token counts\nresult = client.sessions.get_token_counts(session_id='session-uuid')\nprint(f\"Total tokens: {result.total_tokens}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get token counts\nconst result = await client.sessions.getTokenCounts('session-uuid');\nconsole.log(` + "`" + `Total tokens: ${result.total_tokens}` + "`" + `);\n"
+                    }
+                ]
+            }
+        },
+        "/upload": {
+            "post": {
+                "description": "Start a resumable upload of a file of the given size. Send its bytes in order with PATCH /upload/{upload_id}, which may be repeated or resumed after a failure, then complete it with POST /upload/{upload_id}/complete. A completed upload is attached by passing its ID as upload_id to POST /disk/{disk_id}/artifact (target \"disk\") or in a message part (target \"message\").",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Create resumable upload",
+                "parameters": [
+                    {
+                        "description": "CreateUpload payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateUploadReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Upload"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "File size exceeds maximum allowed size",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/upload/{upload_id}": {
+            "get": {
+                "description": "Get the status of a resumable upload. Its offset is where the next append must start, so a client resumes an interrupted upload from it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Get resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Upload"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Upload-Offset": {
+                                "type": "integer",
+                                "description": "Bytes stored so far"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Append the request body to a resumable upload, starting at the offset given in the Upload-Offset header, which must be the upload's current offset. The body is stored in whole parts of the upload's part_size; bytes after the last whole part are discarded unless they end the file, so a body of any length may be sent and the returned offset says where to continue. An append cut short by a network failure keeps every part it completed.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Append to resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset the body starts at",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Upload"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Upload-Offset": {
+                                "type": "integer",
+                                "description": "Bytes stored so far"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or the upload is already completed",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Upload-Offset is not the upload's offset",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Discard a resumable upload and the bytes stored for it. Files already attached from a completed upload are not affected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Abort resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/upload/{upload_id}/complete": {
+            "post": {
+                "description": "Assemble a resumable upload whose bytes have all been appended. The file is stored content-addressed like any other upload, and the upload can then be attached by its ID until it expires; if nothing attached it by then, the file is deleted. Completing a completed upload returns it unchanged, so the request is safe to retry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Complete resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Upload"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Not all bytes of the file were received",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/user/ls": {
+            "get": {
+                "description": "Get all users under a project. If limit is not provided or 0, all users will be returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit of users to return. Max 200. If limit is 0 or not provided, all users will be returned.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination. Use the cursor from the previous response to get the next page.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "description": "Order by created_at descending if true, ascending if false (default false)",
+                        "name": "time_desc",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ListUsersOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List all users\nusers = client.users.list()\nfor user in users.items:\n    print(f\"{user.identifier}: {user.id}\")\n\n# List users with pagination\nusers = client.users.list(limit=20, time_desc=True)\nif users.has_more:\n    next_users = client.users.list(limit=20, cursor=users.next_cursor)\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List all users\nconst users = await client.users.list();\nfor (const user of users.items) {\n  console.log(` + "`" + `${user.identifier}: ${user.id}` + "`" + `);\n}\n\n// List users with pagination\nconst paginatedUsers = await client.users.list({ limit: 20, timeDesc: true });\nif (paginatedUsers.hasMore) {\n  const nextUsers = await client.users.list({ limit: 20, cursor: paginatedUsers.nextCursor });\n}\n"
+                    }
+                ]
+            }
+        },
+        "/user/{identifier}": {
+            "delete": {
+                "description": "Delete a user by identifier and cascade delete all associated resources (Session, Disk, Skill)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User identifier string",
+                        "name": "identifier",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializer.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a user and all associated resources\nclient.users.delete('alice@acontext.io')\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a user and all associated resources\nawait client.users.delete('alice@acontext.io');\n"
+                    }
+                ]
+            }
+        },
+        "/user/{identifier}/resources": {
+            "get": {
+                "description": "Get the resource counts (Sessions, Disks, Skills) associated with a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get user resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User identifier string",
+                        "name": "identifier",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/serializer.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.GetUserResourcesOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "x-code-samples": [
+                    {
+                        "label": "Python",
+                        "lang": "python",
+                        "source": "from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get user resource counts\nresources = client.users.get_resources('alice@acontext.io')\nprint(f\"Sessions: {resources.counts.sessions_count}\")\nprint(f\"Disks: {resources.counts.disks_count}\")\nprint(f\"Skills: {resources.counts.skills_count}\")\n"
+                    },
+                    {
+                        "label": "JavaScript",
+                        "lang": "javascript",
+                        "source": "import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get user resource counts\nconst resources = await client.users.getResources('alice@acontext.io');\nconsole.log(` + "`" + `Sessions: ${resources.counts.sessions_count}` + "`" + `);\nconsole.log(` + "`" + `Disks: ${resources.counts.disks_count}` + "`" + `);\nconsole.log(` + "`" + `Skills: ${resources.counts.skills_count}` + "`" + `);\n"
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "converter.GetMessagesOutput": {
+            "type": "object",
+            "properties": {
+                "edit_at_message_id": {
+                    "description": "Message ID where edit strategies were applied",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Session events within the messages time window",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SessionEvent"
+                    }
+                },
+                "has_more": {
+                    "description": "Whether there are more messages",
+                    "type": "boolean"
+                },
+                "ids": {
+                    "description": "Message IDs corresponding to items",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "items": {
+                    "description": "Messages in the requested format"
+                },
+                "metas": {
+                    "description": "User-provided metadata for each message (same order as items/ids)",
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                },
+                "next_cursor": {
+                    "description": "Cursor for pagination",
+                    "type": "string"
+                },
+                "public_urls": {
+                    "description": "Asset public URLs (only for acontext format)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.PublicURL"
+                    }
+                },
+                "this_time_tokens": {
+                    "description": "Token count for returned messages",
+                    "type": "integer"
+                }
+            }
+        },
+        "fileparser.FileContent": {
+            "type": "object",
+            "properties": {
+                "raw": {
+                    "description": "Raw text content, or the text extracted from a document",
+                    "type": "string"
+                },
+                "truncated": {
+                    "description": "extracted text was cut off at MaxExtractedTextSize (4MB)",
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "\"text\", \"json\", \"csv\", \"code\", or \"pdf\", \"docx\", \"xlsx\", \"pptx\", \"html\" for extracted text",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AddEventReq": {
+            "type": "object"
+        },
+        "handler.ArtifactLeaseReq": {
+            "type": "object",
+            "required": [
+                "owner",
+                "path"
+            ],
+            "properties": {
+                "owner": {
+                    "description": "Token identifying the holder; send it as X-Lease-Owner on writes",
+                    "type": "string",
+                    "example": "agent-7f3a"
+                },
+                "path": {
+                    "description": "File path, or directory path ending with '/' to lock everything below it",
+                    "type": "string",
+                    "example": "/notes/"
+                },
+                "ttl_seconds": {
+                    "description": "Lease duration, defaults to 60 seconds, at most 3600",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 60
+                }
+            }
+        },
+        "handler.BulkArchiveSessionsReq": {
+            "type": "object",
+            "required": [
+                "archived",
+                "session_ids"
+            ],
+            "properties": {
+                "archived": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "session_ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123e4567-e89b-12d3-a456-426614174000"
+                    ]
+                }
+            }
+        },
+        "handler.BulkSessionsFilterReq": {
+            "type": "object",
+            "properties": {
+                "created_after": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "created_before": {
+                    "type": "string",
+                    "example": "2025-02-01T00:00:00Z"
+                },
+                "filter_by_configs": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "updated_after": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "updated_before": {
+                    "type": "string",
+                    "example": "2025-02-01T00:00:00Z"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.BulkSessionsReq": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "archive",
+                        "patch_configs",
+                        "copy",
+                        "learn"
+                    ],
+                    "example": "delete"
+                },
+                "archived": {
+                    "description": "archive: false unarchives (default true)",
+                    "type": "boolean",
+                    "example": true
+                },
+                "configs": {
+                    "description": "patch_configs: keys to patch, null deletes a key",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "filter": {
+                    "$ref": "#/definitions/handler.BulkSessionsFilterReq"
+                },
+                "learning_space_id": {
+                    "description": "learn: target learning space",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "session_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123e4567-e89b-12d3-a456-426614174000"
+                    ]
+                }
+            }
+        },
+        "handler.BulkTagSessionsReq": {
+            "type": "object",
+            "required": [
+                "session_ids"
+            ],
+            "properties": {
+                "add": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "travel"
+                    ]
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "draft"
+                    ]
+                },
+                "session_ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123e4567-e89b-12d3-a456-426614174000"
+                    ]
+                }
+            }
+        },
+        "handler.BulkUpdateSessionsResp": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CheckAssetRefsReq": {
+            "type": "object",
+            "properties": {
+                "grace_period_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 86400
+                },
+                "repair": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handler.CloneDiskReq": {
+            "type": "object",
+            "required": [
+                "snapshot_id"
+            ],
+            "properties": {
+                "snapshot_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "user": {
+                    "description": "Optional user identifier for the new disk",
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.CopySessionResp": {
+            "type": "object",
+            "properties": {
+                "new_session_id": {
+                    "type": "string"
+                },
+                "old_session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateDiskReq": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.CreateDiskSnapshotReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "before-refactor"
+                }
+            }
+        },
+        "handler.CreateLearningSpaceReq": {
+            "type": "object",
+            "properties": {
+                "meta": {
+                    "type": "object"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.CreateProjectReq": {
+            "type": "object",
+            "properties": {
+                "configs": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.CreateSessionReq": {
+            "type": "object",
+            "properties": {
+                "configs": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "disable_task_tracking": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
                         "type": "string"
+                    },
+                    "example": [
+                        "travel",
+                        "draft"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Trip planning"
+                },
+                "use_uuid": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.DeleteArtifactResp": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "Number of artifacts removed by a recursive delete",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.DiskUsageResp": {
+            "type": "object",
+            "properties": {
+                "directories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DirectoryUsage"
+                    }
+                }
+            }
+        },
+        "handler.DownloadSkillToSandboxReq": {
+            "type": "object",
+            "required": [
+                "sandbox_id"
+            ],
+            "properties": {
+                "sandbox_id": {
+                    "description": "Target sandbox ID",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DownloadSkillToSandboxResp": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Skill description",
+                    "type": "string"
+                },
+                "dir_path": {
+                    "description": "Full path to the skill directory in sandbox",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Skill name",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Whether the download was successful",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.DownloadToSandboxReq": {
+            "type": "object",
+            "required": [
+                "file_path",
+                "filename",
+                "sandbox_id",
+                "sandbox_path"
+            ],
+            "properties": {
+                "file_path": {
+                    "description": "File path (directory) of the artifact",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "Filename of the artifact",
+                    "type": "string"
+                },
+                "sandbox_id": {
+                    "description": "Target sandbox ID",
+                    "type": "string"
+                },
+                "sandbox_path": {
+                    "description": "Destination directory in the sandbox",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DownloadToSandboxResp": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.EditArtifactReq": {
+            "type": "object",
+            "required": [
+                "edits",
+                "file_path"
+            ],
+            "properties": {
+                "edits": {
+                    "description": "Operations applied in order; either all apply or none",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/textedit.Edit"
                     }
                 },
-                "items": {
-                    "description": "Messages in the requested format"
+                "file_path": {
+                    "description": "File path including filename",
+                    "type": "string",
+                    "example": "/notes/todo.md"
+                }
+            }
+        },
+        "handler.ExecCommandReq": {
+            "type": "object",
+            "required": [
+                "command"
+            ],
+            "properties": {
+                "command": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GetArtifactResp": {
+            "type": "object",
+            "properties": {
+                "artifact": {
+                    "$ref": "#/definitions/model.Artifact"
                 },
-                "metas": {
-                    "description": "User-provided metadata for each message (same order as items/ids)",
+                "content": {
+                    "$ref": "#/definitions/fileparser.FileContent"
+                },
+                "etag": {
+                    "description": "Entity tag of the content, for If-Match / If-None-Match on later writes",
+                    "type": "string"
+                },
+                "public_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.IncludeSkillReq": {
+            "type": "object",
+            "required": [
+                "skill_id"
+            ],
+            "properties": {
+                "skill_id": {
+                    "type": "string",
+                    "example": "skill-uuid"
+                }
+            }
+        },
+        "handler.LearnReq": {
+            "type": "object",
+            "required": [
+                "session_id"
+            ],
+            "properties": {
+                "session_id": {
+                    "type": "string",
+                    "example": "session-uuid"
+                }
+            }
+        },
+        "handler.ListArtifactVersionsResp": {
+            "type": "object",
+            "properties": {
+                "current_version": {
+                    "type": "integer"
+                },
+                "versions": {
                     "type": "array",
                     "items": {
-                        "type": "object",
-                        "additionalProperties": true
+                        "$ref": "#/definitions/model.ArtifactVersion"
+                    }
+                }
+            }
+        },
+        "handler.ListArtifactsResp": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Artifact"
+                    }
+                },
+                "directories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
+                "has_more": {
+                    "description": "Whether a recursive listing has more pages",
+                    "type": "boolean"
+                },
                 "next_cursor": {
-                    "description": "Cursor for pagination",
+                    "description": "Cursor for the next page of a recursive listing",
                     "type": "string"
+                }
+            }
+        },
+        "handler.PatchMessageMetaReq": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.PatchMessageMetaResp": {
+            "type": "object",
+            "properties": {
+                "meta": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.PatchSessionConfigsReq": {
+            "type": "object",
+            "required": [
+                "configs"
+            ],
+            "properties": {
+                "configs": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.PatchSessionConfigsResp": {
+            "type": "object",
+            "properties": {
+                "configs": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.RestoreArtifactReq": {
+            "type": "object",
+            "required": [
+                "file_path",
+                "version"
+            ],
+            "properties": {
+                "file_path": {
+                    "description": "File path including filename",
+                    "type": "string",
+                    "example": "/notes/todo.md"
                 },
-                "public_urls": {
-                    "description": "Asset public URLs (only for acontext format)",
+                "version": {
+                    "description": "Version to restore",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "handler.StoreMessageReq": {
+            "type": "object",
+            "required": [
+                "blob"
+            ],
+            "properties": {
+                "blob": {},
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "acontext",
+                        "openai",
+                        "anthropic",
+                        "gemini"
+                    ],
+                    "example": "openai"
+                },
+                "meta": {
+                    "description": "Optional user-provided metadata for the message",
                     "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/service.PublicURL"
-                    }
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handler.SubmitFeedbackReq": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Accurate, but too verbose"
                 },
-                "this_time_tokens": {
-                    "description": "Token count for returned messages",
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "verbose"
+                    ]
+                },
+                "score": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.8
+                },
+                "thumbs": {
+                    "type": "string",
+                    "enum": [
+                        "up",
+                        "down"
+                    ],
+                    "example": "up"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "alice@acontext.io"
+                }
+            }
+        },
+        "handler.SweepAssetsReq": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "grace_period_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 86400
+                },
+                "project_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "handler.TokenCountsResp": {
+            "type": "object",
+            "properties": {
+                "total_tokens": {
                     "type": "integer"
                 }
             }
         },
-        "fileparser.FileContent": {
+        "handler.TransferArtifactsReq": {
             "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
             "properties": {
-                "raw": {
-                    "description": "Raw text content",
-                    "type": "string"
+                "dest_disk_id": {
+                    "description": "Optional destination disk in the same project, defaults to the source disk",
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "from": {
+                    "description": "Source file path, or directory path ending with '/'",
+                    "type": "string",
+                    "example": "/notes/todo.md"
                 },
-                "type": {
-                    "description": "\"text\", \"json\", \"csv\", \"code\"",
-                    "type": "string"
+                "on_conflict": {
+                    "description": "What to do when the destination exists, defaults to fail",
+                    "type": "string",
+                    "enum": [
+                        "fail",
+                        "skip",
+                        "overwrite"
+                    ],
+                    "example": "fail"
+                },
+                "to": {
+                    "description": "Destination file path, or directory path ending with '/'",
+                    "type": "string",
+                    "example": "/archive/todo.md"
                 }
             }
         },
-        "handler.AddEventReq": {
-            "type": "object"
-        },
-        "handler.CopySessionResp": {
+        "handler.UpdateArtifactReq": {
             "type": "object",
+            "required": [
+                "file_path",
+                "meta"
+            ],
             "properties": {
-                "new_session_id": {
+                "file_path": {
+                    "description": "File path including filename",
                     "type": "string"
                 },
-                "old_session_id": {
+                "meta": {
+                    "description": "Custom metadata as JSON string",
                     "type": "string"
                 }
             }
         },
-        "handler.CreateDiskReq": {
+        "handler.UpdateArtifactResp": {
             "type": "object",
             "properties": {
-                "user": {
-                    "type": "string",
-                    "example": "alice@acontext.io"
+                "artifact": {
+                    "$ref": "#/definitions/model.Artifact"
                 }
             }
         },
-        "handler.CreateLearningSpaceReq": {
+        "handler.UpdateLearningSpaceReq": {
             "type": "object",
+            "required": [
+                "meta"
+            ],
             "properties": {
                 "meta": {
                     "type": "object"
-                },
-                "user": {
-                    "type": "string",
-                    "example": "alice@acontext.io"
                 }
             }
         },
-        "handler.CreateProjectReq": {
+        "handler.UpdateSessionConfigsReq": {
             "type": "object",
             "properties": {
                 "configs": {
@@ -4471,420 +8246,551 @@ const docTemplate = `{
                 }
             }
         },
-        "handler.CreateSessionReq": {
+        "handler.UpdateSessionReq": {
             "type": "object",
             "properties": {
-                "configs": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "disable_task_tracking": {
+                "archived": {
                     "type": "boolean",
                     "example": false
                 },
-                "use_uuid": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                "tags": {
+                    "description": "replaces the full tag set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "travel",
+                        "draft"
+                    ]
                 },
-                "user": {
+                "title": {
                     "type": "string",
-                    "example": "alice@acontext.io"
-                }
-            }
-        },
-        "handler.DownloadSkillToSandboxReq": {
-            "type": "object",
-            "required": [
-                "sandbox_id"
-            ],
-            "properties": {
-                "sandbox_id": {
-                    "description": "Target sandbox ID",
-                    "type": "string"
-                }
-            }
-        },
-        "handler.DownloadSkillToSandboxResp": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "description": "Skill description",
-                    "type": "string"
-                },
-                "dir_path": {
-                    "description": "Full path to the skill directory in sandbox",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "Skill name",
-                    "type": "string"
-                },
-                "success": {
-                    "description": "Whether the download was successful",
-                    "type": "boolean"
+                    "maxLength": 256,
+                    "example": "Trip planning"
                 }
             }
         },
-        "handler.DownloadToSandboxReq": {
+        "handler.UploadFromSandboxReq": {
             "type": "object",
             "required": [
                 "file_path",
-                "filename",
+                "sandbox_filename",
                 "sandbox_id",
                 "sandbox_path"
             ],
             "properties": {
                 "file_path": {
-                    "description": "File path (directory) of the artifact",
+                    "description": "Destination directory path on the disk",
                     "type": "string"
                 },
-                "filename": {
-                    "description": "Filename of the artifact",
+                "sandbox_filename": {
+                    "description": "Filename in the sandbox",
                     "type": "string"
                 },
                 "sandbox_id": {
-                    "description": "Target sandbox ID",
+                    "description": "Source sandbox ID",
                     "type": "string"
                 },
                 "sandbox_path": {
-                    "description": "Destination directory in the sandbox",
+                    "description": "Source directory in the sandbox",
                     "type": "string"
                 }
             }
         },
-        "handler.DownloadToSandboxResp": {
-            "type": "object",
-            "properties": {
-                "success": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "handler.ExecCommandReq": {
+        "httpclient.FlagResponse": {
             "type": "object",
-            "required": [
-                "command"
-            ],
             "properties": {
-                "command": {
+                "errmsg": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.GetArtifactResp": {
+        "httpclient.SandboxCommandOutput": {
             "type": "object",
             "properties": {
-                "artifact": {
-                    "$ref": "#/definitions/model.Artifact"
+                "exit_code": {
+                    "type": "integer"
                 },
-                "content": {
-                    "$ref": "#/definitions/fileparser.FileContent"
+                "stderr": {
+                    "type": "string"
                 },
-                "public_url": {
+                "stdout": {
                     "type": "string"
                 }
             }
         },
-        "handler.IncludeSkillReq": {
+        "httpclient.SandboxRuntimeInfo": {
             "type": "object",
-            "required": [
-                "skill_id"
-            ],
             "properties": {
-                "skill_id": {
-                    "type": "string",
-                    "example": "skill-uuid"
+                "sandbox_created_at": {
+                    "type": "string"
+                },
+                "sandbox_expires_at": {
+                    "type": "string"
+                },
+                "sandbox_id": {
+                    "type": "string"
+                },
+                "sandbox_status": {
+                    "type": "string"
                 }
             }
         },
-        "handler.LearnReq": {
+        "model.AgentSkills": {
             "type": "object",
-            "required": [
-                "session_id"
-            ],
             "properties": {
-                "session_id": {
-                    "type": "string",
-                    "example": "session-uuid"
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disk_id": {
+                    "description": "DiskID references the Disk that stores this skill's files as Artifacts",
+                    "type": "string"
+                },
+                "file_index": {
+                    "description": "FileIndex is computed at query time from the Disk's Artifacts, not persisted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FileInfo"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meta": {
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Name is not unique - multiple skills can have the same name",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "handler.ListArtifactsResp": {
+        "model.Artifact": {
             "type": "object",
             "properties": {
-                "artifacts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Artifact"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "directories": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "disk_id": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "meta": {
+                    "type": "object"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the revision number of the current content, starting at 1 and\nincremented every time the artifact is overwritten or restored",
+                    "type": "integer"
                 }
             }
         },
-        "handler.PatchMessageMetaReq": {
+        "model.ArtifactLease": {
             "type": "object",
-            "required": [
-                "meta"
-            ],
             "properties": {
-                "meta": {
-                    "type": "object",
-                    "additionalProperties": true
+                "disk_id": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
                 }
             }
         },
-        "handler.PatchMessageMetaResp": {
+        "model.ArtifactVersion": {
             "type": "object",
             "properties": {
+                "archived_at": {
+                    "description": "When this revision was superseded",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "When this revision was written",
+                    "type": "string"
+                },
+                "disk_id": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
                 "meta": {
-                    "type": "object",
-                    "additionalProperties": true
+                    "type": "object"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.PatchSessionConfigsReq": {
+        "model.BulkItemResult": {
             "type": "object",
-            "required": [
-                "configs"
-            ],
             "properties": {
-                "configs": {
-                    "type": "object",
-                    "additionalProperties": true
+                "error": {
+                    "type": "string"
+                },
+                "new_session_id": {
+                    "description": "set by the copy action",
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "handler.PatchSessionConfigsResp": {
+        "model.Disk": {
             "type": "object",
             "properties": {
-                "configs": {
-                    "type": "object",
-                    "additionalProperties": true
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "handler.StoreMessageReq": {
+        "model.DiskSnapshot": {
             "type": "object",
-            "required": [
-                "blob"
-            ],
             "properties": {
-                "blob": {},
-                "format": {
-                    "type": "string",
-                    "enum": [
-                        "acontext",
-                        "openai",
-                        "anthropic",
-                        "gemini"
-                    ],
-                    "example": "openai"
+                "artifact_count": {
+                    "description": "Number of artifacts captured",
+                    "type": "integer"
                 },
-                "meta": {
-                    "description": "Optional user-provided metadata for the message",
-                    "type": "object",
-                    "additionalProperties": true
+                "created_at": {
+                    "type": "string"
+                },
+                "disk_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "size_b": {
+                    "description": "Total size of the captured artifacts in bytes",
+                    "type": "integer"
                 }
             }
         },
-        "handler.TokenCountsResp": {
+        "model.FileInfo": {
             "type": "object",
             "properties": {
-                "total_tokens": {
-                    "type": "integer"
+                "mime": {
+                    "description": "MIME type of the file",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Relative path from skillName root",
+                    "type": "string"
                 }
             }
         },
-        "handler.UpdateArtifactReq": {
+        "model.LearningSpace": {
             "type": "object",
-            "required": [
-                "file_path",
-                "meta"
-            ],
             "properties": {
-                "file_path": {
-                    "description": "File path including filename",
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
                 },
                 "meta": {
-                    "description": "Custom metadata as JSON string",
+                    "type": "object"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "handler.UpdateArtifactResp": {
+        "model.LearningSpaceSession": {
             "type": "object",
             "properties": {
-                "artifact": {
-                    "$ref": "#/definitions/model.Artifact"
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "learning_space_id": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "handler.UpdateLearningSpaceReq": {
+        "model.LearningSpaceSkill": {
             "type": "object",
-            "required": [
-                "meta"
-            ],
             "properties": {
-                "meta": {
-                    "type": "object"
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "learning_space_id": {
+                    "type": "string"
+                },
+                "skill_id": {
+                    "type": "string"
                 }
             }
         },
-        "handler.UpdateSessionConfigsReq": {
+        "model.Message": {
             "type": "object",
             "properties": {
-                "configs": {
-                    "type": "object",
-                    "additionalProperties": true
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meta": {
+                    "type": "object"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "session_task_process_status": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "handler.UploadFromSandboxReq": {
+        "model.MessageFeedback": {
             "type": "object",
-            "required": [
-                "file_path",
-                "sandbox_filename",
-                "sandbox_id",
-                "sandbox_path"
-            ],
             "properties": {
-                "file_path": {
-                    "description": "Destination directory path on the disk",
+                "comment": {
                     "type": "string"
                 },
-                "sandbox_filename": {
-                    "description": "Filename in the sandbox",
+                "created_at": {
                     "type": "string"
                 },
-                "sandbox_id": {
-                    "description": "Source sandbox ID",
+                "id": {
                     "type": "string"
                 },
-                "sandbox_path": {
-                    "description": "Source directory in the sandbox",
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "thumbs": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "httpclient.FlagResponse": {
+        "model.MessageObservingStatus": {
             "type": "object",
             "properties": {
-                "errmsg": {
-                    "type": "string"
+                "in_process": {
+                    "type": "integer"
                 },
-                "status": {
+                "observed": {
+                    "type": "integer"
+                },
+                "pending": {
                     "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "httpclient.SandboxCommandOutput": {
+        "model.PendingAssetUpload": {
             "type": "object",
             "properties": {
-                "exit_code": {
+                "asset_meta": {
+                    "type": "object"
+                },
+                "attempts": {
                     "type": "integer"
                 },
-                "stderr": {
+                "created_at": {
                     "type": "string"
                 },
-                "stdout": {
+                "id": {
                     "type": "string"
-                }
-            }
-        },
-        "httpclient.SandboxRuntimeInfo": {
-            "type": "object",
-            "properties": {
-                "sandbox_created_at": {
+                },
+                "last_error": {
                     "type": "string"
                 },
-                "sandbox_expires_at": {
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "sandbox_id": {
+                "s3_key": {
                     "type": "string"
                 },
-                "sandbox_status": {
+                "session_id": {
+                    "description": "session of the message that first stored it",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "model.AgentSkills": {
+        "model.SandboxLog": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "backend_sandbox_id": {
                     "type": "string"
                 },
-                "description": {
+                "backend_type": {
                     "type": "string"
                 },
-                "disk_id": {
-                    "description": "DiskID references the Disk that stores this skill's files as Artifacts",
+                "created_at": {
                     "type": "string"
                 },
-                "file_index": {
-                    "description": "FileIndex is computed at query time from the Disk's Artifacts, not persisted",
+                "generated_files": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.FileInfo"
+                        "type": "object"
+                    }
+                },
+                "history_commands": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
                     }
                 },
                 "id": {
                     "type": "string"
                 },
-                "meta": {
-                    "type": "object"
-                },
-                "name": {
-                    "description": "Name is not unique - multiple skills can have the same name",
+                "project_id": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "string"
+                "will_total_alive_seconds": {
+                    "type": "integer"
                 }
             }
         },
-        "model.Artifact": {
+        "model.Session": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "archived": {
+                    "type": "boolean"
                 },
-                "disk_id": {
-                    "type": "string"
+                "configs": {
+                    "type": "object"
                 },
-                "filename": {
+                "created_at": {
                     "type": "string"
                 },
-                "meta": {
-                    "type": "object"
+                "disable_task_tracking": {
+                    "type": "boolean"
                 },
-                "path": {
+                "id": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.Disk": {
-            "type": "object",
-            "properties": {
-                "created_at": {
+                "last_message_at": {
+                    "description": "LastMessageAt is computed from messages when listing sessions; it is not a stored column.",
                     "type": "string"
                 },
-                "id": {
+                "project_id": {
                     "type": "string"
                 },
-                "project_id": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -4895,55 +8801,70 @@ const docTemplate = `{
                 }
             }
         },
-        "model.FileInfo": {
+        "model.SessionBulkJob": {
             "type": "object",
             "properties": {
-                "mime": {
-                    "description": "MIME type of the file",
+                "action": {
                     "type": "string"
                 },
-                "path": {
-                    "description": "Relative path from skillName root",
-                    "type": "string"
-                }
-            }
-        },
-        "model.LearningSpace": {
-            "type": "object",
-            "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "meta": {
+                "params": {
+                    "description": "Params holds action arguments, e.g. configs for patch_configs or learning_space_id for learn",
                     "type": "object"
                 },
-                "updated_at": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BulkItemResult"
+                    }
+                },
+                "status": {
                     "type": "string"
                 },
-                "user_id": {
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "model.LearningSpaceSession": {
+        "model.SessionEvent": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "data": {
+                    "type": "object"
+                },
                 "id": {
                     "type": "string"
                 },
-                "learning_space_id": {
+                "project_id": {
                     "type": "string"
                 },
                 "session_id": {
                     "type": "string"
                 },
-                "status": {
+                "type": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -4951,54 +8872,71 @@ const docTemplate = `{
                 }
             }
         },
-        "model.LearningSpaceSkill": {
+        "model.Task": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "data": {
+                    "$ref": "#/definitions/model.TaskData"
+                },
                 "id": {
                     "type": "string"
                 },
-                "learning_space_id": {
+                "is_planning": {
+                    "type": "boolean"
+                },
+                "order": {
+                    "type": "integer"
+                },
+                "project_id": {
                     "type": "string"
                 },
-                "skill_id": {
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "model.Message": {
+        "model.TaskData": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "meta": {
-                    "type": "object"
+                "progresses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "parent_id": {
+                "task_description": {
                     "type": "string"
                 },
-                "parts": {
+                "user_preferences": {
                     "type": "array",
                     "items": {
-                        "type": "object"
+                        "type": "string"
                     }
-                },
-                "role": {
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "session_id": {
+                "id": {
                     "type": "string"
                 },
-                "session_task_process_status": {
+                "identifier": {
                     "type": "string"
                 },
-                "task_id": {
+                "project_id": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -5006,242 +8944,274 @@ const docTemplate = `{
                 }
             }
         },
-        "model.MessageObservingStatus": {
+        "repo.DiskSnapshotRestoreStats": {
             "type": "object",
             "properties": {
-                "in_process": {
+                "removed": {
+                    "description": "artifacts deleted because they were not in the snapshot",
                     "type": "integer"
                 },
-                "observed": {
+                "unchanged": {
+                    "description": "artifacts already matching the snapshot",
                     "type": "integer"
                 },
-                "pending": {
+                "written": {
+                    "description": "artifacts created or overwritten with their snapshot content",
                     "type": "integer"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "model.SandboxLog": {
+        "repo.MessageFeedbackSummary": {
             "type": "object",
             "properties": {
-                "backend_sandbox_id": {
-                    "type": "string"
-                },
-                "backend_type": {
-                    "type": "string"
+                "avg_score": {
+                    "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "generated_files": {
-                    "type": "array",
-                    "items": {
-                        "type": "object"
-                    }
+                "count": {
+                    "type": "integer"
                 },
-                "history_commands": {
-                    "type": "array",
-                    "items": {
-                        "type": "object"
+                "label_counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
                     }
                 },
-                "id": {
-                    "type": "string"
+                "message_count": {
+                    "type": "integer"
                 },
-                "project_id": {
-                    "type": "string"
+                "score_count": {
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "thumbs_down": {
+                    "type": "integer"
                 },
-                "will_total_alive_seconds": {
+                "thumbs_up": {
                     "type": "integer"
                 }
             }
         },
-        "model.Session": {
+        "repo.UserResourceCounts": {
             "type": "object",
             "properties": {
-                "configs": {
-                    "type": "object"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "disable_task_tracking": {
-                    "type": "boolean"
+                "disks_count": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "sessions_count": {
+                    "type": "integer"
                 },
-                "project_id": {
-                    "type": "string"
+                "skills_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "serializer.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "error": {
                     "type": "string"
                 },
-                "user_id": {
+                "msg": {
                     "type": "string"
                 }
             }
         },
-        "model.SessionEvent": {
+        "service.AgentSkillsListItem": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "data": {
-                    "type": "object"
+                "description": {
+                    "type": "string"
+                },
+                "file_index": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FileInfo"
+                    }
                 },
                 "id": {
                     "type": "string"
                 },
-                "project_id": {
-                    "type": "string"
+                "meta": {
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "session_id": {
+                "name": {
                     "type": "string"
                 },
-                "type": {
+                "updated_at": {
                     "type": "string"
                 },
-                "updated_at": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "model.Task": {
+        "service.ArtifactGrepResult": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "data": {
-                    "$ref": "#/definitions/model.TaskData"
-                },
-                "id": {
+                "disk_id": {
                     "type": "string"
                 },
-                "is_planning": {
-                    "type": "boolean"
+                "filename": {
+                    "type": "string"
                 },
-                "order": {
-                    "type": "integer"
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.GrepMatch"
+                    }
                 },
-                "project_id": {
-                    "type": "string"
+                "meta": {
+                    "type": "object"
                 },
-                "session_id": {
+                "path": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "string"
+                "truncated": {
+                    "description": "more lines matched than max_count",
+                    "type": "boolean"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is the revision number of the current content, starting at 1 and\nincremented every time the artifact is overwritten or restored",
+                    "type": "integer"
                 }
             }
         },
-        "model.TaskData": {
+        "service.AssetGCOutput": {
             "type": "object",
             "properties": {
-                "progresses": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "assets": {
+                    "type": "integer"
                 },
-                "task_description": {
+                "before": {
+                    "description": "assets unreferenced since before this were collected",
                     "type": "string"
                 },
-                "user_preferences": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "projects": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/service.AssetGCProjectStats"
                     }
                 }
             }
         },
-        "model.User": {
+        "service.AssetGCProjectStats": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "assets": {
+                    "type": "integer"
                 },
-                "identifier": {
-                    "type": "string"
+                "bytes": {
+                    "type": "integer"
                 },
                 "project_id": {
                     "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "repo.UserResourceCounts": {
+        "service.AssetObjectDrift": {
             "type": "object",
             "properties": {
-                "disks_count": {
-                    "type": "integer"
+                "repaired": {
+                    "type": "boolean"
                 },
-                "sessions_count": {
-                    "type": "integer"
+                "s3_key": {
+                    "type": "string"
                 },
-                "skills_count": {
+                "sha256": {
+                    "type": "string"
+                },
+                "size_b": {
                     "type": "integer"
                 }
             }
         },
-        "serializer.Response": {
+        "service.AssetRefCheckOutput": {
             "type": "object",
             "properties": {
-                "code": {
+                "assets": {
+                    "description": "distinct assets referenced",
                     "type": "integer"
                 },
-                "error": {
-                    "type": "string"
+                "complete": {
+                    "description": "Complete is false when some parts JSON could not be read, so expected counts are lower\nbounds; a repair then only raises counts and deletes nothing",
+                    "type": "boolean"
                 },
-                "msg": {
-                    "type": "string"
-                }
-            }
-        },
-        "service.AgentSkillsListItem": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
+                "leaked_objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.AssetObjectDrift"
+                    }
                 },
-                "description": {
+                "missing_objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.AssetObjectDrift"
+                    }
+                },
+                "objects": {
+                    "description": "objects stored under the project's prefixes",
+                    "type": "integer"
+                },
+                "project_id": {
                     "type": "string"
                 },
-                "file_index": {
+                "repair": {
+                    "type": "boolean"
+                },
+                "unreadable_parts": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.FileInfo"
+                        "type": "string"
                     }
                 },
-                "id": {
-                    "type": "string"
+                "wrong_counts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.AssetRefCountDrift"
+                    }
+                }
+            }
+        },
+        "service.AssetRefCountDrift": {
+            "type": "object",
+            "properties": {
+                "expected": {
+                    "type": "integer"
                 },
-                "meta": {
-                    "type": "object",
-                    "additionalProperties": true
+                "pending": {
+                    "description": "increments buffered but not yet flushed",
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "ref_count": {
+                    "description": "nil when the asset has no reference row",
+                    "type": "integer"
                 },
-                "updated_at": {
+                "repaired": {
+                    "type": "boolean"
+                },
+                "s3_key": {
                     "type": "string"
                 },
-                "user_id": {
+                "sha256": {
                     "type": "string"
                 }
             }
@@ -5257,6 +9227,22 @@ const docTemplate = `{
                 }
             }
         },
+        "service.DirectoryUsage": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "description": "number of files in the subtree",
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size_b": {
+                    "description": "total size of those files in bytes",
+                    "type": "integer"
+                }
+            }
+        },
         "service.GetFileOutput": {
             "type": "object",
             "properties": {
@@ -5322,6 +9308,53 @@ const docTemplate = `{
                 }
             }
         },
+        "service.GrepMatch": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "up to after_context lines following the match",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "before": {
+                    "description": "up to before_context lines preceding the match",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "type": "string",
+                    "example": "// TODO: handle retries"
+                },
+                "line_number": {
+                    "description": "1-based",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "service.ImportArtifactsOutput": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "description": "artifacts created or overwritten, in archive order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Artifact"
+                    }
+                },
+                "skipped": {
+                    "description": "destination file paths left untouched because they existed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "service.ListAgentSkillsOutput": {
             "type": "object",
             "properties": {
@@ -5373,6 +9406,26 @@ const docTemplate = `{
                 }
             }
         },
+        "service.ListFeedbackOutput": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageFeedback"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/repo.MessageFeedbackSummary"
+                }
+            }
+        },
         "service.ListLearningSpacesOutput": {
             "type": "object",
             "properties": {
@@ -5435,6 +9488,84 @@ const docTemplate = `{
                 }
             }
         },
+        "service.SessionStatsOutput": {
+            "type": "object",
+            "properties": {
+                "asset_bytes": {
+                    "description": "AssetBytes is the total size of files attached to message parts",
+                    "type": "integer"
+                },
+                "first_message_at": {
+                    "type": "string"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "part_type_counts": {
+                    "type": "object"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "role_counts": {
+                    "type": "object"
+                },
+                "role_tokens": {
+                    "type": "object"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "task_counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "tool_call_counts": {
+                    "description": "ToolCallCounts maps tool name to the number of tool-call parts invoking it",
+                    "type": "object"
+                },
+                "tool_error_count": {
+                    "type": "integer"
+                },
+                "tool_error_rate": {
+                    "type": "number"
+                },
+                "tool_result_count": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.TransferArtifactsOutput": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "description": "artifacts at their destination",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Artifact"
+                    }
+                },
+                "skipped": {
+                    "description": "source file paths left untouched because the destination existed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "service.UpdateSecretKeyOutput": {
             "type": "object",
             "properties": {
@@ -5442,6 +9573,60 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "textedit.Edit": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "description": "patch: unified diff against the text",
+                    "type": "string"
+                },
+                "end_line": {
+                    "description": "delete: last line to delete, inclusive",
+                    "type": "integer",
+                    "example": 5
+                },
+                "line": {
+                    "description": "insert: line to insert before; one past the last line appends",
+                    "type": "integer",
+                    "example": 3
+                },
+                "new_text": {
+                    "description": "replace: replacement text",
+                    "type": "string",
+                    "example": "DONE"
+                },
+                "old_text": {
+                    "description": "replace: exact text to find",
+                    "type": "string",
+                    "example": "TODO"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "replace",
+                        "insert",
+                        "delete",
+                        "patch"
+                    ],
+                    "example": "replace"
+                },
+                "replace_all": {
+                    "description": "replace: replace every occurrence instead of requiring a unique one",
+                    "type": "boolean",
+                    "example": false
+                },
+                "start_line": {
+                    "description": "delete: first line to delete",
+                    "type": "integer",
+                    "example": 3
+                },
+                "text": {
+                    "description": "insert: text to insert",
+                    "type": "string",
+                    "example": "new line"
+                }
+            }
         }
     },
     "securityDefinitions": {
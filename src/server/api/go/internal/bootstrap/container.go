@@ -113,6 +113,10 @@ func BuildContainer() *do.Injector {
 				&model.Message{},
 				&model.Disk{},
 				&model.Artifact{},
+				&model.ArtifactVersion{},
+				&model.DiskSnapshot{},
+				&model.DiskSnapshotEntry{},
+				&model.ArtifactLease{},
 				&model.AssetReference{},
 				&model.Metric{},
 				&model.AgentSkills{},
@@ -121,6 +125,11 @@ func BuildContainer() *do.Injector {
 				&model.LearningSpaceSkill{},
 				&model.LearningSpaceSession{},
 				&model.SessionEvent{},
+				&model.SessionStats{},
+				&model.SessionBulkJob{},
+				&model.MessageFeedback{},
+				&model.Upload{},
+				&model.PendingAssetUpload{},
 			)
 		}
 
@@ -180,10 +189,10 @@ func BuildContainer() *do.Injector {
 		return mq.NewPublisher(conn, log, cfg, dialFn)
 	})
 
-	// S3
-	do.Provide(inj, func(i *do.Injector) (*blob.S3Deps, error) {
+	// Blob storage (S3 or local filesystem)
+	do.Provide(inj, func(i *do.Injector) (blob.BlobStore, error) {
 		cfg := do.MustInvoke[*config.Config](i)
-		return blob.NewS3(context.Background(), cfg)
+		return blob.NewBlobStore(context.Background(), cfg, repo.NewAssetIndex(do.MustInvoke[*gorm.DB](i)))
 	})
 	// get presign expire duration
 	do.Provide(inj, func(i *do.Injector) (func() time.Duration, error) {
@@ -207,7 +216,7 @@ func BuildContainer() *do.Injector {
 	do.Provide(inj, func(i *do.Injector) (repo.AssetReferenceRepo, error) {
 		return repo.NewAssetReferenceRepo(
 			do.MustInvoke[*gorm.DB](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 		), nil
 	})
 
@@ -215,7 +224,7 @@ func BuildContainer() *do.Injector {
 		return repo.NewSessionRepo(
 			do.MustInvoke[*gorm.DB](i),
 			do.MustInvoke[repo.AssetReferenceRepo](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[*zap.Logger](i),
 		), nil
 	})
@@ -225,12 +234,31 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[repo.AssetReferenceRepo](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (repo.DiskSnapshotRepo, error) {
+		return repo.NewDiskSnapshotRepo(
+			do.MustInvoke[*gorm.DB](i),
+			do.MustInvoke[repo.AssetReferenceRepo](i),
+		), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (repo.ArtifactRepo, error) {
 		return repo.NewArtifactRepo(
 			do.MustInvoke[*gorm.DB](i),
 			do.MustInvoke[repo.AssetReferenceRepo](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (repo.ArtifactLeaseRepo, error) {
+		// Leases are kept in Redis when it is configured and reachable, and in Postgres otherwise
+		log := do.MustInvoke[*zap.Logger](i)
+		var rdb *redis.Client
+		if do.MustInvoke[*config.Config](i).Redis.Addr == "" {
+			log.Info("redis not configured, storing artifact leases in postgres")
+		} else if c, err := do.Invoke[*redis.Client](i); err != nil {
+			log.Warn("redis unavailable, storing artifact leases in postgres", zap.Error(err))
+		} else {
+			rdb = c
+		}
+		return repo.NewArtifactLeaseRepo(rdb, do.MustInvoke[*gorm.DB](i)), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (repo.TaskRepo, error) {
 		return repo.NewTaskRepo(do.MustInvoke[*gorm.DB](i)), nil
 	})
@@ -257,12 +285,24 @@ func BuildContainer() *do.Injector {
 	do.Provide(inj, func(i *do.Injector) (repo.SessionEventRepo, error) {
 		return repo.NewSessionEventRepo(do.MustInvoke[*gorm.DB](i)), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (repo.SessionBulkJobRepo, error) {
+		return repo.NewSessionBulkJobRepo(do.MustInvoke[*gorm.DB](i)), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (repo.UploadRepo, error) {
+		return repo.NewUploadRepo(do.MustInvoke[*gorm.DB](i)), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (repo.MessageFeedbackRepo, error) {
+		return repo.NewMessageFeedbackRepo(do.MustInvoke[*gorm.DB](i)), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (repo.PendingAssetUploadRepo, error) {
+		return repo.NewPendingAssetUploadRepo(do.MustInvoke[*gorm.DB](i)), nil
+	})
 
 	// Material Service (must be before other services that depend on it)
 	do.Provide(inj, func(i *do.Injector) (service.MaterialService, error) {
 		return service.NewMaterialService(
 			do.MustInvoke[*redis.Client](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[*config.Config](i),
 		), nil
 	})
@@ -277,6 +317,14 @@ func BuildContainer() *do.Injector {
 	})
 
 	// Service
+	do.Provide(inj, func(i *do.Injector) (service.AssetOutboxService, error) {
+		return service.NewAssetOutboxService(
+			do.MustInvoke[repo.PendingAssetUploadRepo](i),
+			do.MustInvoke[blob.BlobStore](i),
+			do.MustInvoke[*config.Config](i),
+			do.MustInvoke[*zap.Logger](i),
+		), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (service.SessionService, error) {
 		return service.NewSessionService(
 			do.MustInvoke[repo.SessionRepo](i),
@@ -284,24 +332,35 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[repo.AssetReferenceRepo](i),
 			do.MustInvoke[repo.AssetRefBuffer](i),
 			do.MustInvoke[*zap.Logger](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[*mq.Publisher](i),
 			do.MustInvoke[*config.Config](i),
 			do.MustInvoke[*redis.Client](i),
 			do.MustInvoke[service.MaterialService](i),
+			do.MustInvoke[service.AssetOutboxService](i),
 		), nil
 	})
 	do.Provide(inj, func(i *do.Injector) (service.DiskService, error) {
-		return service.NewDiskService(do.MustInvoke[repo.DiskRepo](i)), nil
+		return service.NewDiskService(
+			do.MustInvoke[repo.DiskRepo](i),
+			do.MustInvoke[repo.DiskSnapshotRepo](i),
+			do.MustInvoke[*config.Config](i),
+		), nil
 	})
 	do.Provide(inj, func(i *do.Injector) (service.ArtifactService, error) {
 		return service.NewArtifactService(
 			do.MustInvoke[repo.ArtifactRepo](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[repo.AgentSkillsRepo](i),
+			do.MustInvoke[*config.Config](i),
 			do.MustInvoke[*zap.Logger](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (service.ArtifactLeaseService, error) {
+		return service.NewArtifactLeaseService(
+			do.MustInvoke[repo.ArtifactLeaseRepo](i),
+		), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (service.TaskService, error) {
 		return service.NewTaskService(
 			do.MustInvoke[repo.TaskRepo](i),
@@ -347,6 +406,41 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[*zap.Logger](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (service.MessageFeedbackService, error) {
+		return service.NewMessageFeedbackService(
+			do.MustInvoke[repo.SessionRepo](i),
+			do.MustInvoke[repo.MessageFeedbackRepo](i),
+			do.MustInvoke[service.UserService](i),
+		), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (service.SessionBulkService, error) {
+		return service.NewSessionBulkService(
+			do.MustInvoke[repo.SessionBulkJobRepo](i),
+			do.MustInvoke[repo.SessionRepo](i),
+			do.MustInvoke[service.SessionService](i),
+			do.MustInvoke[service.LearningSpaceService](i),
+			do.MustInvoke[*zap.Logger](i),
+		), nil
+	})
+
+	do.Provide(inj, func(i *do.Injector) (service.UploadService, error) {
+		return service.NewUploadService(
+			do.MustInvoke[repo.UploadRepo](i),
+			do.MustInvoke[repo.AssetReferenceRepo](i),
+			do.MustInvoke[blob.BlobStore](i),
+			do.MustInvoke[*config.Config](i),
+			do.MustInvoke[*zap.Logger](i),
+		), nil
+	})
+
+	do.Provide(inj, func(i *do.Injector) (service.AssetGCService, error) {
+		return service.NewAssetGCService(
+			do.MustInvoke[repo.AssetReferenceRepo](i),
+			do.MustInvoke[*redis.Client](i),
+			do.MustInvoke[*config.Config](i),
+			do.MustInvoke[*zap.Logger](i),
+		), nil
+	})
 
 	// Handler
 	do.Provide(inj, func(i *do.Injector) (*handler.SessionHandler, error) {
@@ -354,6 +448,7 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[service.SessionService](i),
 			do.MustInvoke[service.UserService](i),
 			do.MustInvoke[*httpclient.CoreClient](i),
+			do.MustInvoke[service.UploadService](i),
 		), nil
 	})
 	do.Provide(inj, func(i *do.Injector) (*handler.DiskHandler, error) {
@@ -361,6 +456,7 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[service.DiskService](i),
 			do.MustInvoke[repo.DiskRepo](i),
 			do.MustInvoke[service.UserService](i),
+			do.MustInvoke[service.ArtifactLeaseService](i),
 		), nil
 	})
 	do.Provide(inj, func(i *do.Injector) (*handler.ArtifactHandler, error) {
@@ -369,8 +465,10 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[repo.DiskRepo](i),
 			do.MustInvoke[*config.Config](i),
 			do.MustInvoke[*httpclient.CoreClient](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[service.MaterialService](i),
+			do.MustInvoke[service.ArtifactLeaseService](i),
+			do.MustInvoke[service.UploadService](i),
 		), nil
 	})
 	do.Provide(inj, func(i *do.Injector) (*handler.TaskHandler, error) {
@@ -397,6 +495,19 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[service.SessionEventService](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (*handler.SessionBulkHandler, error) {
+		return handler.NewSessionBulkHandler(
+			do.MustInvoke[service.SessionBulkService](i),
+		), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (*handler.UploadHandler, error) {
+		return handler.NewUploadHandler(do.MustInvoke[service.UploadService](i)), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (*handler.MessageFeedbackHandler, error) {
+		return handler.NewMessageFeedbackHandler(
+			do.MustInvoke[service.MessageFeedbackService](i),
+		), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (*handler.LearningSpaceHandler, error) {
 		return handler.NewLearningSpaceHandler(
 			do.MustInvoke[service.LearningSpaceService](i),
@@ -408,11 +519,14 @@ func BuildContainer() *do.Injector {
 			do.MustInvoke[service.MaterialService](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (*handler.BlobHandler, error) {
+		return handler.NewBlobHandler(do.MustInvoke[blob.BlobStore](i)), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (*handler.ProjectHandler, error) {
 		return handler.NewProjectHandler(
 			do.MustInvoke[*gorm.DB](i),
 			do.MustInvoke[*redis.Client](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[repo.AssetReferenceRepo](i),
 		), nil
 	})
@@ -8,12 +8,14 @@ import (
 	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/redis/go-redis/v9"
 	"github.com/samber/do"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // BuildAdminContainer extends the base container with admin-specific dependencies.
 // It calls BuildContainer() first, then registers additional providers for
-// ProjectRepo, MetricRepo, ProjectService, MetricService, AdminHandler, and MetricsHandler.
+// ProjectRepo, MetricRepo, ProjectService, MetricService, AssetRefCheckService, AdminHandler,
+// AssetGCHandler, AssetRefCheckHandler and MetricsHandler.
 func BuildAdminContainer() *do.Injector {
 	inj := BuildContainer()
 
@@ -38,19 +40,36 @@ func BuildAdminContainer() *do.Injector {
 			do.MustInvoke[*redis.Client](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (service.AssetRefCheckService, error) {
+		return service.NewAssetRefCheckService(
+			do.MustInvoke[repo.AssetReferenceRepo](i),
+			do.MustInvoke[repo.AssetRefBuffer](i),
+			do.MustInvoke[repo.UploadRepo](i),
+			do.MustInvoke[blob.BlobStore](i),
+			do.MustInvoke[*redis.Client](i),
+			do.MustInvoke[*config.Config](i),
+			do.MustInvoke[*zap.Logger](i),
+		), nil
+	})
 
 	// Admin-specific handlers
 	do.Provide(inj, func(i *do.Injector) (*handler.AdminHandler, error) {
 		return handler.NewAdminHandler(
 			do.MustInvoke[service.ProjectService](i),
 			do.MustInvoke[repo.ProjectRepo](i),
-			do.MustInvoke[*blob.S3Deps](i),
+			do.MustInvoke[blob.BlobStore](i),
 			do.MustInvoke[repo.AssetReferenceRepo](i),
 			do.MustInvoke[*gorm.DB](i),
 			do.MustInvoke[*redis.Client](i),
 			do.MustInvoke[*config.Config](i),
 		), nil
 	})
+	do.Provide(inj, func(i *do.Injector) (*handler.AssetGCHandler, error) {
+		return handler.NewAssetGCHandler(do.MustInvoke[service.AssetGCService](i)), nil
+	})
+	do.Provide(inj, func(i *do.Injector) (*handler.AssetRefCheckHandler, error) {
+		return handler.NewAssetRefCheckHandler(do.MustInvoke[service.AssetRefCheckService](i)), nil
+	})
 	do.Provide(inj, func(i *do.Injector) (*handler.MetricsHandler, error) {
 		return handler.NewMetricsHandler(
 			do.MustInvoke[service.MetricService](i),
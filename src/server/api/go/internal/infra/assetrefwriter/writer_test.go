@@ -62,6 +62,30 @@ func (m *mockAssetReferenceRepo) ListS3KeysByProject(_ context.Context, _ uuid.U
 	return nil, nil
 }
 
+func (m *mockAssetReferenceRepo) ListOrphanedAssetRefs(_ context.Context, _ *uuid.UUID, _ time.Time, _ uuid.UUID, _ int) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepo) DeleteOrphanedAssetRefs(_ context.Context, _ []uuid.UUID, _ time.Time) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepo) ListAssetRefsByProject(_ context.Context, _ uuid.UUID) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepo) CountAssetUses(_ context.Context, _ uuid.UUID) ([]repo.AssetUse, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepo) SetAssetRefCount(_ context.Context, _ uuid.UUID, _ model.Asset, _ *int, _ int) (bool, error) {
+	return false, nil
+}
+
+func (m *mockAssetReferenceRepo) DeleteUnreferencedAsset(_ context.Context, _ uuid.UUID, _ model.Asset) (bool, error) {
+	return false, nil
+}
+
 func newTestRedis(t *testing.T) *redis.Client {
 	t.Helper()
 	rdb := redis.NewClient(&redis.Options{
@@ -0,0 +1,922 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/url"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/mime"
+)
+
+var (
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signed URL")
+)
+
+// localBucket is reported as the bucket of assets kept by LocalStore.
+const localBucket = "local"
+
+// tempPrefix marks files that are still being written; they are never listed as objects.
+const tempPrefix = ".upload-"
+
+// LocalStore keeps objects on the local filesystem under Root, one file per key. Each file
+// holds the object's bytes followed by a JSON trailer with its content type, ETag and
+// metadata, then the trailer's length as 8 big-endian bytes. Writes go to a temp file that
+// is renamed into place, so readers never see a partial object.
+//
+// Presigned URLs point at the API's /api/v1/blob endpoint and are authenticated with an HMAC
+// over the method, key, content type and expiry.
+type LocalStore struct {
+	Root    string
+	BaseURL string // external base URL of this API, used to build signed URLs
+
+	// Index resolves deduplicated uploads without walking Root; optional
+	Index AssetIndex
+	// Compression is the algorithm new text content is compressed with, or "" for none
+	Compression string
+
+	signingKey []byte
+}
+
+// objectTrailer is the JSON stored after an object's bytes.
+type objectTrailer struct {
+	ContentType string            `json:"content_type"`
+	ETag        string            `json:"etag"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+func NewLocalStore(cfg *config.Config) (*LocalStore, error) {
+	root := cfg.Blob.LocalRoot
+	if root == "" {
+		return nil, errors.New("blob local root is empty")
+	}
+	signingKey := cfg.Blob.SigningKey
+	if signingKey == "" {
+		signingKey = cfg.Root.SecretPepper
+	}
+	if signingKey == "" {
+		return nil, errors.New("blob signing key is empty")
+	}
+	compression, err := compressionFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob root %s: %w", root, err)
+	}
+
+	base := cfg.App.ExternalURL
+	if base == "" {
+		host := cfg.App.Host
+		if host == "" {
+			host = "localhost"
+		}
+		base = fmt.Sprintf("http://%s:%d", host, cfg.App.Port)
+	}
+
+	return &LocalStore{
+		Root:        root,
+		BaseURL:     strings.TrimSuffix(base, "/"),
+		Compression: compression,
+		signingKey:  []byte(signingKey),
+	}, nil
+}
+
+// filePath maps a key to its file under Root, rejecting keys that are not clean relative paths.
+func (l *LocalStore) filePath(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || stdpath.Clean(key) != key || key == "." ||
+		key == ".." || strings.HasPrefix(key, "../") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	if strings.HasPrefix(stdpath.Base(key), tempPrefix) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(key)), nil
+}
+
+// Put streams body into key, replacing any existing object, and returns its description.
+func (l *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string, metadata map[string]string) (*ObjectInfo, error) {
+	p, err := l.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, fmt.Errorf("create object directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), tempPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp object: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	h := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), body)
+	if err != nil {
+		return nil, fmt.Errorf("write object: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	trailer := objectTrailer{ContentType: contentType, ETag: hex.EncodeToString(h.Sum(nil)), Metadata: metadata}
+	raw, err := json.Marshal(trailer)
+	if err != nil {
+		return nil, fmt.Errorf("marshal object trailer: %w", err)
+	}
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(raw)))
+	if _, err := tmp.Write(append(raw, n[:]...)); err != nil {
+		return nil, fmt.Errorf("write object trailer: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("close object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return nil, fmt.Errorf("commit object: %w", err)
+	}
+	committed = true
+
+	return &ObjectInfo{Key: key, ETag: trailer.ETag, Size: size, ContentType: contentType, Metadata: metadata}, nil
+}
+
+// Open returns a reader over the object's bytes. The caller must close the returned file.
+func (l *LocalStore) Open(ctx context.Context, key string) (*io.SectionReader, *ObjectInfo, io.Closer, error) {
+	p, err := l.filePath(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, nil, nil, fmt.Errorf("open object: %w", err)
+	}
+	info, err := readTrailer(f, key)
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, err
+	}
+	return io.NewSectionReader(f, 0, info.Size), info, f, nil
+}
+
+func readTrailer(f *os.File, key string) (*ObjectInfo, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat object: %w", err)
+	}
+	if st.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	var n [8]byte
+	if st.Size() < int64(len(n)) {
+		return nil, fmt.Errorf("object %s is corrupt", key)
+	}
+	if _, err := f.ReadAt(n[:], st.Size()-int64(len(n))); err != nil {
+		return nil, fmt.Errorf("read object trailer: %w", err)
+	}
+	trailerLen := int64(binary.BigEndian.Uint64(n[:]))
+	size := st.Size() - int64(len(n)) - trailerLen
+	if trailerLen < 0 || size < 0 {
+		return nil, fmt.Errorf("object %s is corrupt", key)
+	}
+	raw := make([]byte, trailerLen)
+	if _, err := f.ReadAt(raw, size); err != nil {
+		return nil, fmt.Errorf("read object trailer: %w", err)
+	}
+	var trailer objectTrailer
+	if err := json.Unmarshal(raw, &trailer); err != nil {
+		return nil, fmt.Errorf("object %s is corrupt: %w", key, err)
+	}
+	metadata := trailer.Metadata
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	return &ObjectInfo{Key: key, ETag: trailer.ETag, Size: size, ContentType: trailer.ContentType, Metadata: metadata}, nil
+}
+
+// Head returns the object's size, content type and metadata.
+func (l *LocalStore) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	_, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	closer.Close()
+	return info, nil
+}
+
+// downloadRaw reads the stored bytes, metadata, and content type without any decryption.
+func (l *LocalStore) downloadRaw(ctx context.Context, key string) ([]byte, map[string]string, string, error) {
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer closer.Close()
+
+	data := make([]byte, info.Size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, nil, "", fmt.Errorf("read object: %w", err)
+	}
+	return data, info.Metadata, contentTypeOrDefault(info.ContentType), nil
+}
+
+// put stores body at key, encrypting it in chunks as it is written when userKEK is non-nil.
+func (l *LocalStore) put(ctx context.Context, key string, body io.Reader, contentType string, metadata map[string]string, userKEK []byte) (*ObjectInfo, error) {
+	sealed, closeSealed, err := sealStream(body, userKEK, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSealed()
+	return l.Put(ctx, key, sealed, contentType, metadata)
+}
+
+// putContent is put for new content of the given plaintext size, which is compressed first
+// when it is worth it.
+func (l *LocalStore) putContent(ctx context.Context, key string, body io.Reader, contentType string, size int64, metadata map[string]string, userKEK []byte) (*ObjectInfo, error) {
+	compressed, closeCompressed, err := compressContent(body, l.Compression, contentType, size, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCompressed()
+	return l.put(ctx, key, compressed, contentType, metadata, userKEK)
+}
+
+// OpenObject opens key for streaming reads of its plaintext.
+func (l *LocalStore) OpenObject(ctx context.Context, key string, userKEK []byte) (*Object, error) {
+	info, err := l.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return newObject(info, userKEK, func(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+		r, _, closer, err := l.Open(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			length = r.Size() - offset
+		}
+		return readCloser{Reader: io.NewSectionReader(r, offset, length), Closer: closer}, nil
+	})
+}
+
+// uploadWithDedup is S3Deps.uploadWithDedup for the local filesystem.
+func (l *LocalStore) uploadWithDedup(ctx context.Context, keyPrefix string, sumHex string, contentType string, ext string, size int64, body io.Reader, metadata map[string]string, userKEK []byte) (*model.Asset, error) {
+	// Encrypted content is never shared, as each user needs their own wrapped DEK
+	var staleProject uuid.UUID
+	if userKEK == nil {
+		var existing *model.Asset
+		existing, staleProject = findDuplicate(ctx, l.Index, l, keyPrefix, sumHex, contentType)
+		if existing != nil {
+			repairIndex(ctx, l.Index, staleProject, sumHex, existing.S3Key)
+			return existing, nil
+		}
+	}
+
+	key := contentKey(keyPrefix, sumHex, ext)
+	info, err := l.putContent(ctx, key, body, contentType, size, metadata, userKEK)
+	if err != nil {
+		return nil, err
+	}
+	repairIndex(ctx, l.Index, staleProject, sumHex, key)
+
+	return &model.Asset{
+		Bucket: localBucket,
+		S3Key:  key,
+		ETag:   info.ETag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  size, // original plaintext size
+	}, nil
+}
+
+func (l *LocalStore) headAsset(ctx context.Context, key string, sumHex string, contentType string) (*model.Asset, error) {
+	info, err := l.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	size, err := plainSize(info.Metadata, info.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Asset{
+		Bucket: localBucket,
+		S3Key:  key,
+		ETag:   info.ETag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  size,
+	}, nil
+}
+
+func (l *LocalStore) findDuplicateByListing(ctx context.Context, keyPrefix string, sumHex string, contentType string) *model.Asset {
+	var found *model.Asset
+	_ = l.walk(keyPrefix, func(key string) error {
+		if !strings.Contains(stdpath.Base(key), sumHex) {
+			return nil
+		}
+		if asset, err := l.headAsset(ctx, key, sumHex, contentType); err == nil {
+			found = asset
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// walk calls fn with the key of every object under prefix.
+func (l *LocalStore) walk(prefix string, fn func(key string) error) error {
+	dir, err := l.filePath(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Root, p)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel))
+	})
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// UploadFormFile stores a multipart file with automatic deduplication, streaming it once to
+// hash it and once to store it.
+func (l *LocalStore) UploadFormFile(ctx context.Context, keyPrefix string, fh *multipart.FileHeader, userKEK []byte) (*model.Asset, error) {
+	sumHex, contentType, size, err := formFileDigest(fh)
+	if err != nil {
+		return nil, err
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return l.uploadWithDedup(ctx, keyPrefix, sumHex, contentType, formFileExt(fh), size, file,
+		map[string]string{"sha256": sumHex, "name": fh.Filename}, userKEK)
+}
+
+// UploadBytes stores raw bytes with automatic deduplication.
+func (l *LocalStore) UploadBytes(ctx context.Context, keyPrefix string, filename string, content []byte, userKEK []byte) (*model.Asset, error) {
+	sum := sha256.Sum256(content)
+	sumHex := hex.EncodeToString(sum[:])
+	ext := strings.ToLower(filepath.Ext(filename))
+	contentType := mime.DetectMimeType(content, filename)
+
+	return l.uploadWithDedup(ctx, keyPrefix, sumHex, contentType, ext, int64(len(content)), bytes.NewReader(content),
+		map[string]string{"sha256": sumHex, "name": filename}, userKEK)
+}
+
+// UploadJSON stores JSON data with automatic deduplication.
+func (l *LocalStore) UploadJSON(ctx context.Context, keyPrefix string, data interface{}, userKEK []byte) (*model.Asset, error) {
+	jsonData, err := sonic.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal json: %w", err)
+	}
+	sum := sha256.Sum256(jsonData)
+	sumHex := hex.EncodeToString(sum[:])
+
+	return l.uploadWithDedup(ctx, keyPrefix, sumHex, "application/json", ".json", int64(len(jsonData)), bytes.NewReader(jsonData),
+		map[string]string{"sha256": sumHex}, userKEK)
+}
+
+func (l *LocalStore) PrepareJSONAsset(keyPrefix string, data interface{}) (*PreparedUpload, error) {
+	return prepareJSONAsset(localBucket, keyPrefix, data)
+}
+
+func (l *LocalStore) PrepareFormFileAsset(keyPrefix string, fh *multipart.FileHeader) (*PreparedUpload, error) {
+	return prepareFormFileAsset(localBucket, keyPrefix, fh)
+}
+
+// UploadPrepared writes a previously prepared asset at its pre-computed key.
+func (l *LocalStore) UploadPrepared(ctx context.Context, p *PreparedUpload, userKEK []byte) error {
+	metadata := make(map[string]string, len(p.Metadata))
+	for k, v := range p.Metadata {
+		metadata[k] = v
+	}
+	body, err := p.body()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = l.putContent(ctx, p.Asset.S3Key, body, p.Asset.MIME, p.Asset.SizeB, metadata, userKEK)
+	return err
+}
+
+func (l *LocalStore) SealPrepared(p *PreparedUpload, userKEK []byte) (*SealedUpload, error) {
+	return sealPrepared(p, l.Compression, userKEK)
+}
+
+// UploadSealed writes a sealed upload at its pre-computed key as is.
+func (l *LocalStore) UploadSealed(ctx context.Context, s *SealedUpload) error {
+	metadata := make(map[string]string, len(s.Metadata))
+	for k, v := range s.Metadata {
+		metadata[k] = v
+	}
+	_, err := l.Put(ctx, s.Asset.S3Key, bytes.NewReader(s.Content), s.Asset.MIME, metadata)
+	return err
+}
+
+// multipartDir is where the parts of unfinished multipart uploads are kept, one directory
+// per upload holding a manifest and a file per part. Like temp files, they are never listed.
+const multipartDir = tempPrefix + "multipart"
+
+// multipartManifest records what a local multipart upload will be completed into.
+type multipartManifest struct {
+	Key         string            `json:"key"`
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+func (l *LocalStore) multipartPath(uploadID string, name string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", fmt.Errorf("invalid multipart upload ID %q", uploadID)
+	}
+	return filepath.Join(l.Root, multipartDir, uploadID, name), nil
+}
+
+func partFileName(partNumber int32) string {
+	return fmt.Sprintf("%spart-%05d", tempPrefix, partNumber)
+}
+
+// CreateMultipartUpload starts a multipart upload to key and returns its ID.
+func (l *LocalStore) CreateMultipartUpload(ctx context.Context, key string, contentType string, metadata map[string]string) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+	uploadID := uuid.NewString()
+	p, err := l.multipartPath(uploadID, tempPrefix+"manifest.json")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", fmt.Errorf("create multipart upload: %w", err)
+	}
+	raw, err := json.Marshal(multipartManifest{Key: key, ContentType: contentType, Metadata: metadata})
+	if err != nil {
+		return "", fmt.Errorf("marshal multipart manifest: %w", err)
+	}
+	if err := os.WriteFile(p, raw, 0o644); err != nil {
+		return "", fmt.Errorf("create multipart upload: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (l *LocalStore) readManifest(key string, uploadID string) (*multipartManifest, error) {
+	p, err := l.multipartPath(uploadID, tempPrefix+"manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: multipart upload %s", ErrObjectNotFound, uploadID)
+		}
+		return nil, fmt.Errorf("read multipart manifest: %w", err)
+	}
+	var m multipartManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal multipart manifest: %w", err)
+	}
+	if m.Key != key {
+		return nil, fmt.Errorf("multipart upload %s is not for %s", uploadID, key)
+	}
+	return &m, nil
+}
+
+// UploadPart stores data as part partNumber of a multipart upload, replacing any earlier
+// upload of the same part.
+func (l *LocalStore) UploadPart(ctx context.Context, key string, uploadID string, partNumber int32, data []byte) (string, error) {
+	if _, err := l.readManifest(key, uploadID); err != nil {
+		return "", err
+	}
+	p, err := l.multipartPath(uploadID, partFileName(partNumber))
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), tempPrefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("upload part %d: %w", partNumber, err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("upload part %d: %w", partNumber, err)
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CompleteMultipartUpload concatenates parts into the object at key and discards the upload.
+func (l *LocalStore) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []model.UploadPart) error {
+	m, err := l.readManifest(key, uploadID)
+	if err != nil {
+		return err
+	}
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		p, err := l.multipartPath(uploadID, partFileName(part.PartNumber))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return fmt.Errorf("open part %d: %w", part.PartNumber, err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	if _, err := l.Put(ctx, key, io.MultiReader(readers...), m.ContentType, m.Metadata); err != nil {
+		return err
+	}
+	return l.AbortMultipartUpload(ctx, key, uploadID)
+}
+
+// AbortMultipartUpload discards a multipart upload and the parts stored for it.
+func (l *LocalStore) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	p, err := l.multipartPath(uploadID, "")
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(p); err != nil {
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// PromoteUpload moves the assembled object at key to its content-addressed key, or deletes
+// it when keyPrefix already holds the content.
+func (l *LocalStore) PromoteUpload(ctx context.Context, key string, keyPrefix string, sumHex string, ext string, size int64) (*model.Asset, error) {
+	return l.promote(ctx, key, "", keyPrefix, sumHex, ext, "", size)
+}
+
+// promote is PromoteUpload storing the object as contentType, or as its current content type
+// when contentType is empty. With a non-empty etag only that version of the object is moved.
+func (l *LocalStore) promote(ctx context.Context, key string, etag string, keyPrefix string, sumHex string, ext string, contentType string, size int64) (*model.Asset, error) {
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	if etag != "" && info.ETag != etag {
+		return nil, fmt.Errorf("%w: %s", ErrObjectChanged, key)
+	}
+	if contentType == "" {
+		contentType = contentTypeOrDefault(info.ContentType)
+	}
+
+	var staleProject uuid.UUID
+	if encryptionpkg.MetadataFromMap(info.Metadata) == nil {
+		var existing *model.Asset
+		existing, staleProject = findDuplicate(ctx, l.Index, l, keyPrefix, sumHex, contentType)
+		if existing != nil {
+			repairIndex(ctx, l.Index, staleProject, sumHex, existing.S3Key)
+			if err := l.DeleteObject(ctx, key); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}
+	}
+
+	metadata := make(map[string]string, len(info.Metadata)+1)
+	for k, v := range info.Metadata {
+		metadata[k] = v
+	}
+	metadata["sha256"] = sumHex
+
+	dest := contentKey(keyPrefix, sumHex, ext)
+	destInfo, err := l.Put(ctx, dest, r, contentType, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.DeleteObject(ctx, key); err != nil {
+		return nil, err
+	}
+	repairIndex(ctx, l.Index, staleProject, sumHex, dest)
+
+	return &model.Asset{
+		Bucket: localBucket,
+		S3Key:  dest,
+		ETag:   destInfo.ETag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  size, // original plaintext size
+	}, nil
+}
+
+// IngestObject stores a plaintext object a client uploaded to key as content-addressed
+// content, encrypting it when userKEK is non-nil, and deletes it. Only the version of the
+// object with etag is stored.
+func (l *LocalStore) IngestObject(ctx context.Context, key string, etag string, keyPrefix string, sumHex string, contentType string, ext string, size int64, userKEK []byte) (*model.Asset, error) {
+	if userKEK == nil {
+		return l.promote(ctx, key, etag, keyPrefix, sumHex, ext, contentType, size)
+	}
+
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	if etag != "" && info.ETag != etag {
+		return nil, fmt.Errorf("%w: %s", ErrObjectChanged, key)
+	}
+
+	asset, err := l.uploadWithDedup(ctx, keyPrefix, sumHex, contentType, ext, size, r, map[string]string{"sha256": sumHex}, userKEK)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.DeleteObject(ctx, key); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// UploadFileDirect stores content at key without deduplication.
+func (l *LocalStore) UploadFileDirect(ctx context.Context, key string, content []byte, contentType string, userKEK []byte) (*model.Asset, error) {
+	sum := sha256.Sum256(content)
+	sumHex := hex.EncodeToString(sum[:])
+
+	info, err := l.putContent(ctx, key, bytes.NewReader(content), contentType, int64(len(content)), map[string]string{"sha256": sumHex}, userKEK)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Asset{
+		Bucket: localBucket,
+		S3Key:  key,
+		ETag:   info.ETag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  int64(len(content)), // original plaintext size
+	}, nil
+}
+
+// DownloadFile reads an object, decrypting and decompressing it as needed.
+func (l *LocalStore) DownloadFile(ctx context.Context, key string, userKEK []byte) ([]byte, error) {
+	data, metadata, _, err := l.downloadRaw(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeContent(data, metadata, userKEK)
+}
+
+// DownloadJSON reads a JSON object, decrypting it if needed, and unmarshals it into target.
+func (l *LocalStore) DownloadJSON(ctx context.Context, key string, target interface{}, userKEK []byte) error {
+	data, err := l.DownloadFile(ctx, key, userKEK)
+	if err != nil {
+		return err
+	}
+	if err := sonic.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("unmarshal json: %w", err)
+	}
+	return nil
+}
+
+// DeleteObject removes an object; deleting a missing object is not an error.
+func (l *LocalStore) DeleteObject(ctx context.Context, key string) error {
+	p, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete object: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalStore) DeleteObjects(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := l.DeleteObject(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteObjectsByPrefix removes every object under the directory prefix.
+func (l *LocalStore) DeleteObjectsByPrefix(ctx context.Context, prefix string) error {
+	if prefix == "" {
+		return errors.New("prefix is empty")
+	}
+	dir, err := l.filePath(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("delete objects by prefix: %w", err)
+	}
+	return nil
+}
+
+// ListObjects walks the directory holding prefix, skipping files still being written.
+func (l *LocalStore) ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	dir := l.Root
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		p, err := l.filePath(prefix[:i])
+		if err != nil {
+			return err
+		}
+		dir = p
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		st, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		info, err := l.Head(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				return nil
+			}
+			return err
+		}
+		info.LastModified = st.ModTime()
+		return fn(*info)
+	})
+	if err != nil {
+		return fmt.Errorf("list objects: %w", err)
+	}
+	return nil
+}
+
+// PresignGet returns a signed URL that serves the object's stored bytes until it expires.
+func (l *LocalStore) PresignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+	return l.signedURL("GET", key, "", expire), nil
+}
+
+// PresignPut returns a signed URL that accepts a PUT of the object with the given content type.
+func (l *LocalStore) PresignPut(ctx context.Context, key string, contentType string, expire time.Duration) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+	return l.signedURL("PUT", key, contentType, expire), nil
+}
+
+func (l *LocalStore) signedURL(method string, key string, contentType string, expire time.Duration) string {
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	if contentType != "" {
+		q.Set("content_type", contentType)
+	}
+	q.Set("signature", l.signature(method, key, contentType, expires))
+
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return l.BaseURL + "/api/v1/blob/" + strings.Join(segments, "/") + "?" + q.Encode()
+}
+
+func (l *LocalStore) signature(method string, key string, contentType string, expires string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s", method, key, contentType, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignedURL checks the query of a signed URL for method on key. For PUT the signed
+// content type is returned; the request must carry the same Content-Type.
+func (l *LocalStore) VerifySignedURL(method string, key string, query url.Values) (contentType string, err error) {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return "", ErrInvalidSignature
+	}
+	contentType = query.Get("content_type")
+	want := l.signature(method, key, contentType, expires)
+	if !hmac.Equal([]byte(want), []byte(query.Get("signature"))) {
+		return "", ErrInvalidSignature
+	}
+	return contentType, nil
+}
+
+// EncryptObject encrypts an unencrypted object in place with userKEK.
+func (l *LocalStore) EncryptObject(ctx context.Context, key string, userKEK []byte) error {
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return fmt.Errorf("download for encrypt: %w", err)
+	}
+	defer closer.Close()
+	if encryptionpkg.MetadataFromMap(info.Metadata) != nil {
+		return nil
+	}
+	_, err = l.put(ctx, key, r, contentTypeOrDefault(info.ContentType), info.Metadata, userKEK)
+	return err
+}
+
+// DecryptObject decrypts an encrypted object in place and drops its encryption metadata.
+func (l *LocalStore) DecryptObject(ctx context.Context, key string, userKEK []byte) error {
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return fmt.Errorf("download for decrypt: %w", err)
+	}
+	defer closer.Close()
+	if encryptionpkg.MetadataFromMap(info.Metadata) == nil {
+		return nil
+	}
+	plaintext, err := openStream(r, info.Size, info.Metadata, userKEK)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+	encryptionpkg.ClearEncryptionMetadata(info.Metadata)
+	_, err = l.put(ctx, key, plaintext, contentTypeOrDefault(info.ContentType), info.Metadata, nil)
+	return err
+}
+
+// RewrapObjectDEK re-wraps the DEK of an encrypted object from oldKEK to newKEK. The stored
+// bytes are copied unchanged into a file with the new metadata.
+func (l *LocalStore) RewrapObjectDEK(ctx context.Context, key string, oldKEK, newKEK []byte) error {
+	r, info, closer, err := l.Open(ctx, key)
+	if err != nil {
+		return fmt.Errorf("download for rewrap: %w", err)
+	}
+	defer closer.Close()
+	encMeta := encryptionpkg.MetadataFromMap(info.Metadata)
+	if encMeta == nil {
+		return nil
+	}
+	newWrapped, err := encryptionpkg.RewrapDEK(encMeta, oldKEK, newKEK)
+	if err != nil {
+		return fmt.Errorf("rewrap DEK: %w", err)
+	}
+	if newWrapped == "" {
+		return nil
+	}
+	info.Metadata[encryptionpkg.MetaKeyDEKUser] = newWrapped
+	_, err = l.put(ctx, key, r, contentTypeOrDefault(info.ContentType), info.Metadata, nil)
+	return err
+}
@@ -0,0 +1,402 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/crypto"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	cfg.App.ExternalURL = "http://api.test/"
+	l, err := NewLocalStore(cfg)
+	require.NoError(t, err)
+	return l
+}
+
+func TestLocalStore_PutOpen(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+
+	info, err := l.Put(ctx, "disks/p/a.txt", strings.NewReader("hello world"), "text/plain", map[string]string{"k": "v"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+	assert.NotEmpty(t, info.ETag)
+
+	r, got, closer, err := l.Open(ctx, "disks/p/a.txt")
+	require.NoError(t, err)
+	defer closer.Close()
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+	assert.Equal(t, "text/plain", got.ContentType)
+	assert.Equal(t, info.ETag, got.ETag)
+	assert.Equal(t, "v", got.Metadata["k"])
+
+	head, err := l.Head(ctx, "disks/p/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), head.Size)
+
+	_, err = l.Head(ctx, "disks/p/missing.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	l := newTestLocalStore(t)
+	for _, key := range []string{"", "/abs", "../escape", "a/../../b", "a//b", "a/./b", `a\b`, "a/.upload-1"} {
+		t.Run(key, func(t *testing.T) {
+			_, err := l.Put(context.Background(), key, strings.NewReader("x"), "", nil)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	}
+}
+
+func TestLocalStore_UploadBytes_Dedup(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	prefix := "assets/" + uuid.New().String()
+
+	first, err := l.UploadBytes(ctx, prefix, "a.txt", []byte("same content"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "local", first.Bucket)
+
+	second, err := l.UploadBytes(ctx, prefix, "b.txt", []byte("same content"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.S3Key, second.S3Key)
+
+	// a stale index entry falls back to walking the prefix and is repaired
+	index := &fakeAssetIndex{keys: map[string]string{first.SHA256: prefix + "/2000/01/01/gone.txt"}, repaired: map[string]string{}}
+	l.Index = index
+	third, err := l.UploadBytes(ctx, prefix, "c.txt", []byte("same content"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.S3Key, third.S3Key)
+	assert.Equal(t, first.S3Key, index.repaired[first.SHA256])
+}
+
+func TestLocalStore_MultipartUpload(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	prefix := "disks/" + uuid.New().String()
+	staging := "uploads/" + uuid.New().String()
+
+	uploadID, err := l.CreateMultipartUpload(ctx, staging, "text/plain", nil)
+	require.NoError(t, err)
+
+	// Parts are assembled by number, not by upload order
+	parts := make([]model.UploadPart, 2)
+	etag, err := l.UploadPart(ctx, staging, uploadID, 2, []byte(" world"))
+	require.NoError(t, err)
+	parts[1] = model.UploadPart{PartNumber: 2, ETag: etag}
+	etag, err = l.UploadPart(ctx, staging, uploadID, 1, []byte("hello"))
+	require.NoError(t, err)
+	parts[0] = model.UploadPart{PartNumber: 1, ETag: etag}
+	require.NoError(t, l.CompleteMultipartUpload(ctx, staging, uploadID, parts))
+
+	_, err = l.UploadPart(ctx, staging, uploadID, 3, []byte("late"))
+	assert.Error(t, err, "parts are gone once completed")
+
+	sum := sha256.Sum256([]byte("hello world"))
+	sumHex := hex.EncodeToString(sum[:])
+	asset, err := l.PromoteUpload(ctx, staging, prefix, sumHex, ".txt", 11)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(asset.S3Key, prefix+"/"))
+	assert.Equal(t, sumHex, asset.SHA256)
+	assert.Equal(t, "text/plain", asset.MIME)
+	_, err = l.Head(ctx, staging)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	got, err := l.DownloadFile(ctx, asset.S3Key, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+
+	// Promoting identical content reuses the stored object
+	_, err = l.Put(ctx, staging, strings.NewReader("hello world"), "text/plain", nil)
+	require.NoError(t, err)
+	dup, err := l.PromoteUpload(ctx, staging, prefix, sumHex, ".txt", 11)
+	require.NoError(t, err)
+	assert.Equal(t, asset.S3Key, dup.S3Key)
+	_, err = l.Head(ctx, staging)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	aborted, err := l.CreateMultipartUpload(ctx, staging, "", nil)
+	require.NoError(t, err)
+	_, err = l.UploadPart(ctx, staging, aborted, 1, []byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, l.AbortMultipartUpload(ctx, staging, aborted))
+	assert.Error(t, l.CompleteMultipartUpload(ctx, staging, aborted, []model.UploadPart{{PartNumber: 1}}))
+
+	_, err = l.UploadPart(ctx, staging, "../escape", 1, []byte("x"))
+	assert.Error(t, err)
+}
+
+func TestLocalStore_IngestObject(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	prefix := "disks/" + uuid.New().String()
+	key := "uploads/" + uuid.New().String()
+	sum := sha256.Sum256([]byte("verified"))
+	sumHex := hex.EncodeToString(sum[:])
+
+	for _, userKEK := range [][]byte{nil, make([]byte, 32)} {
+		verified, err := l.Put(ctx, key, strings.NewReader("verified"), "text/plain", nil)
+		require.NoError(t, err)
+		// Overwritten through its upload URL after it was verified
+		_, err = l.Put(ctx, key, strings.NewReader("swapped!"), "text/plain", nil)
+		require.NoError(t, err)
+
+		_, err = l.IngestObject(ctx, key, verified.ETag, prefix, sumHex, "text/plain", ".txt", 8, userKEK)
+		assert.ErrorIs(t, err, ErrObjectChanged)
+		_, err = l.Head(ctx, contentKey(prefix, sumHex, ".txt"))
+		assert.ErrorIs(t, err, ErrObjectNotFound, "nothing is stored under the verified hash")
+	}
+
+	verified, err := l.Put(ctx, key, strings.NewReader("verified"), "text/plain", nil)
+	require.NoError(t, err)
+	asset, err := l.IngestObject(ctx, key, verified.ETag, prefix, sumHex, "text/plain", ".txt", 8, nil)
+	require.NoError(t, err)
+	got, err := l.DownloadFile(ctx, asset.S3Key, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "verified", string(got))
+}
+
+func TestLocalStore_Encryption(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	kek, err := crypto.DeriveKEK([]byte("test-secret"), []byte("salt"), []byte("info"))
+	require.NoError(t, err)
+	newKEK, err := crypto.DeriveKEK([]byte("other-secret"), []byte("salt"), []byte("info"))
+	require.NoError(t, err)
+
+	asset, err := l.UploadFileDirect(ctx, "disks/p/secret.txt", []byte("plaintext"), "text/plain", kek)
+	require.NoError(t, err)
+
+	r, _, closer, err := l.Open(ctx, asset.S3Key)
+	require.NoError(t, err)
+	stored, _ := io.ReadAll(r)
+	closer.Close()
+	assert.False(t, bytes.Equal([]byte("plaintext"), stored))
+
+	got, err := l.DownloadFile(ctx, asset.S3Key, kek)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(got))
+
+	require.NoError(t, l.RewrapObjectDEK(ctx, asset.S3Key, kek, newKEK))
+	got, err = l.DownloadFile(ctx, asset.S3Key, newKEK)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(got))
+
+	require.NoError(t, l.DecryptObject(ctx, asset.S3Key, newKEK))
+	got, err = l.DownloadFile(ctx, asset.S3Key, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(got))
+}
+
+func TestLocalStore_Compression(t *testing.T) {
+	kek, err := crypto.DeriveKEK([]byte("test-secret"), []byte("salt"), []byte("info"))
+	require.NoError(t, err)
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 200)
+
+	for _, algo := range []string{compress.Gzip, compress.Zstd} {
+		for _, userKEK := range [][]byte{nil, kek} {
+			t.Run(fmt.Sprintf("%s encrypted=%v", algo, userKEK != nil), func(t *testing.T) {
+				l := newTestLocalStore(t)
+				l.Compression = algo
+				ctx := context.Background()
+
+				asset, err := l.UploadFileDirect(ctx, "disks/p/notes.txt", []byte(text), "text/plain", userKEK)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(text)), asset.SizeB)
+
+				head, err := l.Head(ctx, asset.S3Key)
+				require.NoError(t, err)
+				assert.Equal(t, algo, head.Metadata[metaCompression])
+				assert.Equal(t, strconv.Itoa(len(text)), head.Metadata[metaPlainSize])
+				assert.Less(t, head.Size, int64(len(text)))
+
+				got, err := l.DownloadFile(ctx, asset.S3Key, userKEK)
+				require.NoError(t, err)
+				assert.Equal(t, text, string(got))
+
+				obj, err := l.OpenObject(ctx, asset.S3Key, userKEK)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(text)), obj.Size)
+				rc, err := obj.NewRangeReader(ctx, 1000, 500)
+				require.NoError(t, err)
+				part, err := io.ReadAll(rc)
+				rc.Close()
+				require.NoError(t, err)
+				assert.Equal(t, text[1000:1500], string(part))
+
+				// Deduplicated uploads report the plaintext size too
+				again, err := l.UploadBytes(ctx, "disks/p", "notes.txt", []byte(text), userKEK)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(text)), again.SizeB)
+
+				jsonAsset, err := l.UploadJSON(ctx, "parts/p", map[string]string{"text": text}, userKEK)
+				require.NoError(t, err)
+				var decoded map[string]string
+				require.NoError(t, l.DownloadJSON(ctx, jsonAsset.S3Key, &decoded, userKEK))
+				assert.Equal(t, text, decoded["text"])
+			})
+		}
+	}
+
+	l := newTestLocalStore(t)
+	l.Compression = compress.Gzip
+	ctx := context.Background()
+
+	// Small and binary content is stored as is
+	small, err := l.UploadFileDirect(ctx, "disks/p/small.txt", []byte("hello"), "text/plain", nil)
+	require.NoError(t, err)
+	head, err := l.Head(ctx, small.S3Key)
+	require.NoError(t, err)
+	assert.Empty(t, head.Metadata[metaCompression])
+	bin, err := l.UploadFileDirect(ctx, "disks/p/a.png", []byte(text), "image/png", nil)
+	require.NoError(t, err)
+	head, err = l.Head(ctx, bin.S3Key)
+	require.NoError(t, err)
+	assert.Empty(t, head.Metadata[metaCompression])
+
+	// Encrypting a compressed object keeps it compressed
+	asset, err := l.UploadFileDirect(ctx, "disks/p/notes.txt", []byte(text), "text/plain", nil)
+	require.NoError(t, err)
+	require.NoError(t, l.EncryptObject(ctx, asset.S3Key, kek))
+	got, err := l.DownloadFile(ctx, asset.S3Key, kek)
+	require.NoError(t, err)
+	assert.Equal(t, text, string(got))
+	require.NoError(t, l.DecryptObject(ctx, asset.S3Key, kek))
+	got, err = l.DownloadFile(ctx, asset.S3Key, nil)
+	require.NoError(t, err)
+	assert.Equal(t, text, string(got))
+}
+
+func TestLocalStore_SealedUpload(t *testing.T) {
+	kek, err := crypto.DeriveKEK([]byte("test-secret"), []byte("salt"), []byte("info"))
+	require.NoError(t, err)
+	text := strings.Repeat("sealed now, stored later\n", 200)
+
+	for _, userKEK := range [][]byte{nil, kek} {
+		t.Run(fmt.Sprintf("encrypted=%v", userKEK != nil), func(t *testing.T) {
+			l := newTestLocalStore(t)
+			l.Compression = compress.Gzip
+			ctx := context.Background()
+
+			p, err := l.PrepareJSONAsset("parts/p", map[string]string{"text": text})
+			require.NoError(t, err)
+			sealed, err := l.SealPrepared(p, userKEK)
+			require.NoError(t, err)
+			assert.Equal(t, p.Asset, sealed.Asset)
+			assert.Less(t, len(sealed.Content), len(text))
+			assert.Equal(t, compress.Gzip, sealed.Metadata[metaCompression])
+
+			plain, err := sealed.Decode(userKEK)
+			require.NoError(t, err)
+			assert.Contains(t, string(plain), "sealed now, stored later")
+
+			// Not stored until uploaded
+			_, err = l.Head(ctx, p.Asset.S3Key)
+			assert.ErrorIs(t, err, ErrObjectNotFound)
+			require.NoError(t, l.UploadSealed(ctx, sealed))
+			var decoded map[string]string
+			require.NoError(t, l.DownloadJSON(ctx, p.Asset.S3Key, &decoded, userKEK))
+			assert.Equal(t, text, decoded["text"])
+		})
+	}
+}
+
+func TestLocalStore_DeleteObjectsByPrefix(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	for _, key := range []string{"disks/a/1.txt", "disks/a/sub/2.txt", "disks/ab/3.txt"} {
+		_, err := l.Put(ctx, key, strings.NewReader("x"), "", nil)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, l.DeleteObjectsByPrefix(ctx, "disks/a"))
+
+	_, err := l.Head(ctx, "disks/a/1.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	_, err = l.Head(ctx, "disks/a/sub/2.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	_, err = l.Head(ctx, "disks/ab/3.txt")
+	assert.NoError(t, err)
+}
+
+func TestLocalStore_ListObjects(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+	for _, key := range []string{"disks/a/1.txt", "disks/a/sub/2.txt", "disks/ab/3.txt", "parts/a/4.json"} {
+		_, err := l.Put(ctx, key, strings.NewReader(key), "", nil)
+		require.NoError(t, err)
+	}
+
+	list := func(prefix string) map[string]int64 {
+		got := map[string]int64{}
+		require.NoError(t, l.ListObjects(ctx, prefix, func(obj ObjectInfo) error {
+			assert.False(t, obj.LastModified.IsZero())
+			got[obj.Key] = obj.Size
+			return nil
+		}))
+		return got
+	}
+	assert.Equal(t, map[string]int64{"disks/a/1.txt": 13, "disks/a/sub/2.txt": 17}, list("disks/a/"))
+	assert.Len(t, list("disks/a"), 3)
+	assert.Len(t, list(""), 4)
+	assert.Empty(t, list("assets/a/"))
+
+	stop := errors.New("stop")
+	err := l.ListObjects(ctx, "disks/", func(ObjectInfo) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestLocalStore_SignedURL(t *testing.T) {
+	l := newTestLocalStore(t)
+	ctx := context.Background()
+
+	signed, err := l.PresignPut(ctx, "disks/p/a b.txt", "text/plain", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "http://api.test/api/v1/blob/disks/p/a%20b.txt?"))
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	contentType, err := l.VerifySignedURL("PUT", "disks/p/a b.txt", u.Query())
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+
+	_, err = l.VerifySignedURL("GET", "disks/p/a b.txt", u.Query())
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = l.VerifySignedURL("PUT", "disks/p/other.txt", u.Query())
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	tampered := u.Query()
+	tampered.Set("content_type", "text/html")
+	_, err = l.VerifySignedURL("PUT", "disks/p/a b.txt", tampered)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	expired, err := l.PresignGet(ctx, "disks/p/a b.txt", -time.Minute)
+	require.NoError(t, err)
+	u, err = url.Parse(expired)
+	require.NoError(t, err)
+	_, err = l.VerifySignedURL("GET", "disks/p/a b.txt", u.Query())
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
 	"github.com/memodb-io/Acontext/internal/config"
 	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
 	"github.com/memodb-io/Acontext/internal/modules/model"
@@ -35,9 +37,18 @@ type S3Deps struct {
 	Presigner *s3.PresignClient
 	Bucket    string
 	SSE       *s3types.ServerSideEncryption
+
+	// Index resolves deduplicated uploads without listing the bucket; optional
+	Index AssetIndex
+	// Compression is the algorithm new text content is compressed with, or "" for none
+	Compression string
 }
 
 func NewS3(ctx context.Context, cfg *config.Config) (*S3Deps, error) {
+	compression, err := compressionFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 	loadOpts := []func(*awsCfg.LoadOptions) error{
 		awsCfg.WithRegion(cfg.S3.Region),
 	}
@@ -119,11 +130,12 @@ func NewS3(ctx context.Context, cfg *config.Config) (*S3Deps, error) {
 	}
 
 	return &S3Deps{
-		Client:    client,
-		Uploader:  uploader,
-		Presigner: presigner,
-		Bucket:    cfg.S3.Bucket,
-		SSE:       sse,
+		Client:      client,
+		Uploader:    uploader,
+		Presigner:   presigner,
+		Bucket:      cfg.S3.Bucket,
+		SSE:         sse,
+		Compression: compression,
 	}, nil
 }
 
@@ -172,24 +184,47 @@ func cleanETag(etag string) string {
 	return strings.Trim(etag, `"`)
 }
 
-// encryptAndMergeMetadata encrypts content if a userKEK is provided.
-// Returns the (possibly encrypted) content and updated metadata map.
-func encryptAndMergeMetadata(content []byte, userKEK []byte, metadata map[string]string) ([]byte, map[string]string, error) {
-	if userKEK == nil {
-		return content, metadata, nil
+// putObject streams body to key through a multipart upload, so neither the content nor its
+// ciphertext is held in memory. userKEK is optional; when non-nil, the content is encrypted in
+// chunks as it is uploaded and the encryption fields are added to metadata.
+func (u *S3Deps) putObject(ctx context.Context, key string, body io.Reader, contentType string, metadata map[string]string, userKEK []byte) (string, error) {
+	sealed, closeSealed, err := sealStream(body, userKEK, metadata)
+	if err != nil {
+		return "", err
 	}
-	ciphertext, encMeta, err := encryptionpkg.EncryptData(userKEK, content)
+	defer closeSealed()
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(u.Bucket),
+		Key:         aws.String(key),
+		Body:        sealed,
+		ContentType: aws.String(contentType),
+		Metadata:    metadata,
+	}
+	if u.SSE != nil {
+		input.ServerSideEncryption = *u.SSE
+	}
+
+	out, err := u.Uploader.Upload(ctx, input)
 	if err != nil {
-		return nil, nil, fmt.Errorf("encrypt data: %w", err)
+		return "", err
 	}
-	for k, v := range encMeta.MetadataToMap() {
-		metadata[k] = v
+	return cleanETag(aws.ToString(out.ETag)), nil
+}
+
+// putContent is putObject for new content of the given plaintext size, which is compressed
+// first when it is worth it.
+func (u *S3Deps) putContent(ctx context.Context, key string, body io.Reader, contentType string, size int64, metadata map[string]string, userKEK []byte) (string, error) {
+	compressed, closeCompressed, err := compressContent(body, u.Compression, contentType, size, metadata)
+	if err != nil {
+		return "", err
 	}
-	return ciphertext, metadata, nil
+	defer closeCompressed()
+	return u.putObject(ctx, key, compressed, contentType, metadata, userKEK)
 }
 
 // uploadWithDedup performs content-addressed deduplicated upload.
-// It searches for existing objects under keyPrefix that contain the given sumHex in the key.
+// It looks for an existing object under keyPrefix holding content sumHex (see findDuplicate).
 // If found, returns its metadata; otherwise uploads the new content using date + sumHex + ext as key.
 // userKEK is optional; when non-nil, the data is encrypted before upload.
 func (u *S3Deps) uploadWithDedup(
@@ -205,125 +240,133 @@ func (u *S3Deps) uploadWithDedup(
 ) (*model.Asset, error) {
 	// Skip dedup when encryption is enabled: different users need different wrapped DEKs,
 	// so we cannot reuse an existing encrypted object for a different user.
+	var staleProject uuid.UUID
 	if userKEK == nil {
-		// Check for existing object with pagination support
-		listInput := &s3.ListObjectsV2Input{
-			Bucket: &u.Bucket,
-			Prefix: &keyPrefix,
-		}
-
-		var continuationToken *string
-		for {
-			listInput.ContinuationToken = continuationToken
-			result, err := u.Client.ListObjectsV2(ctx, listInput)
-			if err != nil {
-				break
-			}
-
-			if result.Contents != nil {
-				for _, obj := range result.Contents {
-					if obj.Key != nil && strings.Contains(*obj.Key, sumHex) {
-						if headResult, herr := u.Client.HeadObject(ctx, &s3.HeadObjectInput{
-							Bucket: &u.Bucket,
-							Key:    obj.Key,
-						}); herr == nil {
-							return &model.Asset{
-								Bucket: u.Bucket,
-								S3Key:  *obj.Key,
-								ETag:   cleanETag(*headResult.ETag),
-								SHA256: sumHex,
-								MIME:   contentType,
-								SizeB:  aws.ToInt64(headResult.ContentLength),
-							}, nil
-						}
-					}
-				}
-			}
-
-			// Check if there are more pages
-			if !aws.ToBool(result.IsTruncated) {
-				break
-			}
-			continuationToken = result.NextContinuationToken
+		var existing *model.Asset
+		existing, staleProject = findDuplicate(ctx, u.Index, u, keyPrefix, sumHex, contentType)
+		if existing != nil {
+			repairIndex(ctx, u.Index, staleProject, sumHex, existing.S3Key)
+			return existing, nil
 		}
 	}
 
 	// No existing file found, upload new file with date prefix
-	datePrefix := time.Now().UTC().Format("2006/01/02")
-	key := fmt.Sprintf("%s/%s/%s%s", keyPrefix, datePrefix, sumHex, ext)
+	key := contentKey(keyPrefix, sumHex, ext)
 
-	var bodyBuf bytes.Buffer
-	if _, err := io.Copy(&bodyBuf, body); err != nil {
-		return nil, fmt.Errorf("read body: %w", err)
+	etag, err := u.putContent(ctx, key, body, contentType, size, metadata, userKEK)
+	if err != nil {
+		return nil, err
 	}
-	uploadBytes := bodyBuf.Bytes()
 
-	var encErr error
-	uploadBytes, metadata, encErr = encryptAndMergeMetadata(uploadBytes, userKEK, metadata)
-	if encErr != nil {
-		return nil, encErr
-	}
+	repairIndex(ctx, u.Index, staleProject, sumHex, key)
 
-	input := &s3.PutObjectInput{
-		Bucket:      aws.String(u.Bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(uploadBytes),
-		ContentType: aws.String(contentType),
-		Metadata:    metadata,
+	return &model.Asset{
+		Bucket: u.Bucket,
+		S3Key:  key,
+		ETag:   etag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  size, // original plaintext size
+	}, nil
+}
+
+// findDuplicateByListing scans every object under keyPrefix for one whose key contains sumHex.
+func (u *S3Deps) findDuplicateByListing(ctx context.Context, keyPrefix string, sumHex string, contentType string) *model.Asset {
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: &u.Bucket,
+		Prefix: &keyPrefix,
 	}
-	if u.SSE != nil {
-		input.ServerSideEncryption = *u.SSE
+
+	var continuationToken *string
+	for {
+		listInput.ContinuationToken = continuationToken
+		result, err := u.Client.ListObjectsV2(ctx, listInput)
+		if err != nil {
+			return nil
+		}
+
+		for _, obj := range result.Contents {
+			if obj.Key != nil && strings.Contains(*obj.Key, sumHex) {
+				if asset, err := u.headAsset(ctx, *obj.Key, sumHex, contentType); err == nil {
+					return asset
+				}
+			}
+		}
+
+		// Check if there are more pages
+		if !aws.ToBool(result.IsTruncated) {
+			return nil
+		}
+		continuationToken = result.NextContinuationToken
 	}
+}
 
-	out, err := u.Uploader.Upload(ctx, input)
+// headAsset confirms key exists and describes it as an asset with the given content.
+func (u *S3Deps) headAsset(ctx context.Context, key string, sumHex string, contentType string) (*model.Asset, error) {
+	info, err := u.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	size, err := plainSize(info.Metadata, info.Size)
 	if err != nil {
 		return nil, err
 	}
-
 	return &model.Asset{
 		Bucket: u.Bucket,
 		S3Key:  key,
-		ETag:   cleanETag(*out.ETag),
+		ETag:   info.ETag,
 		SHA256: sumHex,
 		MIME:   contentType,
-		SizeB:  size, // original plaintext size
+		SizeB:  size,
+	}, nil
+}
+
+// Head returns the object's size, content type and metadata.
+func (u *S3Deps) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	headResult, err := u.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &u.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("head object: %w", err)
+	}
+	return &ObjectInfo{
+		Key:         key,
+		ETag:        cleanETag(aws.ToString(headResult.ETag)),
+		Size:        aws.ToInt64(headResult.ContentLength),
+		ContentType: aws.ToString(headResult.ContentType),
+		Metadata:    headResult.Metadata,
 	}, nil
 }
 
 // UploadFormFile uploads a file to S3 with automatic deduplication.
+// The file is streamed twice, once to hash it for its content-addressed key and once to
+// upload it, and is never read into memory.
 // userKEK is optional; when non-nil, the data is encrypted before upload.
 func (u *S3Deps) UploadFormFile(ctx context.Context, keyPrefix string, fh *multipart.FileHeader, userKEK []byte) (*model.Asset, error) {
-	file, err := fh.Open()
+	sumHex, contentType, size, err := formFileDigest(fh)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	// Read file content into memory
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, file); err != nil {
+	file, err := fh.Open()
+	if err != nil {
 		return nil, err
 	}
-	fileContent := buf.Bytes()
-
-	// Calculate SHA256 of the file content
-	h := sha256.New()
-	h.Write(fileContent)
-	sumHex := hex.EncodeToString(h.Sum(nil))
-
-	ext := strings.ToLower(filepath.Ext(fh.Filename))
-
-	// Detect MIME type from file content, with extension-based refinement for text files
-	contentType := mime.DetectMimeType(fileContent, fh.Filename)
+	defer file.Close()
 
 	return u.uploadWithDedup(
 		ctx,
 		keyPrefix,
 		sumHex,
 		contentType,
-		ext,
-		int64(len(fileContent)),
-		bytes.NewReader(fileContent),
+		formFileExt(fh),
+		size,
+		file,
 		map[string]string{
 			"sha256": sumHex,
 			"name":   fh.Filename,
@@ -393,13 +436,31 @@ func (u *S3Deps) UploadJSON(ctx context.Context, keyPrefix string, data interfac
 // PreparedUpload holds pre-computed asset metadata and content for deferred S3 upload.
 type PreparedUpload struct {
 	Asset    model.Asset       // Pre-computed asset metadata (S3Key, SHA256, MIME, SizeB)
-	Content  []byte            // Serialized content to upload
+	Content  []byte            // Serialized content to upload, nil when Open is set
 	Metadata map[string]string // S3 object metadata
+
+	// Open re-reads content too large to keep in memory. It reads the request's multipart
+	// file, so such uploads must complete before the request does.
+	Open func() (io.ReadCloser, error)
+}
+
+// preparedInlineSize is the largest form file PrepareFormFileAsset keeps in memory.
+const preparedInlineSize = 8 << 20
+
+func (p *PreparedUpload) body() (io.ReadCloser, error) {
+	if p.Open != nil {
+		return p.Open()
+	}
+	return io.NopCloser(bytes.NewReader(p.Content)), nil
 }
 
 // PrepareJSONAsset pre-computes asset metadata for JSON data without making any S3 calls.
 // Returns a PreparedUpload that can later be uploaded via UploadPrepared.
 func (u *S3Deps) PrepareJSONAsset(keyPrefix string, data interface{}) (*PreparedUpload, error) {
+	return prepareJSONAsset(u.Bucket, keyPrefix, data)
+}
+
+func prepareJSONAsset(bucket string, keyPrefix string, data interface{}) (*PreparedUpload, error) {
 	jsonData, err := sonic.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("marshal json: %w", err)
@@ -409,12 +470,11 @@ func (u *S3Deps) PrepareJSONAsset(keyPrefix string, data interface{}) (*Prepared
 	h.Write(jsonData)
 	sumHex := hex.EncodeToString(h.Sum(nil))
 
-	datePrefix := time.Now().UTC().Format("2006/01/02")
-	key := fmt.Sprintf("%s/%s/%s.json", keyPrefix, datePrefix, sumHex)
+	key := contentKey(keyPrefix, sumHex, ".json")
 
 	return &PreparedUpload{
 		Asset: model.Asset{
-			Bucket: u.Bucket,
+			Bucket: bucket,
 			S3Key:  key,
 			SHA256: sumHex,
 			MIME:   "application/json",
@@ -428,39 +488,39 @@ func (u *S3Deps) PrepareJSONAsset(keyPrefix string, data interface{}) (*Prepared
 // PrepareFormFileAsset pre-computes asset metadata for a multipart file without making any S3 calls.
 // Returns a PreparedUpload that can later be uploaded via UploadPrepared.
 func (u *S3Deps) PrepareFormFileAsset(keyPrefix string, fh *multipart.FileHeader) (*PreparedUpload, error) {
-	file, err := fh.Open()
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+	return prepareFormFileAsset(u.Bucket, keyPrefix, fh)
+}
 
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, file); err != nil {
+func prepareFormFileAsset(bucket string, keyPrefix string, fh *multipart.FileHeader) (*PreparedUpload, error) {
+	sumHex, contentType, size, err := formFileDigest(fh)
+	if err != nil {
 		return nil, err
 	}
-	fileContent := buf.Bytes()
 
-	h := sha256.New()
-	h.Write(fileContent)
-	sumHex := hex.EncodeToString(h.Sum(nil))
-
-	ext := strings.ToLower(filepath.Ext(fh.Filename))
-	contentType := mime.DetectMimeType(fileContent, fh.Filename)
-
-	datePrefix := time.Now().UTC().Format("2006/01/02")
-	key := fmt.Sprintf("%s/%s/%s%s", keyPrefix, datePrefix, sumHex, ext)
-
-	return &PreparedUpload{
+	p := &PreparedUpload{
 		Asset: model.Asset{
-			Bucket: u.Bucket,
-			S3Key:  key,
+			Bucket: bucket,
+			S3Key:  contentKey(keyPrefix, sumHex, formFileExt(fh)),
 			SHA256: sumHex,
 			MIME:   contentType,
-			SizeB:  int64(len(fileContent)),
+			SizeB:  size,
 		},
-		Content:  fileContent,
 		Metadata: map[string]string{"sha256": sumHex, "name": fh.Filename},
-	}, nil
+	}
+
+	if size > preparedInlineSize {
+		p.Open = func() (io.ReadCloser, error) { return fh.Open() }
+		return p, nil
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if p.Content, err = io.ReadAll(file); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // UploadPrepared executes a deferred S3 upload for a previously prepared asset.
@@ -468,31 +528,232 @@ func (u *S3Deps) PrepareFormFileAsset(keyPrefix string, fh *multipart.FileHeader
 // content SHA256, re-uploading identical content is naturally idempotent.
 // userKEK is optional; when non-nil, the data is encrypted before upload.
 func (u *S3Deps) UploadPrepared(ctx context.Context, p *PreparedUpload, userKEK []byte) error {
-	uploadBytes := p.Content
 	metadata := make(map[string]string)
 	for k, v := range p.Metadata {
 		metadata[k] = v
 	}
 
-	var encErr error
-	uploadBytes, metadata, encErr = encryptAndMergeMetadata(uploadBytes, userKEK, metadata)
-	if encErr != nil {
-		return encErr
+	body, err := p.body()
+	if err != nil {
+		return err
 	}
+	defer body.Close()
 
-	input := &s3.PutObjectInput{
+	_, err = u.putContent(ctx, p.Asset.S3Key, body, p.Asset.MIME, p.Asset.SizeB, metadata, userKEK)
+	return err
+}
+
+func (u *S3Deps) SealPrepared(p *PreparedUpload, userKEK []byte) (*SealedUpload, error) {
+	return sealPrepared(p, u.Compression, userKEK)
+}
+
+// UploadSealed writes a sealed upload at its pre-computed key as is.
+func (u *S3Deps) UploadSealed(ctx context.Context, s *SealedUpload) error {
+	metadata := make(map[string]string, len(s.Metadata))
+	for k, v := range s.Metadata {
+		metadata[k] = v
+	}
+	_, err := u.putObject(ctx, s.Asset.S3Key, bytes.NewReader(s.Content), s.Asset.MIME, metadata, nil)
+	return err
+}
+
+// CreateMultipartUpload starts a multipart upload to key and returns its ID.
+func (u *S3Deps) CreateMultipartUpload(ctx context.Context, key string, contentType string, metadata map[string]string) (string, error) {
+	input := &s3.CreateMultipartUploadInput{
 		Bucket:      aws.String(u.Bucket),
-		Key:         aws.String(p.Asset.S3Key),
-		Body:        bytes.NewReader(uploadBytes),
-		ContentType: aws.String(p.Asset.MIME),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
 		Metadata:    metadata,
 	}
 	if u.SSE != nil {
 		input.ServerSideEncryption = *u.SSE
 	}
+	out, err := u.Client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("create multipart upload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
 
-	_, err := u.Uploader.Upload(ctx, input)
-	return err
+// UploadPart stores data as part partNumber of a multipart upload, replacing any earlier
+// upload of the same part.
+func (u *S3Deps) UploadPart(ctx context.Context, key string, uploadID string, partNumber int32, data []byte) (string, error) {
+	out, err := u.Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(u.Bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return "", fmt.Errorf("upload part %d: %w", partNumber, err)
+	}
+	return cleanETag(aws.ToString(out.ETag)), nil
+}
+
+// CompleteMultipartUpload assembles the object at key from parts.
+func (u *S3Deps) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []model.UploadPart) error {
+	completed := make([]s3types.CompletedPart, 0, len(parts))
+	for _, p := range parts {
+		completed = append(completed, s3types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(`"` + p.ETag + `"`),
+		})
+	}
+	_, err := u.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and the parts stored for it.
+func (u *S3Deps) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	_, err := u.Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(u.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		var noUpload *s3types.NoSuchUpload
+		if errors.As(err, &noUpload) {
+			return nil
+		}
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// PromoteUpload copies the assembled object at key to its content-addressed key and deletes
+// it, or only deletes it when keyPrefix already holds the content. The copy is done by S3,
+// which limits it to objects of 5GB.
+func (u *S3Deps) PromoteUpload(ctx context.Context, key string, keyPrefix string, sumHex string, ext string, size int64) (*model.Asset, error) {
+	return u.promote(ctx, key, "", keyPrefix, sumHex, ext, "", size)
+}
+
+// promote is PromoteUpload storing the object as contentType, or as its current content type
+// when contentType is empty. With a non-empty etag only that version of the object is copied.
+func (u *S3Deps) promote(ctx context.Context, key string, etag string, keyPrefix string, sumHex string, ext string, contentType string, size int64) (*model.Asset, error) {
+	info, err := u.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" && info.ETag != etag {
+		return nil, fmt.Errorf("%w: %s", ErrObjectChanged, key)
+	}
+	if contentType == "" {
+		contentType = contentTypeOrDefault(info.ContentType)
+	}
+
+	var staleProject uuid.UUID
+	if encryptionpkg.MetadataFromMap(info.Metadata) == nil {
+		var existing *model.Asset
+		existing, staleProject = findDuplicate(ctx, u.Index, u, keyPrefix, sumHex, contentType)
+		if existing != nil {
+			repairIndex(ctx, u.Index, staleProject, sumHex, existing.S3Key)
+			if err := u.DeleteObject(ctx, key); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}
+	}
+
+	metadata := make(map[string]string, len(info.Metadata)+1)
+	for k, v := range info.Metadata {
+		metadata[k] = v
+	}
+	metadata["sha256"] = sumHex
+
+	dest := contentKey(keyPrefix, sumHex, ext)
+	source := u.Bucket + "/" + key
+	copyInput := &s3.CopyObjectInput{
+		Bucket:            &u.Bucket,
+		Key:               &dest,
+		CopySource:        &source,
+		ContentType:       aws.String(contentType),
+		Metadata:          metadata,
+		MetadataDirective: s3types.MetadataDirectiveReplace,
+	}
+	if etag != "" {
+		// The object can still be overwritten through its presigned URL after it was verified
+		copyInput.CopySourceIfMatch = aws.String(`"` + etag + `"`)
+	}
+	if u.SSE != nil {
+		copyInput.ServerSideEncryption = *u.SSE
+	}
+	out, err := u.Client.CopyObject(ctx, copyInput)
+	if isPreconditionFailed(err) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectChanged, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy uploaded object: %w", err)
+	}
+	if err := u.DeleteObject(ctx, key); err != nil {
+		return nil, err
+	}
+
+	repairIndex(ctx, u.Index, staleProject, sumHex, dest)
+
+	var destETag string
+	if out.CopyObjectResult != nil {
+		destETag = cleanETag(aws.ToString(out.CopyObjectResult.ETag))
+	}
+	return &model.Asset{
+		Bucket: u.Bucket,
+		S3Key:  dest,
+		ETag:   destETag,
+		SHA256: sumHex,
+		MIME:   contentType,
+		SizeB:  size, // original plaintext size
+	}, nil
+}
+
+// IngestObject stores a plaintext object a client uploaded to key as content-addressed content.
+// Without userKEK it is promoted by S3 like a resumable upload; with userKEK it is read back
+// and encrypted into place, then deleted. Both only read the version of the object with etag.
+func (u *S3Deps) IngestObject(ctx context.Context, key string, etag string, keyPrefix string, sumHex string, contentType string, ext string, size int64, userKEK []byte) (*model.Asset, error) {
+	if userKEK == nil {
+		return u.promote(ctx, key, etag, keyPrefix, sumHex, ext, contentType, size)
+	}
+
+	input := &s3.GetObjectInput{Bucket: &u.Bucket, Key: &key}
+	if etag != "" {
+		input.IfMatch = aws.String(`"` + etag + `"`)
+	}
+	out, err := u.Client.GetObject(ctx, input)
+	if isPreconditionFailed(err) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectChanged, key)
+	}
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("get object from S3: %w", err)
+	}
+	defer out.Body.Close()
+
+	asset, err := u.uploadWithDedup(ctx, keyPrefix, sumHex, contentType, ext, size, out.Body, map[string]string{"sha256": sumHex}, userKEK)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.DeleteObject(ctx, key); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// isPreconditionFailed reports whether S3 rejected a conditional request because the object
+// no longer matches.
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed"
 }
 
 // UploadFileDirect uploads a file directly to S3 at the specified key (no deduplication).
@@ -511,25 +772,7 @@ func (u *S3Deps) UploadFileDirect(ctx context.Context, key string, content []byt
 		"sha256": sumHex,
 	}
 
-	uploadBytes := content
-	var encErr error
-	uploadBytes, metadata, encErr = encryptAndMergeMetadata(uploadBytes, userKEK, metadata)
-	if encErr != nil {
-		return nil, encErr
-	}
-
-	input := &s3.PutObjectInput{
-		Bucket:      aws.String(u.Bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(uploadBytes),
-		ContentType: aws.String(contentType),
-		Metadata:    metadata,
-	}
-	if u.SSE != nil {
-		input.ServerSideEncryption = *u.SSE
-	}
-
-	out, err := u.Uploader.Upload(ctx, input)
+	etag, err := u.putContent(ctx, key, bytes.NewReader(content), contentType, int64(len(content)), metadata, userKEK)
 	if err != nil {
 		return nil, err
 	}
@@ -537,7 +780,7 @@ func (u *S3Deps) UploadFileDirect(ctx context.Context, key string, content []byt
 	return &model.Asset{
 		Bucket: u.Bucket,
 		S3Key:  key,
-		ETag:   cleanETag(*out.ETag),
+		ETag:   etag,
 		SHA256: sumHex,
 		MIME:   contentType,
 		SizeB:  int64(len(content)), // original plaintext size
@@ -554,7 +797,7 @@ func decryptWithUserKEK(data []byte, metadata map[string]string, userKEK []byte)
 		return data, nil
 	}
 	if userKEK == nil {
-		return nil, errors.New("encrypted object but no user KEK provided")
+		return nil, errNoUserKEK
 	}
 	return encryptionpkg.DecryptData(userKEK, data, encMeta)
 }
@@ -564,12 +807,9 @@ func (u *S3Deps) downloadRaw(ctx context.Context, key string) ([]byte, map[strin
 	if key == "" {
 		return nil, nil, "", errors.New("key is empty")
 	}
-	result, err := u.Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &u.Bucket,
-		Key:    &key,
-	})
+	result, err := u.getObject(ctx, key, nil)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("get object from S3: %w", err)
+		return nil, nil, "", err
 	}
 	defer result.Body.Close()
 
@@ -583,15 +823,63 @@ func (u *S3Deps) downloadRaw(ctx context.Context, key string) ([]byte, map[strin
 		metadata = result.Metadata
 	}
 
-	contentType := aws.ToString(result.ContentType)
+	return buf.Bytes(), metadata, contentTypeOrDefault(aws.ToString(result.ContentType)), nil
+}
+
+func contentTypeOrDefault(contentType string) string {
 	if contentType == "" {
-		contentType = "application/octet-stream"
+		return "application/octet-stream"
 	}
+	return contentType
+}
 
-	return buf.Bytes(), metadata, contentType, nil
+// getObject starts a download of key, or of the byte range rng ("bytes=a-b") when non-nil.
+func (u *S3Deps) getObject(ctx context.Context, key string, rng *string) (*s3.GetObjectOutput, error) {
+	result, err := u.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &u.Bucket,
+		Key:    &key,
+		Range:  rng,
+	})
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("get object from S3: %w", err)
+	}
+	return result, nil
 }
 
-// DownloadJSON downloads JSON data from S3, auto-decrypts if encrypted, and unmarshals.
+// OpenObject opens key for streaming reads of its plaintext. Each read of the returned object
+// downloads only the bytes it needs.
+// userKEK is optional; required only if the object is encrypted.
+func (u *S3Deps) OpenObject(ctx context.Context, key string, userKEK []byte) (*Object, error) {
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	info, err := u.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return newObject(info, userKEK, func(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+		var rng *string
+		switch {
+		case length == 0:
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		case length > 0:
+			rng = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+		case offset > 0:
+			rng = aws.String(fmt.Sprintf("bytes=%d-", offset))
+		}
+		result, err := u.getObject(ctx, key, rng)
+		if err != nil {
+			return nil, err
+		}
+		return result.Body, nil
+	})
+}
+
+// DownloadJSON downloads JSON data from S3, auto-decrypts and decompresses it, and unmarshals.
 // userKEK is optional; required only if the object is encrypted.
 func (u *S3Deps) DownloadJSON(ctx context.Context, key string, target interface{}, userKEK []byte) error {
 	data, metadata, _, err := u.downloadRaw(ctx, key)
@@ -599,9 +887,9 @@ func (u *S3Deps) DownloadJSON(ctx context.Context, key string, target interface{
 		return err
 	}
 
-	data, err = decryptWithUserKEK(data, metadata, userKEK)
+	data, err = decodeContent(data, metadata, userKEK)
 	if err != nil {
-		return fmt.Errorf("decrypt: %w", err)
+		return fmt.Errorf("decode: %w", err)
 	}
 
 	if err := sonic.Unmarshal(data, target); err != nil {
@@ -610,82 +898,65 @@ func (u *S3Deps) DownloadJSON(ctx context.Context, key string, target interface{
 	return nil
 }
 
-// DownloadFile downloads file content from S3, auto-decrypts if encrypted, returns plaintext.
+// DownloadFile downloads file content from S3, auto-decrypts if encrypted and decompresses if
+// compressed, returns plaintext.
 // userKEK is optional; required only if the object is encrypted.
 func (u *S3Deps) DownloadFile(ctx context.Context, key string, userKEK []byte) ([]byte, error) {
 	data, metadata, _, err := u.downloadRaw(ctx, key)
 	if err != nil {
 		return nil, err
 	}
-	return decryptWithUserKEK(data, metadata, userKEK)
+	return decodeContent(data, metadata, userKEK)
 }
 
-// EncryptObject reads an unencrypted S3 object, encrypts it with userKEK, and writes it back.
+// EncryptObject streams an unencrypted S3 object through encryption with userKEK and writes it back.
 func (u *S3Deps) EncryptObject(ctx context.Context, key string, userKEK []byte) error {
-	data, metadata, contentType, err := u.downloadRaw(ctx, key)
+	result, err := u.getObject(ctx, key, nil)
 	if err != nil {
 		return fmt.Errorf("download for encrypt: %w", err)
 	}
+	defer result.Body.Close()
+
+	metadata := make(map[string]string)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
 
 	// Skip if already encrypted
 	if encryptionpkg.MetadataFromMap(metadata) != nil {
 		return nil
 	}
 
-	ciphertext, encMeta, err := encryptionpkg.EncryptData(userKEK, data)
-	if err != nil {
-		return fmt.Errorf("encrypt: %w", err)
-	}
-
-	for k, v := range encMeta.MetadataToMap() {
-		metadata[k] = v
-	}
-
-	input := &s3.PutObjectInput{
-		Bucket:      aws.String(u.Bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(ciphertext),
-		ContentType: aws.String(contentType),
-		Metadata:    metadata,
-	}
-	if u.SSE != nil {
-		input.ServerSideEncryption = *u.SSE
-	}
-	_, err = u.Uploader.Upload(ctx, input)
+	_, err = u.putObject(ctx, key, result.Body, contentTypeOrDefault(aws.ToString(result.ContentType)), metadata, userKEK)
 	return err
 }
 
-// DecryptObject reads an encrypted S3 object, decrypts it with userKEK, and writes it back without encryption metadata.
+// DecryptObject streams an encrypted S3 object through decryption with userKEK and writes it back without encryption metadata.
 func (u *S3Deps) DecryptObject(ctx context.Context, key string, userKEK []byte) error {
-	data, metadata, contentType, err := u.downloadRaw(ctx, key)
+	result, err := u.getObject(ctx, key, nil)
 	if err != nil {
 		return fmt.Errorf("download for decrypt: %w", err)
 	}
+	defer result.Body.Close()
 
-	encMeta := encryptionpkg.MetadataFromMap(metadata)
-	if encMeta == nil {
+	metadata := make(map[string]string)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+
+	if encryptionpkg.MetadataFromMap(metadata) == nil {
 		// Not encrypted, nothing to do
 		return nil
 	}
 
-	plaintext, err := encryptionpkg.DecryptData(userKEK, data, encMeta)
+	plaintext, err := openStream(result.Body, aws.ToInt64(result.ContentLength), metadata, userKEK)
 	if err != nil {
 		return fmt.Errorf("decrypt: %w", err)
 	}
 
 	encryptionpkg.ClearEncryptionMetadata(metadata)
 
-	input := &s3.PutObjectInput{
-		Bucket:      aws.String(u.Bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(plaintext),
-		ContentType: aws.String(contentType),
-		Metadata:    metadata,
-	}
-	if u.SSE != nil {
-		input.ServerSideEncryption = *u.SSE
-	}
-	_, err = u.Uploader.Upload(ctx, input)
+	_, err = u.putObject(ctx, key, plaintext, contentTypeOrDefault(aws.ToString(result.ContentType)), metadata, nil)
 	return err
 }
 
@@ -815,11 +1086,27 @@ func (u *S3Deps) DeleteObjectsByPrefix(ctx context.Context, prefix string) error
 		prefixWithSlash = prefix + "/"
 	}
 
-	// List all objects with pagination support
 	var allKeys []string
+	if err := u.ListObjects(ctx, prefixWithSlash, func(obj ObjectInfo) error {
+		allKeys = append(allKeys, obj.Key)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Delete all found objects in batches
+	if len(allKeys) > 0 {
+		return u.DeleteObjects(ctx, allKeys)
+	}
+
+	return nil
+}
+
+// ListObjects pages through the objects under prefix with ListObjectsV2.
+func (u *S3Deps) ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
 	listInput := &s3.ListObjectsV2Input{
 		Bucket: &u.Bucket,
-		Prefix: &prefixWithSlash,
+		Prefix: &prefix,
 	}
 
 	var continuationToken *string
@@ -830,25 +1117,24 @@ func (u *S3Deps) DeleteObjectsByPrefix(ctx context.Context, prefix string) error
 			return fmt.Errorf("list objects from S3: %w", err)
 		}
 
-		if result.Contents != nil {
-			for _, obj := range result.Contents {
-				if obj.Key != nil {
-					allKeys = append(allKeys, *obj.Key)
-				}
+		for _, obj := range result.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			if err := fn(ObjectInfo{
+				Key:          *obj.Key,
+				ETag:         cleanETag(aws.ToString(obj.ETag)),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+			}); err != nil {
+				return err
 			}
 		}
 
 		// Check if there are more pages
 		if !aws.ToBool(result.IsTruncated) {
-			break
+			return nil
 		}
 		continuationToken = result.NextContinuationToken
 	}
-
-	// Delete all found objects in batches
-	if len(allKeys) > 0 {
-		return u.DeleteObjects(ctx, allKeys)
-	}
-
-	return nil
 }
@@ -2,14 +2,27 @@ package blob
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"net/textproto"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -129,3 +142,182 @@ func TestPrepareFormFileAsset_Deterministic(t *testing.T) {
 	assert.Equal(t, p1.Asset.SHA256, p2.Asset.SHA256)
 	assert.Equal(t, p1.Asset.S3Key, p2.Asset.S3Key)
 }
+
+// fakeS3 is a minimal path-style S3 endpoint serving HeadObject, PutObject and ListObjectsV2.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	lists   int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/test-bucket"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.lists++
+		prefix := r.URL.Query().Get("prefix")
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>test-bucket</Name><IsTruncated>false</IsTruncated>`)
+		for k := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				fmt.Fprintf(&b, "<Contents><Key>%s</Key></Contents>", k)
+			}
+		}
+		b.WriteString("</ListBucketResult>")
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(b.String()))
+	case r.Method == http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"etag-`+key+`"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+		w.Header().Set("ETag", `"etag-`+key+`"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newFakeS3Deps(t *testing.T, index AssetIndex) (*S3Deps, *fakeS3) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+	return &S3Deps{
+		Client:   client,
+		Uploader: manager.NewUploader(client),
+		Bucket:   "test-bucket",
+		Index:    index,
+	}, fake
+}
+
+type fakeAssetIndex struct {
+	keys     map[string]string
+	err      error
+	repaired map[string]string
+}
+
+func (x *fakeAssetIndex) LookupS3Key(_ context.Context, _ uuid.UUID, sha256 string) (string, error) {
+	return x.keys[sha256], x.err
+}
+
+func (x *fakeAssetIndex) RepairS3Key(_ context.Context, _ uuid.UUID, sha256 string, key string) error {
+	x.repaired[sha256] = key
+	return nil
+}
+
+func TestUploadBytes_Dedup(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	prefix := "disks/" + projectID.String()
+	content := []byte("hello dedup")
+	sum := sha256.Sum256(content)
+	sumHex := hex.EncodeToString(sum[:])
+
+	t.Run("index hit reuses the object without listing", func(t *testing.T) {
+		existing := prefix + "/2024/01/01/" + sumHex + ".txt"
+		index := &fakeAssetIndex{keys: map[string]string{sumHex: existing}, repaired: map[string]string{}}
+		u, fake := newFakeS3Deps(t, index)
+		fake.objects[existing] = content
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.Equal(t, existing, asset.S3Key)
+		assert.Equal(t, "etag-"+existing, asset.ETag)
+		assert.Equal(t, int64(len(content)), asset.SizeB)
+		assert.Zero(t, fake.lists)
+		assert.Len(t, fake.objects, 1)
+	})
+
+	t.Run("index miss uploads without listing", func(t *testing.T) {
+		index := &fakeAssetIndex{keys: map[string]string{}, repaired: map[string]string{}}
+		u, fake := newFakeS3Deps(t, index)
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(asset.S3Key, prefix+"/"))
+		assert.True(t, strings.HasSuffix(asset.S3Key, sumHex+".txt"))
+		assert.Contains(t, fake.objects, asset.S3Key)
+		assert.Zero(t, fake.lists)
+	})
+
+	t.Run("stale index entry is repaired from a listing", func(t *testing.T) {
+		listed := prefix + "/2024/02/02/" + sumHex + ".txt"
+		index := &fakeAssetIndex{
+			keys:     map[string]string{sumHex: prefix + "/2024/01/01/" + sumHex + ".txt"},
+			repaired: map[string]string{},
+		}
+		u, fake := newFakeS3Deps(t, index)
+		fake.objects[listed] = content
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.Equal(t, listed, asset.S3Key)
+		assert.Equal(t, 1, fake.lists)
+		assert.Equal(t, listed, index.repaired[sumHex])
+	})
+
+	t.Run("stale index entry with no copy left is repaired to the new upload", func(t *testing.T) {
+		index := &fakeAssetIndex{
+			keys:     map[string]string{sumHex: prefix + "/2024/01/01/" + sumHex + ".txt"},
+			repaired: map[string]string{},
+		}
+		u, fake := newFakeS3Deps(t, index)
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.Contains(t, fake.objects, asset.S3Key)
+		assert.Equal(t, asset.S3Key, index.repaired[sumHex])
+	})
+
+	t.Run("index error falls back to listing", func(t *testing.T) {
+		listed := prefix + "/2024/02/02/" + sumHex + ".txt"
+		index := &fakeAssetIndex{err: errors.New("db down"), repaired: map[string]string{}}
+		u, fake := newFakeS3Deps(t, index)
+		fake.objects[listed] = content
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.Equal(t, listed, asset.S3Key)
+		assert.Equal(t, 1, fake.lists)
+		assert.Empty(t, index.repaired)
+	})
+
+	t.Run("no index lists the prefix", func(t *testing.T) {
+		listed := prefix + "/2024/02/02/" + sumHex + ".txt"
+		u, fake := newFakeS3Deps(t, nil)
+		fake.objects[listed] = content
+
+		asset, err := u.UploadBytes(ctx, prefix, "a.txt", content, nil)
+		require.NoError(t, err)
+		assert.Equal(t, listed, asset.S3Key)
+		assert.Equal(t, 1, fake.lists)
+	})
+}
+
+func TestProjectIDFromKeyPrefix(t *testing.T) {
+	id := uuid.New()
+
+	got, ok := projectIDFromKeyPrefix("disks/" + id.String())
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+
+	_, ok = projectIDFromKeyPrefix("parts/project-123")
+	assert.False(t, ok)
+	_, ok = projectIDFromKeyPrefix(id.String())
+	assert.False(t, ok)
+}
@@ -0,0 +1,221 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/compress"
+)
+
+const (
+	DriverS3    = "s3"
+	DriverLocal = "local"
+)
+
+// ErrObjectNotFound is returned when no object is stored under a key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrObjectChanged is returned when an object was overwritten after the version a caller
+// verified.
+var ErrObjectChanged = errors.New("object changed")
+
+// ObjectInfo describes a stored object without its content.
+type ObjectInfo struct {
+	Key         string
+	ETag        string
+	Size        int64 // stored size, which for encrypted objects is the ciphertext size
+	ContentType string
+	Metadata    map[string]string // user metadata, including envelope-encryption fields
+
+	LastModified time.Time // set by ListObjects
+}
+
+// AssetIndex locates content a project has already stored, keyed by (project_id, sha256).
+// It is backed by asset_references.
+type AssetIndex interface {
+	// LookupS3Key returns the canonical key of the project's content, or "" when there is none.
+	LookupS3Key(ctx context.Context, projectID uuid.UUID, sha256 string) (string, error)
+	// RepairS3Key repoints the project's entry for the content at key after its object went missing.
+	RepairS3Key(ctx context.Context, projectID uuid.UUID, sha256 string, key string) error
+}
+
+// BlobStore is the object storage behind assets, artifacts and session parts. S3Deps keeps
+// objects in an S3-compatible bucket; LocalStore keeps them on the local filesystem.
+//
+// userKEK is optional everywhere; when non-nil, content is envelope-encrypted on write and
+// decrypted on read, with the wrapped data key kept in the object's metadata. Text content is
+// also compressed before it is encrypted when the store has a Compression algorithm, and
+// decompressed on read.
+type BlobStore interface {
+	// Content-addressed uploads, deduplicated within keyPrefix ("<kind>/<project_id>")
+	UploadFormFile(ctx context.Context, keyPrefix string, fh *multipart.FileHeader, userKEK []byte) (*model.Asset, error)
+	UploadBytes(ctx context.Context, keyPrefix string, filename string, content []byte, userKEK []byte) (*model.Asset, error)
+	UploadJSON(ctx context.Context, keyPrefix string, data interface{}, userKEK []byte) (*model.Asset, error)
+
+	// Deferred uploads: the asset is computed up front and written later
+	PrepareJSONAsset(keyPrefix string, data interface{}) (*PreparedUpload, error)
+	PrepareFormFileAsset(keyPrefix string, fh *multipart.FileHeader) (*PreparedUpload, error)
+	UploadPrepared(ctx context.Context, p *PreparedUpload, userKEK []byte) error
+	// SealPrepared turns a prepared upload into its stored form so it can be written later
+	// by UploadSealed, e.g. after being persisted, without the user's KEK.
+	SealPrepared(p *PreparedUpload, userKEK []byte) (*SealedUpload, error)
+	UploadSealed(ctx context.Context, s *SealedUpload) error
+
+	// Resumable uploads: an object is assembled at a staging key from parts sent over several
+	// requests, then promoted to its content-addressed key once its SHA256 is known. Parts are
+	// stored as given, so callers encrypt them beforehand, and every part but the last must
+	// be at least 5MiB.
+	CreateMultipartUpload(ctx context.Context, key string, contentType string, metadata map[string]string) (uploadID string, err error)
+	UploadPart(ctx context.Context, key string, uploadID string, partNumber int32, data []byte) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []model.UploadPart) error
+	AbortMultipartUpload(ctx context.Context, key string, uploadID string) error
+	// PromoteUpload moves the object at key, holding size bytes of content sumHex, to its
+	// content-addressed key within keyPrefix. Unencrypted content already stored there is
+	// reused and the object at key is deleted instead.
+	PromoteUpload(ctx context.Context, key string, keyPrefix string, sumHex string, ext string, size int64) (*model.Asset, error)
+	// IngestObject is PromoteUpload for a plaintext object a client wrote through PresignPut,
+	// stored as contentType and encrypted on the way when userKEK is non-nil. etag is the
+	// version of the object that was verified; when the client has overwritten it since,
+	// nothing is stored and ErrObjectChanged is returned.
+	IngestObject(ctx context.Context, key string, etag string, keyPrefix string, sumHex string, contentType string, ext string, size int64, userKEK []byte) (*model.Asset, error)
+
+	// UploadFileDirect writes content at an exact key without deduplication.
+	UploadFileDirect(ctx context.Context, key string, content []byte, contentType string, userKEK []byte) (*model.Asset, error)
+
+	// DownloadFile and DownloadJSON read the whole object into memory; OpenObject streams it.
+	DownloadFile(ctx context.Context, key string, userKEK []byte) ([]byte, error)
+	DownloadJSON(ctx context.Context, key string, target interface{}, userKEK []byte) error
+	OpenObject(ctx context.Context, key string, userKEK []byte) (*Object, error)
+	// Head returns ErrObjectNotFound when key holds no object.
+	Head(ctx context.Context, key string) (*ObjectInfo, error)
+
+	DeleteObject(ctx context.Context, key string) error
+	DeleteObjects(ctx context.Context, keys []string) error
+	DeleteObjectsByPrefix(ctx context.Context, prefix string) error
+	// ListObjects calls fn with every object whose key starts with prefix, stopping at the
+	// first error fn returns. Listed objects need not carry their content type or metadata.
+	ListObjects(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+
+	// Time-limited URLs that let clients read or write an object directly
+	PresignGet(ctx context.Context, key string, expire time.Duration) (string, error)
+	PresignPut(ctx context.Context, key string, contentType string, expire time.Duration) (string, error)
+
+	// Envelope encryption of objects already stored
+	EncryptObject(ctx context.Context, key string, userKEK []byte) error
+	DecryptObject(ctx context.Context, key string, userKEK []byte) error
+	RewrapObjectDEK(ctx context.Context, key string, oldKEK, newKEK []byte) error
+}
+
+var (
+	_ BlobStore = (*S3Deps)(nil)
+	_ BlobStore = (*LocalStore)(nil)
+)
+
+// NewBlobStore builds the store selected by cfg.Blob.Driver. index is optional and lets
+// deduplicated uploads skip listing stored objects.
+func NewBlobStore(ctx context.Context, cfg *config.Config, index AssetIndex) (BlobStore, error) {
+	switch cfg.Blob.Driver {
+	case "", DriverS3:
+		s3, err := NewS3(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		s3.Index = index
+		return s3, nil
+	case DriverLocal:
+		local, err := NewLocalStore(cfg)
+		if err != nil {
+			return nil, err
+		}
+		local.Index = index
+		return local, nil
+	default:
+		return nil, fmt.Errorf("unknown blob driver %q", cfg.Blob.Driver)
+	}
+}
+
+// compressionFromConfig returns the algorithm cfg.Blob.Compression selects for new content,
+// or "" to store it uncompressed.
+func compressionFromConfig(cfg *config.Config) (string, error) {
+	switch algo := cfg.Blob.Compression; algo {
+	case "", compress.None:
+		return "", nil
+	case compress.Gzip, compress.Zstd:
+		return algo, nil
+	default:
+		return "", fmt.Errorf("unknown blob compression %q", algo)
+	}
+}
+
+// duplicateProbe is what findDuplicate needs from a driver.
+type duplicateProbe interface {
+	// headAsset describes the object at key as an asset with the given content, returning
+	// ErrObjectNotFound when it is missing.
+	headAsset(ctx context.Context, key string, sumHex string, contentType string) (*model.Asset, error)
+	// findDuplicateByListing scans keyPrefix for an object whose key contains sumHex.
+	findDuplicateByListing(ctx context.Context, keyPrefix string, sumHex string, contentType string) *model.Asset
+}
+
+// findDuplicate returns an existing object under keyPrefix holding content sumHex, or nil.
+// Key prefixes are "<kind>/<project_id>", so the asset index answers with a single head
+// request. Listing keyPrefix is only a fallback for when there is no index, it fails, or it
+// points outside keyPrefix or at a missing object. In the last case the project is returned
+// as staleProject so the caller can repoint the index at the object it ends up using.
+func findDuplicate(ctx context.Context, index AssetIndex, probe duplicateProbe, keyPrefix string, sumHex string, contentType string) (asset *model.Asset, staleProject uuid.UUID) {
+	projectID, ok := projectIDFromKeyPrefix(keyPrefix)
+	if index == nil || !ok {
+		return probe.findDuplicateByListing(ctx, keyPrefix, sumHex, contentType), uuid.Nil
+	}
+
+	key, err := index.LookupS3Key(ctx, projectID, sumHex)
+	if err != nil {
+		return probe.findDuplicateByListing(ctx, keyPrefix, sumHex, contentType), uuid.Nil
+	}
+	if key == "" {
+		return nil, uuid.Nil
+	}
+	if !strings.HasPrefix(key, keyPrefix+"/") {
+		return probe.findDuplicateByListing(ctx, keyPrefix, sumHex, contentType), uuid.Nil
+	}
+
+	asset, err = probe.headAsset(ctx, key, sumHex, contentType)
+	if err == nil {
+		return asset, uuid.Nil
+	}
+	if errors.Is(err, ErrObjectNotFound) {
+		staleProject = projectID
+	}
+	return probe.findDuplicateByListing(ctx, keyPrefix, sumHex, contentType), staleProject
+}
+
+// repairIndex repoints a stale index entry at key. It is best effort: the upload has already
+// succeeded, and a failed repair only means the next upload of the content lists again.
+func repairIndex(ctx context.Context, index AssetIndex, projectID uuid.UUID, sumHex string, key string) {
+	if projectID == uuid.Nil || index == nil {
+		return
+	}
+	_ = index.RepairS3Key(ctx, projectID, sumHex, key)
+}
+
+// projectIDFromKeyPrefix extracts the project ID from a "<kind>/<project_id>" key prefix.
+func projectIDFromKeyPrefix(keyPrefix string) (uuid.UUID, bool) {
+	_, last, _ := strings.Cut(keyPrefix, "/")
+	id, err := uuid.Parse(last)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// contentKey is where content-addressed uploads are stored: keyPrefix/yyyy/mm/dd/sha256.ext.
+func contentKey(keyPrefix string, sumHex string, ext string) string {
+	datePrefix := time.Now().UTC().Format("2006/01/02")
+	return fmt.Sprintf("%s/%s/%s%s", keyPrefix, datePrefix, sumHex, ext)
+}
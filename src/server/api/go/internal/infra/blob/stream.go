@@ -0,0 +1,431 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/compress"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/mime"
+)
+
+var errNoUserKEK = errors.New("encrypted object but no user KEK provided")
+
+// sniffSize is how much of a file's head is kept for MIME detection while it is hashed.
+const sniffSize = 3072
+
+// formFileDigest hashes a multipart file in one streaming pass and detects its MIME type from
+// its head, so the file never has to be held in memory.
+func formFileDigest(fh *multipart.FileHeader) (sumHex string, contentType string, size int64, err error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	head := &headBuffer{limit: sniffSize}
+	size, err = io.Copy(io.MultiWriter(h, head), file)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("read %s: %w", fh.Filename, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), mime.DetectMimeType(head.Bytes(), fh.Filename), size, nil
+}
+
+// headBuffer keeps the first limit bytes written to it and discards the rest.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.Buffer.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+func formFileExt(fh *multipart.FileHeader) string {
+	return strings.ToLower(filepath.Ext(fh.Filename))
+}
+
+// Metadata keys recording that an object's content was compressed before it was stored.
+const (
+	metaCompression = "compression"
+	metaPlainSize   = "plain-size" // content size before compression
+)
+
+// minCompressSize is the smallest content worth compressing.
+const minCompressSize = 1024
+
+// compressContent returns body as it should be written before encryption: compressed with
+// algo when content of contentType and size is worth compressing, with the compression
+// recorded in metadata. close must be called once the returned reader is no longer read.
+func compressContent(body io.Reader, algo string, contentType string, size int64, metadata map[string]string) (compressed io.Reader, close func(), err error) {
+	if !compress.Valid(algo) || size < minCompressSize || !compress.Compressible(contentType) {
+		return body, func() {}, nil
+	}
+	pr, pw := io.Pipe()
+	w, err := compress.NewWriter(algo, pw)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata[metaCompression] = algo
+	metadata[metaPlainSize] = strconv.FormatInt(size, 10)
+	go func() {
+		_, err := io.Copy(w, body)
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, func() { pr.Close() }, nil
+}
+
+// plainSize is the size of an object's content once decrypted and decompressed, given the
+// size it has once decrypted.
+func plainSize(metadata map[string]string, decryptedSize int64) (int64, error) {
+	if metadata[metaCompression] == "" {
+		return decryptedSize, nil
+	}
+	size, err := strconv.ParseInt(metadata[metaPlainSize], 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid %s metadata %q", metaPlainSize, metadata[metaPlainSize])
+	}
+	return size, nil
+}
+
+// decodeContent returns the content of an object read into memory: decrypted, then
+// decompressed.
+func decodeContent(data []byte, metadata map[string]string, userKEK []byte) ([]byte, error) {
+	data, err := decryptWithUserKEK(data, metadata, userKEK)
+	if err != nil {
+		return nil, err
+	}
+	if algo := metadata[metaCompression]; algo != "" {
+		return compress.Decompress(algo, data)
+	}
+	return data, nil
+}
+
+// sealStream returns body as it should be stored: unchanged without userKEK, or encrypted
+// in the chunked format as it is read, with the encryption fields merged into metadata.
+// close must be called once the returned reader is no longer read.
+func sealStream(body io.Reader, userKEK []byte, metadata map[string]string) (sealed io.Reader, close func(), err error) {
+	if userKEK == nil {
+		return body, func() {}, nil
+	}
+	pr, pw := io.Pipe()
+	w, encMeta, err := encryptionpkg.NewEncryptWriter(userKEK, pw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypt data: %w", err)
+	}
+	for k, v := range encMeta.MetadataToMap() {
+		metadata[k] = v
+	}
+	go func() {
+		_, err := io.Copy(w, body)
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	// Closing the read side unblocks the copy if the upload gives up early
+	return pr, func() { pr.Close() }, nil
+}
+
+// openStream returns a reader of the stored object's plaintext given a reader of its stored
+// bytes, decrypting chunked objects as they are read. Objects encrypted as a single message
+// are decrypted in memory.
+func openStream(body io.Reader, storedSize int64, metadata map[string]string, userKEK []byte) (io.Reader, error) {
+	encMeta := encryptionpkg.MetadataFromMap(metadata)
+	if encMeta == nil {
+		return body, nil
+	}
+	if userKEK == nil {
+		return nil, errNoUserKEK
+	}
+	if encMeta.Algo == encryptionpkg.AlgoGCMChunked {
+		return encryptionpkg.NewDecryptReader(userKEK, encMeta, body, 0, storedSize)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read object: %w", err)
+	}
+	plaintext, err := encryptionpkg.DecryptData(userKEK, data, encMeta)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(plaintext), nil
+}
+
+// rangeFetcher reads length stored bytes of an object from offset; a negative length reads
+// to the end.
+type rangeFetcher func(ctx context.Context, offset int64, length int64) (io.ReadCloser, error)
+
+// Object is a stored object opened for streaming reads of its plaintext.
+type Object struct {
+	Key         string
+	ETag        string
+	ContentType string
+	Size        int64 // plaintext size
+
+	storedSize    int64
+	decryptedSize int64  // size once decrypted, which is Size unless compressed
+	compression   string // algorithm the content was compressed with, if any
+	encMeta       *encryptionpkg.EncryptedMeta
+	userKEK       []byte
+	fetch         rangeFetcher
+}
+
+func newObject(info *ObjectInfo, userKEK []byte, fetch rangeFetcher) (*Object, error) {
+	o := &Object{
+		Key:         info.Key,
+		ETag:        info.ETag,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		storedSize:  info.Size,
+		compression: info.Metadata[metaCompression],
+		encMeta:     encryptionpkg.MetadataFromMap(info.Metadata),
+		userKEK:     userKEK,
+		fetch:       fetch,
+	}
+	if o.ContentType == "" {
+		o.ContentType = "application/octet-stream"
+	}
+	if o.encMeta != nil {
+		if userKEK == nil {
+			return nil, errNoUserKEK
+		}
+		switch o.encMeta.Algo {
+		case encryptionpkg.AlgoGCMChunked:
+			size, err := encryptionpkg.ChunkedPlaintextSize(o.encMeta.ChunkSize, info.Size)
+			if err != nil {
+				return nil, fmt.Errorf("object %s: %w", info.Key, err)
+			}
+			o.Size = size
+		default:
+			// nonce + ciphertext + tag
+			o.Size = info.Size - encryptionpkg.NonceSize - encryptionpkg.TagSize
+			if o.Size < 0 {
+				return nil, fmt.Errorf("object %s: ciphertext too short", info.Key)
+			}
+		}
+	}
+	o.decryptedSize = o.Size
+	size, err := plainSize(info.Metadata, o.decryptedSize)
+	if err != nil {
+		return nil, fmt.Errorf("object %s: %w", info.Key, err)
+	}
+	o.Size = size
+	return o, nil
+}
+
+// NewRangeReader returns the plaintext bytes [offset, offset+length) of the object; a negative
+// length reads to the end. Only the stored bytes covering the range are fetched, except for
+// objects encrypted as a single message, which are read in full, and compressed objects,
+// which are decompressed from the start.
+func (o *Object) NewRangeReader(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+	if length < 0 {
+		length = o.Size - offset
+	}
+	if offset < 0 || length < 0 || offset+length > o.Size {
+		return nil, fmt.Errorf("range %d+%d outside %d bytes", offset, length, o.Size)
+	}
+
+	if o.compression == "" {
+		return o.decryptedRange(ctx, offset, length)
+	}
+	rc, err := o.decryptedRange(ctx, 0, o.decryptedSize)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := compress.NewReader(o.compression, rc)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	if _, err := io.CopyN(io.Discard, dr, offset); err != nil {
+		dr.Close()
+		rc.Close()
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	return readCloser{Reader: io.LimitReader(dr, length), Closer: closers{dr, rc}}, nil
+}
+
+// closers closes each of its elements in order, returning the first error.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var first error
+	for _, c := range cs {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// decryptedRange returns the bytes [offset, offset+length) of the object once decrypted.
+func (o *Object) decryptedRange(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+	if o.encMeta == nil {
+		return o.fetch(ctx, offset, length)
+	}
+
+	if o.encMeta.Algo != encryptionpkg.AlgoGCMChunked {
+		rc, err := o.fetch(ctx, 0, -1)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("read object: %w", err)
+		}
+		plaintext, err := encryptionpkg.DecryptData(o.userKEK, data, o.encMeta)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(plaintext[offset : offset+length])), nil
+	}
+
+	ctOffset, ctLength, firstChunk, skip, err := encryptionpkg.ChunkedSpan(o.encMeta.ChunkSize, o.storedSize, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := o.fetch(ctx, ctOffset, ctLength)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := encryptionpkg.NewDecryptReader(o.userKEK, o.encMeta, rc, firstChunk, o.storedSize)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	if _, err := io.CopyN(io.Discard, dr, skip); err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return readCloser{Reader: io.LimitReader(dr, length), Closer: rc}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewReadSeeker returns a seekable reader of the object's plaintext for http.ServeContent.
+// Each read after a seek fetches from the new offset to the end of the object, and the
+// fetch is abandoned on the next seek or on Close.
+func (o *Object) NewReadSeeker(ctx context.Context) io.ReadSeekCloser {
+	return &objectReadSeeker{ctx: ctx, obj: o}
+}
+
+type objectReadSeeker struct {
+	ctx    context.Context
+	obj    *Object
+	offset int64
+	cur    io.ReadCloser
+}
+
+func (r *objectReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.obj.Size {
+		return 0, io.EOF
+	}
+	if r.cur == nil {
+		rc, err := r.obj.NewRangeReader(r.ctx, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.cur = rc
+	}
+	n, err := r.cur.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *objectReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.obj.Size
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+func (r *objectReadSeeker) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	return err
+}
+
+// SealedUpload is a prepared upload in the form it is stored in: compressed and encrypted as
+// UploadPrepared would write it, with the matching object metadata. It can be kept and
+// written later with UploadSealed without holding on to the user's KEK.
+type SealedUpload struct {
+	Asset    model.Asset
+	Content  []byte
+	Metadata map[string]string
+}
+
+// sealPrepared reads a prepared upload into memory in its stored form.
+func sealPrepared(p *PreparedUpload, algo string, userKEK []byte) (*SealedUpload, error) {
+	metadata := make(map[string]string, len(p.Metadata))
+	for k, v := range p.Metadata {
+		metadata[k] = v
+	}
+	body, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	compressed, closeCompressed, err := compressContent(body, algo, p.Asset.MIME, p.Asset.SizeB, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCompressed()
+	sealed, closeSealed, err := sealStream(compressed, userKEK, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSealed()
+
+	content, err := io.ReadAll(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("seal %s: %w", p.Asset.S3Key, err)
+	}
+	return &SealedUpload{Asset: p.Asset, Content: content, Metadata: metadata}, nil
+}
+
+// Decode returns the upload's plaintext content: decrypted, then decompressed.
+func (s *SealedUpload) Decode(userKEK []byte) ([]byte, error) {
+	return decodeContent(s.Content, s.Metadata, userKEK)
+}
@@ -81,7 +81,7 @@ func DecodeContent(userKEK []byte, stored string) (string, error) {
 	ciphertext := raw[3+wrappedDEKLen:]
 
 	encMeta := &EncryptedMeta{
-		Algo:           "AES-256-GCM",
+		Algo:           AlgoGCM,
 		UserWrappedDEK: wrappedDEK,
 	}
 
@@ -1,10 +1,13 @@
 package crypto
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 )
 
 const (
@@ -35,12 +38,25 @@ const (
 	MetaKeyAlgo = "enc-algo"
 	// MetaKeyDEKUser is the S3 metadata key for the user-wrapped DEK.
 	MetaKeyDEKUser = "enc-dek-user"
+	// MetaKeyChunkSize is the S3 metadata key for the plaintext chunk size of chunked objects.
+	MetaKeyChunkSize = "enc-chunk-size"
+	// MetaKeyNoncePrefix is the S3 metadata key for the nonce prefix of chunked objects.
+	MetaKeyNoncePrefix = "enc-nonce-prefix"
+)
+
+const (
+	// AlgoGCM encrypts the whole object as one AES-256-GCM message.
+	AlgoGCM = "AES-256-GCM"
+	// AlgoGCMChunked encrypts the object in independently sealed chunks (see NewEncryptWriter).
+	AlgoGCMChunked = "AES-256-GCM-CHUNKED"
 )
 
 // EncryptedMeta holds the metadata stored alongside an encrypted S3 object.
 type EncryptedMeta struct {
-	Algo           string // "AES-256-GCM"
+	Algo           string // AlgoGCM or AlgoGCMChunked
 	UserWrappedDEK string // base64(nonce + ciphertext)
+	ChunkSize      int    // AlgoGCMChunked only
+	NoncePrefix    string // AlgoGCMChunked only, base64
 }
 
 // EncryptData encrypts plaintext using a user KEK and returns ciphertext + metadata.
@@ -66,7 +82,7 @@ func EncryptData(userKEK, plaintext []byte) (ciphertext []byte, meta *EncryptedM
 	}
 
 	meta = &EncryptedMeta{
-		Algo:           "AES-256-GCM",
+		Algo:           AlgoGCM,
 		UserWrappedDEK: base64.StdEncoding.EncodeToString(userWrapped),
 	}
 	return ciphertext, meta, nil
@@ -80,6 +96,13 @@ func DecryptData(userKEK, ciphertext []byte, meta *EncryptedMeta) ([]byte, error
 	if meta == nil {
 		return nil, errors.New("crypto: encrypted metadata is required")
 	}
+	if meta.Algo == AlgoGCMChunked {
+		r, err := NewDecryptReader(userKEK, meta, bytes.NewReader(ciphertext), 0, int64(len(ciphertext)))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
 	wrapped, err := base64.StdEncoding.DecodeString(meta.UserWrappedDEK)
 	if err != nil {
 		return nil, fmt.Errorf("crypto: decode user wrapped DEK: %w", err)
@@ -125,16 +148,23 @@ func RewrapDEK(meta *EncryptedMeta, oldUserKEK, newUserKEK []byte) (string, erro
 
 // MetadataToMap converts EncryptedMeta to S3-compatible metadata map.
 func (m *EncryptedMeta) MetadataToMap() map[string]string {
-	return map[string]string{
+	out := map[string]string{
 		MetaKeyAlgo:    m.Algo,
 		MetaKeyDEKUser: m.UserWrappedDEK,
 	}
+	if m.Algo == AlgoGCMChunked {
+		out[MetaKeyChunkSize] = strconv.Itoa(m.ChunkSize)
+		out[MetaKeyNoncePrefix] = m.NoncePrefix
+	}
+	return out
 }
 
 // ClearFromMap removes encryption metadata keys from the given map.
 func ClearEncryptionMetadata(metadata map[string]string) {
 	delete(metadata, MetaKeyAlgo)
 	delete(metadata, MetaKeyDEKUser)
+	delete(metadata, MetaKeyChunkSize)
+	delete(metadata, MetaKeyNoncePrefix)
 }
 
 // MetadataFromMap extracts EncryptedMeta from S3 object metadata.
@@ -144,9 +174,13 @@ func MetadataFromMap(metadata map[string]string) *EncryptedMeta {
 	if !ok || algo == "" {
 		return nil
 	}
+	// an unparsable chunk size is left at 0 and rejected when decrypting
+	chunkSize, _ := strconv.Atoi(metadata[MetaKeyChunkSize])
 	return &EncryptedMeta{
 		Algo:           algo,
 		UserWrappedDEK: metadata[MetaKeyDEKUser],
+		ChunkSize:      chunkSize,
+		NoncePrefix:    metadata[MetaKeyNoncePrefix],
 	}
 }
 
@@ -0,0 +1,338 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Chunked envelope encryption lets objects be encrypted and decrypted as streams, and lets a
+// byte range be decrypted without reading the rest of the object.
+//
+// The plaintext is split into chunks of ChunkSize bytes (the last one may be shorter, and is
+// empty only for empty plaintext). Each chunk is sealed on its own with AES-256-GCM under the
+// object's DEK, so the ciphertext is the concatenation of chunk+tag segments with nothing in
+// between. A chunk's nonce is the object's random 7-byte prefix, the chunk index as 4
+// big-endian bytes, and a final byte set to 1 for the last chunk only; this binds every
+// segment to its position and detects truncation. The prefix and chunk size are kept in the
+// object metadata next to the wrapped DEK.
+
+const (
+	// DefaultChunkSize is the plaintext size of each chunk written by NewEncryptWriter.
+	DefaultChunkSize = 64 * 1024
+	// TagSize is the GCM authentication tag appended to every chunk.
+	TagSize = 16
+
+	noncePrefixSize = NonceSize - 5
+	// maxChunkSize bounds the buffer a reader allocates from untrusted metadata.
+	maxChunkSize = 16 << 20
+)
+
+// ErrTruncated is returned when chunked ciphertext ends before its final chunk.
+var ErrTruncated = errors.New("crypto: chunked ciphertext is truncated")
+
+// NewEncryptWriter returns a writer that encrypts what is written to it into dst with a new
+// DEK wrapped by userKEK, along with the metadata to store next to the ciphertext. Close
+// writes the final chunk and must be called; it does not close dst.
+func NewEncryptWriter(userKEK []byte, dst io.Writer) (io.WriteCloser, *EncryptedMeta, error) {
+	meta, err := NewChunkedMeta(userKEK)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := NewChunkWriter(userKEK, meta, dst, 0, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, meta, nil
+}
+
+// NewChunkedMeta returns the metadata of an object to be encrypted in chunks under a new DEK
+// wrapped by userKEK. It lets an object be encrypted piece by piece with NewChunkWriter, e.g.
+// when its parts arrive in separate requests.
+func NewChunkedMeta(userKEK []byte) (*EncryptedMeta, error) {
+	if userKEK == nil {
+		return nil, errors.New("crypto: user KEK is required")
+	}
+	dek, err := GenerateDEK()
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, fmt.Errorf("crypto: generate nonce prefix: %w", err)
+	}
+	userWrapped, err := WrapDEK(userKEK, dek)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: wrap DEK with user KEK: %w", err)
+	}
+	return &EncryptedMeta{
+		Algo:           AlgoGCMChunked,
+		UserWrappedDEK: base64.StdEncoding.EncodeToString(userWrapped),
+		ChunkSize:      DefaultChunkSize,
+		NoncePrefix:    base64.StdEncoding.EncodeToString(prefix),
+	}, nil
+}
+
+// NewChunkWriter returns a writer that encrypts a piece of the plaintext of meta's object into
+// dst. The piece must start at chunk firstChunk, and unless final, which marks the piece that
+// ends the plaintext, its size must be a multiple of the chunk size. Close must be called.
+func NewChunkWriter(userKEK []byte, meta *EncryptedMeta, dst io.Writer, firstChunk int64, final bool) (io.WriteCloser, error) {
+	gcm, prefix, err := chunkedCipher(userKEK, meta)
+	if err != nil {
+		return nil, err
+	}
+	if firstChunk < 0 || firstChunk > int64(^uint32(0)) {
+		return nil, fmt.Errorf("crypto: chunk %d out of range", firstChunk)
+	}
+	return &encryptWriter{
+		dst:    dst,
+		gcm:    gcm,
+		prefix: prefix,
+		index:  uint32(firstChunk),
+		final:  final,
+		buf:    make([]byte, 0, meta.ChunkSize),
+		out:    make([]byte, 0, meta.ChunkSize+TagSize),
+	}, nil
+}
+
+// chunkedCipher unwraps the DEK of meta's chunked object and returns its cipher and nonce prefix.
+func chunkedCipher(userKEK []byte, meta *EncryptedMeta) (cipher.AEAD, []byte, error) {
+	if userKEK == nil {
+		return nil, nil, errors.New("crypto: user KEK is required")
+	}
+	if meta == nil || meta.Algo != AlgoGCMChunked {
+		return nil, nil, errors.New("crypto: chunked encryption metadata is required")
+	}
+	if meta.ChunkSize <= 0 || meta.ChunkSize > maxChunkSize {
+		return nil, nil, fmt.Errorf("crypto: invalid chunk size %d", meta.ChunkSize)
+	}
+	prefix, err := base64.StdEncoding.DecodeString(meta.NoncePrefix)
+	if err != nil || len(prefix) != noncePrefixSize {
+		return nil, nil, errors.New("crypto: invalid nonce prefix")
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(meta.UserWrappedDEK)
+	if err != nil {
+		return nil, nil, fmt.Errorf("crypto: decode user wrapped DEK: %w", err)
+	}
+	dek, err := UnwrapDEK(userKEK, wrapped)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := newGCM(dek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, prefix, nil
+}
+
+type encryptWriter struct {
+	dst    io.Writer
+	gcm    cipher.AEAD
+	prefix []byte
+	index  uint32
+	final  bool   // whether Close seals the object's last chunk
+	buf    []byte // plaintext of the pending chunk
+	out    []byte
+	closed bool
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("crypto: write to closed encrypt writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data arrives, since the last one is marked
+		if len(w.buf) == cap(w.buf) {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if !w.final {
+		if len(w.buf) == 0 {
+			return nil
+		}
+		if len(w.buf) < cap(w.buf) {
+			return errors.New("crypto: piece does not end on a chunk boundary")
+		}
+		return w.seal(false)
+	}
+	return w.seal(true)
+}
+
+func (w *encryptWriter) seal(last bool) error {
+	if w.index == ^uint32(0) {
+		return errors.New("crypto: too many chunks")
+	}
+	nonce := chunkNonce(w.prefix, int64(w.index), last)
+	w.out = w.gcm.Seal(w.out[:0], nonce, w.buf, nil)
+	if _, err := w.dst.Write(w.out); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	w.index++
+	return nil
+}
+
+// NewDecryptReader decrypts chunked ciphertext read from src. src must start at the segment of
+// chunk firstChunk of an object whose ciphertext is ciphertextSize bytes long; reading stops
+// after the object's final chunk. Use ChunkedSpan to read a plaintext range.
+func NewDecryptReader(userKEK []byte, meta *EncryptedMeta, src io.Reader, firstChunk int64, ciphertextSize int64) (io.Reader, error) {
+	gcm, prefix, err := chunkedCipher(userKEK, meta)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := chunkCount(meta.ChunkSize, ciphertextSize)
+	if err != nil {
+		return nil, err
+	}
+	if firstChunk < 0 || firstChunk >= chunks {
+		return nil, fmt.Errorf("crypto: chunk %d out of range", firstChunk)
+	}
+	return &decryptReader{
+		src:       src,
+		gcm:       gcm,
+		prefix:    prefix,
+		chunkSize: int64(meta.ChunkSize),
+		index:     firstChunk,
+		last:      chunks - 1,
+		ctSize:    ciphertextSize,
+		seg:       make([]byte, meta.ChunkSize+TagSize),
+	}, nil
+}
+
+type decryptReader struct {
+	src       io.Reader
+	gcm       cipher.AEAD
+	prefix    []byte
+	chunkSize int64
+	index     int64 // next chunk to decrypt
+	last      int64 // index of the object's final chunk
+	ctSize    int64
+	seg       []byte
+	plain     []byte // decrypted bytes not yet returned
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.index > r.last {
+			return 0, io.EOF
+		}
+		segLen := r.chunkSize + TagSize
+		if r.index == r.last {
+			segLen = r.ctSize - r.last*(r.chunkSize+TagSize)
+		}
+		seg := r.seg[:segLen]
+		if _, err := io.ReadFull(r.src, seg); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return 0, ErrTruncated
+			}
+			return 0, err
+		}
+		nonce := chunkNonce(r.prefix, r.index, r.index == r.last)
+		plain, err := r.gcm.Open(seg[:0], nonce, seg, nil)
+		if err != nil {
+			return 0, fmt.Errorf("crypto: decrypt chunk %d: %w", r.index, err)
+		}
+		r.plain = plain
+		r.index++
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// ChunkedPlaintextSize returns the plaintext size of chunked ciphertext of ciphertextSize bytes.
+func ChunkedPlaintextSize(chunkSize int, ciphertextSize int64) (int64, error) {
+	chunks, err := chunkCount(chunkSize, ciphertextSize)
+	if err != nil {
+		return 0, err
+	}
+	return ciphertextSize - chunks*TagSize, nil
+}
+
+// ChunkedSpan locates plaintext bytes [offset, offset+length) in chunked ciphertext of
+// ciphertextSize bytes. It returns the ciphertext byte span to read, the index of the chunk
+// that span starts with, and how many decrypted bytes precede offset in that chunk.
+func ChunkedSpan(chunkSize int, ciphertextSize int64, offset int64, length int64) (ctOffset int64, ctLength int64, firstChunk int64, skip int64, err error) {
+	chunks, err := chunkCount(chunkSize, ciphertextSize)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	plainSize := ciphertextSize - chunks*TagSize
+	if offset < 0 || length < 0 || offset+length > plainSize {
+		return 0, 0, 0, 0, fmt.Errorf("crypto: range %d+%d outside %d bytes", offset, length, plainSize)
+	}
+	cs := int64(chunkSize)
+	firstChunk = offset / cs
+	if firstChunk >= chunks {
+		// an empty range at the very end still needs a segment to start from
+		firstChunk = chunks - 1
+	}
+	lastChunk := firstChunk
+	if length > 0 {
+		lastChunk = (offset + length - 1) / cs
+	}
+	ctOffset = firstChunk * (cs + TagSize)
+	ctEnd := (lastChunk + 1) * (cs + TagSize)
+	if ctEnd > ciphertextSize {
+		ctEnd = ciphertextSize
+	}
+	return ctOffset, ctEnd - ctOffset, firstChunk, offset - firstChunk*cs, nil
+}
+
+// chunkCount returns how many segments make up chunked ciphertext of ciphertextSize bytes.
+func chunkCount(chunkSize int, ciphertextSize int64) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("crypto: invalid chunk size %d", chunkSize)
+	}
+	if ciphertextSize < TagSize {
+		return 0, ErrTruncated
+	}
+	segment := int64(chunkSize) + TagSize
+	chunks := (ciphertextSize + segment - 1) / segment
+	if ciphertextSize-(chunks-1)*segment < TagSize {
+		return 0, ErrTruncated
+	}
+	return chunks, nil
+}
+
+func chunkNonce(prefix []byte, index int64, last bool) []byte {
+	nonce := make([]byte, NonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], uint32(index))
+	if last {
+		nonce[NonceSize-1] = 1
+	}
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: new cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: new GCM: %w", err)
+	}
+	return gcm, nil
+}
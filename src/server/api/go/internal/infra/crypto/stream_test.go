@@ -0,0 +1,183 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptChunked(t *testing.T, kek []byte, plaintext []byte) ([]byte, *EncryptedMeta) {
+	t.Helper()
+	var buf bytes.Buffer
+	w, meta, err := NewEncryptWriter(kek, &buf)
+	require.NoError(t, err)
+	// Write in uneven pieces so chunk boundaries don't line up with writes
+	for rest := plaintext; len(rest) > 0; {
+		n := min(len(rest), 1000)
+		_, err := w.Write(rest[:n])
+		require.NoError(t, err)
+		rest = rest[n:]
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes(), meta
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestChunkedEncryption_RoundTrip(t *testing.T) {
+	kek := generateTestKEK(t)
+	sizes := []int{0, 1, DefaultChunkSize - 1, DefaultChunkSize, DefaultChunkSize + 1, 3*DefaultChunkSize + 17}
+
+	for _, size := range sizes {
+		plaintext := randomBytes(t, size)
+		ct, meta := encryptChunked(t, kek, plaintext)
+		assert.Equal(t, AlgoGCMChunked, meta.Algo)
+
+		plainSize, err := ChunkedPlaintextSize(meta.ChunkSize, int64(len(ct)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), plainSize)
+
+		r, err := NewDecryptReader(kek, meta, bytes.NewReader(ct), 0, int64(len(ct)))
+		require.NoError(t, err)
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got, "size %d", size)
+
+		got, err = DecryptData(kek, ct, meta)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got, "size %d", size)
+	}
+}
+
+func TestChunkedEncryption_Range(t *testing.T) {
+	kek := generateTestKEK(t)
+	plaintext := randomBytes(t, 4*DefaultChunkSize+100)
+	ct, meta := encryptChunked(t, kek, plaintext)
+
+	ranges := [][2]int64{
+		{0, 10},
+		{DefaultChunkSize - 5, 10},
+		{DefaultChunkSize, DefaultChunkSize},
+		{3*DefaultChunkSize + 50, DefaultChunkSize + 50},
+		{int64(len(plaintext)), 0},
+	}
+	for _, rg := range ranges {
+		offset, length := rg[0], rg[1]
+		ctOffset, ctLength, firstChunk, skip, err := ChunkedSpan(meta.ChunkSize, int64(len(ct)), offset, length)
+		require.NoError(t, err)
+
+		r, err := NewDecryptReader(kek, meta, bytes.NewReader(ct[ctOffset:ctOffset+ctLength]), firstChunk, int64(len(ct)))
+		require.NoError(t, err)
+		_, err = io.CopyN(io.Discard, r, skip)
+		require.NoError(t, err)
+		got, err := io.ReadAll(io.LimitReader(r, length))
+		require.NoError(t, err)
+		assert.Equal(t, plaintext[offset:offset+length], got, "range %d+%d", offset, length)
+	}
+
+	_, _, _, _, err := ChunkedSpan(meta.ChunkSize, int64(len(ct)), int64(len(plaintext)), 1)
+	assert.Error(t, err)
+}
+
+func TestChunkedEncryption_Pieces(t *testing.T) {
+	kek := generateTestKEK(t)
+	plaintext := randomBytes(t, 5*DefaultChunkSize+300)
+	meta, err := NewChunkedMeta(kek)
+	require.NoError(t, err)
+
+	// Encrypt two chunks per piece independently, as parts of a multipart upload are
+	pieceSize := 2 * DefaultChunkSize
+	var ct bytes.Buffer
+	for start := 0; start < len(plaintext); start += pieceSize {
+		end := min(start+pieceSize, len(plaintext))
+		w, err := NewChunkWriter(kek, meta, &ct, int64(start/DefaultChunkSize), end == len(plaintext))
+		require.NoError(t, err)
+		_, err = w.Write(plaintext[start:end])
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	got, err := DecryptData(kek, ct.Bytes(), meta)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	// A non-final piece must end on a chunk boundary
+	w, err := NewChunkWriter(kek, meta, io.Discard, 0, false)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext[:DefaultChunkSize+1])
+	require.NoError(t, err)
+	assert.Error(t, w.Close())
+}
+
+func TestChunkedEncryption_Tampering(t *testing.T) {
+	kek := generateTestKEK(t)
+	plaintext := randomBytes(t, 3*DefaultChunkSize)
+	ct, meta := encryptChunked(t, kek, plaintext)
+	segment := DefaultChunkSize + TagSize
+
+	decrypt := func(data []byte) error {
+		r, err := NewDecryptReader(kek, meta, bytes.NewReader(data), 0, int64(len(data)))
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	t.Run("truncated stream", func(t *testing.T) {
+		r, err := NewDecryptReader(kek, meta, bytes.NewReader(ct[:len(ct)-10]), 0, int64(len(ct)))
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(t, err, ErrTruncated)
+	})
+
+	t.Run("dropped final chunk", func(t *testing.T) {
+		assert.Error(t, decrypt(ct[:2*segment]))
+	})
+
+	t.Run("reordered chunks", func(t *testing.T) {
+		swapped := append([]byte{}, ct[segment:2*segment]...)
+		swapped = append(swapped, ct[:segment]...)
+		swapped = append(swapped, ct[2*segment:]...)
+		assert.Error(t, decrypt(swapped))
+	})
+
+	t.Run("flipped bit", func(t *testing.T) {
+		flipped := append([]byte{}, ct...)
+		flipped[segment+3] ^= 1
+		assert.Error(t, decrypt(flipped))
+	})
+
+	t.Run("wrong KEK", func(t *testing.T) {
+		_, err := NewDecryptReader(generateTestKEK(t), meta, bytes.NewReader(ct), 0, int64(len(ct)))
+		assert.Error(t, err)
+	})
+}
+
+func TestChunkedEncryption_MetadataRoundTrip(t *testing.T) {
+	kek := generateTestKEK(t)
+	ct, meta := encryptChunked(t, kek, []byte("hello world"))
+
+	parsed := MetadataFromMap(meta.MetadataToMap())
+	require.NotNil(t, parsed)
+	assert.Equal(t, meta, parsed)
+
+	got, err := DecryptData(kek, ct, parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+
+	m := meta.MetadataToMap()
+	m["other"] = "kept"
+	ClearEncryptionMetadata(m)
+	assert.Equal(t, map[string]string{"other": "kept"}, m)
+}
@@ -24,14 +24,14 @@ import (
 type AdminHandler struct {
 	projectSvc   service.ProjectService
 	projectRepo  repo.ProjectRepo
-	s3           *blob.S3Deps
+	s3           blob.BlobStore
 	assetRefRepo repo.AssetReferenceRepo
 	db           *gorm.DB
 	rdb          *redis.Client
 	cfg          *config.Config
 }
 
-func NewAdminHandler(projectSvc service.ProjectService, projectRepo repo.ProjectRepo, s3 *blob.S3Deps, assetRefRepo repo.AssetReferenceRepo, db *gorm.DB, rdb *redis.Client, cfg *config.Config) *AdminHandler {
+func NewAdminHandler(projectSvc service.ProjectService, projectRepo repo.ProjectRepo, s3 blob.BlobStore, assetRefRepo repo.AssetReferenceRepo, db *gorm.DB, rdb *redis.Client, cfg *config.Config) *AdminHandler {
 	return &AdminHandler{
 		projectSvc:   projectSvc,
 		projectRepo:  projectRepo,
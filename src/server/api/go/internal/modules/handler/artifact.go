@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	pathpkg "path"
 	"strings"
@@ -21,6 +23,8 @@ import (
 	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/fileparser"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/path"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/textedit"
+	"gorm.io/gorm"
 )
 
 type ArtifactHandler struct {
@@ -28,22 +32,80 @@ type ArtifactHandler struct {
 	diskRepo    repo.DiskRepo
 	config      *config.Config
 	coreClient  *httpclient.CoreClient
-	s3          *blob.S3Deps
+	s3          blob.BlobStore
 	materialSvc service.MaterialService
+	leaseSvc    service.ArtifactLeaseService
+	uploadSvc   service.UploadService
 }
 
-func NewArtifactHandler(s service.ArtifactService, diskRepo repo.DiskRepo, cfg *config.Config, coreClient *httpclient.CoreClient, s3 *blob.S3Deps, materialSvc service.MaterialService) *ArtifactHandler {
-	return &ArtifactHandler{svc: s, diskRepo: diskRepo, config: cfg, coreClient: coreClient, s3: s3, materialSvc: materialSvc}
+func NewArtifactHandler(s service.ArtifactService, diskRepo repo.DiskRepo, cfg *config.Config, coreClient *httpclient.CoreClient, s3 blob.BlobStore, materialSvc service.MaterialService, leaseSvc service.ArtifactLeaseService, uploadSvc service.UploadService) *ArtifactHandler {
+	return &ArtifactHandler{svc: s, diskRepo: diskRepo, config: cfg, coreClient: coreClient, s3: s3, materialSvc: materialSvc, leaseSvc: leaseSvc, uploadSvc: uploadSvc}
+}
+
+// artifactETag is the entity tag of an artifact's current content: its quoted asset SHA256.
+func artifactETag(a *model.Artifact) string {
+	return `"` + a.AssetMeta.Data().SHA256 + `"`
+}
+
+// parseETags splits an If-Match or If-None-Match header into SHA256 values. Entity tags may be
+// quoted, weak (W/) or a bare SHA256; "*" is kept as is.
+func parseETags(header string) []string {
+	var tags []string
+	for _, t := range strings.Split(header, ",") {
+		t = strings.Trim(strings.TrimPrefix(strings.TrimSpace(t), "W/"), `"`)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// artifactPrecondition reads the conditional request headers guarding a write.
+func artifactPrecondition(c *gin.Context) repo.ArtifactPrecondition {
+	return repo.ArtifactPrecondition{
+		IfMatch:     parseETags(c.GetHeader("If-Match")),
+		IfNoneMatch: parseETags(c.GetHeader("If-None-Match")),
+	}
+}
+
+func (h *ArtifactHandler) checkLeases(c *gin.Context, diskID uuid.UUID, paths ...string) bool {
+	return checkLeases(c, h.leaseSvc, diskID, paths...)
+}
+
+// checkLeases rejects a write with 423 when another owner holds a lease covering any of paths.
+// The writer identifies itself with the X-Lease-Owner header. It reports whether the write may proceed.
+func checkLeases(c *gin.Context, leaseSvc service.ArtifactLeaseService, diskID uuid.UUID, paths ...string) bool {
+	if leaseSvc == nil {
+		return true
+	}
+	if err := leaseSvc.CheckWrite(c.Request.Context(), diskID, c.GetHeader("X-Lease-Owner"), paths...); err != nil {
+		if errors.Is(err, service.ErrArtifactLocked) {
+			c.JSON(http.StatusLocked, serializer.Err(http.StatusLocked, "artifact is locked", err))
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return false
+	}
+	return true
 }
 
 type CreateArtifactReq struct {
 	FilePath string `form:"file_path" json:"file_path"` // Optional, defaults to "/"
 	Meta     string `form:"meta" json:"meta"`
+	UploadID string `form:"upload_id" json:"upload_id"` // Completed resumable upload, instead of file
 }
 
 type GrepArtifactsReq struct {
-	Query string `form:"query" json:"query" binding:"required" example:"TODO.*"`
-	Limit *int   `form:"limit" json:"limit" binding:"omitempty,min=0,max=200" example:"20"`
+	Query         string   `form:"query" json:"query" binding:"required" example:"TODO.*"`
+	Limit         *int     `form:"limit" json:"limit" binding:"omitempty,min=0,max=200" example:"20"`
+	CaseSensitive bool     `form:"case_sensitive" json:"case_sensitive" example:"false"`
+	FixedStrings  bool     `form:"fixed_strings" json:"fixed_strings" example:"false"`
+	Include       []string `form:"include" json:"include" example:"*.py"`
+	Exclude       []string `form:"exclude" json:"exclude" example:"/vendor/*"`
+	BeforeContext *int     `form:"before_context" json:"before_context" binding:"omitempty,min=0,max=20" example:"2"`
+	AfterContext  *int     `form:"after_context" json:"after_context" binding:"omitempty,min=0,max=20" example:"2"`
+	Context       int      `form:"context" json:"context" binding:"omitempty,min=0,max=20" example:"0"`
+	MaxCount      int      `form:"max_count" json:"max_count" binding:"omitempty,min=0,max=1000" example:"100"`
 }
 
 type GlobArtifactsReq struct {
@@ -54,17 +116,24 @@ type GlobArtifactsReq struct {
 // UpsertArtifact godoc
 //
 //	@Summary		Upsert artifact
-//	@Description	Upload a file and create or update an artifact record under a disk. File size must not exceed the configured maximum upload size limit (default: 16MB).
+//	@Description	Upload a file and create or update an artifact record under a disk. File size must not exceed the configured maximum upload size limit (default: 1GB). Instead of sending the file, upload_id may reference a completed resumable upload with target "disk" (see POST /upload), which is how large files are sent over unreliable connections.
 //	@Tags			artifact
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Param			disk_id		path		string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
 //	@Param			file_path	formData	string	false	"File path in the disk storage (optional, defaults to '/')"
-//	@Param			file		formData	file	true	"File to upload (size must not exceed configured limit)"
+//	@Param			file		formData	file	false	"File to upload (size must not exceed configured limit); required unless upload_id is given"
+//	@Param			upload_id	formData	string	false	"ID of a completed resumable upload to use as the file"
 //	@Param			meta		formData	string	false	"Custom metadata as JSON string (optional, system metadata will be stored under '__artifact_info__' key)"
+//	@Param			If-Match		header		string	false	"Only write if the current artifact's ETag (or SHA256) is listed, or exists for '*'"
+//	@Param			If-None-Match	header		string	false	"Only write if the current artifact's ETag is not listed; '*' creates the artifact only if it does not exist"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
 //	@Security		BearerAuth
 //	@Success		201	{object}	serializer.Response{data=model.Artifact}
+//	@Header			201	{string}	ETag				"ETag of the written content"
+//	@Failure		412	{object}	serializer.Response	"Artifact does not match If-Match or If-None-Match"
 //	@Failure		413	{object}	serializer.Response	"File size exceeds maximum allowed size"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
 //	@Router			/disk/{disk_id}/artifact [post]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Upload a file to disk\nwith open('report.pdf', 'rb') as f:\n    artifact = client.disks.upload_artifact(\n        disk_id='disk-uuid',\n        file=f,\n        file_path='/documents/',\n        meta={'category': 'reports', 'year': 2024}\n    )\nprint(f\"Uploaded artifact: {artifact.id}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\nimport fs from 'fs';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Upload a file to disk\nconst fileBuffer = fs.readFileSync('report.pdf');\nconst artifact = await client.disks.uploadArtifact('disk-uuid', {\n  file: fileBuffer,\n  filePath: '/documents/',\n  meta: { category: 'reports', year: 2024 }\n});\nconsole.log(`Uploaded artifact: ${artifact.id}`);\n","label":"JavaScript"}]
 func (h *ArtifactHandler) UpsertArtifact(c *gin.Context) {
@@ -91,25 +160,44 @@ func (h *ArtifactHandler) UpsertArtifact(c *gin.Context) {
 		return
 	}
 
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, serializer.ParamErr("file is required", err))
-		return
-	}
+	var file *multipart.FileHeader
+	var asset *model.Asset
+	var actualFilename string
+	if req.UploadID != "" {
+		uploadID, err := uuid.Parse(req.UploadID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid upload_id", err))
+			return
+		}
+		upload, err := h.uploadSvc.Resolve(c.Request.Context(), project.ID, uploadID, model.UploadTargetDisk)
+		if err != nil {
+			uploadErr(c, err)
+			return
+		}
+		uploaded := upload.AssetMeta.Data()
+		asset = &uploaded
+		actualFilename = upload.Filename
+	} else {
+		file, err = c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("file is required", err))
+			return
+		}
 
-	// Validate file size
-	maxSize := h.config.Artifact.MaxUploadSizeBytes
-	if file.Size > maxSize {
-		maxSizeMB := float64(maxSize) / (1024 * 1024)
-		c.JSON(http.StatusRequestEntityTooLarge, serializer.ParamErr("", fmt.Errorf("file size exceeds maximum allowed size of %.2fMB", maxSizeMB)))
-		return
+		// Validate file size
+		maxSize := h.config.Artifact.MaxUploadSizeBytes
+		if file.Size > maxSize {
+			maxSizeMB := float64(maxSize) / (1024 * 1024)
+			c.JSON(http.StatusRequestEntityTooLarge, serializer.ParamErr("", fmt.Errorf("file size exceeds maximum allowed size of %.2fMB", maxSizeMB)))
+			return
+		}
+		actualFilename = file.Filename
 	}
 
 	// Parse FilePath to extract path and filename
 	filePath, _ := path.SplitFilePath(req.FilePath)
 
 	// Use the filename from the uploaded file, not from the path
-	actualFilename := file.Filename
 
 	// Validate the path parameter
 	if err := path.ValidatePath(filePath); err != nil {
@@ -135,38 +223,224 @@ func (h *ArtifactHandler) UpsertArtifact(c *gin.Context) {
 		}
 	}
 
+	if !h.checkLeases(c, diskID, filePath+actualFilename) {
+		return
+	}
+
 	artifactRecord, err := h.svc.Create(c.Request.Context(), service.CreateArtifactInput{
-		ProjectID:  project.ID,
-		DiskID:     diskID,
-		Path:       filePath,
-		Filename:   actualFilename,
-		FileHeader: file,
-		UserMeta:   userMeta,
-		UserKEK:    middleware.GetUserKEKIfEncrypted(c),
+		ProjectID:    project.ID,
+		DiskID:       diskID,
+		Path:         filePath,
+		Filename:     actualFilename,
+		FileHeader:   file,
+		Asset:        asset,
+		UserMeta:     userMeta,
+		UserKEK:      middleware.GetUserKEKIfEncrypted(c),
+		Precondition: artifactPrecondition(c),
 	})
 	if err != nil {
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			c.JSON(http.StatusPreconditionFailed, serializer.Err(http.StatusPreconditionFailed, "artifact was modified", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
 
+	c.Header("ETag", artifactETag(artifactRecord))
+	c.JSON(http.StatusCreated, serializer.Response{Data: artifactRecord})
+}
+
+type CreateArtifactUploadURLReq struct {
+	ContentType string `json:"content_type" example:"application/pdf"`                     // Content type the PUT must send; defaults to application/octet-stream
+	Expire      int    `json:"expire" binding:"omitempty,min=1,max=604800" example:"3600"` // Expire time in seconds for the upload URL (default: 3600)
+}
+
+// CreateArtifactUploadURL godoc
+//
+//	@Summary		Create artifact upload URL
+//	@Description	Get a presigned URL to PUT a file to storage directly, so large files do not pass through the API. The PUT must send the returned content_type as its Content-Type header. Once uploaded, commit the file with POST /disk/{disk_id}/artifact/commit before the URL expires. Uploads that are never committed are deleted once no upload URL can still be valid, 7 days after they were written.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string								true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.CreateArtifactUploadURLReq	false	"Upload URL request"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=service.PresignedUpload}
+//	@Failure		404	{object}	serializer.Response	"Disk not found"
+//	@Router			/disk/{disk_id}/artifact/upload_url [post]
+func (h *ArtifactHandler) CreateArtifactUploadURL(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := CreateArtifactUploadURLReq{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
+	}
+	if req.Expire == 0 {
+		req.Expire = 3600
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	upload, err := h.svc.PresignUpload(c.Request.Context(), project.ID, req.ContentType, time.Duration(req.Expire)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.Err(http.StatusInternalServerError, "failed to create upload URL", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, serializer.Response{Data: upload})
+}
+
+type CommitArtifactReq struct {
+	UploadKey string `json:"upload_key" binding:"required"`                                              // Key returned with the upload URL
+	FilePath  string `json:"file_path" binding:"required" example:"/documents/report.pdf"`               // File path including filename
+	Size      *int64 `json:"size" binding:"required,min=0" example:"1048576"`                            // Size of the uploaded file in bytes
+	SHA256    string `json:"sha256" binding:"required,len=64,hexadecimal" example:"9f86d081884c7d65..."` // Hex SHA256 of the uploaded file
+	Meta      string `json:"meta"`                                                                       // Custom metadata as JSON string
+}
+
+// CommitArtifact godoc
+//
+//	@Summary		Commit uploaded artifact
+//	@Description	Create or update an artifact from a file uploaded through POST /disk/{disk_id}/artifact/upload_url. The uploaded file's size and SHA256 must match the ones given; a file that does not match is discarded and has to be uploaded again. Its MIME type is detected and text is extracted for grep as for any other upload.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id			path	string						true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request			body	handler.CommitArtifactReq	true	"Commit artifact request"
+//	@Param			If-Match		header	string						false	"Only write if the current artifact's ETag (or SHA256) is listed, or exists for '*'"
+//	@Param			If-None-Match	header	string						false	"Only write if the current artifact's ETag is not listed; '*' creates the artifact only if it does not exist"
+//	@Param			X-Lease-Owner	header	string						false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.Artifact}
+//	@Header			201	{string}	ETag				"ETag of the written content"
+//	@Failure		400	{object}	serializer.Response	"Invalid request, or the uploaded file does not match size or sha256"
+//	@Failure		404	{object}	serializer.Response	"Disk not found, or nothing was uploaded to upload_key"
+//	@Failure		412	{object}	serializer.Response	"Artifact does not match If-Match or If-None-Match"
+//	@Failure		413	{object}	serializer.Response	"File size exceeds maximum allowed size"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/artifact/commit [post]
+func (h *ArtifactHandler) CommitArtifact(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := CommitArtifactReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	filePath, filename := path.SplitFilePath(req.FilePath)
+	if filename == "" {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("file_path must include a filename")))
+		return
+	}
+	if err := path.ValidatePath(filePath); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	var userMeta map[string]interface{}
+	if req.Meta != "" {
+		if err := sonic.Unmarshal([]byte(req.Meta), &userMeta); err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid meta JSON format", err))
+			return
+		}
+		for _, reservedKey := range (model.Artifact{}).GetReservedKeys() {
+			if _, exists := userMeta[reservedKey]; exists {
+				c.JSON(http.StatusBadRequest, serializer.ParamErr("", fmt.Errorf("reserved key '%s' is not allowed in user meta", reservedKey)))
+				return
+			}
+		}
+	}
+
+	if !h.checkLeases(c, diskID, filePath+filename) {
+		return
+	}
+
+	artifactRecord, err := h.svc.Commit(c.Request.Context(), service.CommitArtifactInput{
+		ProjectID:    project.ID,
+		DiskID:       diskID,
+		UploadKey:    req.UploadKey,
+		Path:         filePath,
+		Filename:     filename,
+		Size:         *req.Size,
+		SHA256:       req.SHA256,
+		UserMeta:     userMeta,
+		UserKEK:      middleware.GetUserKEKIfEncrypted(c),
+		Precondition: artifactPrecondition(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, serializer.Err(http.StatusPreconditionFailed, "artifact was modified", err))
+		case errors.Is(err, service.ErrUploadMismatch):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("uploaded file does not match", err))
+		default:
+			uploadErr(c, err)
+		}
+		return
+	}
+
+	c.Header("ETag", artifactETag(artifactRecord))
 	c.JSON(http.StatusCreated, serializer.Response{Data: artifactRecord})
 }
 
 type DeleteArtifactReq struct {
-	FilePath string `form:"file_path" json:"file_path" binding:"required"` // File path including filename
+	FilePath  string `form:"file_path" json:"file_path" binding:"required"` // File path including filename, or a directory path ending with '/'
+	Recursive bool   `form:"recursive" json:"recursive" example:"false"`    // Required to delete a directory and everything below it
+}
+
+type DeleteArtifactResp struct {
+	Deleted int64 `json:"deleted"` // Number of artifacts removed by a recursive delete
 }
 
 // DeleteArtifact godoc
 //
 //	@Summary		Delete artifact
-//	@Description	Delete an artifact by path and filename
+//	@Description	Delete an artifact by path and filename. With recursive=true and a file_path ending with '/', delete the directory and every artifact below it; the response then reports how many artifacts were removed.
 //	@Tags			artifact
 //	@Accept			json
 //	@Produce		json
-//	@Param			disk_id		path	string	true	"Disk ID"						Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
-//	@Param			file_path	query	string	true	"File path including filename"	example(/documents/report.pdf)
+//	@Param			disk_id		path	string	true	"Disk ID"															Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			file_path	query	string	true	"File path including filename, or a directory path ending with '/'"	example(/documents/report.pdf)
+//	@Param			recursive	query	boolean	false	"Delete a directory and everything below it"						example(false)
+//	@Param			If-Match	header	string	false	"Only delete if the current artifact's ETag (or SHA256) is listed"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
 //	@Security		BearerAuth
-//	@Success		200	{object}	serializer.Response{}
+//	@Success		200	{object}	serializer.Response{data=handler.DeleteArtifactResp}
+//	@Failure		412	{object}	serializer.Response	"Artifact does not match If-Match or If-None-Match"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
 //	@Router			/disk/{disk_id}/artifact [delete]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete an artifact\nclient.disks.delete_artifact(\n    disk_id='disk-uuid',\n    file_path='/documents/report.pdf'\n)\nprint('Artifact deleted successfully')\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete an artifact\nawait client.disks.deleteArtifact('disk-uuid', {\n  filePath: '/documents/report.pdf'\n});\nconsole.log('Artifact deleted successfully');\n","label":"JavaScript"}]
 func (h *ArtifactHandler) DeleteArtifact(c *gin.Context) {
@@ -203,7 +477,37 @@ func (h *ArtifactHandler) DeleteArtifact(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.DeleteByPath(c.Request.Context(), project.ID, diskID, filePath, filename); err != nil {
+	cond := artifactPrecondition(c)
+	if filename == "" {
+		if !cond.IsZero() {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("conditional headers are not supported when deleting a directory", errors.New("If-Match and If-None-Match require a file path")))
+			return
+		}
+		if !req.Recursive {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("recursive=true is required to delete a directory", errors.New("file_path is a directory")))
+			return
+		}
+		if !h.checkLeases(c, diskID, filePath) {
+			return
+		}
+		deleted, err := h.svc.DeleteByPathPrefix(c.Request.Context(), project.ID, diskID, filePath)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+			return
+		}
+		c.JSON(http.StatusOK, serializer.Response{Data: DeleteArtifactResp{Deleted: deleted}})
+		return
+	}
+
+	if !h.checkLeases(c, diskID, filePath+filename) {
+		return
+	}
+
+	if err := h.svc.DeleteByPath(c.Request.Context(), project.ID, diskID, filePath, filename, cond); err != nil {
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			c.JSON(http.StatusPreconditionFailed, serializer.Err(http.StatusPreconditionFailed, "artifact was modified", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
@@ -215,11 +519,13 @@ type GetArtifactReq struct {
 	FilePath      string `form:"file_path" json:"file_path" binding:"required"` // File path including filename
 	WithPublicURL bool   `form:"with_public_url,default=true" json:"with_public_url" example:"true"`
 	WithContent   bool   `form:"with_content,default=true" json:"with_content" example:"true"`
-	Expire        int    `form:"expire,default=3600" json:"expire" example:"3600"` // Expire time in seconds for presigned URL
+	Expire        int    `form:"expire,default=3600" json:"expire" example:"3600"`             // Expire time in seconds for presigned URL
+	Version       int    `form:"version" json:"version" binding:"omitempty,min=1" example:"2"` // Optional version to read, defaults to the current one
 }
 
 type GetArtifactResp struct {
 	Artifact  *model.Artifact         `json:"artifact"`
+	ETag      string                  `json:"etag"` // Entity tag of the content, for If-Match / If-None-Match on later writes
 	PublicURL *string                 `json:"public_url,omitempty"`
 	Content   *fileparser.FileContent `json:"content,omitempty"`
 }
@@ -227,7 +533,7 @@ type GetArtifactResp struct {
 // GetArtifact godoc
 //
 //	@Summary		Get artifact
-//	@Description	Get artifact information by path and filename. Optionally include a presigned URL for downloading and parsed file content.
+//	@Description	Get artifact information by path and filename. Optionally include a presigned URL for downloading and parsed file content. For PDF, DOCX, XLSX, PPTX and HTML files the content is their extracted text, with each XLSX sheet as CSV. Pass version to read a previous version retained in the artifact's history.
 //	@Tags			artifact
 //	@Accept			json
 //	@Produce		json
@@ -236,8 +542,10 @@ type GetArtifactResp struct {
 //	@Param			with_public_url	query	boolean	false	"Whether to return public URL, default is true"				example(true)
 //	@Param			with_content	query	boolean	false	"Whether to return parsed file content, default is true"	example(true)
 //	@Param			expire			query	int		false	"Expire time in seconds for presigned URL (default: 3600)"	example(3600)
+//	@Param			version			query	int		false	"Version to read (default: the current version)"			example(2)
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{data=handler.GetArtifactResp}
+//	@Header			200	{string}	ETag	"ETag of the artifact content"
 //	@Router			/disk/{disk_id}/artifact [get]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get artifact information\nartifact_info = client.disks.get_artifact(\n    disk_id='disk-uuid',\n    file_path='/documents/report.pdf',\n    with_public_url=True,\n    with_content=True,\n    expire=3600\n)\nprint(f\"Artifact: {artifact_info.artifact.filename}\")\nif artifact_info.public_url:\n    print(f\"Download URL: {artifact_info.public_url}\")\nif artifact_info.content:\n    print(f\"Content: {artifact_info.content.text[:100]}...\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get artifact information\nconst artifactInfo = await client.disks.getArtifact('disk-uuid', {\n  filePath: '/documents/report.pdf',\n  withPublicUrl: true,\n  withContent: true,\n  expire: 3600\n});\nconsole.log(`Artifact: ${artifactInfo.artifact.filename}`);\nif (artifactInfo.publicUrl) {\n  console.log(`Download URL: ${artifactInfo.publicUrl}`);\n}\nif (artifactInfo.content) {\n  console.log(`Content: ${artifactInfo.content.text.substring(0, 100)}...`);\n}\n","label":"JavaScript"}]
 func (h *ArtifactHandler) GetArtifact(c *gin.Context) {
@@ -269,13 +577,23 @@ func (h *ArtifactHandler) GetArtifact(c *gin.Context) {
 		return
 	}
 
-	artifact, err := h.svc.GetByPath(c.Request.Context(), diskID, filePath, filename)
+	var artifact *model.Artifact
+	if req.Version > 0 {
+		artifact, err = h.svc.GetByPathAtVersion(c.Request.Context(), diskID, filePath, filename, req.Version)
+	} else {
+		artifact, err = h.svc.GetByPath(c.Request.Context(), diskID, filePath, filename)
+	}
 	if err != nil {
+		if errors.Is(err, service.ErrArtifactVersionNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "artifact version not found", err))
+			return
+		}
 		c.JSON(http.StatusNotFound, serializer.DBErr("artifact not found", err))
 		return
 	}
 
-	resp := GetArtifactResp{Artifact: artifact}
+	resp := GetArtifactResp{Artifact: artifact, ETag: artifactETag(artifact)}
+	c.Header("ETag", resp.ETag)
 
 	// Generate material URL if requested (works for both encrypted and non-encrypted projects)
 	if req.WithPublicURL {
@@ -290,18 +608,433 @@ func (h *ArtifactHandler) GetArtifact(c *gin.Context) {
 		resp.PublicURL = &url
 	}
 
-	// Parse file content if requested
-	if req.WithContent {
-		content, err := h.svc.GetFileContent(c.Request.Context(), artifact, middleware.GetUserKEKIfEncrypted(c))
-		// Only set content if parsing succeeded
-		// Unsupported file types (images, binaries, etc.) will not have content
-		if err == nil && content != nil {
-			resp.Content = content
-		}
-		// Don't return error for unsupported file types - just don't include content
+	// Parse file content if requested
+	if req.WithContent {
+		content, err := h.svc.GetFileContent(c.Request.Context(), artifact, middleware.GetUserKEKIfEncrypted(c))
+		// Only set content if parsing succeeded
+		// Unsupported file types (images, binaries, etc.) will not have content
+		if err == nil && content != nil {
+			resp.Content = content
+		}
+		// Don't return error for unsupported file types - just don't include content
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: resp})
+}
+
+type ListArtifactVersionsReq struct {
+	FilePath string `form:"file_path" json:"file_path" binding:"required"` // File path including filename
+}
+
+type ListArtifactVersionsResp struct {
+	CurrentVersion int                      `json:"current_version"`
+	Versions       []*model.ArtifactVersion `json:"versions"`
+}
+
+// ListArtifactVersions godoc
+//
+//	@Summary		List artifact versions
+//	@Description	List the previous versions retained for an artifact, newest first. A version is kept every time the artifact is overwritten, subject to the configured retention policy.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id		path	string	true	"Disk ID"						Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			file_path	query	string	true	"File path including filename"	example(/notes/todo.md)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=handler.ListArtifactVersionsResp}
+//	@Router			/disk/{disk_id}/artifact/versions [get]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List previous versions of an artifact\nresult = client.disks.artifacts.list_versions(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md'\n)\nprint(f\"Current version: {result.current_version}\")\nfor v in result.versions:\n    print(f\"  - v{v.version} archived at {v.archived_at}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List previous versions of an artifact\nconst result = await client.disks.artifacts.listVersions('disk-uuid', {\n  filePath: '/notes/todo.md'\n});\nconsole.log(`Current version: ${result.currentVersion}`);\nfor (const v of result.versions) {\n  console.log(`  - v${v.version} archived at ${v.archivedAt}`);\n}\n","label":"JavaScript"}]
+func (h *ArtifactHandler) ListArtifactVersions(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := ListArtifactVersionsReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	// Verify disk belongs to the authenticated project
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusForbidden, serializer.Err(http.StatusForbidden, "access denied: disk does not belong to this project", nil))
+		return
+	}
+
+	filePath, filename := path.SplitFilePath(req.FilePath)
+	if err := path.ValidatePath(filePath); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	artifact, err := h.svc.GetByPath(c.Request.Context(), diskID, filePath, filename)
+	if err != nil {
+		c.JSON(http.StatusNotFound, serializer.DBErr("artifact not found", err))
+		return
+	}
+
+	versions, err := h.svc.ListVersions(c.Request.Context(), diskID, filePath, filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{
+		Data: ListArtifactVersionsResp{
+			CurrentVersion: artifact.Version,
+			Versions:       versions,
+		},
+	})
+}
+
+type RestoreArtifactReq struct {
+	FilePath string `json:"file_path" binding:"required" example:"/notes/todo.md"` // File path including filename
+	Version  int    `json:"version" binding:"required,min=1" example:"2"`          // Version to restore
+}
+
+// RestoreArtifact godoc
+//
+//	@Summary		Restore artifact version
+//	@Description	Make a previous version of an artifact current again. The restore creates a new version, so the content it replaces is kept in the history.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string						true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.RestoreArtifactReq	true	"Restore artifact request"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Artifact}
+//	@Failure		404	{object}	serializer.Response	"Artifact or version not found"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/artifact/restore [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Roll an artifact back to a previous version\nartifact = client.disks.artifacts.restore(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md',\n    version=2\n)\nprint(f\"Now at version {artifact.version}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Roll an artifact back to a previous version\nconst artifact = await client.disks.artifacts.restore('disk-uuid', {\n  filePath: '/notes/todo.md',\n  version: 2\n});\nconsole.log(`Now at version ${artifact.version}`);\n","label":"JavaScript"}]
+func (h *ArtifactHandler) RestoreArtifact(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := RestoreArtifactReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	// Verify disk belongs to the authenticated project
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusForbidden, serializer.Err(http.StatusForbidden, "access denied: disk does not belong to this project", nil))
+		return
+	}
+
+	filePath, filename := path.SplitFilePath(req.FilePath)
+	if err := path.ValidatePath(filePath); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	if !h.checkLeases(c, diskID, filePath+filename) {
+		return
+	}
+
+	artifact, err := h.svc.RestoreVersion(c.Request.Context(), project.ID, diskID, filePath, filename, req.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrArtifactVersionNotFound):
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "artifact version not found", err))
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, serializer.DBErr("artifact not found", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: artifact})
+}
+
+type TransferArtifactsReq struct {
+	From       string `json:"from" binding:"required" example:"/notes/todo.md"`                                                     // Source file path, or directory path ending with '/'
+	To         string `json:"to" binding:"required" example:"/archive/todo.md"`                                                     // Destination file path, or directory path ending with '/'
+	DestDiskID string `json:"dest_disk_id" example:"123e4567-e89b-12d3-a456-426614174000"`                                          // Optional destination disk in the same project, defaults to the source disk
+	OnConflict string `json:"on_conflict" binding:"omitempty,oneof=fail skip overwrite" enums:"fail,skip,overwrite" example:"fail"` // What to do when the destination exists, defaults to fail
+}
+
+// MoveArtifacts godoc
+//
+//	@Summary		Move or rename artifacts
+//	@Description	Move or rename a single artifact, or every artifact under a directory when from ends with '/'. The move is atomic and keeps each artifact's version history. With on_conflict=overwrite an existing destination keeps its history and gets the moved content as a new version.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string						true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.TransferArtifactsReq	true	"Move request"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=service.TransferArtifactsOutput}
+//	@Failure		404	{object}	serializer.Response	"Disk or source artifact not found"
+//	@Failure		409	{object}	serializer.Response	"Destination exists and on_conflict is fail"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/artifact/move [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rename a directory\nresult = client.disks.artifacts.move(\n    disk_id='disk-uuid',\n    from_path='/drafts/',\n    to_path='/published/',\n    on_conflict='fail'\n)\nprint(f\"Moved {len(result.artifacts)} artifacts\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rename a directory\nconst result = await client.disks.artifacts.move('disk-uuid', {\n  from: '/drafts/',\n  to: '/published/',\n  onConflict: 'fail'\n});\nconsole.log(`Moved ${result.artifacts.length} artifacts`);\n","label":"JavaScript"}]
+func (h *ArtifactHandler) MoveArtifacts(c *gin.Context) {
+	h.transferArtifacts(c, h.svc.Move, true)
+}
+
+// CopyArtifacts godoc
+//
+//	@Summary		Copy artifacts
+//	@Description	Copy a single artifact, or every artifact under a directory when from ends with '/', optionally to another disk of the same project. Copies share the stored content instead of re-uploading it. With on_conflict=overwrite an existing destination keeps its history and gets the copied content as a new version.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string						true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.TransferArtifactsReq	true	"Copy request"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=service.TransferArtifactsOutput}
+//	@Failure		404	{object}	serializer.Response	"Disk or source artifact not found"
+//	@Failure		409	{object}	serializer.Response	"Destination exists and on_conflict is fail"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/artifact/copy [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Copy a file to another disk\nresult = client.disks.artifacts.copy(\n    disk_id='disk-uuid',\n    from_path='/templates/report.md',\n    to_path='/reports/2024.md',\n    dest_disk_id='other-disk-uuid',\n    on_conflict='skip'\n)\nprint(f\"Copied {len(result.artifacts)}, skipped {len(result.skipped)}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Copy a file to another disk\nconst result = await client.disks.artifacts.copy('disk-uuid', {\n  from: '/templates/report.md',\n  to: '/reports/2024.md',\n  destDiskId: 'other-disk-uuid',\n  onConflict: 'skip'\n});\nconsole.log(`Copied ${result.artifacts.length}, skipped ${result.skipped.length}`);\n","label":"JavaScript"}]
+func (h *ArtifactHandler) CopyArtifacts(c *gin.Context) {
+	h.transferArtifacts(c, h.svc.Copy, false)
+}
+
+// transferArtifacts runs a move or copy. A move also writes the source, so its leases are checked too.
+func (h *ArtifactHandler) transferArtifacts(c *gin.Context, transfer func(context.Context, service.TransferArtifactsInput) (*service.TransferArtifactsOutput, error), move bool) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := TransferArtifactsReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	destDiskID := diskID
+	if req.DestDiskID != "" {
+		destDiskID, err = uuid.Parse(req.DestDiskID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid dest_disk_id", err))
+			return
+		}
+	}
+
+	// Verify both disks belong to the authenticated project
+	for _, id := range []uuid.UUID{diskID, destDiskID} {
+		if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, id); err != nil {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+			return
+		}
+	}
+
+	for _, p := range []string{req.From, req.To} {
+		dir, _ := path.SplitFilePath(p)
+		if err := path.ValidatePath(dir); err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+			return
+		}
+	}
+
+	if move && !h.checkLeases(c, diskID, req.From) {
+		return
+	}
+	if !h.checkLeases(c, destDiskID, req.To) {
+		return
+	}
+
+	out, err := transfer(c.Request.Context(), service.TransferArtifactsInput{
+		ProjectID:  project.ID,
+		DiskID:     diskID,
+		From:       req.From,
+		To:         req.To,
+		DestDiskID: destDiskID,
+		OnConflict: req.OnConflict,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTransfer):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		case errors.Is(err, service.ErrArtifactNotFound):
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "artifact not found", err))
+		case errors.Is(err, service.ErrArtifactConflict):
+			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "destination already exists", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: out})
+}
+
+type ArtifactLeaseReq struct {
+	Path       string `json:"path" binding:"required" example:"/notes/"`          // File path, or directory path ending with '/' to lock everything below it
+	Owner      string `json:"owner" binding:"required" example:"agent-7f3a"`      // Token identifying the holder; send it as X-Lease-Owner on writes
+	TTLSeconds int    `json:"ttl_seconds" binding:"omitempty,min=1" example:"60"` // Lease duration, defaults to 60 seconds, at most 3600
+}
+
+// LockArtifacts godoc
+//
+//	@Summary		Acquire artifact lease
+//	@Description	Acquire an advisory lease on a file, or on everything under a directory when path ends with '/'. While the lease is live, writes to covered artifacts are rejected with 423 unless they send the owner token in the X-Lease-Owner header. Acquiring again with the same owner and path extends the lease.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string					true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.ArtifactLeaseReq	true	"Lease request"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.ArtifactLease}
+//	@Failure		409	{object}	serializer.Response	"An overlapping lease is held by another owner"
+//	@Router			/disk/{disk_id}/artifact/lock [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Lock a directory for 5 minutes\nlease = client.disks.artifacts.lock(\n    disk_id='disk-uuid',\n    path='/notes/',\n    owner='agent-7f3a',\n    ttl_seconds=300\n)\nprint(f\"Locked until {lease.expires_at}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Lock a directory for 5 minutes\nconst lease = await client.disks.artifacts.lock('disk-uuid', {\n  path: '/notes/',\n  owner: 'agent-7f3a',\n  ttlSeconds: 300\n});\nconsole.log(`Locked until ${lease.expiresAt}`);\n","label":"JavaScript"}]
+func (h *ArtifactHandler) LockArtifacts(c *gin.Context) {
+	h.writeLease(c, h.leaseSvc.Acquire, http.StatusCreated)
+}
+
+// RenewArtifactLease godoc
+//
+//	@Summary		Renew artifact lease
+//	@Description	Extend a live lease held by owner by ttl_seconds from now. A lease that has already expired cannot be renewed, since another owner may have taken the path in the meantime; acquire it again instead.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string					true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request	body	handler.ArtifactLeaseReq	true	"Lease request"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.ArtifactLease}
+//	@Failure		404	{object}	serializer.Response	"Owner holds no live lease on the path"
+//	@Router			/disk/{disk_id}/artifact/lock/renew [post]
+func (h *ArtifactHandler) RenewArtifactLease(c *gin.Context) {
+	h.writeLease(c, h.leaseSvc.Renew, http.StatusOK)
+}
+
+func (h *ArtifactHandler) writeLease(c *gin.Context, write func(context.Context, service.ArtifactLeaseInput) (*model.ArtifactLease, error), status int) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := ArtifactLeaseReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	lease, err := write(c.Request.Context(), service.ArtifactLeaseInput{
+		DiskID: diskID,
+		Path:   req.Path,
+		Owner:  req.Owner,
+		TTL:    time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+
+	c.JSON(status, serializer.Response{Data: lease})
+}
+
+type ReleaseArtifactLeaseReq struct {
+	Path  string `form:"path" json:"path" binding:"required" example:"/notes/"`
+	Owner string `form:"owner" json:"owner" binding:"required" example:"agent-7f3a"`
+}
+
+// ReleaseArtifactLease godoc
+//
+//	@Summary		Release artifact lease
+//	@Description	Release a lease held by owner, letting other owners write the covered artifacts right away.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string	true	"Disk ID"					Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			path	query	string	true	"Path the lease was taken on"	example(/notes/)
+//	@Param			owner	query	string	true	"Owner token of the lease"		example(agent-7f3a)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{}
+//	@Failure		404	{object}	serializer.Response	"Owner holds no live lease on the path"
+//	@Router			/disk/{disk_id}/artifact/lock [delete]
+func (h *ArtifactHandler) ReleaseArtifactLease(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := ReleaseArtifactLeaseReq{}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	if err := h.leaseSvc.Release(c.Request.Context(), diskID, req.Path, req.Owner); err != nil {
+		leaseError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, serializer.Response{Data: resp})
+	c.JSON(http.StatusOK, serializer.Response{})
+}
+
+// leaseError writes the response for a failed lease operation.
+func leaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLease):
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+	case errors.Is(err, service.ErrLeaseHeld):
+		c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "path is locked by another owner", err))
+	case errors.Is(err, service.ErrLeaseNotFound):
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "lease not found", err))
+	default:
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+	}
 }
 
 type DownloadArtifactReq struct {
@@ -311,13 +1044,17 @@ type DownloadArtifactReq struct {
 // DownloadArtifact godoc
 //
 //	@Summary		Download artifact content
-//	@Description	Download raw artifact file content. Decrypts content if encryption is enabled.
+//	@Description	Download raw artifact file content. Decrypts content if encryption is enabled. The content is streamed, and byte Range requests are answered with 206 Partial Content.
 //	@Tags			artifact
 //	@Produce		octet-stream
 //	@Param			disk_id		path	string	true	"Disk ID"	Format(uuid)
 //	@Param			file_path	query	string	true	"File path including filename"
+//	@Param			Range		header	string	false	"Byte range, e.g. bytes=0-1023"
 //	@Security		BearerAuth
 //	@Success		200	"File content"
+//	@Success		206	"Requested byte range of the file content"
+//	@Header			200	{string}	ETag	"ETag of the artifact content"
+//	@Failure		416	"Range not satisfiable"
 //	@Router			/disk/{disk_id}/artifact/download [get]
 func (h *ArtifactHandler) DownloadArtifact(c *gin.Context) {
 	project, ok := c.MustGet("project").(*model.Project)
@@ -356,13 +1093,21 @@ func (h *ArtifactHandler) DownloadArtifact(c *gin.Context) {
 		return
 	}
 
-	content, mimeType, err := h.svc.DownloadRawContent(c.Request.Context(), artifact, middleware.GetUserKEKIfEncrypted(c))
+	object, err := h.svc.OpenContent(c.Request.Context(), artifact, middleware.GetUserKEKIfEncrypted(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, serializer.Err(http.StatusInternalServerError, "download failed", err))
 		return
 	}
 
-	c.Data(http.StatusOK, mimeType, content)
+	mimeType := artifact.AssetMeta.Data().MIME
+	if mimeType == "" {
+		mimeType = object.ContentType
+	}
+	body := object.NewReadSeeker(c.Request.Context())
+	defer body.Close()
+	c.Header("Content-Type", mimeType)
+	c.Header("ETag", artifactETag(artifact))
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, body)
 }
 
 type UpdateArtifactReq struct {
@@ -382,9 +1127,14 @@ type UpdateArtifactResp struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			disk_id	path	string						true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
-//	@Param			request	body	handler.UpdateArtifactReq	true	"Update artifact request"
+//	@Param			request		body	handler.UpdateArtifactReq	true	"Update artifact request"
+//	@Param			If-Match	header	string						false	"Only update if the current artifact's ETag (or SHA256) is listed"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{data=handler.UpdateArtifactResp}
+//	@Header			200	{string}	ETag				"ETag of the artifact content"
+//	@Failure		412	{object}	serializer.Response	"Artifact does not match If-Match or If-None-Match"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
 //	@Router			/disk/{disk_id}/artifact [put]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Update artifact metadata\nartifact = client.disks.update_artifact(\n    disk_id='disk-uuid',\n    file_path='/documents/report.pdf',\n    meta={'category': 'updated', 'reviewed': True, 'version': 2}\n)\nprint(f\"Updated artifact: {artifact.artifact.id}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Update artifact metadata\nconst artifact = await client.disks.updateArtifact('disk-uuid', {\n  filePath: '/documents/report.pdf',\n  meta: { category: 'updated', reviewed: true, version: 2 }\n});\nconsole.log(`Updated artifact: ${artifact.artifact.id}`);\n","label":"JavaScript"}]
 func (h *ArtifactHandler) UpdateArtifact(c *gin.Context) {
@@ -437,36 +1187,151 @@ func (h *ArtifactHandler) UpdateArtifact(c *gin.Context) {
 		}
 	}
 
+	if !h.checkLeases(c, diskID, filePath+filename) {
+		return
+	}
+
 	// Update artifact meta
-	artifactRecord, err := h.svc.UpdateArtifactMetaByPath(c.Request.Context(), diskID, filePath, filename, userMeta)
+	artifactRecord, err := h.svc.UpdateArtifactMetaByPath(c.Request.Context(), diskID, filePath, filename, userMeta, artifactPrecondition(c))
 	if err != nil {
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			c.JSON(http.StatusPreconditionFailed, serializer.Err(http.StatusPreconditionFailed, "artifact was modified", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
 
+	c.Header("ETag", artifactETag(artifactRecord))
 	c.JSON(http.StatusOK, serializer.Response{
 		Data: UpdateArtifactResp{Artifact: artifactRecord},
 	})
 }
 
+type EditArtifactReq struct {
+	FilePath string          `json:"file_path" binding:"required" example:"/notes/todo.md"` // File path including filename
+	Edits    []textedit.Edit `json:"edits" binding:"required,min=1,max=100"`                // Operations applied in order; either all apply or none
+}
+
+// EditArtifact godoc
+//
+//	@Summary		Edit text artifact
+//	@Description	Edit a text artifact on the server without downloading and re-uploading it. Operations run in order against the current content: replace (old_text must occur exactly once unless replace_all is set), insert (text before line, or one past the last line to append), delete (start_line to end_line, inclusive) and patch (a unified diff). Lines are numbered from 1. The result is stored as a new version and keeps the artifact's meta. If another writer changes the artifact meanwhile, the edit fails with 412 and can be retried. Documents such as PDF and DOCX files cannot be edited; HTML files are edited as markup.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id			path	string					true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			request			body	handler.EditArtifactReq	true	"Edit artifact request"
+//	@Param			If-Match		header	string					false	"Only edit if the current artifact's ETag (or SHA256) is listed"
+//	@Param			X-Lease-Owner	header	string					false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Artifact}
+//	@Header			200	{string}	ETag				"ETag of the edited content"
+//	@Failure		400	{object}	serializer.Response	"Malformed edit, such as a line out of range"
+//	@Failure		404	{object}	serializer.Response	"Artifact not found"
+//	@Failure		409	{object}	serializer.Response	"Content does not match the edit, such as old_text not found or a hunk that does not apply"
+//	@Failure		412	{object}	serializer.Response	"Artifact does not match If-Match, or changed during the edit"
+//	@Failure		415	{object}	serializer.Response	"Artifact is not a text file"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/artifact/edit [patch]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Fix a line and append another without re-uploading the file\nartifact = client.disks.artifacts.edit(\n    disk_id='disk-uuid',\n    file_path='/notes/todo.md',\n    edits=[\n        {'op': 'replace', 'old_text': '- [ ] ship', 'new_text': '- [x] ship'},\n        {'op': 'insert', 'line': 10, 'text': '- [ ] celebrate\\n'},\n    ]\n)\nprint(f\"Now at version {artifact.version}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Fix a line and append another without re-uploading the file\nconst artifact = await client.disks.artifacts.edit('disk-uuid', {\n  filePath: '/notes/todo.md',\n  edits: [\n    { op: 'replace', oldText: '- [ ] ship', newText: '- [x] ship' },\n    { op: 'insert', line: 10, text: '- [ ] celebrate\\n' },\n  ]\n});\nconsole.log(`Now at version ${artifact.version}`);\n","label":"JavaScript"}]
+func (h *ArtifactHandler) EditArtifact(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := EditArtifactReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	filePath, filename := path.SplitFilePath(req.FilePath)
+	if err := path.ValidatePath(filePath); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+	if filename == "" {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("file_path must name a file", errors.New("file_path is a directory")))
+		return
+	}
+
+	if !h.checkLeases(c, diskID, filePath+filename) {
+		return
+	}
+
+	artifact, err := h.svc.Edit(c.Request.Context(), service.EditArtifactInput{
+		ProjectID:    project.ID,
+		DiskID:       diskID,
+		Path:         filePath,
+		Filename:     filename,
+		Edits:        req.Edits,
+		UserKEK:      middleware.GetUserKEKIfEncrypted(c),
+		Precondition: artifactPrecondition(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEdit):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		case errors.Is(err, service.ErrArtifactNotFound):
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "artifact not found", err))
+		case errors.Is(err, service.ErrEditMismatch):
+			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "artifact content does not match the edit", err))
+		case errors.Is(err, service.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, serializer.Err(http.StatusPreconditionFailed, "artifact was modified", err))
+		case errors.Is(err, service.ErrArtifactNotText):
+			c.JSON(http.StatusUnsupportedMediaType, serializer.Err(http.StatusUnsupportedMediaType, "only text artifacts can be edited", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.Header("ETag", artifactETag(artifact))
+	c.JSON(http.StatusOK, serializer.Response{Data: artifact})
+}
+
 type ListArtifactsReq struct {
-	Path string `form:"path" json:"path"` // Optional path filter
+	Path      string `form:"path" json:"path"` // Optional path filter
+	Recursive bool   `form:"recursive" json:"recursive" example:"false"`
+	MaxDepth  int    `form:"max_depth" json:"max_depth" binding:"omitempty,min=0" example:"2"`
+	Limit     int    `form:"limit,default=100" json:"limit" binding:"required,min=1,max=1000" example:"100"`
+	Cursor    string `form:"cursor" json:"cursor" example:"L2RvY3VtZW50cy9yZXBvcnQucGRm"`
 }
 
 type ListArtifactsResp struct {
 	Artifacts   []*model.Artifact `json:"artifacts"`
 	Directories []string          `json:"directories"`
+	NextCursor  string            `json:"next_cursor,omitempty"` // Cursor for the next page of a recursive listing
+	HasMore     bool              `json:"has_more"`              // Whether a recursive listing has more pages
 }
 
 // ListArtifacts godoc
 //
 //	@Summary		List artifacts
-//	@Description	List artifacts in a specific path or all artifacts in a disk
+//	@Description	List artifacts in a specific path or all artifacts in a disk. With recursive=true, artifacts in all subdirectories are listed too, in path order and paginated; max_depth limits how many directory levels are descended (1 lists only the path itself, 0 means unlimited).
 //	@Tags			artifact
 //	@Accept			json
 //	@Produce		json
-//	@Param			disk_id	path	string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
-//	@Param			path	query	string	false	"Path filter (optional, defaults to root '/')"
+//	@Param			disk_id		path	string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			path		query	string	false	"Path filter (optional, defaults to root '/')"
+//	@Param			recursive	query	boolean	false	"Also list artifacts in subdirectories"							example(false)
+//	@Param			max_depth	query	int		false	"Directory levels to descend in a recursive listing (0 = unlimited)"	example(2)
+//	@Param			limit		query	int		false	"Page size of a recursive listing (default 100, max 1000)"		example(100)
+//	@Param			cursor		query	string	false	"Cursor for pagination of a recursive listing"
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{data=handler.ListArtifactsResp}
 //	@Router			/disk/{disk_id}/artifact/ls [get]
@@ -509,12 +1374,39 @@ func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
 		return
 	}
 
-	artifacts, err := h.svc.ListByPath(c.Request.Context(), diskID, pathQuery)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+	req := ListArtifactsReq{}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
 		return
 	}
 
+	resp := ListArtifactsResp{}
+	if req.Recursive {
+		out, err := h.svc.ListTree(c.Request.Context(), service.ListArtifactTreeInput{
+			DiskID:   diskID,
+			Path:     pathQuery,
+			MaxDepth: req.MaxDepth,
+			Limit:    req.Limit,
+			Cursor:   req.Cursor,
+		})
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid cursor", err))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+			return
+		}
+		resp.Artifacts, resp.NextCursor, resp.HasMore = out.Items, out.NextCursor, out.HasMore
+	} else {
+		artifacts, err := h.svc.ListByPath(c.Request.Context(), diskID, pathQuery)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+			return
+		}
+		resp.Artifacts = artifacts
+	}
+
 	// Get all paths to extract directory names
 	allPaths, err := h.svc.GetAllPaths(c.Request.Context(), diskID)
 	if err != nil {
@@ -523,28 +1415,295 @@ func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
 	}
 
 	// Extract direct subdirectories
-	directories := path.GetDirectoriesFromPaths(pathQuery, allPaths)
+	resp.Directories = path.GetDirectoriesFromPaths(pathQuery, allPaths)
 
-	c.JSON(http.StatusOK, serializer.Response{
-		Data: ListArtifactsResp{
-			Artifacts:   artifacts,
-			Directories: directories,
-		},
+	c.JSON(http.StatusOK, serializer.Response{Data: resp})
+}
+
+type DiskUsageReq struct {
+	Path     string `form:"path" json:"path" example:"/documents/"`
+	MaxDepth int    `form:"max_depth" json:"max_depth" binding:"omitempty,min=0" example:"1"`
+}
+
+type DiskUsageResp struct {
+	Directories []service.DirectoryUsage `json:"directories"`
+}
+
+// DiskUsage godoc
+//
+//	@Summary		Summarize directory sizes
+//	@Description	Report the number of files and total bytes stored under a directory and under each of its subdirectories, like du. max_depth limits how many levels of subdirectories are reported (0 = unlimited); totals always include everything below.
+//	@Tags			artifact
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id		path	string	true	"Disk ID"											Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			path		query	string	false	"Directory to summarize (defaults to root '/')"		example(/documents/)
+//	@Param			max_depth	query	int		false	"Subdirectory levels to report (0 = unlimited)"	example(1)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=handler.DiskUsageResp}
+//	@Router			/disk/{disk_id}/artifact/du [get]
+func (h *ArtifactHandler) DiskUsage(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	// Verify disk belongs to the authenticated project
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusForbidden, serializer.Err(http.StatusForbidden, "access denied: disk does not belong to this project", nil))
+		return
+	}
+
+	req := DiskUsageReq{}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	} else if dir, _ := path.SplitFilePath(req.Path); dir != req.Path {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("both ends of the path must be '/'", errors.New("both ends of the path must be '/'")))
+		return
+	}
+	if err := path.ValidatePath(req.Path); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	usage, err := h.svc.DiskUsage(c.Request.Context(), diskID, req.Path, req.MaxDepth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: DiskUsageResp{Directories: usage}})
+}
+
+type ExportDiskReq struct {
+	Format string `form:"format" json:"format" binding:"omitempty,oneof=zip tar.gz" enums:"zip,tar.gz" example:"zip"` // Archive format, defaults to zip
+	Path   string `form:"path" json:"path" example:"/documents/"`                                                     // Directory to export, defaults to root '/'
+}
+
+// ExportDisk godoc
+//
+//	@Summary		Export disk as an archive
+//	@Description	Stream every artifact under a directory as a zip or tar.gz archive. Entry names are relative to the exported directory. Content is decrypted if encryption is enabled.
+//	@Tags			disk
+//	@Produce		application/zip
+//	@Produce		application/gzip
+//	@Param			disk_id	path	string	true	"Disk ID"										Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			format	query	string	false	"Archive format (zip or tar.gz, defaults to zip)"	example(zip)
+//	@Param			path	query	string	false	"Directory to export (defaults to root '/')"		example(/documents/)
+//	@Security		BearerAuth
+//	@Success		200	"Archive content"
+//	@Router			/disk/{disk_id}/export [get]
+func (h *ArtifactHandler) ExportDisk(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	req := ExportDiskReq{}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+	if req.Format == "" {
+		req.Format = service.ArchiveFormatZip
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	} else if dir, _ := path.SplitFilePath(req.Path); dir != req.Path {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("both ends of the path must be '/'", errors.New("both ends of the path must be '/'")))
+		return
+	}
+	if err := path.ValidatePath(req.Path); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	contentType := "application/zip"
+	if req.Format == service.ArchiveFormatTarGz {
+		contentType = "application/gzip"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="disk-%s.%s"`, diskID, req.Format))
+	c.Status(http.StatusOK)
+
+	err = h.svc.Export(c.Request.Context(), c.Writer, service.ExportArtifactsInput{
+		DiskID:  diskID,
+		Path:    req.Path,
+		Format:  req.Format,
+		UserKEK: middleware.GetUserKEKIfEncrypted(c),
+	})
+	if err != nil {
+		// Once the archive has started streaming the status line is sent and the client
+		// only sees a truncated archive; otherwise report the error normally.
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.Err(http.StatusInternalServerError, "export failed", err))
+	}
+}
+
+type ImportDiskReq struct {
+	Path       string `form:"path" json:"path" example:"/imported/"`                                                                                   // Destination directory, defaults to root '/'
+	Format     string `form:"format" json:"format" binding:"omitempty,oneof=zip tar.gz" enums:"zip,tar.gz" example:"zip"`                              // Archive format, inferred from the file name when omitted
+	OnConflict string `form:"on_conflict" json:"on_conflict" binding:"omitempty,oneof=fail skip overwrite" enums:"fail,skip,overwrite" example:"fail"` // What to do when a file exists, defaults to fail
+}
+
+// ImportDisk godoc
+//
+//	@Summary		Import an archive into a disk
+//	@Description	Unpack an uploaded zip or tar.gz archive into artifacts under a directory. A single top-level folder wrapping all files is stripped, macOS metadata is skipped and paths escaping the archive root are rejected. The archive must not exceed the configured maximum archive size (default: 256MB) and each file the maximum upload size.
+//	@Tags			disk
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			disk_id		path		string	true	"Disk ID"												Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			archive		formData	file	true	"Archive to import"
+//	@Param			path		formData	string	false	"Destination directory (defaults to root '/')"
+//	@Param			format		formData	string	false	"Archive format (zip or tar.gz), inferred from the file name when omitted"
+//	@Param			on_conflict	formData	string	false	"What to do when a file exists: fail, skip or overwrite (defaults to fail)"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=service.ImportArtifactsOutput}
+//	@Failure		400	{object}	serializer.Response	"Invalid archive"
+//	@Failure		409	{object}	serializer.Response	"A file exists and on_conflict is fail"
+//	@Failure		413	{object}	serializer.Response	"Archive exceeds maximum allowed size"
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
+//	@Router			/disk/{disk_id}/import [post]
+func (h *ArtifactHandler) ImportDisk(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return
+	}
+
+	req := ImportDiskReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	} else if dir, _ := path.SplitFilePath(req.Path); dir != req.Path {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("both ends of the path must be '/'", errors.New("both ends of the path must be '/'")))
+		return
+	}
+	if err := path.ValidatePath(req.Path); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid path", err))
+		return
+	}
+
+	if !h.checkLeases(c, diskID, req.Path) {
+		return
+	}
+
+	file, err := c.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("archive is required", err))
+		return
+	}
+
+	maxSize := h.config.Artifact.MaxArchiveSizeBytes
+	if maxSize > 0 && file.Size > maxSize {
+		maxSizeMB := float64(maxSize) / (1024 * 1024)
+		c.JSON(http.StatusRequestEntityTooLarge, serializer.ParamErr("", fmt.Errorf("archive size exceeds maximum allowed size of %.2fMB", maxSizeMB)))
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = service.ArchiveFormatFromFilename(file.Filename)
+		if req.Format == "" {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("cannot infer archive format from the file name, set format to zip or tar.gz")))
+			return
+		}
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.Err(http.StatusInternalServerError, "failed to open archive", err))
+		return
+	}
+	defer f.Close()
+
+	out, err := h.svc.Import(c.Request.Context(), service.ImportArtifactsInput{
+		ProjectID:  project.ID,
+		DiskID:     diskID,
+		Path:       req.Path,
+		Format:     req.Format,
+		Archive:    f,
+		Size:       file.Size,
+		OnConflict: req.OnConflict,
+		UserKEK:    middleware.GetUserKEKIfEncrypted(c),
 	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidArchive):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		case errors.Is(err, service.ErrArtifactConflict):
+			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "destination already exists", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, serializer.Response{Data: out})
 }
 
 // GrepArtifacts godoc
 //
 //	@Summary		Search artifact content with regex
-//	@Description	Search through text-based artifact content using regex patterns
+//	@Description	Search artifact text line by line, like ripgrep. Text files are searched as well as the text extracted from PDF, DOCX, XLSX, PPTX and HTML documents. Each result is the matching artifact with the line numbers and text of its matching lines, plus optional context lines. Matching is case-insensitive unless case_sensitive is set; fixed_strings matches query literally. include and exclude take path globs and may be repeated: a glob without '/' matches the file name, otherwise the full path.
 //	@Tags			artifact
 //	@Accept			json
 //	@Produce		json
-//	@Param			disk_id	path	string	true	"Disk ID"	Format(uuid)
-//	@Param			query	query	string	true	"Regex pattern to search for"
-//	@Param			limit	query	int		false	"Maximum number of results (default 100, max 1000)"
+//	@Param			disk_id			path	string		true	"Disk ID"	Format(uuid)
+//	@Param			query			query	string		true	"Regex pattern to search for"
+//	@Param			limit			query	int			false	"Maximum number of matching artifacts (default 100, max 200)"
+//	@Param			case_sensitive	query	boolean		false	"Match case exactly (default false)"
+//	@Param			fixed_strings	query	boolean		false	"Treat query as a literal string instead of a regex (default false)"
+//	@Param			include			query	[]string	false	"Only search paths matching one of these globs"	collectionFormat(multi)
+//	@Param			exclude			query	[]string	false	"Skip paths matching any of these globs"			collectionFormat(multi)
+//	@Param			before_context	query	int			false	"Lines of context before each match (max 20, defaults to context)"
+//	@Param			after_context	query	int			false	"Lines of context after each match (max 20, defaults to context)"
+//	@Param			context			query	int			false	"Lines of context before and after each match (max 20)"
+//	@Param			max_count		query	int			false	"Maximum matching lines reported per artifact (default 100, max 1000)"
 //	@Security		BearerAuth
-//	@Success		200	{object}	serializer.Response{data=[]model.Artifact}
+//	@Success		200	{object}	serializer.Response{data=[]service.ArtifactGrepResult}
 //	@Router			/disk/{disk_id}/artifact/grep [get]
 func (h *ArtifactHandler) GrepArtifacts(c *gin.Context) {
 	project, ok := c.MustGet("project").(*model.Project)
@@ -575,20 +1734,44 @@ func (h *ArtifactHandler) GrepArtifacts(c *gin.Context) {
 	if req.Limit != nil {
 		limit = *req.Limit
 	}
-
-	artifacts, err := h.svc.GrepArtifacts(c.Request.Context(), project.ID, diskID, req.Query, limit)
+	before, after := req.Context, req.Context
+	if req.BeforeContext != nil {
+		before = *req.BeforeContext
+	}
+	if req.AfterContext != nil {
+		after = *req.AfterContext
+	}
+
+	results, err := h.svc.GrepArtifacts(c.Request.Context(), service.GrepArtifactsInput{
+		ProjectID:     project.ID,
+		DiskID:        diskID,
+		Pattern:       req.Query,
+		FixedString:   req.FixedStrings,
+		CaseSensitive: req.CaseSensitive,
+		Include:       req.Include,
+		Exclude:       req.Exclude,
+		Before:        before,
+		After:         after,
+		MaxCount:      req.MaxCount,
+		Limit:         limit,
+		UserKEK:       middleware.GetUserKEKIfEncrypted(c),
+	})
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidGrep) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, serializer.Response{Data: artifacts})
+	c.JSON(http.StatusOK, serializer.Response{Data: results})
 }
 
 // GlobArtifacts godoc
 //
 //	@Summary		Search artifact paths with glob patterns
-//	@Description	Search through artifact file paths using glob patterns (*, ?, etc.)
+//	@Description	Search artifact file paths with shell-style globs. `*` matches within one path segment, `**` spans directories, `?` matches one character; character classes (`[a-z]`, `[!0-9]`) and `{a,b}` alternation are supported. Results are ordered by path.
 //	@Tags			artifact
 //	@Accept			json
 //	@Produce		json
@@ -597,6 +1780,7 @@ func (h *ArtifactHandler) GrepArtifacts(c *gin.Context) {
 //	@Param			limit	query	int		false	"Maximum number of results (default 100, max 1000)"
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{data=[]model.Artifact}
+//	@Failure		400	{object}	serializer.Response	"Invalid glob pattern"
 //	@Router			/disk/{disk_id}/artifact/glob [get]
 func (h *ArtifactHandler) GlobArtifacts(c *gin.Context) {
 	project, ok := c.MustGet("project").(*model.Project)
@@ -630,6 +1814,10 @@ func (h *ArtifactHandler) GlobArtifacts(c *gin.Context) {
 
 	artifacts, err := h.svc.GlobArtifacts(c.Request.Context(), project.ID, diskID, req.Query, limit)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidGlob) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
@@ -743,8 +1931,10 @@ type UploadFromSandboxReq struct {
 //	@Produce		json
 //	@Param			disk_id	path	string							true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
 //	@Param			request	body	handler.UploadFromSandboxReq	true	"Upload from sandbox request"
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the path is covered by a lease"
 //	@Security		BearerAuth
 //	@Success		201	{object}	serializer.Response{data=model.Artifact}
+//	@Failure		423	{object}	serializer.Response	"Path is locked by another lease owner"
 //	@Router			/disk/{disk_id}/artifact/upload_from_sandbox [post]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Upload file from sandbox to disk\nartifact = client.disks.artifacts.upload_from_sandbox(\n    disk_id='disk-uuid',\n    sandbox_id='sandbox-uuid',\n    sandbox_path='/home/user/',\n    sandbox_filename='output.txt',\n    file_path='/results/'\n)\nprint(f\"Created: {artifact.path}{artifact.filename}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Upload file from sandbox to disk\nconst artifact = await client.disks.artifacts.uploadFromSandbox('disk-uuid', {\n  sandboxId: 'sandbox-uuid',\n  sandboxPath: '/home/user/',\n  sandboxFilename: 'output.txt',\n  filePath: '/results/'\n});\nconsole.log(`Created: ${artifact.path}${artifact.filename}`);\n","label":"JavaScript"}]
 func (h *ArtifactHandler) UploadFromSandbox(c *gin.Context) {
@@ -784,6 +1974,10 @@ func (h *ArtifactHandler) UploadFromSandbox(c *gin.Context) {
 		return
 	}
 
+	if !h.checkLeases(c, diskID, req.FilePath+req.SandboxFilename) {
+		return
+	}
+
 	// Generate temp S3 key for sandbox file download
 	tempUUID := uuid.New()
 	ext := pathpkg.Ext(req.SandboxFilename)
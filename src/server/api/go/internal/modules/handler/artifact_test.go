@@ -5,20 +5,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
 	"github.com/memodb-io/Acontext/internal/modules/model"
 	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/memodb-io/Acontext/internal/modules/serializer"
 	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/fileparser"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/textedit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/datatypes"
@@ -34,6 +38,22 @@ func (m *MockArtifactService) Create(ctx context.Context, in service.CreateArtif
 	return args.Get(0).(*model.Artifact), args.Error(1)
 }
 
+func (m *MockArtifactService) PresignUpload(ctx context.Context, projectID uuid.UUID, contentType string, expire time.Duration) (*service.PresignedUpload, error) {
+	args := m.Called(ctx, projectID, contentType, expire)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.PresignedUpload), args.Error(1)
+}
+
+func (m *MockArtifactService) Commit(ctx context.Context, in service.CommitArtifactInput) (*model.Artifact, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
 func (m *MockArtifactService) Delete(ctx context.Context, diskID uuid.UUID, artifactID uuid.UUID) error {
 	args := m.Called(ctx, diskID, artifactID)
 	return args.Error(0)
@@ -69,8 +89,8 @@ func (m *MockArtifactService) GetByDiskID(ctx context.Context, diskID uuid.UUID)
 	return args.Get(0).([]*model.Artifact), args.Error(1)
 }
 
-func (m *MockArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
-	args := m.Called(ctx, projectID, diskID, path, filename)
+func (m *MockArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
+	args := m.Called(ctx, projectID, diskID, path, filename, cond)
 	return args.Error(0)
 }
 
@@ -84,8 +104,19 @@ func (m *MockArtifactService) UpdateArtifactByPath(ctx context.Context, diskID u
 	return args.Get(0).(*model.Artifact), args.Error(1)
 }
 
-func (m *MockArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}) (*model.Artifact, error) {
-	args := m.Called(ctx, diskID, path, filename, userMeta)
+func (m *MockArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}, cond repo.ArtifactPrecondition) (*model.Artifact, error) {
+	args := m.Called(ctx, diskID, path, filename, userMeta, cond)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) Edit(ctx context.Context, in service.EditArtifactInput) (*model.Artifact, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*model.Artifact), args.Error(1)
 }
 
@@ -105,12 +136,20 @@ func (m *MockArtifactService) DownloadRawContent(ctx context.Context, artifact *
 	return args.Get(0).([]byte), args.String(1), args.Error(2)
 }
 
-func (m *MockArtifactService) GrepArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
-	args := m.Called(ctx, projectID, diskID, pattern, limit)
+func (m *MockArtifactService) OpenContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*blob.Object, error) {
+	args := m.Called(ctx, artifact, userKEK)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Artifact), args.Error(1)
+	return args.Get(0).(*blob.Object), args.Error(1)
+}
+
+func (m *MockArtifactService) GrepArtifacts(ctx context.Context, in service.GrepArtifactsInput) ([]*service.ArtifactGrepResult, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*service.ArtifactGrepResult), args.Error(1)
 }
 
 func (m *MockArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
@@ -121,6 +160,80 @@ func (m *MockArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.
 	return args.Get(0).([]*model.Artifact), args.Error(1)
 }
 
+func (m *MockArtifactService) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	args := m.Called(ctx, diskID, path, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ArtifactVersion), args.Error(1)
+}
+
+func (m *MockArtifactService) GetByPathAtVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	args := m.Called(ctx, diskID, path, filename, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) RestoreVersion(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	args := m.Called(ctx, projectID, diskID, path, filename, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) Move(ctx context.Context, in service.TransferArtifactsInput) (*service.TransferArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.TransferArtifactsOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) ListTree(ctx context.Context, in service.ListArtifactTreeInput) (*service.ListArtifactTreeOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ListArtifactTreeOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	args := m.Called(ctx, projectID, diskID, prefix)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockArtifactService) DiskUsage(ctx context.Context, diskID uuid.UUID, path string, maxDepth int) ([]service.DirectoryUsage, error) {
+	args := m.Called(ctx, diskID, path, maxDepth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.DirectoryUsage), args.Error(1)
+}
+
+func (m *MockArtifactService) Export(ctx context.Context, w io.Writer, in service.ExportArtifactsInput) error {
+	args := m.Called(ctx, w, in)
+	return args.Error(0)
+}
+
+func (m *MockArtifactService) Import(ctx context.Context, in service.ImportArtifactsInput) (*service.ImportArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ImportArtifactsOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) Copy(ctx context.Context, in service.TransferArtifactsInput) (*service.TransferArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.TransferArtifactsOutput), args.Error(1)
+}
+
 func (m *MockArtifactService) CreateFromBytes(ctx context.Context, in service.CreateArtifactFromBytesInput) (*model.Artifact, error) {
 	args := m.Called(ctx, in)
 	if args.Get(0) == nil {
@@ -297,7 +410,7 @@ func TestArtifactHandler_UpsertArtifact(t *testing.T) {
 			}
 
 			testConfig := createTestConfig(tt.maxUploadSize)
-			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil)
+			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil, nil, nil)
 
 			// Create multipart form data
 			body := &bytes.Buffer{}
@@ -380,7 +493,24 @@ func TestArtifactHandler_DeleteArtifact(t *testing.T) {
 			filePath: "/test/test.txt",
 			mockSetup: func(m *MockArtifactService, diskIDStr string, filePath string, projectID uuid.UUID) {
 				diskID := uuid.MustParse(diskIDStr)
-				m.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "test.txt").Return(nil)
+				m.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "test.txt", repo.ArtifactPrecondition{}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "directory without recursive",
+			diskID:         uuid.New().String(),
+			filePath:       "/test/",
+			mockSetup:      func(m *MockArtifactService, diskIDStr string, filePath string, projectID uuid.UUID) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "recursive directory deletion",
+			diskID:   uuid.New().String(),
+			filePath: "/test/&recursive=true",
+			mockSetup: func(m *MockArtifactService, diskIDStr string, filePath string, projectID uuid.UUID) {
+				diskID := uuid.MustParse(diskIDStr)
+				m.On("DeleteByPathPrefix", mock.Anything, projectID, diskID, "/test/").Return(int64(3), nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -399,7 +529,7 @@ func TestArtifactHandler_DeleteArtifact(t *testing.T) {
 			}
 
 			testConfig := createDefaultTestConfig() // Default 16MB
-			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil)
+			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil, nil, nil)
 
 			// Create request with query parameters
 			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/disk/%s/artifact?file_path=%s", tt.diskID, tt.filePath), nil)
@@ -473,7 +603,7 @@ func TestArtifactHandler_UpdateArtifact(t *testing.T) {
 					"description": "Updated report",
 					"version":     "2.0",
 				}
-				m.On("UpdateArtifactMetaByPath", mock.Anything, diskID, "/test/", "report.pdf", expectedMeta).Return(expectedFile, nil)
+				m.On("UpdateArtifactMetaByPath", mock.Anything, diskID, "/test/", "report.pdf", expectedMeta, repo.ArtifactPrecondition{}).Return(expectedFile, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -532,7 +662,7 @@ func TestArtifactHandler_UpdateArtifact(t *testing.T) {
 			}
 
 			testConfig := createDefaultTestConfig() // Default 16MB
-			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil)
+			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, nil, nil, nil)
 
 			// Create JSON request body
 			requestBody := map[string]string{
@@ -688,7 +818,7 @@ func TestArtifactHandler_GetArtifact(t *testing.T) {
 			}
 
 			testConfig := createDefaultTestConfig() // Default 16MB
-			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, mockMaterialSvc)
+			handler := NewArtifactHandler(mockService, mockDiskRepo, testConfig, nil, nil, mockMaterialSvc, nil, nil)
 
 			// Set up mock disk repo to allow ownership check for valid disk IDs
 			projectID := uuid.New()
@@ -777,7 +907,7 @@ func TestArtifactHandler_GetArtifact_MaterialURL(t *testing.T) {
 		mockMaterialSvc.On("CreateMaterialURL", mock.Anything, "assets/proj/test.bin", "", mock.AnythingOfType("time.Duration"), "application/octet-stream", "test.bin").
 			Return("http://localhost:8029/api/v1/material/aabbcc", time.Now().Add(time.Hour), nil)
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, mockMaterialSvc)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, mockMaterialSvc, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -827,7 +957,7 @@ func TestArtifactHandler_GetArtifact_MaterialURL(t *testing.T) {
 		mockMaterialSvc.On("CreateMaterialURL", mock.Anything, "assets/proj/secret.bin", mock.Anything, mock.AnythingOfType("time.Duration"), "application/octet-stream", "secret.bin").
 			Return("http://localhost:8029/api/v1/material/encrypted-token", time.Now().Add(time.Hour), nil)
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, mockMaterialSvc)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, mockMaterialSvc, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -866,19 +996,49 @@ func TestArtifactHandler_GrepArtifacts(t *testing.T) {
 			query:  "TODO",
 			limit:  "10",
 			setupMock: func(svc *MockArtifactService) {
-				svc.On("GrepArtifacts", mock.Anything, mock.Anything, mock.Anything, "TODO", 10).
-					Return([]*model.Artifact{
+				svc.On("GrepArtifacts", mock.Anything, mock.MatchedBy(func(in service.GrepArtifactsInput) bool {
+					return in.Pattern == "TODO" && in.Limit == 10 && !in.CaseSensitive && !in.FixedString && in.Before == 0 && in.After == 0
+				})).
+					Return([]*service.ArtifactGrepResult{
 						{
-							ID:       uuid.New(),
-							Filename: "test.py",
-							Path:     "/",
+							Artifact: &model.Artifact{
+								ID:       uuid.New(),
+								Filename: "test.py",
+								Path:     "/",
+							},
+							Matches: []service.GrepMatch{{LineNumber: 3, Line: "# TODO"}},
 						},
 					}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkBody: func(t *testing.T, body string) {
-				assert.Contains(t, body, "test.py")
+				assert.Contains(t, body, `"filename":"test.py"`)
+				assert.Contains(t, body, `"line_number":3`)
+			},
+		},
+		{
+			name:   "grep options",
+			diskID: "123e4567-e89b-12d3-a456-426614174000",
+			query:  "TODO&case_sensitive=true&fixed_strings=true&include=*.py&include=*.md&exclude=/vendor/*&context=2&after_context=5&max_count=3",
+			limit:  "10",
+			setupMock: func(svc *MockArtifactService) {
+				svc.On("GrepArtifacts", mock.Anything, mock.MatchedBy(func(in service.GrepArtifactsInput) bool {
+					return in.CaseSensitive && in.FixedString && in.Before == 2 && in.After == 5 && in.MaxCount == 3 &&
+						assert.ObjectsAreEqual([]string{"*.py", "*.md"}, in.Include) &&
+						assert.ObjectsAreEqual([]string{"/vendor/*"}, in.Exclude)
+				})).Return([]*service.ArtifactGrepResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "invalid pattern",
+			diskID: "123e4567-e89b-12d3-a456-426614174000",
+			query:  "(unclosed",
+			limit:  "10",
+			setupMock: func(svc *MockArtifactService) {
+				svc.On("GrepArtifacts", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidGrep)
 			},
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:   "no matches found",
@@ -886,8 +1046,10 @@ func TestArtifactHandler_GrepArtifacts(t *testing.T) {
 			query:  "NOTFOUND",
 			limit:  "50",
 			setupMock: func(svc *MockArtifactService) {
-				svc.On("GrepArtifacts", mock.Anything, mock.Anything, mock.Anything, "NOTFOUND", 50).
-					Return([]*model.Artifact{}, nil)
+				svc.On("GrepArtifacts", mock.Anything, mock.MatchedBy(func(in service.GrepArtifactsInput) bool {
+					return in.Pattern == "NOTFOUND" && in.Limit == 50
+				})).
+					Return([]*service.ArtifactGrepResult{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkBody: func(t *testing.T, body string) {
@@ -926,7 +1088,7 @@ func TestArtifactHandler_GrepArtifacts(t *testing.T) {
 					Return(&model.Disk{ID: diskUUID, ProjectID: project.ID}, nil)
 			}
 
-			handler := NewArtifactHandler(mockSvc, mockDiskRepo, createTestConfig(10*1024*1024), nil, nil, nil)
+			handler := NewArtifactHandler(mockSvc, mockDiskRepo, createTestConfig(10*1024*1024), nil, nil, nil, nil, nil)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -995,6 +1157,17 @@ func TestArtifactHandler_GlobArtifacts(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:   "invalid glob pattern",
+			diskID: "123e4567-e89b-12d3-a456-426614174000",
+			query:  "*.%7Bgo,py",
+			limit:  "10",
+			setupMock: func(svc *MockArtifactService) {
+				svc.On("GlobArtifacts", mock.Anything, mock.Anything, mock.Anything, "*.{go,py", 10).
+					Return(nil, fmt.Errorf("%w: unmatched '{'", service.ErrInvalidGlob))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "invalid disk ID",
 			diskID:         "not-a-uuid",
@@ -1019,7 +1192,7 @@ func TestArtifactHandler_GlobArtifacts(t *testing.T) {
 					Return(&model.Disk{ID: diskUUID, ProjectID: project.ID}, nil)
 			}
 
-			handler := NewArtifactHandler(mockSvc, mockDiskRepo, createTestConfig(10*1024*1024), nil, nil, nil)
+			handler := NewArtifactHandler(mockSvc, mockDiskRepo, createTestConfig(10*1024*1024), nil, nil, nil, nil, nil)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -1055,7 +1228,7 @@ func TestArtifactHandler_UploadFromSandbox(t *testing.T) {
 		mockDiskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).
 			Return(nil, fmt.Errorf("record not found"))
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -1084,7 +1257,7 @@ func TestArtifactHandler_UploadFromSandbox(t *testing.T) {
 		mockDiskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).
 			Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -1117,7 +1290,7 @@ func TestArtifactHandler_DownloadArtifact(t *testing.T) {
 		mockDiskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).
 			Return(nil, fmt.Errorf("record not found"))
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -1155,9 +1328,9 @@ func TestArtifactHandler_DownloadArtifact(t *testing.T) {
 			}),
 		}
 		mockService.On("GetByPath", mock.Anything, diskID, "/test/", "file.txt").Return(artifact, nil)
-		mockService.On("DownloadRawContent", mock.Anything, artifact, mock.Anything).Return([]byte("content"), "text/plain", nil)
+		mockService.On("OpenContent", mock.Anything, artifact, mock.Anything).Return(testBlobObject(t, []byte("content"), nil), nil)
 
-		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil)
+		handler := NewArtifactHandler(mockService, mockDiskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -1169,7 +1342,828 @@ func TestArtifactHandler_DownloadArtifact(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, "content", w.Body.String())
+		assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
 		mockDiskRepo.AssertExpectations(t)
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestArtifactHandler_ArtifactVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+	current := &model.Artifact{ID: uuid.New(), DiskID: diskID, Path: "/notes/", Filename: "todo.md", Version: 3}
+
+	newHandler := func(svc *MockArtifactService) *ArtifactHandler {
+		diskRepo := new(MockDiskRepo)
+		diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).
+			Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+		return NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+	}
+	newContext := func(method, url, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("project", &model.Project{ID: projectID})
+		c.Request = httptest.NewRequest(method, url, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+		return c, w
+	}
+
+	t.Run("list returns current version and history", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("GetByPath", mock.Anything, diskID, "/notes/", "todo.md").Return(current, nil)
+		svc.On("ListVersions", mock.Anything, diskID, "/notes/", "todo.md").
+			Return([]*model.ArtifactVersion{{Version: 2}, {Version: 1}}, nil)
+
+		c, w := newContext("GET", "/disk/"+diskID.String()+"/artifact/versions?file_path=/notes/todo.md", "")
+		newHandler(svc).ListArtifactVersions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data ListArtifactVersionsResp `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 3, resp.Data.CurrentVersion)
+		assert.Len(t, resp.Data.Versions, 2)
+	})
+
+	t.Run("get reads a previous version", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		old := &model.Artifact{ID: current.ID, DiskID: diskID, Path: "/notes/", Filename: "todo.md", Version: 2}
+		svc.On("GetByPathAtVersion", mock.Anything, diskID, "/notes/", "todo.md", 2).Return(old, nil)
+
+		c, w := newContext("GET", "/disk/"+diskID.String()+"/artifact?file_path=/notes/todo.md&version=2&with_public_url=false&with_content=false", "")
+		newHandler(svc).GetArtifact(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("get unknown version is not found", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("GetByPathAtVersion", mock.Anything, diskID, "/notes/", "todo.md", 9).Return(nil, service.ErrArtifactVersionNotFound)
+
+		c, w := newContext("GET", "/disk/"+diskID.String()+"/artifact?file_path=/notes/todo.md&version=9", "")
+		newHandler(svc).GetArtifact(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		restored := &model.Artifact{ID: current.ID, DiskID: diskID, Path: "/notes/", Filename: "todo.md", Version: 4}
+		svc.On("RestoreVersion", mock.Anything, projectID, diskID, "/notes/", "todo.md", 2).Return(restored, nil)
+
+		c, w := newContext("POST", "/disk/"+diskID.String()+"/artifact/restore", `{"file_path":"/notes/todo.md","version":2}`)
+		newHandler(svc).RestoreArtifact(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("restore missing version", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("RestoreVersion", mock.Anything, projectID, diskID, "/notes/", "todo.md", 1).Return(nil, service.ErrArtifactVersionNotFound)
+
+		c, w := newContext("POST", "/disk/"+diskID.String()+"/artifact/restore", `{"file_path":"/notes/todo.md","version":1}`)
+		newHandler(svc).RestoreArtifact(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("restore requires a version", func(t *testing.T) {
+		svc := new(MockArtifactService)
+
+		c, w := newContext("POST", "/disk/"+diskID.String()+"/artifact/restore", `{"file_path":"/notes/todo.md"}`)
+		newHandler(svc).RestoreArtifact(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		svc.AssertNotCalled(t, "RestoreVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestArtifactHandler_TransferArtifacts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+	otherDiskID := uuid.New()
+	foreignDiskID := uuid.New()
+
+	tests := []struct {
+		name           string
+		move           bool
+		body           string
+		setup          func(*MockArtifactService)
+		expectedStatus int
+	}{
+		{
+			name: "move a file",
+			move: true,
+			body: `{"from":"/notes/todo.md","to":"/archive/"}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Move", mock.Anything, service.TransferArtifactsInput{
+					ProjectID:  projectID,
+					DiskID:     diskID,
+					From:       "/notes/todo.md",
+					To:         "/archive/",
+					DestDiskID: diskID,
+				}).Return(&service.TransferArtifactsOutput{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "copy a directory to another disk",
+			body: `{"from":"/src/","to":"/dst/","dest_disk_id":"` + otherDiskID.String() + `","on_conflict":"skip"}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Copy", mock.Anything, mock.MatchedBy(func(in service.TransferArtifactsInput) bool {
+					return in.DestDiskID == otherDiskID && in.OnConflict == service.ConflictSkip
+				})).Return(&service.TransferArtifactsOutput{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "destination disk of another project",
+			body:           `{"from":"/a.txt","to":"/b.txt","dest_disk_id":"` + foreignDiskID.String() + `"}`,
+			setup:          func(svc *MockArtifactService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown conflict mode",
+			body:           `{"from":"/a.txt","to":"/b.txt","on_conflict":"merge"}`,
+			setup:          func(svc *MockArtifactService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "path traversal",
+			body:           `{"from":"/a.txt","to":"/../b.txt"}`,
+			setup:          func(svc *MockArtifactService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "conflict",
+			move: true,
+			body: `{"from":"/a.txt","to":"/b.txt"}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Move", mock.Anything, mock.Anything).Return(nil, service.ErrArtifactConflict)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "invalid transfer",
+			move: true,
+			body: `{"from":"/a/","to":"/a/b/"}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Move", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidTransfer)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "source not found",
+			body: `{"from":"/missing.txt","to":"/b.txt"}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Copy", mock.Anything, mock.Anything).Return(nil, service.ErrArtifactNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockArtifactService)
+			tt.setup(svc)
+			diskRepo := new(MockDiskRepo)
+			for _, id := range []uuid.UUID{diskID, otherDiskID} {
+				diskRepo.On("GetByProjectAndID", mock.Anything, projectID, id).Return(&model.Disk{ID: id, ProjectID: projectID}, nil)
+			}
+			diskRepo.On("GetByProjectAndID", mock.Anything, projectID, foreignDiskID).Return(nil, fmt.Errorf("record not found"))
+			handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("project", &model.Project{ID: projectID})
+			c.Request = httptest.NewRequest("POST", "/disk/"+diskID.String()+"/artifact/copy", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+
+			if tt.move {
+				handler.MoveArtifacts(c)
+			} else {
+				handler.CopyArtifacts(c)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArtifactHandler_RecursiveListingAndUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+
+	tests := []struct {
+		name           string
+		url            string
+		du             bool
+		setup          func(*MockArtifactService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "recursive listing with depth and cursor",
+			url:  "/ls?path=/docs/&recursive=true&max_depth=2&limit=10&cursor=abc",
+			setup: func(svc *MockArtifactService) {
+				svc.On("ListTree", mock.Anything, service.ListArtifactTreeInput{
+					DiskID: diskID, Path: "/docs/", MaxDepth: 2, Limit: 10, Cursor: "abc",
+				}).Return(&service.ListArtifactTreeOutput{
+					Items:      []*model.Artifact{{Path: "/docs/a/", Filename: "x.md"}},
+					NextCursor: "next",
+					HasMore:    true,
+				}, nil)
+				svc.On("GetAllPaths", mock.Anything, diskID).Return([]string{"/docs/a/"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"next_cursor":"next"`,
+		},
+		{
+			name: "recursive listing with bad cursor",
+			url:  "/ls?recursive=true&cursor=bad",
+			setup: func(svc *MockArtifactService) {
+				svc.On("ListTree", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "recursive listing limit too large",
+			url:            "/ls?recursive=true&limit=5000",
+			setup:          func(svc *MockArtifactService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "du defaults to root",
+			url:  "/du",
+			du:   true,
+			setup: func(svc *MockArtifactService) {
+				svc.On("DiskUsage", mock.Anything, diskID, "/", 0).Return([]service.DirectoryUsage{{Path: "/", Files: 2, Bytes: 30}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"size_b":30`,
+		},
+		{
+			name: "du with depth",
+			url:  "/du?path=/docs/&max_depth=1",
+			du:   true,
+			setup: func(svc *MockArtifactService) {
+				svc.On("DiskUsage", mock.Anything, diskID, "/docs/", 1).Return([]service.DirectoryUsage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "du on a file path",
+			url:            "/du?path=/docs/a.md",
+			du:             true,
+			setup:          func(svc *MockArtifactService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockArtifactService)
+			tt.setup(svc)
+			diskRepo := new(MockDiskRepo)
+			diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+			handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("project", &model.Project{ID: projectID})
+			c.Request = httptest.NewRequest("GET", "/disk/"+diskID.String()+"/artifact"+tt.url, nil)
+			c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+
+			if tt.du {
+				handler.DiskUsage(c)
+			} else {
+				handler.ListArtifacts(c)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArtifactHandler_ExportImportDisk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+
+	newHandler := func(svc *MockArtifactService, cfg *config.Config) *ArtifactHandler {
+		diskRepo := new(MockDiskRepo)
+		diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+		return NewArtifactHandler(svc, diskRepo, cfg, nil, nil, nil, nil, nil)
+	}
+	newContext := func(w *httptest.ResponseRecorder, req *http.Request) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Set("project", &model.Project{ID: projectID})
+		c.Request = req
+		c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+		return c
+	}
+	importRequest := func(filename string, fields map[string]string) *http.Request {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("archive", filename)
+		_, _ = fw.Write([]byte("archive-bytes"))
+		for k, v := range fields {
+			_ = mw.WriteField(k, v)
+		}
+		_ = mw.Close()
+		req := httptest.NewRequest("POST", "/disk/"+diskID.String()+"/import", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	t.Run("export streams a tar.gz", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("Export", mock.Anything, mock.Anything, service.ExportArtifactsInput{
+			DiskID: diskID, Path: "/docs/", Format: service.ArchiveFormatTarGz,
+		}).Run(func(args mock.Arguments) {
+			_, _ = args.Get(1).(io.Writer).Write([]byte("archive"))
+		}).Return(nil)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, httptest.NewRequest("GET", "/disk/"+diskID.String()+"/export?format=tar.gz&path=/docs/", nil))
+		newHandler(svc, createDefaultTestConfig()).ExportDisk(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".tar.gz")
+		assert.Equal(t, "archive", w.Body.String())
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("export rejects an unknown format", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		w := httptest.NewRecorder()
+		c := newContext(w, httptest.NewRequest("GET", "/disk/"+diskID.String()+"/export?format=rar", nil))
+		newHandler(svc, createDefaultTestConfig()).ExportDisk(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("import infers the format from the file name", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("Import", mock.Anything, mock.MatchedBy(func(in service.ImportArtifactsInput) bool {
+			return in.Format == service.ArchiveFormatTarGz && in.Path == "/in/" && in.OnConflict == "skip" && in.Size == int64(len("archive-bytes"))
+		})).Return(&service.ImportArtifactsOutput{Artifacts: []*model.Artifact{{Path: "/in/", Filename: "a.md"}}, Skipped: []string{}}, nil)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, importRequest("project.tgz", map[string]string{"path": "/in/", "on_conflict": "skip"}))
+		newHandler(svc, createDefaultTestConfig()).ImportDisk(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"filename":"a.md"`)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("import without a known format", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		w := httptest.NewRecorder()
+		c := newContext(w, importRequest("project.rar", nil))
+		newHandler(svc, createDefaultTestConfig()).ImportDisk(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("import of an oversized archive", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		cfg := createDefaultTestConfig()
+		cfg.Artifact.MaxArchiveSizeBytes = 4
+		w := httptest.NewRecorder()
+		c := newContext(w, importRequest("project.zip", nil))
+		newHandler(svc, cfg).ImportDisk(c)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		svc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+
+	t.Run("import maps service errors", func(t *testing.T) {
+		for err, status := range map[error]int{
+			service.ErrInvalidArchive:   http.StatusBadRequest,
+			service.ErrArtifactConflict: http.StatusConflict,
+		} {
+			svc := new(MockArtifactService)
+			svc.On("Import", mock.Anything, mock.Anything).Return(nil, err)
+			w := httptest.NewRecorder()
+			c := newContext(w, importRequest("project.zip", nil))
+			newHandler(svc, createDefaultTestConfig()).ImportDisk(c)
+
+			assert.Equal(t, status, w.Code)
+		}
+	})
+}
+
+func TestArtifactHandler_ConditionalWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+	artifact := &model.Artifact{DiskID: diskID, Path: "/notes/", Filename: "todo.md", AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "abc"})}
+
+	run := func(svc *MockArtifactService, method, url string, body io.Reader, headers map[string]string, call func(*ArtifactHandler, *gin.Context)) *httptest.ResponseRecorder {
+		diskRepo := new(MockDiskRepo)
+		diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+		handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("project", &model.Project{ID: projectID})
+		c.Request = httptest.NewRequest(method, "/disk/"+diskID.String()+"/artifact"+url, body)
+		if body != nil {
+			c.Request.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range headers {
+			c.Request.Header.Set(k, v)
+		}
+		c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+		call(handler, c)
+		return w
+	}
+
+	t.Run("get exposes the etag", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("GetByPath", mock.Anything, diskID, "/notes/", "todo.md").Return(artifact, nil)
+
+		w := run(svc, "GET", "?file_path=/notes/todo.md&with_public_url=false&with_content=false", nil, nil, (*ArtifactHandler).GetArtifact)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"etag":"\"abc\""`)
+	})
+
+	t.Run("delete parses if-match and returns 412 on mismatch", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("DeleteByPath", mock.Anything, projectID, diskID, "/notes/", "todo.md", repo.ArtifactPrecondition{IfMatch: []string{"abc", "def"}}).
+			Return(service.ErrPreconditionFailed)
+
+		w := run(svc, "DELETE", "?file_path=/notes/todo.md", nil, map[string]string{"If-Match": `W/"abc", def`}, (*ArtifactHandler).DeleteArtifact)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("conditional directory delete is rejected", func(t *testing.T) {
+		svc := new(MockArtifactService)
+
+		w := run(svc, "DELETE", "?file_path=/notes/&recursive=true", nil, map[string]string{"If-Match": `"abc"`}, (*ArtifactHandler).DeleteArtifact)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		svc.AssertNotCalled(t, "DeleteByPathPrefix", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update passes if-match and returns the etag", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("UpdateArtifactMetaByPath", mock.Anything, diskID, "/notes/", "todo.md", map[string]interface{}{"k": "v"}, repo.ArtifactPrecondition{IfMatch: []string{"abc"}}).
+			Return(artifact, nil)
+
+		body := strings.NewReader(`{"file_path":"/notes/todo.md","meta":"{\"k\":\"v\"}"}`)
+		w := run(svc, "PUT", "", body, map[string]string{"If-Match": `"abc"`}, (*ArtifactHandler).UpdateArtifact)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("update returns 412 on mismatch", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("UpdateArtifactMetaByPath", mock.Anything, diskID, "/notes/", "todo.md", mock.Anything, mock.Anything).
+			Return(nil, service.ErrPreconditionFailed)
+
+		body := strings.NewReader(`{"file_path":"/notes/todo.md","meta":"{}"}`)
+		w := run(svc, "PUT", "", body, map[string]string{"If-Match": `"old"`}, (*ArtifactHandler).UpdateArtifact)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+// MockArtifactLeaseService is a mock implementation of ArtifactLeaseService
+type MockArtifactLeaseService struct {
+	mock.Mock
+}
+
+func (m *MockArtifactLeaseService) Acquire(ctx context.Context, in service.ArtifactLeaseInput) (*model.ArtifactLease, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ArtifactLease), args.Error(1)
+}
+
+func (m *MockArtifactLeaseService) Renew(ctx context.Context, in service.ArtifactLeaseInput) (*model.ArtifactLease, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ArtifactLease), args.Error(1)
+}
+
+func (m *MockArtifactLeaseService) Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error {
+	args := m.Called(ctx, diskID, path, owner)
+	return args.Error(0)
+}
+
+func (m *MockArtifactLeaseService) CheckWrite(ctx context.Context, diskID uuid.UUID, owner string, paths ...string) error {
+	args := m.Called(ctx, diskID, owner, paths)
+	return args.Error(0)
+}
+
+func TestArtifactHandler_Leases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+
+	run := func(svc *MockArtifactService, leaseSvc *MockArtifactLeaseService, method, url string, body io.Reader, headers map[string]string, call func(*ArtifactHandler, *gin.Context)) *httptest.ResponseRecorder {
+		diskRepo := new(MockDiskRepo)
+		diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+		handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, leaseSvc, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("project", &model.Project{ID: projectID})
+		c.Request = httptest.NewRequest(method, "/disk/"+diskID.String()+"/artifact"+url, body)
+		if body != nil {
+			c.Request.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range headers {
+			c.Request.Header.Set(k, v)
+		}
+		c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+		call(handler, c)
+		return w
+	}
+
+	t.Run("lock acquires a lease", func(t *testing.T) {
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("Acquire", mock.Anything, service.ArtifactLeaseInput{DiskID: diskID, Path: "/notes/", Owner: "agent-1", TTL: 30 * time.Second}).
+			Return(&model.ArtifactLease{DiskID: diskID, Path: "/notes/", Owner: "agent-1", ExpiresAt: time.Now().Add(30 * time.Second)}, nil)
+
+		w := run(new(MockArtifactService), leaseSvc, "POST", "/lock", strings.NewReader(`{"path":"/notes/","owner":"agent-1","ttl_seconds":30}`), nil, (*ArtifactHandler).LockArtifacts)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"path":"/notes/"`)
+		leaseSvc.AssertExpectations(t)
+	})
+
+	t.Run("lock held by another owner", func(t *testing.T) {
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("Acquire", mock.Anything, mock.Anything).Return(nil, service.ErrLeaseHeld)
+
+		w := run(new(MockArtifactService), leaseSvc, "POST", "/lock", strings.NewReader(`{"path":"/notes/","owner":"agent-2"}`), nil, (*ArtifactHandler).LockArtifacts)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid lease", func(t *testing.T) {
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("Acquire", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidLease)
+
+		w := run(new(MockArtifactService), leaseSvc, "POST", "/lock", strings.NewReader(`{"path":"notes","owner":"agent-2"}`), nil, (*ArtifactHandler).LockArtifacts)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("renew expired lease", func(t *testing.T) {
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("Renew", mock.Anything, mock.Anything).Return(nil, service.ErrLeaseNotFound)
+
+		w := run(new(MockArtifactService), leaseSvc, "POST", "/lock/renew", strings.NewReader(`{"path":"/notes/","owner":"agent-1"}`), nil, (*ArtifactHandler).RenewArtifactLease)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("Release", mock.Anything, diskID, "/notes/", "agent-1").Return(nil)
+
+		w := run(new(MockArtifactService), leaseSvc, "DELETE", "/lock?path=/notes/&owner=agent-1", nil, nil, (*ArtifactHandler).ReleaseArtifactLease)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		leaseSvc.AssertExpectations(t)
+	})
+
+	t.Run("write to a locked path returns 423", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("CheckWrite", mock.Anything, diskID, "agent-2", []string{"/notes/todo.md"}).Return(service.ErrArtifactLocked)
+
+		w := run(svc, leaseSvc, "DELETE", "?file_path=/notes/todo.md", nil, map[string]string{"X-Lease-Owner": "agent-2"}, (*ArtifactHandler).DeleteArtifact)
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+		svc.AssertNotCalled(t, "DeleteByPath", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("move checks source and destination", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		leaseSvc := new(MockArtifactLeaseService)
+		leaseSvc.On("CheckWrite", mock.Anything, diskID, "agent-1", []string{"/notes/"}).Return(nil)
+		leaseSvc.On("CheckWrite", mock.Anything, diskID, "agent-1", []string{"/archive/"}).Return(service.ErrArtifactLocked)
+
+		w := run(svc, leaseSvc, "POST", "/move", strings.NewReader(`{"from":"/notes/","to":"/archive/"}`), map[string]string{"X-Lease-Owner": "agent-1"}, (*ArtifactHandler).MoveArtifacts)
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+		leaseSvc.AssertExpectations(t)
+		svc.AssertNotCalled(t, "Move", mock.Anything, mock.Anything)
+	})
+}
+
+func TestArtifactHandler_EditArtifact(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+	edited := &model.Artifact{DiskID: diskID, Path: "/notes/", Filename: "todo.md", AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "new"})}
+
+	tests := []struct {
+		name       string
+		body       string
+		headers    map[string]string
+		setup      func(svc *MockArtifactService)
+		wantStatus int
+	}{
+		{
+			name:    "applies edits",
+			body:    `{"file_path":"/notes/todo.md","edits":[{"op":"replace","old_text":"a","new_text":"b"},{"op":"delete","start_line":2,"end_line":3}]}`,
+			headers: map[string]string{"If-Match": `"old"`},
+			setup: func(svc *MockArtifactService) {
+				svc.On("Edit", mock.Anything, mock.MatchedBy(func(in service.EditArtifactInput) bool {
+					return in.Path == "/notes/" && in.Filename == "todo.md" && len(in.Edits) == 2 &&
+						in.Edits[1].Op == textedit.OpDelete && in.Edits[1].EndLine == 3 &&
+						assert.ObjectsAreEqual(in.Precondition.IfMatch, []string{"old"})
+				})).Return(edited, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no edits",
+			body:       `{"file_path":"/notes/todo.md","edits":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "directory path",
+			body:       `{"file_path":"/notes/","edits":[{"op":"insert","line":1,"text":"x"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "mismatch",
+			body: `{"file_path":"/notes/todo.md","edits":[{"op":"replace","old_text":"zzz","new_text":"b"}]}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Edit", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: old_text not found", service.ErrEditMismatch))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "invalid edit",
+			body: `{"file_path":"/notes/todo.md","edits":[{"op":"delete","start_line":5,"end_line":9}]}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Edit", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidEdit)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "concurrent write",
+			body: `{"file_path":"/notes/todo.md","edits":[{"op":"insert","line":1,"text":"x"}]}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Edit", mock.Anything, mock.Anything).Return(nil, service.ErrPreconditionFailed)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name: "binary artifact",
+			body: `{"file_path":"/img/cat.png","edits":[{"op":"insert","line":1,"text":"x"}]}`,
+			setup: func(svc *MockArtifactService) {
+				svc.On("Edit", mock.Anything, mock.Anything).Return(nil, service.ErrArtifactNotText)
+			},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockArtifactService)
+			if tt.setup != nil {
+				tt.setup(svc)
+			}
+			diskRepo := new(MockDiskRepo)
+			diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+			handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("project", &model.Project{ID: projectID})
+			c.Request = httptest.NewRequest("PATCH", "/disk/"+diskID.String()+"/artifact/edit", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+			c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+			handler.EditArtifact(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, `"new"`, w.Header().Get("ETag"))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArtifactHandler_DirectUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	projectID := uuid.New()
+	diskID := uuid.New()
+	sum := strings.Repeat("ab", 32)
+	committed := &model.Artifact{DiskID: diskID, Path: "/docs/", Filename: "report.pdf", AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: sum})}
+
+	serve := func(t *testing.T, svc *MockArtifactService, method string, route string, body string) *httptest.ResponseRecorder {
+		diskRepo := new(MockDiskRepo)
+		diskRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+		handler := NewArtifactHandler(svc, diskRepo, createDefaultTestConfig(), nil, nil, nil, nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("project", &model.Project{ID: projectID})
+		c.Request = httptest.NewRequest(method, "/disk/"+diskID.String()+"/artifact/"+route, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "disk_id", Value: diskID.String()}}
+		if route == "upload_url" {
+			handler.CreateArtifactUploadURL(c)
+		} else {
+			handler.CommitArtifact(c)
+		}
+		return w
+	}
+
+	t.Run("upload url defaults", func(t *testing.T) {
+		svc := new(MockArtifactService)
+		svc.On("PresignUpload", mock.Anything, projectID, "", time.Hour).Return(&service.PresignedUpload{UploadURL: "https://s3/put", UploadKey: "uploads/k"}, nil)
+
+		w := serve(t, svc, "POST", "upload_url", "")
+
+		assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"upload_key":"uploads/k"`)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("upload url rejects a long expiry", func(t *testing.T) {
+		w := serve(t, new(MockArtifactService), "POST", "upload_url", `{"expire":9999999}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "commits", body: `{"upload_key":"uploads/k","file_path":"/docs/report.pdf","size":10,"sha256":"` + sum + `","meta":"{\"a\":1}"}`, wantStatus: http.StatusCreated},
+		{name: "directory path", body: `{"upload_key":"uploads/k","file_path":"/docs/","size":10,"sha256":"` + sum + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "bad sha256", body: `{"upload_key":"uploads/k","file_path":"/docs/report.pdf","size":10,"sha256":"xyz"}`, wantStatus: http.StatusBadRequest},
+		{name: "mismatch", body: `{"upload_key":"uploads/k","file_path":"/docs/report.pdf","size":10,"sha256":"` + sum + `"}`, err: service.ErrUploadMismatch, wantStatus: http.StatusBadRequest},
+		{name: "not uploaded", body: `{"upload_key":"uploads/k","file_path":"/docs/report.pdf","size":10,"sha256":"` + sum + `"}`, err: service.ErrUploadNotFound, wantStatus: http.StatusNotFound},
+		{name: "too large", body: `{"upload_key":"uploads/k","file_path":"/docs/report.pdf","size":10,"sha256":"` + sum + `"}`, err: service.ErrUploadTooLarge, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockArtifactService)
+			if tt.wantStatus == http.StatusCreated || tt.err != nil {
+				ret := committed
+				if tt.err != nil {
+					ret = nil
+				}
+				svc.On("Commit", mock.Anything, mock.MatchedBy(func(in service.CommitArtifactInput) bool {
+					return in.UploadKey == "uploads/k" && in.Path == "/docs/" && in.Filename == "report.pdf" && in.Size == 10 && in.SHA256 == sum
+				})).Return(ret, tt.err)
+			}
+
+			w := serve(t, svc, "POST", "commit", tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusCreated {
+				assert.Equal(t, `"`+sum+`"`, w.Header().Get("ETag"))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+)
+
+type AssetGCHandler struct {
+	svc service.AssetGCService
+}
+
+func NewAssetGCHandler(svc service.AssetGCService) *AssetGCHandler {
+	return &AssetGCHandler{svc: svc}
+}
+
+type SweepAssetsReq struct {
+	ProjectID          string `json:"project_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	DryRun             bool   `json:"dry_run" example:"true"`
+	GracePeriodSeconds *int   `json:"grace_period_seconds" binding:"omitempty,min=0" example:"86400"`
+}
+
+// SweepAssets godoc
+//
+//	@Summary		Sweep orphaned assets
+//	@Description	Delete assets whose reference count is zero and that have not been referenced for longer than the grace period (assetGC.gracePeriodSeconds unless given), removing their stored objects in batches. Scope the sweep to one project with project_id, and set dry_run to only report what would be deleted.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		handler.SweepAssetsReq	true	"SweepAssets payload"
+//	@Success		200		{object}	serializer.Response{data=service.AssetGCOutput}
+//	@Failure		400		{object}	serializer.Response
+//	@Failure		409		{object}	serializer.Response	"Another sweep is running"
+//	@Failure		500		{object}	serializer.Response
+//	@Router			/admin/v1/asset_gc [post]
+func (h *AssetGCHandler) SweepAssets(c *gin.Context) {
+	req := SweepAssetsReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	in := service.AssetGCInput{DryRun: req.DryRun}
+	if req.ProjectID != "" {
+		projectID := uuid.MustParse(req.ProjectID)
+		in.ProjectID = &projectID
+	}
+	if req.GracePeriodSeconds != nil {
+		grace := time.Duration(*req.GracePeriodSeconds) * time.Second
+		in.GracePeriod = &grace
+	}
+
+	out, err := h.svc.Sweep(c.Request.Context(), in)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAssetGCRunning):
+			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "another asset sweep is running", err))
+		case errors.Is(err, service.ErrInvalidAssetGC):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: out})
+}
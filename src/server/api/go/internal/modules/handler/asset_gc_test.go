@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAssetGCService is a mock implementation of AssetGCService
+type MockAssetGCService struct {
+	mock.Mock
+}
+
+func (m *MockAssetGCService) Sweep(ctx context.Context, in service.AssetGCInput) (*service.AssetGCOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AssetGCOutput), args.Error(1)
+}
+
+func (m *MockAssetGCService) Start() {}
+
+func (m *MockAssetGCService) Stop() {}
+
+func TestAssetGCHandler_SweepAssets(t *testing.T) {
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockAssetGCService)
+		expectedStatus int
+	}{
+		{
+			name: "dry run of one project",
+			body: `{"project_id":"` + projectID.String() + `","dry_run":true,"grace_period_seconds":3600}`,
+			setup: func(svc *MockAssetGCService) {
+				svc.On("Sweep", mock.Anything, mock.MatchedBy(func(in service.AssetGCInput) bool {
+					return in.DryRun && in.ProjectID != nil && *in.ProjectID == projectID &&
+						in.GracePeriod != nil && *in.GracePeriod == time.Hour
+				})).Return(&service.AssetGCOutput{DryRun: true, Assets: 2, Bytes: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "all projects with the configured grace period",
+			body: `{}`,
+			setup: func(svc *MockAssetGCService) {
+				svc.On("Sweep", mock.Anything, service.AssetGCInput{}).Return(&service.AssetGCOutput{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid project id",
+			body:           `{"project_id":"not-a-uuid"}`,
+			setup:          func(svc *MockAssetGCService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative grace period",
+			body:           `{"grace_period_seconds":-1}`,
+			setup:          func(svc *MockAssetGCService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "sweep already running",
+			body: `{}`,
+			setup: func(svc *MockAssetGCService) {
+				svc.On("Sweep", mock.Anything, mock.Anything).Return(nil, service.ErrAssetGCRunning)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "service layer error",
+			body: `{}`,
+			setup: func(svc *MockAssetGCService) {
+				svc.On("Sweep", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAssetGCService{}
+			tt.setup(mockService)
+
+			handler := NewAssetGCHandler(mockService)
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/v1/asset_gc", handler.SweepAssets)
+
+			req := httptest.NewRequest("POST", "/admin/v1/asset_gc", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/memodb-io/Acontext/internal/middleware"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+)
+
+type AssetRefCheckHandler struct {
+	svc service.AssetRefCheckService
+}
+
+func NewAssetRefCheckHandler(svc service.AssetRefCheckService) *AssetRefCheckHandler {
+	return &AssetRefCheckHandler{svc: svc}
+}
+
+type CheckAssetRefsReq struct {
+	Repair             bool `json:"repair" example:"false"`
+	GracePeriodSeconds *int `json:"grace_period_seconds" binding:"omitempty,min=0" example:"86400"`
+}
+
+// CheckAssetRefs godoc
+//
+//	@Summary		Check asset references
+//	@Description	Recompute the project's expected asset reference counts from its messages (parts JSON and the assets of their parts), artifacts, artifact versions and disk snapshots, and compare them with the recorded counts and the stored objects. Reports wrong counts, objects missing for referenced assets, and stored objects nothing references that are older than the grace period (assetGC.gracePeriodSeconds unless given) and not held by an upload that can still be attached or committed. With repair, wrong counts are set to the expected ones and leaked objects deleted; when some parts JSON cannot be read, counts are only raised and nothing is deleted.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			body	body		handler.CheckAssetRefsReq	true	"CheckAssetRefs payload"
+//	@Success		200		{object}	serializer.Response{data=service.AssetRefCheckOutput}
+//	@Failure		400		{object}	serializer.Response
+//	@Failure		409		{object}	serializer.Response	"An asset sweep is running"
+//	@Failure		500		{object}	serializer.Response
+//	@Router			/admin/v1/project/asset_refs/check [post]
+func (h *AssetRefCheckHandler) CheckAssetRefs(c *gin.Context) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	req := CheckAssetRefsReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	in := service.AssetRefCheckInput{
+		ProjectID: project.ID,
+		UserKEK:   middleware.GetUserKEKIfEncrypted(c),
+		Repair:    req.Repair,
+	}
+	if req.GracePeriodSeconds != nil {
+		grace := time.Duration(*req.GracePeriodSeconds) * time.Second
+		in.GracePeriod = &grace
+	}
+
+	out, err := h.svc.Check(c.Request.Context(), in)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAssetGCRunning):
+			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "an asset sweep is running", err))
+		case errors.Is(err, service.ErrInvalidAssetRefCheck):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: out})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAssetRefCheckService is a mock implementation of AssetRefCheckService
+type MockAssetRefCheckService struct {
+	mock.Mock
+}
+
+func (m *MockAssetRefCheckService) Check(ctx context.Context, in service.AssetRefCheckInput) (*service.AssetRefCheckOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AssetRefCheckOutput), args.Error(1)
+}
+
+func TestAssetRefCheckHandler_CheckAssetRefs(t *testing.T) {
+	project := &model.Project{ID: uuid.New()}
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockAssetRefCheckService)
+		expectedStatus int
+	}{
+		{
+			name: "report only",
+			body: `{}`,
+			setup: func(svc *MockAssetRefCheckService) {
+				svc.On("Check", mock.Anything, service.AssetRefCheckInput{ProjectID: project.ID}).
+					Return(&service.AssetRefCheckOutput{ProjectID: project.ID, Complete: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "repair with a grace period",
+			body: `{"repair":true,"grace_period_seconds":3600}`,
+			setup: func(svc *MockAssetRefCheckService) {
+				svc.On("Check", mock.Anything, mock.MatchedBy(func(in service.AssetRefCheckInput) bool {
+					return in.ProjectID == project.ID && in.Repair && in.GracePeriod != nil && *in.GracePeriod == time.Hour
+				})).Return(&service.AssetRefCheckOutput{ProjectID: project.ID, Repair: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "negative grace period",
+			body:           `{"grace_period_seconds":-1}`,
+			setup:          func(svc *MockAssetRefCheckService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "sweep running",
+			body: `{"repair":true}`,
+			setup: func(svc *MockAssetRefCheckService) {
+				svc.On("Check", mock.Anything, mock.Anything).Return(nil, service.ErrAssetGCRunning)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "service layer error",
+			body: `{}`,
+			setup: func(svc *MockAssetRefCheckService) {
+				svc.On("Check", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAssetRefCheckService{}
+			tt.setup(mockService)
+
+			handler := NewAssetRefCheckHandler(mockService)
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/v1/project/asset_refs/check", func(c *gin.Context) {
+				c.Set("project", project)
+				handler.CheckAssetRefs(c)
+			})
+
+			req := httptest.NewRequest("POST", "/admin/v1/project/asset_refs/check", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
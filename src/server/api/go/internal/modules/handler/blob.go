@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+)
+
+// BlobHandler serves the signed URLs issued by the local blob driver. With the S3 driver
+// clients talk to the bucket directly and these endpoints answer 404.
+type BlobHandler struct {
+	local *blob.LocalStore
+}
+
+func NewBlobHandler(store blob.BlobStore) *BlobHandler {
+	local, _ := store.(*blob.LocalStore)
+	return &BlobHandler{local: local}
+}
+
+// Get godoc
+//
+//	@Summary		Download a blob through a signed URL
+//	@Description	Serve an object of the local blob store. The URL comes from a presigned download and carries its own HMAC signature, so no authentication header is required. Range requests are supported.
+//	@Tags			blob
+//	@Produce		octet-stream
+//	@Param			key			path	string	true	"Object key"
+//	@Param			expires		query	int		true	"Expiry as a Unix timestamp"
+//	@Param			signature	query	string	true	"URL signature"
+//	@Success		200			"Object content"
+//	@Failure		403			{object}	serializer.Response	"Signature is invalid or expired"
+//	@Failure		404			{object}	serializer.Response	"Object not found"
+//	@Router			/blob/{key} [get]
+func (h *BlobHandler) Get(c *gin.Context) {
+	key, _, ok := h.authorize(c, http.MethodGet)
+	if !ok {
+		return
+	}
+
+	r, info, closer, err := h.local.Open(c.Request.Context(), key)
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	defer closer.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", `"`+info.ETag+`"`)
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, r)
+}
+
+// Put godoc
+//
+//	@Summary		Upload a blob through a signed URL
+//	@Description	Store the request body as an object of the local blob store, replacing any existing one. The URL comes from a presigned upload and carries its own HMAC signature; the request's Content-Type must match the one it was signed for.
+//	@Tags			blob
+//	@Accept			octet-stream
+//	@Produce		json
+//	@Param			key				path	string	true	"Object key"
+//	@Param			expires			query	int		true	"Expiry as a Unix timestamp"
+//	@Param			content_type	query	string	false	"Content type the URL was signed for"
+//	@Param			signature		query	string	true	"URL signature"
+//	@Success		200				"Object stored; its ETag is returned in the ETag header"
+//	@Failure		403				{object}	serializer.Response	"Signature is invalid or expired, or Content-Type does not match"
+//	@Router			/blob/{key} [put]
+func (h *BlobHandler) Put(c *gin.Context) {
+	key, contentType, ok := h.authorize(c, http.MethodPut)
+	if !ok {
+		return
+	}
+	if mediaType, _, _ := strings.Cut(contentType, ";"); c.ContentType() != strings.TrimSpace(mediaType) {
+		c.JSON(http.StatusForbidden, serializer.Err(http.StatusForbidden, "content type does not match the signed URL", nil))
+		return
+	}
+
+	info, err := h.local.Put(c.Request.Context(), key, c.Request.Body, contentType, nil)
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.Header("ETag", `"`+info.ETag+`"`)
+	c.Status(http.StatusOK)
+}
+
+// authorize checks the signed URL for method and returns the object key and content type it grants.
+func (h *BlobHandler) authorize(c *gin.Context, method string) (key string, contentType string, ok bool) {
+	if h.local == nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "blob endpoint is not enabled", nil))
+		return "", "", false
+	}
+	key = strings.TrimPrefix(c.Param("key"), "/")
+	contentType, err := h.local.VerifySignedURL(method, key, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusForbidden, serializer.Err(http.StatusForbidden, "invalid or expired signed URL", nil))
+		return "", "", false
+	}
+	return key, contentType, true
+}
+
+func (h *BlobHandler) storeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, blob.ErrObjectNotFound):
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "object not found", nil))
+	case errors.Is(err, blob.ErrInvalidKey):
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid object key", err))
+	default:
+		c.JSON(http.StatusInternalServerError, serializer.Err(http.StatusInternalServerError, "blob store error", err))
+	}
+}
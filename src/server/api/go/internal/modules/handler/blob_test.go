@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBlobRouter(t *testing.T, store blob.BlobStore) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := NewBlobHandler(store)
+	r := gin.New()
+	r.GET("/api/v1/blob/*key", h.Get)
+	r.PUT("/api/v1/blob/*key", h.Put)
+	return r
+}
+
+func newTestLocalBlobStore(t *testing.T) *blob.LocalStore {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	cfg.App.ExternalURL = "http://api.test"
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	return store
+}
+
+// testBlobObject stores content in a local blob store and opens it for streaming.
+func testBlobObject(t *testing.T, content []byte, userKEK []byte) *blob.Object {
+	t.Helper()
+	store := newTestLocalBlobStore(t)
+	asset, err := store.UploadFileDirect(context.Background(), "assets/p/object", content, "application/octet-stream", userKEK)
+	require.NoError(t, err)
+	object, err := store.OpenObject(context.Background(), asset.S3Key, userKEK)
+	require.NoError(t, err)
+	return object
+}
+
+// requestURI strips the scheme and host from a signed URL so it can be served by the test router.
+func requestURI(t *testing.T, signed string) string {
+	t.Helper()
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	return u.RequestURI()
+}
+
+func TestBlobHandler_PutThenGet(t *testing.T) {
+	store := newTestLocalBlobStore(t)
+	r := setupBlobRouter(t, store)
+	ctx := context.Background()
+
+	putURL, err := store.PresignPut(ctx, "disks/p/a b.txt", "text/plain", time.Minute)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, requestURI(t, putURL), strings.NewReader("hello world"))
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	getURL, err := store.PresignGet(ctx, "disks/p/a b.txt", time.Minute)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, requestURI(t, getURL), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "hello world", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, requestURI(t, getURL), nil)
+	req.Header.Set("Range", "bytes=6-")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "world", w.Body.String())
+}
+
+func TestBlobHandler_Errors(t *testing.T) {
+	store := newTestLocalBlobStore(t)
+	r := setupBlobRouter(t, store)
+	ctx := context.Background()
+
+	putURL, err := store.PresignPut(ctx, "disks/p/a.txt", "text/plain", time.Minute)
+	require.NoError(t, err)
+	missingURL, err := store.PresignGet(ctx, "disks/p/missing.txt", time.Minute)
+	require.NoError(t, err)
+	expiredURL, err := store.PresignGet(ctx, "disks/p/a.txt", -time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		wantCode    int
+	}{
+		{name: "content type mismatch", method: http.MethodPut, target: requestURI(t, putURL), contentType: "text/html", wantCode: http.StatusForbidden},
+		{name: "signed for another method", method: http.MethodGet, target: requestURI(t, putURL), wantCode: http.StatusForbidden},
+		{name: "expired", method: http.MethodGet, target: requestURI(t, expiredURL), wantCode: http.StatusForbidden},
+		{name: "missing signature", method: http.MethodGet, target: "/api/v1/blob/disks/p/a.txt", wantCode: http.StatusForbidden},
+		{name: "object not found", method: http.MethodGet, target: requestURI(t, missingURL), wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader("body"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestBlobHandler_NotLocalDriver(t *testing.T) {
+	r := setupBlobRouter(t, &blob.S3Deps{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/blob/disks/p/a.txt", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
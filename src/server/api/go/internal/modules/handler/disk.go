@@ -16,10 +16,11 @@ type DiskHandler struct {
 	svc      service.DiskService
 	diskRepo repo.DiskRepo
 	userSvc  service.UserService
+	leaseSvc service.ArtifactLeaseService
 }
 
-func NewDiskHandler(s service.DiskService, diskRepo repo.DiskRepo, userSvc service.UserService) *DiskHandler {
-	return &DiskHandler{svc: s, diskRepo: diskRepo, userSvc: userSvc}
+func NewDiskHandler(s service.DiskService, diskRepo repo.DiskRepo, userSvc service.UserService, leaseSvc service.ArtifactLeaseService) *DiskHandler {
+	return &DiskHandler{svc: s, diskRepo: diskRepo, userSvc: userSvc, leaseSvc: leaseSvc}
 }
 
 type CreateDiskReq struct {
@@ -124,13 +125,15 @@ func (h *DiskHandler) ListDisks(c *gin.Context) {
 // DeleteDisk godoc
 //
 //	@Summary		Delete disk
-//	@Description	Delete a disk by its UUID
+//	@Description	Delete a disk by its UUID. Rejected with 423 while another owner holds a lease on any of its artifacts.
 //	@Tags			disk
 //	@Accept			json
 //	@Produce		json
-//	@Param			disk_id	path	string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			disk_id			path	string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the disk has leases"
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{}
+//	@Failure		423	{object}	serializer.Response	"Disk is locked by another lease owner"
 //	@Router			/disk/{disk_id} [delete]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete a disk\nclient.disks.delete(disk_id='disk-uuid')\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete a disk\nawait client.disks.delete('disk-uuid');\n","label":"JavaScript"}]
 func (h *DiskHandler) DeleteDisk(c *gin.Context) {
@@ -151,6 +154,10 @@ func (h *DiskHandler) DeleteDisk(c *gin.Context) {
 		return
 	}
 
+	if !checkLeases(c, h.leaseSvc, diskID, "/") {
+		return
+	}
+
 	if err := h.svc.Delete(c.Request.Context(), project.ID, diskID); err != nil {
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
@@ -158,3 +165,219 @@ func (h *DiskHandler) DeleteDisk(c *gin.Context) {
 
 	c.JSON(http.StatusOK, serializer.Response{})
 }
+
+// diskFromPath parses disk_id and verifies the disk belongs to the authenticated project.
+// It writes the error response and returns false when the request cannot proceed.
+func (h *DiskHandler) diskFromPath(c *gin.Context) (*model.Project, uuid.UUID, bool) {
+	diskID, err := uuid.Parse(c.Param("disk_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return nil, uuid.Nil, false
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return nil, uuid.Nil, false
+	}
+
+	if _, err := h.diskRepo.GetByProjectAndID(c.Request.Context(), project.ID, diskID); err != nil {
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "disk not found or access denied", nil))
+		return nil, uuid.Nil, false
+	}
+	return project, diskID, true
+}
+
+type CreateDiskSnapshotReq struct {
+	Name string `form:"name" json:"name" binding:"max=256" example:"before-refactor"`
+}
+
+// CreateDiskSnapshot godoc
+//
+//	@Summary		Create disk snapshot
+//	@Description	Capture an immutable manifest of all artifacts on the disk (path, filename, asset SHA256 and meta). Content is not copied; the snapshot only adds references to the existing assets.
+//	@Tags			disk
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string							true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			payload	body	handler.CreateDiskSnapshotReq	false	"CreateDiskSnapshot payload"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.DiskSnapshot}
+//	@Router			/disk/{disk_id}/snapshot [post]
+func (h *DiskHandler) CreateDiskSnapshot(c *gin.Context) {
+	project, diskID, ok := h.diskFromPath(c)
+	if !ok {
+		return
+	}
+
+	req := CreateDiskSnapshotReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	snap, err := h.svc.CreateSnapshot(c.Request.Context(), project.ID, diskID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, serializer.Response{Data: snap})
+}
+
+// ListDiskSnapshots godoc
+//
+//	@Summary		List disk snapshots
+//	@Description	List the snapshots of a disk, newest first
+//	@Tags			disk
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id	path	string	true	"Disk ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=[]model.DiskSnapshot}
+//	@Router			/disk/{disk_id}/snapshot [get]
+func (h *DiskHandler) ListDiskSnapshots(c *gin.Context) {
+	_, diskID, ok := h.diskFromPath(c)
+	if !ok {
+		return
+	}
+
+	snaps, err := h.svc.ListSnapshots(c.Request.Context(), diskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: snaps})
+}
+
+// DeleteDiskSnapshot godoc
+//
+//	@Summary		Delete disk snapshot
+//	@Description	Delete a snapshot and release its asset references. The disk itself is not changed.
+//	@Tags			disk
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id		path	string	true	"Disk ID"		Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			snapshot_id	path	string	true	"Snapshot ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{}
+//	@Router			/disk/{disk_id}/snapshot/{snapshot_id} [delete]
+func (h *DiskHandler) DeleteDiskSnapshot(c *gin.Context) {
+	project, diskID, ok := h.diskFromPath(c)
+	if !ok {
+		return
+	}
+
+	snapshotID, err := uuid.Parse(c.Param("snapshot_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if err := h.svc.DeleteSnapshot(c.Request.Context(), project.ID, diskID, snapshotID); err != nil {
+		if errors.Is(err, service.ErrSnapshotNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "snapshot not found", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{})
+}
+
+// RestoreDiskSnapshot godoc
+//
+//	@Summary		Restore disk snapshot
+//	@Description	Roll the disk back to one of its snapshots in a single transaction. Artifacts that differ from the snapshot are overwritten (their current content is kept in the artifact's version history), artifacts not in the snapshot are deleted, and missing ones are recreated. Since a restore may touch any path, it is rejected with 423 while another owner holds a lease anywhere on the disk.
+//	@Tags			disk
+//	@Accept			json
+//	@Produce		json
+//	@Param			disk_id			path	string	true	"Disk ID"		Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			snapshot_id		path	string	true	"Snapshot ID"	Format(uuid)	Example(123e4567-e89b-12d3-a456-426614174000)
+//	@Param			X-Lease-Owner	header	string	false	"Lease owner token, required when the disk has leases"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=repo.DiskSnapshotRestoreStats}
+//	@Failure		423	{object}	serializer.Response	"Disk is locked by another lease owner"
+//	@Router			/disk/{disk_id}/restore/{snapshot_id} [post]
+func (h *DiskHandler) RestoreDiskSnapshot(c *gin.Context) {
+	project, diskID, ok := h.diskFromPath(c)
+	if !ok {
+		return
+	}
+
+	snapshotID, err := uuid.Parse(c.Param("snapshot_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	if !checkLeases(c, h.leaseSvc, diskID, "/") {
+		return
+	}
+
+	stats, err := h.svc.RestoreSnapshot(c.Request.Context(), project.ID, diskID, snapshotID)
+	if err != nil {
+		if errors.Is(err, service.ErrSnapshotNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "snapshot not found", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: stats})
+}
+
+type CloneDiskReq struct {
+	SnapshotID string `form:"snapshot_id" json:"snapshot_id" binding:"required,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	User       string `form:"user" json:"user" example:"alice@acontext.io"` // Optional user identifier for the new disk
+}
+
+// CloneDisk godoc
+//
+//	@Summary		Clone disk from snapshot
+//	@Description	Create a new disk holding the artifacts captured by a snapshot. Assets are shared with the snapshot, so no content is copied.
+//	@Tags			disk
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	handler.CloneDiskReq	true	"CloneDisk payload"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.Disk}
+//	@Router			/disk/clone [post]
+func (h *DiskHandler) CloneDisk(c *gin.Context) {
+	req := CloneDiskReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	var userID *uuid.UUID
+	if req.User != "" {
+		user, err := h.userSvc.GetOrCreate(c.Request.Context(), project.ID, req.User)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("failed to get or create user", err))
+			return
+		}
+		userID = &user.ID
+	}
+
+	disk, err := h.svc.CloneSnapshot(c.Request.Context(), project.ID, uuid.MustParse(req.SnapshotID), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrSnapshotNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "snapshot not found", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, serializer.Response{Data: disk})
+}
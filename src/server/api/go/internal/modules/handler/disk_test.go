@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,6 +45,43 @@ func (m *MockDiskService) List(ctx context.Context, in service.ListDisksInput) (
 	return args.Get(0).(*service.ListDisksOutput), args.Error(1)
 }
 
+func (m *MockDiskService) CreateSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, name string) (*model.DiskSnapshot, error) {
+	args := m.Called(ctx, projectID, diskID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskService) ListSnapshots(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	args := m.Called(ctx, diskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskService) DeleteSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) error {
+	args := m.Called(ctx, projectID, diskID, snapshotID)
+	return args.Error(0)
+}
+
+func (m *MockDiskService) RestoreSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) (*repo.DiskSnapshotRestoreStats, error) {
+	args := m.Called(ctx, projectID, diskID, snapshotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repo.DiskSnapshotRestoreStats), args.Error(1)
+}
+
+func (m *MockDiskService) CloneSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, userID *uuid.UUID) (*model.Disk, error) {
+	args := m.Called(ctx, projectID, snapshotID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Disk), args.Error(1)
+}
+
 // MockDiskRepo is a mock implementation of DiskRepo for disk handler tests
 type MockDiskRepoForDisk struct {
 	mock.Mock
@@ -122,7 +161,7 @@ func TestDiskHandler_CreateDisk(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockDiskService{}
 			tt.setup(mockService)
-			handler := NewDiskHandler(mockService, &MockDiskRepoForDisk{}, &MockUserService{})
+			handler := NewDiskHandler(mockService, &MockDiskRepoForDisk{}, &MockUserService{}, nil)
 
 			router := setupDiskRouter()
 			router.POST("/disk", func(c *gin.Context) {
@@ -202,7 +241,7 @@ func TestDiskHandler_ListDisks(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockDiskService{}
 			tt.setup(mockService)
-			handler := NewDiskHandler(mockService, &MockDiskRepoForDisk{}, &MockUserService{})
+			handler := NewDiskHandler(mockService, &MockDiskRepoForDisk{}, &MockUserService{}, nil)
 
 			router := setupDiskRouter()
 			router.GET("/disk", func(c *gin.Context) {
@@ -295,7 +334,7 @@ func TestDiskHandler_DeleteDisk(t *testing.T) {
 			mockRepo := &MockDiskRepoForDisk{}
 			tt.setupSvc(mockService)
 			tt.setupRepo(mockRepo)
-			handler := NewDiskHandler(mockService, mockRepo, &MockUserService{})
+			handler := NewDiskHandler(mockService, mockRepo, &MockUserService{}, nil)
 
 			router := setupDiskRouter()
 			router.DELETE("/disk/:disk_id", func(c *gin.Context) {
@@ -324,3 +363,209 @@ func TestDiskHandler_DeleteDisk(t *testing.T) {
 		})
 	}
 }
+
+func TestDiskHandler_Snapshots(t *testing.T) {
+	projectID := uuid.New()
+	diskID := uuid.New()
+	snapshotID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		setupSvc       func(*MockDiskService)
+		setupUser      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:   "create snapshot",
+			method: "POST",
+			url:    "/disk/" + diskID.String() + "/snapshot",
+			body:   `{"name":"before-refactor"}`,
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("CreateSnapshot", mock.Anything, projectID, diskID, "before-refactor").Return(&model.DiskSnapshot{ID: snapshotID}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create snapshot on unknown disk",
+			method:         "POST",
+			url:            "/disk/" + uuid.New().String() + "/snapshot",
+			body:           `{}`,
+			setupSvc:       func(svc *MockDiskService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "list snapshots",
+			method: "GET",
+			url:    "/disk/" + diskID.String() + "/snapshot",
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("ListSnapshots", mock.Anything, diskID).Return([]*model.DiskSnapshot{{ID: snapshotID}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete unknown snapshot",
+			method: "DELETE",
+			url:    "/disk/" + diskID.String() + "/snapshot/" + snapshotID.String(),
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("DeleteSnapshot", mock.Anything, projectID, diskID, snapshotID).Return(service.ErrSnapshotNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "restore snapshot",
+			method: "POST",
+			url:    "/disk/" + diskID.String() + "/restore/" + snapshotID.String(),
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("RestoreSnapshot", mock.Anything, projectID, diskID, snapshotID).Return(&repo.DiskSnapshotRestoreStats{Written: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "restore with invalid snapshot id",
+			method:         "POST",
+			url:            "/disk/" + diskID.String() + "/restore/nope",
+			setupSvc:       func(svc *MockDiskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "clone for a user",
+			method: "POST",
+			url:    "/disk/clone",
+			body:   `{"snapshot_id":"` + snapshotID.String() + `","user":"alice"}`,
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("CloneSnapshot", mock.Anything, projectID, snapshotID, &userID).Return(&model.Disk{ID: uuid.New()}, nil)
+			},
+			setupUser: func(u *MockUserService) {
+				u.On("GetOrCreate", mock.Anything, projectID, "alice").Return(&model.User{ID: userID}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "clone unknown snapshot",
+			method: "POST",
+			url:    "/disk/clone",
+			body:   `{"snapshot_id":"` + snapshotID.String() + `"}`,
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("CloneSnapshot", mock.Anything, projectID, snapshotID, (*uuid.UUID)(nil)).Return(nil, service.ErrSnapshotNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "clone without snapshot id",
+			method:         "POST",
+			url:            "/disk/clone",
+			body:           `{}`,
+			setupSvc:       func(svc *MockDiskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockDiskService{}
+			tt.setupSvc(mockService)
+			userSvc := &MockUserService{}
+			if tt.setupUser != nil {
+				tt.setupUser(userSvc)
+			}
+			mockRepo := &MockDiskRepoForDisk{}
+			mockRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+			mockRepo.On("GetByProjectAndID", mock.Anything, projectID, mock.Anything).Return(nil, errors.New("record not found"))
+			handler := NewDiskHandler(mockService, mockRepo, userSvc, nil)
+
+			router := setupDiskRouter()
+			router.Use(func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+			})
+			router.POST("/disk/clone", handler.CloneDisk)
+			router.POST("/disk/:disk_id/snapshot", handler.CreateDiskSnapshot)
+			router.GET("/disk/:disk_id/snapshot", handler.ListDiskSnapshots)
+			router.DELETE("/disk/:disk_id/snapshot/:snapshot_id", handler.DeleteDiskSnapshot)
+			router.POST("/disk/:disk_id/restore/:snapshot_id", handler.RestoreDiskSnapshot)
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+			userSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDiskHandler_Leases(t *testing.T) {
+	projectID := uuid.New()
+	diskID := uuid.New()
+	snapshotID := uuid.New()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		lockErr        error
+		setupSvc       func(*MockDiskService)
+		expectedStatus int
+	}{
+		{
+			name:           "delete locked disk",
+			method:         "DELETE",
+			url:            "/disk/" + diskID.String(),
+			lockErr:        service.ErrArtifactLocked,
+			setupSvc:       func(svc *MockDiskService) {},
+			expectedStatus: http.StatusLocked,
+		},
+		{
+			name:           "restore locked disk",
+			method:         "POST",
+			url:            "/disk/" + diskID.String() + "/restore/" + snapshotID.String(),
+			lockErr:        service.ErrArtifactLocked,
+			setupSvc:       func(svc *MockDiskService) {},
+			expectedStatus: http.StatusLocked,
+		},
+		{
+			name:   "restore by the lease owner",
+			method: "POST",
+			url:    "/disk/" + diskID.String() + "/restore/" + snapshotID.String(),
+			setupSvc: func(svc *MockDiskService) {
+				svc.On("RestoreSnapshot", mock.Anything, projectID, diskID, snapshotID).Return(&repo.DiskSnapshotRestoreStats{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockDiskService{}
+			tt.setupSvc(mockService)
+			mockRepo := &MockDiskRepoForDisk{}
+			mockRepo.On("GetByProjectAndID", mock.Anything, projectID, diskID).Return(&model.Disk{ID: diskID, ProjectID: projectID}, nil)
+			leaseSvc := &MockArtifactLeaseService{}
+			leaseSvc.On("CheckWrite", mock.Anything, diskID, "agent-1", []string{"/"}).Return(tt.lockErr)
+			handler := NewDiskHandler(mockService, mockRepo, &MockUserService{}, leaseSvc)
+
+			router := setupDiskRouter()
+			router.Use(func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+			})
+			router.DELETE("/disk/:disk_id", handler.DeleteDisk)
+			router.POST("/disk/:disk_id/restore/:snapshot_id", handler.RestoreDiskSnapshot)
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			req.Header.Set("X-Lease-Owner", "agent-1")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+			leaseSvc.AssertExpectations(t)
+		})
+	}
+}
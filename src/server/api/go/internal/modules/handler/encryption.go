@@ -17,7 +17,7 @@ import (
 
 // encryptProject is the shared implementation for enabling project encryption.
 // Both AdminHandler and ProjectHandler delegate to this function.
-func encryptProject(c *gin.Context, db *gorm.DB, rdb *redis.Client, s3 *blob.S3Deps, assetRefRepo repo.AssetReferenceRepo) {
+func encryptProject(c *gin.Context, db *gorm.DB, rdb *redis.Client, s3 blob.BlobStore, assetRefRepo repo.AssetReferenceRepo) {
 	project, ok := c.MustGet("project").(*model.Project)
 	if !ok {
 		c.JSON(http.StatusBadRequest, serializer.ParamErr("project not found", fmt.Errorf("project not found")))
@@ -70,7 +70,7 @@ func encryptProject(c *gin.Context, db *gorm.DB, rdb *redis.Client, s3 *blob.S3D
 
 // decryptProject is the shared implementation for disabling project encryption.
 // Both AdminHandler and ProjectHandler delegate to this function.
-func decryptProject(c *gin.Context, db *gorm.DB, rdb *redis.Client, s3 *blob.S3Deps, assetRefRepo repo.AssetReferenceRepo) {
+func decryptProject(c *gin.Context, db *gorm.DB, rdb *redis.Client, s3 blob.BlobStore, assetRefRepo repo.AssetReferenceRepo) {
 	project, ok := c.MustGet("project").(*model.Project)
 	if !ok {
 		c.JSON(http.StatusBadRequest, serializer.ParamErr("project not found", fmt.Errorf("project not found")))
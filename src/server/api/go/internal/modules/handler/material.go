@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/memodb-io/Acontext/internal/modules/serializer"
@@ -21,12 +22,15 @@ func NewMaterialHandler(materialSvc service.MaterialService) *MaterialHandler {
 // Serve godoc
 //
 //	@Summary		Serve material content
-//	@Description	Download file content via a material token. No authentication required. Returns the file content with appropriate Content-Type header.
+//	@Description	Download file content via a material token. No authentication required. Returns the file content with appropriate Content-Type header. The content is streamed, and byte Range requests are answered with 206 Partial Content.
 //	@Tags			material
 //	@Produce		octet-stream
 //	@Param			token	path	string	true	"Material token (64-char hex)"
+//	@Param			Range	header	string	false	"Byte range, e.g. bytes=0-1023"
 //	@Success		200		"File content"
+//	@Success		206		"Requested byte range of the file content"
 //	@Failure		404		{object}	serializer.Response	"Token not found or expired"
+//	@Failure		416		"Range not satisfiable"
 //	@Router			/material/{token} [get]
 func (h *MaterialHandler) Serve(c *gin.Context) {
 	token := c.Param("token")
@@ -35,7 +39,7 @@ func (h *MaterialHandler) Serve(c *gin.Context) {
 		return
 	}
 
-	content, mimeType, fileName, err := h.materialSvc.ServeMaterial(c.Request.Context(), token)
+	object, mimeType, fileName, err := h.materialSvc.ServeMaterial(c.Request.Context(), token)
 	if err != nil {
 		if errors.Is(err, service.ErrMaterialNotFound) {
 			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "material not found", nil))
@@ -46,12 +50,16 @@ func (h *MaterialHandler) Serve(c *gin.Context) {
 	}
 
 	if mimeType == "" {
-		mimeType = "application/octet-stream"
+		mimeType = object.ContentType
 	}
 
 	if fileName != "" {
 		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
 	}
 
-	c.Data(http.StatusOK, mimeType, content)
+	body := object.NewReadSeeker(c.Request.Context())
+	defer body.Close()
+	c.Header("Content-Type", mimeType)
+	c.Header("ETag", `"`+object.ETag+`"`)
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, body)
 }
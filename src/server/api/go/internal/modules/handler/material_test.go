@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"net/http"
@@ -9,9 +10,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/infra/crypto"
 	"github.com/memodb-io/Acontext/internal/modules/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockMaterialService is a mock implementation of MaterialService
@@ -24,12 +28,12 @@ func (m *MockMaterialService) CreateMaterialURL(ctx context.Context, s3Key strin
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
 
-func (m *MockMaterialService) ServeMaterial(ctx context.Context, token string) ([]byte, string, string, error) {
+func (m *MockMaterialService) ServeMaterial(ctx context.Context, token string) (*blob.Object, string, string, error) {
 	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
 		return nil, args.String(1), args.String(2), args.Error(3)
 	}
-	return args.Get(0).([]byte), args.String(1), args.String(2), args.Error(3)
+	return args.Get(0).(*blob.Object), args.String(1), args.String(2), args.Error(3)
 }
 
 var _ service.MaterialService = (*MockMaterialService)(nil)
@@ -41,7 +45,7 @@ func TestMaterialHandler_Serve_Success(t *testing.T) {
 	handler := NewMaterialHandler(mockSvc)
 
 	content := []byte("hello world")
-	mockSvc.On("ServeMaterial", mock.Anything, "abc123").Return(content, "text/plain", "test.txt", nil)
+	mockSvc.On("ServeMaterial", mock.Anything, "abc123").Return(testBlobObject(t, content, nil), "text/plain", "test.txt", nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -83,7 +87,7 @@ func TestMaterialHandler_Serve_WithFileName(t *testing.T) {
 	handler := NewMaterialHandler(mockSvc)
 
 	content := []byte{0x89, 0x50, 0x4E, 0x47} // PNG magic bytes
-	mockSvc.On("ServeMaterial", mock.Anything, "img-token").Return(content, "image/png", "photo.png", nil)
+	mockSvc.On("ServeMaterial", mock.Anything, "img-token").Return(testBlobObject(t, content, nil), "image/png", "photo.png", nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -124,7 +128,7 @@ func TestMaterialHandler_Serve_NoFileName(t *testing.T) {
 	handler := NewMaterialHandler(mockSvc)
 
 	content := []byte("data")
-	mockSvc.On("ServeMaterial", mock.Anything, "noname-token").Return(content, "application/octet-stream", "", nil)
+	mockSvc.On("ServeMaterial", mock.Anything, "noname-token").Return(testBlobObject(t, content, nil), "application/octet-stream", "", nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -137,3 +141,59 @@ func TestMaterialHandler_Serve_NoFileName(t *testing.T) {
 	assert.Empty(t, w.Header().Get("Content-Disposition"))
 	mockSvc.AssertExpectations(t)
 }
+
+func TestMaterialHandler_Serve_Range(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	kek, err := crypto.DeriveKEK([]byte("test-secret"), []byte("salt"), []byte("info"))
+	require.NoError(t, err)
+	// spans several encryption chunks so the range starts and ends mid-chunk
+	content := bytes.Repeat([]byte("0123456789abcdef"), crypto.DefaultChunkSize/4)
+
+	tests := []struct {
+		name    string
+		userKEK []byte
+	}{
+		{name: "plaintext"},
+		{name: "encrypted", userKEK: kek},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockMaterialService)
+			handler := NewMaterialHandler(mockSvc)
+			mockSvc.On("ServeMaterial", mock.Anything, "range-token").Return(testBlobObject(t, content, tt.userKEK), "", "data.bin", nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "token", Value: "range-token"}}
+			c.Request, _ = http.NewRequest("GET", "/api/v1/material/range-token", nil)
+			c.Request.Header.Set("Range", "bytes=65530-131080")
+
+			handler.Serve(c)
+
+			assert.Equal(t, http.StatusPartialContent, w.Code)
+			assert.Equal(t, "bytes 65530-131080/262144", w.Header().Get("Content-Range"))
+			assert.Equal(t, content[65530:131081], w.Body.Bytes())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMaterialHandler_Serve_RangeNotSatisfiable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := new(MockMaterialService)
+	handler := NewMaterialHandler(mockSvc)
+	mockSvc.On("ServeMaterial", mock.Anything, "short-token").Return(testBlobObject(t, []byte("short"), nil), "text/plain", "", nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "token", Value: "short-token"}}
+	c.Request, _ = http.NewRequest("GET", "/api/v1/material/short-token", nil)
+	c.Request.Header.Set("Range", "bytes=100-200")
+
+	handler.Serve(c)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	mockSvc.AssertExpectations(t)
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+)
+
+type MessageFeedbackHandler struct {
+	svc service.MessageFeedbackService
+}
+
+func NewMessageFeedbackHandler(svc service.MessageFeedbackService) *MessageFeedbackHandler {
+	return &MessageFeedbackHandler{svc: svc}
+}
+
+type SubmitFeedbackReq struct {
+	User    string   `json:"user" example:"alice@acontext.io"`
+	Thumbs  string   `json:"thumbs" binding:"omitempty,oneof=up down" example:"up" enums:"up,down"`
+	Score   *float64 `json:"score" binding:"omitempty,min=0,max=1" example:"0.8"`
+	Comment string   `json:"comment" example:"Accurate, but too verbose"`
+	Labels  []string `json:"labels" example:"verbose"`
+}
+
+type GetFeedbackReq struct {
+	Limit         int    `form:"limit,default=50" json:"limit" binding:"min=1,max=200" example:"50"`
+	Cursor        string `form:"cursor" json:"cursor"`
+	TimeDesc      bool   `form:"time_desc,default=false" json:"time_desc" example:"false"`
+	MessageID     string `form:"message_id" json:"message_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	User          string `form:"user" json:"user" example:"alice@acontext.io"`
+	Thumbs        string `form:"thumbs" json:"thumbs" binding:"omitempty,oneof=up down" example:"down" enums:"up,down"`
+	Labels        string `form:"labels" json:"labels" example:"verbose"` // comma-separated; feedback must carry all of them
+	CreatedAfter  string `form:"created_after" json:"created_after" example:"2025-01-01T00:00:00Z"`
+	CreatedBefore string `form:"created_before" json:"created_before" example:"2025-02-01T00:00:00Z"`
+}
+
+// listInput converts the query into a service input, validating IDs and timestamps.
+func (req GetFeedbackReq) listInput(projectID uuid.UUID) (service.ListFeedbackInput, error) {
+	in := service.ListFeedbackInput{
+		ProjectID: projectID,
+		Limit:     req.Limit,
+		Cursor:    req.Cursor,
+		TimeDesc:  req.TimeDesc,
+		Filter: repo.MessageFeedbackFilter{
+			UserIdentifier: req.User,
+			Thumbs:         req.Thumbs,
+		},
+	}
+	if req.MessageID != "" {
+		messageID, err := uuid.Parse(req.MessageID)
+		if err != nil {
+			return in, errors.New("invalid message_id")
+		}
+		in.Filter.MessageID = &messageID
+	}
+	for _, l := range strings.Split(req.Labels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			in.Filter.Labels = append(in.Filter.Labels, l)
+		}
+	}
+	var err error
+	if in.Filter.CreatedAfter, err = parseOptionalTime("created_after", req.CreatedAfter); err != nil {
+		return in, err
+	}
+	if in.Filter.CreatedBefore, err = parseOptionalTime("created_before", req.CreatedBefore); err != nil {
+		return in, err
+	}
+	return in, nil
+}
+
+// SubmitFeedback godoc
+//
+//	@Summary		Submit message feedback
+//	@Description	Record a rating for a message: thumbs up/down, a score between 0 and 1, a free-form comment and labels. Every submission is stored as its own record, so concurrent raters never overwrite each other. At least one of thumbs, score, comment or labels is required.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path	string						true	"Session ID"	format(uuid)
+//	@Param			message_id	path	string						true	"Message ID"	format(uuid)
+//	@Param			payload		body	handler.SubmitFeedbackReq	true	"SubmitFeedback payload"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.MessageFeedback}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Failure		404	{object}	serializer.Response	"Session or message not found"
+//	@Router			/session/{session_id}/messages/{message_id}/feedback [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rate an assistant message\nfeedback = client.sessions.submit_feedback(\n    session_id='session-uuid',\n    message_id='message-uuid',\n    user='alice@acontext.io',\n    thumbs='down',\n    score=0.4,\n    comment='Accurate, but too verbose',\n    labels=['verbose']\n)\nprint(feedback.id)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rate an assistant message\nconst feedback = await client.sessions.submitFeedback('session-uuid', 'message-uuid', {\n  user: 'alice@acontext.io',\n  thumbs: 'down',\n  score: 0.4,\n  comment: 'Accurate, but too verbose',\n  labels: ['verbose']\n});\nconsole.log(feedback.id);\n","label":"JavaScript"}]
+func (h *MessageFeedbackHandler) SubmitFeedback(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid session_id", err))
+		return
+	}
+
+	messageID, err := uuid.Parse(c.Param("message_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid message_id", err))
+		return
+	}
+
+	req := SubmitFeedbackReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	fb, err := h.svc.Submit(c.Request.Context(), service.SubmitFeedbackInput{
+		ProjectID: project.ID,
+		SessionID: sessionID,
+		MessageID: messageID,
+		User:      req.User,
+		Thumbs:    req.Thumbs,
+		Score:     req.Score,
+		Comment:   req.Comment,
+		Labels:    req.Labels,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidFeedback):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		case errors.Is(err, service.ErrSessionNotFound), errors.Is(err, service.ErrMessageNotFound):
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, err.Error(), nil))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, serializer.Response{Data: fb})
+}
+
+// GetSessionFeedback godoc
+//
+//	@Summary		Get session feedback
+//	@Description	List feedback submitted for the messages of a session with cursor-based pagination, together with a summary (counts, thumbs totals, average score and label counts) over all matching feedback.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id		path	string	true	"Session ID"	format(uuid)
+//	@Param			message_id		query	string	false	"Only feedback for this message"	format(uuid)
+//	@Param			user			query	string	false	"Only feedback submitted by this user identifier"
+//	@Param			thumbs			query	string	false	"Only feedback with this thumbs value"	enums(up,down)
+//	@Param			labels			query	string	false	"Comma-separated labels; only feedback carrying all of them"
+//	@Param			created_after	query	string	false	"Only feedback submitted at or after this RFC3339 time"
+//	@Param			created_before	query	string	false	"Only feedback submitted before this RFC3339 time"
+//	@Param			limit			query	integer	false	"Limit of feedback to return, default 50. Max 200."
+//	@Param			cursor			query	string	false	"Cursor for pagination."
+//	@Param			time_desc		query	boolean	false	"Order by created_at descending if true, ascending if false (default false)"	example(false)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=service.ListFeedbackOutput}
+//	@Failure		404	{object}	serializer.Response	"Session not found"
+//	@Router			/session/{session_id}/feedback [get]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\nresult = client.sessions.get_feedback(session_id='session-uuid')\nprint(f\"{result.summary.thumbs_up} up / {result.summary.thumbs_down} down\")\nfor fb in result.items:\n    print(fb.message_id, fb.thumbs, fb.comment)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\nconst result = await client.sessions.getFeedback('session-uuid');\nconsole.log(`${result.summary.thumbs_up} up / ${result.summary.thumbs_down} down`);\nfor (const fb of result.items) {\n  console.log(fb.message_id, fb.thumbs, fb.comment);\n}\n","label":"JavaScript"}]
+func (h *MessageFeedbackHandler) GetSessionFeedback(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid session_id", err))
+		return
+	}
+	h.listFeedback(c, &sessionID)
+}
+
+// GetProjectFeedback godoc
+//
+//	@Summary		Get project feedback
+//	@Description	List message feedback across all sessions of the project with cursor-based pagination, together with a summary (counts, thumbs totals, average score and label counts) over all matching feedback.
+//	@Tags			Project
+//	@Accept			json
+//	@Produce		json
+//	@Param			message_id		query	string	false	"Only feedback for this message"	format(uuid)
+//	@Param			user			query	string	false	"Only feedback submitted by this user identifier"
+//	@Param			thumbs			query	string	false	"Only feedback with this thumbs value"	enums(up,down)
+//	@Param			labels			query	string	false	"Comma-separated labels; only feedback carrying all of them"
+//	@Param			created_after	query	string	false	"Only feedback submitted at or after this RFC3339 time"
+//	@Param			created_before	query	string	false	"Only feedback submitted before this RFC3339 time"
+//	@Param			limit			query	integer	false	"Limit of feedback to return, default 50. Max 200."
+//	@Param			cursor			query	string	false	"Cursor for pagination."
+//	@Param			time_desc		query	boolean	false	"Order by created_at descending if true, ascending if false (default false)"	example(false)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=service.ListFeedbackOutput}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Router			/project/feedback [get]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Negative feedback from the last week\nresult = client.project.get_feedback(thumbs='down', created_after='2025-01-01T00:00:00Z')\nprint(result.summary.count, result.summary.label_counts)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Negative feedback from the last week\nconst result = await client.project.getFeedback({ thumbs: 'down', createdAfter: '2025-01-01T00:00:00Z' });\nconsole.log(result.summary.count, result.summary.label_counts);\n","label":"JavaScript"}]
+func (h *MessageFeedbackHandler) GetProjectFeedback(c *gin.Context) {
+	h.listFeedback(c, nil)
+}
+
+func (h *MessageFeedbackHandler) listFeedback(c *gin.Context, sessionID *uuid.UUID) {
+	req := GetFeedbackReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	in, err := req.listInput(project.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr(err.Error(), err))
+		return
+	}
+	in.SessionID = sessionID
+
+	out, err := h.svc.List(c.Request.Context(), in)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "session not found", nil))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid cursor", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: out})
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMessageFeedbackService is a mock implementation of MessageFeedbackService
+type MockMessageFeedbackService struct {
+	mock.Mock
+}
+
+func (m *MockMessageFeedbackService) Submit(ctx context.Context, in service.SubmitFeedbackInput) (*model.MessageFeedback, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MessageFeedback), args.Error(1)
+}
+
+func (m *MockMessageFeedbackService) List(ctx context.Context, in service.ListFeedbackInput) (*service.ListFeedbackOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ListFeedbackOutput), args.Error(1)
+}
+
+func TestMessageFeedbackHandler_SubmitFeedback(t *testing.T) {
+	projectID := uuid.New()
+	sessionID := uuid.New()
+	messageID := uuid.New()
+	path := "/session/" + sessionID.String() + "/messages/" + messageID.String() + "/feedback"
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		setup          func(*MockMessageFeedbackService)
+		expectedStatus int
+	}{
+		{
+			name: "thumbs with labels",
+			path: path,
+			body: `{"user":"alice","thumbs":"up","score":0.9,"labels":["helpful"]}`,
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("Submit", mock.Anything, mock.MatchedBy(func(in service.SubmitFeedbackInput) bool {
+					return in.ProjectID == projectID && in.SessionID == sessionID && in.MessageID == messageID &&
+						in.User == "alice" && in.Thumbs == "up" && in.Score != nil && *in.Score == 0.9 &&
+						len(in.Labels) == 1 && in.Labels[0] == "helpful"
+				})).Return(&model.MessageFeedback{ID: uuid.New()}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid thumbs",
+			path:           path,
+			body:           `{"thumbs":"sideways"}`,
+			setup:          func(svc *MockMessageFeedbackService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "score out of range",
+			path:           path,
+			body:           `{"score":2}`,
+			setup:          func(svc *MockMessageFeedbackService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid message id",
+			path:           "/session/" + sessionID.String() + "/messages/nope/feedback",
+			body:           `{"thumbs":"up"}`,
+			setup:          func(svc *MockMessageFeedbackService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "empty feedback rejected by service",
+			path: path,
+			body: `{}`,
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("Submit", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidFeedback)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "message not found",
+			path: path,
+			body: `{"thumbs":"down"}`,
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("Submit", mock.Anything, mock.Anything).Return(nil, service.ErrMessageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "service layer error",
+			path: path,
+			body: `{"thumbs":"down"}`,
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("Submit", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageFeedbackService{}
+			tt.setup(mockService)
+
+			handler := NewMessageFeedbackHandler(mockService)
+			router := setupSessionRouter()
+			router.POST("/session/:session_id/messages/:message_id/feedback", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.SubmitFeedback(c)
+			})
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageFeedbackHandler_GetFeedback(t *testing.T) {
+	projectID := uuid.New()
+	sessionID := uuid.New()
+	out := &service.ListFeedbackOutput{Items: []model.MessageFeedback{}, Summary: &repo.MessageFeedbackSummary{}}
+
+	tests := []struct {
+		name           string
+		url            string
+		setup          func(*MockMessageFeedbackService)
+		expectedStatus int
+	}{
+		{
+			name: "session feedback with filters",
+			url:  "/session/" + sessionID.String() + "/feedback?thumbs=down&labels=verbose,%20style&created_after=2025-01-01T00:00:00Z",
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListFeedbackInput) bool {
+					return in.ProjectID == projectID && in.SessionID != nil && *in.SessionID == sessionID &&
+						in.Limit == 50 && in.Filter.Thumbs == "down" &&
+						len(in.Filter.Labels) == 2 && in.Filter.Labels[1] == "style" &&
+						in.Filter.CreatedAfter != nil
+				})).Return(out, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "session not found",
+			url:  "/session/" + sessionID.String() + "/feedback",
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("List", mock.Anything, mock.Anything).Return(nil, service.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "project feedback",
+			url:  "/project/feedback?user=alice&limit=10",
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListFeedbackInput) bool {
+					return in.SessionID == nil && in.Limit == 10 && in.Filter.UserIdentifier == "alice"
+				})).Return(out, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "malformed cursor",
+			url:  "/project/feedback?cursor=nope",
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("List", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: bad encoding", service.ErrInvalidCursor))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "list failure",
+			url:  "/project/feedback",
+			setup: func(svc *MockMessageFeedbackService) {
+				svc.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid created_before",
+			url:            "/project/feedback?created_before=yesterday",
+			setup:          func(svc *MockMessageFeedbackService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid message_id",
+			url:            "/project/feedback?message_id=nope",
+			setup:          func(svc *MockMessageFeedbackService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageFeedbackService{}
+			tt.setup(mockService)
+
+			handler := NewMessageFeedbackHandler(mockService)
+			router := setupSessionRouter()
+			withProject := func(h gin.HandlerFunc) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					c.Set("project", &model.Project{ID: projectID})
+					h(c)
+				}
+			}
+			router.GET("/session/:session_id/feedback", withProject(handler.GetSessionFeedback))
+			router.GET("/project/feedback", withProject(handler.GetProjectFeedback))
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
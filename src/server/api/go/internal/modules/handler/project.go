@@ -16,11 +16,11 @@ import (
 type ProjectHandler struct {
 	db           *gorm.DB
 	rdb          *redis.Client
-	s3           *blob.S3Deps
+	s3           blob.BlobStore
 	assetRefRepo repo.AssetReferenceRepo
 }
 
-func NewProjectHandler(db *gorm.DB, rdb *redis.Client, s3 *blob.S3Deps, assetRefRepo repo.AssetReferenceRepo) *ProjectHandler {
+func NewProjectHandler(db *gorm.DB, rdb *redis.Client, s3 blob.BlobStore, assetRefRepo repo.AssetReferenceRepo) *ProjectHandler {
 	return &ProjectHandler{db: db, rdb: rdb, s3: s3, assetRefRepo: assetRefRepo}
 }
 
@@ -35,13 +35,15 @@ type SessionHandler struct {
 	svc        service.SessionService
 	userSvc    service.UserService
 	coreClient *httpclient.CoreClient
+	uploadSvc  service.UploadService
 }
 
-func NewSessionHandler(s service.SessionService, userSvc service.UserService, coreClient *httpclient.CoreClient) *SessionHandler {
+func NewSessionHandler(s service.SessionService, userSvc service.UserService, coreClient *httpclient.CoreClient, uploadSvc service.UploadService) *SessionHandler {
 	return &SessionHandler{
 		svc:        s,
 		userSvc:    userSvc,
 		coreClient: coreClient,
+		uploadSvc:  uploadSvc,
 	}
 }
 
@@ -50,30 +52,75 @@ type CreateSessionReq struct {
 	DisableTaskTracking *bool                  `form:"disable_task_tracking" json:"disable_task_tracking" example:"false"`
 	Configs             map[string]interface{} `form:"configs" json:"configs"`
 	UseUUID             *string                `form:"use_uuid" json:"use_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Title               string                 `form:"title" json:"title" binding:"max=256" example:"Trip planning"`
+	Tags                []string               `form:"tags" json:"tags" example:"travel,draft"`
 }
 
 type GetSessionsReq struct {
-	User            string `form:"user" json:"user" example:"alice@acontext.io"`
-	Limit           int    `form:"limit,default=20" json:"limit" binding:"required,min=1,max=200" example:"20"`
-	Cursor          string `form:"cursor" json:"cursor" example:"cHJvdGVjdGVkIHZlcnNpb24gdG8gYmUgZXhjbHVkZWQgaW4gcGFyc2luZyB0aGUgY3Vyc29y"`
-	TimeDesc        bool   `form:"time_desc,default=false" json:"time_desc" example:"false"`
-	FilterByConfigs string `form:"filter_by_configs" json:"filter_by_configs"` // JSON-encoded string for JSONB containment filter
+	User             string `form:"user" json:"user" example:"alice@acontext.io"`
+	Limit            int    `form:"limit,default=20" json:"limit" binding:"required,min=1,max=200" example:"20"`
+	Cursor           string `form:"cursor" json:"cursor" example:"cHJvdGVjdGVkIHZlcnNpb24gdG8gYmUgZXhjbHVkZWQgaW4gcGFyc2luZyB0aGUgY3Vyc29y"`
+	TimeDesc         bool   `form:"time_desc,default=false" json:"time_desc" example:"false"`
+	SortBy           string `form:"sort_by" json:"sort_by" binding:"omitempty,oneof=created_at updated_at last_message_at" example:"last_message_at" enums:"created_at,updated_at,last_message_at"`
+	FilterByConfigs  string `form:"filter_by_configs" json:"filter_by_configs"` // JSON-encoded string for JSONB containment filter
+	ConfigConditions string `form:"config_conditions" json:"config_conditions"` // JSON-encoded array of {key, op, value}
+	CreatedAfter     string `form:"created_after" json:"created_after" example:"2025-01-01T00:00:00Z"`
+	CreatedBefore    string `form:"created_before" json:"created_before" example:"2025-02-01T00:00:00Z"`
+	UpdatedAfter     string `form:"updated_after" json:"updated_after" example:"2025-01-01T00:00:00Z"`
+	UpdatedBefore    string `form:"updated_before" json:"updated_before" example:"2025-02-01T00:00:00Z"`
+	HasMessages      *bool  `form:"has_messages" json:"has_messages" example:"true"`
+	MinMessages      *int   `form:"min_messages" json:"min_messages" binding:"omitempty,min=0" example:"1"`
+	MaxMessages      *int   `form:"max_messages" json:"max_messages" binding:"omitempty,min=0" example:"100"`
+	TaskStatus       string `form:"task_status" json:"task_status" binding:"omitempty,oneof=success failed running pending" example:"running" enums:"success,failed,running,pending"`
+	LearningSpaceID  string `form:"learning_space_id" json:"learning_space_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	InLearningSpace  *bool  `form:"in_learning_space" json:"in_learning_space" example:"false"`
+	Tags             string `form:"tags" json:"tags" example:"travel,draft"` // comma-separated; sessions must carry all of them
+	TitleContains    string `form:"title_contains" json:"title_contains" example:"trip"`
+	Archived         *bool  `form:"archived" json:"archived" example:"false"`
+}
+
+// parseOptionalTime parses an optional RFC3339 timestamp query parameter.
+func parseOptionalTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected RFC3339 timestamp: %w", name, err)
+	}
+	return &t, nil
 }
 
 // GetSessions godoc
 //
 //	@Summary		Get sessions
-//	@Description	Get all sessions under a project, optionally filtered by user or configs
+//	@Description	Get all sessions under a project, optionally filtered by user, configs, time ranges, message counts, task status, learning space membership, tags, title or archive state
 //	@Tags			session
 //	@Accept			json
 //	@Produce		json
 //	@Param			user				query	string	false	"User identifier to filter sessions"	example(alice@acontext.io)
 //	@Param			filter_by_configs	query	string	false	"JSON-encoded object for JSONB containment filter. Example: {\"agent\":\"bot1\"}"
+//	@Param			config_conditions	query	string	false	"JSON-encoded array of conditions on top-level configs keys. op is one of exists, not_exists, eq, ne, gt, gte, lt, lte. Example: [{\"key\":\"priority\",\"op\":\"gte\",\"value\":3}]"
+//	@Param			created_after		query	string	false	"Only sessions created at or after this RFC3339 time"
+//	@Param			created_before		query	string	false	"Only sessions created before this RFC3339 time"
+//	@Param			updated_after		query	string	false	"Only sessions updated at or after this RFC3339 time"
+//	@Param			updated_before		query	string	false	"Only sessions updated before this RFC3339 time"
+//	@Param			has_messages		query	boolean	false	"Only sessions with (true) or without (false) messages"
+//	@Param			min_messages		query	integer	false	"Only sessions with at least this many messages"
+//	@Param			max_messages		query	integer	false	"Only sessions with at most this many messages"
+//	@Param			task_status			query	string	false	"Only sessions having at least one task in this status"	enums(success,failed,running,pending)
+//	@Param			learning_space_id	query	string	false	"Only sessions that belong to this learning space"	format(uuid)
+//	@Param			in_learning_space	query	boolean	false	"Only sessions that belong (true) or do not belong (false) to any learning space"
+//	@Param			tags				query	string	false	"Comma-separated tags; only sessions carrying all of them"	example(travel,draft)
+//	@Param			title_contains		query	string	false	"Case-insensitive substring match on the session title"
+//	@Param			archived			query	boolean	false	"Only archived (true) or unarchived (false) sessions; omit to include both"
+//	@Param			sort_by				query	string	false	"Sort key, default created_at. last_message_at falls back to created_at for sessions without messages."	enums(created_at,updated_at,last_message_at)
 //	@Param			limit				query	integer	false	"Limit of sessions to return, default 20. Max 200."
-//	@Param			cursor				query	string	false	"Cursor for pagination. Use the cursor from the previous response to get the next page."
-//	@Param			time_desc			query	boolean	false	"Order by created_at descending if true, ascending if false (default false)"	example(false)
+//	@Param			cursor				query	string	false	"Cursor for pagination. Use the cursor from the previous response to get the next page, with the same sort_by."
+//	@Param			time_desc			query	boolean	false	"Order by the sort key descending if true, ascending if false (default false)"	example(false)
 //	@Security		BearerAuth
 //	@Success		200	{object}	serializer.Response{data=service.ListSessionsOutput}
+//	@Failure		400	{object}	serializer.Response	"Invalid filter or cursor"
 //	@Router			/session [get]
 //	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# List sessions\nsessions = client.sessions.list(\n    limit=20,\n    time_desc=True\n)\nfor session in sessions.items:\n    print(f\"{session.id}\")\n\n# List sessions for a specific user\nsessions = client.sessions.list(user='alice@acontext.io', limit=20)\n\n# List sessions filtered by configs\nsessions = client.sessions.list(\n    limit=20,\n    filter_by_configs={\"agent\": \"bot1\"}\n)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// List sessions\nconst sessions = await client.sessions.list({\n  limit: 20,\n  timeDesc: true\n});\nfor (const session of sessions.items) {\n  console.log(`${session.id}`);\n}\n\n// List sessions for a specific user\nconst userSessions = await client.sessions.list({ user: 'alice@acontext.io', limit: 20 });\n\n// List sessions filtered by configs\nconst filteredSessions = await client.sessions.list({\n  limit: 20,\n  filterByConfigs: { agent: 'bot1' }\n});\n","label":"JavaScript"}]
 func (h *SessionHandler) GetSessions(c *gin.Context) {
@@ -102,15 +149,82 @@ func (h *SessionHandler) GetSessions(c *gin.Context) {
 		}
 	}
 
-	out, err := h.svc.List(c.Request.Context(), service.ListSessionsInput{
-		ProjectID:       project.ID,
-		User:            req.User,
-		FilterByConfigs: filterByConfigs,
-		Limit:           req.Limit,
-		Cursor:          req.Cursor,
-		TimeDesc:        req.TimeDesc,
-	})
+	// Parse config_conditions JSON string
+	var configConditions []repo.ConfigCondition
+	if req.ConfigConditions != "" {
+		if err := json.Unmarshal([]byte(req.ConfigConditions), &configConditions); err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid config_conditions JSON", err))
+			return
+		}
+		for _, cond := range configConditions {
+			if err := cond.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid config_conditions", err))
+				return
+			}
+		}
+	}
+
+	in := service.ListSessionsInput{
+		ProjectID:        project.ID,
+		User:             req.User,
+		FilterByConfigs:  filterByConfigs,
+		ConfigConditions: configConditions,
+		HasMessages:      req.HasMessages,
+		MinMessages:      req.MinMessages,
+		MaxMessages:      req.MaxMessages,
+		TaskStatus:       req.TaskStatus,
+		InLearningSpace:  req.InLearningSpace,
+		TitleContains:    req.TitleContains,
+		Archived:         req.Archived,
+		SortBy:           req.SortBy,
+		Limit:            req.Limit,
+		Cursor:           req.Cursor,
+		TimeDesc:         req.TimeDesc,
+	}
+
+	// Parse time range filters
+	for _, tf := range []struct {
+		name  string
+		value string
+		dst   **time.Time
+	}{
+		{"created_after", req.CreatedAfter, &in.CreatedAfter},
+		{"created_before", req.CreatedBefore, &in.CreatedBefore},
+		{"updated_after", req.UpdatedAfter, &in.UpdatedAfter},
+		{"updated_before", req.UpdatedBefore, &in.UpdatedBefore},
+	} {
+		t, err := parseOptionalTime(tf.name, tf.value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
+		*tf.dst = t
+	}
+
+	if req.Tags != "" {
+		tags, err := service.NormalizeTags(strings.Split(req.Tags, ","))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid tags", err))
+			return
+		}
+		in.Tags = tags
+	}
+
+	if req.LearningSpaceID != "" {
+		lsID, err := uuid.Parse(req.LearningSpaceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid learning_space_id", err))
+			return
+		}
+		in.LearningSpaceID = &lsID
+	}
+
+	out, err := h.svc.List(c.Request.Context(), in)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid cursor", err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
 		return
 	}
@@ -149,6 +263,8 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		ProjectID:           project.ID,
 		DisableTaskTracking: false, // Default value
 		Configs:             datatypes.JSONMap(req.Configs),
+		Title:               strings.TrimSpace(req.Title),
+		Tags:                req.Tags,
 	}
 
 	// If use_uuid is provided, validate and set the session ID
@@ -188,6 +304,10 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 	}
 
 	if err := h.svc.Create(c.Request.Context(), &session); err != nil {
+		if errors.Is(err, service.ErrInvalidTags) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
 		// Check for duplicate key error (race condition: created between Get and Create)
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "23505") {
 			c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "session with this UUID already exists", nil))
@@ -360,6 +480,9 @@ type StoreMessageReq struct {
 //	// Content-Type: multipart/form-data
 //	@Param			payload		formData	string					false	"StoreMessage payload (Content-Type: multipart/form-data)"
 //	@Param			file		formData	file					false	"When uploading files, the field name must correspond to parts[*].file_field."
+//
+//	// Large files can instead be sent as resumable uploads with target "message" (see POST /upload)
+//	// and referenced by parts[*].upload_id in the acontext format.
 //	@Security		BearerAuth
 //	@Success		201	{object}	serializer.Response{data=model.Message}
 //	@Router			/session/{session_id}/messages [post]
@@ -426,10 +549,14 @@ func (h *SessionHandler) StoreMessage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, serializer.ParamErr(fmt.Sprintf("failed to normalize %s message", format), err))
 		return
 	}
+	var uploadIDs []string
 	for _, p := range normalizedParts {
 		if p.FileField != "" {
 			fileFields = append(fileFields, p.FileField)
 		}
+		if p.UploadID != "" {
+			uploadIDs = append(uploadIDs, p.UploadID)
+		}
 	}
 
 	// Handle file uploads if multipart
@@ -457,6 +584,22 @@ func (h *SessionHandler) StoreMessage(c *gin.Context) {
 		return
 	}
 
+	// Resolve files sent as resumable uploads
+	uploadMap := map[string]*model.Upload{}
+	for _, rawID := range uploadIDs {
+		uploadID, err := uuid.Parse(rawID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr(fmt.Sprintf("invalid upload_id %s", rawID), err))
+			return
+		}
+		upload, err := h.uploadSvc.Resolve(c.Request.Context(), project.ID, uploadID, model.UploadTargetMessage)
+		if err != nil {
+			uploadErr(c, err)
+			return
+		}
+		uploadMap[rawID] = upload
+	}
+
 	// Store user-provided meta in __user_meta__ field for complete isolation from system fields
 	if len(req.Meta) > 0 {
 		if normalizedMeta == nil {
@@ -473,6 +616,7 @@ func (h *SessionHandler) StoreMessage(c *gin.Context) {
 		Format:      format,
 		MessageMeta: normalizedMeta,
 		Files:       fileMap,
+		Uploads:     uploadMap,
 		UserKEK:     middleware.GetUserKEKIfEncrypted(c),
 	})
 	if err != nil {
@@ -766,6 +910,82 @@ func (h *SessionHandler) GetTokenCounts(c *gin.Context) {
 	}})
 }
 
+// GetSessionStats godoc
+//
+//	@Summary		Get session stats
+//	@Description	Get aggregated statistics of a session: message counts by role, part type histogram, tool-call counts by tool name, tool error rate, total and per-role tokens, asset bytes, first/last message time and task counts by status
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path	string	true	"Session ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=service.SessionStatsOutput}
+//	@Router			/session/{session_id}/stats [get]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Get session stats\nstats = client.sessions.get_stats(session_id='session-uuid')\nprint(f\"Messages: {stats.message_count}, Tool error rate: {stats.tool_error_rate}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Get session stats\nconst stats = await client.sessions.getStats('session-uuid');\nconsole.log(`Messages: ${stats.message_count}, Tool error rate: ${stats.tool_error_rate}`);\n","label":"JavaScript"}]
+func (h *SessionHandler) GetSessionStats(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	stats, err := h.svc.GetStats(c.Request.Context(), project.ID, sessionID, middleware.GetUserKEKIfEncrypted(c))
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "session not found", nil))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("failed to get session stats", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: stats})
+}
+
+// GetPendingUploads godoc
+//
+//	@Summary		Get pending message asset uploads
+//	@Description	List the assets of the session's messages that are not in storage yet: their parts JSON and files are uploaded after the message is stored and retried with backoff when an upload fails. Until then they are still read from the queue. Each entry shows its status (pending, or failed once it ran out of attempts), the attempts made, the last error and when it is retried next. An empty list means everything is stored.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path	string	true	"Session ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=[]model.PendingAssetUpload}
+//	@Failure		404	{object}	serializer.Response
+//	@Router			/session/{session_id}/pending_uploads [get]
+func (h *SessionHandler) GetPendingUploads(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	uploads, err := h.svc.ListPendingUploads(c.Request.Context(), project.ID, sessionID)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "session not found", nil))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("failed to list pending uploads", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: uploads})
+}
+
 // GetSessionObservingStatus godoc
 //
 //	@Summary		Get message observing status for a session
@@ -946,6 +1166,161 @@ func (h *SessionHandler) PatchConfigs(c *gin.Context) {
 	c.JSON(http.StatusOK, serializer.Response{Data: PatchSessionConfigsResp{Configs: updatedConfigs}})
 }
 
+type UpdateSessionReq struct {
+	Title    *string   `json:"title" binding:"omitempty,max=256" example:"Trip planning"`
+	Tags     *[]string `json:"tags" example:"travel,draft"` // replaces the full tag set
+	Archived *bool     `json:"archived" example:"false"`
+}
+
+// UpdateSession godoc
+//
+//	@Summary		Update session title, tags or archive state
+//	@Description	Overwrite the title, tags and/or archived flag of a session. Omitted fields are left unchanged; tags replaces the whole tag set.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path	string						true	"Session ID"	format(uuid)
+//	@Param			payload		body	handler.UpdateSessionReq	true	"UpdateSession payload"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Session}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Failure		404	{object}	serializer.Response	"Session not found"
+//	@Router			/session/{session_id} [patch]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Rename and tag a session\nsession = client.sessions.update(\n    session_id='session-uuid',\n    title='Trip planning',\n    tags=['travel', 'draft']\n)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Rename and tag a session\nconst session = await client.sessions.update('session-uuid', {\n  title: 'Trip planning',\n  tags: ['travel', 'draft']\n});\n","label":"JavaScript"}]
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+	req := UpdateSessionReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid session_id", err))
+		return
+	}
+
+	in := service.UpdateSessionInfoInput{Tags: req.Tags, Archived: req.Archived}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		in.Title = &title
+	}
+
+	session, err := h.svc.UpdateInfo(c.Request.Context(), project.ID, sessionID, in)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "session not found", nil))
+		case errors.Is(err, service.ErrInvalidTags):
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		default:
+			c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: session})
+}
+
+type BulkTagSessionsReq struct {
+	SessionIDs []uuid.UUID `json:"session_ids" binding:"required,min=1,max=1000" swaggertype:"array,string" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Add        []string    `json:"add" example:"travel"`
+	Remove     []string    `json:"remove" example:"draft"`
+}
+
+type BulkArchiveSessionsReq struct {
+	SessionIDs []uuid.UUID `json:"session_ids" binding:"required,min=1,max=1000" swaggertype:"array,string" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Archived   *bool       `json:"archived" binding:"required" example:"true"`
+}
+
+type BulkUpdateSessionsResp struct {
+	Updated int64 `json:"updated"`
+}
+
+// BulkTagSessions godoc
+//
+//	@Summary		Bulk tag sessions
+//	@Description	Add and/or remove tags on multiple sessions at once. Sessions that do not belong to the project are ignored. Returns the number of sessions updated.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	handler.BulkTagSessionsReq	true	"BulkTagSessions payload"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=handler.BulkUpdateSessionsResp}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Router			/session/tags [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Tag several sessions at once\nresult = client.sessions.bulk_tag(\n    session_ids=['session-uuid-1', 'session-uuid-2'],\n    add=['travel'],\n    remove=['draft']\n)\nprint(result.updated)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Tag several sessions at once\nconst result = await client.sessions.bulkTag({\n  sessionIds: ['session-uuid-1', 'session-uuid-2'],\n  add: ['travel'],\n  remove: ['draft']\n});\nconsole.log(result.updated);\n","label":"JavaScript"}]
+func (h *SessionHandler) BulkTagSessions(c *gin.Context) {
+	req := BulkTagSessionsReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("at least one of add or remove is required")))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	updated, err := h.svc.BulkUpdateTags(c.Request.Context(), project.ID, req.SessionIDs, req.Add, req.Remove)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTags) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: BulkUpdateSessionsResp{Updated: updated}})
+}
+
+// BulkArchiveSessions godoc
+//
+//	@Summary		Bulk archive sessions
+//	@Description	Archive or unarchive multiple sessions at once. Sessions that do not belong to the project are ignored. Returns the number of sessions updated.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	handler.BulkArchiveSessionsReq	true	"BulkArchiveSessions payload"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=handler.BulkUpdateSessionsResp}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Router			/session/archive [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Archive several sessions at once\nresult = client.sessions.bulk_archive(\n    session_ids=['session-uuid-1', 'session-uuid-2'],\n    archived=True\n)\nprint(result.updated)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Archive several sessions at once\nconst result = await client.sessions.bulkArchive({\n  sessionIds: ['session-uuid-1', 'session-uuid-2'],\n  archived: true\n});\nconsole.log(result.updated);\n","label":"JavaScript"}]
+func (h *SessionHandler) BulkArchiveSessions(c *gin.Context) {
+	req := BulkArchiveSessionsReq{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	updated, err := h.svc.BulkSetArchived(c.Request.Context(), project.ID, req.SessionIDs, *req.Archived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: BulkUpdateSessionsResp{Updated: updated}})
+}
+
 // CopySession godoc
 //
 //	@Summary		Copy session
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/middleware"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+)
+
+type SessionBulkHandler struct {
+	svc service.SessionBulkService
+}
+
+func NewSessionBulkHandler(svc service.SessionBulkService) *SessionBulkHandler {
+	return &SessionBulkHandler{svc: svc}
+}
+
+type BulkSessionsFilterReq struct {
+	User            string                 `json:"user" example:"alice@acontext.io"`
+	FilterByConfigs map[string]interface{} `json:"filter_by_configs"`
+	CreatedAfter    *time.Time             `json:"created_after" example:"2025-01-01T00:00:00Z"`
+	CreatedBefore   *time.Time             `json:"created_before" example:"2025-02-01T00:00:00Z"`
+	UpdatedAfter    *time.Time             `json:"updated_after" example:"2025-01-01T00:00:00Z"`
+	UpdatedBefore   *time.Time             `json:"updated_before" example:"2025-02-01T00:00:00Z"`
+}
+
+type BulkSessionsReq struct {
+	Action     string                 `json:"action" binding:"required,oneof=delete archive patch_configs copy learn" example:"delete" enums:"delete,archive,patch_configs,copy,learn"`
+	SessionIDs []uuid.UUID            `json:"session_ids" swaggertype:"array,string" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Filter     *BulkSessionsFilterReq `json:"filter"`
+
+	Configs         map[string]interface{} `json:"configs"`                                              // patch_configs: keys to patch, null deletes a key
+	Archived        *bool                  `json:"archived" example:"true"`                              // archive: false unarchives (default true)
+	LearningSpaceID *uuid.UUID             `json:"learning_space_id" swaggertype:"string" format:"uuid"` // learn: target learning space
+}
+
+// BulkSessions godoc
+//
+//	@Summary		Run a bulk session operation
+//	@Description	Start an asynchronous job applying one action (delete, archive, patch_configs, copy or learn) to sessions selected by an explicit session_ids list or by a filter on user, configs and time range. Returns the job, whose status and per-item results can be polled via GET /session/bulk/{job_id}.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	handler.BulkSessionsReq	true	"BulkSessions payload"
+//	@Security		BearerAuth
+//	@Success		202	{object}	serializer.Response{data=model.SessionBulkJob}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Router			/session/bulk [post]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\n# Delete all sessions of a test user\njob = client.sessions.bulk(\n    action='delete',\n    filter={'user': 'test@acontext.io'}\n)\n\n# Poll for progress\njob = client.sessions.get_bulk_job(job.id)\nprint(f\"{job.status}: {job.succeeded}/{job.total}\")\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\n// Delete all sessions of a test user\nlet job = await client.sessions.bulk({\n  action: 'delete',\n  filter: { user: 'test@acontext.io' }\n});\n\n// Poll for progress\njob = await client.sessions.getBulkJob(job.id);\nconsole.log(`${job.status}: ${job.succeeded}/${job.total}`);\n","label":"JavaScript"}]
+func (h *SessionBulkHandler) BulkSessions(c *gin.Context) {
+	req := BulkSessionsReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	in := service.SubmitBulkInput{
+		ProjectID:       project.ID,
+		Action:          req.Action,
+		SessionIDs:      req.SessionIDs,
+		Configs:         req.Configs,
+		Archived:        req.Archived,
+		LearningSpaceID: req.LearningSpaceID,
+		UserKEK:         middleware.GetUserKEKIfEncrypted(c),
+	}
+	if req.Filter != nil {
+		in.Filter = &service.BulkSessionFilter{
+			User:            req.Filter.User,
+			FilterByConfigs: req.Filter.FilterByConfigs,
+			CreatedAfter:    req.Filter.CreatedAfter,
+			CreatedBefore:   req.Filter.CreatedBefore,
+			UpdatedAfter:    req.Filter.UpdatedAfter,
+			UpdatedBefore:   req.Filter.UpdatedBefore,
+		}
+	}
+
+	job, err := h.svc.Submit(c.Request.Context(), in)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkRequest) {
+			c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, serializer.Response{Data: job})
+}
+
+// GetBulkJob godoc
+//
+//	@Summary		Get bulk session job
+//	@Description	Get the status, progress counters and per-item results of a bulk session job.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			job_id	path	string	true	"Bulk job ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.SessionBulkJob}
+//	@Failure		404	{object}	serializer.Response	"Job not found"
+//	@Router			/session/bulk/{job_id} [get]
+//	@x-code-samples	[{"lang":"python","source":"from acontext import AcontextClient\n\nclient = AcontextClient(api_key='sk_project_token')\n\njob = client.sessions.get_bulk_job('job-uuid')\nfor item in job.results:\n    if item.status == 'failed':\n        print(item.session_id, item.error)\n","label":"Python"},{"lang":"javascript","source":"import { AcontextClient } from '@acontext/acontext';\n\nconst client = new AcontextClient({ apiKey: 'sk_project_token' });\n\nconst job = await client.sessions.getBulkJob('job-uuid');\nfor (const item of job.results) {\n  if (item.status === 'failed') console.log(item.session_id, item.error);\n}\n","label":"JavaScript"}]
+func (h *SessionBulkHandler) GetBulkJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid job_id", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	job, err := h.svc.GetJob(c.Request.Context(), project.ID, jobID)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkJobNotFound) {
+			c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "bulk job not found", nil))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: job})
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSessionBulkService is a mock implementation of SessionBulkService
+type MockSessionBulkService struct {
+	mock.Mock
+}
+
+func (m *MockSessionBulkService) Submit(ctx context.Context, in service.SubmitBulkInput) (*model.SessionBulkJob, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionBulkJob), args.Error(1)
+}
+
+func (m *MockSessionBulkService) GetJob(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error) {
+	args := m.Called(ctx, projectID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionBulkJob), args.Error(1)
+}
+
+func TestSessionBulkHandler_BulkSessions(t *testing.T) {
+	projectID := uuid.New()
+	id1 := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockSessionBulkService)
+		expectedStatus int
+	}{
+		{
+			name: "delete by ids",
+			body: `{"action":"delete","session_ids":["` + id1.String() + `"]}`,
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("Submit", mock.Anything, mock.MatchedBy(func(in service.SubmitBulkInput) bool {
+					return in.ProjectID == projectID && in.Action == model.BulkActionDelete &&
+						len(in.SessionIDs) == 1 && in.SessionIDs[0] == id1 && in.Filter == nil
+				})).Return(&model.SessionBulkJob{ID: uuid.New(), Status: model.BulkJobStatusPending, Total: 1}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "archive by filter",
+			body: `{"action":"archive","filter":{"user":"test@acontext.io","created_before":"2025-02-01T00:00:00Z"}}`,
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("Submit", mock.Anything, mock.MatchedBy(func(in service.SubmitBulkInput) bool {
+					return in.Action == model.BulkActionArchive && in.Filter != nil &&
+						in.Filter.User == "test@acontext.io" && in.Filter.CreatedBefore != nil
+				})).Return(&model.SessionBulkJob{ID: uuid.New(), Status: model.BulkJobStatusPending}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unknown action",
+			body:           `{"action":"explode","session_ids":["` + id1.String() + `"]}`,
+			setup:          func(svc *MockSessionBulkService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid request from service",
+			body: `{"action":"patch_configs","session_ids":["` + id1.String() + `"]}`,
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("Submit", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidBulkRequest)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "service layer error",
+			body: `{"action":"delete","session_ids":["` + id1.String() + `"]}`,
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("Submit", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionBulkService{}
+			tt.setup(mockService)
+
+			handler := NewSessionBulkHandler(mockService)
+			router := setupSessionRouter()
+			router.POST("/session/bulk", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.BulkSessions(c)
+			})
+
+			req := httptest.NewRequest("POST", "/session/bulk", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionBulkHandler_GetBulkJob(t *testing.T) {
+	projectID := uuid.New()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name           string
+		jobID          string
+		setup          func(*MockSessionBulkService)
+		expectedStatus int
+	}{
+		{
+			name:  "found",
+			jobID: jobID.String(),
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("GetJob", mock.Anything, projectID, jobID).Return(&model.SessionBulkJob{ID: jobID, Status: model.BulkJobStatusCompleted}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "not found",
+			jobID: jobID.String(),
+			setup: func(svc *MockSessionBulkService) {
+				svc.On("GetJob", mock.Anything, projectID, jobID).Return(nil, service.ErrBulkJobNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid job id",
+			jobID:          "nope",
+			setup:          func(svc *MockSessionBulkService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionBulkService{}
+			tt.setup(mockService)
+
+			handler := NewSessionBulkHandler(mockService)
+			router := setupSessionRouter()
+			router.GET("/session/bulk/:job_id", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.GetBulkJob(c)
+			})
+
+			req := httptest.NewRequest("GET", "/session/bulk/"+tt.jobID, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
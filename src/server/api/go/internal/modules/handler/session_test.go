@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/gin-gonic/gin"
@@ -123,6 +126,40 @@ func (m *MockSessionService) DownloadAsset(ctx context.Context, s3Key string, us
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *MockSessionService) GetStats(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) (*service.SessionStatsOutput, error) {
+	args := m.Called(ctx, projectID, sessionID, userKEK)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SessionStatsOutput), args.Error(1)
+}
+
+func (m *MockSessionService) UpdateInfo(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, in service.UpdateSessionInfoInput) (*model.Session, error) {
+	args := m.Called(ctx, projectID, sessionID, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockSessionService) BulkUpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error) {
+	args := m.Called(ctx, projectID, sessionIDs, add, remove)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSessionService) BulkSetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error) {
+	args := m.Called(ctx, projectID, sessionIDs, archived)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSessionService) ListPendingUploads(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error) {
+	args := m.Called(ctx, projectID, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PendingAssetUpload), args.Error(1)
+}
+
 func setupSessionRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -140,6 +177,7 @@ func getMockSessionCoreClient() *httpclient.CoreClient {
 
 func TestSessionHandler_GetSessions(t *testing.T) {
 	projectID := uuid.New()
+	learningSpaceID := uuid.New()
 
 	tests := []struct {
 		name           string
@@ -260,6 +298,102 @@ func TestSessionHandler_GetSessions(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:        "sort by last message time with activity filters",
+			queryParams: `?sort_by=last_message_at&time_desc=true&has_messages=true&min_messages=2&max_messages=50&task_status=running`,
+			setup: func(svc *MockSessionService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListSessionsInput) bool {
+					return in.SortBy == "last_message_at" && in.TimeDesc &&
+						in.HasMessages != nil && *in.HasMessages &&
+						in.MinMessages != nil && *in.MinMessages == 2 &&
+						in.MaxMessages != nil && *in.MaxMessages == 50 &&
+						in.TaskStatus == "running"
+				})).Return(&service.ListSessionsOutput{Items: []model.Session{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "time range and learning space filters",
+			queryParams: `?created_after=2025-01-01T00:00:00Z&updated_before=2025-02-01T00:00:00Z&learning_space_id=` + learningSpaceID.String() + `&in_learning_space=true`,
+			setup: func(svc *MockSessionService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListSessionsInput) bool {
+					return in.CreatedAfter != nil && in.CreatedAfter.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+						in.CreatedBefore == nil &&
+						in.UpdatedBefore != nil && in.UpdatedBefore.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) &&
+						in.LearningSpaceID != nil && *in.LearningSpaceID == learningSpaceID &&
+						in.InLearningSpace != nil && *in.InLearningSpace
+				})).Return(&service.ListSessionsOutput{Items: []model.Session{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "config conditions - valid",
+			queryParams: `?config_conditions=[{"key":"priority","op":"gte","value":3},{"key":"agent","op":"exists"}]`,
+			setup: func(svc *MockSessionService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListSessionsInput) bool {
+					return len(in.ConfigConditions) == 2 &&
+						in.ConfigConditions[0].Key == "priority" && in.ConfigConditions[0].Op == "gte" && in.ConfigConditions[0].Value == float64(3) &&
+						in.ConfigConditions[1].Key == "agent" && in.ConfigConditions[1].Op == "exists"
+				})).Return(&service.ListSessionsOutput{Items: []model.Session{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "config conditions - unknown op returns 400",
+			queryParams:    `?config_conditions=[{"key":"priority","op":"between","value":3}]`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "config conditions - comparison without value returns 400",
+			queryParams:    `?config_conditions=[{"key":"priority","op":"gt"}]`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid sort_by returns 400",
+			queryParams:    `?sort_by=name`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "cursor issued for another sort_by returns 400",
+			queryParams: `?sort_by=updated_at&cursor=abc`,
+			setup: func(svc *MockSessionService) {
+				svc.On("List", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: cursor was issued for sort_by=created_at", service.ErrInvalidCursor))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid created_after returns 400",
+			queryParams:    `?created_after=yesterday`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid learning_space_id returns 400",
+			queryParams:    `?learning_space_id=not-a-uuid`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "tags, title and archived filters",
+			queryParams: `?tags=travel,%20draft,travel&title_contains=trip&archived=false`,
+			setup: func(svc *MockSessionService) {
+				svc.On("List", mock.Anything, mock.MatchedBy(func(in service.ListSessionsInput) bool {
+					return assert.ObjectsAreEqual([]string{"draft", "travel"}, in.Tags) &&
+						in.TitleContains == "trip" &&
+						in.Archived != nil && !*in.Archived
+				})).Return(&service.ListSessionsOutput{Items: []model.Session{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty tag returns 400",
+			queryParams:    `?tags=travel,,draft`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -267,7 +401,7 @@ func TestSessionHandler_GetSessions(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.GET("/session", func(c *gin.Context) {
 				project := &model.Project{ID: projectID}
@@ -398,7 +532,7 @@ func TestSessionHandler_CreateSession(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.POST("/session", func(c *gin.Context) {
 				// Simulate middleware setting project information
@@ -477,7 +611,7 @@ func TestSessionHandler_DeleteSession(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.DELETE("/session/:session_id", func(c *gin.Context) {
 				project := &model.Project{ID: projectID}
@@ -554,7 +688,7 @@ func TestSessionHandler_UpdateConfigs(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.PUT("/session/:session_id/configs", func(c *gin.Context) {
 				c.Set("project", &model.Project{ID: projectID})
@@ -620,7 +754,7 @@ func TestSessionHandler_GetConfigs(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.GET("/session/:session_id/configs", func(c *gin.Context) {
 				c.Set("project", &model.Project{ID: projectID})
@@ -1968,7 +2102,7 @@ func TestSessionHandler_StoreMessage(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.POST("/session/:session_id/messages", func(c *gin.Context) {
 				project := &model.Project{ID: projectID}
@@ -2359,7 +2493,7 @@ func TestSessionHandler_GetMessages(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.GET("/session/:session_id/messages", func(c *gin.Context) {
 				project := &model.Project{ID: projectID}
@@ -2553,7 +2687,7 @@ func TestSessionHandler_StoreMessage_Multipart(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.POST("/session/:session_id/messages", func(c *gin.Context) {
 				project := &model.Project{ID: projectID}
@@ -2599,7 +2733,7 @@ func TestSessionHandler_StoreMessage_InvalidJSON(t *testing.T) {
 		mockService := &MockSessionService{}
 		// No setup needed as the request should fail before reaching the service
 
-		handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+		handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 		router := setupSessionRouter()
 		router.POST("/session/:session_id/messages", func(c *gin.Context) {
 			project := &model.Project{ID: projectID}
@@ -2661,7 +2795,7 @@ func TestOpenAI_ToolCalls_FieldPreservation(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -2824,7 +2958,7 @@ func TestOpenAIToAnthropic_FieldMapping(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -2956,7 +3090,7 @@ func TestAnthropicToOpenAI_FieldMapping(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -3070,7 +3204,7 @@ func TestToolResult_OpenAIToAnthropic(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -3191,7 +3325,7 @@ func TestToolResult_AnthropicToOpenAI(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -3297,7 +3431,7 @@ func TestAnthropic_CacheControl_Preservation(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -3431,7 +3565,7 @@ func TestMultipleToolCalls_Conversion(t *testing.T) {
 		HasMore: false,
 	}, nil)
 
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 	router := setupSessionRouter()
 
 	router.POST("/session/:session_id/messages", func(c *gin.Context) {
@@ -3710,7 +3844,7 @@ func TestSessionHandler_GetTokenCounts(t *testing.T) {
 			mockService := &MockSessionService{}
 			tt.setup(mockService)
 
-			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 			router := setupSessionRouter()
 			router.GET("/session/:session_id/token_counts", func(c *gin.Context) {
 				c.Set("project", &model.Project{ID: projectID})
@@ -3748,12 +3882,405 @@ func TestSessionHandler_GetTokenCounts(t *testing.T) {
 	}
 }
 
+func TestSessionHandler_GetSessionStats(t *testing.T) {
+	projectID := uuid.New()
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		sessionIDParam string
+		setup          func(*MockSessionService)
+		expectedStatus int
+	}{
+		{
+			name:           "successful stats retrieval",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("GetStats", mock.Anything, projectID, sessionID, mock.Anything).Return(&service.SessionStatsOutput{
+					SessionStats: model.SessionStats{
+						SessionID:       sessionID,
+						ProjectID:       projectID,
+						MessageCount:    3,
+						ToolResultCount: 2,
+						ToolErrorCount:  1,
+					},
+					ToolErrorRate: 0.5,
+					TaskCounts:    map[string]int64{"success": 2},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session ID",
+			sessionIDParam: "invalid-uuid",
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "session not found",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("GetStats", mock.Anything, projectID, sessionID, mock.Anything).Return(nil, service.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "service layer error",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("GetStats", mock.Anything, projectID, sessionID, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionService{}
+			tt.setup(mockService)
+
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
+			router := setupSessionRouter()
+			router.GET("/session/:session_id/stats", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.GetSessionStats(c)
+			})
+
+			req := httptest.NewRequest("GET", "/session/"+tt.sessionIDParam+"/stats", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := sonic.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+
+				data, ok := response["data"].(map[string]interface{})
+				require.True(t, ok, "Should have data field")
+				assert.Equal(t, float64(3), data["message_count"])
+				assert.Equal(t, 0.5, data["tool_error_rate"])
+				assert.Equal(t, map[string]interface{}{"success": float64(2)}, data["task_counts"])
+			}
+		})
+	}
+}
+
+func TestSessionHandler_GetPendingUploads(t *testing.T) {
+	projectID := uuid.New()
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		sessionIDParam string
+		setup          func(*MockSessionService)
+		expectedStatus int
+	}{
+		{
+			name:           "lists pending uploads",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("ListPendingUploads", mock.Anything, projectID, sessionID).Return([]*model.PendingAssetUpload{{
+					SessionID: sessionID,
+					S3Key:     "parts/p/abc.json",
+					Content:   []byte("sealed"),
+					Status:    model.PendingAssetUploadFailed,
+					Attempts:  20,
+					LastError: "connection refused",
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session ID",
+			sessionIDParam: "invalid-uuid",
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "session not found",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("ListPendingUploads", mock.Anything, projectID, sessionID).Return(nil, service.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "service layer error",
+			sessionIDParam: sessionID.String(),
+			setup: func(svc *MockSessionService) {
+				svc.On("ListPendingUploads", mock.Anything, projectID, sessionID).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionService{}
+			tt.setup(mockService)
+
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
+			router := setupSessionRouter()
+			router.GET("/session/:session_id/pending_uploads", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.GetPendingUploads(c)
+			})
+
+			req := httptest.NewRequest("GET", "/session/"+tt.sessionIDParam+"/pending_uploads", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := sonic.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+
+				data, ok := response["data"].([]interface{})
+				require.True(t, ok, "Should have data field")
+				require.Len(t, data, 1)
+				upload := data[0].(map[string]interface{})
+				assert.Equal(t, "parts/p/abc.json", upload["s3_key"])
+				assert.Equal(t, "failed", upload["status"])
+				assert.Equal(t, float64(20), upload["attempts"])
+				assert.NotContains(t, upload, "content")
+			}
+		})
+	}
+}
+
+func TestSessionHandler_UpdateSession(t *testing.T) {
+	projectID := uuid.New()
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		sessionIDParam string
+		body           string
+		setup          func(*MockSessionService)
+		expectedStatus int
+	}{
+		{
+			name:           "update title, tags and archived",
+			sessionIDParam: sessionID.String(),
+			body:           `{"title":"  Trip planning ","tags":["travel"],"archived":true}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("UpdateInfo", mock.Anything, projectID, sessionID, mock.MatchedBy(func(in service.UpdateSessionInfoInput) bool {
+					return in.Title != nil && *in.Title == "Trip planning" &&
+						in.Tags != nil && assert.ObjectsAreEqual([]string{"travel"}, *in.Tags) &&
+						in.Archived != nil && *in.Archived
+				})).Return(&model.Session{ID: sessionID, ProjectID: projectID, Title: "Trip planning", Archived: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session ID",
+			sessionIDParam: "invalid-uuid",
+			body:           `{"title":"x"}`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid tags",
+			sessionIDParam: sessionID.String(),
+			body:           `{"tags":[""]}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("UpdateInfo", mock.Anything, projectID, sessionID, mock.Anything).Return(nil, fmt.Errorf("%w: tag must not be empty", service.ErrInvalidTags))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "session not found",
+			sessionIDParam: sessionID.String(),
+			body:           `{"archived":false}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("UpdateInfo", mock.Anything, projectID, sessionID, mock.Anything).Return(nil, service.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionService{}
+			tt.setup(mockService)
+
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
+			router := setupSessionRouter()
+			router.PATCH("/session/:session_id", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.UpdateSession(c)
+			})
+
+			req := httptest.NewRequest("PATCH", "/session/"+tt.sessionIDParam, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionHandler_BulkTagSessions(t *testing.T) {
+	projectID := uuid.New()
+	id1, id2 := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockSessionService)
+		expectedStatus int
+		expectedCount  float64
+	}{
+		{
+			name: "add and remove tags",
+			body: `{"session_ids":["` + id1.String() + `","` + id2.String() + `"],"add":["travel"],"remove":["draft"]}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("BulkUpdateTags", mock.Anything, projectID, []uuid.UUID{id1, id2}, []string{"travel"}, []string{"draft"}).Return(int64(2), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "missing session_ids",
+			body:           `{"add":["travel"]}`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "neither add nor remove",
+			body:           `{"session_ids":["` + id1.String() + `"]}`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid tags",
+			body: `{"session_ids":["` + id1.String() + `"],"add":[" "]}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("BulkUpdateTags", mock.Anything, projectID, []uuid.UUID{id1}, []string{" "}, []string(nil)).Return(int64(0), fmt.Errorf("%w: tag must not be empty", service.ErrInvalidTags))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionService{}
+			tt.setup(mockService)
+
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
+			router := setupSessionRouter()
+			router.POST("/session/tags", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.BulkTagSessions(c)
+			})
+
+			req := httptest.NewRequest("POST", "/session/tags", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, sonic.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedCount, response["data"].(map[string]interface{})["updated"])
+			}
+		})
+	}
+}
+
+func TestSessionHandler_BulkArchiveSessions(t *testing.T) {
+	projectID := uuid.New()
+	id1 := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockSessionService)
+		expectedStatus int
+	}{
+		{
+			name: "archive sessions",
+			body: `{"session_ids":["` + id1.String() + `"],"archived":true}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("BulkSetArchived", mock.Anything, projectID, []uuid.UUID{id1}, true).Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unarchive sessions",
+			body: `{"session_ids":["` + id1.String() + `"],"archived":false}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("BulkSetArchived", mock.Anything, projectID, []uuid.UUID{id1}, false).Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing archived",
+			body:           `{"session_ids":["` + id1.String() + `"]}`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid session id",
+			body:           `{"session_ids":["nope"],"archived":true}`,
+			setup:          func(svc *MockSessionService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "service layer error",
+			body: `{"session_ids":["` + id1.String() + `"],"archived":true}`,
+			setup: func(svc *MockSessionService) {
+				svc.On("BulkSetArchived", mock.Anything, projectID, []uuid.UUID{id1}, true).Return(int64(0), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSessionService{}
+			tt.setup(mockService)
+
+			handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
+			router := setupSessionRouter()
+			router.POST("/session/archive", func(c *gin.Context) {
+				c.Set("project", &model.Project{ID: projectID})
+				handler.BulkArchiveSessions(c)
+			})
+
+			req := httptest.NewRequest("POST", "/session/archive", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestSessionHandler_GetSessionObservingStatus_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	projectID := uuid.New()
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	sessionID := "550e8400-e29b-41d4-a716-446655440000"
 	sessionUUID := uuid.MustParse(sessionID)
@@ -3800,7 +4327,7 @@ func TestSessionHandler_GetSessionObservingStatus_EmptySessionID(t *testing.T) {
 
 	projectID := uuid.New()
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -3827,7 +4354,7 @@ func TestSessionHandler_GetSessionObservingStatus_InvalidSessionID(t *testing.T)
 
 	projectID := uuid.New()
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -3854,7 +4381,7 @@ func TestSessionHandler_GetSessionObservingStatus_ServiceError(t *testing.T) {
 
 	projectID := uuid.New()
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	sessionID := "550e8400-e29b-41d4-a716-446655440000"
 	sessionUUID := uuid.MustParse(sessionID)
@@ -3894,7 +4421,7 @@ func TestSessionHandler_PatchConfigs_Success(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	// Mock the service to return updated configs
 	mockService.On("PatchConfigs", mock.Anything, projectID, sessionID, mock.MatchedBy(func(patch map[string]interface{}) bool {
@@ -3938,7 +4465,7 @@ func TestSessionHandler_PatchConfigs_InvalidSessionID(t *testing.T) {
 	projectID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	reqBody := `{"configs": {"key": "value"}}`
 	w := httptest.NewRecorder()
@@ -3964,7 +4491,7 @@ func TestSessionHandler_PatchConfigs_SessionNotFound(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	mockService.On("PatchConfigs", mock.Anything, projectID, sessionID, mock.Anything).
 		Return(nil, errors.New("session not found"))
@@ -3993,7 +4520,7 @@ func TestSessionHandler_PatchConfigs_InvalidRequest(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	// Test with missing required configs field
 	reqBody := `{}`
@@ -4023,7 +4550,7 @@ func TestSessionHandler_CopySession_Success(t *testing.T) {
 	newSessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	expectedOutput := &service.CopySessionOutput{
 		OldSessionID: sessionID,
@@ -4066,7 +4593,7 @@ func TestSessionHandler_CopySession_SessionNotFound(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	mockService.On("CopySession", mock.Anything, service.CopySessionInput{
 		ProjectID: projectID,
@@ -4101,7 +4628,7 @@ func TestSessionHandler_CopySession_SessionTooLarge(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	mockService.On("CopySession", mock.Anything, service.CopySessionInput{
 		ProjectID: projectID,
@@ -4136,7 +4663,7 @@ func TestSessionHandler_CopySession_InvalidUUID(t *testing.T) {
 	invalidSessionID := "invalid-uuid"
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -4166,7 +4693,7 @@ func TestSessionHandler_CopySession_InternalError(t *testing.T) {
 	sessionID := uuid.New()
 
 	mockService := new(MockSessionService)
-	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient())
+	handler := NewSessionHandler(mockService, &MockUserService{}, getMockSessionCoreClient(), nil)
 
 	mockService.On("CopySession", mock.Anything, service.CopySessionInput{
 		ProjectID: projectID,
@@ -4199,7 +4726,7 @@ func TestSessionHandler_DownloadSessionAsset(t *testing.T) {
 
 	t.Run("returns 400 for invalid session_id", func(t *testing.T) {
 		mockService := new(MockSessionService)
-		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient())
+		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient(), nil)
 
 		projectID := uuid.New()
 
@@ -4216,7 +4743,7 @@ func TestSessionHandler_DownloadSessionAsset(t *testing.T) {
 
 	t.Run("returns 404 when session not found", func(t *testing.T) {
 		mockService := new(MockSessionService)
-		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient())
+		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient(), nil)
 
 		projectID := uuid.New()
 		sessionID := uuid.New()
@@ -4239,7 +4766,7 @@ func TestSessionHandler_DownloadSessionAsset(t *testing.T) {
 
 	t.Run("returns 403 when session belongs to different project", func(t *testing.T) {
 		mockService := new(MockSessionService)
-		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient())
+		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient(), nil)
 
 		projectID := uuid.New()
 		otherProjectID := uuid.New()
@@ -4263,7 +4790,7 @@ func TestSessionHandler_DownloadSessionAsset(t *testing.T) {
 
 	t.Run("succeeds with valid session_id and matching project", func(t *testing.T) {
 		mockService := new(MockSessionService)
-		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient())
+		handler := NewSessionHandler(mockService, nil, getMockSessionCoreClient(), nil)
 
 		projectID := uuid.New()
 		sessionID := uuid.New()
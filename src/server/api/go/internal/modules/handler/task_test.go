@@ -58,12 +58,12 @@ func (m *MockSessionRepo) GetDisableTaskTracking(ctx context.Context, sessionID
 	args := m.Called(ctx, sessionID)
 	return args.Bool(0), args.Error(1)
 }
-func (m *MockSessionRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, userIdentifier string, filterByConfigs map[string]interface{}, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error) {
-	args := m.Called(ctx, projectID, userIdentifier, filterByConfigs, afterCreatedAt, afterID, limit, timeDesc)
+func (m *MockSessionRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, filter repo.SessionListFilter, afterSortValue time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error) {
+	args := m.Called(ctx, projectID, filter, afterSortValue, afterID, limit, timeDesc)
 	return args.Get(0).([]model.Session), args.Error(1)
 }
-func (m *MockSessionRepo) CreateMessageWithAssets(ctx context.Context, msg *model.Message) error {
-	return m.Called(ctx, msg).Error(0)
+func (m *MockSessionRepo) CreateMessageWithAssets(ctx context.Context, msg *model.Message, stats *model.SessionStats) error {
+	return m.Called(ctx, msg, stats).Error(0)
 }
 func (m *MockSessionRepo) ListBySessionWithCursor(ctx context.Context, sessionID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Message, error) {
 	args := m.Called(ctx, sessionID, afterCreatedAt, afterID, limit, timeDesc)
@@ -109,6 +109,38 @@ func (m *MockSessionRepo) HasFailedMessages(ctx context.Context, sessionID uuid.
 	args := m.Called(ctx, sessionID)
 	return args.Bool(0), args.Error(1)
 }
+func (m *MockSessionRepo) GetStats(ctx context.Context, sessionID uuid.UUID) (*model.SessionStats, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionStats), args.Error(1)
+}
+func (m *MockSessionRepo) BackfillStats(ctx context.Context, sessionID uuid.UUID, compute func(msgs []model.Message) (*model.SessionStats, error)) (*model.SessionStats, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionStats), args.Error(1)
+}
+func (m *MockSessionRepo) CountTasksByStatus(ctx context.Context, sessionID uuid.UUID) (map[string]int64, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+func (m *MockSessionRepo) UpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error) {
+	args := m.Called(ctx, projectID, sessionIDs, add, remove)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockSessionRepo) SetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error) {
+	args := m.Called(ctx, projectID, sessionIDs, archived)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockSessionRepo) SetTitleIfEmpty(ctx context.Context, sessionID uuid.UUID, title string) error {
+	return m.Called(ctx, sessionID, title).Error(0)
+}
+func (m *MockSessionRepo) UpdateInfo(ctx context.Context, sessionID uuid.UUID, update repo.SessionInfoUpdate) error {
+	return m.Called(ctx, sessionID, update).Error(0)
+}
 
 func TestTaskHandler_GetTasks(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/middleware"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/serializer"
+	"github.com/memodb-io/Acontext/internal/modules/service"
+)
+
+// uploadOffsetHeader carries an upload's offset: where an append starts, and in responses,
+// how many bytes have been stored.
+const uploadOffsetHeader = "Upload-Offset"
+
+type UploadHandler struct {
+	svc service.UploadService
+}
+
+func NewUploadHandler(svc service.UploadService) *UploadHandler {
+	return &UploadHandler{svc: svc}
+}
+
+type CreateUploadReq struct {
+	Target   string `json:"target" binding:"required,oneof=disk message" example:"disk" enums:"disk,message"`
+	Filename string `json:"filename" binding:"required" example:"dataset.parquet"`
+	Size     *int64 `json:"size" binding:"required,min=0" example:"104857600"`
+}
+
+// uploadErr writes the response for an error returned by the upload service.
+func uploadErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, serializer.Err(http.StatusNotFound, "upload not found", nil))
+	case errors.Is(err, service.ErrUploadOffsetMismatch):
+		c.JSON(http.StatusConflict, serializer.Err(http.StatusConflict, "upload offset does not match", err))
+	case errors.Is(err, service.ErrUploadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, serializer.ParamErr("", err))
+	case errors.Is(err, service.ErrInvalidUpload), errors.Is(err, service.ErrUploadIncomplete):
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+	default:
+		c.JSON(http.StatusInternalServerError, serializer.DBErr("", err))
+	}
+}
+
+// uploadFromParams reads the project and the upload_id path parameter, writing an error
+// response and returning false when either is missing or invalid.
+func uploadFromParams(c *gin.Context) (*model.Project, uuid.UUID, bool) {
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return nil, uuid.Nil, false
+	}
+	uploadID, err := uuid.Parse(c.Param("upload_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid upload_id", err))
+		return nil, uuid.Nil, false
+	}
+	return project, uploadID, true
+}
+
+// CreateUpload godoc
+//
+//	@Summary		Create resumable upload
+//	@Description	Start a resumable upload of a file of the given size. Send its bytes in order with PATCH /upload/{upload_id}, which may be repeated or resumed after a failure, then complete it with POST /upload/{upload_id}/complete. A completed upload is attached by passing its ID as upload_id to POST /disk/{disk_id}/artifact (target "disk") or in a message part (target "message").
+//	@Tags			upload
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	handler.CreateUploadReq	true	"CreateUpload payload"
+//	@Security		BearerAuth
+//	@Success		201	{object}	serializer.Response{data=model.Upload}
+//	@Failure		400	{object}	serializer.Response	"Invalid request"
+//	@Failure		413	{object}	serializer.Response	"File size exceeds maximum allowed size"
+//	@Router			/upload [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	req := CreateUploadReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", err))
+		return
+	}
+
+	project, ok := c.MustGet("project").(*model.Project)
+	if !ok {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("", errors.New("project not found")))
+		return
+	}
+
+	upload, err := h.svc.Create(c.Request.Context(), service.CreateUploadInput{
+		ProjectID: project.ID,
+		Target:    req.Target,
+		Filename:  req.Filename,
+		Size:      *req.Size,
+		UserKEK:   middleware.GetUserKEKIfEncrypted(c),
+	})
+	if err != nil {
+		uploadErr(c, err)
+		return
+	}
+
+	c.Header(uploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	c.JSON(http.StatusCreated, serializer.Response{Data: upload})
+}
+
+// GetUpload godoc
+//
+//	@Summary		Get resumable upload
+//	@Description	Get the status of a resumable upload. Its offset is where the next append must start, so a client resumes an interrupted upload from it.
+//	@Tags			upload
+//	@Accept			json
+//	@Produce		json
+//	@Param			upload_id	path	string	true	"Upload ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Upload}
+//	@Header			200	{integer}	Upload-Offset	"Bytes stored so far"
+//	@Failure		404	{object}	serializer.Response	"Upload not found or expired"
+//	@Router			/upload/{upload_id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	project, uploadID, ok := uploadFromParams(c)
+	if !ok {
+		return
+	}
+
+	upload, err := h.svc.Get(c.Request.Context(), project.ID, uploadID)
+	if err != nil {
+		uploadErr(c, err)
+		return
+	}
+
+	c.Header(uploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	c.JSON(http.StatusOK, serializer.Response{Data: upload})
+}
+
+// AppendUpload godoc
+//
+//	@Summary		Append to resumable upload
+//	@Description	Append the request body to a resumable upload, starting at the offset given in the Upload-Offset header, which must be the upload's current offset. The body is stored in whole parts of the upload's part_size; bytes after the last whole part are discarded unless they end the file, so a body of any length may be sent and the returned offset says where to continue. An append cut short by a network failure keeps every part it completed.
+//	@Tags			upload
+//	@Accept			octet-stream
+//	@Produce		json
+//	@Param			upload_id		path	string	true	"Upload ID"	format(uuid)
+//	@Param			Upload-Offset	header	integer	true	"Offset the body starts at"
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Upload}
+//	@Header			200	{integer}	Upload-Offset	"Bytes stored so far"
+//	@Failure		400	{object}	serializer.Response	"Invalid request, or the upload is already completed"
+//	@Failure		404	{object}	serializer.Response	"Upload not found or expired"
+//	@Failure		409	{object}	serializer.Response	"Upload-Offset is not the upload's offset"
+//	@Router			/upload/{upload_id} [patch]
+func (h *UploadHandler) AppendUpload(c *gin.Context) {
+	project, uploadID, ok := uploadFromParams(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, serializer.ParamErr("invalid Upload-Offset header", err))
+		return
+	}
+
+	upload, err := h.svc.Append(c.Request.Context(), service.AppendUploadInput{
+		ProjectID: project.ID,
+		UploadID:  uploadID,
+		Offset:    offset,
+		Body:      c.Request.Body,
+		UserKEK:   middleware.GetUserKEKIfEncrypted(c),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUploadOffsetMismatch) {
+			// Tell the client where to resume
+			if current, getErr := h.svc.Get(c.Request.Context(), project.ID, uploadID); getErr == nil {
+				c.Header(uploadOffsetHeader, strconv.FormatInt(current.Offset, 10))
+			}
+		}
+		uploadErr(c, err)
+		return
+	}
+
+	c.Header(uploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	c.JSON(http.StatusOK, serializer.Response{Data: upload})
+}
+
+// CompleteUpload godoc
+//
+//	@Summary		Complete resumable upload
+//	@Description	Assemble a resumable upload whose bytes have all been appended. The file is stored content-addressed like any other upload, and the upload can then be attached by its ID until it expires; if nothing attached it by then, the file is deleted. Completing a completed upload returns it unchanged, so the request is safe to retry.
+//	@Tags			upload
+//	@Accept			json
+//	@Produce		json
+//	@Param			upload_id	path	string	true	"Upload ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{data=model.Upload}
+//	@Failure		400	{object}	serializer.Response	"Not all bytes of the file were received"
+//	@Failure		404	{object}	serializer.Response	"Upload not found or expired"
+//	@Router			/upload/{upload_id}/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	project, uploadID, ok := uploadFromParams(c)
+	if !ok {
+		return
+	}
+
+	upload, err := h.svc.Complete(c.Request.Context(), project.ID, uploadID, middleware.GetUserKEKIfEncrypted(c))
+	if err != nil {
+		uploadErr(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{Data: upload})
+}
+
+// AbortUpload godoc
+//
+//	@Summary		Abort resumable upload
+//	@Description	Discard a resumable upload and the bytes stored for it. Files already attached from a completed upload are not affected.
+//	@Tags			upload
+//	@Accept			json
+//	@Produce		json
+//	@Param			upload_id	path	string	true	"Upload ID"	format(uuid)
+//	@Security		BearerAuth
+//	@Success		200	{object}	serializer.Response{}
+//	@Failure		404	{object}	serializer.Response	"Upload not found or expired"
+//	@Router			/upload/{upload_id} [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	project, uploadID, ok := uploadFromParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Abort(c.Request.Context(), project.ID, uploadID); err != nil {
+		uploadErr(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, serializer.Response{})
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,6 +40,10 @@ type Artifact struct {
 	Meta      datatypes.JSONMap         `gorm:"type:jsonb" swaggertype:"object" json:"meta"`
 	AssetMeta datatypes.JSONType[Asset] `gorm:"type:jsonb;not null" swaggertype:"-" json:"-"`
 
+	// Version is the revision number of the current content, starting at 1 and
+	// incremented every time the artifact is overwritten or restored
+	Version int `gorm:"not null;default:1" json:"version"`
+
 	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
@@ -52,3 +57,80 @@ func (Artifact) TableName() string { return "artifacts" }
 func (Artifact) GetReservedKeys() []string {
 	return []string{ArtifactInfoKey}
 }
+
+// ArtifactVersion is a superseded revision of an artifact. It is archived when the artifact
+// is overwritten so the previous content can be inspected or restored, and keeps a reference
+// on its asset until it is pruned.
+type ArtifactVersion struct {
+	ID        uuid.UUID                 `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"-"`
+	DiskID    uuid.UUID                 `gorm:"type:uuid;not null;uniqueIndex:idx_artifact_version,priority:1" json:"disk_id"`
+	Path      string                    `gorm:"type:text;not null;uniqueIndex:idx_artifact_version,priority:2" json:"path"`
+	Filename  string                    `gorm:"type:text;not null;uniqueIndex:idx_artifact_version,priority:3" json:"filename"`
+	Version   int                       `gorm:"not null;uniqueIndex:idx_artifact_version,priority:4" json:"version"`
+	Meta      datatypes.JSONMap         `gorm:"type:jsonb" swaggertype:"object" json:"meta"`
+	AssetMeta datatypes.JSONType[Asset] `gorm:"type:jsonb;not null" swaggertype:"-" json:"-"`
+
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`                                           // When this revision was written
+	ArchivedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"archived_at"` // When this revision was superseded
+
+	// ArtifactVersion <-> Disk
+	Disk *Disk `gorm:"foreignKey:DiskID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (ArtifactVersion) TableName() string { return "artifact_versions" }
+
+// DiskSnapshot is an immutable, point-in-time manifest of the artifacts on a disk. Its entries
+// hold references on their assets, so the content stays available until the snapshot is deleted.
+type DiskSnapshot struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProjectID     uuid.UUID `gorm:"type:uuid;not null;index" json:"project_id"`
+	DiskID        uuid.UUID `gorm:"type:uuid;not null;index" json:"disk_id"`
+	Name          string    `gorm:"type:text;not null;default:''" json:"name"`
+	ArtifactCount int       `gorm:"not null;default:0" json:"artifact_count"` // Number of artifacts captured
+	SizeB         int64     `gorm:"not null;default:0" json:"size_b"`         // Total size of the captured artifacts in bytes
+
+	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// DiskSnapshot <-> Disk
+	Disk *Disk `gorm:"foreignKey:DiskID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (DiskSnapshot) TableName() string { return "disk_snapshots" }
+
+// DiskSnapshotEntry is one artifact captured by a DiskSnapshot.
+type DiskSnapshotEntry struct {
+	ID         uuid.UUID                 `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"-"`
+	SnapshotID uuid.UUID                 `gorm:"type:uuid;not null;uniqueIndex:idx_disk_snapshot_entry,priority:1" json:"snapshot_id"`
+	Path       string                    `gorm:"type:text;not null;uniqueIndex:idx_disk_snapshot_entry,priority:2" json:"path"`
+	Filename   string                    `gorm:"type:text;not null;uniqueIndex:idx_disk_snapshot_entry,priority:3" json:"filename"`
+	SHA256     string                    `gorm:"type:varchar(64);not null;default:''" json:"sha256"`
+	Meta       datatypes.JSONMap         `gorm:"type:jsonb" swaggertype:"object" json:"meta"`
+	AssetMeta  datatypes.JSONType[Asset] `gorm:"type:jsonb;not null" swaggertype:"-" json:"-"`
+
+	// DiskSnapshotEntry <-> DiskSnapshot
+	Snapshot *DiskSnapshot `gorm:"foreignKey:SnapshotID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (DiskSnapshotEntry) TableName() string { return "disk_snapshot_entries" }
+
+// ArtifactLease is an advisory, time-limited lock on a file path, or on every path under a
+// directory when Path ends with '/'. While it is held, artifact writes from other owners are
+// rejected. This table is only used when Redis is not configured or cannot be reached.
+type ArtifactLease struct {
+	DiskID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"disk_id"`
+	Path      string    `gorm:"type:text;primaryKey" json:"path"`
+	Owner     string    `gorm:"type:text;not null" json:"owner"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+
+	// ArtifactLease <-> Disk
+	Disk *Disk `gorm:"foreignKey:DiskID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (ArtifactLease) TableName() string { return "artifact_leases" }
+
+// Covers reports whether the lease applies to p, a file path or a directory ending with '/'.
+// Leases overlap when either covers the other, so locking a directory conflicts with leases
+// on files inside it and the other way round.
+func (l ArtifactLease) Covers(p string) bool {
+	return l.Path == p || (strings.HasSuffix(l.Path, "/") && strings.HasPrefix(p, l.Path))
+}
@@ -56,7 +56,7 @@ type Asset struct {
 	SHA256  string `json:"sha256"`
 	MIME    string `json:"mime"`
 	SizeB   int64  `json:"size_b"`
-	Content string `json:"content,omitempty"` // Text content for text-searchable files (text/*, application/json, application/x-*)
+	Content string `json:"content,omitempty"` // Text content of text files, or the text extracted from documents
 }
 
 // IsOrphaned returns true if this asset has no references
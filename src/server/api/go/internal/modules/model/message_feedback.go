@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Thumbs values for MessageFeedback.Thumbs
+const (
+	FeedbackThumbsUp   = "up"
+	FeedbackThumbsDown = "down"
+)
+
+// MessageFeedback is a single rating submitted for a message. Every submission is its own row,
+// so concurrent raters never overwrite each other.
+type MessageFeedback struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProjectID uuid.UUID  `gorm:"type:uuid;not null;index:idx_message_feedback_project_created,priority:1" json:"project_id"`
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index:idx_message_feedback_session_created,priority:1" json:"session_id"`
+	MessageID uuid.UUID  `gorm:"type:uuid;not null;index" json:"message_id"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+
+	Thumbs  *string                     `gorm:"type:text" json:"thumbs"`
+	Score   *float64                    `json:"score"`
+	Comment string                      `gorm:"type:text;not null;default:''" json:"comment"`
+	Labels  datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]';index:idx_message_feedback_labels,type:gin" swaggertype:"array,string" json:"labels"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP;index:idx_message_feedback_project_created,priority:2,sort:desc;index:idx_message_feedback_session_created,priority:2,sort:desc" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// MessageFeedback <-> Message
+	Message *Message `gorm:"foreignKey:MessageID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+
+	// MessageFeedback <-> Session
+	Session *Session `gorm:"foreignKey:SessionID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+
+	// MessageFeedback <-> User
+	User *User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE;" json:"-"`
+
+	// MessageFeedback <-> Project
+	Project *Project `gorm:"foreignKey:ProjectID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (MessageFeedback) TableName() string { return "message_feedback" }
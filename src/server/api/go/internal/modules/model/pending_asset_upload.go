@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Pending asset upload statuses for PendingAssetUpload.Status
+const (
+	PendingAssetUploadPending = "pending" // waiting for its next attempt
+	PendingAssetUploadFailed  = "failed"  // gave up after the maximum number of attempts
+)
+
+// PendingAssetUpload is an asset of a stored message that is not in the blob store yet.
+//
+// Message assets are uploaded after the message is stored, so their content is first kept
+// here, in the compressed and encrypted form it is stored in, until an upload succeeds and
+// the row is deleted. Failed attempts are retried with backoff; reads of the asset fall back
+// to this content meanwhile. Rows are keyed by S3Key: content-addressed keys make every
+// upload of the same key the same object.
+type PendingAssetUpload struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProjectID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"session_id"` // session of the message that first stored it
+	S3Key     string    `gorm:"type:text;not null;uniqueIndex" json:"s3_key"`
+
+	AssetMeta datatypes.JSONType[Asset]             `gorm:"type:jsonb;not null" swaggertype:"object" json:"asset_meta"`
+	Content   []byte                                `gorm:"type:bytea;not null" json:"-"`
+	Metadata  datatypes.JSONType[map[string]string] `gorm:"type:jsonb" json:"-"` // object metadata, incl. encryption and compression fields
+
+	Status        string    `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// PendingAssetUpload <-> Project
+	Project *Project `gorm:"foreignKey:ProjectID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (PendingAssetUpload) TableName() string { return "pending_asset_uploads" }
@@ -9,14 +9,21 @@ import (
 
 type Session struct {
 	ID                  uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	ProjectID           uuid.UUID         `gorm:"type:uuid;not null;index" json:"project_id"`
+	ProjectID           uuid.UUID         `gorm:"type:uuid;not null;index;index:idx_sessions_project_archived,priority:1" json:"project_id"`
 	UserID              *uuid.UUID        `gorm:"type:uuid;index" json:"user_id"`
 	DisableTaskTracking bool              `gorm:"not null;default:false" json:"disable_task_tracking"`
 	Configs             datatypes.JSONMap `gorm:"type:jsonb;index:idx_sessions_configs,type:gin" swaggertype:"object" json:"configs"`
 
+	Title    string                      `gorm:"type:text;not null;default:''" json:"title"`
+	Tags     datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]';index:idx_sessions_tags,type:gin" swaggertype:"array,string" json:"tags"`
+	Archived bool                        `gorm:"not null;default:false;index:idx_sessions_project_archived,priority:2" json:"archived"`
+
 	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
+	// LastMessageAt is computed from messages when listing sessions; it is not a stored column.
+	LastMessageAt *time.Time `gorm:"->;-:migration" json:"last_message_at,omitempty"`
+
 	// Session <-> Project
 	Project *Project `gorm:"foreignKey:ProjectID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
 
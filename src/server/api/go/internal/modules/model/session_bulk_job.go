@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Bulk session actions accepted by SessionBulkJob.Action
+const (
+	BulkActionDelete       = "delete"
+	BulkActionArchive      = "archive"
+	BulkActionPatchConfigs = "patch_configs"
+	BulkActionCopy         = "copy"
+	BulkActionLearn        = "learn"
+)
+
+// Bulk job statuses for SessionBulkJob.Status
+const (
+	BulkJobStatusPending   = "pending"
+	BulkJobStatusRunning   = "running"
+	BulkJobStatusCompleted = "completed"
+	BulkJobStatusFailed    = "failed"
+)
+
+// Per-item statuses for BulkItemResult.Status
+const (
+	BulkItemStatusPending   = "pending"
+	BulkItemStatusSucceeded = "succeeded"
+	BulkItemStatusFailed    = "failed"
+)
+
+// BulkItemResult is the outcome of a bulk action on a single session.
+type BulkItemResult struct {
+	SessionID    uuid.UUID  `json:"session_id"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	NewSessionID *uuid.UUID `json:"new_session_id,omitempty"` // set by the copy action
+}
+
+// SessionBulkJob tracks an asynchronous bulk operation over sessions of a project.
+type SessionBulkJob struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProjectID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Action    string    `gorm:"type:text;not null" json:"action"`
+	Status    string    `gorm:"type:text;not null;default:'pending'" json:"status"`
+
+	// Params holds action arguments, e.g. configs for patch_configs or learning_space_id for learn
+	Params datatypes.JSONMap `gorm:"type:jsonb" swaggertype:"object" json:"params"`
+
+	Total     int `gorm:"not null;default:0" json:"total"`
+	Succeeded int `gorm:"not null;default:0" json:"succeeded"`
+	Failed    int `gorm:"not null;default:0" json:"failed"`
+
+	Results datatypes.JSONSlice[BulkItemResult] `gorm:"type:jsonb" json:"results"`
+	Error   string                              `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+
+	// HeartbeatAt is refreshed by the worker running the job; a pending or running job whose
+	// heartbeat is too old has lost its worker
+	HeartbeatAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"-"`
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// SessionBulkJob <-> Project
+	Project *Project `gorm:"foreignKey:ProjectID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (SessionBulkJob) TableName() string { return "session_bulk_jobs" }
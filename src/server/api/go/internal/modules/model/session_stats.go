@@ -0,0 +1,126 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// SessionStats holds per-session aggregates that are maintained incrementally
+// as messages are stored, so reading them never requires loading parts blobs.
+type SessionStats struct {
+	SessionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"session_id"`
+	ProjectID uuid.UUID `gorm:"type:uuid;not null;index" json:"project_id"`
+
+	MessageCount   int64                                `gorm:"not null;default:0" json:"message_count"`
+	RoleCounts     datatypes.JSONType[map[string]int64] `gorm:"type:jsonb;not null;default:'{}'" swaggertype:"object" json:"role_counts"`
+	PartTypeCounts datatypes.JSONType[map[string]int64] `gorm:"type:jsonb;not null;default:'{}'" swaggertype:"object" json:"part_type_counts"`
+
+	// ToolCallCounts maps tool name to the number of tool-call parts invoking it
+	ToolCallCounts  datatypes.JSONType[map[string]int64] `gorm:"type:jsonb;not null;default:'{}'" swaggertype:"object" json:"tool_call_counts"`
+	ToolResultCount int64                                `gorm:"not null;default:0" json:"tool_result_count"`
+	ToolErrorCount  int64                                `gorm:"not null;default:0" json:"tool_error_count"`
+
+	TotalTokens int64                                `gorm:"not null;default:0" json:"total_tokens"`
+	RoleTokens  datatypes.JSONType[map[string]int64] `gorm:"type:jsonb;not null;default:'{}'" swaggertype:"object" json:"role_tokens"`
+
+	// AssetBytes is the total size of files attached to message parts
+	AssetBytes int64 `gorm:"not null;default:0" json:"asset_bytes"`
+
+	FirstMessageAt *time.Time `json:"first_message_at"`
+	LastMessageAt  *time.Time `json:"last_message_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"-"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// SessionStats <-> Session
+	Session *Session `gorm:"foreignKey:SessionID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;" json:"-"`
+}
+
+func (SessionStats) TableName() string { return "session_stats" }
+
+// NewMessageStats builds the stats contribution of a single message.
+// tokens is the token count of the message's text and tool-call content.
+func NewMessageStats(sessionID, projectID uuid.UUID, role string, parts []Part, tokens int, at time.Time) SessionStats {
+	partTypes := make(map[string]int64)
+	toolCalls := make(map[string]int64)
+	var toolResults, toolErrors, assetBytes int64
+
+	for _, p := range parts {
+		partTypes[p.Type]++
+		switch p.Type {
+		case PartTypeToolCall:
+			name := p.Name()
+			if name == "" {
+				name = "unknown"
+			}
+			toolCalls[name]++
+		case PartTypeToolResult:
+			toolResults++
+			if p.IsError() {
+				toolErrors++
+			}
+		}
+		if p.Asset != nil {
+			assetBytes += p.Asset.SizeB
+		}
+	}
+
+	return SessionStats{
+		SessionID:       sessionID,
+		ProjectID:       projectID,
+		MessageCount:    1,
+		RoleCounts:      datatypes.NewJSONType(map[string]int64{role: 1}),
+		PartTypeCounts:  datatypes.NewJSONType(partTypes),
+		ToolCallCounts:  datatypes.NewJSONType(toolCalls),
+		ToolResultCount: toolResults,
+		ToolErrorCount:  toolErrors,
+		TotalTokens:     int64(tokens),
+		RoleTokens:      datatypes.NewJSONType(map[string]int64{role: int64(tokens)}),
+		AssetBytes:      assetBytes,
+		FirstMessageAt:  &at,
+		LastMessageAt:   &at,
+	}
+}
+
+// Add merges delta into s.
+func (s *SessionStats) Add(delta SessionStats) {
+	s.MessageCount += delta.MessageCount
+	s.RoleCounts = addCounts(s.RoleCounts, delta.RoleCounts)
+	s.PartTypeCounts = addCounts(s.PartTypeCounts, delta.PartTypeCounts)
+	s.ToolCallCounts = addCounts(s.ToolCallCounts, delta.ToolCallCounts)
+	s.ToolResultCount += delta.ToolResultCount
+	s.ToolErrorCount += delta.ToolErrorCount
+	s.TotalTokens += delta.TotalTokens
+	s.RoleTokens = addCounts(s.RoleTokens, delta.RoleTokens)
+	s.AssetBytes += delta.AssetBytes
+
+	if delta.FirstMessageAt != nil && (s.FirstMessageAt == nil || delta.FirstMessageAt.Before(*s.FirstMessageAt)) {
+		t := *delta.FirstMessageAt
+		s.FirstMessageAt = &t
+	}
+	if delta.LastMessageAt != nil && (s.LastMessageAt == nil || delta.LastMessageAt.After(*s.LastMessageAt)) {
+		t := *delta.LastMessageAt
+		s.LastMessageAt = &t
+	}
+}
+
+// ToolErrorRate returns the fraction of tool results flagged is_error, or 0 when there are none.
+func (s *SessionStats) ToolErrorRate() float64 {
+	if s.ToolResultCount == 0 {
+		return 0
+	}
+	return float64(s.ToolErrorCount) / float64(s.ToolResultCount)
+}
+
+func addCounts(a, b datatypes.JSONType[map[string]int64]) datatypes.JSONType[map[string]int64] {
+	out := make(map[string]int64)
+	for k, v := range a.Data() {
+		out[k] += v
+	}
+	for k, v := range b.Data() {
+		out[k] += v
+	}
+	return datatypes.NewJSONType(out)
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Upload targets for Upload.Target: what the uploaded file will be attached to
+const (
+	UploadTargetDisk    = "disk"    // an artifact on a disk
+	UploadTargetMessage = "message" // a file part of a session message
+)
+
+// Upload statuses for Upload.Status
+const (
+	UploadStatusUploading = "uploading"
+	UploadStatusCompleted = "completed"
+)
+
+// UploadPart is a part of a multipart upload already stored in the blob store.
+type UploadPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// Upload is a resumable upload of a single file, sent over any number of requests.
+//
+// Bytes are appended in order; every whole part is stored as a part of a multipart upload at
+// StagingKey as soon as it arrives, so Offset only moves in steps of PartSize and a client
+// whose connection drops resumes from Offset. Once all Size bytes are in, completing the
+// upload assembles the object and moves it to its content-addressed key, and the file can be
+// attached by referencing the upload's ID.
+type Upload struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProjectID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Target    string    `gorm:"type:text;not null" json:"target"`
+	Filename  string    `gorm:"type:text;not null" json:"filename"`
+	Status    string    `gorm:"type:text;not null;default:'uploading'" json:"status"`
+
+	Size     int64  `gorm:"not null" json:"size"`                                  // declared file size in bytes
+	Offset   int64  `gorm:"column:upload_offset;not null;default:0" json:"offset"` // bytes received and stored so far
+	PartSize int64  `gorm:"not null" json:"part_size"`                             // appends are stored in parts of this size
+	MIME     string `gorm:"type:text;not null;default:''" json:"mime,omitempty"`   // detected from the first part
+
+	StagingKey    string                          `gorm:"type:text;not null" json:"-"`
+	StoreUploadID string                          `gorm:"type:text;not null;default:''" json:"-"` // blob store multipart upload ID
+	Parts         datatypes.JSONSlice[UploadPart] `gorm:"type:jsonb" json:"-"`
+	// HashState is the marshaled SHA256 state of the bytes received so far
+	HashState []byte `gorm:"type:bytea" json:"-"`
+	// EncMeta holds the envelope-encryption fields of the object for encrypted projects
+	EncMeta datatypes.JSONType[map[string]string] `gorm:"type:jsonb" json:"-"`
+
+	SHA256    string                    `gorm:"type:text;not null;default:''" json:"sha256,omitempty"` // set once completed
+	AssetMeta datatypes.JSONType[Asset] `gorm:"type:jsonb" json:"-"`                                   // set once completed
+
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (Upload) TableName() string { return "uploads" }
@@ -2,26 +2,134 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ArtifactRepo interface {
 	Create(ctx context.Context, projectID uuid.UUID, a *model.Artifact) error
-	DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error
-	Update(ctx context.Context, a *model.Artifact) error
+	Upsert(ctx context.Context, projectID uuid.UUID, a *model.Artifact, policy ArtifactVersionPolicy, cond ArtifactPrecondition) error
+	DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond ArtifactPrecondition) error
+	Update(ctx context.Context, a *model.Artifact, cond ArtifactPrecondition) error
 	GetByPath(ctx context.Context, diskID uuid.UUID, path string, filename string) (*model.Artifact, error)
 	ListByPath(ctx context.Context, diskID uuid.UUID, path string) ([]*model.Artifact, error)
 	GetAllPaths(ctx context.Context, diskID uuid.UUID) ([]string, error)
 	ExistsByPathAndFilename(ctx context.Context, diskID uuid.UUID, path string, filename string, excludeID *uuid.UUID) (bool, error)
-	GrepArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error)
-	GlobArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error)
+	GrepArtifacts(ctx context.Context, diskID uuid.UUID, q ArtifactGrepQuery, limit int) ([]*model.Artifact, error)
+	GlobArtifacts(ctx context.Context, diskID uuid.UUID, q ArtifactGlobQuery, limit int) ([]*model.Artifact, error)
+	ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error)
+	GetVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.ArtifactVersion, error)
+	ListByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]*model.Artifact, error)
+	ListTree(ctx context.Context, diskID uuid.UUID, prefix string, maxDepth int, afterPath string, afterFilename string, limit int) ([]*model.Artifact, error)
+	DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error)
+	SummarizeByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]ArtifactPathUsage, error)
+	Move(ctx context.Context, projectID uuid.UUID, transfers []ArtifactTransfer, overwrite bool, policy ArtifactVersionPolicy) error
+	Copy(ctx context.Context, projectID uuid.UUID, transfers []ArtifactTransfer, overwrite bool, policy ArtifactVersionPolicy) error
 }
 
+// ErrArtifactExists is returned when a move or copy targets an occupied location without overwrite.
+var ErrArtifactExists = errors.New("artifact already exists at destination")
+
+// ErrInvalidPattern is returned when Postgres rejects a grep regular expression.
+var ErrInvalidPattern = errors.New("invalid regular expression")
+
+// ErrPreconditionFailed is returned when a conditional write finds the artifact in another state.
+var ErrPreconditionFailed = errors.New("artifact precondition failed")
+
+// ArtifactPrecondition makes a write conditional on the current content of the target artifact,
+// identified by its asset SHA256. The zero value always matches.
+type ArtifactPrecondition struct {
+	IfMatch     []string // current SHA256 must be listed; "*" matches any existing artifact
+	IfNoneMatch []string // current SHA256 must not be listed; "*" matches only a missing artifact
+}
+
+func (p ArtifactPrecondition) IsZero() bool {
+	return len(p.IfMatch) == 0 && len(p.IfNoneMatch) == 0
+}
+
+// Check returns ErrPreconditionFailed unless cur, nil when the artifact does not exist, satisfies p.
+func (p ArtifactPrecondition) Check(cur *model.Artifact) error {
+	matches := func(tags []string) bool {
+		if cur == nil {
+			return false
+		}
+		sha := cur.AssetMeta.Data().SHA256
+		for _, t := range tags {
+			if t == "*" || t == sha {
+				return true
+			}
+		}
+		return false
+	}
+	if len(p.IfMatch) > 0 && !matches(p.IfMatch) {
+		return fmt.Errorf("%w: If-Match", ErrPreconditionFailed)
+	}
+	if len(p.IfNoneMatch) > 0 && matches(p.IfNoneMatch) {
+		return fmt.Errorf("%w: If-None-Match", ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// checkArtifactPreconditionTx locks the artifact at the location inside tx and checks cond
+// against it, so the write that follows in the same transaction cannot race another writer.
+func checkArtifactPreconditionTx(tx *gorm.DB, diskID uuid.UUID, path string, filename string, cond ArtifactPrecondition) error {
+	if cond.IsZero() {
+		return nil
+	}
+	var cur model.Artifact
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).
+		First(&cur).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return cond.Check(nil)
+	}
+	if err != nil {
+		return err
+	}
+	return cond.Check(&cur)
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "23505"))
+}
+
+// isInvalidRegex reports whether err is a Postgres invalid_regular_expression error.
+func isInvalidRegex(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "invalid regular expression") || strings.Contains(err.Error(), "2201B"))
+}
+
+// ArtifactTransfer pairs an existing artifact with the row it is moved or copied to.
+// Dest carries the target disk, path, filename and meta; the content is taken from Source.
+type ArtifactTransfer struct {
+	Source *model.Artifact
+	Dest   *model.Artifact
+}
+
+// ArtifactVersionPolicy controls how many superseded versions of an artifact are retained.
+type ArtifactVersionPolicy struct {
+	Keep   int           // newest versions kept; older ones are pruned
+	MaxAge time.Duration // versions archived longer ago than this are pruned, 0 disables
+}
+
+// ArtifactPathUsage is the number and total size of the files stored directly in one directory.
+type ArtifactPathUsage struct {
+	Path  string
+	Files int64
+	Bytes int64
+}
+
+// ArtifactDeleteBatchSize bounds how many rows a recursive delete removes per statement.
+const ArtifactDeleteBatchSize = 1000
+
 type artifactRepo struct {
 	db                 *gorm.DB
 	assetReferenceRepo AssetReferenceRepo
@@ -49,11 +157,142 @@ func (r *artifactRepo) Create(ctx context.Context, projectID uuid.UUID, a *model
 	})
 }
 
-func (r *artifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
+// Upsert creates the artifact at its (disk, path, filename). When one already exists, its
+// current revision is archived as an ArtifactVersion and replaced in place, keeping the
+// artifact ID and bumping Version. Versions falling outside policy are pruned afterwards.
+// The write only happens if the artifact currently satisfies cond.
+func (r *artifactRepo) Upsert(ctx context.Context, projectID uuid.UUID, a *model.Artifact, policy ArtifactVersionPolicy, cond ArtifactPrecondition) error {
+	asset := a.AssetMeta.Data()
+
+	var pruned []model.Asset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkArtifactPreconditionTx(tx, a.DiskID, a.Path, a.Filename, cond); err != nil {
+			return err
+		}
+
+		var err error
+		pruned, err = upsertArtifactTx(tx, a, true, policy)
+		if err != nil {
+			// A missing artifact cannot be locked, so a concurrent create can win the race
+			// after the check; for a conditional write that is a failed precondition.
+			if !cond.IsZero() && isUniqueViolation(err) {
+				return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+			}
+			return err
+		}
+
+		// Increment before pruned references are released so restoring a pruned
+		// version never drops its asset to zero references
+		if err := r.assetReferenceRepo.IncrementAssetRef(ctx, projectID, asset); err != nil {
+			return fmt.Errorf("increment asset reference: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(pruned) > 0 {
+		if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, projectID, pruned); err != nil {
+			return fmt.Errorf("decrement pruned version references: %w", err)
+		}
+	}
+	return nil
+}
+
+// upsertArtifactTx writes a at its location inside tx, archiving and replacing an existing artifact
+// when overwrite is set, and returns the assets of versions pruned as a result.
+func upsertArtifactTx(tx *gorm.DB, a *model.Artifact, overwrite bool, policy ArtifactVersionPolicy) ([]model.Asset, error) {
+	var cur model.Artifact
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("disk_id = ? AND path = ? AND filename = ?", a.DiskID, a.Path, a.Filename).
+		First(&cur).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		a.Version = 1
+		return nil, tx.Create(a).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !overwrite {
+		return nil, fmt.Errorf("%w: %s%s", ErrArtifactExists, a.Path, a.Filename)
+	}
+
+	archived := &model.ArtifactVersion{
+		DiskID:    cur.DiskID,
+		Path:      cur.Path,
+		Filename:  cur.Filename,
+		Version:   cur.Version,
+		Meta:      cur.Meta,
+		AssetMeta: cur.AssetMeta,
+		CreatedAt: cur.UpdatedAt,
+	}
+	if err := tx.Create(archived).Error; err != nil {
+		return nil, fmt.Errorf("archive artifact version: %w", err)
+	}
+
+	a.ID = cur.ID
+	a.Version = cur.Version + 1
+	a.CreatedAt = cur.CreatedAt
+	if err := tx.Omit(clause.Associations).Save(a).Error; err != nil {
+		return nil, err
+	}
+
+	pruned, err := pruneArtifactVersions(tx, a.DiskID, a.Path, a.Filename, policy)
+	if err != nil {
+		return nil, fmt.Errorf("prune artifact versions: %w", err)
+	}
+	return pruned, nil
+}
+
+// pruneArtifactVersions deletes the versions of an artifact that fall outside policy and returns
+// their assets so the caller can release the references once the transaction commits.
+func pruneArtifactVersions(tx *gorm.DB, diskID uuid.UUID, path string, filename string, policy ArtifactVersionPolicy) ([]model.Asset, error) {
+	var versions []model.ArtifactVersion
+	if err := tx.Select("id", "asset_meta", "archived_at").
+		Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	var cutoff time.Time
+	if policy.MaxAge > 0 {
+		cutoff = time.Now().Add(-policy.MaxAge)
+	}
+
+	ids := make([]uuid.UUID, 0)
+	assets := make([]model.Asset, 0)
+	for i, v := range versions {
+		if i < policy.Keep && (cutoff.IsZero() || v.ArchivedAt.After(cutoff)) {
+			continue
+		}
+		ids = append(ids, v.ID)
+		if asset := v.AssetMeta.Data(); asset.SHA256 != "" {
+			assets = append(assets, asset)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if err := tx.Where("id IN ?", ids).Delete(&model.ArtifactVersion{}).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// DeleteByPath deletes the artifact together with all of its retained versions, provided it
+// currently satisfies cond.
+func (r *artifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond ArtifactPrecondition) error {
 	var a model.Artifact
 	err := r.db.WithContext(ctx).Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).First(&a).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
+			if len(cond.IfMatch) > 0 {
+				return cond.Check(nil)
+			}
 			return err
 		}
 		return err
@@ -62,8 +301,26 @@ func (r *artifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, di
 	// Save asset meta before deletion for reference decrement
 	asset := a.AssetMeta.Data()
 
-	// Use transaction to ensure atomicity: delete artifact and decrement reference
+	// Use transaction to ensure atomicity: delete artifact and versions, and decrement references
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !cond.IsZero() {
+			// Re-read under lock so the condition and the released asset match the deleted row
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", a.ID).First(&a).Error; err != nil {
+				return err
+			}
+			if err := cond.Check(&a); err != nil {
+				return err
+			}
+			asset = a.AssetMeta.Data()
+		}
+
+		var versions []model.ArtifactVersion
+		if err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "asset_meta"}}}).
+			Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).
+			Delete(&versions).Error; err != nil {
+			return fmt.Errorf("delete artifact versions: %w", err)
+		}
+
 		if err := tx.Delete(&a).Error; err != nil {
 			return err
 		}
@@ -72,12 +329,26 @@ func (r *artifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, di
 			return fmt.Errorf("decrement asset reference: %w", err)
 		}
 
+		versionAssets := make([]model.Asset, 0, len(versions))
+		for _, v := range versions {
+			versionAssets = append(versionAssets, v.AssetMeta.Data())
+		}
+		if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, projectID, versionAssets); err != nil {
+			return fmt.Errorf("decrement version asset references: %w", err)
+		}
+
 		return nil
 	})
 }
 
-func (r *artifactRepo) Update(ctx context.Context, a *model.Artifact) error {
-	return r.db.WithContext(ctx).Where("id = ? AND disk_id = ?", a.ID, a.DiskID).Updates(a).Error
+// Update saves the changed fields of a, provided the stored artifact currently satisfies cond.
+func (r *artifactRepo) Update(ctx context.Context, a *model.Artifact, cond ArtifactPrecondition) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkArtifactPreconditionTx(tx, a.DiskID, a.Path, a.Filename, cond); err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND disk_id = ?", a.ID, a.DiskID).Updates(a).Error
+	})
 }
 
 func (r *artifactRepo) GetByPath(ctx context.Context, diskID uuid.UUID, path string, filename string) (*model.Artifact, error) {
@@ -137,20 +408,48 @@ func (r *artifactRepo) ExistsByPathAndFilename(ctx context.Context, diskID uuid.
 	return count > 0, nil
 }
 
-func (r *artifactRepo) GrepArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
+// ArtifactGrepQuery selects text artifacts whose content matches a pattern.
+type ArtifactGrepQuery struct {
+	Pattern       string
+	FixedString   bool // match Pattern literally instead of as a regular expression
+	CaseSensitive bool
+
+	// Keyset position: only artifacts after (AfterPath, AfterFilename) are returned
+	AfterPath     string
+	AfterFilename string
+}
+
+// GrepArtifacts returns one page of the text artifacts whose content matches q, in (path, filename) order.
+// Every artifact with stored text is searched: text files as well as documents (PDF, DOCX, XLSX,
+// PPTX, HTML) whose extracted text is kept in asset_meta.content. Regular expressions run in
+// newline-sensitive mode so that '.' and bracket expressions stop at line ends, and patterns
+// Postgres cannot compile return ErrInvalidPattern.
+func (r *artifactRepo) GrepArtifacts(ctx context.Context, diskID uuid.UUID, q ArtifactGrepQuery, limit int) ([]*model.Artifact, error) {
 	var artifacts []*model.Artifact
 
-	// Use case-insensitive regex pattern matching on text content
-	// Filter by text-searchable mime types and ensure content is not null
-	// This matches the index condition for optimal performance
+	// content is only stored for files the parser can read, so its presence selects the searchable ones
 	query := r.db.WithContext(ctx).
 		Where("disk_id = ?", diskID).
-		Where("(asset_meta->>'content') IS NOT NULL").
-		Where("((asset_meta->>'mime') LIKE 'text/%' OR (asset_meta->>'mime') = 'application/json' OR (asset_meta->>'mime') LIKE 'application/x-%')").
-		Where("(asset_meta->>'content') ~* ?", pattern).
-		Limit(limit)
+		Where("(asset_meta->>'content') IS NOT NULL AND (asset_meta->>'content') <> ''")
+
+	switch {
+	case q.FixedString && q.CaseSensitive:
+		query = query.Where("(asset_meta->>'content') LIKE ?", "%"+escapeLike(q.Pattern)+"%")
+	case q.FixedString:
+		query = query.Where("(asset_meta->>'content') ILIKE ?", "%"+escapeLike(q.Pattern)+"%")
+	case q.CaseSensitive:
+		query = query.Where("(asset_meta->>'content') ~ ?", "(?n)"+q.Pattern)
+	default:
+		query = query.Where("(asset_meta->>'content') ~* ?", "(?n)"+q.Pattern)
+	}
+	if q.AfterPath != "" {
+		query = query.Where("(path, filename) > (?, ?)", q.AfterPath, q.AfterFilename)
+	}
 
-	err := query.Find(&artifacts).Error
+	err := query.Order("path ASC, filename ASC").Limit(limit).Find(&artifacts).Error
+	if isInvalidRegex(err) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -158,34 +457,292 @@ func (r *artifactRepo) GrepArtifacts(ctx context.Context, diskID uuid.UUID, patt
 	return artifacts, nil
 }
 
-func (r *artifactRepo) GlobArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
+// ArtifactGlobQuery selects artifacts whose full path (path || filename) matches a LIKE pattern.
+type ArtifactGlobQuery struct {
+	LikePattern string // escaped with '\'
+
+	// Keyset position: only artifacts after (AfterPath, AfterFilename) are returned
+	AfterPath     string
+	AfterFilename string
+}
+
+// GlobArtifacts returns one page of the artifacts matching q, in (path, filename) order.
+func (r *artifactRepo) GlobArtifacts(ctx context.Context, diskID uuid.UUID, q ArtifactGlobQuery, limit int) ([]*model.Artifact, error) {
 	var artifacts []*model.Artifact
 
-	// Convert glob pattern to PostgreSQL LIKE pattern
-	// Replace ** with % (recursive directory matching)
-	// Replace * with % (any characters)
-	// Replace ? with _ (single character)
-	// Note: ** must be replaced before * to avoid double replacement
-	sqlPattern := strings.ReplaceAll(pattern, "**", "%")
-	sqlPattern = strings.ReplaceAll(sqlPattern, "*", "%")
-	sqlPattern = strings.ReplaceAll(sqlPattern, "?", "_")
-
-	// Escape special LIKE characters that aren't glob patterns
-	// PostgreSQL LIKE uses % and _ as wildcards, \ as escape
-	// We need to escape any literal % or _ that aren't from our replacements
-	// But since we're doing full replacement, we don't need to escape
-
-	// Match against combined path/filename
 	// path already ends with '/', so no extra separator needed
 	query := r.db.WithContext(ctx).
 		Where("disk_id = ?", diskID).
-		Where("(path || filename) LIKE ?", sqlPattern).
-		Limit(limit)
+		Where("(path || filename) LIKE ?", q.LikePattern)
+	if q.AfterPath != "" {
+		query = query.Where("(path, filename) > (?, ?)", q.AfterPath, q.AfterFilename)
+	}
 
-	err := query.Find(&artifacts).Error
+	err := query.Order("path ASC, filename ASC").Limit(limit).Find(&artifacts).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return artifacts, nil
 }
+
+// ListVersions returns the retained versions of an artifact, newest first.
+func (r *artifactRepo) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	var versions []*model.ArtifactVersion
+	err := r.db.WithContext(ctx).
+		Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).
+		Order("version DESC").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *artifactRepo) GetVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.ArtifactVersion, error) {
+	var v model.ArtifactVersion
+	err := r.db.WithContext(ctx).
+		Where("disk_id = ? AND path = ? AND filename = ? AND version = ?", diskID, path, filename, version).
+		First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListByPathPrefix returns every artifact under the directory prefix, including nested ones.
+func (r *artifactRepo) ListByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]*model.Artifact, error) {
+	var artifacts []*model.Artifact
+	err := r.db.WithContext(ctx).
+		Where("disk_id = ? AND path LIKE ?", diskID, escapeLike(prefix)+"%").
+		Order("path ASC, filename ASC").
+		Find(&artifacts).Error
+	if err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// ListTree returns one page of the artifacts under the directory prefix in (path, filename)
+// order, starting after the given position. maxDepth 1 lists only files directly in prefix,
+// 2 adds files one directory down, and so on; 0 means unlimited.
+func (r *artifactRepo) ListTree(ctx context.Context, diskID uuid.UUID, prefix string, maxDepth int, afterPath string, afterFilename string, limit int) ([]*model.Artifact, error) {
+	q := r.db.WithContext(ctx).
+		Where("disk_id = ? AND path LIKE ?", diskID, escapeLike(prefix)+"%")
+	if maxDepth > 0 {
+		q = q.Where("length(path) - length(replace(path, '/', '')) < ?", strings.Count(prefix, "/")+maxDepth)
+	}
+	if afterPath != "" {
+		q = q.Where("(path, filename) > (?, ?)", afterPath, afterFilename)
+	}
+
+	var artifacts []*model.Artifact
+	if err := q.Order("path ASC, filename ASC").Limit(limit).Find(&artifacts).Error; err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// DeleteByPathPrefix removes every artifact under the directory prefix, with its versions, and
+// returns how many artifacts were deleted. Rows are deleted in batches so large directories do
+// not hold locks for long. Each batch commits together with the release of its asset references,
+// so an interrupted delete leaves a partially emptied directory with correct reference counts
+// and can simply be retried.
+func (r *artifactRepo) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	like := escapeLike(prefix) + "%"
+
+	// Versions go first so an interrupted delete never leaves history behind without its artifact
+	for {
+		n, err := r.deletePrefixBatch(ctx, projectID, model.ArtifactVersion{}.TableName(), diskID, like)
+		if err != nil {
+			return 0, fmt.Errorf("delete artifact versions: %w", err)
+		}
+		if n < ArtifactDeleteBatchSize {
+			break
+		}
+	}
+
+	var deleted int64
+	for {
+		n, err := r.deletePrefixBatch(ctx, projectID, model.Artifact{}.TableName(), diskID, like)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("delete artifacts: %w", err)
+		}
+		if n < ArtifactDeleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deletePrefixBatch deletes up to ArtifactDeleteBatchSize rows of table under the prefix and
+// releases their asset references in the same transaction. Assets left unreferenced are
+// deleted after the commit; if that fails the asset GC collects them later.
+func (r *artifactRepo) deletePrefixBatch(ctx context.Context, projectID uuid.UUID, table string, diskID uuid.UUID, like string) (int64, error) {
+	var n int64
+	var emptied []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			AssetMeta datatypes.JSONType[model.Asset]
+		}
+		if err := tx.Raw(`
+			DELETE FROM `+table+` WHERE id IN (
+				SELECT id FROM `+table+` WHERE disk_id = ? AND path LIKE ? LIMIT ?
+			) RETURNING asset_meta`, diskID, like, ArtifactDeleteBatchSize).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		n = int64(len(rows))
+
+		assets := make([]model.Asset, 0, len(rows))
+		for _, row := range rows {
+			assets = append(assets, row.AssetMeta.Data())
+		}
+		var err error
+		if emptied, err = decrementAssetRefsTx(tx, projectID, assets); err != nil {
+			return fmt.Errorf("decrement asset references: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(emptied) > 0 {
+		if _, err := r.assetReferenceRepo.DeleteOrphanedAssetRefs(ctx, emptied, time.Now()); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// SummarizeByPathPrefix returns file counts and byte totals for each directory under the
+// prefix that directly contains files. Callers roll these up into their ancestors.
+func (r *artifactRepo) SummarizeByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]ArtifactPathUsage, error) {
+	var usage []ArtifactPathUsage
+	err := r.db.WithContext(ctx).
+		Model(&model.Artifact{}).
+		Select("path, COUNT(*) AS files, COALESCE(SUM((asset_meta->>'size_b')::bigint), 0) AS bytes").
+		Where("disk_id = ? AND path LIKE ?", diskID, escapeLike(prefix)+"%").
+		Group("path").
+		Order("path ASC").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// Move relocates artifacts in a single transaction. An artifact moved to a free location keeps
+// its ID and takes its version history along. One moved onto an existing artifact (overwrite
+// only) becomes a new version of the destination, and the source is removed with its history.
+// Moves stay within a project, so the current asset references carry over unchanged.
+func (r *artifactRepo) Move(ctx context.Context, projectID uuid.UUID, transfers []ArtifactTransfer, overwrite bool, policy ArtifactVersionPolicy) error {
+	var released []model.Asset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range transfers {
+			src, dst := t.Source, t.Dest
+
+			exists, err := r.existsTx(tx, dst.DiskID, dst.Path, dst.Filename)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				res := tx.Model(&model.Artifact{}).Where("id = ?", src.ID).Updates(map[string]interface{}{
+					"disk_id":  dst.DiskID,
+					"path":     dst.Path,
+					"filename": dst.Filename,
+					"meta":     dst.Meta,
+				})
+				if res.Error != nil {
+					return fmt.Errorf("move artifact: %w", res.Error)
+				}
+				if res.RowsAffected == 0 {
+					return gorm.ErrRecordNotFound
+				}
+				if err := tx.Model(&model.ArtifactVersion{}).
+					Where("disk_id = ? AND path = ? AND filename = ?", src.DiskID, src.Path, src.Filename).
+					Updates(map[string]interface{}{"disk_id": dst.DiskID, "path": dst.Path, "filename": dst.Filename}).Error; err != nil {
+					return fmt.Errorf("move artifact versions: %w", err)
+				}
+				dst.ID, dst.Version, dst.CreatedAt, dst.UpdatedAt = src.ID, src.Version, src.CreatedAt, time.Now()
+				continue
+			}
+
+			dst.AssetMeta = src.AssetMeta
+			pruned, err := upsertArtifactTx(tx, dst, overwrite, policy)
+			if err != nil {
+				return err
+			}
+			released = append(released, pruned...)
+
+			var versions []model.ArtifactVersion
+			if err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "asset_meta"}}}).
+				Where("disk_id = ? AND path = ? AND filename = ?", src.DiskID, src.Path, src.Filename).
+				Delete(&versions).Error; err != nil {
+				return fmt.Errorf("delete source versions: %w", err)
+			}
+			for _, v := range versions {
+				released = append(released, v.AssetMeta.Data())
+			}
+			// The source's own asset reference now belongs to the destination row
+			if res := tx.Delete(&model.Artifact{}, "id = ?", src.ID); res.Error != nil {
+				return fmt.Errorf("delete source artifact: %w", res.Error)
+			} else if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(released) > 0 {
+		if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, projectID, released); err != nil {
+			return fmt.Errorf("decrement released asset references: %w", err)
+		}
+	}
+	return nil
+}
+
+// Copy writes each destination with its source's content in a single transaction. The
+// content-addressed asset is shared rather than re-uploaded, so only its reference count grows.
+func (r *artifactRepo) Copy(ctx context.Context, projectID uuid.UUID, transfers []ArtifactTransfer, overwrite bool, policy ArtifactVersionPolicy) error {
+	var released []model.Asset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		copied := make([]model.Asset, 0, len(transfers))
+		for _, t := range transfers {
+			t.Dest.AssetMeta = t.Source.AssetMeta
+			pruned, err := upsertArtifactTx(tx, t.Dest, overwrite, policy)
+			if err != nil {
+				return err
+			}
+			released = append(released, pruned...)
+			copied = append(copied, t.Dest.AssetMeta.Data())
+		}
+
+		if err := r.assetReferenceRepo.BatchIncrementAssetRefs(ctx, projectID, copied); err != nil {
+			return fmt.Errorf("increment asset references: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(released) > 0 {
+		if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, projectID, released); err != nil {
+			return fmt.Errorf("decrement pruned version references: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *artifactRepo) existsTx(tx *gorm.DB, diskID uuid.UUID, path string, filename string) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Artifact{}).
+		Where("disk_id = ? AND path = ? AND filename = ?", diskID, path, filename).
+		Count(&count).Error
+	return count > 0, err
+}
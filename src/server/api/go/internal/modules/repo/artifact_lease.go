@@ -0,0 +1,268 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrLeaseHeld is returned when another owner holds a live lease overlapping the path.
+	ErrLeaseHeld = errors.New("artifact lease held by another owner")
+	// ErrLeaseNotFound is returned when the owner holds no live lease at the path.
+	ErrLeaseNotFound = errors.New("artifact lease not found")
+)
+
+type ArtifactLeaseRepo interface {
+	// Acquire takes lease, or extends it when the owner already holds the same path. It fails
+	// with ErrLeaseHeld when a live lease of another owner overlaps the path.
+	Acquire(ctx context.Context, lease *model.ArtifactLease) error
+	// Renew extends a live lease held by lease.Owner to lease.ExpiresAt. It fails with
+	// ErrLeaseNotFound once the lease has expired, since another owner may have taken it since.
+	Renew(ctx context.Context, lease *model.ArtifactLease) error
+	Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error
+	ListActive(ctx context.Context, diskID uuid.UUID) ([]*model.ArtifactLease, error)
+}
+
+// NewArtifactLeaseRepo keeps leases in Redis when a client is available and falls back to the
+// artifact_leases table otherwise.
+func NewArtifactLeaseRepo(rdb *redis.Client, db *gorm.DB) ArtifactLeaseRepo {
+	if rdb != nil {
+		return &redisArtifactLeaseRepo{redis: rdb}
+	}
+	return &pgArtifactLeaseRepo{db: db}
+}
+
+// conflictingLease returns a lease among leases, owned by someone other than owner, that
+// overlaps path.
+func conflictingLease(leases []*model.ArtifactLease, path string, owner string) *model.ArtifactLease {
+	for _, l := range leases {
+		if l.Owner != owner && (l.Covers(path) || (model.ArtifactLease{Path: path}).Covers(l.Path)) {
+			return l
+		}
+	}
+	return nil
+}
+
+func leaseHeldErr(l *model.ArtifactLease) error {
+	return fmt.Errorf("%w: %s is locked until %s", ErrLeaseHeld, l.Path, l.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// Redis keeps the leases of a disk in one hash, path -> "owner\nexpires_at_ms", so that
+// acquiring can check for overlaps and write in a single script. Expiry is judged against the
+// Redis clock and the hash itself expires with its last lease.
+const redisKeyPrefixArtifactLease = "artifact:lease:"
+
+var acquireLeaseScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local path, owner, ttl, renew = ARGV[1], ARGV[2], tonumber(ARGV[3]), ARGV[4] == '1'
+
+local function covers(a, b)
+	return a == b or (string.sub(a, -1) == '/' and string.sub(b, 1, #a) == a)
+end
+
+if renew then
+	local cur = redis.call('HGET', KEYS[1], path)
+	if not cur then
+		return {'missing'}
+	end
+	local sep = string.find(cur, '\n', 1, true)
+	if string.sub(cur, 1, sep - 1) ~= owner or tonumber(string.sub(cur, sep + 1)) <= now then
+		return {'missing'}
+	end
+end
+
+local last = now + ttl
+local data = redis.call('HGETALL', KEYS[1])
+for i = 1, #data, 2 do
+	local p, v = data[i], data[i + 1]
+	local sep = string.find(v, '\n', 1, true)
+	local o, exp = string.sub(v, 1, sep - 1), tonumber(string.sub(v, sep + 1))
+	if exp <= now then
+		redis.call('HDEL', KEYS[1], p)
+	else
+		if o ~= owner and (covers(p, path) or covers(path, p)) then
+			return {'held', p, exp}
+		end
+		if p ~= path and exp > last then
+			last = exp
+		end
+	end
+end
+
+redis.call('HSET', KEYS[1], path, owner .. '\n' .. (now + ttl))
+redis.call('PEXPIRE', KEYS[1], last - now)
+return {'ok', now + ttl}
+`)
+
+var releaseLeaseScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if not cur then
+	return 0
+end
+local sep = string.find(cur, '\n', 1, true)
+if string.sub(cur, 1, sep - 1) ~= ARGV[2] then
+	return 0
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+if tonumber(string.sub(cur, sep + 1)) <= now then
+	return 0
+end
+return 1
+`)
+
+type redisArtifactLeaseRepo struct {
+	redis *redis.Client
+}
+
+func (r *redisArtifactLeaseRepo) Acquire(ctx context.Context, lease *model.ArtifactLease) error {
+	return r.write(ctx, lease, false)
+}
+
+func (r *redisArtifactLeaseRepo) Renew(ctx context.Context, lease *model.ArtifactLease) error {
+	return r.write(ctx, lease, true)
+}
+
+func (r *redisArtifactLeaseRepo) write(ctx context.Context, lease *model.ArtifactLease, renew bool) error {
+	ttl := time.Until(lease.ExpiresAt).Milliseconds()
+	if ttl <= 0 {
+		return fmt.Errorf("lease expiry must be in the future")
+	}
+	renewArg := "0"
+	if renew {
+		renewArg = "1"
+	}
+
+	res, err := acquireLeaseScript.Run(ctx, r.redis, []string{redisKeyPrefixArtifactLease + lease.DiskID.String()},
+		lease.Path, lease.Owner, ttl, renewArg).Slice()
+	if err != nil {
+		return fmt.Errorf("run lease script: %w", err)
+	}
+
+	switch res[0] {
+	case "ok":
+		lease.ExpiresAt = time.UnixMilli(res[1].(int64))
+		return nil
+	case "held":
+		return leaseHeldErr(&model.ArtifactLease{Path: res[1].(string), ExpiresAt: time.UnixMilli(res[2].(int64))})
+	default:
+		return fmt.Errorf("%w: %s", ErrLeaseNotFound, lease.Path)
+	}
+}
+
+func (r *redisArtifactLeaseRepo) Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error {
+	released, err := releaseLeaseScript.Run(ctx, r.redis, []string{redisKeyPrefixArtifactLease + diskID.String()}, path, owner).Int()
+	if err != nil {
+		return fmt.Errorf("run release script: %w", err)
+	}
+	if released == 0 {
+		return fmt.Errorf("%w: %s", ErrLeaseNotFound, path)
+	}
+	return nil
+}
+
+func (r *redisArtifactLeaseRepo) ListActive(ctx context.Context, diskID uuid.UUID) ([]*model.ArtifactLease, error) {
+	data, err := r.redis.HGetAll(ctx, redisKeyPrefixArtifactLease+diskID.String()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get leases: %w", err)
+	}
+
+	now := time.Now()
+	leases := make([]*model.ArtifactLease, 0, len(data))
+	for path, v := range data {
+		owner, exp, ok := strings.Cut(v, "\n")
+		if !ok {
+			continue
+		}
+		ms, err := strconv.ParseInt(exp, 10, 64)
+		if err != nil {
+			continue
+		}
+		if expiresAt := time.UnixMilli(ms); expiresAt.After(now) {
+			leases = append(leases, &model.ArtifactLease{DiskID: diskID, Path: path, Owner: owner, ExpiresAt: expiresAt})
+		}
+	}
+	return leases, nil
+}
+
+type pgArtifactLeaseRepo struct {
+	db *gorm.DB
+}
+
+func (r *pgArtifactLeaseRepo) Acquire(ctx context.Context, lease *model.ArtifactLease) error {
+	return r.write(ctx, lease, false)
+}
+
+func (r *pgArtifactLeaseRepo) Renew(ctx context.Context, lease *model.ArtifactLease) error {
+	return r.write(ctx, lease, true)
+}
+
+// write serializes lease changes per disk with a transaction-scoped advisory lock, so the
+// overlap check and the write cannot interleave with another acquire on the same disk.
+func (r *pgArtifactLeaseRepo) write(ctx context.Context, lease *model.ArtifactLease, renew bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", redisKeyPrefixArtifactLease+lease.DiskID.String()).Error; err != nil {
+			return fmt.Errorf("lock disk leases: %w", err)
+		}
+
+		now := time.Now()
+		if err := tx.Where("disk_id = ? AND expires_at <= ?", lease.DiskID, now).Delete(&model.ArtifactLease{}).Error; err != nil {
+			return fmt.Errorf("delete expired leases: %w", err)
+		}
+		var leases []*model.ArtifactLease
+		if err := tx.Where("disk_id = ?", lease.DiskID).Find(&leases).Error; err != nil {
+			return fmt.Errorf("load leases: %w", err)
+		}
+
+		if renew {
+			held := false
+			for _, l := range leases {
+				held = held || (l.Path == lease.Path && l.Owner == lease.Owner)
+			}
+			if !held {
+				return fmt.Errorf("%w: %s", ErrLeaseNotFound, lease.Path)
+			}
+		}
+		if l := conflictingLease(leases, lease.Path, lease.Owner); l != nil {
+			return leaseHeldErr(l)
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "disk_id"}, {Name: "path"}},
+			DoUpdates: clause.AssignmentColumns([]string{"owner", "expires_at"}),
+		}).Omit(clause.Associations).Create(lease).Error
+	})
+}
+
+func (r *pgArtifactLeaseRepo) Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error {
+	res := r.db.WithContext(ctx).
+		Where("disk_id = ? AND path = ? AND owner = ? AND expires_at > ?", diskID, path, owner, time.Now()).
+		Delete(&model.ArtifactLease{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrLeaseNotFound, path)
+	}
+	return nil
+}
+
+func (r *pgArtifactLeaseRepo) ListActive(ctx context.Context, diskID uuid.UUID) ([]*model.ArtifactLease, error) {
+	var leases []*model.ArtifactLease
+	if err := r.db.WithContext(ctx).Where("disk_id = ? AND expires_at > ?", diskID, time.Now()).Find(&leases).Error; err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewArtifactLeaseRepo(t *testing.T) {
+	mr := miniredis.RunT(t)
+	assert.IsType(t, &redisArtifactLeaseRepo{}, NewArtifactLeaseRepo(redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil))
+	assert.IsType(t, &pgArtifactLeaseRepo{}, NewArtifactLeaseRepo(nil, nil))
+}
+
+func TestRedisArtifactLeaseRepo(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	r := NewArtifactLeaseRepo(rdb, nil)
+	ctx := context.Background()
+	diskID := uuid.New()
+
+	lease := func(path, owner string, ttl time.Duration) *model.ArtifactLease {
+		return &model.ArtifactLease{DiskID: diskID, Path: path, Owner: owner, ExpiresAt: time.Now().Add(ttl)}
+	}
+
+	require.NoError(t, r.Acquire(ctx, lease("/plans/", "alice", time.Minute)))
+
+	t.Run("overlapping leases of other owners are refused", func(t *testing.T) {
+		assert.ErrorIs(t, r.Acquire(ctx, lease("/plans/today.md", "bob", time.Minute)), ErrLeaseHeld)
+		assert.ErrorIs(t, r.Acquire(ctx, lease("/", "bob", time.Minute)), ErrLeaseHeld)
+		assert.NoError(t, r.Acquire(ctx, lease("/notes/today.md", "bob", time.Minute)))
+	})
+
+	t.Run("the owner can nest and re-acquire", func(t *testing.T) {
+		assert.NoError(t, r.Acquire(ctx, lease("/plans/today.md", "alice", time.Minute)))
+		assert.NoError(t, r.Acquire(ctx, lease("/plans/", "alice", 2*time.Minute)))
+	})
+
+	t.Run("renew requires the live lease of the owner", func(t *testing.T) {
+		assert.NoError(t, r.Renew(ctx, lease("/plans/", "alice", 5*time.Minute)))
+		assert.ErrorIs(t, r.Renew(ctx, lease("/plans/", "bob", time.Minute)), ErrLeaseNotFound)
+		assert.ErrorIs(t, r.Renew(ctx, lease("/other/", "alice", time.Minute)), ErrLeaseNotFound)
+	})
+
+	t.Run("list returns live leases", func(t *testing.T) {
+		leases, err := r.ListActive(ctx, diskID)
+		require.NoError(t, err)
+		paths := map[string]string{}
+		for _, l := range leases {
+			paths[l.Path] = l.Owner
+		}
+		assert.Equal(t, map[string]string{"/plans/": "alice", "/plans/today.md": "alice", "/notes/today.md": "bob"}, paths)
+	})
+
+	t.Run("release only by the owner", func(t *testing.T) {
+		assert.ErrorIs(t, r.Release(ctx, diskID, "/notes/today.md", "alice"), ErrLeaseNotFound)
+		assert.NoError(t, r.Release(ctx, diskID, "/notes/today.md", "bob"))
+		assert.ErrorIs(t, r.Release(ctx, diskID, "/notes/today.md", "bob"), ErrLeaseNotFound)
+	})
+
+	t.Run("expired leases no longer block", func(t *testing.T) {
+		mr.SetTime(time.Now().Add(time.Hour))
+		defer mr.SetTime(time.Time{})
+
+		assert.NoError(t, r.Acquire(ctx, lease("/plans/today.md", "bob", 2*time.Hour)))
+		assert.ErrorIs(t, r.Renew(ctx, lease("/plans/", "alice", 2*time.Hour)), ErrLeaseNotFound)
+	})
+}
+
+func TestPgArtifactLeaseRepo(t *testing.T) {
+	db := setupArtifactTestDB(t)
+	if db == nil {
+		return
+	}
+	require.NoError(t, db.AutoMigrate(&model.ArtifactLease{}))
+	_, diskID := createArtifactTestDisk(t, db)
+	r := NewArtifactLeaseRepo(nil, db)
+	ctx := context.Background()
+
+	lease := func(path, owner string, ttl time.Duration) *model.ArtifactLease {
+		return &model.ArtifactLease{DiskID: diskID, Path: path, Owner: owner, ExpiresAt: time.Now().Add(ttl)}
+	}
+
+	require.NoError(t, r.Acquire(ctx, lease("/plans/", "alice", time.Minute)))
+	assert.ErrorIs(t, r.Acquire(ctx, lease("/plans/today.md", "bob", time.Minute)), ErrLeaseHeld)
+	assert.NoError(t, r.Acquire(ctx, lease("/notes/today.md", "bob", time.Minute)))
+	assert.NoError(t, r.Renew(ctx, lease("/plans/", "alice", 5*time.Minute)))
+	assert.ErrorIs(t, r.Renew(ctx, lease("/plans/", "bob", time.Minute)), ErrLeaseNotFound)
+
+	leases, err := r.ListActive(ctx, diskID)
+	require.NoError(t, err)
+	assert.Len(t, leases, 2)
+
+	assert.ErrorIs(t, r.Release(ctx, diskID, "/plans/", "bob"), ErrLeaseNotFound)
+	assert.NoError(t, r.Release(ctx, diskID, "/plans/", "alice"))
+	assert.NoError(t, r.Acquire(ctx, lease("/plans/today.md", "bob", time.Minute)))
+
+	// An expired lease no longer blocks and cannot be renewed
+	require.NoError(t, db.Model(&model.ArtifactLease{}).Where("disk_id = ?", diskID).Update("expires_at", time.Now().Add(-time.Second)).Error)
+	assert.NoError(t, r.Acquire(ctx, lease("/plans/", "carol", time.Minute)))
+	assert.ErrorIs(t, r.Renew(ctx, lease("/notes/today.md", "bob", time.Minute)), ErrLeaseNotFound)
+}
+
+func TestArtifactLease_Covers(t *testing.T) {
+	dir := model.ArtifactLease{Path: "/plans/"}
+	file := model.ArtifactLease{Path: "/plans/today.md"}
+
+	assert.True(t, dir.Covers("/plans/"))
+	assert.True(t, dir.Covers("/plans/today.md"))
+	assert.True(t, dir.Covers("/plans/sub/"))
+	assert.False(t, dir.Covers("/plans2/x.md"))
+	assert.True(t, file.Covers("/plans/today.md"))
+	assert.False(t, file.Covers("/plans/today.md.bak"))
+}
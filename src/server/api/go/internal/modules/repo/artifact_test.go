@@ -0,0 +1,206 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupArtifactTestDB(t *testing.T) *gorm.DB {
+	dsn := "host=localhost user=acontext password=helloworld dbname=acontext port=15432 sslmode=disable"
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Skip("Test database not available, skipping integration tests")
+		return nil
+	}
+
+	err = db.AutoMigrate(
+		&model.Project{},
+		&model.User{},
+		&model.Disk{},
+		&model.Artifact{},
+		&model.ArtifactVersion{},
+		&model.AssetReference{},
+	)
+	require.NoError(t, err)
+
+	return db
+}
+
+// createArtifactTestDisk creates a project with one disk, removed with everything on it when the test ends.
+func createArtifactTestDisk(t *testing.T, db *gorm.DB) (projectID uuid.UUID, diskID uuid.UUID) {
+	projectID = uuid.New()
+	require.NoError(t, db.Create(&model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_artifact_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_artifact",
+	}).Error)
+	disk := &model.Disk{ProjectID: projectID}
+	require.NoError(t, db.Create(disk).Error)
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM asset_references WHERE project_id = ?", projectID)
+		db.Exec("DELETE FROM projects WHERE id = ?", projectID)
+	})
+	return projectID, disk.ID
+}
+
+func TestArtifactPrecondition_Check(t *testing.T) {
+	cur := &model.Artifact{AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "abc"})}
+
+	tests := []struct {
+		name string
+		cond ArtifactPrecondition
+		cur  *model.Artifact
+		ok   bool
+	}{
+		{name: "zero value matches an existing artifact", cur: cur, ok: true},
+		{name: "zero value matches a missing artifact", ok: true},
+		{name: "if-match on the current sha", cond: ArtifactPrecondition{IfMatch: []string{"old", "abc"}}, cur: cur, ok: true},
+		{name: "if-match on a stale sha", cond: ArtifactPrecondition{IfMatch: []string{"old"}}, cur: cur},
+		{name: "if-match any on an existing artifact", cond: ArtifactPrecondition{IfMatch: []string{"*"}}, cur: cur, ok: true},
+		{name: "if-match any on a missing artifact", cond: ArtifactPrecondition{IfMatch: []string{"*"}}},
+		{name: "if-none-match any on a missing artifact", cond: ArtifactPrecondition{IfNoneMatch: []string{"*"}}, ok: true},
+		{name: "if-none-match any on an existing artifact", cond: ArtifactPrecondition{IfNoneMatch: []string{"*"}}, cur: cur},
+		{name: "if-none-match on the current sha", cond: ArtifactPrecondition{IfNoneMatch: []string{"abc"}}, cur: cur},
+		{name: "if-none-match on another sha", cond: ArtifactPrecondition{IfNoneMatch: []string{"old"}}, cur: cur, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cond.Check(tt.cur)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrPreconditionFailed)
+			}
+		})
+	}
+}
+
+func TestArtifactRepo_GrepArtifacts_Documents(t *testing.T) {
+	db := setupArtifactTestDB(t)
+	if db == nil {
+		return
+	}
+	_, diskID := createArtifactTestDisk(t, db)
+	r := NewArtifactRepo(db, nil)
+	ctx := context.Background()
+
+	for _, a := range []*model.Artifact{
+		{Path: "/docs/", Filename: "spec.pdf", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "application/pdf", Content: "Quarterly revenue grew\n"})},
+		{Path: "/docs/", Filename: "plan.docx", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Content: "Revenue plan\n"})},
+		{Path: "/docs/", Filename: "notes.md", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "text/markdown", Content: "no match here\n"})},
+		{Path: "/img/", Filename: "chart.png", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "image/png"})},
+	} {
+		a.DiskID = diskID
+		require.NoError(t, db.Create(a).Error)
+	}
+
+	got, err := r.GrepArtifacts(ctx, diskID, ArtifactGrepQuery{Pattern: "revenue"}, 10)
+	require.NoError(t, err)
+
+	var names []string
+	for _, a := range got {
+		names = append(names, a.Filename)
+	}
+	assert.Equal(t, []string{"plan.docx", "spec.pdf"}, names)
+}
+
+func TestArtifactRepo_GrepArtifacts_Regex(t *testing.T) {
+	db := setupArtifactTestDB(t)
+	if db == nil {
+		return
+	}
+	_, diskID := createArtifactTestDisk(t, db)
+	r := NewArtifactRepo(db, nil)
+	ctx := context.Background()
+
+	for _, a := range []*model.Artifact{
+		{Path: "/", Filename: "one.txt", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "text/plain", Content: "start here\nfinish there\n"})},
+		{Path: "/", Filename: "two.txt", AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "text/plain", Content: "start and finish\n"})},
+	} {
+		a.DiskID = diskID
+		require.NoError(t, db.Create(a).Error)
+	}
+
+	t.Run("dot does not cross lines", func(t *testing.T) {
+		got, err := r.GrepArtifacts(ctx, diskID, ArtifactGrepQuery{Pattern: "start.*finish"}, 10)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "two.txt", got[0].Filename)
+	})
+
+	t.Run("pattern postgres rejects", func(t *testing.T) {
+		_, err := r.GrepArtifacts(ctx, diskID, ArtifactGrepQuery{Pattern: `\p{Greek}`}, 10)
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+	})
+}
+
+func TestArtifactRepo_DeleteByPathPrefix(t *testing.T) {
+	db := setupArtifactTestDB(t)
+	if db == nil {
+		return
+	}
+	projectID, diskID := createArtifactTestDisk(t, db)
+
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	r := NewArtifactRepo(db, NewAssetReferenceRepo(db, store))
+	ctx := context.Background()
+
+	newAsset := func(name string, refCount int) model.Asset {
+		asset, err := store.UploadFileDirect(ctx, "assets/"+projectID.String()+"/"+name, []byte(name), "text/plain", nil)
+		require.NoError(t, err)
+		asset.SHA256 = "dddd" + uuid.New().String()[:60]
+		require.NoError(t, db.Create(&model.AssetReference{
+			ProjectID:        projectID,
+			SHA256:           asset.SHA256,
+			S3Key:            asset.S3Key,
+			RefCount:         refCount,
+			AssetMeta:        datatypes.NewJSONType(*asset),
+			LastReferencedAt: time.Now().Add(-time.Minute),
+		}).Error)
+		return *asset
+	}
+	// shared is used twice under /dir/ and once outside it; only is used once under /dir/
+	shared := newAsset("shared.txt", 3)
+	only := newAsset("only.txt", 1)
+
+	for _, a := range []*model.Artifact{
+		{Path: "/dir/", Filename: "a.txt", AssetMeta: datatypes.NewJSONType(shared)},
+		{Path: "/dir/sub/", Filename: "b.txt", AssetMeta: datatypes.NewJSONType(shared)},
+		{Path: "/dir/", Filename: "c.txt", AssetMeta: datatypes.NewJSONType(only)},
+		{Path: "/other/", Filename: "d.txt", AssetMeta: datatypes.NewJSONType(shared)},
+	} {
+		a.DiskID = diskID
+		require.NoError(t, db.Create(a).Error)
+	}
+
+	deleted, err := r.DeleteByPathPrefix(ctx, projectID, diskID, "/dir/")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	var ref model.AssetReference
+	require.NoError(t, db.First(&ref, "project_id = ? AND sha256 = ?", projectID, shared.SHA256).Error)
+	assert.Equal(t, 1, ref.RefCount)
+	_, err = store.Head(ctx, shared.S3Key)
+	assert.NoError(t, err)
+
+	err = db.First(&model.AssetReference{}, "project_id = ? AND sha256 = ?", projectID, only.SHA256).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = store.Head(ctx, only.S3Key)
+	assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+}
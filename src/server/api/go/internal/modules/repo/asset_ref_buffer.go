@@ -29,6 +29,8 @@ const (
 // INSERT ... ON CONFLICT contention under high concurrency.
 type AssetRefBuffer interface {
 	Enqueue(ctx context.Context, projectID uuid.UUID, assets []model.Asset) error
+	// Pending returns the project's increments not yet flushed, keyed by sha256.
+	Pending(ctx context.Context, projectID uuid.UUID) (map[string]int, error)
 	Start()
 	Stop()
 }
@@ -102,6 +104,23 @@ func (b *assetRefBuffer) Enqueue(ctx context.Context, projectID uuid.UUID, asset
 	return nil
 }
 
+// Pending reads the project's buffer hash without draining it.
+func (b *assetRefBuffer) Pending(ctx context.Context, projectID uuid.UUID) (map[string]int, error) {
+	fields, err := b.redis.HGetAll(ctx, assetRefBufPrefix+projectID.String()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("AssetRefBuffer.Pending: %w", err)
+	}
+	pending := make(map[string]int, len(fields))
+	for sha256, v := range fields {
+		var count int
+		if _, err := fmt.Sscanf(v, "%d", &count); err != nil || count <= 0 {
+			continue
+		}
+		pending[sha256] = count
+	}
+	return pending, nil
+}
+
 // Start begins the background flusher goroutine.
 func (b *assetRefBuffer) Start() {
 	go b.run()
@@ -46,6 +46,30 @@ func (m *mockAssetReferenceRepoForBuffer) ListS3KeysByProject(_ context.Context,
 	return nil, nil
 }
 
+func (m *mockAssetReferenceRepoForBuffer) ListOrphanedAssetRefs(_ context.Context, _ *uuid.UUID, _ time.Time, _ uuid.UUID, _ int) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepoForBuffer) DeleteOrphanedAssetRefs(_ context.Context, _ []uuid.UUID, _ time.Time) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepoForBuffer) ListAssetRefsByProject(_ context.Context, _ uuid.UUID) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepoForBuffer) CountAssetUses(_ context.Context, _ uuid.UUID) ([]AssetUse, error) {
+	return nil, nil
+}
+
+func (m *mockAssetReferenceRepoForBuffer) SetAssetRefCount(_ context.Context, _ uuid.UUID, _ model.Asset, _ *int, _ int) (bool, error) {
+	return false, nil
+}
+
+func (m *mockAssetReferenceRepoForBuffer) DeleteUnreferencedAsset(_ context.Context, _ uuid.UUID, _ model.Asset) (bool, error) {
+	return false, nil
+}
+
 func setupMiniRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
 	t.Helper()
 	mr := miniredis.RunT(t)
@@ -124,6 +148,35 @@ func TestAssetRefBuffer_FlushCoalesces(t *testing.T) {
 	assert.Equal(t, "abc123", mockRepo.calls[0].Increments[0].Asset.SHA256)
 }
 
+func TestAssetRefBuffer_Pending(t *testing.T) {
+	_, rdb := setupMiniRedis(t)
+	logger, _ := zap.NewDevelopment()
+	mockRepo := &mockAssetReferenceRepoForBuffer{}
+
+	buf := NewAssetRefBuffer(rdb, mockRepo, logger)
+	ctx := context.Background()
+	pid := uuid.New()
+
+	pending, err := buf.Pending(ctx, pid)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	require.NoError(t, buf.Enqueue(ctx, pid, []model.Asset{{SHA256: "abc123"}, {SHA256: "abc123"}, {SHA256: "def456"}}))
+	require.NoError(t, buf.Enqueue(ctx, uuid.New(), []model.Asset{{SHA256: "abc123"}}))
+
+	pending, err = buf.Pending(ctx, pid)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"abc123": 2, "def456": 1}, pending)
+
+	// Reading does not drain the buffer
+	buf.(*assetRefBuffer).flushAll()
+	require.Len(t, mockRepo.calls, 2)
+
+	pending, err = buf.Pending(ctx, pid)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
 func TestAssetRefBuffer_FlushMultipleProjects(t *testing.T) {
 	_, rdb := setupMiniRedis(t)
 	logger, _ := zap.NewDevelopment()
@@ -3,6 +3,8 @@ package repo
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +22,14 @@ type AssetRefIncrement struct {
 	Count int
 }
 
+// AssetUse counts the rows of a project that reference one asset.
+type AssetUse struct {
+	Asset model.Asset
+	Count int
+	// Parts marks a message's parts JSON, whose parts may reference further assets
+	Parts bool
+}
+
 type AssetReferenceRepo interface {
 	IncrementAssetRef(ctx context.Context, projectID uuid.UUID, asset model.Asset) error
 	DecrementAssetRef(ctx context.Context, projectID uuid.UUID, asset model.Asset) error
@@ -27,14 +37,20 @@ type AssetReferenceRepo interface {
 	BatchIncrementAssetRefsWithCounts(ctx context.Context, projectID uuid.UUID, increments []AssetRefIncrement) error
 	BatchDecrementAssetRefs(ctx context.Context, projectID uuid.UUID, assets []model.Asset) error
 	ListS3KeysByProject(ctx context.Context, projectID uuid.UUID) ([]string, error)
+	ListOrphanedAssetRefs(ctx context.Context, projectID *uuid.UUID, before time.Time, afterID uuid.UUID, limit int) ([]model.AssetReference, error)
+	DeleteOrphanedAssetRefs(ctx context.Context, ids []uuid.UUID, before time.Time) ([]model.AssetReference, error)
+	ListAssetRefsByProject(ctx context.Context, projectID uuid.UUID) ([]model.AssetReference, error)
+	CountAssetUses(ctx context.Context, projectID uuid.UUID) ([]AssetUse, error)
+	SetAssetRefCount(ctx context.Context, projectID uuid.UUID, asset model.Asset, observed *int, expected int) (bool, error)
+	DeleteUnreferencedAsset(ctx context.Context, projectID uuid.UUID, asset model.Asset) (bool, error)
 }
 
 type assetReferenceRepo struct {
 	db *gorm.DB
-	s3 *blob.S3Deps
+	s3 blob.BlobStore
 }
 
-func NewAssetReferenceRepo(db *gorm.DB, s3 *blob.S3Deps) AssetReferenceRepo {
+func NewAssetReferenceRepo(db *gorm.DB, s3 blob.BlobStore) AssetReferenceRepo {
 	return &assetReferenceRepo{db: db, s3: s3}
 }
 
@@ -234,9 +250,10 @@ func (r *assetReferenceRepo) BatchDecrementAssetRefs(ctx context.Context, projec
 		return nil
 	}
 
-	// For each sha, decrement or delete
+	// For each sha, decrement or collect for deletion
 	// Use SkipHooks to prevent recursive hook triggers when called from other hooks
 	sessionTx := r.db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true})
+	var dropped []model.AssetReference
 	for sha, dec := range grouped {
 		var ref model.AssetReference
 		err := sessionTx.Where("project_id = ? AND sha256 = ?", projectID, sha).First(&ref).Error
@@ -247,12 +264,7 @@ func (r *assetReferenceRepo) BatchDecrementAssetRefs(ctx context.Context, projec
 			return err
 		}
 		if ref.RefCount <= dec {
-			if err := r.s3.DeleteObject(ctx, ref.S3Key); err != nil {
-				return err
-			}
-			if err := sessionTx.Delete(&ref).Error; err != nil {
-				return err
-			}
+			dropped = append(dropped, ref)
 			continue
 		}
 		if err := sessionTx.Model(&model.AssetReference{}).
@@ -261,7 +273,57 @@ func (r *assetReferenceRepo) BatchDecrementAssetRefs(ctx context.Context, projec
 			return err
 		}
 	}
-	return nil
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	// Objects whose last reference is gone are removed from S3 in bulk before their rows
+	keys := make([]string, 0, len(dropped))
+	shas := make([]string, 0, len(dropped))
+	for _, ref := range dropped {
+		keys = append(keys, ref.S3Key)
+		shas = append(shas, ref.SHA256)
+	}
+	if err := r.s3.DeleteObjects(ctx, keys); err != nil {
+		return err
+	}
+	return sessionTx.Where("project_id = ? AND sha256 IN ?", projectID, shas).Delete(&model.AssetReference{}).Error
+}
+
+// decrementAssetRefsTx releases the references held by assets inside tx and returns the IDs of
+// the asset references left with none. Unlike BatchDecrementAssetRefs it keeps their rows and
+// objects, since tx may still roll back; callers remove them with DeleteOrphanedAssetRefs once
+// tx commits, and the asset GC collects any that are missed.
+func decrementAssetRefsTx(tx *gorm.DB, projectID uuid.UUID, assets []model.Asset) ([]uuid.UUID, error) {
+	grouped := make(map[string]int)
+	for _, a := range assets {
+		if a.SHA256 == "" {
+			continue
+		}
+		grouped[a.SHA256]++
+	}
+
+	var emptied []uuid.UUID
+	// Rows are locked in sha256 order so concurrent batches cannot deadlock
+	for _, sha := range slices.Sorted(maps.Keys(grouped)) {
+		var rows []struct {
+			ID       uuid.UUID
+			RefCount int
+		}
+		if err := tx.Raw(`
+			UPDATE asset_references SET ref_count = GREATEST(ref_count - ?, 0)
+			WHERE project_id = ? AND sha256 = ?
+			RETURNING id, ref_count`, grouped[sha], projectID, sha).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if row.RefCount == 0 {
+				emptied = append(emptied, row.ID)
+			}
+		}
+	}
+	return emptied, nil
 }
 
 // ListS3KeysByProject returns all distinct S3 keys for a project from the asset_references table.
@@ -276,3 +338,221 @@ func (r *assetReferenceRepo) ListS3KeysByProject(ctx context.Context, projectID
 	}
 	return keys, nil
 }
+
+// orphanedBefore scopes a query to asset references with no references left and none
+// taken since before.
+func orphanedBefore(db *gorm.DB, before time.Time) *gorm.DB {
+	return db.Where("ref_count = 0 AND updated_at < ? AND last_referenced_at < ?", before, before)
+}
+
+// ListOrphanedAssetRefs returns up to limit asset references, in ID order after afterID, whose
+// ref_count is zero and that have not been referenced or updated since before. A nil
+// projectID lists them across all projects.
+func (r *assetReferenceRepo) ListOrphanedAssetRefs(ctx context.Context, projectID *uuid.UUID, before time.Time, afterID uuid.UUID, limit int) ([]model.AssetReference, error) {
+	q := orphanedBefore(r.db.WithContext(ctx), before).Where("id > ?", afterID)
+	if projectID != nil {
+		q = q.Where("project_id = ?", *projectID)
+	}
+	var refs []model.AssetReference
+	if err := q.Order("id ASC").Limit(limit).Find(&refs).Error; err != nil {
+		return nil, fmt.Errorf("list orphaned asset references: %w", err)
+	}
+	return refs, nil
+}
+
+// DeleteOrphanedAssetRefs deletes the asset references among ids that are still orphaned as
+// of before, together with their S3 objects, and returns the deleted rows. The rows are
+// deleted first and the transaction commits only once their objects are gone, so a
+// reference taken meanwhile keeps its row and a failed object deletion is retried by the
+// next sweep.
+func (r *assetReferenceRepo) DeleteOrphanedAssetRefs(ctx context.Context, ids []uuid.UUID, before time.Time) ([]model.AssetReference, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var deleted []model.AssetReference
+	err := r.db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		if err := orphanedBefore(tx, before).Where("id IN ?", ids).
+			Clauses(clause.Returning{}).Delete(&deleted).Error; err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(deleted))
+		for _, ref := range deleted {
+			keys = append(keys, ref.S3Key)
+		}
+		return r.s3.DeleteObjects(ctx, keys)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete orphaned asset references: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListAssetRefsByProject returns all asset references of a project.
+func (r *assetReferenceRepo) ListAssetRefsByProject(ctx context.Context, projectID uuid.UUID) ([]model.AssetReference, error) {
+	var refs []model.AssetReference
+	if err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("sha256 ASC").Find(&refs).Error; err != nil {
+		return nil, fmt.Errorf("list asset references by project: %w", err)
+	}
+	return refs, nil
+}
+
+// CountAssetUses counts, per asset, the project's rows that hold a reference to it: artifacts,
+// their archived versions, disk snapshot entries, and messages through their parts JSON.
+// Assets referenced by individual parts are inside the parts JSON and are not counted here.
+func (r *assetReferenceRepo) CountAssetUses(ctx context.Context, projectID uuid.UUID) ([]AssetUse, error) {
+	var rows []struct {
+		Count     int
+		Parts     bool
+		AssetMeta datatypes.JSONType[model.Asset]
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) AS count, parts, (ARRAY_AGG(asset_meta))[1] AS asset_meta
+		FROM (
+			SELECT a.asset_meta, FALSE AS parts
+			FROM artifacts a JOIN disks d ON d.id = a.disk_id WHERE d.project_id = @project_id
+			UNION ALL
+			SELECT v.asset_meta, FALSE
+			FROM artifact_versions v JOIN disks d ON d.id = v.disk_id WHERE d.project_id = @project_id
+			UNION ALL
+			SELECT e.asset_meta, FALSE
+			FROM disk_snapshot_entries e JOIN disk_snapshots s ON s.id = e.snapshot_id WHERE s.project_id = @project_id
+			UNION ALL
+			SELECT m.parts_asset_meta, TRUE
+			FROM messages m JOIN sessions s ON s.id = m.session_id WHERE s.project_id = @project_id
+		) uses
+		WHERE COALESCE(asset_meta->>'sha256', '') <> ''
+		GROUP BY asset_meta->>'sha256', parts
+		ORDER BY asset_meta->>'sha256'`,
+		map[string]any{"project_id": projectID}).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("count asset uses: %w", err)
+	}
+	uses := make([]AssetUse, len(rows))
+	for i, row := range rows {
+		uses[i] = AssetUse{Asset: row.AssetMeta.Data(), Count: row.Count, Parts: row.Parts}
+	}
+	return uses, nil
+}
+
+// SetAssetRefCount sets the asset's ref_count to expected if its reference still holds the
+// observed count, or, when observed is nil, creates the reference if there still is none. It
+// reports whether the count was set, so a reference changed meanwhile is left alone.
+func (r *assetReferenceRepo) SetAssetRefCount(ctx context.Context, projectID uuid.UUID, asset model.Asset, observed *int, expected int) (bool, error) {
+	if projectID == uuid.Nil {
+		return false, fmt.Errorf("SetAssetRefCount: project_id is required")
+	}
+	if asset.SHA256 == "" {
+		return false, fmt.Errorf("SetAssetRefCount: asset.sha256 is required")
+	}
+	if expected < 0 {
+		return false, fmt.Errorf("SetAssetRefCount: negative ref count %d", expected)
+	}
+
+	now := time.Now()
+	db := r.db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true})
+	if observed == nil {
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&model.AssetReference{
+			ProjectID:        projectID,
+			SHA256:           asset.SHA256,
+			S3Key:            asset.S3Key,
+			RefCount:         expected,
+			AssetMeta:        datatypes.NewJSONType(asset),
+			LastReferencedAt: now,
+		})
+		if res.Error != nil {
+			return false, fmt.Errorf("create asset reference: %w", res.Error)
+		}
+		return res.RowsAffected > 0, nil
+	}
+
+	updates := map[string]any{"ref_count": expected, "updated_at": now}
+	if expected > 0 {
+		updates["last_referenced_at"] = now
+	}
+	res := db.Model(&model.AssetReference{}).
+		Where("project_id = ? AND sha256 = ? AND ref_count = ?", projectID, asset.SHA256, *observed).
+		UpdateColumns(updates)
+	if res.Error != nil {
+		return false, fmt.Errorf("set asset reference count: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteUnreferencedAsset deletes the S3 object of an asset the project holds no reference
+// to, such as the file of an upload that was never attached, and reports whether it did. An
+// asset with a reference, even one of count zero left to the garbage collector, is kept.
+// The deletion holds a placeholder reference, so a reference taken meanwhile waits for it.
+func (r *assetReferenceRepo) DeleteUnreferencedAsset(ctx context.Context, projectID uuid.UUID, asset model.Asset) (bool, error) {
+	if projectID == uuid.Nil {
+		return false, fmt.Errorf("DeleteUnreferencedAsset: project_id is required")
+	}
+	if asset.SHA256 == "" || asset.S3Key == "" {
+		return false, fmt.Errorf("DeleteUnreferencedAsset: asset.sha256 and asset.s3_key are required")
+	}
+
+	deleted := false
+	err := r.db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&model.AssetReference{
+			ProjectID:        projectID,
+			SHA256:           asset.SHA256,
+			S3Key:            asset.S3Key,
+			AssetMeta:        datatypes.NewJSONType(asset),
+			LastReferencedAt: time.Now(),
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Where("project_id = ? AND sha256 = ?", projectID, asset.SHA256).
+			Delete(&model.AssetReference{}).Error; err != nil {
+			return err
+		}
+		if err := r.s3.DeleteObject(ctx, asset.S3Key); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete unreferenced asset: %w", err)
+	}
+	return deleted, nil
+}
+
+// assetIndex serves blob.AssetIndex from asset_references, whose (project_id, sha256) unique
+// index makes each lookup a single row read.
+type assetIndex struct {
+	db *gorm.DB
+}
+
+func NewAssetIndex(db *gorm.DB) blob.AssetIndex {
+	return &assetIndex{db: db}
+}
+
+// LookupS3Key only returns content that is still referenced. An orphaned row may be collected
+// at any moment, deleting its object, so an upload must not be deduplicated against it.
+func (x *assetIndex) LookupS3Key(ctx context.Context, projectID uuid.UUID, sha256 string) (string, error) {
+	var keys []string
+	err := x.db.WithContext(ctx).
+		Model(&model.AssetReference{}).
+		Where("project_id = ? AND sha256 = ? AND s3_key != '' AND ref_count > 0", projectID, sha256).
+		Limit(1).
+		Pluck("s3_key", &keys).Error
+	if err != nil {
+		return "", fmt.Errorf("lookup asset key: %w", err)
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+	return keys[0], nil
+}
+
+func (x *assetIndex) RepairS3Key(ctx context.Context, projectID uuid.UUID, sha256 string, key string) error {
+	return x.db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true}).
+		Model(&model.AssetReference{}).
+		Where("project_id = ? AND sha256 = ?", projectID, sha256).
+		UpdateColumns(map[string]any{
+			"s3_key":     key,
+			"asset_meta": gorm.Expr("jsonb_set(asset_meta, '{s3_key}', to_jsonb(?::text))", key),
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -3,11 +3,15 @@ package repo
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
 	"github.com/memodb-io/Acontext/internal/modules/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -180,3 +184,218 @@ func TestAssetReferenceRepo_IncrementVsBatchIncrement(t *testing.T) {
 		db.Where("project_id = ? AND sha256 = ?", projectID, shaBatch).Delete(&model.AssetReference{})
 	})
 }
+
+func TestAssetIndex_LookupAndRepair(t *testing.T) {
+	db := setupAssetRefTestDB(t)
+	if db == nil {
+		return
+	}
+
+	refs := NewAssetReferenceRepo(db, nil)
+	index := NewAssetIndex(db)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_asset_idx_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_asset_idx",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupAssetRefTestDB(t, db, projectID)
+
+	sha := "dddd" + uuid.New().String()[:60]
+	key, err := index.LookupS3Key(ctx, projectID, sha)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	asset := model.Asset{SHA256: sha, S3Key: "disks/" + projectID.String() + "/old.txt", Bucket: "test"}
+	require.NoError(t, refs.IncrementAssetRef(ctx, projectID, asset))
+
+	key, err = index.LookupS3Key(ctx, projectID, sha)
+	require.NoError(t, err)
+	assert.Equal(t, asset.S3Key, key)
+
+	// Another project never sees this project's content
+	key, err = index.LookupS3Key(ctx, uuid.New(), sha)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	repaired := "disks/" + projectID.String() + "/new.txt"
+	require.NoError(t, index.RepairS3Key(ctx, projectID, sha, repaired))
+
+	var ref model.AssetReference
+	require.NoError(t, db.Where("project_id = ? AND sha256 = ?", projectID, sha).First(&ref).Error)
+	assert.Equal(t, repaired, ref.S3Key)
+	assert.Equal(t, repaired, ref.AssetMeta.Data().S3Key)
+	assert.Equal(t, 1, ref.RefCount)
+
+	// Orphaned content awaits collection and is never offered for deduplication
+	require.NoError(t, db.Model(&model.AssetReference{}).Where("project_id = ? AND sha256 = ?", projectID, sha).Update("ref_count", 0).Error)
+	key, err = index.LookupS3Key(ctx, projectID, sha)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+}
+
+func TestAssetReferenceRepo_OrphanedAssetRefs(t *testing.T) {
+	db := setupAssetRefTestDB(t)
+	if db == nil {
+		return
+	}
+
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	refs := NewAssetReferenceRepo(db, store)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_asset_gc_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_asset_gc",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupAssetRefTestDB(t, db, projectID)
+
+	old := time.Now().Add(-2 * time.Hour)
+	newRef := func(name string, refCount int, lastReferenced time.Time) model.AssetReference {
+		asset, err := store.UploadFileDirect(ctx, "assets/"+projectID.String()+"/"+name, []byte(name), "text/plain", nil)
+		require.NoError(t, err)
+		asset.SHA256 = "eeee" + uuid.New().String()[:60]
+		ref := model.AssetReference{
+			ProjectID:        projectID,
+			SHA256:           asset.SHA256,
+			S3Key:            asset.S3Key,
+			RefCount:         refCount,
+			AssetMeta:        datatypes.NewJSONType(*asset),
+			LastReferencedAt: lastReferenced,
+		}
+		require.NoError(t, db.Create(&ref).Error)
+		require.NoError(t, db.Model(&ref).UpdateColumn("updated_at", lastReferenced).Error)
+		return ref
+	}
+	orphan := newRef("orphan.txt", 0, old)
+	recent := newRef("recent.txt", 0, time.Now())
+	live := newRef("live.txt", 1, old)
+
+	before := time.Now().Add(-time.Hour)
+	listed, err := refs.ListOrphanedAssetRefs(ctx, &projectID, before, uuid.Nil, 10)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, orphan.ID, listed[0].ID)
+
+	listed, err = refs.ListOrphanedAssetRefs(ctx, &projectID, before, orphan.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	deleted, err := refs.DeleteOrphanedAssetRefs(ctx, []uuid.UUID{orphan.ID, recent.ID, live.ID}, before)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, orphan.S3Key, deleted[0].S3Key)
+
+	_, err = store.Head(ctx, orphan.S3Key)
+	assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+	for _, kept := range []model.AssetReference{recent, live} {
+		_, err = store.Head(ctx, kept.S3Key)
+		assert.NoError(t, err)
+		assert.NoError(t, db.First(&model.AssetReference{}, "id = ?", kept.ID).Error)
+	}
+}
+
+func TestAssetReferenceRepo_DeleteUnreferencedAsset(t *testing.T) {
+	db := setupAssetRefTestDB(t)
+	if db == nil {
+		return
+	}
+
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	refs := NewAssetReferenceRepo(db, store)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_unreferenced_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_unreferenced",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupAssetRefTestDB(t, db, projectID)
+
+	unattached, err := store.UploadBytes(ctx, "disks/"+projectID.String(), "unattached.txt", []byte("unattached"), nil)
+	require.NoError(t, err)
+	attached, err := store.UploadBytes(ctx, "disks/"+projectID.String(), "attached.txt", []byte("attached"), nil)
+	require.NoError(t, err)
+	require.NoError(t, refs.IncrementAssetRef(ctx, projectID, *attached))
+
+	deleted, err := refs.DeleteUnreferencedAsset(ctx, projectID, *unattached)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = store.Head(ctx, unattached.S3Key)
+	assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+	var count int64
+	require.NoError(t, db.Model(&model.AssetReference{}).Where("project_id = ? AND sha256 = ?", projectID, unattached.SHA256).Count(&count).Error)
+	assert.Zero(t, count, "the placeholder reference is removed")
+
+	deleted, err = refs.DeleteUnreferencedAsset(ctx, projectID, *attached)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	_, err = store.Head(ctx, attached.S3Key)
+	assert.NoError(t, err)
+}
+
+func TestAssetReferenceRepo_SetAssetRefCount(t *testing.T) {
+	db := setupAssetRefTestDB(t)
+	if db == nil {
+		return
+	}
+
+	refs := NewAssetReferenceRepo(db, nil)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_asset_fix_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_asset_fix",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupAssetRefTestDB(t, db, projectID)
+
+	asset := model.Asset{SHA256: "ffff" + uuid.New().String()[:60], S3Key: "assets/" + projectID.String() + "/a.txt"}
+	count := func() int {
+		var ref model.AssetReference
+		require.NoError(t, db.Where("project_id = ? AND sha256 = ?", projectID, asset.SHA256).First(&ref).Error)
+		return ref.RefCount
+	}
+
+	// A missing reference is created once
+	ok, err := refs.SetAssetRefCount(ctx, projectID, asset, nil, 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, count())
+	ok, err = refs.SetAssetRefCount(ctx, projectID, asset, nil, 5)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 2, count())
+
+	// An existing one is only set while it holds the observed count
+	stale := 1
+	ok, err = refs.SetAssetRefCount(ctx, projectID, asset, &stale, 3)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	observed := 2
+	ok, err = refs.SetAssetRefCount(ctx, projectID, asset, &observed, 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0, count())
+
+	_, err = refs.SetAssetRefCount(ctx, projectID, asset, &observed, -1)
+	assert.Error(t, err)
+}
@@ -54,14 +54,41 @@ func (r *diskRepo) Delete(ctx context.Context, projectID uuid.UUID, diskID uuid.
 			return fmt.Errorf("query artifacts: %w", err)
 		}
 
-		// Collect asset meta from all artifacts for batch decrement
-		assets := make([]model.Asset, 0, len(artifacts))
+		// Retained artifact versions hold references too and are removed by the same CASCADE
+		var versions []model.ArtifactVersion
+		if err := tx.Select("asset_meta").Where("disk_id = ?", diskID).Find(&versions).Error; err != nil {
+			return fmt.Errorf("query artifact versions: %w", err)
+		}
+
+		// Snapshot entries of the disk also hold references and go with the same CASCADE
+		var entries []model.DiskSnapshotEntry
+		if err := tx.Select("disk_snapshot_entries.asset_meta").
+			Joins("JOIN disk_snapshots ON disk_snapshots.id = disk_snapshot_entries.snapshot_id").
+			Where("disk_snapshots.disk_id = ?", diskID).
+			Find(&entries).Error; err != nil {
+			return fmt.Errorf("query snapshot entries: %w", err)
+		}
+
+		// Collect asset meta from all artifacts, versions and snapshot entries for batch decrement
+		assets := make([]model.Asset, 0, len(artifacts)+len(versions)+len(entries))
 		for _, artifact := range artifacts {
 			asset := artifact.AssetMeta.Data()
 			if asset.SHA256 != "" {
 				assets = append(assets, asset)
 			}
 		}
+		for _, version := range versions {
+			asset := version.AssetMeta.Data()
+			if asset.SHA256 != "" {
+				assets = append(assets, asset)
+			}
+		}
+		for _, entry := range entries {
+			asset := entry.AssetMeta.Data()
+			if asset.SHA256 != "" {
+				assets = append(assets, asset)
+			}
+		}
 
 		// Delete the disk (artifacts will be deleted automatically by CASCADE)
 		if err := tx.Delete(&disk).Error; err != nil {
@@ -0,0 +1,251 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DiskSnapshotRepo interface {
+	Create(ctx context.Context, snap *model.DiskSnapshot) error
+	Get(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) (*model.DiskSnapshot, error)
+	ListByDisk(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error)
+	Delete(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) error
+	Restore(ctx context.Context, snap *model.DiskSnapshot, policy ArtifactVersionPolicy) (*DiskSnapshotRestoreStats, error)
+	Clone(ctx context.Context, snap *model.DiskSnapshot, disk *model.Disk) error
+}
+
+// DiskSnapshotRestoreStats reports what a restore changed on the disk.
+type DiskSnapshotRestoreStats struct {
+	Written   int `json:"written"`   // artifacts created or overwritten with their snapshot content
+	Removed   int `json:"removed"`   // artifacts deleted because they were not in the snapshot
+	Unchanged int `json:"unchanged"` // artifacts already matching the snapshot
+}
+
+type diskSnapshotRepo struct {
+	db                 *gorm.DB
+	assetReferenceRepo AssetReferenceRepo
+}
+
+func NewDiskSnapshotRepo(db *gorm.DB, assetReferenceRepo AssetReferenceRepo) DiskSnapshotRepo {
+	return &diskSnapshotRepo{db: db, assetReferenceRepo: assetReferenceRepo}
+}
+
+// Create records snap and captures the current artifacts of snap.DiskID as its entries in a
+// single statement, so the manifest is a consistent view of the disk. Every entry takes a
+// reference on its asset; no content is copied.
+func (r *diskSnapshotRepo) Create(ctx context.Context, snap *model.DiskSnapshot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(snap).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO disk_snapshot_entries (snapshot_id, path, filename, sha256, meta, asset_meta)
+			SELECT ?, path, filename, COALESCE(asset_meta->>'sha256', ''), meta, asset_meta
+			FROM artifacts WHERE disk_id = ?`, snap.ID, snap.DiskID).Error; err != nil {
+			return fmt.Errorf("capture snapshot entries: %w", err)
+		}
+
+		assets, err := entryAssets(tx, snap.ID)
+		if err != nil {
+			return err
+		}
+		snap.ArtifactCount = len(assets)
+		for _, a := range assets {
+			snap.SizeB += a.SizeB
+		}
+		if err := tx.Model(snap).Updates(map[string]interface{}{
+			"artifact_count": snap.ArtifactCount,
+			"size_b":         snap.SizeB,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := r.assetReferenceRepo.BatchIncrementAssetRefs(ctx, snap.ProjectID, assets); err != nil {
+			return fmt.Errorf("increment asset references: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *diskSnapshotRepo) Get(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) (*model.DiskSnapshot, error) {
+	var snap model.DiskSnapshot
+	if err := r.db.WithContext(ctx).Where("id = ? AND project_id = ?", snapshotID, projectID).First(&snap).Error; err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (r *diskSnapshotRepo) ListByDisk(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	var snaps []*model.DiskSnapshot
+	err := r.db.WithContext(ctx).
+		Where("disk_id = ?", diskID).
+		Order("created_at DESC, id DESC").
+		Find(&snaps).Error
+	if err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// Delete removes the snapshot with its entries and releases their asset references.
+func (r *diskSnapshotRepo) Delete(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) error {
+	var released []model.Asset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var snap model.DiskSnapshot
+		if err := tx.Where("id = ? AND project_id = ?", snapshotID, projectID).First(&snap).Error; err != nil {
+			return err
+		}
+
+		var entries []model.DiskSnapshotEntry
+		if err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "asset_meta"}}}).
+			Where("snapshot_id = ?", snapshotID).
+			Delete(&entries).Error; err != nil {
+			return fmt.Errorf("delete snapshot entries: %w", err)
+		}
+		if err := tx.Delete(&snap).Error; err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			released = append(released, e.AssetMeta.Data())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, projectID, released); err != nil {
+		return fmt.Errorf("decrement asset references: %w", err)
+	}
+	return nil
+}
+
+// Restore makes the snapshot's disk match the snapshot in one transaction. Artifacts whose
+// content or meta differ are overwritten, so their current revision is kept as a version and
+// the restore itself can be undone file by file. Artifacts absent from the snapshot are
+// deleted together with their versions.
+func (r *diskSnapshotRepo) Restore(ctx context.Context, snap *model.DiskSnapshot, policy ArtifactVersionPolicy) (*DiskSnapshotRestoreStats, error) {
+	stats := &DiskSnapshotRestoreStats{}
+	var released []model.Asset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entries []model.DiskSnapshotEntry
+		if err := tx.Where("snapshot_id = ?", snap.ID).Find(&entries).Error; err != nil {
+			return fmt.Errorf("load snapshot entries: %w", err)
+		}
+
+		var current []model.Artifact
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("disk_id = ?", snap.DiskID).
+			Find(&current).Error; err != nil {
+			return fmt.Errorf("load artifacts: %w", err)
+		}
+		byLocation := make(map[string]*model.Artifact, len(current))
+		for i := range current {
+			byLocation[current[i].Path+current[i].Filename] = &current[i]
+		}
+
+		written := make([]model.Asset, 0)
+		for _, e := range entries {
+			key := e.Path + e.Filename
+			cur, ok := byLocation[key]
+			delete(byLocation, key)
+			if ok && cur.AssetMeta.Data().SHA256 == e.SHA256 && reflect.DeepEqual(cur.Meta, e.Meta) {
+				stats.Unchanged++
+				continue
+			}
+
+			a := &model.Artifact{
+				DiskID:    snap.DiskID,
+				Path:      e.Path,
+				Filename:  e.Filename,
+				Meta:      e.Meta,
+				AssetMeta: e.AssetMeta,
+			}
+			pruned, err := upsertArtifactTx(tx, a, true, policy)
+			if err != nil {
+				return err
+			}
+			released = append(released, pruned...)
+			written = append(written, e.AssetMeta.Data())
+			stats.Written++
+		}
+
+		for _, cur := range byLocation {
+			var versions []model.ArtifactVersion
+			if err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "asset_meta"}}}).
+				Where("disk_id = ? AND path = ? AND filename = ?", cur.DiskID, cur.Path, cur.Filename).
+				Delete(&versions).Error; err != nil {
+				return fmt.Errorf("delete artifact versions: %w", err)
+			}
+			if err := tx.Delete(cur).Error; err != nil {
+				return err
+			}
+			released = append(released, cur.AssetMeta.Data())
+			for _, v := range versions {
+				released = append(released, v.AssetMeta.Data())
+			}
+			stats.Removed++
+		}
+
+		if err := r.assetReferenceRepo.BatchIncrementAssetRefs(ctx, snap.ProjectID, written); err != nil {
+			return fmt.Errorf("increment asset references: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(released) > 0 {
+		if err := r.assetReferenceRepo.BatchDecrementAssetRefs(ctx, snap.ProjectID, released); err != nil {
+			return stats, fmt.Errorf("decrement asset references: %w", err)
+		}
+	}
+	return stats, nil
+}
+
+// Clone creates disk and fills it with the artifacts captured by the snapshot.
+func (r *diskSnapshotRepo) Clone(ctx context.Context, snap *model.DiskSnapshot, disk *model.Disk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(disk).Error; err != nil {
+			return fmt.Errorf("create disk: %w", err)
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO artifacts (disk_id, path, filename, meta, asset_meta)
+			SELECT ?, path, filename, meta, asset_meta
+			FROM disk_snapshot_entries WHERE snapshot_id = ?`, disk.ID, snap.ID).Error; err != nil {
+			return fmt.Errorf("copy snapshot entries: %w", err)
+		}
+
+		assets, err := entryAssets(tx, snap.ID)
+		if err != nil {
+			return err
+		}
+		if err := r.assetReferenceRepo.BatchIncrementAssetRefs(ctx, disk.ProjectID, assets); err != nil {
+			return fmt.Errorf("increment asset references: %w", err)
+		}
+		return nil
+	})
+}
+
+// entryAssets returns the assets referenced by the entries of a snapshot.
+func entryAssets(tx *gorm.DB, snapshotID uuid.UUID) ([]model.Asset, error) {
+	var entries []model.DiskSnapshotEntry
+	if err := tx.Select("asset_meta").Where("snapshot_id = ?", snapshotID).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("load snapshot entries: %w", err)
+	}
+	assets := make([]model.Asset, 0, len(entries))
+	for _, e := range entries {
+		assets = append(assets, e.AssetMeta.Data())
+	}
+	return assets, nil
+}
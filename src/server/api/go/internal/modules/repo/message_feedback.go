@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/gorm"
+)
+
+type MessageFeedbackRepo interface {
+	Create(ctx context.Context, fb *model.MessageFeedback) error
+	ListWithCursor(ctx context.Context, projectID uuid.UUID, filter MessageFeedbackFilter, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.MessageFeedback, error)
+	Summarize(ctx context.Context, projectID uuid.UUID, filter MessageFeedbackFilter) (*MessageFeedbackSummary, error)
+}
+
+// MessageFeedbackFilter holds the optional filters for listing and summarizing feedback.
+// Zero values mean "no filter".
+type MessageFeedbackFilter struct {
+	SessionID      *uuid.UUID
+	MessageID      *uuid.UUID
+	UserIdentifier string
+	Thumbs         string   // one of model.FeedbackThumbs*
+	Labels         []string // only feedback carrying all of these labels
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+}
+
+// MessageFeedbackSummary aggregates the feedback matched by a filter.
+type MessageFeedbackSummary struct {
+	Count        int64            `json:"count"`
+	MessageCount int64            `json:"message_count"`
+	ThumbsUp     int64            `json:"thumbs_up"`
+	ThumbsDown   int64            `json:"thumbs_down"`
+	ScoreCount   int64            `json:"score_count"`
+	AvgScore     *float64         `json:"avg_score"`
+	LabelCounts  map[string]int64 `json:"label_counts"`
+}
+
+type messageFeedbackRepo struct {
+	db *gorm.DB
+}
+
+func NewMessageFeedbackRepo(db *gorm.DB) MessageFeedbackRepo {
+	return &messageFeedbackRepo{db: db}
+}
+
+func (r *messageFeedbackRepo) Create(ctx context.Context, fb *model.MessageFeedback) error {
+	return r.db.WithContext(ctx).Create(fb).Error
+}
+
+// scoped applies the project scope and filter conditions to a query on message_feedback.
+func (r *messageFeedbackRepo) scoped(ctx context.Context, projectID uuid.UUID, filter MessageFeedbackFilter) (*gorm.DB, error) {
+	q := r.db.WithContext(ctx).Model(&model.MessageFeedback{}).Where("message_feedback.project_id = ?", projectID)
+
+	if filter.SessionID != nil {
+		q = q.Where("message_feedback.session_id = ?", *filter.SessionID)
+	}
+	if filter.MessageID != nil {
+		q = q.Where("message_feedback.message_id = ?", *filter.MessageID)
+	}
+	if filter.UserIdentifier != "" {
+		q = q.Joins("JOIN users ON users.id = message_feedback.user_id").
+			Where("users.identifier = ?", filter.UserIdentifier)
+	}
+	if filter.Thumbs != "" {
+		q = q.Where("message_feedback.thumbs = ?", filter.Thumbs)
+	}
+	if len(filter.Labels) > 0 {
+		jsonBytes, err := json.Marshal(filter.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("marshal labels: %w", err)
+		}
+		q = q.Where("message_feedback.labels @> ?", string(jsonBytes))
+	}
+	if filter.CreatedAfter != nil {
+		q = q.Where("message_feedback.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		q = q.Where("message_feedback.created_at < ?", *filter.CreatedBefore)
+	}
+	return q, nil
+}
+
+func (r *messageFeedbackRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, filter MessageFeedbackFilter, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.MessageFeedback, error) {
+	q, err := r.scoped(ctx, projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !afterCreatedAt.IsZero() && afterID != uuid.Nil {
+		comparisonOp := ">"
+		if timeDesc {
+			comparisonOp = "<"
+		}
+		q = q.Where(
+			"(message_feedback.created_at "+comparisonOp+" ?) OR (message_feedback.created_at = ? AND message_feedback.id "+comparisonOp+" ?)",
+			afterCreatedAt, afterCreatedAt, afterID,
+		)
+	}
+
+	orderBy := "message_feedback.created_at ASC, message_feedback.id ASC"
+	if timeDesc {
+		orderBy = "message_feedback.created_at DESC, message_feedback.id DESC"
+	}
+
+	var items []model.MessageFeedback
+	return items, q.Select("message_feedback.*").Order(orderBy).Limit(limit).Find(&items).Error
+}
+
+// Summarize computes counts, thumbs totals, the average score and per-label counts in the database.
+func (r *messageFeedbackRepo) Summarize(ctx context.Context, projectID uuid.UUID, filter MessageFeedbackFilter) (*MessageFeedbackSummary, error) {
+	q, err := r.scoped(ctx, projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var row struct {
+		Count        int64
+		MessageCount int64
+		ThumbsUp     int64
+		ThumbsDown   int64
+		ScoreCount   int64
+		AvgScore     *float64
+	}
+	err = q.Select(`COUNT(*) AS count,
+		COUNT(DISTINCT message_feedback.message_id) AS message_count,
+		COUNT(*) FILTER (WHERE message_feedback.thumbs = ?) AS thumbs_up,
+		COUNT(*) FILTER (WHERE message_feedback.thumbs = ?) AS thumbs_down,
+		COUNT(message_feedback.score) AS score_count,
+		AVG(message_feedback.score) AS avg_score`, model.FeedbackThumbsUp, model.FeedbackThumbsDown).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	q, err = r.scoped(ctx, projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var labels []struct {
+		Label string
+		Count int64
+	}
+	err = q.Select("l.label AS label, COUNT(*) AS count").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(message_feedback.labels) AS l(label)").
+		Group("l.label").
+		Scan(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := &MessageFeedbackSummary{
+		Count:        row.Count,
+		MessageCount: row.MessageCount,
+		ThumbsUp:     row.ThumbsUp,
+		ThumbsDown:   row.ThumbsDown,
+		ScoreCount:   row.ScoreCount,
+		AvgScore:     row.AvgScore,
+		LabelCounts:  make(map[string]int64, len(labels)),
+	}
+	for _, l := range labels {
+		out.LabelCounts[l.Label] = l.Count
+	}
+	return out, nil
+}
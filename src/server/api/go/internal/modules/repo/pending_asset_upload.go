@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PendingAssetUploadRepo interface {
+	// Enqueue stores uploads not stored yet. An upload whose key is already queued is kept,
+	// only made pending again and rescheduled: keys are content-addressed.
+	Enqueue(ctx context.Context, uploads []*model.PendingAssetUpload) error
+	// Claim returns up to limit pending uploads due for an attempt and reschedules them to
+	// lease from now, so other workers skip them while they are attempted.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.PendingAssetUpload, error)
+	// Reschedule saves the outcome of a failed attempt.
+	Reschedule(ctx context.Context, u *model.PendingAssetUpload) error
+	// Delete removes an upload once it is stored.
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByKey(ctx context.Context, s3Key string) (*model.PendingAssetUpload, error)
+	// ListBySession lists the uploads of a session's messages, without their content.
+	ListBySession(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error)
+}
+
+type pendingAssetUploadRepo struct {
+	db *gorm.DB
+}
+
+func NewPendingAssetUploadRepo(db *gorm.DB) PendingAssetUploadRepo {
+	return &pendingAssetUploadRepo{db: db}
+}
+
+func (r *pendingAssetUploadRepo) Enqueue(ctx context.Context, uploads []*model.PendingAssetUpload) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "s3_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "attempts", "last_error", "next_attempt_at", "updated_at"}),
+	}).Omit(clause.Associations).Create(uploads).Error
+}
+
+func (r *pendingAssetUploadRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.PendingAssetUpload, error) {
+	now := time.Now()
+	var uploads []*model.PendingAssetUpload
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE pending_asset_uploads SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM pending_asset_uploads
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, model.PendingAssetUploadPending, now, limit,
+	).Scan(&uploads).Error
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+func (r *pendingAssetUploadRepo) Reschedule(ctx context.Context, u *model.PendingAssetUpload) error {
+	return r.db.WithContext(ctx).Model(&model.PendingAssetUpload{}).
+		Where("id = ?", u.ID).
+		Updates(map[string]interface{}{
+			"status":          u.Status,
+			"attempts":        u.Attempts,
+			"last_error":      u.LastError,
+			"next_attempt_at": u.NextAttemptAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *pendingAssetUploadRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.PendingAssetUpload{}).Error
+}
+
+func (r *pendingAssetUploadRepo) GetByKey(ctx context.Context, s3Key string) (*model.PendingAssetUpload, error) {
+	var u model.PendingAssetUpload
+	if err := r.db.WithContext(ctx).Where("s3_key = ?", s3Key).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *pendingAssetUploadRepo) ListBySession(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error) {
+	var uploads []*model.PendingAssetUpload
+	err := r.db.WithContext(ctx).
+		Omit("content").
+		Where("project_id = ? AND session_id = ?", projectID, sessionID).
+		Order("created_at ASC").
+		Find(&uploads).Error
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
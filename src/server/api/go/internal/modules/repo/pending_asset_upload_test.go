@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestPendingAssetUploadRepo(t *testing.T) {
+	db := setupAssetRefTestDB(t)
+	if db == nil {
+		return
+	}
+	require.NoError(t, db.AutoMigrate(&model.PendingAssetUpload{}))
+
+	uploads := NewPendingAssetUploadRepo(db)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:               projectID,
+		SecretKeyHMAC:    "test_hmac_outbox_" + projectID.String()[:8],
+		SecretKeyHashPHC: "test_hash_outbox",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer func() {
+		db.Exec("DELETE FROM pending_asset_uploads WHERE project_id = ?", projectID)
+		db.Exec("DELETE FROM projects WHERE id = ?", projectID)
+	}()
+
+	sessionID := uuid.New()
+	newUpload := func(name string, next time.Time) *model.PendingAssetUpload {
+		key := "parts/" + projectID.String() + "/" + name
+		return &model.PendingAssetUpload{
+			ProjectID:     projectID,
+			SessionID:     sessionID,
+			S3Key:         key,
+			AssetMeta:     datatypes.NewJSONType(model.Asset{S3Key: key}),
+			Content:       []byte(name),
+			Metadata:      datatypes.NewJSONType(map[string]string{}),
+			Status:        model.PendingAssetUploadPending,
+			NextAttemptAt: next,
+		}
+	}
+	now := time.Now()
+	due := newUpload("due.json", now.Add(-time.Second))
+	later := newUpload("later.json", now.Add(time.Hour))
+	require.NoError(t, uploads.Enqueue(ctx, []*model.PendingAssetUpload{due, later}))
+
+	// Only due uploads are claimed, and only once per lease
+	claimed, err := uploads.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.S3Key, claimed[0].S3Key)
+	assert.Equal(t, []byte("due.json"), claimed[0].Content)
+	claimed, err = uploads.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// A failed one is no longer claimed, until the same key is stored again
+	got, err := uploads.GetByKey(ctx, due.S3Key)
+	require.NoError(t, err)
+	got.Status = model.PendingAssetUploadFailed
+	got.Attempts = 3
+	got.LastError = "boom"
+	got.NextAttemptAt = now.Add(-time.Second)
+	require.NoError(t, uploads.Reschedule(ctx, got))
+	claimed, err = uploads.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	again := newUpload("due.json", now.Add(-time.Second))
+	again.SessionID = uuid.New()
+	require.NoError(t, uploads.Enqueue(ctx, []*model.PendingAssetUpload{again}))
+	got, err = uploads.GetByKey(ctx, due.S3Key)
+	require.NoError(t, err)
+	assert.Equal(t, model.PendingAssetUploadPending, got.Status)
+	assert.Zero(t, got.Attempts)
+	assert.Equal(t, sessionID, got.SessionID)
+
+	listed, err := uploads.ListBySession(ctx, projectID, sessionID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Empty(t, listed[0].Content)
+
+	require.NoError(t, uploads.Delete(ctx, got.ID))
+	_, err = uploads.GetByKey(ctx, due.S3Key)
+	assert.Error(t, err)
+}
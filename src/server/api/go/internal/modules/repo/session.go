@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,8 +31,8 @@ type SessionRepo interface {
 	Update(ctx context.Context, s *model.Session) error
 	Get(ctx context.Context, s *model.Session) (*model.Session, error)
 	GetDisableTaskTracking(ctx context.Context, sessionID uuid.UUID) (bool, error)
-	ListWithCursor(ctx context.Context, projectID uuid.UUID, userIdentifier string, filterByConfigs map[string]interface{}, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error)
-	CreateMessageWithAssets(ctx context.Context, msg *model.Message) error
+	ListWithCursor(ctx context.Context, projectID uuid.UUID, filter SessionListFilter, afterSortValue time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error)
+	CreateMessageWithAssets(ctx context.Context, msg *model.Message, stats *model.SessionStats) error
 	ListBySessionWithCursor(ctx context.Context, sessionID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Message, error)
 	ListAllMessagesBySession(ctx context.Context, sessionID uuid.UUID) ([]model.Message, error)
 	GetObservingStatus(ctx context.Context, sessionID string) (*model.MessageObservingStatus, error)
@@ -41,6 +42,20 @@ type SessionRepo interface {
 	CopySession(ctx context.Context, sessionID uuid.UUID, userKEK []byte) (*CopySessionResult, error)
 	HasUnfinishedMessages(ctx context.Context, sessionID uuid.UUID) (bool, error)
 	HasFailedMessages(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	GetStats(ctx context.Context, sessionID uuid.UUID) (*model.SessionStats, error)
+	BackfillStats(ctx context.Context, sessionID uuid.UUID, compute func(msgs []model.Message) (*model.SessionStats, error)) (*model.SessionStats, error)
+	CountTasksByStatus(ctx context.Context, sessionID uuid.UUID) (map[string]int64, error)
+	UpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error)
+	SetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error)
+	SetTitleIfEmpty(ctx context.Context, sessionID uuid.UUID, title string) error
+	UpdateInfo(ctx context.Context, sessionID uuid.UUID, update SessionInfoUpdate) error
+}
+
+// SessionInfoUpdate holds the user-facing session fields to overwrite; nil fields are left unchanged.
+type SessionInfoUpdate struct {
+	Title    *string
+	Tags     *[]string
+	Archived *bool
 }
 
 // CopySessionResult contains the result of a copy operation
@@ -52,11 +67,11 @@ type CopySessionResult struct {
 type sessionRepo struct {
 	db                 *gorm.DB
 	assetReferenceRepo AssetReferenceRepo
-	s3                 *blob.S3Deps
+	s3                 blob.BlobStore
 	log                *zap.Logger
 }
 
-func NewSessionRepo(db *gorm.DB, assetReferenceRepo AssetReferenceRepo, s3 *blob.S3Deps, log *zap.Logger) SessionRepo {
+func NewSessionRepo(db *gorm.DB, assetReferenceRepo AssetReferenceRepo, s3 blob.BlobStore, log *zap.Logger) SessionRepo {
 	return &sessionRepo{
 		db:                 db,
 		assetReferenceRepo: assetReferenceRepo,
@@ -66,7 +81,13 @@ func NewSessionRepo(db *gorm.DB, assetReferenceRepo AssetReferenceRepo, s3 *blob
 }
 
 func (r *sessionRepo) Create(ctx context.Context, s *model.Session) error {
-	return r.db.WithContext(ctx).Create(s).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		// Start with an empty stats row so message inserts can increment it
+		return tx.Create(&model.SessionStats{SessionID: s.ID, ProjectID: s.ProjectID}).Error
+	})
 }
 
 func (r *sessionRepo) Delete(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) error {
@@ -148,50 +169,270 @@ func (r *sessionRepo) GetDisableTaskTracking(ctx context.Context, sessionID uuid
 	return result.DisableTaskTracking, err
 }
 
-func (r *sessionRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, userIdentifier string, filterByConfigs map[string]interface{}, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error) {
-	q := r.db.WithContext(ctx).Where("sessions.project_id = ?", projectID)
+// Session list sort keys accepted by SessionListFilter.SortBy
+const (
+	SessionSortCreatedAt     = "created_at"
+	SessionSortUpdatedAt     = "updated_at"
+	SessionSortLastMessageAt = "last_message_at"
+)
+
+// Config condition operators accepted by ConfigCondition.Op
+const (
+	ConfigOpExists    = "exists"
+	ConfigOpNotExists = "not_exists"
+	ConfigOpEq        = "eq"
+	ConfigOpNe        = "ne"
+	ConfigOpGt        = "gt"
+	ConfigOpGte       = "gte"
+	ConfigOpLt        = "lt"
+	ConfigOpLte       = "lte"
+)
+
+// lastMessageAtExpr is the correlated subquery yielding a session's latest message time.
+// It is served by the idx_session_created index on messages(session_id, created_at).
+const lastMessageAtExpr = "(SELECT MAX(m.created_at) FROM messages m WHERE m.session_id = sessions.id)"
+
+// messageCountExpr yields a session's message count from its stats row, counting the messages
+// of sessions whose stats have not been backfilled yet.
+const messageCountExpr = "COALESCE(session_stats.message_count, (SELECT COUNT(*) FROM messages m WHERE m.session_id = sessions.id))"
+
+// ConfigCondition is a single predicate on a top-level key of Session.Configs.
+// Comparison operators compare numerically when Value is a number and lexically when it is a string.
+type ConfigCondition struct {
+	Key   string      `json:"key"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// SessionListFilter holds the optional filters and sort key for ListWithCursor.
+// Zero values mean "no filter".
+type SessionListFilter struct {
+	UserIdentifier   string
+	FilterByConfigs  map[string]interface{} // JSONB containment (@>)
+	ConfigConditions []ConfigCondition
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	UpdatedAfter     *time.Time
+	UpdatedBefore    *time.Time
+	HasMessages      *bool
+	MinMessages      *int
+	MaxMessages      *int
+	TaskStatus       string     // only sessions with at least one task in this status
+	LearningSpaceID  *uuid.UUID // only sessions that belong to this learning space
+	InLearningSpace  *bool      // only sessions that do (or do not) belong to any learning space
+	Tags             []string   // only sessions carrying all of these tags
+	TitleContains    string     // case-insensitive substring match on title
+	Archived         *bool
+	SortBy           string // one of SessionSort*; defaults to created_at
+}
+
+// SortExpr returns the SQL expression used for ordering and cursor comparison.
+func (f SessionListFilter) SortExpr() string {
+	switch f.SortBy {
+	case SessionSortUpdatedAt:
+		return "sessions.updated_at"
+	case SessionSortLastMessageAt:
+		// Sessions without messages fall back to their creation time
+		return "COALESCE(" + lastMessageAtExpr + ", sessions.created_at)"
+	default:
+		return "sessions.created_at"
+	}
+}
+
+// SortValue returns the value of the sort key for s, matching SortExpr.
+func (f SessionListFilter) SortValue(s model.Session) time.Time {
+	switch f.SortBy {
+	case SessionSortUpdatedAt:
+		return s.UpdatedAt
+	case SessionSortLastMessageAt:
+		if s.LastMessageAt != nil {
+			return *s.LastMessageAt
+		}
+		return s.CreatedAt
+	default:
+		return s.CreatedAt
+	}
+}
+
+// Validate checks that the condition has a key, a known operator and a usable value.
+func (c ConfigCondition) Validate() error {
+	if c.Key == "" {
+		return errors.New("config condition key is empty")
+	}
+	switch c.Op {
+	case ConfigOpExists, ConfigOpNotExists, ConfigOpEq, ConfigOpNe:
+		return nil
+	case ConfigOpGt, ConfigOpGte, ConfigOpLt, ConfigOpLte:
+		switch c.Value.(type) {
+		case float64, int, int64, string:
+			return nil
+		}
+		return fmt.Errorf("config condition %q on key %q requires a number or string value", c.Op, c.Key)
+	default:
+		return fmt.Errorf("unsupported config condition op %q", c.Op)
+	}
+}
+
+// applyConfigCondition adds a single ConfigCondition predicate to q.
+func applyConfigCondition(q *gorm.DB, c ConfigCondition) (*gorm.DB, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch c.Op {
+	case ConfigOpExists:
+		return q.Where("sessions.configs -> ? IS NOT NULL", c.Key), nil
+	case ConfigOpNotExists:
+		return q.Where("sessions.configs -> ? IS NULL", c.Key), nil
+	case ConfigOpEq, ConfigOpNe:
+		jsonBytes, err := json.Marshal(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal config condition value: %w", err)
+		}
+		if c.Op == ConfigOpEq {
+			return q.Where("sessions.configs -> ? = ?::jsonb", c.Key, string(jsonBytes)), nil
+		}
+		return q.Where("sessions.configs -> ? IS DISTINCT FROM ?::jsonb", c.Key, string(jsonBytes)), nil
+	}
+
+	sqlOp := map[string]string{ConfigOpGt: ">", ConfigOpGte: ">=", ConfigOpLt: "<", ConfigOpLte: "<="}[c.Op]
+	if v, ok := c.Value.(string); ok {
+		return q.Where(
+			"(CASE WHEN jsonb_typeof(sessions.configs -> ?) = 'string' THEN sessions.configs ->> ? END) "+sqlOp+" ?",
+			c.Key, c.Key, v,
+		), nil
+	}
+	// CASE guards the cast so non-numeric values never raise an error
+	return q.Where(
+		"(CASE WHEN jsonb_typeof(sessions.configs -> ?) = 'number' THEN (sessions.configs ->> ?)::numeric END) "+sqlOp+" ?",
+		c.Key, c.Key, c.Value,
+	), nil
+}
+
+func (r *sessionRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, filter SessionListFilter, afterSortValue time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Session, error) {
+	q := r.db.WithContext(ctx).
+		Select("sessions.*, "+lastMessageAtExpr+" AS last_message_at").
+		Where("sessions.project_id = ?", projectID)
 
 	// Filter by user identifier if provided
-	if userIdentifier != "" {
+	if filter.UserIdentifier != "" {
 		q = q.Joins("JOIN users ON users.id = sessions.user_id").
-			Where("users.identifier = ?", userIdentifier)
+			Where("users.identifier = ?", filter.UserIdentifier)
 	}
 
 	// Apply configs filter if provided (non-nil and non-empty)
 	// Uses PostgreSQL JSONB containment operator @> for efficient filtering
-	if filterByConfigs != nil && len(filterByConfigs) > 0 {
+	if len(filter.FilterByConfigs) > 0 {
 		// CRITICAL: Use parameterized query to prevent SQL injection
-		jsonBytes, err := json.Marshal(filterByConfigs)
+		jsonBytes, err := json.Marshal(filter.FilterByConfigs)
 		if err != nil {
 			return nil, fmt.Errorf("marshal filter_by_configs: %w", err)
 		}
 		q = q.Where("sessions.configs @> ?", string(jsonBytes))
 	}
 
+	for _, c := range filter.ConfigConditions {
+		var err error
+		if q, err = applyConfigCondition(q, c); err != nil {
+			return nil, err
+		}
+	}
+
+	// Time range filters
+	if filter.CreatedAfter != nil {
+		q = q.Where("sessions.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		q = q.Where("sessions.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		q = q.Where("sessions.updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		q = q.Where("sessions.updated_at < ?", *filter.UpdatedBefore)
+	}
+
+	// Message presence and count filters
+	if filter.HasMessages != nil {
+		if *filter.HasMessages {
+			q = q.Where("EXISTS (SELECT 1 FROM messages m WHERE m.session_id = sessions.id)")
+		} else {
+			q = q.Where("NOT EXISTS (SELECT 1 FROM messages m WHERE m.session_id = sessions.id)")
+		}
+	}
+	if filter.MinMessages != nil || filter.MaxMessages != nil {
+		q = q.Joins("LEFT JOIN session_stats ON session_stats.session_id = sessions.id")
+	}
+	if filter.MinMessages != nil {
+		q = q.Where(messageCountExpr+" >= ?", *filter.MinMessages)
+	}
+	if filter.MaxMessages != nil {
+		q = q.Where(messageCountExpr+" <= ?", *filter.MaxMessages)
+	}
+
+	// Task status presence
+	if filter.TaskStatus != "" {
+		q = q.Where("EXISTS (SELECT 1 FROM tasks t WHERE t.session_id = sessions.id AND t.status = ?)", filter.TaskStatus)
+	}
+
+	// Learning space membership
+	if filter.LearningSpaceID != nil {
+		q = q.Where(
+			"EXISTS (SELECT 1 FROM learning_space_sessions lss WHERE lss.session_id = sessions.id AND lss.learning_space_id = ?)",
+			*filter.LearningSpaceID,
+		)
+	}
+	if filter.InLearningSpace != nil {
+		if *filter.InLearningSpace {
+			q = q.Where("EXISTS (SELECT 1 FROM learning_space_sessions lss WHERE lss.session_id = sessions.id)")
+		} else {
+			q = q.Where("NOT EXISTS (SELECT 1 FROM learning_space_sessions lss WHERE lss.session_id = sessions.id)")
+		}
+	}
+
+	// Title, tags and archive state
+	if len(filter.Tags) > 0 {
+		jsonBytes, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("marshal tags: %w", err)
+		}
+		q = q.Where("sessions.tags @> ?", string(jsonBytes))
+	}
+	if filter.TitleContains != "" {
+		q = q.Where("sessions.title ILIKE ?", "%"+escapeLike(filter.TitleContains)+"%")
+	}
+	if filter.Archived != nil {
+		q = q.Where("sessions.archived = ?", *filter.Archived)
+	}
+
+	sortExpr := filter.SortExpr()
+
 	// Apply cursor-based pagination filter if cursor is provided
-	if !afterCreatedAt.IsZero() && afterID != uuid.Nil {
+	if !afterSortValue.IsZero() && afterID != uuid.Nil {
 		// Determine comparison operator based on sort direction
 		comparisonOp := ">"
 		if timeDesc {
 			comparisonOp = "<"
 		}
 		q = q.Where(
-			"(sessions.created_at "+comparisonOp+" ?) OR (sessions.created_at = ? AND sessions.id "+comparisonOp+" ?)",
-			afterCreatedAt, afterCreatedAt, afterID,
+			"("+sortExpr+" "+comparisonOp+" ?) OR ("+sortExpr+" = ? AND sessions.id "+comparisonOp+" ?)",
+			afterSortValue, afterSortValue, afterID,
 		)
 	}
 
 	// Apply ordering based on sort direction
-	orderBy := "sessions.created_at ASC, sessions.id ASC"
+	orderBy := sortExpr + " ASC, sessions.id ASC"
 	if timeDesc {
-		orderBy = "sessions.created_at DESC, sessions.id DESC"
+		orderBy = sortExpr + " DESC, sessions.id DESC"
 	}
 
 	var sessions []model.Session
 	return sessions, q.Order(orderBy).Limit(limit).Find(&sessions).Error
 }
 
-func (r *sessionRepo) CreateMessageWithAssets(ctx context.Context, msg *model.Message) error {
+// CreateMessageWithAssets creates msg and, when stats is non-nil, merges the message's
+// stats contribution into the session's stats row within the same transaction.
+func (r *sessionRepo) CreateMessageWithAssets(ctx context.Context, msg *model.Message, stats *model.SessionStats) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// First get the message parent id in session
 		parent := model.Message{}
@@ -206,10 +447,37 @@ func (r *sessionRepo) CreateMessageWithAssets(ctx context.Context, msg *model.Me
 			return err
 		}
 
+		if stats != nil {
+			stats.FirstMessageAt = &msg.CreatedAt
+			stats.LastMessageAt = &msg.CreatedAt
+			if err := incrementStats(tx, *stats); err != nil {
+				return fmt.Errorf("increment session stats: %w", err)
+			}
+		}
+
 		return nil
 	})
 }
 
+// incrementStats merges delta into the session's stats row under a row lock.
+// Sessions created before stats existed have no row; the message is left to
+// BackfillStats, which cannot run until this transaction commits and so counts it.
+func incrementStats(tx *gorm.DB, delta model.SessionStats) error {
+	var current model.SessionStats
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("session_id = ?", delta.SessionID).
+		First(&current).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	current.Add(delta)
+	return tx.Save(&current).Error
+}
+
 func (r *sessionRepo) ListBySessionWithCursor(ctx context.Context, sessionID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.Message, error) {
 	q := r.db.WithContext(ctx).Where("session_id = ?", sessionID)
 
@@ -481,12 +749,27 @@ func (r *sessionRepo) CopySession(ctx context.Context, sessionID uuid.UUID, user
 			UserID:              originalSession.UserID,
 			DisableTaskTracking: originalSession.DisableTaskTracking,
 			Configs:             originalSession.Configs,
+			Title:               originalSession.Title,
+			Tags:                originalSession.Tags,
 		}
 		if err := tx.Create(&newSession).Error; err != nil {
 			return fmt.Errorf("failed to create new session: %w", err)
 		}
 		result.NewSessionID = newSession.ID
 
+		// Copy stats so the new session starts with the same aggregates.
+		// A missing source row is left missing and backfilled lazily for both sessions.
+		var stats model.SessionStats
+		if err := tx.Where("session_id = ?", sessionID).Limit(1).Find(&stats).Error; err != nil {
+			return fmt.Errorf("failed to get session stats: %w", err)
+		}
+		if stats.SessionID != uuid.Nil {
+			stats.SessionID = newSession.ID
+			if err := tx.Create(&stats).Error; err != nil {
+				return fmt.Errorf("failed to copy session stats: %w", err)
+			}
+		}
+
 		// Pre-assign new IDs so we can build the parent-ID mapping before inserting.
 		oldToNewMessageID := make(map[uuid.UUID]uuid.UUID, len(originalMessages))
 		for _, oldMsg := range originalMessages {
@@ -644,3 +927,144 @@ func (r *sessionRepo) HasFailedMessages(ctx context.Context, sessionID uuid.UUID
 	).Scan(&exists).Error
 	return exists, err
 }
+
+// GetStats returns the stats row for a session, or gorm.ErrRecordNotFound if none exists yet.
+func (r *sessionRepo) GetStats(ctx context.Context, sessionID uuid.UUID) (*model.SessionStats, error) {
+	var stats model.SessionStats
+	if err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).First(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// BackfillStats creates the stats row of a session that has none from compute over its full
+// message history, or returns the existing row. The session row is locked for the whole
+// backfill: storing a message takes a key-share lock on it through the messages foreign key,
+// so no message can land between listing the history and creating the row, and messages
+// stored afterwards find the row and increment it.
+func (r *sessionRepo) BackfillStats(ctx context.Context, sessionID uuid.UUID, compute func(msgs []model.Message) (*model.SessionStats, error)) (*model.SessionStats, error) {
+	var stats *model.SessionStats
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session model.Session
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", sessionID).First(&session).Error; err != nil {
+			return err
+		}
+
+		var existing model.SessionStats
+		if err := tx.Where("session_id = ?", sessionID).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.SessionID != uuid.Nil {
+			stats = &existing
+			return nil
+		}
+
+		var msgs []model.Message
+		if err := tx.Where("session_id = ?", sessionID).Order("created_at ASC, id ASC").Find(&msgs).Error; err != nil {
+			return err
+		}
+		computed, err := compute(msgs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(computed).Error; err != nil {
+			return err
+		}
+		stats = computed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// CountTasksByStatus returns the number of non-planning tasks per status for a session.
+func (r *sessionRepo) CountTasksByStatus(ctx context.Context, sessionID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("session_id = ? AND is_planning = false", sessionID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally (PostgreSQL's default escape is backslash).
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UpdateTags adds and removes tags on the given sessions of a project.
+// Tags are kept unique and sorted. Returns the number of sessions updated.
+func (r *sessionRepo) UpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error) {
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	addJSON, err := json.Marshal(append([]string{}, add...))
+	if err != nil {
+		return 0, fmt.Errorf("marshal tags: %w", err)
+	}
+	removeJSON, err := json.Marshal(append([]string{}, remove...))
+	if err != nil {
+		return 0, fmt.Errorf("marshal tags: %w", err)
+	}
+
+	res := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("project_id = ? AND id IN ?", projectID, sessionIDs).
+		Update("tags", gorm.Expr(
+			`(SELECT COALESCE(jsonb_agg(DISTINCT t.tag ORDER BY t.tag), '[]'::jsonb)
+			FROM jsonb_array_elements_text(sessions.tags || ?::jsonb) AS t(tag)
+			WHERE t.tag NOT IN (SELECT jsonb_array_elements_text(?::jsonb)))`,
+			string(addJSON), string(removeJSON),
+		))
+	return res.RowsAffected, res.Error
+}
+
+// SetArchived sets the archived flag on the given sessions of a project.
+// Returns the number of sessions updated.
+func (r *sessionRepo) SetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error) {
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("project_id = ? AND id IN ?", projectID, sessionIDs).
+		Update("archived", archived)
+	return res.RowsAffected, res.Error
+}
+
+// UpdateInfo overwrites the non-nil fields of update on a session.
+func (r *sessionRepo) UpdateInfo(ctx context.Context, sessionID uuid.UUID, update SessionInfoUpdate) error {
+	fields := map[string]interface{}{}
+	if update.Title != nil {
+		fields["title"] = *update.Title
+	}
+	if update.Tags != nil {
+		fields["tags"] = datatypes.NewJSONSlice(append([]string{}, *update.Tags...))
+	}
+	if update.Archived != nil {
+		fields["archived"] = *update.Archived
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.Session{}).Where("id = ?", sessionID).Updates(fields).Error
+}
+
+// SetTitleIfEmpty sets the session title unless one has already been set.
+func (r *sessionRepo) SetTitleIfEmpty(ctx context.Context, sessionID uuid.UUID, title string) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND title = ''", sessionID).
+		Update("title", title).Error
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/gorm"
+)
+
+// activeBulkJobStatuses are the statuses a bulk job may still leave
+var activeBulkJobStatuses = []string{model.BulkJobStatusPending, model.BulkJobStatusRunning}
+
+type SessionBulkJobRepo interface {
+	Create(ctx context.Context, job *model.SessionBulkJob) error
+	Get(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error)
+	SaveProgress(ctx context.Context, job *model.SessionBulkJob) (bool, error)
+	Heartbeat(ctx context.Context, jobID uuid.UUID) (bool, error)
+	MarkStale(ctx context.Context, jobID uuid.UUID, heartbeatBefore time.Time, reason string) (bool, error)
+}
+
+type sessionBulkJobRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionBulkJobRepo(db *gorm.DB) SessionBulkJobRepo {
+	return &sessionBulkJobRepo{db: db}
+}
+
+func (r *sessionBulkJobRepo) Create(ctx context.Context, job *model.SessionBulkJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *sessionBulkJobRepo) Get(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error) {
+	var job model.SessionBulkJob
+	if err := r.db.WithContext(ctx).Where("id = ? AND project_id = ?", jobID, projectID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SaveProgress persists the job's status, counters and results and refreshes its heartbeat.
+// It only writes while the stored job is still pending or running and reports false once the
+// job has reached a final status, e.g. because it was marked stale.
+func (r *sessionBulkJobRepo) SaveProgress(ctx context.Context, job *model.SessionBulkJob) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.SessionBulkJob{}).
+		Where("id = ? AND status IN ?", job.ID, activeBulkJobStatuses).
+		Updates(map[string]interface{}{
+			"status":       job.Status,
+			"succeeded":    job.Succeeded,
+			"failed":       job.Failed,
+			"results":      job.Results,
+			"error":        job.Error,
+			"finished_at":  job.FinishedAt,
+			"heartbeat_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Heartbeat refreshes the heartbeat of a pending or running job and reports false once the
+// job has reached a final status.
+func (r *sessionBulkJobRepo) Heartbeat(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.SessionBulkJob{}).
+		Where("id = ? AND status IN ?", jobID, activeBulkJobStatuses).
+		UpdateColumn("heartbeat_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// MarkStale fails a pending or running job whose heartbeat is older than heartbeatBefore.
+// The check and the update are a single statement, so a job whose worker is still
+// heartbeating is never failed.
+func (r *sessionBulkJobRepo) MarkStale(ctx context.Context, jobID uuid.UUID, heartbeatBefore time.Time, reason string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.SessionBulkJob{}).
+		Where("id = ? AND status IN ? AND heartbeat_at < ?", jobID, activeBulkJobStatuses, heartbeatBefore).
+		Updates(map[string]interface{}{
+			"status":      model.BulkJobStatusFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
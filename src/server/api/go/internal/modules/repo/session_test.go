@@ -434,6 +434,30 @@ func (m *MockAssetReferenceRepoForCopy) ListS3KeysByProject(ctx context.Context,
 	return nil, nil
 }
 
+func (m *MockAssetReferenceRepoForCopy) ListOrphanedAssetRefs(ctx context.Context, projectID *uuid.UUID, before time.Time, afterID uuid.UUID, limit int) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *MockAssetReferenceRepoForCopy) DeleteOrphanedAssetRefs(ctx context.Context, ids []uuid.UUID, before time.Time) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *MockAssetReferenceRepoForCopy) ListAssetRefsByProject(ctx context.Context, projectID uuid.UUID) ([]model.AssetReference, error) {
+	return nil, nil
+}
+
+func (m *MockAssetReferenceRepoForCopy) CountAssetUses(ctx context.Context, projectID uuid.UUID) ([]AssetUse, error) {
+	return nil, nil
+}
+
+func (m *MockAssetReferenceRepoForCopy) SetAssetRefCount(ctx context.Context, projectID uuid.UUID, asset model.Asset, observed *int, expected int) (bool, error) {
+	return false, nil
+}
+
+func (m *MockAssetReferenceRepoForCopy) DeleteUnreferencedAsset(ctx context.Context, projectID uuid.UUID, asset model.Asset) (bool, error) {
+	return false, nil
+}
+
 // TestSessionRepo_CopySession tests the CopySession method with comprehensive scenarios
 func TestSessionRepo_CopySession(t *testing.T) {
 	db := setupSessionTestDB(t)
@@ -1007,3 +1031,167 @@ func TestSessionRepo_CopySession(t *testing.T) {
 		assert.True(t, newSession.DisableTaskTracking)
 	})
 }
+
+func TestConfigCondition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cond    ConfigCondition
+		wantErr bool
+	}{
+		{name: "exists", cond: ConfigCondition{Key: "agent", Op: ConfigOpExists}},
+		{name: "not_exists", cond: ConfigCondition{Key: "agent", Op: ConfigOpNotExists}},
+		{name: "eq with object value", cond: ConfigCondition{Key: "agent", Op: ConfigOpEq, Value: map[string]interface{}{"name": "bot1"}}},
+		{name: "ne with nil value", cond: ConfigCondition{Key: "agent", Op: ConfigOpNe}},
+		{name: "gte with number", cond: ConfigCondition{Key: "priority", Op: ConfigOpGte, Value: float64(3)}},
+		{name: "lt with string", cond: ConfigCondition{Key: "version", Op: ConfigOpLt, Value: "2.0"}},
+		{name: "empty key", cond: ConfigCondition{Op: ConfigOpExists}, wantErr: true},
+		{name: "unknown op", cond: ConfigCondition{Key: "agent", Op: "like"}, wantErr: true},
+		{name: "gt without value", cond: ConfigCondition{Key: "priority", Op: ConfigOpGt}, wantErr: true},
+		{name: "lte with bool", cond: ConfigCondition{Key: "priority", Op: ConfigOpLte, Value: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cond.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSessionListFilter_SortValue(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	lastMsg := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	withMsg := model.Session{CreatedAt: created, UpdatedAt: updated, LastMessageAt: &lastMsg}
+	noMsg := model.Session{CreatedAt: created, UpdatedAt: updated}
+
+	assert.Equal(t, created, SessionListFilter{}.SortValue(withMsg))
+	assert.Equal(t, updated, SessionListFilter{SortBy: SessionSortUpdatedAt}.SortValue(withMsg))
+	assert.Equal(t, lastMsg, SessionListFilter{SortBy: SessionSortLastMessageAt}.SortValue(withMsg))
+	assert.Equal(t, created, SessionListFilter{SortBy: SessionSortLastMessageAt}.SortValue(noMsg))
+
+	assert.Equal(t, "sessions.created_at", SessionListFilter{}.SortExpr())
+	assert.Equal(t, "sessions.updated_at", SessionListFilter{SortBy: SessionSortUpdatedAt}.SortExpr())
+	assert.Contains(t, SessionListFilter{SortBy: SessionSortLastMessageAt}.SortExpr(), "MAX(m.created_at)")
+}
+
+func TestSessionRepo_BackfillStats(t *testing.T) {
+	db := setupSessionTestDB(t)
+	if db == nil {
+		return // Test was skipped
+	}
+	require.NoError(t, db.AutoMigrate(&model.Message{}, &model.SessionStats{}))
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewSessionRepo(db, nil, nil, logger)
+	ctx := context.Background()
+
+	project := &model.Project{
+		ID:               uuid.New(),
+		SecretKeyHMAC:    "test_hmac_stats_backfill",
+		SecretKeyHashPHC: "test_hash_stats_backfill",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupSessionTestDB(t, db, project.ID)
+
+	// A session from before stats were tracked: one message and no stats row
+	session := &model.Session{ID: uuid.New(), ProjectID: project.ID}
+	require.NoError(t, db.Create(session).Error)
+	require.NoError(t, db.Create(&model.Message{ID: uuid.New(), SessionID: session.ID, Role: "user"}).Error)
+
+	count := func(msgs []model.Message) *model.SessionStats {
+		stats := &model.SessionStats{SessionID: session.ID, ProjectID: project.ID}
+		for range msgs {
+			stats.Add(model.SessionStats{MessageCount: 1})
+		}
+		return stats
+	}
+
+	listed := make(chan struct{})
+	release := make(chan struct{})
+	backfilled := make(chan error, 1)
+	go func() {
+		_, err := repo.BackfillStats(ctx, session.ID, func(msgs []model.Message) (*model.SessionStats, error) {
+			close(listed)
+			<-release
+			return count(msgs), nil
+		})
+		backfilled <- err
+	}()
+	<-listed
+
+	// A message stored while the backfill runs waits for it and then increments the new row
+	stored := make(chan error, 1)
+	go func() {
+		msg := &model.Message{ID: uuid.New(), SessionID: session.ID, Role: "assistant"}
+		stored <- repo.CreateMessageWithAssets(ctx, msg, &model.SessionStats{SessionID: session.ID, ProjectID: project.ID, MessageCount: 1})
+	}()
+	select {
+	case err := <-stored:
+		t.Fatalf("message stored during backfill: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-backfilled)
+	require.NoError(t, <-stored)
+
+	stats, err := repo.GetStats(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.MessageCount)
+
+	// Once the row exists, backfill returns it as is
+	again, err := repo.BackfillStats(ctx, session.ID, func(msgs []model.Message) (*model.SessionStats, error) {
+		t.Fatal("history recomputed for a session with stats")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), again.MessageCount)
+}
+
+func TestSessionRepo_ListWithCursor_MessageCount(t *testing.T) {
+	db := setupSessionTestDB(t)
+	if db == nil {
+		return // Test was skipped
+	}
+	require.NoError(t, db.AutoMigrate(&model.Message{}, &model.SessionStats{}))
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewSessionRepo(db, nil, nil, logger)
+	ctx := context.Background()
+
+	project := &model.Project{
+		ID:               uuid.New(),
+		SecretKeyHMAC:    "test_hmac_list_message_count",
+		SecretKeyHashPHC: "test_hash_list_message_count",
+	}
+	require.NoError(t, db.Create(project).Error)
+	defer cleanupSessionTestDB(t, db, project.ID)
+
+	// Counted from its stats row
+	tracked := &model.Session{ID: uuid.New(), ProjectID: project.ID}
+	require.NoError(t, db.Create(tracked).Error)
+	require.NoError(t, db.Create(&model.SessionStats{SessionID: tracked.ID, ProjectID: project.ID, MessageCount: 3}).Error)
+	// Not backfilled yet, so its messages are counted
+	untracked := &model.Session{ID: uuid.New(), ProjectID: project.ID}
+	require.NoError(t, db.Create(untracked).Error)
+	require.NoError(t, db.Create(&model.Message{ID: uuid.New(), SessionID: untracked.ID, Role: "user"}).Error)
+
+	ids := func(filter SessionListFilter) []uuid.UUID {
+		sessions, err := repo.ListWithCursor(ctx, project.ID, filter, time.Time{}, uuid.Nil, 10, false)
+		require.NoError(t, err)
+		var got []uuid.UUID
+		for _, s := range sessions {
+			got = append(got, s.ID)
+		}
+		return got
+	}
+	two, one := 2, 1
+	assert.Equal(t, []uuid.UUID{tracked.ID}, ids(SessionListFilter{MinMessages: &two}))
+	assert.Equal(t, []uuid.UUID{untracked.ID}, ids(SessionListFilter{MaxMessages: &one}))
+	assert.Len(t, ids(SessionListFilter{MinMessages: &one, MaxMessages: &two}), 1)
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"gorm.io/gorm"
+)
+
+// ErrUploadConflict is returned when an upload changed since it was read, e.g. because a
+// concurrent append to it advanced its offset first.
+var ErrUploadConflict = errors.New("upload was modified concurrently")
+
+type UploadRepo interface {
+	Create(ctx context.Context, u *model.Upload) error
+	// Get returns an unexpired upload of the project.
+	Get(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) (*model.Upload, error)
+	// Advance saves u's progress if its offset is still prevOffset, returning ErrUploadConflict otherwise.
+	Advance(ctx context.Context, u *model.Upload, prevOffset int64) error
+	// Complete marks u completed if it is still uploading, returning ErrUploadConflict otherwise.
+	Complete(ctx context.Context, u *model.Upload) error
+	Delete(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) error
+	// ListActiveByProject returns the project's unexpired uploads, completed or not.
+	ListActiveByProject(ctx context.Context, projectID uuid.UUID) ([]*model.Upload, error)
+	// ListExpired returns up to limit uploads that expired before before, oldest first.
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*model.Upload, error)
+	// DeleteExpired deletes the upload if it still expired before before, reporting whether it did.
+	DeleteExpired(ctx context.Context, uploadID uuid.UUID, before time.Time) (bool, error)
+}
+
+type uploadRepo struct {
+	db *gorm.DB
+}
+
+func NewUploadRepo(db *gorm.DB) UploadRepo {
+	return &uploadRepo{db: db}
+}
+
+func (r *uploadRepo) Create(ctx context.Context, u *model.Upload) error {
+	return r.db.WithContext(ctx).Create(u).Error
+}
+
+func (r *uploadRepo) Get(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) (*model.Upload, error) {
+	var u model.Upload
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND project_id = ? AND expires_at > ?", uploadID, projectID, time.Now()).
+		First(&u).Error
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *uploadRepo) Advance(ctx context.Context, u *model.Upload, prevOffset int64) error {
+	res := r.db.WithContext(ctx).Model(&model.Upload{}).
+		Where("id = ? AND status = ? AND upload_offset = ?", u.ID, model.UploadStatusUploading, prevOffset).
+		Updates(map[string]interface{}{
+			"upload_offset":   u.Offset,
+			"mime":            u.MIME,
+			"store_upload_id": u.StoreUploadID,
+			"parts":           u.Parts,
+			"hash_state":      u.HashState,
+			"updated_at":      time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUploadConflict
+	}
+	return nil
+}
+
+func (r *uploadRepo) Complete(ctx context.Context, u *model.Upload) error {
+	res := r.db.WithContext(ctx).Model(&model.Upload{}).
+		Where("id = ? AND status = ?", u.ID, model.UploadStatusUploading).
+		Updates(map[string]interface{}{
+			"status":     model.UploadStatusCompleted,
+			"sha256":     u.SHA256,
+			"asset_meta": u.AssetMeta,
+			"hash_state": nil,
+			"expires_at": u.ExpiresAt,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUploadConflict
+	}
+	return nil
+}
+
+func (r *uploadRepo) Delete(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", uploadID, projectID).
+		Delete(&model.Upload{}).Error
+}
+
+func (r *uploadRepo) ListActiveByProject(ctx context.Context, projectID uuid.UUID) ([]*model.Upload, error) {
+	var uploads []*model.Upload
+	err := r.db.WithContext(ctx).
+		Omit("hash_state").
+		Where("project_id = ? AND expires_at > ?", projectID, time.Now()).
+		Find(&uploads).Error
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+func (r *uploadRepo) ListExpired(ctx context.Context, before time.Time, limit int) ([]*model.Upload, error) {
+	var uploads []*model.Upload
+	err := r.db.WithContext(ctx).
+		Omit("hash_state").
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads).Error
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+func (r *uploadRepo) DeleteExpired(ctx context.Context, uploadID uuid.UUID, before time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Where("id = ? AND expires_at < ?", uploadID, before).
+		Delete(&model.Upload{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
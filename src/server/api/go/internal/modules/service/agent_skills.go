@@ -165,13 +165,6 @@ func sanitizeS3Key(name string) string {
 	}, name)
 }
 
-func isMacOSSystemFile(fileName string) bool {
-	return strings.Contains(fileName, "__MACOSX/") ||
-		strings.Contains(fileName, "__MACOSX\\") ||
-		strings.HasPrefix(filepath.Base(fileName), "._") ||
-		filepath.Base(fileName) == ".DS_Store"
-}
-
 type zipFileData struct {
 	name         string
 	content      []byte
@@ -199,7 +192,6 @@ func (s *agentSkillsService) Create(ctx context.Context, in CreateAgentSkillsInp
 
 	var skillName, skillDescription string
 	var skillMetadataFound bool
-	var rootPrefix string
 	var fileNames []string
 	filesToUpload := make([]*zipFileData, 0)
 
@@ -212,6 +204,11 @@ func (s *agentSkillsService) Create(ctx context.Context, in CreateAgentSkillsInp
 			continue
 		}
 
+		name, err := sanitizeArchivePath(file.Name)
+		if err != nil {
+			return nil, err
+		}
+
 		fileReader, err := file.Open()
 		if err != nil {
 			return nil, fmt.Errorf("open file in zip: %w", err)
@@ -247,9 +244,9 @@ func (s *agentSkillsService) Create(ctx context.Context, in CreateAgentSkillsInp
 			}
 		}
 
-		fileNames = append(fileNames, file.Name)
+		fileNames = append(fileNames, name)
 		filesToUpload = append(filesToUpload, &zipFileData{
-			name:    file.Name,
+			name:    name,
 			content: fileContent,
 		})
 	}
@@ -259,24 +256,7 @@ func (s *agentSkillsService) Create(ctx context.Context, in CreateAgentSkillsInp
 	}
 
 	// Detect root prefix for stripping
-	if len(fileNames) > 0 {
-		firstFile := fileNames[0]
-		parts := strings.Split(firstFile, "/")
-		if len(parts) > 1 && parts[0] != "" {
-			outermostDir := parts[0]
-			allUnderSameRoot := true
-			for _, fileName := range fileNames {
-				fileParts := strings.Split(fileName, "/")
-				if len(fileParts) == 0 || fileParts[0] != outermostDir {
-					allUnderSameRoot = false
-					break
-				}
-			}
-			if allUnderSameRoot {
-				rootPrefix = outermostDir + "/"
-			}
-		}
-	}
+	rootPrefix := archiveRootPrefix(fileNames)
 
 	for _, fileData := range filesToUpload {
 		relativePath := fileData.name
@@ -558,7 +538,8 @@ func (s *agentSkillsService) GetFile(ctx context.Context, projectID uuid.UUID, s
 	mimeType := artifact.AssetMeta.Data().MIME
 
 	parser := fileparser.NewFileParser()
-	canParse := parser.CanParseFile(fname, mimeType)
+	// Documents such as PDFs are binary files, returned as URLs like any other
+	canParse := parser.IsTextFile(fname, mimeType)
 
 	output := &GetFileOutput{
 		Path: filePath,
@@ -6,12 +6,15 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"io"
 	"mime/multipart"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/fileparser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -91,6 +94,43 @@ func (m *MockDiskService) List(ctx context.Context, in ListDisksInput) (*ListDis
 	return args.Get(0).(*ListDisksOutput), args.Error(1)
 }
 
+func (m *MockDiskService) CreateSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, name string) (*model.DiskSnapshot, error) {
+	args := m.Called(ctx, projectID, diskID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskService) ListSnapshots(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	args := m.Called(ctx, diskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskService) DeleteSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) error {
+	args := m.Called(ctx, projectID, diskID, snapshotID)
+	return args.Error(0)
+}
+
+func (m *MockDiskService) RestoreSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) (*repo.DiskSnapshotRestoreStats, error) {
+	args := m.Called(ctx, projectID, diskID, snapshotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repo.DiskSnapshotRestoreStats), args.Error(1)
+}
+
+func (m *MockDiskService) CloneSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, userID *uuid.UUID) (*model.Disk, error) {
+	args := m.Called(ctx, projectID, snapshotID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Disk), args.Error(1)
+}
+
 // ── Mock: ArtifactService ──
 
 type MockArtifactService struct {
@@ -105,6 +145,22 @@ func (m *MockArtifactService) Create(ctx context.Context, in CreateArtifactInput
 	return args.Get(0).(*model.Artifact), args.Error(1)
 }
 
+func (m *MockArtifactService) PresignUpload(ctx context.Context, projectID uuid.UUID, contentType string, expire time.Duration) (*PresignedUpload, error) {
+	args := m.Called(ctx, projectID, contentType, expire)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PresignedUpload), args.Error(1)
+}
+
+func (m *MockArtifactService) Commit(ctx context.Context, in CommitArtifactInput) (*model.Artifact, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
 func (m *MockArtifactService) CreateFromBytes(ctx context.Context, in CreateArtifactFromBytesInput) (*model.Artifact, error) {
 	args := m.Called(ctx, in)
 	if args.Get(0) == nil {
@@ -113,8 +169,8 @@ func (m *MockArtifactService) CreateFromBytes(ctx context.Context, in CreateArti
 	return args.Get(0).(*model.Artifact), args.Error(1)
 }
 
-func (m *MockArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
-	args := m.Called(ctx, projectID, diskID, path, filename)
+func (m *MockArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
+	args := m.Called(ctx, projectID, diskID, path, filename, cond)
 	return args.Error(0)
 }
 
@@ -147,8 +203,24 @@ func (m *MockArtifactService) DownloadRawContent(ctx context.Context, artifact *
 	return args.Get(0).([]byte), args.String(1), args.Error(2)
 }
 
-func (m *MockArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}) (*model.Artifact, error) {
-	args := m.Called(ctx, diskID, path, filename, userMeta)
+func (m *MockArtifactService) OpenContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*blob.Object, error) {
+	args := m.Called(ctx, artifact, userKEK)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*blob.Object), args.Error(1)
+}
+
+func (m *MockArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}, cond repo.ArtifactPrecondition) (*model.Artifact, error) {
+	args := m.Called(ctx, diskID, path, filename, userMeta, cond)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) Edit(ctx context.Context, in EditArtifactInput) (*model.Artifact, error) {
+	args := m.Called(ctx, in)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -171,12 +243,12 @@ func (m *MockArtifactService) GetAllPaths(ctx context.Context, diskID uuid.UUID)
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockArtifactService) GrepArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
-	args := m.Called(ctx, projectID, diskID, pattern, limit)
+func (m *MockArtifactService) GrepArtifacts(ctx context.Context, in GrepArtifactsInput) ([]*ArtifactGrepResult, error) {
+	args := m.Called(ctx, in)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Artifact), args.Error(1)
+	return args.Get(0).([]*ArtifactGrepResult), args.Error(1)
 }
 
 func (m *MockArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
@@ -187,6 +259,80 @@ func (m *MockArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.
 	return args.Get(0).([]*model.Artifact), args.Error(1)
 }
 
+func (m *MockArtifactService) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	args := m.Called(ctx, diskID, path, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ArtifactVersion), args.Error(1)
+}
+
+func (m *MockArtifactService) GetByPathAtVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	args := m.Called(ctx, diskID, path, filename, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) RestoreVersion(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	args := m.Called(ctx, projectID, diskID, path, filename, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactService) Move(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TransferArtifactsOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) ListTree(ctx context.Context, in ListArtifactTreeInput) (*ListArtifactTreeOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ListArtifactTreeOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	args := m.Called(ctx, projectID, diskID, prefix)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockArtifactService) DiskUsage(ctx context.Context, diskID uuid.UUID, path string, maxDepth int) ([]DirectoryUsage, error) {
+	args := m.Called(ctx, diskID, path, maxDepth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]DirectoryUsage), args.Error(1)
+}
+
+func (m *MockArtifactService) Export(ctx context.Context, w io.Writer, in ExportArtifactsInput) error {
+	args := m.Called(ctx, w, in)
+	return args.Error(0)
+}
+
+func (m *MockArtifactService) Import(ctx context.Context, in ImportArtifactsInput) (*ImportArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportArtifactsOutput), args.Error(1)
+}
+
+func (m *MockArtifactService) Copy(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TransferArtifactsOutput), args.Error(1)
+}
+
 // ── Helpers ──
 
 func createTestZipFile(files map[string]string) ([]byte, error) {
@@ -268,12 +414,12 @@ func (m *MockMaterialService) CreateMaterialURL(ctx context.Context, s3Key strin
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
 
-func (m *MockMaterialService) ServeMaterial(ctx context.Context, token string) ([]byte, string, string, error) {
+func (m *MockMaterialService) ServeMaterial(ctx context.Context, token string) (*blob.Object, string, string, error) {
 	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
 		return nil, args.String(1), args.String(2), args.Error(3)
 	}
-	return args.Get(0).([]byte), args.String(1), args.String(2), args.Error(3)
+	return args.Get(0).(*blob.Object), args.String(1), args.String(2), args.Error(3)
 }
 
 func newService(repo *MockAgentSkillsRepo, diskSvc *MockDiskService, artifactSvc *MockArtifactService) AgentSkillsService {
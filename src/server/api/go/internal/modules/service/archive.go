@@ -0,0 +1,191 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Archive formats supported by disk export and import
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
+// ArchiveFormatFromFilename infers the archive format from a file name, returning "" when unknown.
+func ArchiveFormatFromFilename(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveFormatZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveFormatTarGz
+	}
+	return ""
+}
+
+func isMacOSSystemFile(fileName string) bool {
+	return strings.Contains(fileName, "__MACOSX/") ||
+		strings.Contains(fileName, "__MACOSX\\") ||
+		strings.HasPrefix(filepath.Base(fileName), "._") ||
+		filepath.Base(fileName) == ".DS_Store"
+}
+
+// sanitizeArchivePath normalizes an archive member name to a '/'-separated path relative to
+// the archive root. Names that climb out of the root with ".." are rejected.
+func sanitizeArchivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("%w: %q escapes the archive root", ErrInvalidArchive, name)
+		}
+	}
+	cleaned := strings.TrimPrefix(stdpath.Clean("/"+name), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("%w: empty file name", ErrInvalidArchive)
+	}
+	return cleaned, nil
+}
+
+// archiveRootPrefix returns the top-level directory shared by every name, with a trailing '/',
+// or "" if there is none. Zipping a folder wraps all files in it, and callers strip the wrapper.
+func archiveRootPrefix(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	parts := strings.Split(names[0], "/")
+	if len(parts) <= 1 || parts[0] == "" {
+		return ""
+	}
+	outermostDir := parts[0]
+	for _, name := range names {
+		if strings.Split(name, "/")[0] != outermostDir {
+			return ""
+		}
+	}
+	return outermostDir + "/"
+}
+
+// archiveMember is a regular file stored in an archive.
+type archiveMember struct {
+	name string // sanitized path relative to the archive root
+	size int64  // uncompressed size as declared by the archive
+}
+
+// eachArchiveFile calls fn for every regular file in the archive, skipping directories, links
+// and macOS metadata. open returns the file content; it is only valid during the call.
+func eachArchiveFile(format string, ra io.ReaderAt, size int64, fn func(m archiveMember, open func() (io.ReadCloser, error)) error) error {
+	switch format {
+	case ArchiveFormatZip:
+		zr, err := zip.NewReader(ra, size)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		for _, f := range zr.File {
+			if !f.Mode().IsRegular() || isMacOSSystemFile(f.Name) {
+				continue
+			}
+			name, err := sanitizeArchivePath(f.Name)
+			if err != nil {
+				return err
+			}
+			if err := fn(archiveMember{name: name, size: int64(f.UncompressedSize64)}, f.Open); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ArchiveFormatTarGz:
+		gz, err := gzip.NewReader(io.NewSectionReader(ra, 0, size))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		defer gz.Close()
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+			if !hdr.FileInfo().Mode().IsRegular() || isMacOSSystemFile(hdr.Name) {
+				continue
+			}
+			name, err := sanitizeArchivePath(hdr.Name)
+			if err != nil {
+				return err
+			}
+			open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+			if err := fn(archiveMember{name: name, size: hdr.Size}, open); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: unsupported format %q", ErrInvalidArchive, format)
+	}
+}
+
+// archiveWriter writes files into a zip or tar.gz stream.
+type archiveWriter struct {
+	zw *zip.Writer
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newArchiveWriter(format string, w io.Writer) (*archiveWriter, error) {
+	switch format {
+	case ArchiveFormatZip:
+		return &archiveWriter{zw: zip.NewWriter(w)}, nil
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &archiveWriter{gz: gz, tw: tar.NewWriter(gz)}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidArchive, format)
+}
+
+// WriteFile copies size bytes of r into the archive as name. Tar headers record the size up
+// front, so r must yield exactly size bytes.
+func (a *archiveWriter) WriteFile(name string, r io.Reader, size int64, modTime time.Time) error {
+	if a.zw != nil {
+		fw, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, r)
+		return err
+	}
+	if err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	n, err := io.Copy(a.tw, r)
+	if err == nil && n != size {
+		err = fmt.Errorf("read %d of %d bytes", n, size)
+	}
+	return err
+}
+
+func (a *archiveWriter) Close() error {
+	if a.zw != nil {
+		return a.zw.Close()
+	}
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
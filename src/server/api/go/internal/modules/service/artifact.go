@@ -2,47 +2,89 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/memodb-io/Acontext/internal/config"
 	"github.com/memodb-io/Acontext/internal/infra/blob"
 	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
 	"github.com/memodb-io/Acontext/internal/modules/model"
 	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/pkg/paging"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/fileparser"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/mime"
+	pathutil "github.com/memodb-io/Acontext/internal/pkg/utils/path"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/textedit"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type ArtifactService interface {
 	Create(ctx context.Context, in CreateArtifactInput) (*model.Artifact, error)
 	CreateFromBytes(ctx context.Context, in CreateArtifactFromBytesInput) (*model.Artifact, error)
-	DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error
+	PresignUpload(ctx context.Context, projectID uuid.UUID, contentType string, expire time.Duration) (*PresignedUpload, error)
+	Commit(ctx context.Context, in CommitArtifactInput) (*model.Artifact, error)
+	DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error
 	GetByPath(ctx context.Context, diskID uuid.UUID, path string, filename string) (*model.Artifact, error)
 	GetPresignedURL(ctx context.Context, artifact *model.Artifact, expire time.Duration) (string, error)
 	GetFileContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*fileparser.FileContent, error)
 	DownloadRawContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) ([]byte, string, error) // returns content, mime, error
-	UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}) (*model.Artifact, error)
+	OpenContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*blob.Object, error)
+	UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}, cond repo.ArtifactPrecondition) (*model.Artifact, error)
+	Edit(ctx context.Context, in EditArtifactInput) (*model.Artifact, error)
 	ListByPath(ctx context.Context, diskID uuid.UUID, path string) ([]*model.Artifact, error)
 	GetAllPaths(ctx context.Context, diskID uuid.UUID) ([]string, error)
-	GrepArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error)
+	GrepArtifacts(ctx context.Context, in GrepArtifactsInput) ([]*ArtifactGrepResult, error)
 	GlobArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error)
+	ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error)
+	GetByPathAtVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error)
+	RestoreVersion(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error)
+	Move(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error)
+	Copy(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error)
+	ListTree(ctx context.Context, in ListArtifactTreeInput) (*ListArtifactTreeOutput, error)
+	DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error)
+	DiskUsage(ctx context.Context, diskID uuid.UUID, path string, maxDepth int) ([]DirectoryUsage, error)
+	Export(ctx context.Context, w io.Writer, in ExportArtifactsInput) error
+	Import(ctx context.Context, in ImportArtifactsInput) (*ImportArtifactsOutput, error)
 }
 
 type artifactService struct {
 	r               repo.ArtifactRepo
-	s3              *blob.S3Deps
+	s3              blob.BlobStore
 	agentSkillsRepo repo.AgentSkillsRepo
+	cfg             *config.Config
 	log             *zap.Logger
 }
 
-func NewArtifactService(r repo.ArtifactRepo, s3 *blob.S3Deps, agentSkillsRepo repo.AgentSkillsRepo, log *zap.Logger) ArtifactService {
-	return &artifactService{r: r, s3: s3, agentSkillsRepo: agentSkillsRepo, log: log}
+func NewArtifactService(r repo.ArtifactRepo, s3 blob.BlobStore, agentSkillsRepo repo.AgentSkillsRepo, cfg *config.Config, log *zap.Logger) ArtifactService {
+	return &artifactService{r: r, s3: s3, agentSkillsRepo: agentSkillsRepo, cfg: cfg, log: log}
+}
+
+// versionPolicy returns the configured retention for superseded artifact versions.
+func (s *artifactService) versionPolicy() repo.ArtifactVersionPolicy {
+	return artifactVersionPolicy(s.cfg)
+}
+
+func artifactVersionPolicy(cfg *config.Config) repo.ArtifactVersionPolicy {
+	if cfg == nil {
+		return repo.ArtifactVersionPolicy{}
+	}
+	return repo.ArtifactVersionPolicy{
+		Keep:   cfg.Artifact.MaxVersions,
+		MaxAge: time.Duration(cfg.Artifact.VersionRetentionDays) * 24 * time.Hour,
+	}
 }
 
 // touchSkillUpdatedAt is best-effort: logs a warning on failure but does not propagate the error.
@@ -64,8 +106,11 @@ type CreateArtifactInput struct {
 	Path       string
 	Filename   string
 	FileHeader *multipart.FileHeader
+	Asset      *model.Asset // content already stored, e.g. by a resumable upload; used instead of FileHeader
 	UserMeta   map[string]interface{}
 	UserKEK    []byte // optional: for envelope encryption
+
+	Precondition repo.ArtifactPrecondition // optional: compare-and-swap against the current artifact
 }
 
 type CreateArtifactFromBytesInput struct {
@@ -75,39 +120,70 @@ type CreateArtifactFromBytesInput struct {
 	Filename  string
 	Content   []byte
 	UserKEK   []byte // optional: for envelope encryption
+
+	Precondition repo.ArtifactPrecondition // optional: compare-and-swap against the current artifact
 }
 
-func (s *artifactService) Create(ctx context.Context, in CreateArtifactInput) (*model.Artifact, error) {
-	// Check if artifact with same path and filename already exists in the same disk
-	exists, err := s.r.ExistsByPathAndFilename(ctx, in.DiskID, in.Path, in.Filename, nil)
-	if err != nil {
-		return nil, fmt.Errorf("check artifact existence: %w", err)
+// checkPrecondition fails fast when cond already does not hold, before any content is uploaded.
+// The repository checks it again atomically with the write.
+func (s *artifactService) checkPrecondition(ctx context.Context, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
+	if cond.IsZero() {
+		return nil
 	}
-	if exists {
-		if err := s.r.DeleteByPath(ctx, in.ProjectID, in.DiskID, in.Path, in.Filename); err != nil {
-			return nil, fmt.Errorf("upsert existing artifact: %w", err)
-		}
+	cur, err := s.r.GetByPath(ctx, diskID, path, filename)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		cur = nil
+	} else if err != nil {
+		return err
 	}
+	return cond.Check(cur)
+}
 
-	asset, err := s.s3.UploadFormFile(ctx, "disks/"+in.ProjectID.String(), in.FileHeader, in.UserKEK)
-	if err != nil {
-		return nil, fmt.Errorf("upload file to S3: %w", err)
+// exceedsInline reports whether a file of size bytes is too large to read into memory, which
+// text extraction, parsing, edits and archive import all do. Larger files can only be
+// streamed.
+func (s *artifactService) exceedsInline(size int64) bool {
+	return s.cfg != nil && s.cfg.Artifact.MaxInlineSizeBytes > 0 && size > s.cfg.Artifact.MaxInlineSizeBytes
+}
+
+func (s *artifactService) Create(ctx context.Context, in CreateArtifactInput) (*model.Artifact, error) {
+	if err := s.checkPrecondition(ctx, in.DiskID, in.Path, in.Filename, in.Precondition); err != nil {
+		return nil, err
+	}
+
+	var asset *model.Asset
+	var readContent func() ([]byte, error)
+	if in.Asset != nil {
+		asset = in.Asset
+		readContent = func() ([]byte, error) {
+			return s.s3.DownloadFile(ctx, asset.S3Key, in.UserKEK)
+		}
+	} else {
+		var err error
+		asset, err = s.s3.UploadFormFile(ctx, "disks/"+in.ProjectID.String(), in.FileHeader, in.UserKEK)
+		if err != nil {
+			return nil, fmt.Errorf("upload file to S3: %w", err)
+		}
+		readContent = func() ([]byte, error) {
+			file, err := in.FileHeader.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			return io.ReadAll(file)
+		}
 	}
 
 	// Extract text content for text-searchable files (grep/glob).
 	// For encrypted projects, content is stored encrypted using the cache framing format.
+	// Files too large to read into memory are stored without it.
 	var textContent string
 	parser := fileparser.NewFileParser()
-	if parser.CanParseFile(in.FileHeader.Filename, asset.MIME) {
-		file, err := in.FileHeader.Open()
-		if err == nil {
-			content, readErr := io.ReadAll(file)
-			file.Close()
-			if readErr == nil {
-				fileContent, parseErr := parser.ParseFile(in.FileHeader.Filename, asset.MIME, content)
-				if parseErr == nil && fileContent != nil {
-					textContent = fileContent.Raw
-				}
+	if parser.CanParseFile(in.Filename, asset.MIME) && !s.exceedsInline(asset.SizeB) {
+		if content, readErr := readContent(); readErr == nil {
+			fileContent, parseErr := parser.ParseFile(in.Filename, asset.MIME, content)
+			if parseErr == nil && fileContent != nil {
+				textContent = fileContent.Raw
 			}
 		}
 	}
@@ -121,7 +197,7 @@ func (s *artifactService) Create(ctx context.Context, in CreateArtifactInput) (*
 	meta := map[string]interface{}{
 		model.ArtifactInfoKey: map[string]interface{}{
 			"path":     in.Path,
-			"filename": in.FileHeader.Filename,
+			"filename": in.Filename,
 			"mime":     asset.MIME,
 			"size":     asset.SizeB,
 		},
@@ -138,8 +214,9 @@ func (s *artifactService) Create(ctx context.Context, in CreateArtifactInput) (*
 		AssetMeta: datatypes.NewJSONType(*asset),
 	}
 
-	if err := s.r.Create(ctx, in.ProjectID, artifact); err != nil {
-		return nil, fmt.Errorf("create artifact record: %w", err)
+	// An existing artifact at the same path is archived as a version rather than discarded
+	if err := s.r.Upsert(ctx, in.ProjectID, artifact, s.versionPolicy(), in.Precondition); err != nil {
+		return nil, fmt.Errorf("upsert artifact record: %w", err)
 	}
 
 	s.touchSkillUpdatedAt(ctx, in.DiskID)
@@ -147,15 +224,8 @@ func (s *artifactService) Create(ctx context.Context, in CreateArtifactInput) (*
 }
 
 func (s *artifactService) CreateFromBytes(ctx context.Context, in CreateArtifactFromBytesInput) (*model.Artifact, error) {
-	// Check if artifact with same path and filename already exists in the same disk
-	exists, err := s.r.ExistsByPathAndFilename(ctx, in.DiskID, in.Path, in.Filename, nil)
-	if err != nil {
-		return nil, fmt.Errorf("check artifact existence: %w", err)
-	}
-	if exists {
-		if err := s.r.DeleteByPath(ctx, in.ProjectID, in.DiskID, in.Path, in.Filename); err != nil {
-			return nil, fmt.Errorf("upsert existing artifact: %w", err)
-		}
+	if err := s.checkPrecondition(ctx, in.DiskID, in.Path, in.Filename, in.Precondition); err != nil {
+		return nil, err
 	}
 
 	// Upload bytes to S3 with deduplication
@@ -198,19 +268,162 @@ func (s *artifactService) CreateFromBytes(ctx context.Context, in CreateArtifact
 		AssetMeta: datatypes.NewJSONType(*asset),
 	}
 
-	if err := s.r.Create(ctx, in.ProjectID, artifact); err != nil {
-		return nil, fmt.Errorf("create artifact record: %w", err)
+	// An existing artifact at the same path is archived as a version rather than discarded
+	if err := s.r.Upsert(ctx, in.ProjectID, artifact, s.versionPolicy(), in.Precondition); err != nil {
+		return nil, fmt.Errorf("upsert artifact record: %w", err)
 	}
 
 	s.touchSkillUpdatedAt(ctx, in.DiskID)
 	return artifact, nil
 }
 
-func (s *artifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
+// PresignedUpload is a URL a client PUTs a file to, bypassing the API, before committing it
+// as an artifact with its upload key.
+type PresignedUpload struct {
+	UploadURL   string    `json:"upload_url"`
+	UploadKey   string    `json:"upload_key"`
+	ContentType string    `json:"content_type"` // the PUT must send this Content-Type
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MaxDirectUploadExpiry is the longest a presigned upload URL stays valid. Uploads not
+// committed within it, plus directUploadSweepGrace, are deleted by the upload sweep.
+const MaxDirectUploadExpiry = 7 * 24 * time.Hour
+
+// directUploadPrefix is where a project's presigned uploads are written before they are committed.
+func directUploadPrefix(projectID uuid.UUID) string {
+	return fmt.Sprintf("uploads/%s/direct/", projectID)
+}
+
+func (s *artifactService) PresignUpload(ctx context.Context, projectID uuid.UUID, contentType string, expire time.Duration) (*PresignedUpload, error) {
+	if expire <= 0 || expire > MaxDirectUploadExpiry {
+		return nil, fmt.Errorf("%w: upload URLs expire after at most %s", ErrInvalidUpload, MaxDirectUploadExpiry)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	key := directUploadPrefix(projectID) + uuid.New().String()
+	url, err := s.s3.PresignPut(ctx, key, contentType, expire)
+	if err != nil {
+		return nil, fmt.Errorf("presign upload: %w", err)
+	}
+	return &PresignedUpload{
+		UploadURL:   url,
+		UploadKey:   key,
+		ContentType: contentType,
+		ExpiresAt:   time.Now().Add(expire).UTC(),
+	}, nil
+}
+
+type CommitArtifactInput struct {
+	ProjectID uuid.UUID
+	DiskID    uuid.UUID
+	UploadKey string // from PresignUpload
+	Path      string
+	Filename  string
+	Size      int64  // declared by the client and verified
+	SHA256    string // declared by the client and verified
+	UserMeta  map[string]interface{}
+	UserKEK   []byte // optional: for envelope encryption
+
+	Precondition repo.ArtifactPrecondition // optional: compare-and-swap against the current artifact
+}
+
+// Commit turns a file the client uploaded through PresignUpload into an artifact. The uploaded
+// object is read once to verify its size and SHA256 and detect its MIME type, then moved to its
+// content-addressed key, or encrypted into place for encrypted projects. An object that fails
+// verification is deleted, so the client has to upload it again.
+func (s *artifactService) Commit(ctx context.Context, in CommitArtifactInput) (*model.Artifact, error) {
+	rest, ok := strings.CutPrefix(in.UploadKey, directUploadPrefix(in.ProjectID))
+	if _, err := uuid.Parse(rest); !ok || err != nil {
+		return nil, fmt.Errorf("%w: upload_key was not issued for this project", ErrInvalidUpload)
+	}
+	if maxSize := s.cfg.Artifact.MaxUploadSizeBytes; maxSize > 0 && in.Size > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, the maximum is %d", ErrUploadTooLarge, in.Size, maxSize)
+	}
+	if err := s.checkPrecondition(ctx, in.DiskID, in.Path, in.Filename, in.Precondition); err != nil {
+		return nil, err
+	}
+
+	obj, err := s.s3.OpenObject(ctx, in.UploadKey, nil)
+	if errors.Is(err, blob.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: nothing was uploaded to upload_key", ErrUploadNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open uploaded object: %w", err)
+	}
+	if obj.Size != in.Size {
+		s.discardUpload(ctx, in.UploadKey)
+		return nil, fmt.Errorf("%w: size is %d bytes, not %d", ErrUploadMismatch, obj.Size, in.Size)
+	}
+
+	sumHex, head, err := digestObject(ctx, obj)
+	if err != nil {
+		return nil, fmt.Errorf("read uploaded object: %w", err)
+	}
+	if !strings.EqualFold(sumHex, in.SHA256) {
+		s.discardUpload(ctx, in.UploadKey)
+		return nil, fmt.Errorf("%w: sha256 is %s", ErrUploadMismatch, sumHex)
+	}
+
+	ext := strings.ToLower(filepath.Ext(in.Filename))
+	asset, err := s.s3.IngestObject(ctx, in.UploadKey, obj.ETag, "disks/"+in.ProjectID.String(), sumHex, mime.DetectMimeType(head, in.Filename), ext, obj.Size, in.UserKEK)
+	if errors.Is(err, blob.ErrObjectChanged) {
+		// Overwritten after it was verified: what is there now was never checked
+		s.discardUpload(ctx, in.UploadKey)
+		return nil, fmt.Errorf("%w: the upload changed while it was being committed", ErrUploadMismatch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store uploaded object: %w", err)
+	}
+
+	return s.Create(ctx, CreateArtifactInput{
+		ProjectID:    in.ProjectID,
+		DiskID:       in.DiskID,
+		Path:         in.Path,
+		Filename:     in.Filename,
+		Asset:        asset,
+		UserMeta:     in.UserMeta,
+		UserKEK:      in.UserKEK,
+		Precondition: in.Precondition,
+	})
+}
+
+// digestObject streams obj, returning its SHA256 and its first bytes for MIME detection.
+func digestObject(ctx context.Context, obj *blob.Object) (sumHex string, head []byte, err error) {
+	r, err := obj.NewRangeReader(ctx, 0, -1)
+	if err != nil {
+		return "", nil, err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	head = make([]byte, mimeSniffSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", nil, err
+	}
+	head = head[:n]
+	h.Write(head)
+	if _, err := io.Copy(h, r); err != nil {
+		return "", nil, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), head, nil
+}
+
+// discardUpload deletes a presigned upload that failed verification. It is best effort: a
+// leftover object is never committed, since a retry would fail verification the same way.
+func (s *artifactService) discardUpload(ctx context.Context, key string) {
+	if err := s.s3.DeleteObject(ctx, key); err != nil {
+		s.log.Warn("failed to delete rejected upload", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (s *artifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
 	if path == "" || filename == "" {
 		return errors.New("path and filename are required")
 	}
-	if err := s.r.DeleteByPath(ctx, projectID, diskID, path, filename); err != nil {
+	if err := s.r.DeleteByPath(ctx, projectID, diskID, path, filename, cond); err != nil {
 		return err
 	}
 	s.touchSkillUpdatedAt(ctx, diskID)
@@ -252,6 +465,9 @@ func (s *artifactService) GetFileContent(ctx context.Context, artifact *model.Ar
 	if !parser.CanParseFile(artifact.Filename, assetData.MIME) {
 		return nil, fmt.Errorf("unsupported file type: %s (mime: %s)", artifact.Filename, assetData.MIME)
 	}
+	if s.exceedsInline(assetData.SizeB) {
+		return nil, fmt.Errorf("file too large to parse: %s (%d bytes)", artifact.Filename, assetData.SizeB)
+	}
 
 	// Download file content from S3
 	content, err := s.s3.DownloadFile(ctx, assetData.S3Key, userKEK)
@@ -285,10 +501,29 @@ func (s *artifactService) DownloadRawContent(ctx context.Context, artifact *mode
 	return content, assetData.MIME, nil
 }
 
-func (s *artifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}) (*model.Artifact, error) {
+// OpenContent opens the artifact's file for streaming reads, decrypting it if it is encrypted.
+func (s *artifactService) OpenContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*blob.Object, error) {
+	if artifact == nil {
+		return nil, errors.New("artifact is nil")
+	}
+	assetData := artifact.AssetMeta.Data()
+	if assetData.S3Key == "" {
+		return nil, errors.New("artifact has no S3 key")
+	}
+	object, err := s.s3.OpenObject(ctx, assetData.S3Key, userKEK)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	return object, nil
+}
+
+func (s *artifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}, cond repo.ArtifactPrecondition) (*model.Artifact, error) {
 	// Get existing artifact
 	artifact, err := s.GetByPath(ctx, diskID, path, filename)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) && len(cond.IfMatch) > 0 {
+			return nil, cond.Check(nil)
+		}
 		return nil, err
 	}
 
@@ -316,7 +551,7 @@ func (s *artifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID u
 	// Update artifact meta
 	artifact.Meta = newMeta
 
-	if err := s.r.Update(ctx, artifact); err != nil {
+	if err := s.r.Update(ctx, artifact, cond); err != nil {
 		return nil, fmt.Errorf("update artifact meta: %w", err)
 	}
 
@@ -324,6 +559,113 @@ func (s *artifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID u
 	return artifact, nil
 }
 
+type EditArtifactInput struct {
+	ProjectID uuid.UUID
+	DiskID    uuid.UUID
+	Path      string
+	Filename  string
+	Edits     []textedit.Edit
+	UserKEK   []byte // optional: for envelope encryption
+
+	Precondition repo.ArtifactPrecondition // optional: compare-and-swap against the current artifact
+}
+
+// Edit applies line-oriented edits to a text artifact on the server and stores the result as a
+// new version, keeping the artifact's meta. The write is conditional on the content the edits
+// were applied to, so a concurrent writer makes it fail with ErrPreconditionFailed instead of
+// being overwritten.
+func (s *artifactService) Edit(ctx context.Context, in EditArtifactInput) (*model.Artifact, error) {
+	cur, err := s.GetByPath(ctx, in.DiskID, in.Path, in.Filename)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s%s", ErrArtifactNotFound, in.Path, in.Filename)
+		}
+		return nil, err
+	}
+	if err := in.Precondition.Check(cur); err != nil {
+		return nil, err
+	}
+
+	asset := cur.AssetMeta.Data()
+	parser := fileparser.NewFileParser()
+	if !parser.IsTextFile(cur.Filename, asset.MIME) {
+		return nil, fmt.Errorf("%w: %s (mime: %s)", ErrArtifactNotText, cur.Filename, asset.MIME)
+	}
+	// The content column of HTML holds its extracted text, not the file
+	extracted := parser.ExtractsText(cur.Filename, asset.MIME)
+	var text string
+	if !extracted {
+		if text, err = encryptionpkg.DecodeContent(in.UserKEK, asset.Content); err != nil {
+			return nil, fmt.Errorf("decode artifact content: %w", err)
+		}
+	}
+	if text == "" && asset.SizeB > 0 {
+		// artifacts stored before text extraction, or too large for it, have no content column to edit
+		if s.exceedsInline(asset.SizeB) {
+			return nil, fmt.Errorf("%w: file of %d bytes exceeds the maximum editable size of %d bytes", ErrInvalidEdit, asset.SizeB, s.cfg.Artifact.MaxInlineSizeBytes)
+		}
+		raw, err := s.s3.DownloadFile(ctx, asset.S3Key, in.UserKEK)
+		if err != nil {
+			return nil, fmt.Errorf("download artifact content: %w", err)
+		}
+		text = string(raw)
+	}
+
+	edited, err := textedit.Apply(text, in.Edits)
+	switch {
+	case errors.Is(err, textedit.ErrMismatch):
+		return nil, fmt.Errorf("%w: %v", ErrEditMismatch, err)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEdit, err)
+	case edited == text:
+		return cur, nil
+	}
+	if s.exceedsInline(int64(len(edited))) {
+		return nil, fmt.Errorf("%w: edited file exceeds the maximum editable size of %d bytes", ErrInvalidEdit, s.cfg.Artifact.MaxInlineSizeBytes)
+	}
+
+	newAsset, err := s.s3.UploadBytes(ctx, "disks/"+in.ProjectID.String(), cur.Filename, []byte(edited), in.UserKEK)
+	if err != nil {
+		return nil, fmt.Errorf("upload bytes to S3: %w", err)
+	}
+	textContent := edited
+	if extracted {
+		textContent = ""
+		if fileContent, parseErr := parser.ParseFile(cur.Filename, newAsset.MIME, []byte(edited)); parseErr == nil {
+			textContent = fileContent.Raw
+		}
+	}
+	if newAsset.Content, err = encryptionpkg.EncodeContent(in.UserKEK, textContent); err != nil {
+		return nil, fmt.Errorf("encode artifact content: %w", err)
+	}
+
+	meta := make(map[string]interface{}, len(cur.Meta))
+	for k, v := range cur.Meta {
+		meta[k] = v
+	}
+	meta[model.ArtifactInfoKey] = map[string]interface{}{
+		"path":     cur.Path,
+		"filename": cur.Filename,
+		"mime":     newAsset.MIME,
+		"size":     newAsset.SizeB,
+	}
+
+	artifact := &model.Artifact{
+		DiskID:    cur.DiskID,
+		Path:      cur.Path,
+		Filename:  cur.Filename,
+		Meta:      meta,
+		AssetMeta: datatypes.NewJSONType(*newAsset),
+	}
+	cond := repo.ArtifactPrecondition{IfMatch: []string{asset.SHA256}}
+	if err := s.r.Upsert(ctx, in.ProjectID, artifact, s.versionPolicy(), cond); err != nil {
+		return nil, fmt.Errorf("upsert artifact record: %w", err)
+	}
+
+	s.touchSkillUpdatedAt(ctx, in.DiskID)
+	return artifact, nil
+}
+
 func (s *artifactService) ListByPath(ctx context.Context, diskID uuid.UUID, path string) ([]*model.Artifact, error) {
 	return s.r.ListByPath(ctx, diskID, path)
 }
@@ -332,19 +674,229 @@ func (s *artifactService) GetAllPaths(ctx context.Context, diskID uuid.UUID) ([]
 	return s.r.GetAllPaths(ctx, diskID)
 }
 
-func (s *artifactService) GrepArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
+type ListArtifactTreeInput struct {
+	DiskID   uuid.UUID
+	Path     string // directory path ending with '/'
+	MaxDepth int    // levels below Path to descend into, 0 means unlimited
+	Limit    int
+	Cursor   string
+}
+
+type ListArtifactTreeOutput struct {
+	Items      []*model.Artifact `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+// ListTree pages through every artifact under a directory in path order.
+func (s *artifactService) ListTree(ctx context.Context, in ListArtifactTreeInput) (*ListArtifactTreeOutput, error) {
+	// Parse cursor (path, filename); an empty cursor starts from the beginning of the tree
+	var afterPath, afterName string
+	var err error
+	if in.Cursor != "" {
+		afterPath, afterName, err = paging.DecodePathCursor(in.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+	}
+
+	// Query limit+1 is used to determine has_more
+	artifacts, err := s.r.ListTree(ctx, in.DiskID, in.Path, in.MaxDepth, afterPath, afterName, in.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListArtifactTreeOutput{
+		Items:   artifacts,
+		HasMore: false,
+	}
+	if len(artifacts) > in.Limit {
+		out.HasMore = true
+		out.Items = artifacts[:in.Limit]
+		last := out.Items[len(out.Items)-1]
+		out.NextCursor = paging.EncodePathCursor(last.Path, last.Filename)
+	}
+
+	return out, nil
+}
+
+// DeleteByPathPrefix removes a directory and everything below it, returning the number of
+// artifacts deleted.
+func (s *artifactService) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	if !strings.HasPrefix(prefix, "/") || !strings.HasSuffix(prefix, "/") {
+		return 0, errors.New("directory path must start and end with '/'")
+	}
+	deleted, err := s.r.DeleteByPathPrefix(ctx, projectID, diskID, prefix)
+	if deleted > 0 {
+		s.touchSkillUpdatedAt(ctx, diskID)
+	}
+	return deleted, err
+}
+
+// DirectoryUsage is the number and total size of the files in a directory and all of its subdirectories.
+type DirectoryUsage struct {
+	Path  string `json:"path"`
+	Files int64  `json:"files"`  // number of files in the subtree
+	Bytes int64  `json:"size_b"` // total size of those files in bytes
+}
+
+// DiskUsage summarizes file counts and sizes for path and for each directory below it, down to
+// maxDepth levels (0 means unlimited). Entries are ordered by path, starting with path itself.
+func (s *artifactService) DiskUsage(ctx context.Context, diskID uuid.UUID, path string, maxDepth int) ([]DirectoryUsage, error) {
+	rows, err := s.r.SummarizeByPathPrefix(ctx, diskID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.Count(path, "/")
+	totals := map[string]*DirectoryUsage{path: {Path: path}}
+	for _, row := range rows {
+		// Credit the directory holding the files and every ancestor up to path
+		for dir := row.Path; ; dir = parentDir(dir) {
+			if maxDepth == 0 || strings.Count(dir, "/")-base <= maxDepth {
+				u, ok := totals[dir]
+				if !ok {
+					u = &DirectoryUsage{Path: dir}
+					totals[dir] = u
+				}
+				u.Files += row.Files
+				u.Bytes += row.Bytes
+			}
+			if dir == path || dir == "/" {
+				break
+			}
+		}
+	}
+
+	out := make([]DirectoryUsage, 0, len(totals))
+	for _, u := range totals {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// parentDir returns the parent of a directory path ending with '/', e.g. "/a/b/" -> "/a/".
+func parentDir(dir string) string {
+	trimmed := strings.TrimSuffix(dir, "/")
+	return trimmed[:strings.LastIndex(trimmed, "/")+1]
+}
+
+type GrepArtifactsInput struct {
+	ProjectID     uuid.UUID
+	DiskID        uuid.UUID
+	Pattern       string
+	FixedString   bool     // match Pattern literally instead of as a regular expression
+	CaseSensitive bool     // defaults to case-insensitive matching
+	Include       []string // only search artifacts matching one of these path globs
+	Exclude       []string // skip artifacts matching any of these path globs
+	Before        int      // context lines before each match
+	After         int      // context lines after each match
+	MaxCount      int      // matches reported per artifact, 0 means DefaultGrepMaxCount
+	Limit         int      // artifacts returned, defaults to 100 and is capped at 1000
+	UserKEK       []byte   // optional: for envelope encryption
+}
+
+// ArtifactGrepResult is an artifact that matched a grep, with the matching lines.
+type ArtifactGrepResult struct {
+	*model.Artifact
+	Matches   []GrepMatch `json:"matches"`
+	Truncated bool        `json:"truncated"` // more lines matched than max_count
+}
+
+const (
+	DefaultGrepMaxCount = 100
+	MaxGrepContext      = 20
+
+	// grepBatchSize and globBatchSize are how many candidate artifacts are loaded per query while filtering
+	grepBatchSize = 100
+	globBatchSize = 500
+)
+
+// GrepArtifacts searches text artifacts line by line. Postgres selects candidates by content;
+// path globs and line matching are applied here, paging through candidates until Limit
+// artifacts with matching lines are found.
+func (s *artifactService) GrepArtifacts(ctx context.Context, in GrepArtifactsInput) ([]*ArtifactGrepResult, error) {
 	// Set default limit if not provided
-	if limit <= 0 {
-		limit = 100
+	if in.Limit <= 0 {
+		in.Limit = 100
 	}
 	// Cap at 1000 results
-	if limit > 1000 {
-		limit = 1000
+	if in.Limit > 1000 {
+		in.Limit = 1000
+	}
+	if in.MaxCount <= 0 {
+		in.MaxCount = DefaultGrepMaxCount
+	}
+	if in.Before < 0 || in.After < 0 || in.Before > MaxGrepContext || in.After > MaxGrepContext {
+		return nil, fmt.Errorf("%w: context must be between 0 and %d lines", ErrInvalidGrep, MaxGrepContext)
+	}
+	for _, g := range append(append([]string{}, in.Include...), in.Exclude...) {
+		if err := pathutil.ValidateGlob(g); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGrep, err)
+		}
+	}
+	re, err := compileGrepPattern(in.Pattern, in.FixedString, in.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	q := repo.ArtifactGrepQuery{Pattern: in.Pattern, FixedString: in.FixedString, CaseSensitive: in.CaseSensitive}
+	results := make([]*ArtifactGrepResult, 0)
+	for {
+		batch, err := s.r.GrepArtifacts(ctx, in.DiskID, q, grepBatchSize)
+		if errors.Is(err, repo.ErrInvalidPattern) {
+			// Go and Postgres regex syntax differ; a pattern only Go accepts is still the caller's error
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGrep, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range batch {
+			q.AfterPath, q.AfterFilename = a.Path, a.Filename
+			if !grepPathSelected(a.Path, a.Filename, in.Include, in.Exclude) {
+				continue
+			}
+			text, err := encryptionpkg.DecodeContent(in.UserKEK, a.AssetMeta.Data().Content)
+			if err != nil {
+				return nil, fmt.Errorf("decode content of %s%s: %w", a.Path, a.Filename, err)
+			}
+			// Postgres may match across lines where no single line matches
+			matches, truncated := grepLines(text, re, in.Before, in.After, in.MaxCount)
+			if len(matches) == 0 {
+				continue
+			}
+			results = append(results, &ArtifactGrepResult{Artifact: a, Matches: matches, Truncated: truncated})
+			if len(results) == in.Limit {
+				return results, nil
+			}
+		}
+		if len(batch) < grepBatchSize {
+			return results, nil
+		}
 	}
+}
 
-	return s.r.GrepArtifacts(ctx, diskID, pattern, limit)
+func grepPathSelected(dir string, filename string, include []string, exclude []string) bool {
+	for _, g := range exclude {
+		if matchPathGlob(g, dir, filename) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, g := range include {
+		if matchPathGlob(g, dir, filename) {
+			return true
+		}
+	}
+	return false
 }
 
+// GlobArtifacts returns the artifacts whose full path matches a shell-style glob (see
+// pathutil.MatchGlob). Postgres narrows candidates with a LIKE prefilter; exact matching is
+// done here, paging through candidates until limit artifacts match.
 func (s *artifactService) GlobArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
 	// Set default limit if not provided
 	if limit <= 0 {
@@ -354,6 +906,508 @@ func (s *artifactService) GlobArtifacts(ctx context.Context, projectID uuid.UUID
 	if limit > 1000 {
 		limit = 1000
 	}
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: pattern is required", ErrInvalidGlob)
+	}
+	if err := pathutil.ValidateGlob(pattern); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGlob, err)
+	}
+
+	q := repo.ArtifactGlobQuery{LikePattern: pathutil.GlobToLike(pattern)}
+	results := make([]*model.Artifact, 0)
+	for {
+		batch, err := s.r.GlobArtifacts(ctx, diskID, q, globBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range batch {
+			q.AfterPath, q.AfterFilename = a.Path, a.Filename
+			if !pathutil.MatchGlob(pattern, a.Path+a.Filename) {
+				continue
+			}
+			results = append(results, a)
+			if len(results) == limit {
+				return results, nil
+			}
+		}
+		if len(batch) < globBatchSize {
+			return results, nil
+		}
+	}
+}
+
+func (s *artifactService) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	if path == "" || filename == "" {
+		return nil, errors.New("path and filename are required")
+	}
+	return s.r.ListVersions(ctx, diskID, path, filename)
+}
+
+// GetByPathAtVersion returns the artifact as it was at the given version. Version 0 or the
+// current version returns the live artifact; older versions are served from the retained
+// history with the artifact's identity and the version's content.
+func (s *artifactService) GetByPathAtVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	artifact, err := s.GetByPath(ctx, diskID, path, filename)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 || version == artifact.Version {
+		return artifact, nil
+	}
+
+	v, err := s.r.GetVersion(ctx, diskID, path, filename, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArtifactVersionNotFound
+		}
+		return nil, fmt.Errorf("get artifact version: %w", err)
+	}
+	return &model.Artifact{
+		ID:        artifact.ID,
+		DiskID:    artifact.DiskID,
+		Path:      artifact.Path,
+		Filename:  artifact.Filename,
+		Meta:      v.Meta,
+		AssetMeta: v.AssetMeta,
+		Version:   v.Version,
+		CreatedAt: artifact.CreatedAt,
+		UpdatedAt: v.CreatedAt,
+	}, nil
+}
+
+// RestoreVersion makes the content and meta of a retained version current again. The
+// restore is itself a new version, so the content it replaces stays in the history.
+func (s *artifactService) RestoreVersion(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	target, err := s.GetByPathAtVersion(ctx, diskID, path, filename, version)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.GetByPath(ctx, diskID, path, filename)
+	if err != nil {
+		return nil, err
+	}
+	if target.Version == current.Version {
+		return current, nil
+	}
+
+	restored := &model.Artifact{
+		DiskID:    diskID,
+		Path:      path,
+		Filename:  filename,
+		Meta:      target.Meta,
+		AssetMeta: target.AssetMeta,
+	}
+	if err := s.r.Upsert(ctx, projectID, restored, s.versionPolicy(), repo.ArtifactPrecondition{}); err != nil {
+		return nil, fmt.Errorf("restore artifact version: %w", err)
+	}
+
+	s.touchSkillUpdatedAt(ctx, diskID)
+	return restored, nil
+}
+
+// Conflict modes for moves and copies whose destination is already occupied
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+)
+
+// MaxTransferArtifacts caps the number of artifacts a single directory move or copy may touch
+const MaxTransferArtifacts = 10000
+
+type TransferArtifactsInput struct {
+	ProjectID  uuid.UUID
+	DiskID     uuid.UUID
+	From       string    // file path, or directory path ending with '/'
+	To         string    // destination file path, or directory path ending with '/'
+	DestDiskID uuid.UUID // optional, defaults to DiskID; must belong to the same project
+	OnConflict string    // one of Conflict*, defaults to ConflictFail
+}
+
+type TransferArtifactsOutput struct {
+	Artifacts []*model.Artifact `json:"artifacts"` // artifacts at their destination
+	Skipped   []string          `json:"skipped"`   // source file paths left untouched because the destination existed
+}
+
+func (s *artifactService) Move(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	return s.transfer(ctx, in, true)
+}
+
+func (s *artifactService) Copy(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	return s.transfer(ctx, in, false)
+}
+
+// transfer resolves the source artifacts and their destinations, applies the conflict mode
+// and hands the batch to the repo, which performs it in a single transaction.
+func (s *artifactService) transfer(ctx context.Context, in TransferArtifactsInput, move bool) (*TransferArtifactsOutput, error) {
+	if in.DestDiskID == uuid.Nil {
+		in.DestDiskID = in.DiskID
+	}
+	if in.OnConflict == "" {
+		in.OnConflict = ConflictFail
+	}
+	if in.OnConflict != ConflictFail && in.OnConflict != ConflictSkip && in.OnConflict != ConflictOverwrite {
+		return nil, fmt.Errorf("%w: unknown conflict mode %q", ErrInvalidTransfer, in.OnConflict)
+	}
+
+	transfers, err := s.resolveTransfers(ctx, in, move)
+	if err != nil {
+		return nil, err
+	}
+
+	occupied, err := s.occupiedDestinations(ctx, in, transfers)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &TransferArtifactsOutput{Artifacts: []*model.Artifact{}, Skipped: []string{}}
+	pending := make([]repo.ArtifactTransfer, 0, len(transfers))
+	for _, t := range transfers {
+		if _, ok := occupied[t.Dest.Path+t.Dest.Filename]; ok {
+			switch in.OnConflict {
+			case ConflictFail:
+				return nil, fmt.Errorf("%w: %s%s", ErrArtifactConflict, t.Dest.Path, t.Dest.Filename)
+			case ConflictSkip:
+				out.Skipped = append(out.Skipped, t.Source.Path+t.Source.Filename)
+				continue
+			}
+		}
+		pending = append(pending, t)
+	}
+	if len(pending) == 0 {
+		return out, nil
+	}
 
-	return s.r.GlobArtifacts(ctx, diskID, pattern, limit)
+	overwrite := in.OnConflict == ConflictOverwrite
+	if move {
+		err = s.r.Move(ctx, in.ProjectID, pending, overwrite, s.versionPolicy())
+	} else {
+		err = s.r.Copy(ctx, in.ProjectID, pending, overwrite, s.versionPolicy())
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrArtifactExists) {
+			return nil, fmt.Errorf("%w: %v", ErrArtifactConflict, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArtifactNotFound
+		}
+		return nil, err
+	}
+
+	for _, t := range pending {
+		out.Artifacts = append(out.Artifacts, t.Dest)
+	}
+	if move {
+		s.touchSkillUpdatedAt(ctx, in.DiskID)
+	}
+	if !move || in.DestDiskID != in.DiskID {
+		s.touchSkillUpdatedAt(ctx, in.DestDiskID)
+	}
+	return out, nil
+}
+
+// resolveTransfers lists the artifacts addressed by in.From and maps each to its destination.
+func (s *artifactService) resolveTransfers(ctx context.Context, in TransferArtifactsInput, move bool) ([]repo.ArtifactTransfer, error) {
+	sameDisk := in.DestDiskID == in.DiskID
+
+	if !strings.HasSuffix(in.From, "/") {
+		srcPath, srcName := pathutil.SplitFilePath(in.From)
+		dstPath, dstName := pathutil.SplitFilePath(in.To)
+		if dstName == "" {
+			dstName = srcName
+		}
+		if sameDisk && srcPath == dstPath && srcName == dstName {
+			return nil, fmt.Errorf("%w: source and destination are the same", ErrInvalidTransfer)
+		}
+
+		src, err := s.r.GetByPath(ctx, in.DiskID, srcPath, srcName)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrArtifactNotFound
+			}
+			return nil, fmt.Errorf("get artifact: %w", err)
+		}
+		return []repo.ArtifactTransfer{{Source: src, Dest: transferDest(src, in.DestDiskID, dstPath, dstName)}}, nil
+	}
+
+	if !strings.HasSuffix(in.To, "/") {
+		return nil, fmt.Errorf("%w: destination of a directory must end with '/'", ErrInvalidTransfer)
+	}
+	if sameDisk && strings.HasPrefix(in.To, in.From) {
+		return nil, fmt.Errorf("%w: cannot move or copy a directory into itself", ErrInvalidTransfer)
+	}
+	if sameDisk && move && strings.HasPrefix(in.From, in.To) {
+		return nil, fmt.Errorf("%w: cannot move a directory into one of its parents", ErrInvalidTransfer)
+	}
+
+	sources, err := s.r.ListByPathPrefix(ctx, in.DiskID, in.From)
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	if len(sources) == 0 {
+		return nil, ErrArtifactNotFound
+	}
+	if len(sources) > MaxTransferArtifacts {
+		return nil, fmt.Errorf("%w: directory holds %d artifacts, at most %d can be transferred at once", ErrInvalidTransfer, len(sources), MaxTransferArtifacts)
+	}
+
+	transfers := make([]repo.ArtifactTransfer, 0, len(sources))
+	for _, src := range sources {
+		dstPath := in.To + strings.TrimPrefix(src.Path, in.From)
+		transfers = append(transfers, repo.ArtifactTransfer{Source: src, Dest: transferDest(src, in.DestDiskID, dstPath, src.Filename)})
+	}
+	return transfers, nil
+}
+
+// occupiedDestinations returns the destination locations (path+filename) that already hold an artifact.
+func (s *artifactService) occupiedDestinations(ctx context.Context, in TransferArtifactsInput, transfers []repo.ArtifactTransfer) (map[string]struct{}, error) {
+	occupied := make(map[string]struct{})
+	if len(transfers) == 1 {
+		dst := transfers[0].Dest
+		exists, err := s.r.ExistsByPathAndFilename(ctx, dst.DiskID, dst.Path, dst.Filename, nil)
+		if err != nil {
+			return nil, fmt.Errorf("check destination: %w", err)
+		}
+		if exists {
+			occupied[dst.Path+dst.Filename] = struct{}{}
+		}
+		return occupied, nil
+	}
+
+	existing, err := s.r.ListByPathPrefix(ctx, in.DestDiskID, in.To)
+	if err != nil {
+		return nil, fmt.Errorf("list destination: %w", err)
+	}
+	for _, a := range existing {
+		occupied[a.Path+a.Filename] = struct{}{}
+	}
+	return occupied, nil
+}
+
+// transferDest builds the destination row for src, pointing the system metadata at the new location.
+func transferDest(src *model.Artifact, diskID uuid.UUID, path string, filename string) *model.Artifact {
+	meta := make(map[string]interface{}, len(src.Meta))
+	for k, v := range src.Meta {
+		meta[k] = v
+	}
+	info := make(map[string]interface{})
+	if cur, ok := src.Meta[model.ArtifactInfoKey].(map[string]interface{}); ok {
+		for k, v := range cur {
+			info[k] = v
+		}
+	}
+	info["path"] = path
+	info["filename"] = filename
+	meta[model.ArtifactInfoKey] = info
+
+	return &model.Artifact{
+		DiskID:    diskID,
+		Path:      path,
+		Filename:  filename,
+		Meta:      meta,
+		AssetMeta: src.AssetMeta,
+	}
+}
+
+type ExportArtifactsInput struct {
+	DiskID  uuid.UUID
+	Path    string // directory to export, ending with '/'
+	Format  string // one of ArchiveFormat*
+	UserKEK []byte // optional: for envelope encryption
+}
+
+// Export writes every artifact under in.Path to w as an archive, with entry names relative to
+// in.Path. Each artifact is streamed from storage into the archive, decrypting as it goes, so
+// memory use does not grow with file or disk size.
+func (s *artifactService) Export(ctx context.Context, w io.Writer, in ExportArtifactsInput) error {
+	artifacts, err := s.r.ListByPathPrefix(ctx, in.DiskID, in.Path)
+	if err != nil {
+		return fmt.Errorf("list artifacts: %w", err)
+	}
+
+	aw, err := newArchiveWriter(in.Format, w)
+	if err != nil {
+		return err
+	}
+	for _, a := range artifacts {
+		if err := s.exportArtifact(ctx, aw, a, strings.TrimPrefix(a.Path, in.Path)+a.Filename, in.UserKEK); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+// exportArtifact streams one artifact's content into the archive without buffering the file.
+func (s *artifactService) exportArtifact(ctx context.Context, aw *archiveWriter, a *model.Artifact, name string, userKEK []byte) error {
+	obj, err := s.OpenContent(ctx, a, userKEK)
+	if err != nil {
+		return fmt.Errorf("open %s%s: %w", a.Path, a.Filename, err)
+	}
+	r, err := obj.NewRangeReader(ctx, 0, -1)
+	if err != nil {
+		return fmt.Errorf("read %s%s: %w", a.Path, a.Filename, err)
+	}
+	defer r.Close()
+	if err := aw.WriteFile(name, r, obj.Size, a.UpdatedAt); err != nil {
+		return fmt.Errorf("write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+type ImportArtifactsInput struct {
+	ProjectID  uuid.UUID
+	DiskID     uuid.UUID
+	Path       string // destination directory, ending with '/'
+	Format     string // one of ArchiveFormat*
+	Archive    io.ReaderAt
+	Size       int64  // size of Archive in bytes
+	OnConflict string // one of Conflict*, defaults to ConflictFail
+	UserKEK    []byte // optional: for envelope encryption
+}
+
+type ImportArtifactsOutput struct {
+	Artifacts []*model.Artifact `json:"artifacts"` // artifacts created or overwritten, in archive order
+	Skipped   []string          `json:"skipped"`   // destination file paths left untouched because they existed
+}
+
+// importConcurrency bounds the archive members uploaded at once, and so the content held in memory
+const importConcurrency = 4
+
+// Import unpacks an archive into artifacts under in.Path. A single top-level folder wrapping
+// every file is stripped, as is macOS metadata. The archive is checked in full (names, sizes
+// and conflicts) before anything is written; uploads are not transactional, so a failure
+// part way leaves the members written so far in place.
+func (s *artifactService) Import(ctx context.Context, in ImportArtifactsInput) (*ImportArtifactsOutput, error) {
+	if in.OnConflict == "" {
+		in.OnConflict = ConflictFail
+	}
+	var maxFile, maxTotal int64
+	if s.cfg != nil {
+		// members are read into memory to be uploaded
+		maxFile, maxTotal = s.cfg.Artifact.MaxInlineSizeBytes, s.cfg.Artifact.MaxArchiveSizeBytes
+	}
+
+	// Pass 1: list members and validate them without reading content
+	var members []archiveMember
+	var total int64
+	err := eachArchiveFile(in.Format, in.Archive, in.Size, func(m archiveMember, _ func() (io.ReadCloser, error)) error {
+		if maxFile > 0 && m.size > maxFile {
+			return fmt.Errorf("%w: %s exceeds the maximum file size of %d bytes", ErrInvalidArchive, m.name, maxFile)
+		}
+		total += m.size
+		members = append(members, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: archive contains no files", ErrInvalidArchive)
+	}
+	if len(members) > MaxTransferArtifacts {
+		return nil, fmt.Errorf("%w: archive holds %d files, at most %d can be imported at once", ErrInvalidArchive, len(members), MaxTransferArtifacts)
+	}
+	if maxTotal > 0 && total > maxTotal {
+		return nil, fmt.Errorf("%w: extracted size exceeds the maximum of %d bytes", ErrInvalidArchive, maxTotal)
+	}
+
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.name
+	}
+	rootPrefix := archiveRootPrefix(names)
+
+	type destination struct{ path, filename string }
+	dests := make(map[string]destination, len(members))
+	for _, name := range names {
+		dir, filename := pathutil.SplitFilePath(in.Path + strings.TrimPrefix(name, rootPrefix))
+		if err := pathutil.ValidatePath(dir); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+		}
+		dests[name] = destination{path: dir, filename: filename}
+	}
+
+	existing, err := s.r.ListByPathPrefix(ctx, in.DiskID, in.Path)
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	occupied := make(map[string]struct{}, len(existing))
+	for _, a := range existing {
+		occupied[a.Path+a.Filename] = struct{}{}
+	}
+
+	out := &ImportArtifactsOutput{Artifacts: make([]*model.Artifact, 0, len(members)), Skipped: make([]string, 0)}
+	skip := make(map[string]bool)
+	for _, name := range names {
+		d := dests[name]
+		if _, ok := occupied[d.path+d.filename]; !ok {
+			continue
+		}
+		switch in.OnConflict {
+		case ConflictFail:
+			return nil, fmt.Errorf("%w: %s%s", ErrArtifactConflict, d.path, d.filename)
+		case ConflictSkip:
+			skip[name] = true
+			out.Skipped = append(out.Skipped, d.path+d.filename)
+		}
+	}
+
+	// Pass 2: read each member and upload it, a few at a time
+	results := make([]*model.Artifact, len(members))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(importConcurrency)
+	i := -1
+	err = eachArchiveFile(in.Format, in.Archive, in.Size, func(m archiveMember, open func() (io.ReadCloser, error)) error {
+		i++
+		if skip[m.name] {
+			return nil
+		}
+		if err := gctx.Err(); err != nil {
+			return err
+		}
+
+		rc, err := open()
+		if err != nil {
+			return fmt.Errorf("%w: open %s: %v", ErrInvalidArchive, m.name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, m.size+1))
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%w: read %s: %v", ErrInvalidArchive, m.name, err)
+		}
+		if int64(len(content)) != m.size {
+			return fmt.Errorf("%w: %s does not match its declared size", ErrInvalidArchive, m.name)
+		}
+
+		idx, d := i, dests[m.name]
+		g.Go(func() error {
+			artifact, err := s.CreateFromBytes(gctx, CreateArtifactFromBytesInput{
+				ProjectID: in.ProjectID,
+				DiskID:    in.DiskID,
+				Path:      d.path,
+				Filename:  d.filename,
+				Content:   content,
+				UserKEK:   in.UserKEK,
+			})
+			if err != nil {
+				return fmt.Errorf("create artifact for %s: %w", m.name, err)
+			}
+			results[idx] = artifact
+			return nil
+		})
+		return nil
+	})
+	if waitErr := g.Wait(); err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range results {
+		if a != nil {
+			out.Artifacts = append(out.Artifacts, a)
+		}
+	}
+	return out, nil
 }
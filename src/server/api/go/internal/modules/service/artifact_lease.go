@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	pathutil "github.com/memodb-io/Acontext/internal/pkg/utils/path"
+)
+
+const (
+	DefaultLeaseTTL = time.Minute
+	MaxLeaseTTL     = time.Hour
+)
+
+// leaseOwnerPattern restricts owner tokens to characters that are safe in headers and storage
+var leaseOwnerPattern = regexp.MustCompile(`^[A-Za-z0-9._:@-]{1,128}$`)
+
+// ArtifactLeaseService manages advisory leases that let one owner at a time write a file, or
+// everything under a directory. Leases are not enforced by the storage layer; writers check
+// them with CheckWrite.
+type ArtifactLeaseService interface {
+	Acquire(ctx context.Context, in ArtifactLeaseInput) (*model.ArtifactLease, error)
+	Renew(ctx context.Context, in ArtifactLeaseInput) (*model.ArtifactLease, error)
+	Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error
+	CheckWrite(ctx context.Context, diskID uuid.UUID, owner string, paths ...string) error
+}
+
+type artifactLeaseService struct {
+	r repo.ArtifactLeaseRepo
+}
+
+func NewArtifactLeaseService(r repo.ArtifactLeaseRepo) ArtifactLeaseService {
+	return &artifactLeaseService{r: r}
+}
+
+type ArtifactLeaseInput struct {
+	DiskID uuid.UUID
+	Path   string        // file path, or directory path ending with '/' to lock everything below it
+	Owner  string        // token identifying the holder; writes must present it
+	TTL    time.Duration // defaults to DefaultLeaseTTL, at most MaxLeaseTTL
+}
+
+func (in *ArtifactLeaseInput) validate() error {
+	if in.TTL == 0 {
+		in.TTL = DefaultLeaseTTL
+	}
+	if in.TTL < time.Second || in.TTL > MaxLeaseTTL {
+		return fmt.Errorf("%w: ttl must be between 1s and %s", ErrInvalidLease, MaxLeaseTTL)
+	}
+	if !leaseOwnerPattern.MatchString(in.Owner) {
+		return fmt.Errorf("%w: owner must be 1-128 letters, digits or ._:@-", ErrInvalidLease)
+	}
+	if !strings.HasPrefix(in.Path, "/") {
+		return fmt.Errorf("%w: path must start with '/'", ErrInvalidLease)
+	}
+	dir, _ := pathutil.SplitFilePath(in.Path)
+	if err := pathutil.ValidatePath(dir); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidLease, err)
+	}
+	return nil
+}
+
+func (s *artifactLeaseService) Acquire(ctx context.Context, in ArtifactLeaseInput) (*model.ArtifactLease, error) {
+	return s.write(ctx, in, s.r.Acquire)
+}
+
+func (s *artifactLeaseService) Renew(ctx context.Context, in ArtifactLeaseInput) (*model.ArtifactLease, error) {
+	return s.write(ctx, in, s.r.Renew)
+}
+
+func (s *artifactLeaseService) write(ctx context.Context, in ArtifactLeaseInput, write func(context.Context, *model.ArtifactLease) error) (*model.ArtifactLease, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	lease := &model.ArtifactLease{
+		DiskID:    in.DiskID,
+		Path:      in.Path,
+		Owner:     in.Owner,
+		ExpiresAt: time.Now().Add(in.TTL),
+	}
+	if err := write(ctx, lease); err != nil {
+		return nil, leaseErr(err)
+	}
+	return lease, nil
+}
+
+func (s *artifactLeaseService) Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error {
+	return leaseErr(s.r.Release(ctx, diskID, path, owner))
+}
+
+// CheckWrite returns ErrArtifactLocked when a live lease of another owner covers any of paths,
+// or, for a directory path, covers anything below it. An empty owner holds no lease.
+func (s *artifactLeaseService) CheckWrite(ctx context.Context, diskID uuid.UUID, owner string, paths ...string) error {
+	leases, err := s.r.ListActive(ctx, diskID)
+	if err != nil {
+		return fmt.Errorf("list leases: %w", err)
+	}
+	for _, l := range leases {
+		if l.Owner == owner {
+			continue
+		}
+		for _, p := range paths {
+			if l.Covers(p) || (model.ArtifactLease{Path: p}).Covers(l.Path) {
+				return fmt.Errorf("%w: %s is locked until %s", ErrArtifactLocked, l.Path, l.ExpiresAt.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
+
+// leaseErr maps lease repository errors to service errors.
+func leaseErr(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrLeaseHeld):
+		return fmt.Errorf("%w: %v", ErrLeaseHeld, err)
+	case errors.Is(err, repo.ErrLeaseNotFound):
+		return fmt.Errorf("%w: %v", ErrLeaseNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockArtifactLeaseRepo is a mock implementation of ArtifactLeaseRepo
+type MockArtifactLeaseRepo struct {
+	mock.Mock
+}
+
+func (m *MockArtifactLeaseRepo) Acquire(ctx context.Context, lease *model.ArtifactLease) error {
+	args := m.Called(ctx, lease)
+	return args.Error(0)
+}
+
+func (m *MockArtifactLeaseRepo) Renew(ctx context.Context, lease *model.ArtifactLease) error {
+	args := m.Called(ctx, lease)
+	return args.Error(0)
+}
+
+func (m *MockArtifactLeaseRepo) Release(ctx context.Context, diskID uuid.UUID, path string, owner string) error {
+	args := m.Called(ctx, diskID, path, owner)
+	return args.Error(0)
+}
+
+func (m *MockArtifactLeaseRepo) ListActive(ctx context.Context, diskID uuid.UUID) ([]*model.ArtifactLease, error) {
+	args := m.Called(ctx, diskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ArtifactLease), args.Error(1)
+}
+
+func TestArtifactLeaseService_Acquire(t *testing.T) {
+	ctx := context.Background()
+	diskID := uuid.New()
+
+	tests := []struct {
+		name    string
+		in      ArtifactLeaseInput
+		setup   func(r *MockArtifactLeaseRepo)
+		wantErr error
+		wantTTL time.Duration
+	}{
+		{
+			name: "default ttl",
+			in:   ArtifactLeaseInput{DiskID: diskID, Path: "/notes/", Owner: "agent-1"},
+			setup: func(r *MockArtifactLeaseRepo) {
+				r.On("Acquire", ctx, mock.MatchedBy(func(l *model.ArtifactLease) bool {
+					return l.DiskID == diskID && l.Path == "/notes/" && l.Owner == "agent-1"
+				})).Return(nil)
+			},
+			wantTTL: DefaultLeaseTTL,
+		},
+		{
+			name:    "ttl too long",
+			in:      ArtifactLeaseInput{DiskID: diskID, Path: "/notes/", Owner: "agent-1", TTL: 2 * time.Hour},
+			wantErr: ErrInvalidLease,
+		},
+		{
+			name:    "owner with spaces",
+			in:      ArtifactLeaseInput{DiskID: diskID, Path: "/notes/", Owner: "agent 1"},
+			wantErr: ErrInvalidLease,
+		},
+		{
+			name:    "relative path",
+			in:      ArtifactLeaseInput{DiskID: diskID, Path: "notes/", Owner: "agent-1"},
+			wantErr: ErrInvalidLease,
+		},
+		{
+			name: "held by another owner",
+			in:   ArtifactLeaseInput{DiskID: diskID, Path: "/notes/a.md", Owner: "agent-1", TTL: time.Minute},
+			setup: func(r *MockArtifactLeaseRepo) {
+				r.On("Acquire", ctx, mock.Anything).Return(fmt.Errorf("%w: /notes/ is locked", repo.ErrLeaseHeld))
+			},
+			wantErr: ErrLeaseHeld,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockArtifactLeaseRepo{}
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			svc := NewArtifactLeaseService(r)
+
+			start := time.Now()
+			lease, err := svc.Acquire(ctx, tt.in)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, lease)
+			} else {
+				assert.NoError(t, err)
+				assert.WithinDuration(t, start.Add(tt.wantTTL), lease.ExpiresAt, time.Second)
+			}
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArtifactLeaseService_RenewRelease(t *testing.T) {
+	ctx := context.Background()
+	diskID := uuid.New()
+
+	r := &MockArtifactLeaseRepo{}
+	r.On("Renew", ctx, mock.Anything).Return(fmt.Errorf("%w: /a.md", repo.ErrLeaseNotFound)).Once()
+	r.On("Release", ctx, diskID, "/a.md", "agent-1").Return(fmt.Errorf("%w: /a.md", repo.ErrLeaseNotFound)).Once()
+	svc := NewArtifactLeaseService(r)
+
+	_, err := svc.Renew(ctx, ArtifactLeaseInput{DiskID: diskID, Path: "/a.md", Owner: "agent-1"})
+	assert.ErrorIs(t, err, ErrLeaseNotFound)
+	assert.ErrorIs(t, svc.Release(ctx, diskID, "/a.md", "agent-1"), ErrLeaseNotFound)
+	r.AssertExpectations(t)
+}
+
+func TestArtifactLeaseService_CheckWrite(t *testing.T) {
+	ctx := context.Background()
+	diskID := uuid.New()
+	leases := []*model.ArtifactLease{
+		{DiskID: diskID, Path: "/notes/", Owner: "agent-1", ExpiresAt: time.Now().Add(time.Minute)},
+		{DiskID: diskID, Path: "/data/report.csv", Owner: "agent-2", ExpiresAt: time.Now().Add(time.Minute)},
+	}
+
+	tests := []struct {
+		name   string
+		owner  string
+		paths  []string
+		locked bool
+	}{
+		{name: "file under locked directory", owner: "", paths: []string{"/notes/todo.md"}, locked: true},
+		{name: "holder may write", owner: "agent-1", paths: []string{"/notes/todo.md"}, locked: false},
+		{name: "other owner", owner: "agent-2", paths: []string{"/notes/sub/x.md"}, locked: true},
+		{name: "directory containing locked file", owner: "agent-1", paths: []string{"/data/"}, locked: true},
+		{name: "unlocked path", owner: "", paths: []string{"/other.md"}, locked: false},
+		{name: "any locked path rejects", owner: "", paths: []string{"/other.md", "/data/report.csv"}, locked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MockArtifactLeaseRepo{}
+			r.On("ListActive", ctx, diskID).Return(leases, nil)
+			svc := NewArtifactLeaseService(r)
+
+			err := svc.CheckWrite(ctx, diskID, tt.owner, tt.paths...)
+			if tt.locked {
+				assert.ErrorIs(t, err, ErrArtifactLocked)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("repo error", func(t *testing.T) {
+		r := &MockArtifactLeaseRepo{}
+		r.On("ListActive", ctx, diskID).Return(nil, errors.New("connection refused"))
+		err := NewArtifactLeaseService(r).CheckWrite(ctx, diskID, "", "/a.md")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrArtifactLocked)
+	})
+}
@@ -1,20 +1,33 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/pkg/paging"
 	"github.com/memodb-io/Acontext/internal/pkg/utils/fileparser"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/textedit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // MockArtifactRepo is a mock implementation of ArtifactRepo
@@ -27,13 +40,13 @@ func (m *MockArtifactRepo) Create(ctx context.Context, projectID uuid.UUID, f *m
 	return args.Error(0)
 }
 
-func (m *MockArtifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
-	args := m.Called(ctx, projectID, diskID, path, filename)
+func (m *MockArtifactRepo) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
+	args := m.Called(ctx, projectID, diskID, path, filename, cond)
 	return args.Error(0)
 }
 
-func (m *MockArtifactRepo) Update(ctx context.Context, f *model.Artifact) error {
-	args := m.Called(ctx, f)
+func (m *MockArtifactRepo) Update(ctx context.Context, f *model.Artifact, cond repo.ArtifactPrecondition) error {
+	args := m.Called(ctx, f, cond)
 	return args.Error(0)
 }
 
@@ -66,22 +79,82 @@ func (m *MockArtifactRepo) ExistsByPathAndFilename(ctx context.Context, diskID u
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockArtifactRepo) GrepArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
-	args := m.Called(ctx, diskID, pattern, limit)
+func (m *MockArtifactRepo) GrepArtifacts(ctx context.Context, diskID uuid.UUID, q repo.ArtifactGrepQuery, limit int) ([]*model.Artifact, error) {
+	args := m.Called(ctx, diskID, q, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Artifact), args.Error(1)
 }
 
-func (m *MockArtifactRepo) GlobArtifacts(ctx context.Context, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
-	args := m.Called(ctx, diskID, pattern, limit)
+func (m *MockArtifactRepo) GlobArtifacts(ctx context.Context, diskID uuid.UUID, q repo.ArtifactGlobQuery, limit int) ([]*model.Artifact, error) {
+	args := m.Called(ctx, diskID, q, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Artifact), args.Error(1)
 }
 
+func (m *MockArtifactRepo) Upsert(ctx context.Context, projectID uuid.UUID, a *model.Artifact, policy repo.ArtifactVersionPolicy, cond repo.ArtifactPrecondition) error {
+	args := m.Called(ctx, projectID, a, policy, cond)
+	return args.Error(0)
+}
+
+func (m *MockArtifactRepo) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	args := m.Called(ctx, diskID, path, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ArtifactVersion), args.Error(1)
+}
+
+func (m *MockArtifactRepo) GetVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.ArtifactVersion, error) {
+	args := m.Called(ctx, diskID, path, filename, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ArtifactVersion), args.Error(1)
+}
+
+func (m *MockArtifactRepo) ListByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]*model.Artifact, error) {
+	args := m.Called(ctx, diskID, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactRepo) Move(ctx context.Context, projectID uuid.UUID, transfers []repo.ArtifactTransfer, overwrite bool, policy repo.ArtifactVersionPolicy) error {
+	args := m.Called(ctx, projectID, transfers, overwrite, policy)
+	return args.Error(0)
+}
+
+func (m *MockArtifactRepo) Copy(ctx context.Context, projectID uuid.UUID, transfers []repo.ArtifactTransfer, overwrite bool, policy repo.ArtifactVersionPolicy) error {
+	args := m.Called(ctx, projectID, transfers, overwrite, policy)
+	return args.Error(0)
+}
+
+func (m *MockArtifactRepo) ListTree(ctx context.Context, diskID uuid.UUID, prefix string, maxDepth int, afterPath string, afterFilename string, limit int) ([]*model.Artifact, error) {
+	args := m.Called(ctx, diskID, prefix, maxDepth, afterPath, afterFilename, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactRepo) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	args := m.Called(ctx, projectID, diskID, prefix)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockArtifactRepo) SummarizeByPathPrefix(ctx context.Context, diskID uuid.UUID, prefix string) ([]repo.ArtifactPathUsage, error) {
+	args := m.Called(ctx, diskID, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repo.ArtifactPathUsage), args.Error(1)
+}
+
 // MockArtifactS3Deps is a mock implementation of blob.S3Deps for file service
 type MockArtifactS3Deps struct {
 	mock.Mock
@@ -219,11 +292,11 @@ func (s *testArtifactService) Create(ctx context.Context, in CreateArtifactInput
 	return file, nil
 }
 
-func (s *testArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string) error {
+func (s *testArtifactService) DeleteByPath(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, cond repo.ArtifactPrecondition) error {
 	if path == "" || filename == "" {
 		return errors.New("path and filename are required")
 	}
-	return s.r.DeleteByPath(ctx, projectID, diskID, path, filename)
+	return s.r.DeleteByPath(ctx, projectID, diskID, path, filename, cond)
 }
 
 func (s *testArtifactService) GetByPath(ctx context.Context, diskID uuid.UUID, path string, filename string) (*model.Artifact, error) {
@@ -254,7 +327,7 @@ func (s *testArtifactService) GetAllPaths(ctx context.Context, diskID uuid.UUID)
 	return s.r.GetAllPaths(ctx, diskID)
 }
 
-func (s *testArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}) (*model.Artifact, error) {
+func (s *testArtifactService) UpdateArtifactMetaByPath(ctx context.Context, diskID uuid.UUID, path string, filename string, userMeta map[string]interface{}, cond repo.ArtifactPrecondition) (*model.Artifact, error) {
 	// Get existing artifact
 	artifact, err := s.GetByPath(ctx, diskID, path, filename)
 	if err != nil {
@@ -285,7 +358,7 @@ func (s *testArtifactService) UpdateArtifactMetaByPath(ctx context.Context, disk
 	// Update artifact meta
 	artifact.Meta = newMeta
 
-	if err := s.r.Update(ctx, artifact); err != nil {
+	if err := s.r.Update(ctx, artifact, cond); err != nil {
 		return nil, err
 	}
 
@@ -312,9 +385,13 @@ func (s *testArtifactService) DownloadRawContent(ctx context.Context, artifact *
 	return []byte("test content"), "application/octet-stream", nil
 }
 
-func (s *testArtifactService) GrepArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
+func (s *testArtifactService) OpenContent(ctx context.Context, artifact *model.Artifact, userKEK []byte) (*blob.Object, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *testArtifactService) GrepArtifacts(ctx context.Context, in GrepArtifactsInput) ([]*ArtifactGrepResult, error) {
 	// Test implementation - return empty list for now
-	return []*model.Artifact{}, nil
+	return []*ArtifactGrepResult{}, nil
 }
 
 func (s *testArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, pattern string, limit int) ([]*model.Artifact, error) {
@@ -322,6 +399,58 @@ func (s *testArtifactService) GlobArtifacts(ctx context.Context, projectID uuid.
 	return []*model.Artifact{}, nil
 }
 
+func (s *testArtifactService) ListVersions(ctx context.Context, diskID uuid.UUID, path string, filename string) ([]*model.ArtifactVersion, error) {
+	return s.r.ListVersions(ctx, diskID, path, filename)
+}
+
+func (s *testArtifactService) GetByPathAtVersion(ctx context.Context, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	return s.GetByPath(ctx, diskID, path, filename)
+}
+
+func (s *testArtifactService) RestoreVersion(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, path string, filename string, version int) (*model.Artifact, error) {
+	return s.GetByPath(ctx, diskID, path, filename)
+}
+
+func (s *testArtifactService) Move(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	return &TransferArtifactsOutput{}, nil
+}
+
+func (s *testArtifactService) Copy(ctx context.Context, in TransferArtifactsInput) (*TransferArtifactsOutput, error) {
+	return &TransferArtifactsOutput{}, nil
+}
+
+func (s *testArtifactService) ListTree(ctx context.Context, in ListArtifactTreeInput) (*ListArtifactTreeOutput, error) {
+	return &ListArtifactTreeOutput{}, nil
+}
+
+func (s *testArtifactService) DeleteByPathPrefix(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, prefix string) (int64, error) {
+	return s.r.DeleteByPathPrefix(ctx, projectID, diskID, prefix)
+}
+
+func (s *testArtifactService) DiskUsage(ctx context.Context, diskID uuid.UUID, path string, maxDepth int) ([]DirectoryUsage, error) {
+	return nil, nil
+}
+
+func (s *testArtifactService) Export(ctx context.Context, w io.Writer, in ExportArtifactsInput) error {
+	return nil
+}
+
+func (s *testArtifactService) Import(ctx context.Context, in ImportArtifactsInput) (*ImportArtifactsOutput, error) {
+	return &ImportArtifactsOutput{}, nil
+}
+
+func (s *testArtifactService) PresignUpload(ctx context.Context, projectID uuid.UUID, contentType string, expire time.Duration) (*PresignedUpload, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *testArtifactService) Commit(ctx context.Context, in CommitArtifactInput) (*model.Artifact, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *testArtifactService) Edit(ctx context.Context, in EditArtifactInput) (*model.Artifact, error) {
+	return s.GetByPath(ctx, in.DiskID, in.Path, in.Filename)
+}
+
 func (s *testArtifactService) CreateFromBytes(ctx context.Context, in CreateArtifactFromBytesInput) (*model.Artifact, error) {
 	// Check if artifact with same path and filename already exists in the same disk
 	exists, err := s.r.ExistsByPathAndFilename(ctx, in.DiskID, in.Path, in.Filename, nil)
@@ -491,7 +620,7 @@ func TestArtifactService_UpdateArtifactMetaByPath(t *testing.T) {
 						return false
 					}
 					return true
-				})).Return(nil)
+				}), mock.Anything).Return(nil)
 			},
 			expectError: false,
 		},
@@ -536,7 +665,7 @@ func TestArtifactService_UpdateArtifactMetaByPath(t *testing.T) {
 				existingArtifact.Filename = filename
 
 				repo.On("GetByPath", mock.Anything, diskID, path, filename).Return(existingArtifact, nil)
-				repo.On("Update", mock.Anything, mock.Anything).Return(errors.New("update error"))
+				repo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("update error"))
 			},
 			expectError: true,
 			errorMsg:    "update error",
@@ -550,7 +679,7 @@ func TestArtifactService_UpdateArtifactMetaByPath(t *testing.T) {
 
 			service := newTestArtifactService(mockRepo, &MockArtifactS3Deps{})
 
-			artifact, err := service.UpdateArtifactMetaByPath(context.Background(), diskID, path, filename, tt.userMeta)
+			artifact, err := service.UpdateArtifactMetaByPath(context.Background(), diskID, path, filename, tt.userMeta, repo.ArtifactPrecondition{})
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -575,74 +704,141 @@ func TestArtifactService_UpdateArtifactMetaByPath(t *testing.T) {
 }
 
 func TestArtifactService_GrepArtifacts(t *testing.T) {
+	ctx := context.Background()
+	diskID := uuid.New()
+	textArtifact := func(path, filename, content string) *model.Artifact {
+		return &model.Artifact{DiskID: diskID, Path: path, Filename: filename,
+			AssetMeta: datatypes.NewJSONType(model.Asset{MIME: "text/plain", Content: content})}
+	}
+	mainPy := textArtifact("/src/", "main.py", "import os\n# TODO: retry\nprint(1)\n# todo: log\n")
+	readme := textArtifact("/", "README.md", "a\nTODO\nb\n")
+
 	tests := []struct {
 		name      string
-		pattern   string
-		limit     int
+		in        GrepArtifactsInput
 		setupMock func(*MockArtifactRepo)
-		wantCount int
-		wantErr   bool
+		check     func(*testing.T, []*ArtifactGrepResult)
+		wantErr   error
 	}{
 		{
-			name:    "successful search with default limit",
-			pattern: "TODO",
-			limit:   0, // Should default to 100
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GrepArtifacts", mock.Anything, mock.Anything, "TODO", 100).
-					Return([]*model.Artifact{
-						{Filename: "test.py", Path: "/"},
-					}, nil)
+			name: "line numbers and context with default limit",
+			in:   GrepArtifactsInput{Pattern: "todo", Before: 1, After: 1},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, repo.ArtifactGrepQuery{Pattern: "todo"}, grepBatchSize).
+					Return([]*model.Artifact{mainPy}, nil)
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Len(t, results, 1)
+				assert.Equal(t, "main.py", results[0].Filename)
+				assert.Equal(t, []GrepMatch{
+					{LineNumber: 2, Line: "# TODO: retry", Before: []string{"import os"}, After: []string{"print(1)"}},
+					{LineNumber: 4, Line: "# todo: log", Before: []string{"print(1)"}},
+				}, results[0].Matches)
+				assert.False(t, results[0].Truncated)
 			},
-			wantCount: 1,
-			wantErr:   false,
 		},
 		{
-			name:    "limit capped at 1000",
-			pattern: "function",
-			limit:   5000, // Should be capped to 1000
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GrepArtifacts", mock.Anything, mock.Anything, "function", 1000).
-					Return([]*model.Artifact{}, nil)
+			name: "case sensitive",
+			in:   GrepArtifactsInput{Pattern: "TODO", CaseSensitive: true},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, repo.ArtifactGrepQuery{Pattern: "TODO", CaseSensitive: true}, grepBatchSize).
+					Return([]*model.Artifact{mainPy}, nil)
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Len(t, results[0].Matches, 1)
+				assert.Equal(t, 2, results[0].Matches[0].LineNumber)
 			},
-			wantCount: 0,
-			wantErr:   false,
 		},
 		{
-			name:    "custom limit",
-			pattern: "import",
-			limit:   50,
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GrepArtifacts", mock.Anything, mock.Anything, "import", 50).
-					Return([]*model.Artifact{
-						{Filename: "main.py", Path: "/"},
-						{Filename: "utils.py", Path: "/"},
-					}, nil)
+			name: "fixed string does not interpret regex",
+			in:   GrepArtifactsInput{Pattern: "print(", FixedString: true},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, repo.ArtifactGrepQuery{Pattern: "print(", FixedString: true}, grepBatchSize).
+					Return([]*model.Artifact{mainPy}, nil)
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Equal(t, "print(1)", results[0].Matches[0].Line)
 			},
-			wantCount: 2,
-			wantErr:   false,
+		},
+		{
+			name: "include and exclude globs",
+			in:   GrepArtifactsInput{Pattern: "todo", Include: []string{"*.md", "/src/*"}, Exclude: []string{"main.*"}},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, mock.Anything, grepBatchSize).
+					Return([]*model.Artifact{readme, mainPy}, nil)
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Len(t, results, 1)
+				assert.Equal(t, "README.md", results[0].Filename)
+			},
+		},
+		{
+			name: "max count per file",
+			in:   GrepArtifactsInput{Pattern: "todo", MaxCount: 1},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, mock.Anything, grepBatchSize).
+					Return([]*model.Artifact{mainPy}, nil)
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Len(t, results[0].Matches, 1)
+				assert.True(t, results[0].Truncated)
+			},
+		},
+		{
+			name: "pages past candidates without matching lines",
+			in:   GrepArtifactsInput{Pattern: "todo", Limit: 1},
+			setupMock: func(r *MockArtifactRepo) {
+				first := make([]*model.Artifact, grepBatchSize)
+				for i := range first {
+					first[i] = textArtifact("/a/", fmt.Sprintf("f%03d.txt", i), "no match here")
+				}
+				r.On("GrepArtifacts", mock.Anything, diskID, repo.ArtifactGrepQuery{Pattern: "todo"}, grepBatchSize).
+					Return(first, nil).Once()
+				r.On("GrepArtifacts", mock.Anything, diskID, repo.ArtifactGrepQuery{Pattern: "todo", AfterPath: "/a/", AfterFilename: "f099.txt"}, grepBatchSize).
+					Return([]*model.Artifact{mainPy, readme}, nil).Once()
+			},
+			check: func(t *testing.T, results []*ArtifactGrepResult) {
+				assert.Len(t, results, 1)
+				assert.Equal(t, "main.py", results[0].Filename)
+			},
+		},
+		{
+			name:    "invalid regex",
+			in:      GrepArtifactsInput{Pattern: "(unclosed"},
+			wantErr: ErrInvalidGrep,
+		},
+		{
+			name:    "context too large",
+			in:      GrepArtifactsInput{Pattern: "todo", After: MaxGrepContext + 1},
+			wantErr: ErrInvalidGrep,
+		},
+		{
+			name: "pattern postgres cannot compile",
+			in:   GrepArtifactsInput{Pattern: `\p{Greek}`},
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GrepArtifacts", mock.Anything, diskID, mock.Anything, grepBatchSize).
+					Return(nil, fmt.Errorf("%w: ERROR: invalid regular expression (SQLSTATE 2201B)", repo.ErrInvalidPattern))
+			},
+			wantErr: ErrInvalidGrep,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(MockArtifactRepo)
-			tt.setupMock(mockRepo)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
 
 			svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+			tt.in.DiskID = diskID
+			results, err := svc.GrepArtifacts(ctx, tt.in)
 
-			results, err := svc.GrepArtifacts(
-				context.Background(),
-				uuid.New(),
-				uuid.New(),
-				tt.pattern,
-				tt.limit,
-			)
-
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, results, tt.wantCount)
+				tt.check(t, results)
 			}
 			mockRepo.AssertExpectations(t)
 		})
@@ -657,11 +853,11 @@ func TestArtifactService_TouchSkillUpdatedAt(t *testing.T) {
 		mockSkillsRepo := &MockAgentSkillsRepo{}
 		projectID := uuid.New()
 
-		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt").Return(nil)
+		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt", mock.Anything).Return(nil)
 		mockSkillsRepo.On("TouchUpdatedAtByDiskID", mock.Anything, diskID).Return(nil)
 
 		svc := &artifactService{r: mockRepo, agentSkillsRepo: mockSkillsRepo, log: zap.NewNop()}
-		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt")
+		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt", repo.ArtifactPrecondition{})
 
 		assert.NoError(t, err)
 		mockSkillsRepo.AssertCalled(t, "TouchUpdatedAtByDiskID", mock.Anything, diskID)
@@ -672,10 +868,10 @@ func TestArtifactService_TouchSkillUpdatedAt(t *testing.T) {
 		mockSkillsRepo := &MockAgentSkillsRepo{}
 		projectID := uuid.New()
 
-		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt").Return(errors.New("delete failed"))
+		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt", mock.Anything).Return(errors.New("delete failed"))
 
 		svc := &artifactService{r: mockRepo, agentSkillsRepo: mockSkillsRepo, log: zap.NewNop()}
-		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt")
+		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt", repo.ArtifactPrecondition{})
 
 		assert.Error(t, err)
 		mockSkillsRepo.AssertNotCalled(t, "TouchUpdatedAtByDiskID")
@@ -689,11 +885,11 @@ func TestArtifactService_TouchSkillUpdatedAt(t *testing.T) {
 		existingArtifact.DiskID = diskID
 
 		mockRepo.On("GetByPath", mock.Anything, diskID, "/test/", "file.txt").Return(existingArtifact, nil)
-		mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 		mockSkillsRepo.On("TouchUpdatedAtByDiskID", mock.Anything, diskID).Return(nil)
 
 		svc := &artifactService{r: mockRepo, agentSkillsRepo: mockSkillsRepo, log: zap.NewNop()}
-		_, err := svc.UpdateArtifactMetaByPath(context.Background(), diskID, "/test/", "file.txt", map[string]interface{}{"key": "val"})
+		_, err := svc.UpdateArtifactMetaByPath(context.Background(), diskID, "/test/", "file.txt", map[string]interface{}{"key": "val"}, repo.ArtifactPrecondition{})
 
 		assert.NoError(t, err)
 		mockSkillsRepo.AssertCalled(t, "TouchUpdatedAtByDiskID", mock.Anything, diskID)
@@ -704,11 +900,11 @@ func TestArtifactService_TouchSkillUpdatedAt(t *testing.T) {
 		mockSkillsRepo := &MockAgentSkillsRepo{}
 		projectID := uuid.New()
 
-		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt").Return(nil)
+		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt", mock.Anything).Return(nil)
 		mockSkillsRepo.On("TouchUpdatedAtByDiskID", mock.Anything, diskID).Return(errors.New("touch failed"))
 
 		svc := &artifactService{r: mockRepo, agentSkillsRepo: mockSkillsRepo, log: zap.NewNop()}
-		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt")
+		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt", repo.ArtifactPrecondition{})
 
 		// The artifact operation should still succeed even though touch failed
 		assert.NoError(t, err)
@@ -719,10 +915,10 @@ func TestArtifactService_TouchSkillUpdatedAt(t *testing.T) {
 		mockRepo := &MockArtifactRepo{}
 		projectID := uuid.New()
 
-		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt").Return(nil)
+		mockRepo.On("DeleteByPath", mock.Anything, projectID, diskID, "/test/", "file.txt", mock.Anything).Return(nil)
 
 		svc := &artifactService{r: mockRepo, agentSkillsRepo: nil, log: zap.NewNop()}
-		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt")
+		err := svc.DeleteByPath(context.Background(), projectID, diskID, "/test/", "file.txt", repo.ArtifactPrecondition{})
 
 		assert.NoError(t, err)
 	})
@@ -755,62 +951,125 @@ func TestAgentSkillsService_TouchByDiskID(t *testing.T) {
 }
 
 func TestArtifactService_GlobArtifacts(t *testing.T) {
+	firstPage := func(like string) repo.ArtifactGlobQuery {
+		return repo.ArtifactGlobQuery{LikePattern: like}
+	}
+	// a full batch of candidates that pass the LIKE prefilter but not the glob
+	prefilterOnly := make([]*model.Artifact, globBatchSize)
+	for i := range prefilterOnly {
+		prefilterOnly[i] = &model.Artifact{Path: fmt.Sprintf("/a/%03d/", i), Filename: "x.py"}
+	}
+
 	tests := []struct {
 		name      string
 		pattern   string
 		limit     int
 		setupMock func(*MockArtifactRepo)
-		wantCount int
-		wantErr   bool
+		wantPaths []string
+		wantErr   error
 	}{
 		{
-			name:    "successful glob with wildcard",
+			name:    "star does not cross directories",
 			pattern: "*.py",
 			limit:   100,
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GlobArtifacts", mock.Anything, mock.Anything, "*.py", 100).
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/%.py"), globBatchSize).
 					Return([]*model.Artifact{
 						{Filename: "test.py", Path: "/"},
 						{Filename: "main.py", Path: "/src/"},
 					}, nil)
 			},
-			wantCount: 2,
-			wantErr:   false,
+			wantPaths: []string{"/test.py"},
 		},
 		{
 			name:    "no results",
 			pattern: "*.xyz",
 			limit:   100,
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GlobArtifacts", mock.Anything, mock.Anything, "*.xyz", 100).
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/%.xyz"), globBatchSize).
 					Return([]*model.Artifact{}, nil)
 			},
-			wantCount: 0,
-			wantErr:   false,
+			wantPaths: []string{},
 		},
 		{
 			name:    "exact path match without wildcards",
 			pattern: "/src/main.py",
 			limit:   100,
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GlobArtifacts", mock.Anything, mock.Anything, "/src/main.py", 100).
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/src/main.py"), globBatchSize).
 					Return([]*model.Artifact{
 						{Filename: "main.py", Path: "/src/"},
 					}, nil)
 			},
-			wantCount: 1,
-			wantErr:   false,
+			wantPaths: []string{"/src/main.py"},
+		},
+		{
+			name:    "literal percent is escaped",
+			pattern: "100%.txt",
+			limit:   100,
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage(`/100\%.txt`), globBatchSize).
+					Return([]*model.Artifact{{Filename: "100%.txt", Path: "/"}}, nil)
+			},
+			wantPaths: []string{"/100%.txt"},
+		},
+		{
+			name:    "alternation and classes",
+			pattern: "**/*.{go,py}",
+			limit:   100,
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/%.%"), globBatchSize).
+					Return([]*model.Artifact{
+						{Filename: "main.go", Path: "/"},
+						{Filename: "notes.md", Path: "/docs/"},
+						{Filename: "app.py", Path: "/src/pkg/"},
+					}, nil)
+			},
+			wantPaths: []string{"/main.go", "/src/pkg/app.py"},
 		},
 		{
-			name:    "limit enforcement",
+			name:    "pages past candidates that fail the exact match",
+			pattern: "a/*.py",
+			limit:   100,
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/a/%.py"), globBatchSize).
+					Return(prefilterOnly, nil).Once()
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, repo.ArtifactGlobQuery{
+					LikePattern:   "/a/%.py",
+					AfterPath:     "/a/499/",
+					AfterFilename: "x.py",
+				}, globBatchSize).
+					Return([]*model.Artifact{{Filename: "y.py", Path: "/a/"}}, nil).Once()
+			},
+			wantPaths: []string{"/a/y.py"},
+		},
+		{
+			name:    "stops at limit",
 			pattern: "**/*.txt",
-			limit:   0, // Should default to 100
-			setupMock: func(repo *MockArtifactRepo) {
-				repo.On("GlobArtifacts", mock.Anything, mock.Anything, "**/*.txt", 100).
-					Return([]*model.Artifact{{Filename: "readme.txt", Path: "/"}}, nil)
+			limit:   2,
+			setupMock: func(r *MockArtifactRepo) {
+				r.On("GlobArtifacts", mock.Anything, mock.Anything, firstPage("/%.txt"), globBatchSize).
+					Return([]*model.Artifact{
+						{Filename: "a.txt", Path: "/"},
+						{Filename: "b.txt", Path: "/"},
+						{Filename: "c.txt", Path: "/"},
+					}, nil)
 			},
-			wantCount: 1,
-			wantErr:   false,
+			wantPaths: []string{"/a.txt", "/b.txt"},
+		},
+		{
+			name:      "invalid glob",
+			pattern:   "*.{go,py",
+			limit:     100,
+			setupMock: func(r *MockArtifactRepo) {},
+			wantErr:   ErrInvalidGlob,
+		},
+		{
+			name:      "empty pattern",
+			pattern:   "",
+			limit:     100,
+			setupMock: func(r *MockArtifactRepo) {},
+			wantErr:   ErrInvalidGlob,
 		},
 	}
 
@@ -829,13 +1088,857 @@ func TestArtifactService_GlobArtifacts(t *testing.T) {
 				tt.limit,
 			)
 
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, results, tt.wantCount)
+				paths := make([]string, 0, len(results))
+				for _, a := range results {
+					paths = append(paths, a.Path+a.Filename)
+				}
+				assert.Equal(t, tt.wantPaths, paths)
 			}
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestArtifactService_Versions(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	current := createTestArtifact()
+	current.Version = 3
+	diskID, path, filename := current.DiskID, current.Path, current.Filename
+	archivedAt := time.Now().Add(-time.Hour)
+	v2 := &model.ArtifactVersion{
+		DiskID:    diskID,
+		Path:      path,
+		Filename:  filename,
+		Version:   2,
+		Meta:      map[string]interface{}{"note": "v2"},
+		AssetMeta: datatypes.NewJSONType(model.Asset{S3Key: "disks/v2.txt", SHA256: "sha-v2", MIME: "text/plain"}),
+		CreatedAt: archivedAt,
+	}
+	cfg := &config.Config{Artifact: config.ArtifactCfg{MaxVersions: 5, VersionRetentionDays: 7}}
+	policy := repo.ArtifactVersionPolicy{Keep: 5, MaxAge: 7 * 24 * time.Hour}
+
+	t.Run("current version is served from the artifact", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, path, filename).Return(current, nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		got, err := svc.GetByPathAtVersion(ctx, diskID, path, filename, 3)
+
+		assert.NoError(t, err)
+		assert.Same(t, current, got)
+		mockRepo.AssertNotCalled(t, "GetVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("older version keeps the artifact identity with the version content", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, path, filename).Return(current, nil)
+		mockRepo.On("GetVersion", ctx, diskID, path, filename, 2).Return(v2, nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		got, err := svc.GetByPathAtVersion(ctx, diskID, path, filename, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, current.ID, got.ID)
+		assert.Equal(t, 2, got.Version)
+		assert.Equal(t, "disks/v2.txt", got.AssetMeta.Data().S3Key)
+		assert.Equal(t, archivedAt, got.UpdatedAt)
+	})
+
+	t.Run("pruned version is not found", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, path, filename).Return(current, nil)
+		mockRepo.On("GetVersion", ctx, diskID, path, filename, 1).Return(nil, gorm.ErrRecordNotFound)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		_, err := svc.GetByPathAtVersion(ctx, diskID, path, filename, 1)
+
+		assert.ErrorIs(t, err, ErrArtifactVersionNotFound)
+	})
+
+	t.Run("restore upserts the version content under the configured policy", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, path, filename).Return(current, nil)
+		mockRepo.On("GetVersion", ctx, diskID, path, filename, 2).Return(v2, nil)
+		mockRepo.On("Upsert", ctx, projectID, mock.MatchedBy(func(a *model.Artifact) bool {
+			return a.DiskID == diskID && a.Path == path && a.Filename == filename &&
+				a.AssetMeta.Data().SHA256 == "sha-v2" && a.Meta["note"] == "v2"
+		}), policy, repo.ArtifactPrecondition{}).Return(nil)
+
+		svc := &artifactService{r: mockRepo, cfg: cfg, log: zap.NewNop()}
+		_, err := svc.RestoreVersion(ctx, projectID, diskID, path, filename, 2)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("restoring the current version is a no-op", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, path, filename).Return(current, nil)
+
+		svc := &artifactService{r: mockRepo, cfg: cfg, log: zap.NewNop()}
+		got, err := svc.RestoreVersion(ctx, projectID, diskID, path, filename, 3)
+
+		assert.NoError(t, err)
+		assert.Same(t, current, got)
+		mockRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestArtifactService_Transfer(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	otherDiskID := uuid.New()
+	newArtifact := func(path, filename string) *model.Artifact {
+		return &model.Artifact{
+			ID:       uuid.New(),
+			DiskID:   diskID,
+			Path:     path,
+			Filename: filename,
+			Meta: map[string]interface{}{
+				model.ArtifactInfoKey: map[string]interface{}{"path": path, "filename": filename, "mime": "text/plain"},
+				"owner":               "agent",
+			},
+			AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-" + filename}),
+			Version:   2,
+		}
+	}
+
+	t.Run("rename a file rewrites its location and system meta", func(t *testing.T) {
+		src := newArtifact("/notes/", "todo.md")
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/notes/", "todo.md").Return(src, nil)
+		mockRepo.On("ExistsByPathAndFilename", ctx, diskID, "/archive/", "done.md", (*uuid.UUID)(nil)).Return(false, nil)
+		mockRepo.On("Move", ctx, projectID, mock.MatchedBy(func(ts []repo.ArtifactTransfer) bool {
+			if len(ts) != 1 || ts[0].Source != src {
+				return false
+			}
+			dst := ts[0].Dest
+			info := dst.Meta[model.ArtifactInfoKey].(map[string]interface{})
+			return dst.DiskID == diskID && dst.Path == "/archive/" && dst.Filename == "done.md" &&
+				info["path"] == "/archive/" && info["filename"] == "done.md" && info["mime"] == "text/plain" &&
+				dst.Meta["owner"] == "agent"
+		}), false, repo.ArtifactVersionPolicy{}).Return(nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		out, err := svc.Move(ctx, TransferArtifactsInput{ProjectID: projectID, DiskID: diskID, From: "/notes/todo.md", To: "/archive/done.md"})
+
+		assert.NoError(t, err)
+		assert.Len(t, out.Artifacts, 1)
+		assert.Empty(t, out.Skipped)
+		// The source meta must not be mutated
+		assert.Equal(t, "/notes/", src.Meta[model.ArtifactInfoKey].(map[string]interface{})["path"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("copy a directory across disks skipping conflicts", func(t *testing.T) {
+		a := newArtifact("/src/", "a.txt")
+		b := newArtifact("/src/sub/", "b.txt")
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/src/").Return([]*model.Artifact{a, b}, nil)
+		mockRepo.On("ListByPathPrefix", ctx, otherDiskID, "/dst/").Return([]*model.Artifact{{Path: "/dst/", Filename: "a.txt"}}, nil)
+		mockRepo.On("Copy", ctx, projectID, mock.MatchedBy(func(ts []repo.ArtifactTransfer) bool {
+			return len(ts) == 1 && ts[0].Source == b &&
+				ts[0].Dest.DiskID == otherDiskID && ts[0].Dest.Path == "/dst/sub/" && ts[0].Dest.Filename == "b.txt"
+		}), false, repo.ArtifactVersionPolicy{}).Return(nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		out, err := svc.Copy(ctx, TransferArtifactsInput{
+			ProjectID:  projectID,
+			DiskID:     diskID,
+			From:       "/src/",
+			To:         "/dst/",
+			DestDiskID: otherDiskID,
+			OnConflict: ConflictSkip,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, out.Artifacts, 1)
+		assert.Equal(t, []string{"/src/a.txt"}, out.Skipped)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("overwrite passes conflicts through to the repo", func(t *testing.T) {
+		src := newArtifact("/", "a.txt")
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/", "a.txt").Return(src, nil)
+		mockRepo.On("ExistsByPathAndFilename", ctx, diskID, "/b/", "a.txt", (*uuid.UUID)(nil)).Return(true, nil)
+		mockRepo.On("Copy", ctx, projectID, mock.Anything, true, repo.ArtifactVersionPolicy{}).Return(nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		_, err := svc.Copy(ctx, TransferArtifactsInput{ProjectID: projectID, DiskID: diskID, From: "/a.txt", To: "/b/", OnConflict: ConflictOverwrite})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("conflict fails by default", func(t *testing.T) {
+		src := newArtifact("/", "a.txt")
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/", "a.txt").Return(src, nil)
+		mockRepo.On("ExistsByPathAndFilename", ctx, diskID, "/", "b.txt", (*uuid.UUID)(nil)).Return(true, nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		_, err := svc.Move(ctx, TransferArtifactsInput{ProjectID: projectID, DiskID: diskID, From: "/a.txt", To: "/b.txt"})
+
+		assert.ErrorIs(t, err, ErrArtifactConflict)
+		mockRepo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("conflict raced in the repo", func(t *testing.T) {
+		src := newArtifact("/", "a.txt")
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/", "a.txt").Return(src, nil)
+		mockRepo.On("ExistsByPathAndFilename", ctx, diskID, "/", "b.txt", (*uuid.UUID)(nil)).Return(false, nil)
+		mockRepo.On("Move", ctx, projectID, mock.Anything, false, repo.ArtifactVersionPolicy{}).Return(repo.ErrArtifactExists)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		_, err := svc.Move(ctx, TransferArtifactsInput{ProjectID: projectID, DiskID: diskID, From: "/a.txt", To: "/b.txt"})
+
+		assert.ErrorIs(t, err, ErrArtifactConflict)
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/empty/").Return([]*model.Artifact{}, nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		_, err := svc.Copy(ctx, TransferArtifactsInput{ProjectID: projectID, DiskID: diskID, From: "/empty/", To: "/other/"})
+
+		assert.ErrorIs(t, err, ErrArtifactNotFound)
+	})
+
+	invalid := []struct {
+		name string
+		in   TransferArtifactsInput
+		move bool
+	}{
+		{name: "same file", in: TransferArtifactsInput{From: "/a.txt", To: "/a.txt"}, move: true},
+		{name: "directory into itself", in: TransferArtifactsInput{From: "/a/", To: "/a/b/"}},
+		{name: "directory into its parent", in: TransferArtifactsInput{From: "/a/b/", To: "/a/"}, move: true},
+		{name: "directory onto a file", in: TransferArtifactsInput{From: "/a/", To: "/b.txt"}},
+		{name: "unknown conflict mode", in: TransferArtifactsInput{From: "/a.txt", To: "/b.txt", OnConflict: "merge"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &artifactService{r: &MockArtifactRepo{}, log: zap.NewNop()}
+			tt.in.ProjectID, tt.in.DiskID = projectID, diskID
+
+			var err error
+			if tt.move {
+				_, err = svc.Move(ctx, tt.in)
+			} else {
+				_, err = svc.Copy(ctx, tt.in)
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidTransfer)
+		})
+	}
+}
+
+func TestArtifactService_DirectoryTree(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	items := []*model.Artifact{
+		{Path: "/docs/", Filename: "a.md"},
+		{Path: "/docs/sub/", Filename: "b.md"},
+		{Path: "/docs/sub/", Filename: "c.md"},
+	}
+
+	t.Run("list tree pages in path order", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListTree", ctx, diskID, "/docs/", 2, "/docs/", "a.md", 3).Return(items[1:], nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		out, err := svc.ListTree(ctx, ListArtifactTreeInput{
+			DiskID:   diskID,
+			Path:     "/docs/",
+			MaxDepth: 2,
+			Limit:    2,
+			Cursor:   paging.EncodePathCursor("/docs/", "a.md"),
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, out.Items, 2)
+		assert.False(t, out.HasMore)
+		assert.Empty(t, out.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("list tree reports the next cursor", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListTree", ctx, diskID, "/docs/", 0, "", "", 3).Return(items, nil)
+
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+		out, err := svc.ListTree(ctx, ListArtifactTreeInput{DiskID: diskID, Path: "/docs/", Limit: 2})
+
+		assert.NoError(t, err)
+		assert.True(t, out.HasMore)
+		assert.Equal(t, paging.EncodePathCursor("/docs/sub/", "b.md"), out.NextCursor)
+	})
+
+	t.Run("list tree rejects a malformed cursor", func(t *testing.T) {
+		svc := &artifactService{r: &MockArtifactRepo{}, log: zap.NewNop()}
+		_, err := svc.ListTree(ctx, ListArtifactTreeInput{DiskID: diskID, Path: "/", Limit: 10, Cursor: "%%%"})
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("recursive delete requires a directory path", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.DeleteByPathPrefix(ctx, projectID, diskID, "/docs/a.md")
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "DeleteByPathPrefix", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recursive delete returns the deleted count", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("DeleteByPathPrefix", ctx, projectID, diskID, "/docs/").Return(int64(3), nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		deleted, err := svc.DeleteByPathPrefix(ctx, projectID, diskID, "/docs/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("disk usage rolls sizes up into ancestors", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("SummarizeByPathPrefix", ctx, diskID, "/docs/").Return([]repo.ArtifactPathUsage{
+			{Path: "/docs/", Files: 1, Bytes: 10},
+			{Path: "/docs/sub/", Files: 2, Bytes: 20},
+			{Path: "/docs/sub/deep/", Files: 4, Bytes: 40},
+		}, nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		all, err := svc.DiskUsage(ctx, diskID, "/docs/", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []DirectoryUsage{
+			{Path: "/docs/", Files: 7, Bytes: 70},
+			{Path: "/docs/sub/", Files: 6, Bytes: 60},
+			{Path: "/docs/sub/deep/", Files: 4, Bytes: 40},
+		}, all)
+
+		shallow, err := svc.DiskUsage(ctx, diskID, "/docs/", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []DirectoryUsage{
+			{Path: "/docs/", Files: 7, Bytes: 70},
+			{Path: "/docs/sub/", Files: 6, Bytes: 60},
+		}, shallow)
+	})
+
+	t.Run("disk usage of an empty directory", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("SummarizeByPathPrefix", ctx, diskID, "/").Return([]repo.ArtifactPathUsage{}, nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		usage, err := svc.DiskUsage(ctx, diskID, "/", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []DirectoryUsage{{Path: "/"}}, usage)
+	})
+}
+
+func buildTestArchive(t *testing.T, format string, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	aw, err := newArchiveWriter(format, &buf)
+	assert.NoError(t, err)
+	for name, content := range files {
+		assert.NoError(t, aw.WriteFile(name, strings.NewReader(content), int64(len(content)), time.Now()))
+	}
+	assert.NoError(t, aw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestArtifactService_Archive(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarGz} {
+		t.Run("round trip skips macOS metadata "+format, func(t *testing.T) {
+			ar := buildTestArchive(t, format, map[string]string{
+				"docs/a.md":            "alpha",
+				"docs/sub/b.md":        "beta",
+				"__MACOSX/docs/._a.md": "junk",
+				"docs/.DS_Store":       "junk",
+			})
+
+			got := map[string]string{}
+			err := eachArchiveFile(format, ar, ar.Size(), func(m archiveMember, open func() (io.ReadCloser, error)) error {
+				rc, err := open()
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+				content, err := io.ReadAll(rc)
+				got[m.name] = string(content)
+				return err
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"docs/a.md": "alpha", "docs/sub/b.md": "beta"}, got)
+		})
+	}
+
+	t.Run("rejects paths escaping the archive root", func(t *testing.T) {
+		for _, name := range []string{"../evil.sh", "docs/../../evil.sh", "..\\evil.sh"} {
+			_, err := sanitizeArchivePath(name)
+			assert.ErrorIs(t, err, ErrInvalidArchive, name)
+		}
+		clean, err := sanitizeArchivePath("./docs//a.md")
+		assert.NoError(t, err)
+		assert.Equal(t, "docs/a.md", clean)
+	})
+
+	t.Run("import rejects traversal before writing", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		ar := buildTestArchive(t, ArchiveFormatZip, map[string]string{"../evil.sh": "x"})
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.Import(ctx, ImportArtifactsInput{ProjectID: projectID, DiskID: diskID, Path: "/", Format: ArchiveFormatZip, Archive: ar, Size: ar.Size()})
+
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+		mockRepo.AssertNotCalled(t, "ListByPathPrefix", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("import rejects an empty archive", func(t *testing.T) {
+		ar := buildTestArchive(t, ArchiveFormatTarGz, map[string]string{"__MACOSX/._x": "junk"})
+		svc := &artifactService{r: &MockArtifactRepo{}, log: zap.NewNop()}
+
+		_, err := svc.Import(ctx, ImportArtifactsInput{DiskID: diskID, Path: "/", Format: ArchiveFormatTarGz, Archive: ar, Size: ar.Size()})
+
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("import enforces the extracted size limit", func(t *testing.T) {
+		ar := buildTestArchive(t, ArchiveFormatZip, map[string]string{"a.txt": "0123456789", "b.txt": "0123456789"})
+		cfg := &config.Config{Artifact: config.ArtifactCfg{MaxInlineSizeBytes: 16, MaxArchiveSizeBytes: 16}}
+		svc := &artifactService{r: &MockArtifactRepo{}, cfg: cfg, log: zap.NewNop()}
+
+		_, err := svc.Import(ctx, ImportArtifactsInput{DiskID: diskID, Path: "/", Format: ArchiveFormatZip, Archive: ar, Size: ar.Size()})
+
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("import strips the root folder and fails on conflict", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/in/").Return([]*model.Artifact{{Path: "/in/sub/", Filename: "b.md"}}, nil)
+		ar := buildTestArchive(t, ArchiveFormatZip, map[string]string{"project/a.md": "a", "project/sub/b.md": "b"})
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.Import(ctx, ImportArtifactsInput{DiskID: diskID, Path: "/in/", Format: ArchiveFormatZip, Archive: ar, Size: ar.Size()})
+
+		assert.ErrorIs(t, err, ErrArtifactConflict)
+		assert.Contains(t, err.Error(), "/in/sub/b.md")
+	})
+
+	t.Run("import skips existing files", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/in/").Return([]*model.Artifact{{Path: "/in/", Filename: "a.md"}}, nil)
+		ar := buildTestArchive(t, ArchiveFormatTarGz, map[string]string{"a.md": "a"})
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		out, err := svc.Import(ctx, ImportArtifactsInput{DiskID: diskID, Path: "/in/", Format: ArchiveFormatTarGz, Archive: ar, Size: ar.Size(), OnConflict: ConflictSkip})
+
+		assert.NoError(t, err)
+		assert.Empty(t, out.Artifacts)
+		assert.Equal(t, []string{"/in/a.md"}, out.Skipped)
+	})
+
+	t.Run("export streams file content into the archive", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Blob.LocalRoot = t.TempDir()
+		cfg.Blob.SigningKey = "test-signing-key"
+		store, err := blob.NewLocalStore(cfg)
+		assert.NoError(t, err)
+		body := strings.Repeat("exported line\n", 1000)
+		asset, err := store.UploadBytes(ctx, "disks/"+projectID.String(), "notes.md", []byte(body), nil)
+		assert.NoError(t, err)
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/out/").Return([]*model.Artifact{
+			{Path: "/out/sub/", Filename: "notes.md", AssetMeta: datatypes.NewJSONType(*asset)},
+		}, nil)
+		svc := &artifactService{r: mockRepo, s3: store, log: zap.NewNop()}
+
+		for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarGz} {
+			var buf bytes.Buffer
+			err := svc.Export(ctx, &buf, ExportArtifactsInput{DiskID: diskID, Path: "/out/", Format: format})
+			assert.NoError(t, err)
+
+			files := map[string]string{}
+			ar := bytes.NewReader(buf.Bytes())
+			err = eachArchiveFile(format, ar, ar.Size(), func(m archiveMember, open func() (io.ReadCloser, error)) error {
+				r, err := open()
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+				content, err := io.ReadAll(r)
+				files[m.name] = string(content)
+				return err
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"sub/notes.md": body}, files, format)
+		}
+	})
+
+	t.Run("export of an empty directory is a valid archive", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("ListByPathPrefix", ctx, diskID, "/empty/").Return([]*model.Artifact{}, nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		var buf bytes.Buffer
+		err := svc.Export(ctx, &buf, ExportArtifactsInput{DiskID: diskID, Path: "/empty/", Format: ArchiveFormatZip})
+
+		assert.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+		assert.Empty(t, zr.File)
+	})
+}
+
+func TestArtifactService_Preconditions(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	current := &model.Artifact{DiskID: diskID, Path: "/notes/", Filename: "todo.md", AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-current"})}
+
+	t.Run("stale if-match fails before uploading", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/notes/", "todo.md").Return(current, nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.CreateFromBytes(ctx, CreateArtifactFromBytesInput{
+			ProjectID:    projectID,
+			DiskID:       diskID,
+			Path:         "/notes/",
+			Filename:     "todo.md",
+			Content:      []byte("new"),
+			Precondition: repo.ArtifactPrecondition{IfMatch: []string{"sha-stale"}},
+		})
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		mockRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("create-only fails when the artifact exists", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/notes/", "todo.md").Return(current, nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.CreateFromBytes(ctx, CreateArtifactFromBytesInput{
+			ProjectID:    projectID,
+			DiskID:       diskID,
+			Path:         "/notes/",
+			Filename:     "todo.md",
+			Content:      []byte("new"),
+			Precondition: repo.ArtifactPrecondition{IfNoneMatch: []string{"*"}},
+		})
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+	})
+
+	t.Run("delete maps a failed repository precondition", func(t *testing.T) {
+		cond := repo.ArtifactPrecondition{IfMatch: []string{"sha-stale"}}
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("DeleteByPath", ctx, projectID, diskID, "/notes/", "todo.md", cond).Return(fmt.Errorf("%w: If-Match", repo.ErrPreconditionFailed))
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		err := svc.DeleteByPath(ctx, projectID, diskID, "/notes/", "todo.md", cond)
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+	})
+
+	t.Run("meta update with if-match on a missing artifact", func(t *testing.T) {
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/notes/", "gone.md").Return(nil, gorm.ErrRecordNotFound)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.UpdateArtifactMetaByPath(ctx, diskID, "/notes/", "gone.md", map[string]interface{}{"k": "v"}, repo.ArtifactPrecondition{IfMatch: []string{"sha-current"}})
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+	})
+
+	t.Run("meta update passes the precondition to the repository", func(t *testing.T) {
+		cond := repo.ArtifactPrecondition{IfMatch: []string{"sha-current"}}
+		mockRepo := &MockArtifactRepo{}
+		mockRepo.On("GetByPath", ctx, diskID, "/notes/", "todo.md").Return(&model.Artifact{DiskID: diskID, Path: "/notes/", Filename: "todo.md", Meta: map[string]interface{}{}}, nil)
+		mockRepo.On("Update", ctx, mock.Anything, cond).Return(nil)
+		svc := &artifactService{r: mockRepo, log: zap.NewNop()}
+
+		_, err := svc.UpdateArtifactMetaByPath(ctx, diskID, "/notes/", "todo.md", map[string]interface{}{"k": "v"}, cond)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestArtifactService_Edit(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	text := &model.Artifact{DiskID: diskID, Path: "/notes/", Filename: "todo.md",
+		AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-current", MIME: "text/markdown", SizeB: 12, Content: "one\ntwo\n"})}
+	image := &model.Artifact{DiskID: diskID, Path: "/img/", Filename: "cat.png",
+		AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-img", MIME: "image/png", SizeB: 100})}
+	// extracted text is searchable but the document itself is not editable
+	document := &model.Artifact{DiskID: diskID, Path: "/docs/", Filename: "spec.pdf",
+		AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-pdf", MIME: "application/pdf", SizeB: 100, Content: "Design spec\n"})}
+	// stored without extracted text because it exceeds the inline size
+	large := &model.Artifact{DiskID: diskID, Path: "/data/", Filename: "big.csv",
+		AssetMeta: datatypes.NewJSONType(model.Asset{SHA256: "sha-big", MIME: "text/csv", SizeB: 4096})}
+	cfg := &config.Config{Artifact: config.ArtifactCfg{MaxInlineSizeBytes: 1024}}
+
+	tests := []struct {
+		name     string
+		artifact *model.Artifact
+		getErr   error
+		edits    []textedit.Edit
+		cond     repo.ArtifactPrecondition
+		wantErr  error
+	}{
+		{
+			name:    "missing artifact",
+			getErr:  gorm.ErrRecordNotFound,
+			edits:   []textedit.Edit{{Op: textedit.OpInsert, Line: 1, Text: "x"}},
+			wantErr: ErrArtifactNotFound,
+		},
+		{
+			name:     "stale if-match",
+			artifact: text,
+			edits:    []textedit.Edit{{Op: textedit.OpInsert, Line: 1, Text: "x"}},
+			cond:     repo.ArtifactPrecondition{IfMatch: []string{"sha-stale"}},
+			wantErr:  ErrPreconditionFailed,
+		},
+		{
+			name:     "binary artifact",
+			artifact: image,
+			edits:    []textedit.Edit{{Op: textedit.OpInsert, Line: 1, Text: "x"}},
+			wantErr:  ErrArtifactNotText,
+		},
+		{
+			name:     "document artifact",
+			artifact: document,
+			edits:    []textedit.Edit{{Op: textedit.OpReplace, OldText: "Design", NewText: "Draft"}},
+			wantErr:  ErrArtifactNotText,
+		},
+		{
+			name:     "file too large to edit in memory",
+			artifact: large,
+			edits:    []textedit.Edit{{Op: textedit.OpInsert, Line: 1, Text: "x"}},
+			wantErr:  ErrInvalidEdit,
+		},
+		{
+			name:     "replace text that is not there",
+			artifact: text,
+			edits:    []textedit.Edit{{Op: textedit.OpReplace, OldText: "three", NewText: "3"}},
+			wantErr:  ErrEditMismatch,
+		},
+		{
+			name:     "line out of range",
+			artifact: text,
+			edits:    []textedit.Edit{{Op: textedit.OpDelete, StartLine: 2, EndLine: 9}},
+			wantErr:  ErrInvalidEdit,
+		},
+		{
+			name:     "edit that changes nothing keeps the artifact",
+			artifact: text,
+			edits:    []textedit.Edit{{Op: textedit.OpReplace, OldText: "one", NewText: "one"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockArtifactRepo{}
+			filename := "todo.md"
+			if tt.artifact != nil {
+				filename = tt.artifact.Filename
+				mockRepo.On("GetByPath", ctx, diskID, tt.artifact.Path, filename).Return(tt.artifact, nil)
+			} else {
+				mockRepo.On("GetByPath", ctx, diskID, "/notes/", filename).Return(nil, tt.getErr)
+			}
+			svc := &artifactService{r: mockRepo, cfg: cfg, log: zap.NewNop()}
+
+			path := "/notes/"
+			if tt.artifact != nil {
+				path = tt.artifact.Path
+			}
+			got, err := svc.Edit(ctx, EditArtifactInput{
+				ProjectID:    projectID,
+				DiskID:       diskID,
+				Path:         path,
+				Filename:     filename,
+				Edits:        tt.edits,
+				Precondition: tt.cond,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Same(t, tt.artifact, got)
+			}
+			mockRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestArtifactService_DocumentText(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+
+	newSvc := func(t *testing.T) (*artifactService, *blob.LocalStore, *MockArtifactRepo) {
+		cfg := &config.Config{}
+		cfg.Blob.LocalRoot = t.TempDir()
+		cfg.Blob.SigningKey = "test-signing-key"
+		cfg.Artifact.MaxInlineSizeBytes = 1 << 20
+		store, err := blob.NewLocalStore(cfg)
+		assert.NoError(t, err)
+		mockRepo := &MockArtifactRepo{}
+		return &artifactService{r: mockRepo, s3: store, cfg: cfg, log: zap.NewNop()}, store, mockRepo
+	}
+
+	t.Run("stores the text of documents", func(t *testing.T) {
+		svc, _, mockRepo := newSvc(t)
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("word/document.xml")
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Design spec</w:t></w:r></w:p></w:body></w:document>`))
+		assert.NoError(t, err)
+		assert.NoError(t, zw.Close())
+		mockRepo.On("Upsert", ctx, projectID, mock.AnythingOfType("*model.Artifact"), mock.Anything, repo.ArtifactPrecondition{}).Return(nil)
+
+		artifact, err := svc.CreateFromBytes(ctx, CreateArtifactFromBytesInput{
+			ProjectID: projectID, DiskID: diskID, Path: "/docs/", Filename: "spec.docx", Content: buf.Bytes(),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Design spec\n", artifact.AssetMeta.Data().Content)
+	})
+
+	t.Run("edits HTML markup and re-extracts its text", func(t *testing.T) {
+		svc, store, mockRepo := newSvc(t)
+		page := "<html><head><title>Notes</title></head><body><p>old text</p></body></html>\n"
+		asset, err := store.UploadBytes(ctx, "disks/"+projectID.String(), "notes.html", []byte(page), nil)
+		assert.NoError(t, err)
+		asset.Content = "Notes\nold text\n"
+		cur := &model.Artifact{DiskID: diskID, Path: "/web/", Filename: "notes.html", AssetMeta: datatypes.NewJSONType(*asset)}
+		mockRepo.On("GetByPath", ctx, diskID, "/web/", "notes.html").Return(cur, nil)
+		mockRepo.On("Upsert", ctx, projectID, mock.AnythingOfType("*model.Artifact"), mock.Anything, repo.ArtifactPrecondition{IfMatch: []string{asset.SHA256}}).Return(nil)
+
+		got, err := svc.Edit(ctx, EditArtifactInput{
+			ProjectID: projectID, DiskID: diskID, Path: "/web/", Filename: "notes.html",
+			Edits: []textedit.Edit{{Op: textedit.OpReplace, OldText: "<p>old text</p>", NewText: "<p>new text</p>"}},
+		})
+
+		assert.NoError(t, err)
+		edited := got.AssetMeta.Data()
+		assert.Equal(t, "Notes\nnew text\n", edited.Content)
+		raw, err := store.DownloadFile(ctx, edited.S3Key, nil)
+		assert.NoError(t, err)
+		assert.Contains(t, string(raw), "<p>new text</p>")
+	})
+}
+
+func TestArtifactService_Commit(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	content := []byte("hello from a presigned upload\n")
+	sum := sha256.Sum256(content)
+	sumHex := hex.EncodeToString(sum[:])
+
+	newSvc := func(t *testing.T) (*artifactService, *blob.LocalStore, *MockArtifactRepo) {
+		cfg := &config.Config{}
+		cfg.Blob.LocalRoot = t.TempDir()
+		cfg.Blob.SigningKey = "test-signing-key"
+		cfg.Artifact.MaxUploadSizeBytes = 1 << 20
+		store, err := blob.NewLocalStore(cfg)
+		assert.NoError(t, err)
+		mockRepo := &MockArtifactRepo{}
+		return &artifactService{r: mockRepo, s3: store, cfg: cfg, log: zap.NewNop()}, store, mockRepo
+	}
+	// presign issues an upload key and writes content to it, as the client's PUT would
+	presign := func(t *testing.T, svc *artifactService, store *blob.LocalStore) string {
+		up, err := svc.PresignUpload(ctx, projectID, "", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "application/octet-stream", up.ContentType)
+		assert.Contains(t, up.UploadURL, "signature=")
+		_, err = store.Put(ctx, up.UploadKey, bytes.NewReader(content), up.ContentType, nil)
+		assert.NoError(t, err)
+		return up.UploadKey
+	}
+
+	t.Run("verifies and stores the upload", func(t *testing.T) {
+		for _, encrypted := range []bool{false, true} {
+			svc, store, mockRepo := newSvc(t)
+			key := presign(t, svc, store)
+			var userKEK []byte
+			if encrypted {
+				userKEK = make([]byte, 32)
+			}
+			mockRepo.On("Upsert", ctx, projectID, mock.AnythingOfType("*model.Artifact"), mock.Anything, repo.ArtifactPrecondition{}).Return(nil)
+
+			artifact, err := svc.Commit(ctx, CommitArtifactInput{
+				ProjectID: projectID, DiskID: diskID, UploadKey: key, Path: "/notes/", Filename: "hello.txt",
+				Size: int64(len(content)), SHA256: strings.ToUpper(sumHex), UserKEK: userKEK,
+			})
+
+			assert.NoError(t, err)
+			asset := artifact.AssetMeta.Data()
+			assert.Equal(t, sumHex, asset.SHA256)
+			assert.Contains(t, asset.MIME, "text/plain")
+			assert.NotEmpty(t, asset.Content, "text is extracted for grep")
+			got, err := store.DownloadFile(ctx, asset.S3Key, userKEK)
+			assert.NoError(t, err)
+			assert.Equal(t, content, got)
+			_, err = store.Head(ctx, key)
+			assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+			if encrypted {
+				_, err = store.DownloadFile(ctx, asset.S3Key, nil)
+				assert.Error(t, err, "stored encrypted")
+			}
+		}
+	})
+
+	t.Run("rejects and deletes a mismatching upload", func(t *testing.T) {
+		svc, store, _ := newSvc(t)
+		key := presign(t, svc, store)
+		_, err := svc.Commit(ctx, CommitArtifactInput{ProjectID: projectID, DiskID: diskID, UploadKey: key, Path: "/", Filename: "a.txt", Size: int64(len(content)), SHA256: strings.Repeat("0", 64)})
+		assert.ErrorIs(t, err, ErrUploadMismatch)
+		_, err = store.Head(ctx, key)
+		assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+
+		key = presign(t, svc, store)
+		_, err = svc.Commit(ctx, CommitArtifactInput{ProjectID: projectID, DiskID: diskID, UploadKey: key, Path: "/", Filename: "a.txt", Size: 3, SHA256: sumHex})
+		assert.ErrorIs(t, err, ErrUploadMismatch)
+	})
+
+	t.Run("rejects foreign, missing and oversized uploads", func(t *testing.T) {
+		svc, store, _ := newSvc(t)
+		key := presign(t, svc, store)
+
+		_, err := svc.Commit(ctx, CommitArtifactInput{ProjectID: uuid.New(), DiskID: diskID, UploadKey: key, Path: "/", Filename: "a.txt", Size: int64(len(content)), SHA256: sumHex})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+		_, err = svc.Commit(ctx, CommitArtifactInput{ProjectID: projectID, DiskID: diskID, UploadKey: "disks/" + projectID.String() + "/x.txt", Path: "/", Filename: "a.txt", Size: 1, SHA256: sumHex})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+		_, err = svc.Commit(ctx, CommitArtifactInput{ProjectID: projectID, DiskID: diskID, UploadKey: directUploadPrefix(projectID) + uuid.NewString(), Path: "/", Filename: "a.txt", Size: 1, SHA256: sumHex})
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+		_, err = svc.Commit(ctx, CommitArtifactInput{ProjectID: projectID, DiskID: diskID, UploadKey: key, Path: "/", Filename: "a.txt", Size: 2 << 20, SHA256: sumHex})
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+	})
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const (
+	// assetGCLockKey serializes sweeps across API and admin instances
+	assetGCLockKey = "asset_gc:lock"
+	// assetGCLockTTL bounds how long a crashed sweep keeps others out
+	assetGCLockTTL = 30 * time.Minute
+	// maxAssetGCBatchSize is the most objects one S3 DeleteObjects request removes
+	maxAssetGCBatchSize = 1000
+)
+
+// AssetGCService deletes orphaned assets: asset references whose ref_count dropped to zero
+// without their S3 object being removed, which happens when a decrement fails halfway.
+type AssetGCService interface {
+	// Sweep collects the orphaned assets unreferenced for longer than the grace period.
+	Sweep(ctx context.Context, in AssetGCInput) (*AssetGCOutput, error)
+	// Start runs Sweep periodically in the background when enabled in config.
+	Start()
+	// Stop ends background sweeps, interrupting a running one.
+	Stop()
+}
+
+type AssetGCInput struct {
+	ProjectID   *uuid.UUID     // limits the sweep to one project; nil sweeps all of them
+	DryRun      bool           // reports what would be deleted without deleting it
+	GracePeriod *time.Duration // overrides assetGC.gracePeriodSeconds
+}
+
+type AssetGCProjectStats struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Assets    int       `json:"assets"`
+	Bytes     int64     `json:"bytes"`
+}
+
+type AssetGCOutput struct {
+	DryRun   bool                  `json:"dry_run"`
+	Before   time.Time             `json:"before"` // assets unreferenced since before this were collected
+	Assets   int                   `json:"assets"`
+	Bytes    int64                 `json:"bytes"`
+	Projects []AssetGCProjectStats `json:"projects"`
+}
+
+type assetGCService struct {
+	assetRefRepo repo.AssetReferenceRepo
+	redis        *redis.Client
+	cfg          *config.Config
+	log          *zap.Logger
+
+	deletedAssets  metric.Int64Counter
+	reclaimedBytes metric.Int64Counter
+	sweepErrors    metric.Int64Counter
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewAssetGCService(assetRefRepo repo.AssetReferenceRepo, rdb *redis.Client, cfg *config.Config, log *zap.Logger) AssetGCService {
+	// Instruments are no-ops until a meter provider is installed
+	meter := otel.Meter("github.com/memodb-io/Acontext/asset-gc")
+	deletedAssets, _ := meter.Int64Counter("asset_gc.assets_deleted",
+		metric.WithDescription("Orphaned assets deleted by the asset garbage collector"))
+	reclaimedBytes, _ := meter.Int64Counter("asset_gc.bytes_reclaimed",
+		metric.WithDescription("Bytes of orphaned assets deleted by the asset garbage collector"), metric.WithUnit("By"))
+	sweepErrors, _ := meter.Int64Counter("asset_gc.sweep_errors",
+		metric.WithDescription("Asset garbage collection sweeps that failed"))
+	return &assetGCService{
+		assetRefRepo:   assetRefRepo,
+		redis:          rdb,
+		cfg:            cfg,
+		log:            log.Named("asset-gc"),
+		deletedAssets:  deletedAssets,
+		reclaimedBytes: reclaimedBytes,
+		sweepErrors:    sweepErrors,
+	}
+}
+
+func (s *assetGCService) batchSize() int {
+	n := s.cfg.AssetGC.BatchSize
+	if n <= 0 || n > maxAssetGCBatchSize {
+		return maxAssetGCBatchSize
+	}
+	return n
+}
+
+// Sweep pages through orphaned asset references in ID order and deletes each page with its
+// S3 objects. A dry run only tallies them. Only one non-dry sweep runs at a time.
+func (s *assetGCService) Sweep(ctx context.Context, in AssetGCInput) (*AssetGCOutput, error) {
+	grace := time.Duration(s.cfg.AssetGC.GracePeriodSeconds) * time.Second
+	if in.GracePeriod != nil {
+		grace = *in.GracePeriod
+	}
+	if grace < 0 {
+		return nil, fmt.Errorf("%w: negative grace period", ErrInvalidAssetGC)
+	}
+
+	if !in.DryRun {
+		ok, err := s.redis.SetNX(ctx, assetGCLockKey, "1", assetGCLockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquire asset gc lock: %w", err)
+		}
+		if !ok {
+			return nil, ErrAssetGCRunning
+		}
+		defer s.redis.Del(context.Background(), assetGCLockKey)
+	}
+
+	out := &AssetGCOutput{DryRun: in.DryRun, Before: time.Now().Add(-grace), Projects: []AssetGCProjectStats{}}
+	byProject := map[uuid.UUID]int{}
+	batch := s.batchSize()
+	afterID := uuid.Nil
+	for {
+		refs, err := s.assetRefRepo.ListOrphanedAssetRefs(ctx, in.ProjectID, out.Before, afterID, batch)
+		if err != nil {
+			return s.failed(ctx, err)
+		}
+		if len(refs) == 0 {
+			break
+		}
+		afterID = refs[len(refs)-1].ID
+
+		collected := refs
+		if !in.DryRun {
+			ids := make([]uuid.UUID, len(refs))
+			for i, ref := range refs {
+				ids[i] = ref.ID
+			}
+			collected, err = s.assetRefRepo.DeleteOrphanedAssetRefs(ctx, ids, out.Before)
+			if err != nil {
+				return s.failed(ctx, err)
+			}
+		}
+
+		var bytes int64
+		for _, ref := range collected {
+			size := ref.AssetMeta.Data().SizeB
+			i, ok := byProject[ref.ProjectID]
+			if !ok {
+				i = len(out.Projects)
+				byProject[ref.ProjectID] = i
+				out.Projects = append(out.Projects, AssetGCProjectStats{ProjectID: ref.ProjectID})
+			}
+			out.Projects[i].Assets++
+			out.Projects[i].Bytes += size
+			bytes += size
+		}
+		out.Assets += len(collected)
+		out.Bytes += bytes
+		if !in.DryRun {
+			s.deletedAssets.Add(ctx, int64(len(collected)))
+			s.reclaimedBytes.Add(ctx, bytes)
+		}
+
+		if len(refs) < batch {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (s *assetGCService) failed(ctx context.Context, err error) (*AssetGCOutput, error) {
+	s.sweepErrors.Add(ctx, 1)
+	return nil, fmt.Errorf("sweep orphaned assets: %w", err)
+}
+
+func (s *assetGCService) Start() {
+	if !s.cfg.AssetGC.Enabled {
+		return
+	}
+	interval := time.Duration(s.cfg.AssetGC.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, interval)
+}
+
+func (s *assetGCService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *assetGCService) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			out, err := s.Sweep(ctx, AssetGCInput{})
+			switch {
+			case errors.Is(err, ErrAssetGCRunning):
+				// Another instance is sweeping
+			case err != nil:
+				if ctx.Err() == nil {
+					s.log.Error("asset gc sweep failed", zap.Error(err))
+				}
+			case out.Assets > 0:
+				s.log.Info("asset gc sweep deleted orphaned assets",
+					zap.Int("assets", out.Assets),
+					zap.Int64("bytes", out.Bytes),
+					zap.Int("projects", len(out.Projects)))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
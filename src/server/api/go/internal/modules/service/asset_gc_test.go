@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+func orphanRef(projectID uuid.UUID, size int64) model.AssetReference {
+	return model.AssetReference{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		AssetMeta: datatypes.NewJSONType(model.Asset{SizeB: size}),
+	}
+}
+
+func newTestAssetGCService(t *testing.T, assetRefRepo *MockAssetReferenceRepo) *assetGCService {
+	t.Helper()
+	rdb, _ := newTestRedis(t)
+	cfg := &config.Config{}
+	cfg.AssetGC.GracePeriodSeconds = 3600
+	cfg.AssetGC.BatchSize = 2
+	return NewAssetGCService(assetRefRepo, rdb, cfg, zap.NewNop()).(*assetGCService)
+}
+
+func TestAssetGCService_Sweep(t *testing.T) {
+	p1, p2 := uuid.New(), uuid.New()
+	page1 := []model.AssetReference{orphanRef(p1, 100), orphanRef(p2, 10)}
+	page2 := []model.AssetReference{orphanRef(p1, 1)}
+	beforeWithin := func(lo, hi time.Time) interface{} {
+		return mock.MatchedBy(func(before time.Time) bool { return before.After(lo) && before.Before(hi) })
+	}
+
+	t.Run("dry run tallies without deleting", func(t *testing.T) {
+		repo := &MockAssetReferenceRepo{}
+		svc := newTestAssetGCService(t, repo)
+		now := time.Now()
+		before := beforeWithin(now.Add(-time.Hour-time.Minute), now.Add(-time.Hour+time.Minute))
+		repo.On("ListOrphanedAssetRefs", mock.Anything, (*uuid.UUID)(nil), before, uuid.Nil, 2).Return(page1, nil)
+		repo.On("ListOrphanedAssetRefs", mock.Anything, (*uuid.UUID)(nil), before, page1[1].ID, 2).Return(page2, nil)
+
+		out, err := svc.Sweep(context.Background(), AssetGCInput{DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, out.DryRun)
+		assert.Equal(t, 3, out.Assets)
+		assert.Equal(t, int64(111), out.Bytes)
+		assert.Equal(t, []AssetGCProjectStats{{ProjectID: p1, Assets: 2, Bytes: 101}, {ProjectID: p2, Assets: 1, Bytes: 10}}, out.Projects)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "DeleteOrphanedAssetRefs", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes page by page and counts what was deleted", func(t *testing.T) {
+		repo := &MockAssetReferenceRepo{}
+		svc := newTestAssetGCService(t, repo)
+		grace := time.Duration(0)
+		repo.On("ListOrphanedAssetRefs", mock.Anything, &p1, mock.Anything, uuid.Nil, 2).Return(page1[:1], nil)
+		// The asset was referenced again after it was listed
+		repo.On("DeleteOrphanedAssetRefs", mock.Anything, []uuid.UUID{page1[0].ID}, mock.Anything).Return([]model.AssetReference{}, nil)
+
+		out, err := svc.Sweep(context.Background(), AssetGCInput{ProjectID: &p1, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.Zero(t, out.Assets)
+		assert.Empty(t, out.Projects)
+		assert.WithinDuration(t, time.Now(), out.Before, time.Minute)
+		repo.AssertExpectations(t)
+
+		repo = &MockAssetReferenceRepo{}
+		svc = newTestAssetGCService(t, repo)
+		repo.On("ListOrphanedAssetRefs", mock.Anything, (*uuid.UUID)(nil), mock.Anything, uuid.Nil, 2).Return(page1, nil)
+		repo.On("ListOrphanedAssetRefs", mock.Anything, (*uuid.UUID)(nil), mock.Anything, page1[1].ID, 2).Return([]model.AssetReference{}, nil)
+		repo.On("DeleteOrphanedAssetRefs", mock.Anything, []uuid.UUID{page1[0].ID, page1[1].ID}, mock.Anything).Return(page1, nil)
+
+		out, err = svc.Sweep(context.Background(), AssetGCInput{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, out.Assets)
+		assert.Equal(t, int64(110), out.Bytes)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("one sweep at a time", func(t *testing.T) {
+		repo := &MockAssetReferenceRepo{}
+		svc := newTestAssetGCService(t, repo)
+		require.NoError(t, svc.redis.Set(context.Background(), assetGCLockKey, "1", time.Minute).Err())
+
+		_, err := svc.Sweep(context.Background(), AssetGCInput{})
+		assert.ErrorIs(t, err, ErrAssetGCRunning)
+
+		// Dry runs do not take the lock
+		repo.On("ListOrphanedAssetRefs", mock.Anything, mock.Anything, mock.Anything, uuid.Nil, 2).Return([]model.AssetReference{}, nil)
+		_, err = svc.Sweep(context.Background(), AssetGCInput{DryRun: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("negative grace period", func(t *testing.T) {
+		svc := newTestAssetGCService(t, &MockAssetReferenceRepo{})
+		grace := -time.Second
+		_, err := svc.Sweep(context.Background(), AssetGCInput{GracePeriod: &grace})
+		assert.ErrorIs(t, err, ErrInvalidAssetGC)
+	})
+}
+
+func TestAssetGCService_StartStop(t *testing.T) {
+	svc := newTestAssetGCService(t, &MockAssetReferenceRepo{})
+	// Disabled: Start does nothing and Stop returns at once
+	svc.Start()
+	svc.Stop()
+
+	svc.cfg.AssetGC.Enabled = true
+	svc.Start()
+	svc.Stop()
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// AssetOutboxService uploads message assets after their message is stored, without losing
+// them when an upload fails or the process stops: each upload is persisted before it is
+// attempted and only dropped once it is stored, and failed ones are retried with backoff.
+type AssetOutboxService interface {
+	// Enqueue persists the uploads of a session's message and attempts them in the background.
+	Enqueue(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, uploads []*blob.PreparedUpload, userKEK []byte) error
+	// Read returns the plaintext content of an upload that is not stored yet.
+	Read(ctx context.Context, s3Key string, userKEK []byte) ([]byte, error)
+	// List returns the uploads of a session's messages that are not stored yet.
+	List(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error)
+	// Start retries due uploads periodically in the background.
+	Start()
+	// Stop ends background retries and waits for running attempts.
+	Stop()
+}
+
+type assetOutboxService struct {
+	repo repo.PendingAssetUploadRepo
+	s3   blob.BlobStore
+	cfg  *config.Config
+	log  *zap.Logger
+
+	// attempts tracks the first attempts started by Enqueue
+	attempts sync.WaitGroup
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewAssetOutboxService(r repo.PendingAssetUploadRepo, s3 blob.BlobStore, cfg *config.Config, log *zap.Logger) AssetOutboxService {
+	return &assetOutboxService{
+		repo: r,
+		s3:   s3,
+		cfg:  cfg,
+		log:  log.Named("asset-outbox"),
+	}
+}
+
+func (s *assetOutboxService) uploadTimeout() time.Duration {
+	if s.cfg.AssetOutbox.UploadTimeoutSeconds <= 0 {
+		return 2 * time.Minute
+	}
+	return time.Duration(s.cfg.AssetOutbox.UploadTimeoutSeconds) * time.Second
+}
+
+// backoff is the delay after the given number of failed attempts: the initial backoff
+// doubled after every further failure, capped at the maximum.
+func (s *assetOutboxService) backoff(attempts int) time.Duration {
+	delay := time.Duration(s.cfg.AssetOutbox.InitialBackoffSeconds) * time.Second
+	if delay <= 0 {
+		delay = 5 * time.Second
+	}
+	limit := time.Duration(s.cfg.AssetOutbox.MaxBackoffSeconds) * time.Second
+	if limit < delay {
+		limit = delay
+	}
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// Enqueue seals the uploads into their stored form, so retries need neither the request's
+// files nor the user's KEK, and schedules their next attempt after the first one can time
+// out: the worker only picks them up if that attempt is lost.
+func (s *assetOutboxService) Enqueue(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, uploads []*blob.PreparedUpload, userKEK []byte) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	next := time.Now().Add(s.uploadTimeout())
+	rows := make([]*model.PendingAssetUpload, 0, len(uploads))
+	for _, p := range uploads {
+		sealed, err := s.s3.SealPrepared(p, userKEK)
+		if err != nil {
+			return fmt.Errorf("seal %s: %w", p.Asset.S3Key, err)
+		}
+		rows = append(rows, &model.PendingAssetUpload{
+			ProjectID:     projectID,
+			SessionID:     sessionID,
+			S3Key:         sealed.Asset.S3Key,
+			AssetMeta:     datatypes.NewJSONType(sealed.Asset),
+			Content:       sealed.Content,
+			Metadata:      datatypes.NewJSONType(sealed.Metadata),
+			Status:        model.PendingAssetUploadPending,
+			NextAttemptAt: next,
+		})
+	}
+	if err := s.repo.Enqueue(ctx, rows); err != nil {
+		return fmt.Errorf("enqueue asset uploads: %w", err)
+	}
+
+	s.attempts.Add(1)
+	go func() {
+		defer s.attempts.Done()
+		for _, u := range rows {
+			s.attempt(context.Background(), u)
+		}
+	}()
+	return nil
+}
+
+// attempt uploads u, then drops it from the outbox, or reschedules it with backoff and marks
+// it failed once it ran out of attempts.
+func (s *assetOutboxService) attempt(ctx context.Context, u *model.PendingAssetUpload) {
+	uploadCtx, cancel := context.WithTimeout(ctx, s.uploadTimeout())
+	err := s.s3.UploadSealed(uploadCtx, &blob.SealedUpload{
+		Asset:    u.AssetMeta.Data(),
+		Content:  u.Content,
+		Metadata: u.Metadata.Data(),
+	})
+	cancel()
+	if err == nil {
+		if err := s.repo.Delete(context.Background(), u.ID); err != nil {
+			// Retried again and dropped then: uploads of the same key are idempotent
+			s.log.Warn("failed to drop stored asset upload", zap.String("s3_key", u.S3Key), zap.Error(err))
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Interrupted by Stop; it is claimed again once its lease expires
+		return
+	}
+
+	u.Attempts++
+	u.LastError = err.Error()
+	u.NextAttemptAt = time.Now().Add(s.backoff(u.Attempts))
+	fields := []zap.Field{zap.String("s3_key", u.S3Key), zap.Int("attempts", u.Attempts), zap.Error(err)}
+	if maxAttempts := s.cfg.AssetOutbox.MaxAttempts; maxAttempts > 0 && u.Attempts >= maxAttempts {
+		u.Status = model.PendingAssetUploadFailed
+		s.log.Error("asset upload failed, giving up", fields...)
+	} else {
+		s.log.Warn("asset upload failed, will retry", append(fields, zap.Time("next_attempt_at", u.NextAttemptAt))...)
+	}
+	if err := s.repo.Reschedule(context.Background(), u); err != nil {
+		s.log.Error("failed to reschedule asset upload", zap.String("s3_key", u.S3Key), zap.Error(err))
+	}
+}
+
+func (s *assetOutboxService) Read(ctx context.Context, s3Key string, userKEK []byte) ([]byte, error) {
+	u, err := s.repo.GetByKey(ctx, s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("get pending upload %s: %w", s3Key, err)
+	}
+	sealed := &blob.SealedUpload{Asset: u.AssetMeta.Data(), Content: u.Content, Metadata: u.Metadata.Data()}
+	return sealed.Decode(userKEK)
+}
+
+func (s *assetOutboxService) List(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error) {
+	return s.repo.ListBySession(ctx, projectID, sessionID)
+}
+
+func (s *assetOutboxService) Start() {
+	interval := time.Duration(s.cfg.AssetOutbox.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, interval)
+}
+
+func (s *assetOutboxService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	s.attempts.Wait()
+}
+
+func (s *assetOutboxService) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.retryDue(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retryDue attempts one batch of due uploads, leased for as long as the attempts can take.
+func (s *assetOutboxService) retryDue(ctx context.Context) {
+	batch := s.cfg.AssetOutbox.BatchSize
+	if batch <= 0 {
+		batch = 20
+	}
+	due, err := s.repo.Claim(ctx, batch, time.Duration(batch)*s.uploadTimeout())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("failed to claim asset uploads", zap.Error(err))
+		}
+		return
+	}
+	for _, u := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.attempt(ctx, u)
+	}
+}
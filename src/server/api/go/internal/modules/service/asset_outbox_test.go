@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakePendingUploadRepo keeps pending uploads in memory, keyed by S3 key.
+type fakePendingUploadRepo struct {
+	mu      sync.Mutex
+	uploads map[string]model.PendingAssetUpload
+}
+
+func (r *fakePendingUploadRepo) Enqueue(ctx context.Context, uploads []*model.PendingAssetUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range uploads {
+		if existing, ok := r.uploads[u.S3Key]; ok {
+			existing.Status, existing.Attempts, existing.LastError = u.Status, u.Attempts, u.LastError
+			existing.NextAttemptAt = u.NextAttemptAt
+			r.uploads[u.S3Key] = existing
+			u.ID = existing.ID
+			continue
+		}
+		u.ID = uuid.New()
+		r.uploads[u.S3Key] = *u
+	}
+	return nil
+}
+
+func (r *fakePendingUploadRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.PendingAssetUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var due []*model.PendingAssetUpload
+	for key, u := range r.uploads {
+		if len(due) == limit || u.Status != model.PendingAssetUploadPending || u.NextAttemptAt.After(now) {
+			continue
+		}
+		u.NextAttemptAt = now.Add(lease)
+		r.uploads[key] = u
+		due = append(due, &u)
+	}
+	return due, nil
+}
+
+func (r *fakePendingUploadRepo) Reschedule(ctx context.Context, u *model.PendingAssetUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, existing := range r.uploads {
+		if existing.ID == u.ID {
+			existing.Status, existing.Attempts, existing.LastError = u.Status, u.Attempts, u.LastError
+			existing.NextAttemptAt = u.NextAttemptAt
+			r.uploads[key] = existing
+		}
+	}
+	return nil
+}
+
+func (r *fakePendingUploadRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, u := range r.uploads {
+		if u.ID == id {
+			delete(r.uploads, key)
+		}
+	}
+	return nil
+}
+
+func (r *fakePendingUploadRepo) GetByKey(ctx context.Context, s3Key string) (*model.PendingAssetUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.uploads[s3Key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &u, nil
+}
+
+func (r *fakePendingUploadRepo) ListBySession(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []*model.PendingAssetUpload{}
+	for _, u := range r.uploads {
+		if u.ProjectID == projectID && u.SessionID == sessionID {
+			u.Content = nil
+			out = append(out, &u)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakePendingUploadRepo) get(s3Key string) (model.PendingAssetUpload, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.uploads[s3Key]
+	return u, ok
+}
+
+// flakyStore fails the first failures sealed uploads.
+type flakyStore struct {
+	*blob.LocalStore
+	mu       sync.Mutex
+	failures int
+}
+
+func (s *flakyStore) UploadSealed(ctx context.Context, sealed *blob.SealedUpload) error {
+	s.mu.Lock()
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		return errors.New("storage unavailable")
+	}
+	s.mu.Unlock()
+	return s.LocalStore.UploadSealed(ctx, sealed)
+}
+
+func newTestAssetOutbox(t *testing.T, failures int) (*assetOutboxService, *fakePendingUploadRepo, *flakyStore) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	cfg.Blob.Compression = "gzip"
+	cfg.AssetOutbox.MaxAttempts = 3
+	cfg.AssetOutbox.InitialBackoffSeconds = 5
+	cfg.AssetOutbox.MaxBackoffSeconds = 60
+	local, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	store := &flakyStore{LocalStore: local, failures: failures}
+	r := &fakePendingUploadRepo{uploads: map[string]model.PendingAssetUpload{}}
+	return NewAssetOutboxService(r, store, cfg, zap.NewNop()).(*assetOutboxService), r, store
+}
+
+func testOutboxParts() []model.Part {
+	return []model.Part{{Type: "text", Text: strings.Repeat("pending parts survive ", 100)}}
+}
+
+func TestAssetOutboxService_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	projectID, sessionID := uuid.New(), uuid.New()
+	kek := testUserKEK(t)
+
+	t.Run("uploads and drops stored uploads", func(t *testing.T) {
+		svc, r, store := newTestAssetOutbox(t, 0)
+		p, err := store.PrepareJSONAsset("parts/"+projectID.String(), testOutboxParts())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.Enqueue(ctx, projectID, sessionID, []*blob.PreparedUpload{p}, kek))
+		svc.Stop()
+
+		_, queued := r.get(p.Asset.S3Key)
+		assert.False(t, queued)
+		var parts []model.Part
+		require.NoError(t, store.DownloadJSON(ctx, p.Asset.S3Key, &parts, kek))
+		assert.Equal(t, testOutboxParts(), parts)
+	})
+
+	t.Run("keeps failed uploads readable and retries them", func(t *testing.T) {
+		svc, r, store := newTestAssetOutbox(t, 1)
+		p, err := store.PrepareJSONAsset("parts/"+projectID.String(), testOutboxParts())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.Enqueue(ctx, projectID, sessionID, []*blob.PreparedUpload{p}, kek))
+		svc.Stop()
+
+		u, queued := r.get(p.Asset.S3Key)
+		require.True(t, queued)
+		assert.Equal(t, model.PendingAssetUploadPending, u.Status)
+		assert.Equal(t, 1, u.Attempts)
+		assert.Equal(t, "storage unavailable", u.LastError)
+		assert.WithinDuration(t, time.Now().Add(5*time.Second), u.NextAttemptAt, time.Second)
+		_, err = store.Head(ctx, p.Asset.S3Key)
+		assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+
+		// Stored sealed: the KEK is needed to read it back
+		_, err = svc.Read(ctx, p.Asset.S3Key, nil)
+		assert.Error(t, err)
+		data, err := svc.Read(ctx, p.Asset.S3Key, kek)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "pending parts survive")
+
+		listed, err := svc.List(ctx, projectID, sessionID)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Empty(t, listed[0].Content)
+
+		// Not due yet
+		svc.retryDue(ctx)
+		_, queued = r.get(p.Asset.S3Key)
+		assert.True(t, queued)
+
+		u.NextAttemptAt = time.Now().Add(-time.Second)
+		require.NoError(t, r.Reschedule(ctx, &u))
+		svc.retryDue(ctx)
+		_, queued = r.get(p.Asset.S3Key)
+		assert.False(t, queued)
+		var parts []model.Part
+		require.NoError(t, store.DownloadJSON(ctx, p.Asset.S3Key, &parts, kek))
+		assert.Equal(t, testOutboxParts(), parts)
+	})
+
+	t.Run("gives up after the maximum attempts", func(t *testing.T) {
+		svc, r, store := newTestAssetOutbox(t, 10)
+		p, err := store.PrepareJSONAsset("parts/"+projectID.String(), testOutboxParts())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.Enqueue(ctx, projectID, sessionID, []*blob.PreparedUpload{p}, nil))
+		svc.Stop()
+		for i := 0; i < 3; i++ {
+			u, _ := r.get(p.Asset.S3Key)
+			u.NextAttemptAt = time.Now().Add(-time.Second)
+			require.NoError(t, r.Reschedule(ctx, &u))
+			svc.retryDue(ctx)
+		}
+
+		u, queued := r.get(p.Asset.S3Key)
+		require.True(t, queued)
+		assert.Equal(t, model.PendingAssetUploadFailed, u.Status)
+		assert.Equal(t, 3, u.Attempts)
+		// Still readable once failed
+		_, err = svc.Read(ctx, p.Asset.S3Key, nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestAssetOutboxService_Backoff(t *testing.T) {
+	svc, _, _ := newTestAssetOutbox(t, 0)
+	for attempts, want := range map[int]time.Duration{
+		1:  5 * time.Second,
+		2:  10 * time.Second,
+		4:  40 * time.Second,
+		5:  time.Minute,
+		50: time.Minute,
+	} {
+		assert.Equal(t, want, svc.backoff(attempts), "attempts=%d", attempts)
+	}
+}
+
+func TestSessionService_ReadsPendingUploads(t *testing.T) {
+	ctx := context.Background()
+	projectID, sessionID := uuid.New(), uuid.New()
+	outbox, _, store := newTestAssetOutbox(t, 1)
+	p, err := store.PrepareJSONAsset("parts/"+projectID.String(), testOutboxParts())
+	require.NoError(t, err)
+	require.NoError(t, outbox.Enqueue(ctx, projectID, sessionID, []*blob.PreparedUpload{p}, nil))
+	outbox.Stop()
+
+	svc := &sessionService{s3: store, outbox: outbox, log: zap.NewNop()}
+	parts, ok := svc.loadPartsForMessage(ctx, projectID.String(), p.Asset, nil)
+	require.True(t, ok)
+	assert.Equal(t, testOutboxParts(), parts)
+
+	data, err := svc.DownloadAsset(ctx, p.Asset.S3Key, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "pending parts survive")
+
+	_, err = svc.DownloadAsset(ctx, "parts/"+projectID.String()+"/missing.json", nil)
+	assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+}
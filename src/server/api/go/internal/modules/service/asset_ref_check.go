@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// assetKeyKinds are the key prefixes under which a project's referenced objects are stored,
+// each followed by the project ID.
+var assetKeyKinds = []string{"assets", "disks", "parts"}
+
+// AssetRefCheckService recomputes a project's asset reference counts from the rows that hold
+// them and compares them with asset_references and the stored objects.
+type AssetRefCheckService interface {
+	// Check reports the drift and, when asked, repairs it.
+	Check(ctx context.Context, in AssetRefCheckInput) (*AssetRefCheckOutput, error)
+}
+
+type AssetRefCheckInput struct {
+	ProjectID uuid.UUID
+	UserKEK   []byte // reads the parts JSON of encrypted projects
+	Repair    bool
+	// GracePeriod overrides assetGC.gracePeriodSeconds; objects written more recently are
+	// never reported as leaked, since their references may not be recorded yet
+	GracePeriod *time.Duration
+}
+
+type AssetRefCountDrift struct {
+	SHA256   string `json:"sha256"`
+	S3Key    string `json:"s3_key"`
+	RefCount *int   `json:"ref_count"` // nil when the asset has no reference row
+	Pending  int    `json:"pending"`   // increments buffered but not yet flushed
+	Expected int    `json:"expected"`
+	Repaired bool   `json:"repaired"`
+}
+
+type AssetObjectDrift struct {
+	S3Key    string `json:"s3_key"`
+	SHA256   string `json:"sha256,omitempty"`
+	SizeB    int64  `json:"size_b"`
+	Repaired bool   `json:"repaired"`
+}
+
+type AssetRefCheckOutput struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Repair    bool      `json:"repair"`
+	// Complete is false when some parts JSON could not be read, so expected counts are lower
+	// bounds; a repair then only raises counts and deletes nothing
+	Complete        bool                 `json:"complete"`
+	Assets          int                  `json:"assets"`  // distinct assets referenced
+	Objects         int                  `json:"objects"` // objects stored under the project's prefixes
+	WrongCounts     []AssetRefCountDrift `json:"wrong_counts"`
+	MissingObjects  []AssetObjectDrift   `json:"missing_objects"`
+	LeakedObjects   []AssetObjectDrift   `json:"leaked_objects"`
+	UnreadableParts []string             `json:"unreadable_parts"`
+}
+
+type assetRefCheckService struct {
+	assetRefRepo   repo.AssetReferenceRepo
+	assetRefBuffer repo.AssetRefBuffer
+	uploadRepo     repo.UploadRepo
+	s3             blob.BlobStore
+	redis          *redis.Client
+	cfg            *config.Config
+	log            *zap.Logger
+}
+
+func NewAssetRefCheckService(assetRefRepo repo.AssetReferenceRepo, assetRefBuffer repo.AssetRefBuffer, uploadRepo repo.UploadRepo, s3 blob.BlobStore, rdb *redis.Client, cfg *config.Config, log *zap.Logger) AssetRefCheckService {
+	return &assetRefCheckService{
+		assetRefRepo:   assetRefRepo,
+		assetRefBuffer: assetRefBuffer,
+		uploadRepo:     uploadRepo,
+		s3:             s3,
+		redis:          rdb,
+		cfg:            cfg,
+		log:            log.Named("asset-ref-check"),
+	}
+}
+
+// expectedAsset is an asset with the number of references the project's rows hold to it.
+type expectedAsset struct {
+	asset model.Asset
+	count int
+}
+
+// Check counts one reference per artifact, artifact version, snapshot entry and message
+// parts JSON, plus one per part asset inside each message's parts JSON. A repair sets each
+// wrong count unless its reference changed meanwhile and deletes leaked objects; missing
+// objects cannot be restored and are only reported. Objects held by uploads that can still be
+// attached or committed are never leaked. Repairs hold the asset GC lock, so a
+// sweep never collects an asset whose count is being raised.
+func (s *assetRefCheckService) Check(ctx context.Context, in AssetRefCheckInput) (*AssetRefCheckOutput, error) {
+	if in.ProjectID == uuid.Nil {
+		return nil, fmt.Errorf("%w: project_id is required", ErrInvalidAssetRefCheck)
+	}
+	grace := time.Duration(s.cfg.AssetGC.GracePeriodSeconds) * time.Second
+	if in.GracePeriod != nil {
+		grace = *in.GracePeriod
+	}
+	if grace < 0 {
+		return nil, fmt.Errorf("%w: negative grace period", ErrInvalidAssetRefCheck)
+	}
+
+	if in.Repair {
+		ok, err := s.redis.SetNX(ctx, assetGCLockKey, "1", assetGCLockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquire asset gc lock: %w", err)
+		}
+		if !ok {
+			return nil, ErrAssetGCRunning
+		}
+		defer s.redis.Del(context.Background(), assetGCLockKey)
+	}
+	before := time.Now().Add(-grace)
+
+	refs, err := s.assetRefRepo.ListAssetRefsByProject(ctx, in.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	refBySHA := make(map[string]*model.AssetReference, len(refs))
+	for i := range refs {
+		refBySHA[refs[i].SHA256] = &refs[i]
+	}
+	pending, err := s.assetRefBuffer.Pending(ctx, in.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &AssetRefCheckOutput{
+		ProjectID:       in.ProjectID,
+		Repair:          in.Repair,
+		Complete:        true,
+		WrongCounts:     []AssetRefCountDrift{},
+		MissingObjects:  []AssetObjectDrift{},
+		LeakedObjects:   []AssetObjectDrift{},
+		UnreadableParts: []string{},
+	}
+	expected, err := s.expectedRefs(ctx, in, refBySHA, out)
+	if err != nil {
+		return nil, err
+	}
+	out.Assets = len(expected)
+
+	// The canonical key of an asset is its reference's; content stored again under another
+	// key was deduplicated into it
+	keyOf := func(sha string) string {
+		if ref, ok := refBySHA[sha]; ok && ref.S3Key != "" {
+			return ref.S3Key
+		}
+		if e, ok := expected[sha]; ok {
+			return e.asset.S3Key
+		}
+		return ""
+	}
+
+	shas := make([]string, 0, len(expected)+len(refs))
+	for sha := range expected {
+		shas = append(shas, sha)
+	}
+	for sha := range refBySHA {
+		if _, ok := expected[sha]; !ok {
+			shas = append(shas, sha)
+		}
+	}
+	sort.Strings(shas)
+
+	for _, sha := range shas {
+		want := 0
+		asset := model.Asset{SHA256: sha}
+		if e, ok := expected[sha]; ok {
+			want = e.count
+			asset = e.asset
+		}
+		drift := AssetRefCountDrift{SHA256: sha, S3Key: keyOf(sha), Pending: pending[sha], Expected: want}
+		held := drift.Pending
+		if ref, ok := refBySHA[sha]; ok {
+			count := ref.RefCount
+			drift.RefCount = &count
+			held += count
+			asset = ref.AssetMeta.Data()
+		}
+		if held == want {
+			continue
+		}
+		if in.Repair && (out.Complete || want > held) && want >= drift.Pending {
+			drift.Repaired, err = s.assetRefRepo.SetAssetRefCount(ctx, in.ProjectID, asset, drift.RefCount, want-drift.Pending)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out.WrongCounts = append(out.WrongCounts, drift)
+	}
+
+	objects, err := s.listObjects(ctx, in.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	out.Objects = len(objects)
+
+	referenced := make(map[string]bool, len(shas))
+	for _, sha := range shas {
+		held := pending[sha]
+		if ref, ok := refBySHA[sha]; ok {
+			held += ref.RefCount
+			referenced[ref.S3Key] = true
+		}
+		e, ok := expected[sha]
+		if ok {
+			referenced[e.asset.S3Key] = true
+		}
+		if !ok && held == 0 {
+			continue
+		}
+		key := keyOf(sha)
+		if key == "" {
+			continue
+		}
+		found, err := s.objectExists(ctx, in.ProjectID, key, objects)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missing := AssetObjectDrift{S3Key: key, SHA256: sha}
+			if ok {
+				missing.SizeB = e.asset.SizeB
+			}
+			out.MissingObjects = append(out.MissingObjects, missing)
+		}
+	}
+
+	held, landing, err := s.inFlightUploads(ctx, in.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	var leakedKeys []string
+	for key, obj := range objects {
+		if referenced[key] || held[key] || landing[obj.Size] || !obj.LastModified.Before(before) {
+			continue
+		}
+		out.LeakedObjects = append(out.LeakedObjects, AssetObjectDrift{S3Key: key, SizeB: obj.Size})
+		leakedKeys = append(leakedKeys, key)
+	}
+	sort.Slice(out.LeakedObjects, func(i, j int) bool { return out.LeakedObjects[i].S3Key < out.LeakedObjects[j].S3Key })
+	if in.Repair && out.Complete && len(leakedKeys) > 0 {
+		if err := s.s3.DeleteObjects(ctx, leakedKeys); err != nil {
+			return nil, fmt.Errorf("delete leaked objects: %w", err)
+		}
+		for i := range out.LeakedObjects {
+			out.LeakedObjects[i].Repaired = true
+		}
+	}
+
+	if in.Repair {
+		s.log.Info("asset reference check repaired drift",
+			zap.String("project_id", in.ProjectID.String()),
+			zap.Int("wrong_counts", len(out.WrongCounts)),
+			zap.Int("missing_objects", len(out.MissingObjects)),
+			zap.Int("leaked_objects", len(out.LeakedObjects)),
+			zap.Bool("complete", out.Complete))
+	}
+	return out, nil
+}
+
+// inFlightUploads returns the keys of completed uploads that have not expired, whose files
+// are only referenced once attached, and the sizes of uploads still to be stored: storing
+// content that already exists reuses the existing object before taking a reference, so an
+// unreferenced object of one of those sizes may be about to be used. Presigned uploads count
+// until they are swept, since they can be committed until then.
+func (s *assetRefCheckService) inFlightUploads(ctx context.Context, projectID uuid.UUID) (held map[string]bool, landing map[int64]bool, err error) {
+	uploads, err := s.uploadRepo.ListActiveByProject(ctx, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list uploads: %w", err)
+	}
+	held = map[string]bool{}
+	landing = map[int64]bool{}
+	for _, u := range uploads {
+		if u.Status == model.UploadStatusCompleted {
+			held[u.AssetMeta.Data().S3Key] = true
+		} else {
+			landing[u.Size] = true
+		}
+	}
+
+	committable := time.Now().Add(-MaxDirectUploadExpiry - directUploadSweepGrace)
+	err = s.s3.ListObjects(ctx, directUploadPrefix(projectID), func(obj blob.ObjectInfo) error {
+		if obj.LastModified.After(committable) {
+			landing[obj.Size] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list direct uploads: %w", err)
+	}
+	return held, landing, nil
+}
+
+// expectedRefs counts the references held by the project's rows, reading each distinct parts
+// JSON once for the assets of its parts. Parts JSON that cannot be read are recorded in out.
+func (s *assetRefCheckService) expectedRefs(ctx context.Context, in AssetRefCheckInput, refBySHA map[string]*model.AssetReference, out *AssetRefCheckOutput) (map[string]*expectedAsset, error) {
+	uses, err := s.assetRefRepo.CountAssetUses(ctx, in.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]*expectedAsset, len(uses))
+	add := func(asset model.Asset, count int) {
+		if e, ok := expected[asset.SHA256]; ok {
+			e.count += count
+			return
+		}
+		expected[asset.SHA256] = &expectedAsset{asset: asset, count: count}
+	}
+	for _, use := range uses {
+		add(use.Asset, use.Count)
+		if !use.Parts {
+			continue
+		}
+
+		key := use.Asset.S3Key
+		if ref, ok := refBySHA[use.Asset.SHA256]; ok && ref.S3Key != "" {
+			key = ref.S3Key
+		}
+		parts := []model.Part{}
+		if err := s.s3.DownloadJSON(ctx, key, &parts, in.UserKEK); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.log.Warn("failed to read parts for asset reference check", zap.String("s3_key", key), zap.Error(err))
+			out.Complete = false
+			out.UnreadableParts = append(out.UnreadableParts, key)
+			continue
+		}
+		for _, part := range parts {
+			if part.Asset != nil && part.Asset.SHA256 != "" {
+				add(*part.Asset, use.Count)
+			}
+		}
+	}
+	return expected, nil
+}
+
+// listObjects returns the objects stored under the project's asset, disk and parts prefixes.
+func (s *assetRefCheckService) listObjects(ctx context.Context, projectID uuid.UUID) (map[string]blob.ObjectInfo, error) {
+	objects := map[string]blob.ObjectInfo{}
+	for _, kind := range assetKeyKinds {
+		err := s.s3.ListObjects(ctx, kind+"/"+projectID.String()+"/", func(obj blob.ObjectInfo) error {
+			objects[obj.Key] = obj
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list %s objects: %w", kind, err)
+		}
+	}
+	return objects, nil
+}
+
+// objectExists looks key up among the listed objects, asking the store for keys outside the
+// listed prefixes.
+func (s *assetRefCheckService) objectExists(ctx context.Context, projectID uuid.UUID, key string, objects map[string]blob.ObjectInfo) (bool, error) {
+	if _, ok := objects[key]; ok {
+		return true, nil
+	}
+	for _, kind := range assetKeyKinds {
+		if strings.HasPrefix(key, kind+"/"+projectID.String()+"/") {
+			return false, nil
+		}
+	}
+	if _, err := s.s3.Head(ctx, key); err != nil {
+		if errors.Is(err, blob.ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("head %s: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+type assetRefCheckFixture struct {
+	svc       *assetRefCheckService
+	store     *blob.LocalStore
+	rdb       *redis.Client
+	repo      *MockAssetReferenceRepo
+	buffer    *MockAssetRefBuffer
+	uploads   *fakeUploadRepo
+	projectID uuid.UUID
+
+	file, image, parts, leaked *model.Asset
+	gone                       model.Asset
+}
+
+// newAssetRefCheckFixture stores an artifact file referenced by an artifact and one of its
+// versions, and a parts JSON with an image part shared by three messages. Their references
+// are wrong: the file is under-counted, the image has one buffered increment and no row, and
+// a third asset holds references although nothing uses it and its object is gone. One stored
+// object has no reference at all.
+func newAssetRefCheckFixture(t *testing.T) *assetRefCheckFixture {
+	t.Helper()
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	rdb, _ := newTestRedis(t)
+
+	f := &assetRefCheckFixture{store: store, rdb: rdb, repo: &MockAssetReferenceRepo{}, buffer: &MockAssetRefBuffer{}, uploads: &fakeUploadRepo{uploads: map[uuid.UUID]model.Upload{}}, projectID: uuid.New()}
+	p := f.projectID.String()
+	f.svc = NewAssetRefCheckService(f.repo, f.buffer, f.uploads, store, rdb, cfg, zap.NewNop()).(*assetRefCheckService)
+
+	f.file, err = store.UploadBytes(ctx, "disks/"+p, "notes.txt", []byte("notes"), nil)
+	require.NoError(t, err)
+	f.image, err = store.UploadBytes(ctx, "assets/"+p, "cat.png", []byte("\x89PNG cat"), nil)
+	require.NoError(t, err)
+	f.parts, err = store.UploadJSON(ctx, "parts/"+p, []model.Part{{Type: "text", Text: "look"}, {Type: "image", Asset: f.image}}, nil)
+	require.NoError(t, err)
+	f.leaked, err = store.UploadBytes(ctx, "disks/"+p, "leaked.txt", []byte("leaked"), nil)
+	require.NoError(t, err)
+	f.gone = model.Asset{SHA256: "gone", S3Key: "disks/" + p + "/gone.txt", SizeB: 4}
+
+	ref := func(a model.Asset, count int) model.AssetReference {
+		return model.AssetReference{ProjectID: f.projectID, SHA256: a.SHA256, S3Key: a.S3Key, RefCount: count, AssetMeta: datatypes.NewJSONType(a)}
+	}
+	f.repo.On("ListAssetRefsByProject", mock.Anything, f.projectID).Return([]model.AssetReference{
+		ref(*f.file, 1), ref(*f.parts, 3), ref(f.gone, 2),
+	}, nil)
+	f.repo.On("CountAssetUses", mock.Anything, f.projectID).Return([]repo.AssetUse{
+		{Asset: *f.file, Count: 2},
+		{Asset: *f.parts, Count: 3, Parts: true},
+	}, nil)
+	f.buffer.On("Pending", mock.Anything, f.projectID).Return(map[string]int{f.image.SHA256: 1}, nil)
+	return f
+}
+
+func TestAssetRefCheckService_Check(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	grace := time.Duration(0)
+
+	t.Run("reports drift", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+
+		out, err := f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.True(t, out.Complete)
+		assert.Equal(t, 3, out.Assets)
+		assert.Equal(t, 4, out.Objects)
+		assert.ElementsMatch(t, []AssetRefCountDrift{
+			{SHA256: f.file.SHA256, S3Key: f.file.S3Key, RefCount: intPtr(1), Expected: 2},
+			{SHA256: f.image.SHA256, S3Key: f.image.S3Key, Pending: 1, Expected: 3},
+			{SHA256: "gone", S3Key: f.gone.S3Key, RefCount: intPtr(2), Expected: 0},
+		}, out.WrongCounts)
+		assert.Equal(t, []AssetObjectDrift{{S3Key: f.gone.S3Key, SHA256: "gone"}}, out.MissingObjects)
+		assert.Equal(t, []AssetObjectDrift{{S3Key: f.leaked.S3Key, SizeB: f.leaked.SizeB}}, out.LeakedObjects)
+		assert.Empty(t, out.UnreadableParts)
+		f.repo.AssertNotCalled(t, "SetAssetRefCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		_, err = f.store.Head(context.Background(), f.leaked.S3Key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("recent objects are not leaked", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+		hour := time.Hour
+
+		out, err := f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &hour})
+		require.NoError(t, err)
+		assert.Empty(t, out.LeakedObjects)
+	})
+
+	t.Run("repairs counts and deletes leaked objects", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+		f.repo.On("SetAssetRefCount", mock.Anything, f.projectID, mock.MatchedBy(func(a model.Asset) bool { return a.SHA256 == f.file.SHA256 }), intPtr(1), 2).Return(true, nil)
+		f.repo.On("SetAssetRefCount", mock.Anything, f.projectID, *f.image, (*int)(nil), 2).Return(true, nil)
+		// Referenced again since it was listed
+		f.repo.On("SetAssetRefCount", mock.Anything, f.projectID, mock.MatchedBy(func(a model.Asset) bool { return a.SHA256 == "gone" }), intPtr(2), 0).Return(false, nil)
+
+		out, err := f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, Repair: true, GracePeriod: &grace})
+		require.NoError(t, err)
+		require.Len(t, out.WrongCounts, 3)
+		repaired := map[string]bool{}
+		for _, d := range out.WrongCounts {
+			repaired[d.SHA256] = d.Repaired
+		}
+		assert.Equal(t, map[string]bool{f.file.SHA256: true, f.image.SHA256: true, "gone": false}, repaired)
+		require.Len(t, out.LeakedObjects, 1)
+		assert.True(t, out.LeakedObjects[0].Repaired)
+		f.repo.AssertExpectations(t)
+
+		_, err = f.store.Head(context.Background(), f.leaked.S3Key)
+		assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+		for _, a := range []*model.Asset{f.file, f.image, f.parts} {
+			_, err = f.store.Head(context.Background(), a.S3Key)
+			assert.NoError(t, err)
+		}
+
+		// The lock is released afterwards
+		assert.Zero(t, f.rdb.Exists(context.Background(), assetGCLockKey).Val())
+	})
+
+	t.Run("objects of uploads in flight are not leaked", func(t *testing.T) {
+		ctx := context.Background()
+
+		// A completed upload not attached to anything yet holds its file
+		f := newAssetRefCheckFixture(t)
+		uploadID := uuid.New()
+		require.NoError(t, f.uploads.Create(ctx, &model.Upload{
+			ID: uploadID, ProjectID: f.projectID, Status: model.UploadStatusCompleted,
+			AssetMeta: datatypes.NewJSONType(*f.leaked), ExpiresAt: time.Now().Add(time.Hour),
+		}))
+		out, err := f.svc.Check(ctx, AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.Empty(t, out.LeakedObjects)
+
+		// An expired one does not
+		f.uploads.expire(uploadID, time.Minute)
+		out, err = f.svc.Check(ctx, AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.Len(t, out.LeakedObjects, 1)
+
+		// A presigned upload of the same size may be committed into it
+		f = newAssetRefCheckFixture(t)
+		_, err = f.store.UploadFileDirect(ctx, directUploadPrefix(f.projectID)+uuid.New().String(), []byte("pushed"), "text/plain", nil)
+		require.NoError(t, err)
+		out, err = f.svc.Check(ctx, AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.Empty(t, out.LeakedObjects)
+	})
+
+	t.Run("unreadable parts only allow raising counts", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+		require.NoError(t, f.store.DeleteObject(context.Background(), f.parts.S3Key))
+		f.repo.On("SetAssetRefCount", mock.Anything, f.projectID, mock.Anything, intPtr(1), 2).Return(true, nil)
+
+		out, err := f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, Repair: true, GracePeriod: &grace})
+		require.NoError(t, err)
+		assert.False(t, out.Complete)
+		assert.Equal(t, []string{f.parts.S3Key}, out.UnreadableParts)
+		// The image looks unused without its parts, so it is reported leaked but kept
+		require.Len(t, out.LeakedObjects, 2)
+		for _, leaked := range out.LeakedObjects {
+			assert.False(t, leaked.Repaired)
+		}
+		f.repo.AssertNumberOfCalls(t, "SetAssetRefCount", 1)
+
+		_, err = f.store.Head(context.Background(), f.leaked.S3Key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("repair waits for a running sweep", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+		require.NoError(t, f.rdb.Set(context.Background(), assetGCLockKey, "1", time.Minute).Err())
+
+		_, err := f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, Repair: true})
+		assert.ErrorIs(t, err, ErrAssetGCRunning)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		f := newAssetRefCheckFixture(t)
+		negative := -time.Second
+
+		_, err := f.svc.Check(context.Background(), AssetRefCheckInput{})
+		assert.ErrorIs(t, err, ErrInvalidAssetRefCheck)
+		_, err = f.svc.Check(context.Background(), AssetRefCheckInput{ProjectID: f.projectID, GracePeriod: &negative})
+		assert.ErrorIs(t, err, ErrInvalidAssetRefCheck)
+	})
+}
@@ -7,21 +7,32 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
 	"github.com/memodb-io/Acontext/internal/modules/model"
 	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/memodb-io/Acontext/internal/pkg/paging"
+	"gorm.io/gorm"
 )
 
 type DiskService interface {
 	Create(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID) (*model.Disk, error)
 	Delete(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID) error
 	List(ctx context.Context, in ListDisksInput) (*ListDisksOutput, error)
+	CreateSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, name string) (*model.DiskSnapshot, error)
+	ListSnapshots(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error)
+	DeleteSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) error
+	RestoreSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) (*repo.DiskSnapshotRestoreStats, error)
+	CloneSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, userID *uuid.UUID) (*model.Disk, error)
 }
 
-type diskService struct{ r repo.DiskRepo }
+type diskService struct {
+	r         repo.DiskRepo
+	snapshots repo.DiskSnapshotRepo
+	cfg       *config.Config
+}
 
-func NewDiskService(r repo.DiskRepo) DiskService {
-	return &diskService{r: r}
+func NewDiskService(r repo.DiskRepo, snapshots repo.DiskSnapshotRepo, cfg *config.Config) DiskService {
+	return &diskService{r: r, snapshots: snapshots, cfg: cfg}
 }
 
 func (s *diskService) Create(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID) (*model.Disk, error) {
@@ -89,3 +100,69 @@ func (s *diskService) List(ctx context.Context, in ListDisksInput) (*ListDisksOu
 
 	return out, nil
 }
+
+// CreateSnapshot captures the current artifacts of a disk. Only asset references are added;
+// content is shared with the disk.
+func (s *diskService) CreateSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, name string) (*model.DiskSnapshot, error) {
+	snap := &model.DiskSnapshot{
+		ProjectID: projectID,
+		DiskID:    diskID,
+		Name:      name,
+	}
+	if err := s.snapshots.Create(ctx, snap); err != nil {
+		return nil, fmt.Errorf("create disk snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+func (s *diskService) ListSnapshots(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	return s.snapshots.ListByDisk(ctx, diskID)
+}
+
+func (s *diskService) DeleteSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) error {
+	if _, err := s.getSnapshot(ctx, projectID, snapshotID, &diskID); err != nil {
+		return err
+	}
+	return s.snapshots.Delete(ctx, projectID, snapshotID)
+}
+
+// RestoreSnapshot rolls a disk back to one of its snapshots.
+func (s *diskService) RestoreSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) (*repo.DiskSnapshotRestoreStats, error) {
+	snap, err := s.getSnapshot(ctx, projectID, snapshotID, &diskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshots.Restore(ctx, snap, artifactVersionPolicy(s.cfg))
+}
+
+// CloneSnapshot creates a new disk holding the artifacts captured by a snapshot.
+func (s *diskService) CloneSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, userID *uuid.UUID) (*model.Disk, error) {
+	snap, err := s.getSnapshot(ctx, projectID, snapshotID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := &model.Disk{
+		ProjectID: projectID,
+		UserID:    userID,
+	}
+	if err := s.snapshots.Clone(ctx, snap, disk); err != nil {
+		return nil, fmt.Errorf("clone disk snapshot: %w", err)
+	}
+	return disk, nil
+}
+
+// getSnapshot loads a snapshot of the project, optionally requiring it to belong to diskID.
+func (s *diskService) getSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, diskID *uuid.UUID) (*model.DiskSnapshot, error) {
+	snap, err := s.snapshots.Get(ctx, projectID, snapshotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	if diskID != nil && snap.DiskID != *diskID {
+		return nil, ErrSnapshotNotFound
+	}
+	return snap, nil
+}
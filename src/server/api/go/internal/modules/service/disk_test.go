@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockDiskRepo is a mock implementation of DiskRepo
@@ -92,6 +95,70 @@ func (s *testDiskService) List(ctx context.Context, in ListDisksInput) (*ListDis
 	return &ListDisksOutput{Items: disks, HasMore: false}, nil
 }
 
+func (s *testDiskService) CreateSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, name string) (*model.DiskSnapshot, error) {
+	return &model.DiskSnapshot{ProjectID: projectID, DiskID: diskID, Name: name}, nil
+}
+
+func (s *testDiskService) ListSnapshots(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	return nil, nil
+}
+
+func (s *testDiskService) DeleteSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) error {
+	return nil
+}
+
+func (s *testDiskService) RestoreSnapshot(ctx context.Context, projectID uuid.UUID, diskID uuid.UUID, snapshotID uuid.UUID) (*repo.DiskSnapshotRestoreStats, error) {
+	return &repo.DiskSnapshotRestoreStats{}, nil
+}
+
+func (s *testDiskService) CloneSnapshot(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID, userID *uuid.UUID) (*model.Disk, error) {
+	return &model.Disk{ID: uuid.New(), ProjectID: projectID, UserID: userID}, nil
+}
+
+// MockDiskSnapshotRepo is a mock implementation of DiskSnapshotRepo
+type MockDiskSnapshotRepo struct {
+	mock.Mock
+}
+
+func (m *MockDiskSnapshotRepo) Create(ctx context.Context, snap *model.DiskSnapshot) error {
+	args := m.Called(ctx, snap)
+	return args.Error(0)
+}
+
+func (m *MockDiskSnapshotRepo) Get(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) (*model.DiskSnapshot, error) {
+	args := m.Called(ctx, projectID, snapshotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskSnapshotRepo) ListByDisk(ctx context.Context, diskID uuid.UUID) ([]*model.DiskSnapshot, error) {
+	args := m.Called(ctx, diskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DiskSnapshot), args.Error(1)
+}
+
+func (m *MockDiskSnapshotRepo) Delete(ctx context.Context, projectID uuid.UUID, snapshotID uuid.UUID) error {
+	args := m.Called(ctx, projectID, snapshotID)
+	return args.Error(0)
+}
+
+func (m *MockDiskSnapshotRepo) Restore(ctx context.Context, snap *model.DiskSnapshot, policy repo.ArtifactVersionPolicy) (*repo.DiskSnapshotRestoreStats, error) {
+	args := m.Called(ctx, snap, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repo.DiskSnapshotRestoreStats), args.Error(1)
+}
+
+func (m *MockDiskSnapshotRepo) Clone(ctx context.Context, snap *model.DiskSnapshot, disk *model.Disk) error {
+	args := m.Called(ctx, snap, disk)
+	return args.Error(0)
+}
+
 func createTestDisk() *model.Disk {
 	projectID := uuid.New()
 	diskID := uuid.New()
@@ -299,3 +366,78 @@ func TestDiskService_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestDiskService_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	diskID := uuid.New()
+	snapshotID := uuid.New()
+	snap := &model.DiskSnapshot{ID: snapshotID, ProjectID: projectID, DiskID: diskID}
+
+	t.Run("create captures the disk", func(t *testing.T) {
+		snapshots := &MockDiskSnapshotRepo{}
+		snapshots.On("Create", ctx, mock.MatchedBy(func(s *model.DiskSnapshot) bool {
+			return s.ProjectID == projectID && s.DiskID == diskID && s.Name == "checkpoint"
+		})).Return(nil)
+
+		svc := NewDiskService(nil, snapshots, nil)
+		got, err := svc.CreateSnapshot(ctx, projectID, diskID, "checkpoint")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "checkpoint", got.Name)
+		snapshots.AssertExpectations(t)
+	})
+
+	t.Run("restore uses the configured version policy", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Artifact.MaxVersions = 5
+		stats := &repo.DiskSnapshotRestoreStats{Written: 2, Removed: 1}
+		snapshots := &MockDiskSnapshotRepo{}
+		snapshots.On("Get", ctx, projectID, snapshotID).Return(snap, nil)
+		snapshots.On("Restore", ctx, snap, repo.ArtifactVersionPolicy{Keep: 5}).Return(stats, nil)
+
+		svc := NewDiskService(nil, snapshots, cfg)
+		got, err := svc.RestoreSnapshot(ctx, projectID, diskID, snapshotID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stats, got)
+		snapshots.AssertExpectations(t)
+	})
+
+	t.Run("restore rejects a snapshot of another disk", func(t *testing.T) {
+		snapshots := &MockDiskSnapshotRepo{}
+		snapshots.On("Get", ctx, projectID, snapshotID).Return(snap, nil)
+
+		svc := NewDiskService(nil, snapshots, nil)
+		_, err := svc.RestoreSnapshot(ctx, projectID, uuid.New(), snapshotID)
+
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+		snapshots.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete of an unknown snapshot", func(t *testing.T) {
+		snapshots := &MockDiskSnapshotRepo{}
+		snapshots.On("Get", ctx, projectID, snapshotID).Return(nil, gorm.ErrRecordNotFound)
+
+		svc := NewDiskService(nil, snapshots, nil)
+		err := svc.DeleteSnapshot(ctx, projectID, diskID, snapshotID)
+
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	})
+
+	t.Run("clone creates a new disk for the user", func(t *testing.T) {
+		userID := uuid.New()
+		snapshots := &MockDiskSnapshotRepo{}
+		snapshots.On("Get", ctx, projectID, snapshotID).Return(snap, nil)
+		snapshots.On("Clone", ctx, snap, mock.MatchedBy(func(d *model.Disk) bool {
+			return d.ProjectID == projectID && d.UserID != nil && *d.UserID == userID
+		})).Return(nil)
+
+		svc := NewDiskService(nil, snapshots, nil)
+		disk, err := svc.CloneSnapshot(ctx, projectID, snapshotID, &userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, projectID, disk.ProjectID)
+		snapshots.AssertExpectations(t)
+	})
+}
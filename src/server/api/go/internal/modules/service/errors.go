@@ -1,6 +1,10 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+)
 
 // Service layer errors for better error handling
 var (
@@ -10,5 +14,52 @@ var (
 	ErrCopyFailed      = errors.New("failed to copy session")
 
 	// General session errors
-	ErrUnauthorized = errors.New("unauthorized access to session")
+	ErrUnauthorized  = errors.New("unauthorized access to session")
+	ErrInvalidTags   = errors.New("invalid session tags")
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// Bulk session job errors
+	ErrInvalidBulkRequest = errors.New("invalid bulk request")
+	ErrBulkJobNotFound    = errors.New("bulk job not found")
+
+	// Message feedback errors
+	ErrMessageNotFound = errors.New("message not found")
+	ErrInvalidFeedback = errors.New("invalid feedback")
+
+	// Artifact errors
+	ErrArtifactVersionNotFound = errors.New("artifact version not found")
+	ErrArtifactNotFound        = errors.New("artifact not found")
+	ErrArtifactConflict        = errors.New("artifact already exists at destination")
+	ErrInvalidTransfer         = errors.New("invalid move or copy request")
+	ErrInvalidArchive          = errors.New("invalid archive")
+	ErrPreconditionFailed      = repo.ErrPreconditionFailed // evaluated by the repository, atomically with the write
+	ErrArtifactNotText         = errors.New("artifact is not a text file")
+	ErrInvalidEdit             = errors.New("invalid artifact edit")
+	ErrEditMismatch            = errors.New("artifact content does not match edit")
+	ErrInvalidGrep             = errors.New("invalid grep query")
+	ErrInvalidGlob             = errors.New("invalid glob pattern")
+
+	// Artifact lease errors
+	ErrInvalidLease   = errors.New("invalid artifact lease")
+	ErrLeaseHeld      = errors.New("artifact lease held by another owner")
+	ErrLeaseNotFound  = errors.New("artifact lease not found")
+	ErrArtifactLocked = errors.New("artifact is locked by another owner")
+
+	// Disk snapshot errors
+	ErrSnapshotNotFound = errors.New("disk snapshot not found")
+
+	// Resumable upload errors
+	ErrUploadNotFound       = errors.New("upload not found")
+	ErrInvalidUpload        = errors.New("invalid upload request")
+	ErrUploadTooLarge       = errors.New("upload exceeds maximum allowed size")
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+	ErrUploadIncomplete     = errors.New("upload is not completed")
+	ErrUploadMismatch       = errors.New("uploaded content does not match")
+
+	// Asset garbage collection errors
+	ErrInvalidAssetGC = errors.New("invalid asset garbage collection request")
+	ErrAssetGCRunning = errors.New("asset garbage collection already running")
+
+	// Asset reference check errors
+	ErrInvalidAssetRefCheck = errors.New("invalid asset reference check request")
 )
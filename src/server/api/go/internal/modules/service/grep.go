@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	pathutil "github.com/memodb-io/Acontext/internal/pkg/utils/path"
+)
+
+// GrepMatch is one matching line of an artifact, with its surrounding context.
+type GrepMatch struct {
+	LineNumber int      `json:"line_number" example:"12"` // 1-based
+	Line       string   `json:"line" example:"// TODO: handle retries"`
+	Before     []string `json:"before,omitempty"` // up to before_context lines preceding the match
+	After      []string `json:"after,omitempty"`  // up to after_context lines following the match
+}
+
+// compileGrepPattern builds the line matcher for a grep query.
+func compileGrepPattern(pattern string, fixed bool, caseSensitive bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGrep, err)
+	}
+	return re, nil
+}
+
+// grepLines returns the lines of text matching re, each with up to before and after lines of
+// context. At most maxCount matches are returned when maxCount > 0; truncated reports whether
+// more matches were left out.
+func grepLines(text string, re *regexp.Regexp, before, after, maxCount int) (matches []GrepMatch, truncated bool) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if !re.MatchString(line) {
+			continue
+		}
+		if maxCount > 0 && len(matches) == maxCount {
+			return matches, true
+		}
+		m := GrepMatch{LineNumber: i + 1, Line: line}
+		if before > 0 {
+			m.Before = contextLines(lines[max(0, i-before):i])
+		}
+		if after > 0 {
+			m.After = contextLines(lines[i+1 : min(len(lines), i+1+after)])
+		}
+		matches = append(matches, m)
+	}
+	return matches, false
+}
+
+func contextLines(lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = strings.TrimSuffix(l, "\r")
+	}
+	return out
+}
+
+// matchPathGlob reports whether the artifact at dir+filename matches a path glob. A pattern
+// without '/' is matched against the file name, like ripgrep's --glob; otherwise against the
+// full path, with or without its leading '/'.
+func matchPathGlob(pattern string, dir string, filename string) bool {
+	if !strings.Contains(pattern, "/") {
+		return pathutil.MatchGlob(pattern, filename)
+	}
+	return pathutil.MatchGlob(pattern, dir+filename)
+}
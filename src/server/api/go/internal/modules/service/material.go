@@ -29,17 +29,17 @@ type MaterialMeta struct {
 type MaterialService interface {
 	// CreateMaterialURL generates a token, stores metadata in Redis, and returns a full URL + expiry.
 	CreateMaterialURL(ctx context.Context, s3Key string, userKEK string, expire time.Duration, mimeType string, fileName string) (url string, expireAt time.Time, err error)
-	// ServeMaterial looks up a token, downloads from S3, decrypts if needed, and returns content.
-	ServeMaterial(ctx context.Context, token string) (content []byte, mimeType string, fileName string, err error)
+	// ServeMaterial looks up a token and opens its object for streaming, decrypting if needed.
+	ServeMaterial(ctx context.Context, token string) (object *blob.Object, mimeType string, fileName string, err error)
 }
 
 type materialService struct {
 	redis *redis.Client
-	s3    *blob.S3Deps
+	s3    blob.BlobStore
 	cfg   *config.Config
 }
 
-func NewMaterialService(redis *redis.Client, s3 *blob.S3Deps, cfg *config.Config) MaterialService {
+func NewMaterialService(redis *redis.Client, s3 blob.BlobStore, cfg *config.Config) MaterialService {
 	return &materialService{redis: redis, s3: s3, cfg: cfg}
 }
 
@@ -75,7 +75,7 @@ func (m *materialService) CreateMaterialURL(ctx context.Context, s3Key string, u
 	return url, expireAt, nil
 }
 
-func (m *materialService) ServeMaterial(ctx context.Context, token string) ([]byte, string, string, error) {
+func (m *materialService) ServeMaterial(ctx context.Context, token string) (*blob.Object, string, string, error) {
 	redisKey := redisKeyPrefixMaterial + token
 
 	val, err := m.redis.Get(ctx, redisKey).Bytes()
@@ -101,12 +101,15 @@ func (m *materialService) ServeMaterial(ctx context.Context, token string) ([]by
 		userKEK = decoded
 	}
 
-	content, err := m.s3.DownloadFile(ctx, meta.S3Key, userKEK)
+	object, err := m.s3.OpenObject(ctx, meta.S3Key, userKEK)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("download file from S3: %w", err)
+		if errors.Is(err, blob.ErrObjectNotFound) {
+			return nil, "", "", ErrMaterialNotFound
+		}
+		return nil, "", "", fmt.Errorf("open file from S3: %w", err)
 	}
 
-	return content, meta.MIMEType, meta.FileName, nil
+	return object, meta.MIMEType, meta.FileName, nil
 }
 
 func (m *materialService) buildURL(token string) string {
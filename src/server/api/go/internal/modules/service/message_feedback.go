@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/pkg/paging"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// MinFeedbackScore and MaxFeedbackScore bound MessageFeedback.Score
+	MinFeedbackScore = 0.0
+	MaxFeedbackScore = 1.0
+	// MaxFeedbackCommentLength is the maximum number of characters in a feedback comment
+	MaxFeedbackCommentLength = 4096
+	// MaxFeedbackLabels is the maximum number of labels on a single feedback
+	MaxFeedbackLabels = 20
+	// MaxFeedbackLabelLength is the maximum number of characters in a single label
+	MaxFeedbackLabelLength = 64
+)
+
+type MessageFeedbackService interface {
+	Submit(ctx context.Context, in SubmitFeedbackInput) (*model.MessageFeedback, error)
+	List(ctx context.Context, in ListFeedbackInput) (*ListFeedbackOutput, error)
+}
+
+type SubmitFeedbackInput struct {
+	ProjectID uuid.UUID
+	SessionID uuid.UUID
+	MessageID uuid.UUID
+	User      string // optional user identifier of the rater
+	Thumbs    string // optional, one of model.FeedbackThumbs*
+	Score     *float64
+	Comment   string
+	Labels    []string
+}
+
+type ListFeedbackInput struct {
+	ProjectID uuid.UUID
+	SessionID *uuid.UUID // restricts the listing to one session when set
+	Filter    repo.MessageFeedbackFilter
+	Limit     int
+	Cursor    string
+	TimeDesc  bool
+}
+
+type ListFeedbackOutput struct {
+	Items      []model.MessageFeedback      `json:"items"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+	HasMore    bool                         `json:"has_more"`
+	Summary    *repo.MessageFeedbackSummary `json:"summary"`
+}
+
+type messageFeedbackService struct {
+	sessionRepo  repo.SessionRepo
+	feedbackRepo repo.MessageFeedbackRepo
+	userSvc      UserService
+}
+
+func NewMessageFeedbackService(sessionRepo repo.SessionRepo, feedbackRepo repo.MessageFeedbackRepo, userSvc UserService) MessageFeedbackService {
+	return &messageFeedbackService{
+		sessionRepo:  sessionRepo,
+		feedbackRepo: feedbackRepo,
+		userSvc:      userSvc,
+	}
+}
+
+func (s *messageFeedbackService) Submit(ctx context.Context, in SubmitFeedbackInput) (*model.MessageFeedback, error) {
+	labels, err := normalizeLabels(in.Labels, MaxFeedbackLabels, MaxFeedbackLabelLength, "label")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFeedback, err)
+	}
+	comment := strings.TrimSpace(in.Comment)
+	if in.Thumbs == "" && in.Score == nil && comment == "" && len(labels) == 0 {
+		return nil, fmt.Errorf("%w: at least one of thumbs, score, comment or labels is required", ErrInvalidFeedback)
+	}
+	if in.Thumbs != "" && in.Thumbs != model.FeedbackThumbsUp && in.Thumbs != model.FeedbackThumbsDown {
+		return nil, fmt.Errorf("%w: thumbs must be %q or %q", ErrInvalidFeedback, model.FeedbackThumbsUp, model.FeedbackThumbsDown)
+	}
+	if in.Score != nil && (*in.Score < MinFeedbackScore || *in.Score > MaxFeedbackScore) {
+		return nil, fmt.Errorf("%w: score must be between %g and %g", ErrInvalidFeedback, MinFeedbackScore, MaxFeedbackScore)
+	}
+	if utf8.RuneCountInString(comment) > MaxFeedbackCommentLength {
+		return nil, fmt.Errorf("%w: comment exceeds %d characters", ErrInvalidFeedback, MaxFeedbackCommentLength)
+	}
+
+	if err := s.checkSession(ctx, in.ProjectID, in.SessionID); err != nil {
+		return nil, err
+	}
+	if _, err := s.sessionRepo.GetMessageByID(ctx, in.SessionID, in.MessageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("get message: %w", err)
+	}
+
+	fb := &model.MessageFeedback{
+		ProjectID: in.ProjectID,
+		SessionID: in.SessionID,
+		MessageID: in.MessageID,
+		Score:     in.Score,
+		Comment:   comment,
+		Labels:    datatypes.NewJSONSlice(labels),
+	}
+	if in.Thumbs != "" {
+		thumbs := in.Thumbs
+		fb.Thumbs = &thumbs
+	}
+	if in.User != "" {
+		user, err := s.userSvc.GetOrCreate(ctx, in.ProjectID, in.User)
+		if err != nil {
+			return nil, fmt.Errorf("get or create user: %w", err)
+		}
+		fb.UserID = &user.ID
+	}
+
+	if err := s.feedbackRepo.Create(ctx, fb); err != nil {
+		return nil, fmt.Errorf("create feedback: %w", err)
+	}
+	return fb, nil
+}
+
+// List returns a page of feedback, newest first when TimeDesc is set, together with
+// a summary aggregated over every feedback matching the filter (not just the page).
+func (s *messageFeedbackService) List(ctx context.Context, in ListFeedbackInput) (*ListFeedbackOutput, error) {
+	filter := in.Filter
+	if in.SessionID != nil {
+		if err := s.checkSession(ctx, in.ProjectID, *in.SessionID); err != nil {
+			return nil, err
+		}
+		filter.SessionID = in.SessionID
+	}
+
+	var afterT time.Time
+	var afterID uuid.UUID
+	if in.Cursor != "" {
+		var err error
+		afterT, afterID, err = paging.DecodeCursor(in.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+	}
+
+	items, err := s.feedbackRepo.ListWithCursor(ctx, in.ProjectID, filter, afterT, afterID, in.Limit+1, in.TimeDesc)
+	if err != nil {
+		return nil, fmt.Errorf("list feedback: %w", err)
+	}
+	summary, err := s.feedbackRepo.Summarize(ctx, in.ProjectID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("summarize feedback: %w", err)
+	}
+
+	out := &ListFeedbackOutput{
+		Items:   items,
+		HasMore: false,
+		Summary: summary,
+	}
+	if len(items) > in.Limit {
+		out.HasMore = true
+		out.Items = items[:in.Limit]
+		last := out.Items[len(out.Items)-1]
+		out.NextCursor = paging.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return out, nil
+}
+
+// checkSession verifies the session exists and belongs to the project.
+func (s *messageFeedbackService) checkSession(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.Get(ctx, &model.Session{ID: sessionID})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("get session: %w", err)
+	}
+	if session.ProjectID != projectID {
+		return ErrSessionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/pkg/paging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockMessageFeedbackRepo is a mock implementation of MessageFeedbackRepo
+type MockMessageFeedbackRepo struct {
+	mock.Mock
+}
+
+func (m *MockMessageFeedbackRepo) Create(ctx context.Context, fb *model.MessageFeedback) error {
+	args := m.Called(ctx, fb)
+	return args.Error(0)
+}
+
+func (m *MockMessageFeedbackRepo) ListWithCursor(ctx context.Context, projectID uuid.UUID, filter repo.MessageFeedbackFilter, afterCreatedAt time.Time, afterID uuid.UUID, limit int, timeDesc bool) ([]model.MessageFeedback, error) {
+	args := m.Called(ctx, projectID, filter, afterCreatedAt, afterID, limit, timeDesc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.MessageFeedback), args.Error(1)
+}
+
+func (m *MockMessageFeedbackRepo) Summarize(ctx context.Context, projectID uuid.UUID, filter repo.MessageFeedbackFilter) (*repo.MessageFeedbackSummary, error) {
+	args := m.Called(ctx, projectID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repo.MessageFeedbackSummary), args.Error(1)
+}
+
+func TestMessageFeedbackService_Submit(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	sessionID := uuid.New()
+	messageID := uuid.New()
+	userID := uuid.New()
+	matchSession := mock.MatchedBy(func(s *model.Session) bool { return s.ID == sessionID })
+	score := 0.5
+
+	t.Run("stores feedback with normalized labels and user", func(t *testing.T) {
+		sessionRepo := &MockSessionRepo{}
+		sessionRepo.On("Get", ctx, matchSession).Return(&model.Session{ID: sessionID, ProjectID: projectID}, nil)
+		sessionRepo.On("GetMessageByID", ctx, sessionID, messageID).Return(&model.Message{ID: messageID}, nil)
+		userRepo := &MockUserRepo{}
+		userRepo.On("GetOrCreate", ctx, projectID, "alice").Return(&model.User{ID: userID}, nil)
+		feedbackRepo := &MockMessageFeedbackRepo{}
+		feedbackRepo.On("Create", ctx, mock.MatchedBy(func(fb *model.MessageFeedback) bool {
+			return fb.MessageID == messageID && fb.SessionID == sessionID &&
+				fb.UserID != nil && *fb.UserID == userID &&
+				fb.Thumbs != nil && *fb.Thumbs == model.FeedbackThumbsDown &&
+				fb.Comment == "too long" &&
+				len(fb.Labels) == 2 && fb.Labels[0] == "style" && fb.Labels[1] == "verbose"
+		})).Return(nil)
+
+		svc := NewMessageFeedbackService(sessionRepo, feedbackRepo, NewUserService(userRepo))
+		fb, err := svc.Submit(ctx, SubmitFeedbackInput{
+			ProjectID: projectID,
+			SessionID: sessionID,
+			MessageID: messageID,
+			User:      "alice",
+			Thumbs:    model.FeedbackThumbsDown,
+			Score:     &score,
+			Comment:   "  too long ",
+			Labels:    []string{"verbose", " style", "verbose"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, &score, fb.Score)
+		sessionRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		feedbackRepo.AssertExpectations(t)
+	})
+
+	t.Run("message outside the session is not found", func(t *testing.T) {
+		sessionRepo := &MockSessionRepo{}
+		sessionRepo.On("Get", ctx, matchSession).Return(&model.Session{ID: sessionID, ProjectID: projectID}, nil)
+		sessionRepo.On("GetMessageByID", ctx, sessionID, messageID).Return(nil, gorm.ErrRecordNotFound)
+		feedbackRepo := &MockMessageFeedbackRepo{}
+
+		svc := NewMessageFeedbackService(sessionRepo, feedbackRepo, nil)
+		_, err := svc.Submit(ctx, SubmitFeedbackInput{ProjectID: projectID, SessionID: sessionID, MessageID: messageID, Thumbs: model.FeedbackThumbsUp})
+
+		assert.ErrorIs(t, err, ErrMessageNotFound)
+		feedbackRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("session from another project is not found", func(t *testing.T) {
+		sessionRepo := &MockSessionRepo{}
+		sessionRepo.On("Get", ctx, matchSession).Return(&model.Session{ID: sessionID, ProjectID: uuid.New()}, nil)
+
+		svc := NewMessageFeedbackService(sessionRepo, &MockMessageFeedbackRepo{}, nil)
+		_, err := svc.Submit(ctx, SubmitFeedbackInput{ProjectID: projectID, SessionID: sessionID, MessageID: messageID, Thumbs: model.FeedbackThumbsUp})
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	invalid := []struct {
+		name string
+		in   SubmitFeedbackInput
+	}{
+		{name: "empty feedback", in: SubmitFeedbackInput{Comment: "   "}},
+		{name: "unknown thumbs", in: SubmitFeedbackInput{Thumbs: "sideways"}},
+		{name: "score out of range", in: SubmitFeedbackInput{Score: func() *float64 { v := 1.5; return &v }()}},
+		{name: "comment too long", in: SubmitFeedbackInput{Comment: strings.Repeat("a", MaxFeedbackCommentLength+1)}},
+		{name: "blank label", in: SubmitFeedbackInput{Labels: []string{" "}}},
+		{name: "label too long", in: SubmitFeedbackInput{Labels: []string{strings.Repeat("a", MaxFeedbackLabelLength+1)}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMessageFeedbackService(&MockSessionRepo{}, &MockMessageFeedbackRepo{}, nil)
+			tt.in.ProjectID, tt.in.SessionID, tt.in.MessageID = projectID, sessionID, messageID
+
+			_, err := svc.Submit(ctx, tt.in)
+
+			assert.ErrorIs(t, err, ErrInvalidFeedback)
+		})
+	}
+}
+
+func TestMessageFeedbackService_List(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	sessionID := uuid.New()
+	matchSession := mock.MatchedBy(func(s *model.Session) bool { return s.ID == sessionID })
+	now := time.Now()
+	items := []model.MessageFeedback{
+		{ID: uuid.New(), CreatedAt: now},
+		{ID: uuid.New(), CreatedAt: now.Add(time.Second)},
+		{ID: uuid.New(), CreatedAt: now.Add(2 * time.Second)},
+	}
+	summary := &repo.MessageFeedbackSummary{Count: 3, ThumbsUp: 2, LabelCounts: map[string]int64{}}
+
+	t.Run("session listing pages and summarizes", func(t *testing.T) {
+		filter := repo.MessageFeedbackFilter{SessionID: &sessionID, Thumbs: model.FeedbackThumbsUp}
+		sessionRepo := &MockSessionRepo{}
+		sessionRepo.On("Get", ctx, matchSession).Return(&model.Session{ID: sessionID, ProjectID: projectID}, nil)
+		feedbackRepo := &MockMessageFeedbackRepo{}
+		feedbackRepo.On("ListWithCursor", ctx, projectID, filter, time.Time{}, uuid.Nil, 3, false).Return(items, nil)
+		feedbackRepo.On("Summarize", ctx, projectID, filter).Return(summary, nil)
+
+		svc := NewMessageFeedbackService(sessionRepo, feedbackRepo, nil)
+		out, err := svc.List(ctx, ListFeedbackInput{
+			ProjectID: projectID,
+			SessionID: &sessionID,
+			Filter:    repo.MessageFeedbackFilter{Thumbs: model.FeedbackThumbsUp},
+			Limit:     2,
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, out.Items, 2)
+		assert.True(t, out.HasMore)
+		assert.Equal(t, paging.EncodeCursor(items[1].CreatedAt, items[1].ID), out.NextCursor)
+		assert.Equal(t, summary, out.Summary)
+		feedbackRepo.AssertExpectations(t)
+	})
+
+	t.Run("project listing skips the session check", func(t *testing.T) {
+		feedbackRepo := &MockMessageFeedbackRepo{}
+		feedbackRepo.On("ListWithCursor", ctx, projectID, repo.MessageFeedbackFilter{}, time.Time{}, uuid.Nil, 51, true).Return(items[:1], nil)
+		feedbackRepo.On("Summarize", ctx, projectID, repo.MessageFeedbackFilter{}).Return(summary, nil)
+
+		svc := NewMessageFeedbackService(&MockSessionRepo{}, feedbackRepo, nil)
+		out, err := svc.List(ctx, ListFeedbackInput{ProjectID: projectID, Limit: 50, TimeDesc: true})
+
+		require.NoError(t, err)
+		assert.Len(t, out.Items, 1)
+		assert.False(t, out.HasMore)
+		assert.Empty(t, out.NextCursor)
+		feedbackRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		sessionRepo := &MockSessionRepo{}
+		sessionRepo.On("Get", ctx, matchSession).Return(nil, gorm.ErrRecordNotFound)
+
+		svc := NewMessageFeedbackService(sessionRepo, &MockMessageFeedbackRepo{}, nil)
+		_, err := svc.List(ctx, ListFeedbackInput{ProjectID: projectID, SessionID: &sessionID, Limit: 10})
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
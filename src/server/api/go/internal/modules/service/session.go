@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"mime/multipart"
 	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 	"github.com/go-playground/validator/v10"
@@ -21,6 +23,8 @@ import (
 	"github.com/memodb-io/Acontext/internal/modules/repo"
 	"github.com/memodb-io/Acontext/internal/pkg/editor"
 	"github.com/memodb-io/Acontext/internal/pkg/paging"
+	"github.com/memodb-io/Acontext/internal/pkg/tokenizer"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/compress"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
@@ -41,6 +45,24 @@ type SessionService interface {
 	PatchConfigs(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, patchConfigs map[string]interface{}) (map[string]interface{}, error)
 	CopySession(ctx context.Context, in CopySessionInput) (*CopySessionOutput, error)
 	DownloadAsset(ctx context.Context, s3Key string, userKEK []byte) ([]byte, error)
+	GetStats(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) (*SessionStatsOutput, error)
+	UpdateInfo(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, in UpdateSessionInfoInput) (*model.Session, error)
+	BulkUpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error)
+	BulkSetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error)
+	ListPendingUploads(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error)
+}
+
+// UpdateSessionInfoInput holds the title, tags and archive state to overwrite; nil fields are left unchanged.
+type UpdateSessionInfoInput struct {
+	Title    *string
+	Tags     *[]string
+	Archived *bool
+}
+
+type SessionStatsOutput struct {
+	model.SessionStats
+	ToolErrorRate float64          `json:"tool_error_rate"`
+	TaskCounts    map[string]int64 `json:"task_counts"`
 }
 
 type CopySessionInput struct {
@@ -60,11 +82,12 @@ type sessionService struct {
 	assetReferenceRepo repo.AssetReferenceRepo
 	assetRefBuffer     repo.AssetRefBuffer
 	log                *zap.Logger
-	s3                 *blob.S3Deps
+	s3                 blob.BlobStore
 	publisher          *mq.Publisher
 	cfg                *config.Config
 	redis              *redis.Client
 	materialSvc        MaterialService
+	outbox             AssetOutboxService
 }
 
 const (
@@ -74,11 +97,22 @@ const (
 	defaultPartsCacheTTL = time.Hour
 
 	// Cache framing prefix bytes to distinguish encrypted vs plaintext cached data
-	cachePrefixPlaintext byte = 0x00
-	cachePrefixEncrypted byte = 0x01
+	cachePrefixPlaintext           byte = 0x00
+	cachePrefixEncrypted           byte = 0x01
+	cachePrefixCompressed          byte = 0x02
+	cachePrefixCompressedEncrypted byte = 0x03
+
+	// Parts JSON shorter than this is cached uncompressed
+	minCompressedCacheSize = 1024
 )
 
-func NewSessionService(sessionRepo repo.SessionRepo, sessionEventRepo repo.SessionEventRepo, assetReferenceRepo repo.AssetReferenceRepo, assetRefBuffer repo.AssetRefBuffer, log *zap.Logger, s3 *blob.S3Deps, publisher *mq.Publisher, cfg *config.Config, redis *redis.Client, materialSvc MaterialService) SessionService {
+// cacheCompressionAlgos maps the algorithm byte of a compressed cache entry to its codec.
+var cacheCompressionAlgos = map[byte]string{
+	0x01: compress.Gzip,
+	0x02: compress.Zstd,
+}
+
+func NewSessionService(sessionRepo repo.SessionRepo, sessionEventRepo repo.SessionEventRepo, assetReferenceRepo repo.AssetReferenceRepo, assetRefBuffer repo.AssetRefBuffer, log *zap.Logger, s3 blob.BlobStore, publisher *mq.Publisher, cfg *config.Config, redis *redis.Client, materialSvc MaterialService, outbox AssetOutboxService) SessionService {
 	return &sessionService{
 		sessionRepo:        sessionRepo,
 		sessionEventRepo:   sessionEventRepo,
@@ -90,10 +124,16 @@ func NewSessionService(sessionRepo repo.SessionRepo, sessionEventRepo repo.Sessi
 		cfg:                cfg,
 		redis:              redis,
 		materialSvc:        materialSvc,
+		outbox:             outbox,
 	}
 }
 
 func (s *sessionService) Create(ctx context.Context, ss *model.Session) error {
+	tags, err := NormalizeTags(ss.Tags)
+	if err != nil {
+		return err
+	}
+	ss.Tags = tags
 	return s.sessionRepo.Create(ctx, ss)
 }
 
@@ -121,12 +161,27 @@ func (s *sessionService) GetByID(ctx context.Context, ss *model.Session) (*model
 }
 
 type ListSessionsInput struct {
-	ProjectID       uuid.UUID              `json:"project_id"`
-	User            string                 `json:"user"`
-	FilterByConfigs map[string]interface{} `json:"filter_by_configs"` // Filter by configs JSONB containment
-	Limit           int                    `json:"limit"`
-	Cursor          string                 `json:"cursor"`
-	TimeDesc        bool                   `json:"time_desc"`
+	ProjectID        uuid.UUID              `json:"project_id"`
+	User             string                 `json:"user"`
+	FilterByConfigs  map[string]interface{} `json:"filter_by_configs"` // Filter by configs JSONB containment
+	ConfigConditions []repo.ConfigCondition `json:"config_conditions"` // Key existence / comparison filters on configs
+	CreatedAfter     *time.Time             `json:"created_after"`
+	CreatedBefore    *time.Time             `json:"created_before"`
+	UpdatedAfter     *time.Time             `json:"updated_after"`
+	UpdatedBefore    *time.Time             `json:"updated_before"`
+	HasMessages      *bool                  `json:"has_messages"`
+	MinMessages      *int                   `json:"min_messages"`
+	MaxMessages      *int                   `json:"max_messages"`
+	TaskStatus       string                 `json:"task_status"`
+	LearningSpaceID  *uuid.UUID             `json:"learning_space_id"`
+	InLearningSpace  *bool                  `json:"in_learning_space"`
+	Tags             []string               `json:"tags"` // Sessions must carry all of these tags
+	TitleContains    string                 `json:"title_contains"`
+	Archived         *bool                  `json:"archived"`
+	SortBy           string                 `json:"sort_by"` // created_at (default), updated_at, last_message_at
+	Limit            int                    `json:"limit"`
+	Cursor           string                 `json:"cursor"`
+	TimeDesc         bool                   `json:"time_desc"`
 }
 
 type ListSessionsOutput struct {
@@ -136,19 +191,49 @@ type ListSessionsOutput struct {
 }
 
 func (s *sessionService) List(ctx context.Context, in ListSessionsInput) (*ListSessionsOutput, error) {
-	// Parse cursor (createdAt, id); an empty cursor indicates starting from the latest
+	sortBy := in.SortBy
+	if sortBy == "" {
+		sortBy = repo.SessionSortCreatedAt
+	}
+
+	// Parse cursor (sort key, sort value, id); an empty cursor indicates starting from the latest
 	var afterT time.Time
 	var afterID uuid.UUID
 	var err error
 	if in.Cursor != "" {
-		afterT, afterID, err = paging.DecodeCursor(in.Cursor)
+		var cursorSortBy string
+		cursorSortBy, afterT, afterID, err = paging.DecodeSortCursor(in.Cursor)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		// The sort value of another key would skip or repeat sessions
+		if cursorSortBy != sortBy {
+			return nil, fmt.Errorf("%w: cursor was issued for sort_by=%s", ErrInvalidCursor, cursorSortBy)
 		}
 	}
 
+	filter := repo.SessionListFilter{
+		UserIdentifier:   in.User,
+		FilterByConfigs:  in.FilterByConfigs,
+		ConfigConditions: in.ConfigConditions,
+		CreatedAfter:     in.CreatedAfter,
+		CreatedBefore:    in.CreatedBefore,
+		UpdatedAfter:     in.UpdatedAfter,
+		UpdatedBefore:    in.UpdatedBefore,
+		HasMessages:      in.HasMessages,
+		MinMessages:      in.MinMessages,
+		MaxMessages:      in.MaxMessages,
+		TaskStatus:       in.TaskStatus,
+		LearningSpaceID:  in.LearningSpaceID,
+		InLearningSpace:  in.InLearningSpace,
+		Tags:             in.Tags,
+		TitleContains:    in.TitleContains,
+		Archived:         in.Archived,
+		SortBy:           sortBy,
+	}
+
 	// Query limit+1 is used to determine has_more
-	sessions, err := s.sessionRepo.ListWithCursor(ctx, in.ProjectID, in.User, in.FilterByConfigs, afterT, afterID, in.Limit+1, in.TimeDesc)
+	sessions, err := s.sessionRepo.ListWithCursor(ctx, in.ProjectID, filter, afterT, afterID, in.Limit+1, in.TimeDesc)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +246,7 @@ func (s *sessionService) List(ctx context.Context, in ListSessionsInput) (*ListS
 		out.HasMore = true
 		out.Items = sessions[:in.Limit]
 		last := out.Items[len(out.Items)-1]
-		out.NextCursor = paging.EncodeCursor(last.CreatedAt, last.ID)
+		out.NextCursor = paging.EncodeSortCursor(sortBy, filter.SortValue(last), last.ID)
 	}
 
 	return out, nil
@@ -175,7 +260,8 @@ type StoreMessageInput struct {
 	Format      model.MessageFormat    // Message format (acontext, openai, anthropic, gemini)
 	MessageMeta map[string]interface{} // Message-level metadata (e.g., name, source_format)
 	Files       map[string]*multipart.FileHeader
-	UserKEK     []byte // optional: for envelope encryption
+	Uploads     map[string]*model.Upload // completed resumable uploads, by ID
+	UserKEK     []byte                   // optional: for envelope encryption
 }
 
 type StoreMQPublishJSON struct {
@@ -189,6 +275,7 @@ type PartIn struct {
 	Type      string                 `json:"type" validate:"required,oneof=text image audio video file tool-call tool-result data thinking redacted_thinking"` // "text" | "image" | ...
 	Text      string                 `json:"text,omitempty"`                                                                                                   // Text sharding
 	FileField string                 `json:"file_field,omitempty"`                                                                                             // File field name in the form
+	UploadID  string                 `json:"upload_id,omitempty"`                                                                                              // Completed resumable upload holding the file, instead of file_field
 	Meta      map[string]interface{} `json:"meta,omitempty"`                                                                                                   // [Optional] metadata
 }
 
@@ -200,6 +287,10 @@ func (p *PartIn) Validate() error {
 		return err
 	}
 
+	if p.FileField != "" && p.UploadID != "" {
+		return errors.New("part cannot have both file_field and upload_id")
+	}
+
 	// Validate required fields based on type (using model constants)
 	switch p.Type {
 	case model.PartTypeText:
@@ -344,6 +435,18 @@ func (s *sessionService) StoreMessage(ctx context.Context, in StoreMessageInput)
 			part.Filename = fh.Filename
 		}
 
+		if partIn.UploadID != "" {
+			upload, ok := in.Uploads[partIn.UploadID]
+			if !ok || upload == nil {
+				return nil, fmt.Errorf("parts[%d]: missing upload %s", idx, partIn.UploadID)
+			}
+			// Already stored by the completed upload
+			asset := upload.AssetMeta.Data()
+			uploadedAssets = append(uploadedAssets, asset)
+			part.Asset = &asset
+			part.Filename = upload.Filename
+		}
+
 		if partIn.Text != "" {
 			part.Text = partIn.Text
 		}
@@ -368,20 +471,46 @@ func (s *sessionService) StoreMessage(ctx context.Context, in StoreMessageInput)
 		}
 	}
 
-	// Upload all assets to S3 asynchronously — not on the request critical path.
+	// Files too large to keep in memory are streamed from the request's multipart files,
+	// which are removed once the request completes, so they are uploaded before responding.
+	asyncUploads := pendingUploads[:0:0]
+	for _, p := range pendingUploads {
+		if p.Open == nil {
+			asyncUploads = append(asyncUploads, p)
+			continue
+		}
+		if err := s.s3.UploadPrepared(ctx, p, in.UserKEK); err != nil {
+			return nil, fmt.Errorf("upload %s failed: %w", p.Asset.S3Key, err)
+		}
+	}
+
+	// Upload the remaining assets asynchronously — not on the request critical path. They
+	// are persisted in the outbox first, which retries failed uploads until they are stored.
 	// Since S3 keys are content-addressed (SHA256), uploads are idempotent.
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		defer cancel()
-		for _, p := range pendingUploads {
-			if err := s.s3.UploadPrepared(bgCtx, p, in.UserKEK); err != nil {
-				s.log.Error("async S3 upload failed",
-					zap.String("s3_key", p.Asset.S3Key),
-					zap.String("sha256", p.Asset.SHA256),
-					zap.Error(err))
+	if s.outbox != nil {
+		if err := s.outbox.Enqueue(ctx, in.ProjectID, in.SessionID, asyncUploads, in.UserKEK); err != nil {
+			s.log.Warn("failed to enqueue asset uploads, uploading them now",
+				zap.String("session_id", in.SessionID.String()), zap.Error(err))
+			for _, p := range asyncUploads {
+				if err := s.s3.UploadPrepared(ctx, p, in.UserKEK); err != nil {
+					return nil, fmt.Errorf("upload %s failed: %w", p.Asset.S3Key, err)
+				}
 			}
 		}
-	}()
+	} else {
+		go func() {
+			bgCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			for _, p := range asyncUploads {
+				if err := s.s3.UploadPrepared(bgCtx, p, in.UserKEK); err != nil {
+					s.log.Error("async S3 upload failed",
+						zap.String("s3_key", p.Asset.S3Key),
+						zap.String("sha256", p.Asset.SHA256),
+						zap.Error(err))
+				}
+			}
+		}()
+	}
 
 	// Buffer asset reference increments in Redis for coalesced DB flush.
 	if err := s.assetRefBuffer.Enqueue(ctx, in.ProjectID, uploadedAssets); err != nil {
@@ -411,10 +540,26 @@ func (s *sessionService) StoreMessage(ctx context.Context, in StoreMessageInput)
 		msg.SessionTaskProcessStatus = model.MessageStatusDisableTracking
 	}
 
-	if err := s.sessionRepo.CreateMessageWithAssets(ctx, &msg); err != nil {
+	// Token counting failures only degrade stats; they never block the write.
+	tokens, err := tokenizer.CountSingleMessageTokens(ctx, msg)
+	if err != nil {
+		s.log.Warn("failed to count message tokens for session stats", zap.Error(err))
+		tokens = 0
+	}
+	stats := model.NewMessageStats(in.SessionID, in.ProjectID, in.Role, parts, tokens, time.Now())
+
+	if err := s.sessionRepo.CreateMessageWithAssets(ctx, &msg, &stats); err != nil {
 		return nil, err
 	}
 
+	if s.cfg != nil && s.cfg.Session.AutoTitle && session.Title == "" && in.Role == model.RoleUser {
+		if title := deriveTitle(parts); title != "" {
+			if err := s.sessionRepo.SetTitleIfEmpty(ctx, in.SessionID, title); err != nil {
+				s.log.Warn("failed to set session title", zap.String("session_id", in.SessionID.String()), zap.Error(err))
+			}
+		}
+	}
+
 	if !disableTaskTracking && s.publisher != nil {
 		mqMsg := StoreMQPublishJSON{
 			ProjectID: in.ProjectID,
@@ -596,14 +741,42 @@ func (s *sessionService) DownloadAsset(ctx context.Context, s3Key string, userKE
 	if s.s3 == nil {
 		return nil, errors.New("S3 not configured")
 	}
-	return s.s3.DownloadFile(ctx, s3Key, userKEK)
+	data, err := s.s3.DownloadFile(ctx, s3Key, userKEK)
+	if err != nil && s.outbox != nil {
+		// The asset may not be uploaded yet
+		if pending, outboxErr := s.outbox.Read(ctx, s3Key, userKEK); outboxErr == nil {
+			return pending, nil
+		}
+	}
+	return data, err
+}
+
+// ListPendingUploads lists the assets of the session's messages that are not uploaded yet.
+func (s *sessionService) ListPendingUploads(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID) ([]*model.PendingAssetUpload, error) {
+	session, err := s.sessionRepo.Get(ctx, &model.Session{ID: sessionID})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.ProjectID != projectID {
+		return nil, ErrSessionNotFound
+	}
+	if s.outbox == nil {
+		return []*model.PendingAssetUpload{}, nil
+	}
+	return s.outbox.List(ctx, projectID, sessionID)
 }
 
 // cachePartsInRedis stores message parts in Redis with a fixed TTL.
-// When userKEK is provided, the serialized JSON is encrypted before caching.
+// The serialized JSON is compressed with the blob compression algorithm when it is large
+// enough, and when userKEK is provided it is then encrypted before caching.
 // Format: prefix_byte | payload
 //   - 0x00 | json_data (plaintext)
 //   - 0x01 | wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext (encrypted)
+//   - 0x02 | algo | compressed json_data (compressed)
+//   - 0x03 | algo | wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext (compressed, then encrypted)
 func (s *sessionService) cachePartsInRedis(ctx context.Context, projectID string, sha256 string, parts []model.Part, userKEK []byte) error {
 	if s.redis == nil {
 		return errors.New("redis client is not available")
@@ -615,6 +788,23 @@ func (s *sessionService) cachePartsInRedis(ctx context.Context, projectID string
 		return fmt.Errorf("marshal parts to JSON: %w", err)
 	}
 
+	// header is the prefix byte, followed by the algorithm byte when compressed
+	header := []byte{cachePrefixPlaintext}
+	if userKEK != nil {
+		header[0] = cachePrefixEncrypted
+	}
+	if algoByte, algo := s.cacheCompression(); algo != "" && len(jsonData) >= minCompressedCacheSize {
+		compressed, err := compress.Compress(algo, jsonData)
+		if err != nil {
+			return fmt.Errorf("compress parts for cache: %w", err)
+		}
+		jsonData = compressed
+		header = []byte{cachePrefixCompressed, algoByte}
+		if userKEK != nil {
+			header[0] = cachePrefixCompressedEncrypted
+		}
+	}
+
 	var cacheData []byte
 	if userKEK != nil {
 		// Encrypt the JSON data
@@ -623,17 +813,18 @@ func (s *sessionService) cachePartsInRedis(ctx context.Context, projectID string
 			return fmt.Errorf("encrypt parts for cache: %w", err)
 		}
 		wrappedDEK := []byte(encMeta.UserWrappedDEK)
-		// Frame: 0x01 | wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext
-		cacheData = make([]byte, 1+2+len(wrappedDEK)+len(ciphertext))
-		cacheData[0] = cachePrefixEncrypted
-		binary.BigEndian.PutUint16(cacheData[1:3], uint16(len(wrappedDEK)))
-		copy(cacheData[3:3+len(wrappedDEK)], wrappedDEK)
-		copy(cacheData[3+len(wrappedDEK):], ciphertext)
+		// Frame: header | wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext
+		n := len(header)
+		cacheData = make([]byte, n+2+len(wrappedDEK)+len(ciphertext))
+		copy(cacheData, header)
+		binary.BigEndian.PutUint16(cacheData[n:n+2], uint16(len(wrappedDEK)))
+		copy(cacheData[n+2:n+2+len(wrappedDEK)], wrappedDEK)
+		copy(cacheData[n+2+len(wrappedDEK):], ciphertext)
 	} else {
-		// Frame: 0x00 | json_data
-		cacheData = make([]byte, 1+len(jsonData))
-		cacheData[0] = cachePrefixPlaintext
-		copy(cacheData[1:], jsonData)
+		// Frame: header | json_data
+		cacheData = make([]byte, len(header)+len(jsonData))
+		copy(cacheData, header)
+		copy(cacheData[len(header):], jsonData)
 	}
 
 	// Use project ID + SHA256 as Redis key for project-scoped content-based caching
@@ -647,8 +838,23 @@ func (s *sessionService) cachePartsInRedis(ctx context.Context, projectID string
 	return nil
 }
 
+// cacheCompression returns the algorithm byte and codec cached parts are compressed with,
+// following the blob compression setting; the codec is "" when they are not compressed.
+func (s *sessionService) cacheCompression() (byte, string) {
+	if s.cfg == nil {
+		return 0, ""
+	}
+	for b, algo := range cacheCompressionAlgos {
+		if algo == s.cfg.Blob.Compression {
+			return b, algo
+		}
+	}
+	return 0, ""
+}
+
 // getPartsFromRedis retrieves message parts from Redis cache.
-// When userKEK is provided and the cached data is encrypted, it decrypts before unmarshalling.
+// When userKEK is provided and the cached data is encrypted, it decrypts before unmarshalling;
+// compressed entries are decompressed whatever the current compression setting.
 // Returns (nil, redis.Nil) on cache miss, which is a normal condition.
 func (s *sessionService) getPartsFromRedis(ctx context.Context, projectID string, sha256 string, userKEK []byte) ([]model.Part, error) {
 	if s.redis == nil {
@@ -678,26 +884,29 @@ func (s *sessionService) getPartsFromRedis(ctx context.Context, projectID string
 		// Plaintext: strip prefix byte
 		jsonData = val[1:]
 	case cachePrefixEncrypted:
-		// Encrypted: 0x01 | wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext
-		if userKEK == nil {
-			return nil, fmt.Errorf("encrypted cache entry but no user KEK provided")
+		jsonData, err = decryptCachedParts(val[1:], userKEK)
+		if err != nil {
+			return nil, err
 		}
-		if len(val) < 3 {
-			return nil, fmt.Errorf("malformed encrypted cache entry: too short")
+	case cachePrefixCompressed, cachePrefixCompressedEncrypted:
+		// Compressed: prefix | algo | payload, where the payload is framed as above
+		if len(val) < 2 {
+			return nil, fmt.Errorf("malformed compressed cache entry: too short")
 		}
-		wrappedDEKLen := int(binary.BigEndian.Uint16(val[1:3]))
-		if len(val) < 3+wrappedDEKLen {
-			return nil, fmt.Errorf("malformed encrypted cache entry: wrappedDEK length exceeds data")
+		algo, ok := cacheCompressionAlgos[val[1]]
+		if !ok {
+			return nil, fmt.Errorf("malformed compressed cache entry: unknown algorithm %d", val[1])
 		}
-		wrappedDEK := string(val[3 : 3+wrappedDEKLen])
-		ciphertext := val[3+wrappedDEKLen:]
-		encMeta := &crypto.EncryptedMeta{
-			Algo:           "AES-256-GCM",
-			UserWrappedDEK: wrappedDEK,
+		jsonData = val[2:]
+		if val[0] == cachePrefixCompressedEncrypted {
+			jsonData, err = decryptCachedParts(jsonData, userKEK)
+			if err != nil {
+				return nil, err
+			}
 		}
-		jsonData, err = crypto.DecryptData(userKEK, ciphertext, encMeta)
+		jsonData, err = compress.Decompress(algo, jsonData)
 		if err != nil {
-			return nil, fmt.Errorf("decrypt cached parts: %w", err)
+			return nil, fmt.Errorf("decompress cached parts: %w", err)
 		}
 	default:
 		// Legacy cache entry without prefix byte — treat as raw plaintext JSON
@@ -713,6 +922,32 @@ func (s *sessionService) getPartsFromRedis(ctx context.Context, projectID string
 	return parts, nil
 }
 
+// decryptCachedParts decrypts an encrypted cache payload framed as
+// wrappedDEK_len (2 bytes BE) | wrappedDEK | ciphertext.
+func decryptCachedParts(payload []byte, userKEK []byte) ([]byte, error) {
+	if userKEK == nil {
+		return nil, fmt.Errorf("encrypted cache entry but no user KEK provided")
+	}
+	if len(payload) < 2 {
+		return nil, fmt.Errorf("malformed encrypted cache entry: too short")
+	}
+	wrappedDEKLen := int(binary.BigEndian.Uint16(payload[0:2]))
+	if len(payload) < 2+wrappedDEKLen {
+		return nil, fmt.Errorf("malformed encrypted cache entry: wrappedDEK length exceeds data")
+	}
+	wrappedDEK := string(payload[2 : 2+wrappedDEKLen])
+	ciphertext := payload[2+wrappedDEKLen:]
+	encMeta := &crypto.EncryptedMeta{
+		Algo:           "AES-256-GCM",
+		UserWrappedDEK: wrappedDEK,
+	}
+	jsonData, err := crypto.DecryptData(userKEK, ciphertext, encMeta)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt cached parts: %w", err)
+	}
+	return jsonData, nil
+}
+
 // loadPartsForMessage loads parts from cache/S3. Returns (parts, ok) where
 // ok=false means a load failure (the message should be skipped), and ok=true
 // with an empty slice means the message legitimately has no content parts.
@@ -735,8 +970,10 @@ func (s *sessionService) loadPartsForMessage(ctx context.Context, projectID stri
 	// If cache miss, download from S3
 	if !cacheHit && s.s3 != nil {
 		if err := s.s3.DownloadJSON(ctx, meta.S3Key, &parts, userKEK); err != nil {
-			s.log.Warn("failed to download parts from S3", zap.String("sha256", meta.SHA256), zap.Error(err))
-			return nil, false
+			if !s.readPendingParts(ctx, meta.S3Key, &parts, userKEK) {
+				s.log.Warn("failed to download parts from S3", zap.String("sha256", meta.SHA256), zap.Error(err))
+				return nil, false
+			}
 		}
 		// Cache the parts in Redis after successful S3 download
 		if s.redis != nil {
@@ -750,6 +987,22 @@ func (s *sessionService) loadPartsForMessage(ctx context.Context, projectID stri
 	return parts, true
 }
 
+// readPendingParts reads parts JSON that is not uploaded yet from the outbox.
+func (s *sessionService) readPendingParts(ctx context.Context, s3Key string, parts *[]model.Part, userKEK []byte) bool {
+	if s.outbox == nil {
+		return false
+	}
+	data, err := s.outbox.Read(ctx, s3Key, userKEK)
+	if err != nil {
+		return false
+	}
+	if err := sonic.Unmarshal(data, parts); err != nil {
+		s.log.Warn("failed to decode pending parts", zap.String("s3_key", s3Key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
 // GetAllMessages retrieves all messages for a session and loads their parts
 func (s *sessionService) GetAllMessages(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) ([]model.Message, error) {
 	// Get all messages from repository
@@ -947,3 +1200,176 @@ func (s *sessionService) CopySession(ctx context.Context, in CopySessionInput) (
 		NewSessionID: result.NewSessionID,
 	}, nil
 }
+
+// GetStats returns the aggregated stats of a session together with its task counts by status.
+// Sessions created before stats were tracked are backfilled from their message history on first read.
+func (s *sessionService) GetStats(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) (*SessionStatsOutput, error) {
+	session, err := s.sessionRepo.Get(ctx, &model.Session{ID: sessionID})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.ProjectID != projectID {
+		return nil, ErrSessionNotFound
+	}
+
+	stats, err := s.sessionRepo.GetStats(ctx, sessionID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get session stats: %w", err)
+		}
+		if stats, err = s.backfillStats(ctx, projectID, sessionID, userKEK); err != nil {
+			return nil, err
+		}
+	}
+
+	taskCounts, err := s.sessionRepo.CountTasksByStatus(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return &SessionStatsOutput{
+		SessionStats:  *stats,
+		ToolErrorRate: stats.ToolErrorRate(),
+		TaskCounts:    taskCounts,
+	}, nil
+}
+
+// backfillStats computes stats from every stored message of a session and persists them.
+// Messages whose parts cannot be loaded still count towards message and role totals.
+func (s *sessionService) backfillStats(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, userKEK []byte) (*model.SessionStats, error) {
+	stats, err := s.sessionRepo.BackfillStats(ctx, sessionID, func(msgs []model.Message) (*model.SessionStats, error) {
+		stats := &model.SessionStats{SessionID: sessionID, ProjectID: projectID}
+		for _, m := range msgs {
+			parts, _ := s.loadPartsForMessage(ctx, projectID.String(), m.PartsAssetMeta.Data(), userKEK)
+			m.Parts = parts
+			tokens, err := tokenizer.CountSingleMessageTokens(ctx, m)
+			if err != nil {
+				s.log.Warn("failed to count message tokens for session stats", zap.String("message_id", m.ID.String()), zap.Error(err))
+				tokens = 0
+			}
+			stats.Add(model.NewMessageStats(sessionID, projectID, m.Role, parts, tokens, m.CreatedAt))
+		}
+		return stats, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to backfill session stats: %w", err)
+	}
+	return stats, nil
+}
+
+const (
+	// MaxSessionTags is the maximum number of tags a single request may set on a session
+	MaxSessionTags = 50
+	// MaxSessionTagLength is the maximum length of a single tag in characters
+	MaxSessionTagLength = 64
+	// maxDerivedTitleLength caps titles derived from message text, in characters
+	maxDerivedTitleLength = 80
+)
+
+// NormalizeTags trims, de-duplicates and sorts tags, rejecting empty or oversized ones.
+// It always returns a non-nil slice so that an empty tag set is stored as [].
+func NormalizeTags(tags []string) ([]string, error) {
+	out, err := normalizeLabels(tags, MaxSessionTags, MaxSessionTagLength, "tag")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTags, err)
+	}
+	return out, nil
+}
+
+// normalizeLabels trims, de-duplicates and sorts labels, rejecting more than maxCount of them
+// and empty ones or ones longer than maxLength characters; errors call a label noun. It always
+// returns a non-nil slice.
+func normalizeLabels(labels []string, maxCount int, maxLength int, noun string) ([]string, error) {
+	if len(labels) > maxCount {
+		return nil, fmt.Errorf("at most %d %ss allowed", maxCount, noun)
+	}
+	seen := make(map[string]struct{}, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			return nil, fmt.Errorf("%s must not be empty", noun)
+		}
+		if utf8.RuneCountInString(l) > maxLength {
+			return nil, fmt.Errorf("%s %q exceeds %d characters", noun, l, maxLength)
+		}
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// deriveTitle builds a session title from the first non-blank text part,
+// collapsing whitespace and truncating to maxDerivedTitleLength characters.
+func deriveTitle(parts []model.Part) string {
+	for _, p := range parts {
+		if p.Type != model.PartTypeText {
+			continue
+		}
+		title := strings.Join(strings.Fields(p.Text), " ")
+		if title == "" {
+			continue
+		}
+		if r := []rune(title); len(r) > maxDerivedTitleLength {
+			title = strings.TrimSpace(string(r[:maxDerivedTitleLength-1])) + "…"
+		}
+		return title
+	}
+	return ""
+}
+
+// UpdateInfo overwrites the title, tags and archive state of a session and returns the updated session.
+func (s *sessionService) UpdateInfo(ctx context.Context, projectID uuid.UUID, sessionID uuid.UUID, in UpdateSessionInfoInput) (*model.Session, error) {
+	session, err := s.sessionRepo.Get(ctx, &model.Session{ID: sessionID})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.ProjectID != projectID {
+		return nil, ErrSessionNotFound
+	}
+
+	update := repo.SessionInfoUpdate{Title: in.Title, Archived: in.Archived}
+	if in.Tags != nil {
+		tags, err := NormalizeTags(*in.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.Tags = &tags
+	}
+
+	if err := s.sessionRepo.UpdateInfo(ctx, sessionID, update); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return s.sessionRepo.Get(ctx, &model.Session{ID: sessionID})
+}
+
+// BulkUpdateTags adds and removes tags on sessions of a project.
+// Sessions outside the project are ignored; the number of updated sessions is returned.
+func (s *sessionService) BulkUpdateTags(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, add []string, remove []string) (int64, error) {
+	add, err := NormalizeTags(add)
+	if err != nil {
+		return 0, err
+	}
+	remove, err = NormalizeTags(remove)
+	if err != nil {
+		return 0, err
+	}
+	return s.sessionRepo.UpdateTags(ctx, projectID, sessionIDs, add, remove)
+}
+
+// BulkSetArchived archives or unarchives sessions of a project.
+// Sessions outside the project are ignored; the number of updated sessions is returned.
+func (s *sessionService) BulkSetArchived(ctx context.Context, projectID uuid.UUID, sessionIDs []uuid.UUID, archived bool) (int64, error) {
+	return s.sessionRepo.SetArchived(ctx, projectID, sessionIDs, archived)
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxBulkSessionItems is the maximum number of sessions a single bulk job may target
+	MaxBulkSessionItems = 10000
+
+	// bulkWorkers is the number of sessions processed concurrently by a bulk job
+	bulkWorkers = 4
+	// bulkSaveEvery and bulkSaveInterval bound how often job progress is persisted
+	bulkSaveEvery    = 50
+	bulkSaveInterval = 5 * time.Second
+	// bulkHeartbeatInterval is how often a running job refreshes its heartbeat, so that a
+	// slow item does not make a live job look interrupted
+	bulkHeartbeatInterval = 30 * time.Second
+	// bulkJobStaleAfter marks pending or running jobs without a heartbeat for this long as
+	// interrupted, which happens when the server restarts mid-job
+	bulkJobStaleAfter = 10 * time.Minute
+	// bulkResolvePageSize is the page size used when resolving a filter to session IDs
+	bulkResolvePageSize = 1000
+)
+
+type SessionBulkService interface {
+	Submit(ctx context.Context, in SubmitBulkInput) (*model.SessionBulkJob, error)
+	GetJob(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error)
+}
+
+// BulkSessionFilter selects the sessions of a project a bulk job applies to.
+type BulkSessionFilter struct {
+	User            string
+	FilterByConfigs map[string]interface{}
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	UpdatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+}
+
+func (f BulkSessionFilter) isEmpty() bool {
+	return f.User == "" && len(f.FilterByConfigs) == 0 &&
+		f.CreatedAfter == nil && f.CreatedBefore == nil &&
+		f.UpdatedAfter == nil && f.UpdatedBefore == nil
+}
+
+type SubmitBulkInput struct {
+	ProjectID uuid.UUID
+	Action    string
+
+	// Exactly one of SessionIDs or Filter selects the target sessions
+	SessionIDs []uuid.UUID
+	Filter     *BulkSessionFilter
+
+	Configs         map[string]interface{} // patch_configs: keys to patch, nil values delete
+	Archived        *bool                  // archive: defaults to true
+	LearningSpaceID *uuid.UUID             // learn: target learning space
+
+	UserKEK []byte // kept in memory only, never persisted with the job
+}
+
+type sessionBulkService struct {
+	jobRepo     repo.SessionBulkJobRepo
+	sessionRepo repo.SessionRepo
+	sessionSvc  SessionService
+	lsSvc       LearningSpaceService
+	log         *zap.Logger
+}
+
+func NewSessionBulkService(
+	jobRepo repo.SessionBulkJobRepo,
+	sessionRepo repo.SessionRepo,
+	sessionSvc SessionService,
+	lsSvc LearningSpaceService,
+	log *zap.Logger,
+) SessionBulkService {
+	return &sessionBulkService{
+		jobRepo:     jobRepo,
+		sessionRepo: sessionRepo,
+		sessionSvc:  sessionSvc,
+		lsSvc:       lsSvc,
+		log:         log,
+	}
+}
+
+// Submit validates the request, resolves the target sessions and starts the job in the background.
+// The returned job is in pending state; poll GetJob for progress and per-item results.
+func (s *sessionBulkService) Submit(ctx context.Context, in SubmitBulkInput) (*model.SessionBulkJob, error) {
+	params, err := s.validate(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.resolveTargets(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.BulkItemResult, len(ids))
+	for i, id := range ids {
+		results[i] = model.BulkItemResult{SessionID: id, Status: model.BulkItemStatusPending}
+	}
+
+	job := &model.SessionBulkJob{
+		ProjectID: in.ProjectID,
+		Action:    in.Action,
+		Status:    model.BulkJobStatusPending,
+		Params:    params,
+		Total:     len(ids),
+		Results:   datatypes.NewJSONSlice(results),
+
+		HeartbeatAt: time.Now(),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("create bulk job: %w", err)
+	}
+
+	// Run detached from the request so the job outlives it
+	snapshot := *job
+	snapshot.Results = datatypes.NewJSONSlice(append([]model.BulkItemResult(nil), results...))
+	go s.run(context.Background(), &snapshot, in)
+
+	return job, nil
+}
+
+// GetJob returns a bulk job of the project. Jobs whose worker stopped heartbeating are marked
+// failed, since the worker did not survive a restart. They are not resumed: actions on encrypted
+// projects need the caller's key, which is never persisted with the job.
+func (s *sessionBulkService) GetJob(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error) {
+	job, err := s.jobRepo.Get(ctx, projectID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBulkJobNotFound
+		}
+		return nil, fmt.Errorf("get bulk job: %w", err)
+	}
+
+	active := job.Status == model.BulkJobStatusPending || job.Status == model.BulkJobStatusRunning
+	if !active || time.Since(job.HeartbeatAt) <= bulkJobStaleAfter {
+		return job, nil
+	}
+
+	// The worker may still heartbeat between the read and the update, in which case the
+	// conditional update leaves the job alone; either way return the stored state.
+	marked, err := s.jobRepo.MarkStale(ctx, jobID, time.Now().Add(-bulkJobStaleAfter), "job interrupted before completion")
+	if err != nil {
+		s.log.Warn("failed to mark stale bulk job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		return job, nil
+	}
+	if !marked {
+		s.log.Debug("bulk job heartbeat refreshed before it was marked stale", zap.String("job_id", job.ID.String()))
+	}
+	job, err = s.jobRepo.Get(ctx, projectID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("get bulk job: %w", err)
+	}
+	return job, nil
+}
+
+// validate checks action-specific arguments and returns the params recorded on the job.
+func (s *sessionBulkService) validate(ctx context.Context, in SubmitBulkInput) (datatypes.JSONMap, error) {
+	hasIDs := len(in.SessionIDs) > 0
+	hasFilter := in.Filter != nil
+	if hasIDs == hasFilter {
+		return nil, fmt.Errorf("%w: exactly one of session_ids or filter is required", ErrInvalidBulkRequest)
+	}
+	if hasFilter && in.Filter.isEmpty() {
+		return nil, fmt.Errorf("%w: filter must specify at least one condition", ErrInvalidBulkRequest)
+	}
+	if len(in.SessionIDs) > MaxBulkSessionItems {
+		return nil, fmt.Errorf("%w: at most %d sessions per job", ErrInvalidBulkRequest, MaxBulkSessionItems)
+	}
+
+	params := datatypes.JSONMap{}
+	switch in.Action {
+	case model.BulkActionDelete, model.BulkActionCopy:
+	case model.BulkActionArchive:
+		archived := true
+		if in.Archived != nil {
+			archived = *in.Archived
+		}
+		params["archived"] = archived
+	case model.BulkActionPatchConfigs:
+		if len(in.Configs) == 0 {
+			return nil, fmt.Errorf("%w: configs is required for patch_configs", ErrInvalidBulkRequest)
+		}
+		params["configs"] = in.Configs
+	case model.BulkActionLearn:
+		if in.LearningSpaceID == nil {
+			return nil, fmt.Errorf("%w: learning_space_id is required for learn", ErrInvalidBulkRequest)
+		}
+		if _, err := s.lsSvc.GetByID(ctx, in.ProjectID, *in.LearningSpaceID); err != nil {
+			return nil, fmt.Errorf("%w: learning space not found", ErrInvalidBulkRequest)
+		}
+		params["learning_space_id"] = in.LearningSpaceID.String()
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidBulkRequest, in.Action)
+	}
+	return params, nil
+}
+
+// resolveTargets returns the de-duplicated session IDs the job applies to.
+func (s *sessionBulkService) resolveTargets(ctx context.Context, in SubmitBulkInput) ([]uuid.UUID, error) {
+	if in.Filter == nil {
+		seen := make(map[uuid.UUID]struct{}, len(in.SessionIDs))
+		ids := make([]uuid.UUID, 0, len(in.SessionIDs))
+		for _, id := range in.SessionIDs {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+
+	filter := repo.SessionListFilter{
+		UserIdentifier:  in.Filter.User,
+		FilterByConfigs: in.Filter.FilterByConfigs,
+		CreatedAfter:    in.Filter.CreatedAfter,
+		CreatedBefore:   in.Filter.CreatedBefore,
+		UpdatedAfter:    in.Filter.UpdatedAfter,
+		UpdatedBefore:   in.Filter.UpdatedBefore,
+	}
+
+	var ids []uuid.UUID
+	var afterT time.Time
+	var afterID uuid.UUID
+	for {
+		page, err := s.sessionRepo.ListWithCursor(ctx, in.ProjectID, filter, afterT, afterID, bulkResolvePageSize, false)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions: %w", err)
+		}
+		for _, ss := range page {
+			ids = append(ids, ss.ID)
+		}
+		if len(ids) > MaxBulkSessionItems {
+			return nil, fmt.Errorf("%w: filter matches more than %d sessions", ErrInvalidBulkRequest, MaxBulkSessionItems)
+		}
+		if len(page) < bulkResolvePageSize {
+			return ids, nil
+		}
+		last := page[len(page)-1]
+		afterT, afterID = filter.SortValue(last), last.ID
+	}
+}
+
+// run processes every target session with a small worker pool, persisting progress periodically.
+// It stops handing out sessions once the stored job has reached a final status, e.g. because it
+// was marked stale while this worker was unable to heartbeat.
+func (s *sessionBulkService) run(ctx context.Context, job *model.SessionBulkJob, in SubmitBulkInput) {
+	var mu sync.Mutex
+	done := 0
+	lastSave := time.Now()
+
+	// stopCtx only gates scheduling; sessions already being processed run to completion
+	stopCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	save := func() {
+		ok, err := s.jobRepo.SaveProgress(ctx, job)
+		if err != nil {
+			s.log.Error("failed to save bulk job progress", zap.String("job_id", job.ID.String()), zap.Error(err))
+		} else if !ok {
+			s.log.Warn("bulk job was finalized elsewhere, stopping", zap.String("job_id", job.ID.String()))
+			stop()
+		}
+		lastSave = time.Now()
+	}
+
+	mu.Lock()
+	job.Status = model.BulkJobStatusRunning
+	save()
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(bulkHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCtx.Done():
+				return
+			case <-ticker.C:
+				ok, err := s.jobRepo.Heartbeat(ctx, job.ID)
+				if err != nil {
+					s.log.Warn("failed to refresh bulk job heartbeat", zap.String("job_id", job.ID.String()), zap.Error(err))
+				} else if !ok {
+					s.log.Warn("bulk job was finalized elsewhere, stopping", zap.String("job_id", job.ID.String()))
+					stop()
+					return
+				}
+			}
+		}
+	}()
+
+	results := job.Results
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < bulkWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				res := s.apply(ctx, in, results[i].SessionID)
+
+				mu.Lock()
+				results[i] = res
+				if res.Status == model.BulkItemStatusSucceeded {
+					job.Succeeded++
+				} else {
+					job.Failed++
+				}
+				done++
+				if done%bulkSaveEvery == 0 || time.Since(lastSave) > bulkSaveInterval {
+					save()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+schedule:
+	for i := range results {
+		if stopCtx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-stopCtx.Done():
+			break schedule
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if stopCtx.Err() != nil {
+		return
+	}
+	now := time.Now()
+	job.Status = model.BulkJobStatusCompleted
+	job.FinishedAt = &now
+	save()
+}
+
+// apply runs the job action on a single session and reports its outcome.
+func (s *sessionBulkService) apply(ctx context.Context, in SubmitBulkInput, sessionID uuid.UUID) (res model.BulkItemResult) {
+	res = model.BulkItemResult{SessionID: sessionID, Status: model.BulkItemStatusSucceeded}
+	defer func() {
+		if r := recover(); r != nil {
+			res.Status = model.BulkItemStatusFailed
+			res.Error = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	var err error
+	switch in.Action {
+	case model.BulkActionDelete:
+		err = s.sessionSvc.Delete(ctx, in.ProjectID, sessionID, in.UserKEK)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrSessionNotFound
+		}
+	case model.BulkActionArchive:
+		archived := in.Archived == nil || *in.Archived
+		var n int64
+		n, err = s.sessionRepo.SetArchived(ctx, in.ProjectID, []uuid.UUID{sessionID}, archived)
+		if err == nil && n == 0 {
+			err = ErrSessionNotFound
+		}
+	case model.BulkActionPatchConfigs:
+		_, err = s.sessionSvc.PatchConfigs(ctx, in.ProjectID, sessionID, in.Configs)
+	case model.BulkActionCopy:
+		var out *CopySessionOutput
+		out, err = s.sessionSvc.CopySession(ctx, CopySessionInput{ProjectID: in.ProjectID, SessionID: sessionID, UserKEK: in.UserKEK})
+		if err == nil {
+			res.NewSessionID = &out.NewSessionID
+		}
+	case model.BulkActionLearn:
+		_, err = s.lsSvc.Learn(ctx, LearnInput{ProjectID: in.ProjectID, LearningSpaceID: *in.LearningSpaceID, SessionID: sessionID})
+	}
+
+	if err != nil {
+		res.Status = model.BulkItemStatusFailed
+		res.Error = err.Error()
+	}
+	return res
+}
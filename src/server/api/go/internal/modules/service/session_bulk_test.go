@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// MockSessionBulkJobRepo is a mock implementation of SessionBulkJobRepo
+type MockSessionBulkJobRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionBulkJobRepo) Create(ctx context.Context, job *model.SessionBulkJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockSessionBulkJobRepo) Get(ctx context.Context, projectID uuid.UUID, jobID uuid.UUID) (*model.SessionBulkJob, error) {
+	args := m.Called(ctx, projectID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionBulkJob), args.Error(1)
+}
+
+func (m *MockSessionBulkJobRepo) SaveProgress(ctx context.Context, job *model.SessionBulkJob) (bool, error) {
+	args := m.Called(ctx, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionBulkJobRepo) Heartbeat(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionBulkJobRepo) MarkStale(ctx context.Context, jobID uuid.UUID, heartbeatBefore time.Time, reason string) (bool, error) {
+	args := m.Called(ctx, jobID, heartbeatBefore, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+// waitForBulkJob captures the job as persisted by the final SaveProgress of a background run.
+func waitForBulkJob(jobRepo *MockSessionBulkJobRepo) <-chan model.SessionBulkJob {
+	done := make(chan model.SessionBulkJob, 1)
+	jobRepo.On("Heartbeat", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	jobRepo.On("SaveProgress", mock.Anything, mock.Anything).Return(true, nil).Run(func(args mock.Arguments) {
+		job := args.Get(1).(*model.SessionBulkJob)
+		if job.Status == model.BulkJobStatusCompleted {
+			snapshot := *job
+			snapshot.Results = append(snapshot.Results[:0:0], job.Results...)
+			done <- snapshot
+		}
+	})
+	return done
+}
+
+func TestSessionBulkService_Submit_Validation(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name string
+		in   SubmitBulkInput
+	}{
+		{
+			name: "neither ids nor filter",
+			in:   SubmitBulkInput{ProjectID: projectID, Action: model.BulkActionDelete},
+		},
+		{
+			name: "both ids and filter",
+			in: SubmitBulkInput{
+				ProjectID:  projectID,
+				Action:     model.BulkActionDelete,
+				SessionIDs: []uuid.UUID{uuid.New()},
+				Filter:     &BulkSessionFilter{User: "alice"},
+			},
+		},
+		{
+			name: "empty filter",
+			in:   SubmitBulkInput{ProjectID: projectID, Action: model.BulkActionDelete, Filter: &BulkSessionFilter{}},
+		},
+		{
+			name: "patch_configs without configs",
+			in:   SubmitBulkInput{ProjectID: projectID, Action: model.BulkActionPatchConfigs, SessionIDs: []uuid.UUID{uuid.New()}},
+		},
+		{
+			name: "learn without learning space",
+			in:   SubmitBulkInput{ProjectID: projectID, Action: model.BulkActionLearn, SessionIDs: []uuid.UUID{uuid.New()}},
+		},
+		{
+			name: "unknown action",
+			in:   SubmitBulkInput{ProjectID: projectID, Action: "explode", SessionIDs: []uuid.UUID{uuid.New()}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobRepo := &MockSessionBulkJobRepo{}
+			svc := NewSessionBulkService(jobRepo, &MockSessionRepo{}, nil, nil, zap.NewNop())
+
+			_, err := svc.Submit(ctx, tt.in)
+
+			assert.ErrorIs(t, err, ErrInvalidBulkRequest)
+			jobRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSessionBulkService_Submit_ArchiveByIDs(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	okID := uuid.New()
+	missingID := uuid.New()
+
+	jobRepo := &MockSessionBulkJobRepo{}
+	jobRepo.On("Create", ctx, mock.MatchedBy(func(j *model.SessionBulkJob) bool {
+		return j.Action == model.BulkActionArchive && j.Total == 2 && j.Params["archived"] == false
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.SessionBulkJob).ID = uuid.New()
+	})
+	done := waitForBulkJob(jobRepo)
+
+	sessionRepo := &MockSessionRepo{}
+	sessionRepo.On("SetArchived", mock.Anything, projectID, []uuid.UUID{okID}, false).Return(int64(1), nil)
+	sessionRepo.On("SetArchived", mock.Anything, projectID, []uuid.UUID{missingID}, false).Return(int64(0), nil)
+
+	svc := NewSessionBulkService(jobRepo, sessionRepo, nil, nil, zap.NewNop())
+	archived := false
+	job, err := svc.Submit(ctx, SubmitBulkInput{
+		ProjectID:  projectID,
+		Action:     model.BulkActionArchive,
+		SessionIDs: []uuid.UUID{okID, missingID, okID},
+		Archived:   &archived,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.BulkJobStatusPending, job.Status)
+
+	select {
+	case final := <-done:
+		assert.Equal(t, 2, final.Total)
+		assert.Equal(t, 1, final.Succeeded)
+		assert.Equal(t, 1, final.Failed)
+		require.NotNil(t, final.FinishedAt)
+		require.Len(t, final.Results, 2)
+		assert.Equal(t, model.BulkItemResult{SessionID: okID, Status: model.BulkItemStatusSucceeded}, final.Results[0])
+		assert.Equal(t, missingID, final.Results[1].SessionID)
+		assert.Equal(t, model.BulkItemStatusFailed, final.Results[1].Status)
+		assert.Equal(t, ErrSessionNotFound.Error(), final.Results[1].Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("bulk job did not complete")
+	}
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestSessionBulkService_Submit_ResolvesFilter(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	jobRepo := &MockSessionBulkJobRepo{}
+	jobRepo.On("Create", ctx, mock.Anything).Return(nil)
+	done := waitForBulkJob(jobRepo)
+
+	sessionRepo := &MockSessionRepo{}
+	sessionRepo.On("ListWithCursor", ctx, projectID, repo.SessionListFilter{UserIdentifier: "test@acontext.io"}, time.Time{}, uuid.Nil, bulkResolvePageSize, false).
+		Return([]model.Session{{ID: ids[0]}, {ID: ids[1]}}, nil)
+	sessionRepo.On("SetArchived", mock.Anything, projectID, mock.Anything, true).Return(int64(1), nil)
+
+	svc := NewSessionBulkService(jobRepo, sessionRepo, nil, nil, zap.NewNop())
+	job, err := svc.Submit(ctx, SubmitBulkInput{
+		ProjectID: projectID,
+		Action:    model.BulkActionArchive,
+		Filter:    &BulkSessionFilter{User: "test@acontext.io"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+
+	select {
+	case final := <-done:
+		assert.Equal(t, 2, final.Succeeded)
+		assert.Equal(t, ids[0], final.Results[0].SessionID)
+		assert.Equal(t, ids[1], final.Results[1].SessionID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("bulk job did not complete")
+	}
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestSessionBulkService_GetJob(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	jobID := uuid.New()
+
+	t.Run("not found", func(t *testing.T) {
+		jobRepo := &MockSessionBulkJobRepo{}
+		jobRepo.On("Get", ctx, projectID, jobID).Return(nil, gorm.ErrRecordNotFound)
+
+		svc := NewSessionBulkService(jobRepo, nil, nil, nil, zap.NewNop())
+		_, err := svc.GetJob(ctx, projectID, jobID)
+
+		assert.ErrorIs(t, err, ErrBulkJobNotFound)
+	})
+
+	t.Run("stale running job is marked failed", func(t *testing.T) {
+		jobRepo := &MockSessionBulkJobRepo{}
+		finishedAt := time.Now()
+		jobRepo.On("Get", ctx, projectID, jobID).Return(&model.SessionBulkJob{
+			ID:          jobID,
+			Status:      model.BulkJobStatusRunning,
+			HeartbeatAt: time.Now().Add(-2 * bulkJobStaleAfter),
+		}, nil).Once()
+		jobRepo.On("MarkStale", ctx, jobID, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= bulkJobStaleAfter
+		}), mock.Anything).Return(true, nil)
+		jobRepo.On("Get", ctx, projectID, jobID).Return(&model.SessionBulkJob{
+			ID:         jobID,
+			Status:     model.BulkJobStatusFailed,
+			Error:      "job interrupted before completion",
+			FinishedAt: &finishedAt,
+		}, nil).Once()
+
+		svc := NewSessionBulkService(jobRepo, nil, nil, nil, zap.NewNop())
+		job, err := svc.GetJob(ctx, projectID, jobID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.BulkJobStatusFailed, job.Status)
+		assert.NotEmpty(t, job.Error)
+		require.NotNil(t, job.FinishedAt)
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("job that heartbeats before being marked is returned as stored", func(t *testing.T) {
+		jobRepo := &MockSessionBulkJobRepo{}
+		jobRepo.On("Get", ctx, projectID, jobID).Return(&model.SessionBulkJob{
+			ID:          jobID,
+			Status:      model.BulkJobStatusRunning,
+			HeartbeatAt: time.Now().Add(-2 * bulkJobStaleAfter),
+		}, nil).Once()
+		jobRepo.On("MarkStale", ctx, jobID, mock.Anything, mock.Anything).Return(false, nil)
+		jobRepo.On("Get", ctx, projectID, jobID).Return(&model.SessionBulkJob{
+			ID:          jobID,
+			Status:      model.BulkJobStatusRunning,
+			Succeeded:   3,
+			HeartbeatAt: time.Now(),
+		}, nil).Once()
+
+		svc := NewSessionBulkService(jobRepo, nil, nil, nil, zap.NewNop())
+		job, err := svc.GetJob(ctx, projectID, jobID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.BulkJobStatusRunning, job.Status)
+		assert.Equal(t, 3, job.Succeeded)
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("recent running job is returned as is", func(t *testing.T) {
+		jobRepo := &MockSessionBulkJobRepo{}
+		jobRepo.On("Get", ctx, projectID, jobID).Return(&model.SessionBulkJob{
+			ID:          jobID,
+			Status:      model.BulkJobStatusRunning,
+			UpdatedAt:   time.Now().Add(-2 * bulkJobStaleAfter),
+			HeartbeatAt: time.Now(),
+		}, nil)
+
+		svc := NewSessionBulkService(jobRepo, nil, nil, nil, zap.NewNop())
+		job, err := svc.GetJob(ctx, projectID, jobID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.BulkJobStatusRunning, job.Status)
+		jobRepo.AssertNotCalled(t, "MarkStale", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionBulkService_Run_StopsWhenJobFinalizedElsewhere(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	jobID := uuid.New()
+
+	jobRepo := &MockSessionBulkJobRepo{}
+	jobRepo.On("Heartbeat", mock.Anything, jobID).Return(false, nil).Maybe()
+	// The job was marked stale before the worker started, so nothing may be processed
+	jobRepo.On("SaveProgress", mock.Anything, mock.Anything).Return(false, nil)
+	sessionRepo := &MockSessionRepo{}
+
+	svc := NewSessionBulkService(jobRepo, sessionRepo, nil, nil, zap.NewNop()).(*sessionBulkService)
+	job := &model.SessionBulkJob{
+		ID:        jobID,
+		ProjectID: projectID,
+		Action:    model.BulkActionArchive,
+		Status:    model.BulkJobStatusPending,
+		Total:     1,
+		Results:   datatypes.NewJSONSlice([]model.BulkItemResult{{SessionID: uuid.New(), Status: model.BulkItemStatusPending}}),
+	}
+	svc.run(ctx, job, SubmitBulkInput{ProjectID: projectID, Action: model.BulkActionArchive})
+
+	jobRepo.AssertNumberOfCalls(t, "SaveProgress", 1)
+	sessionRepo.AssertNotCalled(t, "SetArchived", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NotEqual(t, model.BulkJobStatusCompleted, job.Status)
+}
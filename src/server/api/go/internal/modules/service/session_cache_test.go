@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/bytedance/sonic"
+	"github.com/memodb-io/Acontext/internal/config"
 	"github.com/memodb-io/Acontext/internal/infra/crypto"
 	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/compress"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -158,6 +161,71 @@ func TestGetPartsFromRedis_Encrypted_NilKEK_Fails(t *testing.T) {
 	assert.Contains(t, err.Error(), "no user KEK provided")
 }
 
+// ---------------------------------------------------------------------------
+// Compressed cache round-trip
+// ---------------------------------------------------------------------------
+
+func TestCachePartsInRedis_Compressed_RoundTrip(t *testing.T) {
+	parts := []model.Part{{Type: "text", Text: strings.Repeat("hello world ", 200)}}
+	kek := testUserKEK(t)
+
+	for _, tc := range []struct {
+		name       string
+		algo       string
+		userKEK    []byte
+		wantPrefix byte
+	}{
+		{name: "gzip plaintext", algo: compress.Gzip, wantPrefix: cachePrefixCompressed},
+		{name: "zstd plaintext", algo: compress.Zstd, wantPrefix: cachePrefixCompressed},
+		{name: "gzip encrypted", algo: compress.Gzip, userKEK: kek, wantPrefix: cachePrefixCompressedEncrypted},
+		{name: "zstd encrypted", algo: compress.Zstd, userKEK: kek, wantPrefix: cachePrefixCompressedEncrypted},
+		{name: "disabled", algo: compress.None, wantPrefix: cachePrefixPlaintext},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, mr := newTestSessionServiceWithRedis(t)
+			svc.cfg = &config.Config{}
+			svc.cfg.Blob.Compression = tc.algo
+			ctx := context.Background()
+			sha := "abc123compressed"
+
+			err := svc.cachePartsInRedis(ctx, "proj1", sha, parts, tc.userKEK)
+			require.NoError(t, err)
+
+			raw, err := mr.Get(redisKeyPrefixParts + "proj1:" + sha)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPrefix, raw[0])
+			if tc.algo != compress.None {
+				assert.Less(t, len(raw), len(parts[0].Text))
+			}
+
+			got, err := svc.getPartsFromRedis(ctx, "proj1", sha, tc.userKEK)
+			require.NoError(t, err)
+			assert.Equal(t, parts, got)
+
+			// Entries stay readable after the setting changes
+			svc.cfg.Blob.Compression = compress.None
+			got, err = svc.getPartsFromRedis(ctx, "proj1", sha, tc.userKEK)
+			require.NoError(t, err)
+			assert.Equal(t, parts, got)
+		})
+	}
+}
+
+func TestCachePartsInRedis_Compressed_SmallStaysPlain(t *testing.T) {
+	svc, mr := newTestSessionServiceWithRedis(t)
+	svc.cfg = &config.Config{}
+	svc.cfg.Blob.Compression = compress.Gzip
+	ctx := context.Background()
+	sha := "abc123small"
+
+	err := svc.cachePartsInRedis(ctx, "proj1", sha, testParts(), nil)
+	require.NoError(t, err)
+
+	raw, err := mr.Get(redisKeyPrefixParts + "proj1:" + sha)
+	require.NoError(t, err)
+	assert.Equal(t, cachePrefixPlaintext, raw[0], "parts under the threshold are not compressed")
+}
+
 // ---------------------------------------------------------------------------
 // Legacy / backward compatibility
 // ---------------------------------------------------------------------------
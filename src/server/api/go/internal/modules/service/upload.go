@@ -0,0 +1,515 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/memodb-io/Acontext/internal/pkg/utils/mime"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// minUploadPartSize is the smallest part a multipart upload accepts, except for its last
+	minUploadPartSize = 5 << 20
+	// mimeSniffSize is how much of the first part is used to detect the file's MIME type
+	mimeSniffSize = 3072
+	// uploadSweepGrace is how long after expiring an upload is swept, so one resolved just
+	// before it expired has been attached by then
+	uploadSweepGrace = time.Hour
+	// uploadSweepBatchSize is how many expired uploads a sweep loads at a time
+	uploadSweepBatchSize = 100
+	// directUploadSweepGrace is how long after its URL can last an uncommitted presigned
+	// upload is swept, so a commit in progress when the URL expires still finds it
+	directUploadSweepGrace = time.Hour
+	// maxDeleteBatchSize is the most objects one S3 DeleteObjects request removes
+	maxDeleteBatchSize = 1000
+)
+
+// UploadService runs resumable uploads: a file is sent over any number of requests, each
+// appending at the offset the previous ones reached, and once complete it can be attached to
+// an artifact or a message part by the upload's ID.
+type UploadService interface {
+	Create(ctx context.Context, in CreateUploadInput) (*model.Upload, error)
+	Get(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) (*model.Upload, error)
+	Append(ctx context.Context, in AppendUploadInput) (*model.Upload, error)
+	Complete(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID, userKEK []byte) (*model.Upload, error)
+	Abort(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) error
+	// Resolve returns a completed upload for target, to attach its file.
+	Resolve(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID, target string) (*model.Upload, error)
+	// Sweep discards expired uploads: the parts of unfinished ones, the files of completed
+	// ones that were never attached, and their records, along with presigned artifact
+	// uploads that were never committed. It returns how many uploads it discarded.
+	Sweep(ctx context.Context) (int, error)
+	// Start runs Sweep periodically in the background.
+	Start()
+	// Stop ends background sweeps, interrupting a running one.
+	Stop()
+}
+
+type CreateUploadInput struct {
+	ProjectID uuid.UUID
+	Target    string // model.UploadTargetDisk or model.UploadTargetMessage
+	Filename  string
+	Size      int64
+	UserKEK   []byte // optional: parts are encrypted as they arrive
+}
+
+type AppendUploadInput struct {
+	ProjectID uuid.UUID
+	UploadID  uuid.UUID
+	Offset    int64 // where Body starts; must be the upload's current offset
+	Body      io.Reader
+	UserKEK   []byte
+}
+
+type uploadService struct {
+	r            repo.UploadRepo
+	assetRefRepo repo.AssetReferenceRepo
+	s3           blob.BlobStore
+	cfg          *config.Config
+	log          *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewUploadService(r repo.UploadRepo, assetRefRepo repo.AssetReferenceRepo, s3 blob.BlobStore, cfg *config.Config, log *zap.Logger) UploadService {
+	return &uploadService{r: r, assetRefRepo: assetRefRepo, s3: s3, cfg: cfg, log: log.Named("upload-sweep")}
+}
+
+// partSize is the configured part size, raised to the multipart minimum and rounded down to
+// whole encryption chunks so every part but the last encrypts on its own.
+func (s *uploadService) partSize() int64 {
+	size := s.cfg.Artifact.UploadPartSizeBytes
+	if size < minUploadPartSize {
+		size = minUploadPartSize
+	}
+	return size - size%encryptionpkg.DefaultChunkSize
+}
+
+func (s *uploadService) expiry() time.Duration {
+	if s.cfg.Artifact.UploadExpirySeconds <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(s.cfg.Artifact.UploadExpirySeconds) * time.Second
+}
+
+// uploadKeyPrefix is where the completed file of an upload for target is stored.
+func uploadKeyPrefix(projectID uuid.UUID, target string) string {
+	if target == model.UploadTargetMessage {
+		return "assets/" + projectID.String()
+	}
+	return "disks/" + projectID.String()
+}
+
+func (s *uploadService) Create(ctx context.Context, in CreateUploadInput) (*model.Upload, error) {
+	if in.Target != model.UploadTargetDisk && in.Target != model.UploadTargetMessage {
+		return nil, fmt.Errorf("%w: target must be %q or %q", ErrInvalidUpload, model.UploadTargetDisk, model.UploadTargetMessage)
+	}
+	if in.Filename == "" || strings.ContainsAny(in.Filename, `/\`) {
+		return nil, fmt.Errorf("%w: filename must be a non-empty name without slashes", ErrInvalidUpload)
+	}
+	if in.Size < 0 {
+		return nil, fmt.Errorf("%w: size must not be negative", ErrInvalidUpload)
+	}
+	if maxSize := s.cfg.Artifact.MaxUploadSizeBytes; maxSize > 0 && in.Size > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, the maximum is %d", ErrUploadTooLarge, in.Size, maxSize)
+	}
+
+	hashState, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("marshal hash state: %w", err)
+	}
+
+	id := uuid.New()
+	u := &model.Upload{
+		ID:         id,
+		ProjectID:  in.ProjectID,
+		Target:     in.Target,
+		Filename:   in.Filename,
+		Status:     model.UploadStatusUploading,
+		Size:       in.Size,
+		PartSize:   s.partSize(),
+		StagingKey: fmt.Sprintf("uploads/%s/%s", in.ProjectID, id),
+		HashState:  hashState,
+		ExpiresAt:  time.Now().Add(s.expiry()),
+	}
+	if in.UserKEK != nil {
+		encMeta, err := encryptionpkg.NewChunkedMeta(in.UserKEK)
+		if err != nil {
+			return nil, fmt.Errorf("create encryption metadata: %w", err)
+		}
+		u.EncMeta = datatypes.NewJSONType(encMeta.MetadataToMap())
+	}
+
+	if err := s.r.Create(ctx, u); err != nil {
+		return nil, fmt.Errorf("create upload: %w", err)
+	}
+	return u, nil
+}
+
+func (s *uploadService) Get(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) (*model.Upload, error) {
+	u, err := s.r.Get(ctx, projectID, uploadID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// Append stores the whole parts of in.Body and returns the upload with its new offset. Bytes
+// after the last whole part are discarded unless they end the file, so a request cut short,
+// or one that does not end on a part boundary, is resumed from the returned offset.
+func (s *uploadService) Append(ctx context.Context, in AppendUploadInput) (*model.Upload, error) {
+	u, err := s.Get(ctx, in.ProjectID, in.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != model.UploadStatusUploading {
+		return nil, fmt.Errorf("%w: upload is already completed", ErrInvalidUpload)
+	}
+	if in.Offset != u.Offset {
+		return nil, fmt.Errorf("%w: upload is at offset %d", ErrUploadOffsetMismatch, u.Offset)
+	}
+	encMeta := encryptionpkg.MetadataFromMap(u.EncMeta.Data())
+	if encMeta != nil && in.UserKEK == nil {
+		return nil, fmt.Errorf("%w: upload is encrypted but no user KEK was provided", ErrInvalidUpload)
+	}
+
+	h := sha256.New()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.HashState); err != nil {
+		return nil, fmt.Errorf("restore hash state: %w", err)
+	}
+
+	buf := make([]byte, u.PartSize)
+	for u.Offset < u.Size {
+		n := min(u.PartSize, u.Size-u.Offset)
+		if _, err := io.ReadFull(in.Body, buf[:n]); err != nil {
+			// The rest of the body is an incomplete part, which the client sends again
+			break
+		}
+		final := u.Offset+n == u.Size
+		if final {
+			var extra [1]byte
+			if k, _ := io.ReadFull(in.Body, extra[:]); k > 0 {
+				return nil, fmt.Errorf("%w: body extends past the declared size of %d bytes", ErrInvalidUpload, u.Size)
+			}
+		}
+		if err := s.storePart(ctx, u, buf[:n], final, h, encMeta, in.UserKEK); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// storePart uploads data as the upload's next part and saves its progress.
+func (s *uploadService) storePart(ctx context.Context, u *model.Upload, data []byte, final bool, h io.Writer, encMeta *encryptionpkg.EncryptedMeta, userKEK []byte) error {
+	createdUpload := false
+	if u.Offset == 0 {
+		u.MIME = mime.DetectMimeType(data[:min(len(data), mimeSniffSize)], u.Filename)
+		metadata := map[string]string{"name": u.Filename}
+		if encMeta != nil {
+			for k, v := range encMeta.MetadataToMap() {
+				metadata[k] = v
+			}
+		}
+		storeUploadID, err := s.s3.CreateMultipartUpload(ctx, u.StagingKey, u.MIME, metadata)
+		if err != nil {
+			return err
+		}
+		u.StoreUploadID = storeUploadID
+		createdUpload = true
+	}
+
+	body := data
+	if encMeta != nil {
+		var sealed bytes.Buffer
+		w, err := encryptionpkg.NewChunkWriter(userKEK, encMeta, &sealed, u.Offset/int64(encMeta.ChunkSize), final)
+		if err != nil {
+			return fmt.Errorf("encrypt part: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("encrypt part: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("encrypt part: %w", err)
+		}
+		body = sealed.Bytes()
+	}
+
+	partNumber := int32(u.Offset/u.PartSize) + 1
+	etag, err := s.s3.UploadPart(ctx, u.StagingKey, u.StoreUploadID, partNumber, body)
+	if err != nil {
+		return err
+	}
+
+	h.Write(data)
+	hashState, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal hash state: %w", err)
+	}
+
+	prevOffset := u.Offset
+	u.Offset += int64(len(data))
+	u.Parts = append(u.Parts, model.UploadPart{PartNumber: partNumber, ETag: etag})
+	u.HashState = hashState
+	if err := s.r.Advance(ctx, u, prevOffset); err != nil {
+		if errors.Is(err, repo.ErrUploadConflict) {
+			// A concurrent append got there first; the multipart upload started here is unused
+			if createdUpload {
+				_ = s.s3.AbortMultipartUpload(ctx, u.StagingKey, u.StoreUploadID)
+			}
+			return fmt.Errorf("%w: upload was appended to concurrently", ErrUploadOffsetMismatch)
+		}
+		return fmt.Errorf("save upload progress: %w", err)
+	}
+	return nil
+}
+
+// Complete assembles the uploaded file and stores it at its content-addressed key. Completing
+// an upload that is already completed returns it unchanged.
+func (s *uploadService) Complete(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID, userKEK []byte) (*model.Upload, error) {
+	u, err := s.Get(ctx, projectID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status == model.UploadStatusCompleted {
+		return u, nil
+	}
+	if u.Offset != u.Size {
+		return nil, fmt.Errorf("%w: %d of %d bytes received", ErrInvalidUpload, u.Offset, u.Size)
+	}
+
+	keyPrefix := uploadKeyPrefix(projectID, u.Target)
+	var asset *model.Asset
+	if u.Size == 0 {
+		// Nothing was appended, so there is no multipart upload to assemble
+		if encryptionpkg.MetadataFromMap(u.EncMeta.Data()) != nil && userKEK == nil {
+			return nil, fmt.Errorf("%w: upload is encrypted but no user KEK was provided", ErrInvalidUpload)
+		}
+		asset, err = s.s3.UploadBytes(ctx, keyPrefix, u.Filename, nil, userKEK)
+		if err != nil {
+			return nil, fmt.Errorf("store empty upload: %w", err)
+		}
+	} else {
+		// A retry after a failed promotion finds the object already assembled
+		if _, err := s.s3.Head(ctx, u.StagingKey); errors.Is(err, blob.ErrObjectNotFound) {
+			if err := s.s3.CompleteMultipartUpload(ctx, u.StagingKey, u.StoreUploadID, u.Parts); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		sumHex, err := hashFromState(u.HashState)
+		if err != nil {
+			return nil, err
+		}
+		asset, err = s.s3.PromoteUpload(ctx, u.StagingKey, keyPrefix, sumHex, strings.ToLower(filepath.Ext(u.Filename)), u.Size)
+		if err != nil {
+			return nil, fmt.Errorf("store upload: %w", err)
+		}
+	}
+
+	u.Status = model.UploadStatusCompleted
+	u.SHA256 = asset.SHA256
+	u.MIME = asset.MIME
+	u.AssetMeta = datatypes.NewJSONType(*asset)
+	u.HashState = nil
+	u.ExpiresAt = time.Now().Add(s.expiry())
+	if err := s.r.Complete(ctx, u); err != nil {
+		if errors.Is(err, repo.ErrUploadConflict) {
+			// Completed concurrently
+			return s.Get(ctx, projectID, uploadID)
+		}
+		return nil, fmt.Errorf("save completed upload: %w", err)
+	}
+	return u, nil
+}
+
+func hashFromState(state []byte) (string, error) {
+	h := sha256.New()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return "", fmt.Errorf("restore hash state: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Abort discards an upload along with the parts it stored. The file of a completed upload is
+// kept, as it may already be attached.
+func (s *uploadService) Abort(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) error {
+	u, err := s.Get(ctx, projectID, uploadID)
+	if err != nil {
+		return err
+	}
+	if u.Status == model.UploadStatusUploading && u.StoreUploadID != "" {
+		if err := s.s3.AbortMultipartUpload(ctx, u.StagingKey, u.StoreUploadID); err != nil {
+			return err
+		}
+	}
+	return s.r.Delete(ctx, projectID, uploadID)
+}
+
+func (s *uploadService) Resolve(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID, target string) (*model.Upload, error) {
+	u, err := s.Get(ctx, projectID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != model.UploadStatusCompleted {
+		return nil, fmt.Errorf("%w: upload %s", ErrUploadIncomplete, uploadID)
+	}
+	if u.Target != target {
+		return nil, fmt.Errorf("%w: upload %s is for a %s, not a %s", ErrInvalidUpload, uploadID, u.Target, target)
+	}
+	return u, nil
+}
+
+// Sweep pages through uploads expired for longer than uploadSweepGrace, oldest first. An
+// upload completed or resumed meanwhile no longer counts as expired and keeps its record.
+func (s *uploadService) Sweep(ctx context.Context) (int, error) {
+	swept, err := s.sweepDirectUploads(ctx)
+	if err != nil {
+		return swept, err
+	}
+
+	before := time.Now().Add(-uploadSweepGrace)
+	for {
+		uploads, err := s.r.ListExpired(ctx, before, uploadSweepBatchSize)
+		if err != nil {
+			return swept, fmt.Errorf("list expired uploads: %w", err)
+		}
+		for _, u := range uploads {
+			if err := s.discard(ctx, u); err != nil {
+				return swept, fmt.Errorf("discard upload %s: %w", u.ID, err)
+			}
+			deleted, err := s.r.DeleteExpired(ctx, u.ID, before)
+			if err != nil {
+				return swept, fmt.Errorf("delete upload %s: %w", u.ID, err)
+			}
+			if deleted {
+				swept++
+			}
+		}
+		if len(uploads) < uploadSweepBatchSize {
+			return swept, nil
+		}
+	}
+}
+
+// sweepDirectUploads deletes presigned uploads written longer ago than any upload URL lasts,
+// which can no longer be committed.
+func (s *uploadService) sweepDirectUploads(ctx context.Context) (int, error) {
+	before := time.Now().Add(-MaxDirectUploadExpiry - directUploadSweepGrace)
+	var keys []string
+	err := s.s3.ListObjects(ctx, "uploads/", func(obj blob.ObjectInfo) error {
+		if isDirectUploadKey(obj.Key) && obj.LastModified.Before(before) {
+			keys = append(keys, obj.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list direct uploads: %w", err)
+	}
+	for start := 0; start < len(keys); start += maxDeleteBatchSize {
+		end := min(start+maxDeleteBatchSize, len(keys))
+		if err := s.s3.DeleteObjects(ctx, keys[start:end]); err != nil {
+			return start, fmt.Errorf("delete direct uploads: %w", err)
+		}
+	}
+	return len(keys), nil
+}
+
+// isDirectUploadKey reports whether key was issued by PresignUpload.
+func isDirectUploadKey(key string) bool {
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		return false
+	}
+	projectID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return false
+	}
+	_, err = uuid.Parse(parts[3])
+	return err == nil && key == directUploadPrefix(projectID)+parts[3]
+}
+
+// discard deletes what an expired upload stored. Every step tolerates having already run,
+// so a sweep that fails halfway is finished by the next one.
+func (s *uploadService) discard(ctx context.Context, u *model.Upload) error {
+	if u.Status == model.UploadStatusUploading {
+		if u.StoreUploadID != "" {
+			if err := s.s3.AbortMultipartUpload(ctx, u.StagingKey, u.StoreUploadID); err != nil {
+				return err
+			}
+		}
+		// Completing may have assembled the object before failing to store it
+		return s.s3.DeleteObject(ctx, u.StagingKey)
+	}
+
+	// A completed upload's file is kept if anything attached it, or holds the same content
+	asset := u.AssetMeta.Data()
+	if asset.S3Key == "" || asset.SHA256 == "" {
+		return nil
+	}
+	_, err := s.assetRefRepo.DeleteUnreferencedAsset(ctx, u.ProjectID, asset)
+	return err
+}
+
+func (s *uploadService) Start() {
+	interval := time.Duration(s.cfg.Artifact.UploadSweepIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, interval)
+}
+
+func (s *uploadService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *uploadService) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			swept, err := s.Sweep(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.log.Error("upload sweep failed", zap.Error(err))
+			}
+			if swept > 0 {
+				s.log.Info("upload sweep discarded expired uploads", zap.Int("uploads", swept))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/memodb-io/Acontext/internal/config"
+	"github.com/memodb-io/Acontext/internal/infra/blob"
+	encryptionpkg "github.com/memodb-io/Acontext/internal/infra/crypto"
+	"github.com/memodb-io/Acontext/internal/modules/model"
+	"github.com/memodb-io/Acontext/internal/modules/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeUploadRepo keeps uploads in memory with the repository's conditional updates.
+type fakeUploadRepo struct {
+	uploads map[uuid.UUID]model.Upload
+}
+
+func (r *fakeUploadRepo) Create(ctx context.Context, u *model.Upload) error {
+	r.uploads[u.ID] = *u
+	return nil
+}
+
+func (r *fakeUploadRepo) Get(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) (*model.Upload, error) {
+	u, ok := r.uploads[uploadID]
+	if !ok || u.ProjectID != projectID || !u.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	u.Parts = append(u.Parts[:0:0], u.Parts...)
+	return &u, nil
+}
+
+func (r *fakeUploadRepo) Advance(ctx context.Context, u *model.Upload, prevOffset int64) error {
+	cur, ok := r.uploads[u.ID]
+	if !ok || cur.Status != model.UploadStatusUploading || cur.Offset != prevOffset {
+		return repo.ErrUploadConflict
+	}
+	r.uploads[u.ID] = *u
+	return nil
+}
+
+func (r *fakeUploadRepo) Complete(ctx context.Context, u *model.Upload) error {
+	cur, ok := r.uploads[u.ID]
+	if !ok || cur.Status != model.UploadStatusUploading {
+		return repo.ErrUploadConflict
+	}
+	r.uploads[u.ID] = *u
+	return nil
+}
+
+func (r *fakeUploadRepo) Delete(ctx context.Context, projectID uuid.UUID, uploadID uuid.UUID) error {
+	delete(r.uploads, uploadID)
+	return nil
+}
+
+func (r *fakeUploadRepo) ListActiveByProject(ctx context.Context, projectID uuid.UUID) ([]*model.Upload, error) {
+	var active []*model.Upload
+	for _, u := range r.uploads {
+		if u.ProjectID == projectID && u.ExpiresAt.After(time.Now()) {
+			active = append(active, &u)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeUploadRepo) ListExpired(ctx context.Context, before time.Time, limit int) ([]*model.Upload, error) {
+	var expired []*model.Upload
+	for _, u := range r.uploads {
+		if u.ExpiresAt.Before(before) && len(expired) < limit {
+			expired = append(expired, &u)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeUploadRepo) DeleteExpired(ctx context.Context, uploadID uuid.UUID, before time.Time) (bool, error) {
+	u, ok := r.uploads[uploadID]
+	if !ok || !u.ExpiresAt.Before(before) {
+		return false, nil
+	}
+	delete(r.uploads, uploadID)
+	return true, nil
+}
+
+// expire backdates an upload's expiry by age.
+func (r *fakeUploadRepo) expire(uploadID uuid.UUID, age time.Duration) {
+	u := r.uploads[uploadID]
+	u.ExpiresAt = time.Now().Add(-age)
+	r.uploads[uploadID] = u
+}
+
+func newTestUploadService(t *testing.T) (*uploadService, *blob.LocalStore) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Blob.LocalRoot = t.TempDir()
+	cfg.Blob.SigningKey = "test-signing-key"
+	cfg.Artifact.MaxUploadSizeBytes = 64 << 20
+	store, err := blob.NewLocalStore(cfg)
+	require.NoError(t, err)
+	svc := NewUploadService(&fakeUploadRepo{uploads: map[uuid.UUID]model.Upload{}}, &MockAssetReferenceRepo{}, store, cfg, zap.NewNop()).(*uploadService)
+	return svc, store
+}
+
+func TestUploadService_Create_Validation(t *testing.T) {
+	svc, _ := newTestUploadService(t)
+	projectID := uuid.New()
+
+	tests := []struct {
+		name    string
+		in      CreateUploadInput
+		wantErr error
+	}{
+		{name: "unknown target", in: CreateUploadInput{Target: "sandbox", Filename: "a.bin", Size: 1}, wantErr: ErrInvalidUpload},
+		{name: "missing filename", in: CreateUploadInput{Target: model.UploadTargetDisk, Size: 1}, wantErr: ErrInvalidUpload},
+		{name: "filename with slash", in: CreateUploadInput{Target: model.UploadTargetDisk, Filename: "a/b.bin", Size: 1}, wantErr: ErrInvalidUpload},
+		{name: "negative size", in: CreateUploadInput{Target: model.UploadTargetDisk, Filename: "a.bin", Size: -1}, wantErr: ErrInvalidUpload},
+		{name: "too large", in: CreateUploadInput{Target: model.UploadTargetDisk, Filename: "a.bin", Size: 65 << 20}, wantErr: ErrUploadTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.ProjectID = projectID
+			_, err := svc.Create(context.Background(), tt.in)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	u, err := svc.Create(context.Background(), CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetMessage, Filename: "a.bin", Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, model.UploadStatusUploading, u.Status)
+	assert.Equal(t, int64(minUploadPartSize), u.PartSize)
+	assert.Zero(t, u.PartSize%encryptionpkg.DefaultChunkSize)
+}
+
+func TestUploadService_ResumableFlow(t *testing.T) {
+	kek, err := encryptionpkg.GenerateDEK()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		userKEK []byte
+	}{
+		{name: "plaintext"},
+		{name: "encrypted", userKEK: kek},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, store := newTestUploadService(t)
+			ctx := context.Background()
+			projectID := uuid.New()
+
+			partSize := int(svc.partSize())
+			data := make([]byte, 2*partSize+1000)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			u, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "data.bin", Size: int64(len(data)), UserKEK: tc.userKEK})
+			require.NoError(t, err)
+
+			// A body cut short mid-part keeps only the whole parts
+			u, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Body: bytes.NewReader(data[:partSize+partSize/2]), UserKEK: tc.userKEK})
+			require.NoError(t, err)
+			assert.Equal(t, int64(partSize), u.Offset)
+
+			_, err = svc.Complete(ctx, projectID, u.ID, tc.userKEK)
+			assert.ErrorIs(t, err, ErrInvalidUpload)
+			_, err = svc.Resolve(ctx, projectID, u.ID, model.UploadTargetDisk)
+			assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+			// Resuming from the wrong offset is rejected
+			_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Offset: 0, Body: bytes.NewReader(data), UserKEK: tc.userKEK})
+			assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+
+			u, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Offset: u.Offset, Body: bytes.NewReader(data[partSize:]), UserKEK: tc.userKEK})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(data)), u.Offset)
+			assert.Len(t, u.Parts, 3)
+
+			u, err = svc.Complete(ctx, projectID, u.ID, tc.userKEK)
+			require.NoError(t, err)
+			assert.Equal(t, model.UploadStatusCompleted, u.Status)
+			asset := u.AssetMeta.Data()
+			assert.Equal(t, u.SHA256, asset.SHA256)
+			assert.Equal(t, int64(len(data)), asset.SizeB)
+			assert.Contains(t, asset.S3Key, "disks/"+projectID.String()+"/")
+
+			got, err := store.DownloadFile(ctx, asset.S3Key, tc.userKEK)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, got))
+
+			// The staging object is gone once promoted
+			_, err = store.Head(ctx, u.StagingKey)
+			assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+
+			// Completing again is a no-op
+			again, err := svc.Complete(ctx, projectID, u.ID, tc.userKEK)
+			require.NoError(t, err)
+			assert.Equal(t, asset.S3Key, again.AssetMeta.Data().S3Key)
+
+			resolved, err := svc.Resolve(ctx, projectID, u.ID, model.UploadTargetDisk)
+			require.NoError(t, err)
+			assert.Equal(t, u.SHA256, resolved.SHA256)
+			_, err = svc.Resolve(ctx, projectID, u.ID, model.UploadTargetMessage)
+			assert.ErrorIs(t, err, ErrInvalidUpload)
+			_, err = svc.Resolve(ctx, uuid.New(), u.ID, model.UploadTargetDisk)
+			assert.ErrorIs(t, err, ErrUploadNotFound)
+		})
+	}
+}
+
+func TestUploadService_Append_Errors(t *testing.T) {
+	svc, _ := newTestUploadService(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	u, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetMessage, Filename: "a.txt", Size: 5})
+	require.NoError(t, err)
+
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Body: bytes.NewReader([]byte("hello world"))})
+	assert.ErrorIs(t, err, ErrInvalidUpload, "body past the declared size")
+
+	u, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Body: bytes.NewReader([]byte("hello"))})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), u.Offset)
+	assert.Contains(t, u.MIME, "text/plain")
+
+	u, err = svc.Complete(ctx, projectID, u.ID, nil)
+	require.NoError(t, err)
+	assert.Contains(t, u.AssetMeta.Data().S3Key, "assets/"+projectID.String()+"/")
+
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Offset: 5, Body: bytes.NewReader(nil)})
+	assert.ErrorIs(t, err, ErrInvalidUpload, "append to a completed upload")
+
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: uuid.New(), Body: bytes.NewReader(nil)})
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+
+	kek, err := encryptionpkg.GenerateDEK()
+	require.NoError(t, err)
+	enc, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "a.txt", Size: 5, UserKEK: kek})
+	require.NoError(t, err)
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: enc.ID, Body: bytes.NewReader([]byte("hello"))})
+	assert.ErrorIs(t, err, ErrInvalidUpload, "encrypted upload without a KEK")
+}
+
+func TestUploadService_EmptyAndAbort(t *testing.T) {
+	svc, store := newTestUploadService(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	empty, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "empty.txt", Size: 0})
+	require.NoError(t, err)
+	empty, err = svc.Complete(ctx, projectID, empty.ID, nil)
+	require.NoError(t, err)
+	got, err := store.DownloadFile(ctx, empty.AssetMeta.Data().S3Key, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	partSize := int(svc.partSize())
+	u, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "a.bin", Size: int64(2 * partSize)})
+	require.NoError(t, err)
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: u.ID, Body: bytes.NewReader(make([]byte, partSize))})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Abort(ctx, projectID, u.ID))
+	_, err = svc.Get(ctx, projectID, u.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+	assert.ErrorIs(t, svc.Abort(ctx, projectID, u.ID), ErrUploadNotFound)
+}
+
+func TestUploadService_Sweep(t *testing.T) {
+	svc, store := newTestUploadService(t)
+	r := svc.r.(*fakeUploadRepo)
+	refs := &MockAssetReferenceRepo{}
+	svc.assetRefRepo = refs
+	ctx := context.Background()
+	projectID := uuid.New()
+	partSize := int(svc.partSize())
+
+	// Unfinished, with a part stored
+	unfinished, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "a.bin", Size: int64(2 * partSize)})
+	require.NoError(t, err)
+	unfinished, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: unfinished.ID, Body: bytes.NewReader(make([]byte, partSize))})
+	require.NoError(t, err)
+	r.expire(unfinished.ID, 2*uploadSweepGrace)
+
+	// Completed but never attached
+	completed, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "b.txt", Size: 5})
+	require.NoError(t, err)
+	_, err = svc.Append(ctx, AppendUploadInput{ProjectID: projectID, UploadID: completed.ID, Body: bytes.NewReader([]byte("hello"))})
+	require.NoError(t, err)
+	completed, err = svc.Complete(ctx, projectID, completed.ID, nil)
+	require.NoError(t, err)
+	r.expire(completed.ID, 2*uploadSweepGrace)
+	refs.On("DeleteUnreferencedAsset", ctx, projectID, completed.AssetMeta.Data()).Return(true, nil)
+
+	// Expired too recently to be swept, and not expired at all
+	recent, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "c.txt", Size: 1})
+	require.NoError(t, err)
+	r.expire(recent.ID, time.Minute)
+	live, err := svc.Create(ctx, CreateUploadInput{ProjectID: projectID, Target: model.UploadTargetDisk, Filename: "d.txt", Size: 1})
+	require.NoError(t, err)
+
+	swept, err := svc.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, swept)
+	refs.AssertExpectations(t)
+
+	assert.NotContains(t, r.uploads, unfinished.ID)
+	assert.NotContains(t, r.uploads, completed.ID)
+	assert.Contains(t, r.uploads, recent.ID)
+	assert.Contains(t, r.uploads, live.ID)
+	// The stored part was discarded with its multipart upload
+	assert.Error(t, store.CompleteMultipartUpload(ctx, unfinished.StagingKey, unfinished.StoreUploadID, unfinished.Parts))
+
+	swept, err = svc.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, swept)
+}
+
+func TestUploadService_SweepDirectUploads(t *testing.T) {
+	svc, store := newTestUploadService(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	put := func(key string, age time.Duration) string {
+		_, err := store.Put(ctx, key, bytes.NewReader([]byte("x")), "text/plain", nil)
+		require.NoError(t, err)
+		written := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(store.Root, filepath.FromSlash(key)), written, written))
+		return key
+	}
+	abandoned := put(directUploadPrefix(projectID)+uuid.NewString(), MaxDirectUploadExpiry+2*directUploadSweepGrace)
+	pending := put(directUploadPrefix(projectID)+uuid.NewString(), MaxDirectUploadExpiry)
+	staging := put("uploads/"+projectID.String()+"/"+uuid.NewString(), MaxDirectUploadExpiry+2*directUploadSweepGrace)
+
+	swept, err := svc.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, swept)
+	_, err = store.Head(ctx, abandoned)
+	assert.ErrorIs(t, err, blob.ErrObjectNotFound)
+	for _, kept := range []string{pending, staging} {
+		_, err = store.Head(ctx, kept)
+		assert.NoError(t, err, kept)
+	}
+}
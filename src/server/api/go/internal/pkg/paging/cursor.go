@@ -24,7 +24,37 @@ func DecodeCursor(s string) (time.Time, uuid.UUID, error) {
 	if err != nil {
 		return time.Time{}, uuid.Nil, err
 	}
-	parts := strings.Split(string(b), "|")
+	return parseCursor(string(b))
+}
+
+// EncodeSortCursor encodes a position in a list ordered by sortKey, so that the cursor can be
+// rejected by a request sorted another way.
+func EncodeSortCursor(sortKey string, t time.Time, id uuid.UUID) string {
+	raw := fmt.Sprintf("%s|%d|%s", sortKey, t.UTC().UnixNano(), id.String())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeSortCursor(s string) (string, time.Time, uuid.UUID, error) {
+	if s == "" {
+		return "", time.Time{}, uuid.Nil, errors.New("empty cursor")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", time.Time{}, uuid.Nil, err
+	}
+	sortKey, rest, ok := strings.Cut(string(b), "|")
+	if !ok || sortKey == "" {
+		return "", time.Time{}, uuid.Nil, errors.New("bad cursor")
+	}
+	t, id, err := parseCursor(rest)
+	if err != nil {
+		return "", time.Time{}, uuid.Nil, err
+	}
+	return sortKey, t, id, nil
+}
+
+func parseCursor(raw string) (time.Time, uuid.UUID, error) {
+	parts := strings.Split(raw, "|")
 	if len(parts) != 2 {
 		return time.Time{}, uuid.Nil, errors.New("bad cursor")
 	}
@@ -38,3 +68,24 @@ func DecodeCursor(s string) (time.Time, uuid.UUID, error) {
 	}
 	return time.Unix(0, ns).UTC(), id, nil
 }
+
+// EncodePathCursor encodes the position of a file in path order. The path must end with "/".
+func EncodePathCursor(path, filename string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(path + filename))
+}
+
+func DecodePathCursor(s string) (string, string, error) {
+	if s == "" {
+		return "", "", errors.New("empty cursor")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", "", err
+	}
+	raw := string(b)
+	i := strings.LastIndex(raw, "/")
+	if !strings.HasPrefix(raw, "/") || i < 0 {
+		return "", "", errors.New("bad cursor")
+	}
+	return raw[:i+1], raw[i+1:], nil
+}
@@ -236,3 +236,41 @@ func TestCursor_URLSafe(t *testing.T) {
 		assert.NotContains(t, cursor, "=") // RawURLEncoding does not include padding characters
 	})
 }
+
+func TestPathCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, tc := range [][2]string{{"/", "a.txt"}, {"/docs/sub dir/", "notes|v2.md"}, {"/docs/", ""}} {
+			p, f, err := DecodePathCursor(EncodePathCursor(tc[0], tc[1]))
+			assert.NoError(t, err)
+			assert.Equal(t, tc[0], p)
+			assert.Equal(t, tc[1], f)
+		}
+	})
+
+	t.Run("rejects malformed cursors", func(t *testing.T) {
+		for _, s := range []string{"", "!!!", EncodeCursor(time.Now(), uuid.New())} {
+			_, _, err := DecodePathCursor(s)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestSortCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		testTime := time.Date(2024, 1, 1, 12, 0, 0, 123, time.UTC)
+		testID := uuid.New()
+
+		key, gotT, gotID, err := DecodeSortCursor(EncodeSortCursor("last_message_at", testTime, testID))
+		assert.NoError(t, err)
+		assert.Equal(t, "last_message_at", key)
+		assert.True(t, gotT.Equal(testTime))
+		assert.Equal(t, testID, gotID)
+	})
+
+	t.Run("rejects cursors without a sort key", func(t *testing.T) {
+		for _, s := range []string{"", "!!!", EncodeCursor(time.Now(), uuid.New())} {
+			_, _, _, err := DecodeSortCursor(s)
+			assert.Error(t, err)
+		}
+	})
+}
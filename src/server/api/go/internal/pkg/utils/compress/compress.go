@@ -0,0 +1,108 @@
+// Package compress implements the codecs used to compress stored objects and cached parts.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithms, as recorded in object metadata.
+const (
+	None = "none"
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+var ErrUnknownAlgo = errors.New("unknown compression algorithm")
+
+// Valid reports whether algo names a codec; None is not one.
+func Valid(algo string) bool {
+	return algo == Gzip || algo == Zstd
+}
+
+// NewWriter returns a writer compressing into dst. Close flushes it but does not close dst.
+func NewWriter(algo string, dst io.Writer) (io.WriteCloser, error) {
+	switch algo {
+	case Gzip:
+		return gzip.NewWriter(dst), nil
+	case Zstd:
+		return zstd.NewWriter(dst)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgo, algo)
+	}
+}
+
+// NewReader returns a reader of src decompressed. Close releases the decoder but does not
+// close src.
+func NewReader(algo string, src io.Reader) (io.ReadCloser, error) {
+	switch algo {
+	case Gzip:
+		return gzip.NewReader(src)
+	case Zstd:
+		d, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgo, algo)
+	}
+}
+
+// Compress compresses data in memory.
+func Compress(algo string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := NewWriter(algo, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses data in memory.
+func Decompress(algo string, data []byte) ([]byte, error) {
+	r, err := NewReader(algo, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", algo, err)
+	}
+	return out, nil
+}
+
+// Compressible reports whether content of contentType is worth compressing: text and
+// structured text such as JSON, which parts and tool results mostly are. Media and archive
+// formats are compressed already.
+func Compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/x-ndjson", "application/jsonl",
+		"application/xml", "application/javascript", "application/x-javascript",
+		"application/yaml", "application/x-yaml", "application/toml",
+		"application/sql", "application/x-sh", "image/svg+xml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package compress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"type":"text","text":"hello world"},`, 100))
+	for _, algo := range []string{Gzip, Zstd} {
+		t.Run(algo, func(t *testing.T) {
+			compressed, err := Compress(algo, data)
+			require.NoError(t, err)
+			assert.Less(t, len(compressed), len(data))
+
+			got, err := Decompress(algo, compressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+
+			_, err = Decompress(algo, data)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Compress("brotli", data)
+	assert.ErrorIs(t, err, ErrUnknownAlgo)
+	_, err = Decompress(None, data)
+	assert.ErrorIs(t, err, ErrUnknownAlgo)
+}
+
+func TestCompressible(t *testing.T) {
+	for _, ct := range []string{"text/plain; charset=utf-8", "application/json", "application/vnd.api+json", "image/svg+xml", "TEXT/CSV"} {
+		assert.True(t, Compressible(ct), ct)
+	}
+	for _, ct := range []string{"", "image/png", "application/zip", "application/octet-stream", "video/mp4"} {
+		assert.False(t, Compressible(ct), ct)
+	}
+}
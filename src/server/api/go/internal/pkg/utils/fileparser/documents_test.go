@@ -0,0 +1,332 @@
+package fileparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// zipDocument builds an Office document from its parts.
+func zipDocument(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pdfDocument builds a PDF with one page per entry of pages, each showing its lines.
+func pdfDocument(pages ...[]string) []byte {
+	fontID := 3 + 2*len(pages)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+	}
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+2*i)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	for i, lines := range pages {
+		var content strings.Builder
+		for j, line := range lines {
+			fmt.Fprintf(&content, "BT /F1 12 Tf 72 %d Td (%s) Tj ET\n", 720-20*j, line)
+		}
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R >> >> >>", 4+2*i, fontID),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+const docxDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:r><w:t>Design spec</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Latency budget: </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>200ms</w:t></w:r></w:p>
+<w:p><w:r><w:t>Owner</w:t><w:tab/><w:t>Platform</w:t><w:br/><w:t>Reviewed</w:t></w:r><w:r><w:delText>removed</w:delText></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Cell A</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Cell B</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body>
+</w:document>`
+
+func TestDOCXParser(t *testing.T) {
+	parser := NewFileParser()
+	content := zipDocument(t, map[string]string{"word/document.xml": docxDocument})
+
+	result, err := parser.ParseFile("spec.docx", mimeDOCX, content)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if result.Type != "docx" {
+		t.Errorf("ParseFile() type = %v, want docx", result.Type)
+	}
+	want := "Design spec\nLatency budget: 200ms\nOwner\tPlatform\nReviewed\nCell A\nCell B\n"
+	if result.Raw != want {
+		t.Errorf("ParseFile() raw = %q, want %q", result.Raw, want)
+	}
+
+	if _, err := parser.ParseFile("broken.docx", mimeDOCX, []byte("not a zip")); err == nil {
+		t.Error("ParseFile() should return error for invalid DOCX")
+	}
+	if _, err := parser.ParseFile("empty.docx", mimeDOCX, zipDocument(t, map[string]string{"other.xml": "<x/>"})); err == nil {
+		t.Error("ParseFile() should return error for DOCX without document")
+	}
+}
+
+func TestXLSXParser(t *testing.T) {
+	content := zipDocument(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Revenue" sheetId="1" r:id="rId2"/><sheet name="Notes" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet2.xml"/>
+<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Region</t></si><si><t>Q1</t></si><si><r><t>North, </t></r><r><rPr><b/></rPr><t>East</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="b"><v>1</v></c><c r="C2"><f>SUM(1,2)</f><v>1250.5</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="B1" t="inlineStr"><is><t>see "Revenue"</t></is></c></row>
+</sheetData></worksheet>`,
+	})
+
+	result, err := NewFileParser().ParseFile("report.xlsx", mimeXLSX, content)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if result.Type != "xlsx" {
+		t.Errorf("ParseFile() type = %v, want xlsx", result.Type)
+	}
+	want := "# Sheet: Revenue\nRegion,,Q1\n\"North, East\",true,1250.5\n\n# Sheet: Notes\n,\"see \"\"Revenue\"\"\"\n"
+	if result.Raw != want {
+		t.Errorf("ParseFile() raw = %q, want %q", result.Raw, want)
+	}
+}
+
+func TestXLSXSheetCSV_ColumnLimit(t *testing.T) {
+	sheet := func(ref string) []byte {
+		return []byte(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="` + ref + `" t="inlineStr"><is><t>x</t></is></c></row></sheetData></worksheet>`)
+	}
+
+	var b strings.Builder
+	if err := xlsxSheetCSV(&b, sheet("XFD1"), nil); err != nil {
+		t.Fatalf("XFD1: %v", err)
+	}
+	if got := strings.Count(b.String(), ","); got != maxXLSXColumns-1 {
+		t.Errorf("XFD1 is written as column %d", got+1)
+	}
+	for _, ref := range []string{"XFE1", "ZZZZZZZ1", strings.Repeat("Z", 40) + "1"} {
+		if err := xlsxSheetCSV(&strings.Builder{}, sheet(ref), nil); err == nil {
+			t.Errorf("%s: want error", ref)
+		}
+	}
+}
+
+func TestPPTXParser(t *testing.T) {
+	slide := func(texts ...string) string {
+		var b strings.Builder
+		b.WriteString(`<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>`)
+		for _, text := range texts {
+			fmt.Fprintf(&b, `<p:sp><p:txBody><a:p><a:r><a:t>%s</a:t></a:r></a:p></p:txBody></p:sp>`, text)
+		}
+		b.WriteString(`</p:spTree></p:cSld></p:sld>`)
+		return b.String()
+	}
+	content := zipDocument(t, map[string]string{
+		"ppt/slides/slide10.xml":           slide("Appendix"),
+		"ppt/slides/slide2.xml":            slide("Roadmap", "Ship v2"),
+		"ppt/slides/slide1.xml":            slide("Kickoff"),
+		"ppt/slides/_rels/slide1.xml.rels": "<Relationships/>",
+	})
+
+	result, err := NewFileParser().ParseFile("deck.pptx", mimePPTX, content)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if result.Type != "pptx" {
+		t.Errorf("ParseFile() type = %v, want pptx", result.Type)
+	}
+	want := "# Slide 1\nKickoff\n\n# Slide 2\nRoadmap\nShip v2\n\n# Slide 10\nAppendix\n"
+	if result.Raw != want {
+		t.Errorf("ParseFile() raw = %q, want %q", result.Raw, want)
+	}
+}
+
+func TestPDFParser(t *testing.T) {
+	parser := NewFileParser()
+	content := pdfDocument(
+		[]string{"Quarterly report", "Revenue grew 12 percent"},
+		[]string{"Appendix"},
+	)
+
+	result, err := parser.ParseFile("report.pdf", "application/pdf", content)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if result.Type != "pdf" {
+		t.Errorf("ParseFile() type = %v, want pdf", result.Type)
+	}
+	want := "Quarterly report\nRevenue grew 12 percent\n\nAppendix\n"
+	if result.Raw != want {
+		t.Errorf("ParseFile() raw = %q, want %q", result.Raw, want)
+	}
+
+	if _, err := parser.ParseFile("broken.pdf", "application/pdf", []byte("%PDF-1.4\ngarbage")); err == nil {
+		t.Error("ParseFile() should return error for invalid PDF")
+	}
+}
+
+func TestHTMLParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "main element",
+			content: `<!DOCTYPE html><html><head><title>Release notes</title><style>p{color:red}</style></head>
+<body><nav><a href="/">Home</a></nav>
+<main><h1>Version   2.0</h1><p>Adds <b>fast</b>er search and <a href="#">links</a>.</p>
+<script>track()</script><ul><li>One</li><li>Two</li></ul>
+<pre>go test ./...
+  ok</pre>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table></main>
+<footer>Copyright</footer></body></html>`,
+			want: "Release notes\nVersion 2.0\nAdds faster search and links.\nOne\nTwo\ngo test ./...\n  ok\nName\tValue\na\t1\n",
+		},
+		{
+			name:    "body without boilerplate",
+			content: `<html><body><header>Site</header><div>First<br>Second</div><aside>Ads</aside><p>Third</p><form><input value="x"><button>Go</button></form></body></html>`,
+			want:    "First\nSecond\nThird\n",
+		},
+		{
+			name:    "articles",
+			content: `<html><body><nav>Menu</nav><article><p>Post one</p></article><div>Sidebar</div><article><p>Post two</p></article></body></html>`,
+			want:    "Post one\nPost two\n",
+		},
+		{
+			name:    "declared charset",
+			content: "<html><head><meta charset=\"windows-1252\"></head><body><p>Caf\xe9</p></body></html>",
+			want:    "Café\n",
+		},
+	}
+
+	parser := NewFileParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.ParseFile("page.html", "text/html; charset=utf-8", []byte(tt.content))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if result.Type != "html" {
+				t.Errorf("ParseFile() type = %v, want html", result.Type)
+			}
+			if result.Raw != tt.want {
+				t.Errorf("ParseFile() raw = %q, want %q", result.Raw, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileParser_TextAndDocuments(t *testing.T) {
+	parser := NewFileParser()
+
+	tests := []struct {
+		filename     string
+		mimeType     string
+		isText       bool
+		extractsText bool
+	}{
+		{"notes.txt", "text/plain", true, false},
+		{"main.go", "text/x-go", true, false},
+		{"page.html", "text/html; charset=utf-8", true, true},
+		{"report.pdf", "application/pdf", false, true},
+		{"spec.docx", mimeDOCX, false, true},
+		{"upload", mimeXLSX, false, true},
+		{"deck.pptx", "application/zip", false, true},
+		{"image.png", "image/png", false, false},
+	}
+	for _, tt := range tests {
+		if got := parser.IsTextFile(tt.filename, tt.mimeType); got != tt.isText {
+			t.Errorf("IsTextFile(%q) = %v, want %v", tt.filename, got, tt.isText)
+		}
+		if got := parser.ExtractsText(tt.filename, tt.mimeType); got != tt.extractsText {
+			t.Errorf("ExtractsText(%q) = %v, want %v", tt.filename, got, tt.extractsText)
+		}
+		if got := parser.CanParseFile(tt.filename, tt.mimeType); got != (tt.isText || tt.extractsText) {
+			t.Errorf("CanParseFile(%q) = %v", tt.filename, got)
+		}
+	}
+}
+
+func TestOOXMLPackage_Budget(t *testing.T) {
+	part := strings.Repeat("x", 1000)
+	pkg, err := openOOXML(zipDocument(t, map[string]string{"a.xml": part, "b.xml": part}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every part read counts against the whole document's budget
+	pkg.remaining = 1500
+	if _, err := pkg.read("a.xml"); err != nil {
+		t.Fatalf("read a.xml: %v", err)
+	}
+	if _, err := pkg.read("b.xml"); err == nil || !strings.Contains(err.Error(), "decompressed") {
+		t.Errorf("read b.xml past the budget: err = %v", err)
+	}
+}
+
+func TestFileParser_TruncatesExtractedText(t *testing.T) {
+	// A paragraph of multi-byte runes, so the cut has to avoid splitting one
+	text := strings.Repeat("é", MaxExtractedTextSize/2+10)
+	content := zipDocument(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>x` +
+			text + `</w:t></w:r></w:p></w:body></w:document>`,
+	})
+
+	fc, err := NewFileParser().ParseFile("long.docx", mimeDOCX, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fc.Truncated {
+		t.Error("Truncated = false")
+	}
+	if len(fc.Raw) > MaxExtractedTextSize || !utf8.ValidString(fc.Raw) {
+		t.Errorf("extracted %d bytes, valid UTF-8: %v", len(fc.Raw), utf8.ValidString(fc.Raw))
+	}
+
+	fc, err = NewFileParser().ParseFile("short.txt", "text/plain", []byte("short"))
+	if err != nil || fc.Truncated {
+		t.Errorf("ParseFile(short.txt) = %+v, %v", fc, err)
+	}
+}
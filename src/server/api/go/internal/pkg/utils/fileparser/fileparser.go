@@ -7,14 +7,19 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 )
 
+// MaxExtractedTextSize bounds the text extracted from a document; the rest is cut off.
+const MaxExtractedTextSize = 4 << 20
+
 // FileContent represents the parsed content of a file
 type FileContent struct {
-	Type string `json:"type"` // "text", "json", "csv", "code"
-	Raw  string `json:"raw"`  // Raw text content
+	Type      string `json:"type"`                // "text", "json", "csv", "code", or "pdf", "docx", "xlsx", "pptx", "html" for extracted text
+	Raw       string `json:"raw"`                 // Raw text content, or the text extracted from a document
+	Truncated bool   `json:"truncated,omitempty"` // extracted text was cut off at MaxExtractedTextSize (4MB)
 }
 
 // Parser interface for different file types
@@ -150,12 +155,21 @@ func (p *CodeParser) Parse(content []byte) (*FileContent, error) {
 
 // FileParser manages all parsers
 type FileParser struct {
-	parsers []Parser
+	// extractors return text extracted from a document rather than the file itself
+	extractors []Parser
+	parsers    []Parser
 }
 
 // NewFileParser creates a new file parser with all available parsers
 func NewFileParser() *FileParser {
 	return &FileParser{
+		extractors: []Parser{
+			&PDFParser{},
+			&DOCXParser{},
+			&XLSXParser{},
+			&PPTXParser{},
+			&HTMLParser{},
+		},
 		parsers: []Parser{
 			&JSONParser{},
 			&CSVParser{},
@@ -167,6 +181,12 @@ func NewFileParser() *FileParser {
 
 // CanParseFile checks if a file can be parsed based on filename and MIME type
 func (fp *FileParser) CanParseFile(filename string, mimeType string) bool {
+	return fp.IsTextFile(filename, mimeType) || fp.ExtractsText(filename, mimeType)
+}
+
+// IsTextFile reports whether a file is plain text that can be edited as such. Its parsed
+// content is the file itself, except for HTML, whose content is its extracted main text.
+func (fp *FileParser) IsTextFile(filename string, mimeType string) bool {
 	for _, parser := range fp.parsers {
 		if parser.CanParse(filename, mimeType) {
 			return true
@@ -175,9 +195,30 @@ func (fp *FileParser) CanParseFile(filename string, mimeType string) bool {
 	return false
 }
 
+// ExtractsText reports whether a file's parsed content is text extracted from it rather than
+// the file itself.
+func (fp *FileParser) ExtractsText(filename string, mimeType string) bool {
+	for _, parser := range fp.extractors {
+		if parser.CanParse(filename, mimeType) {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseFile attempts to parse file content based on filename and MIME type
 func (fp *FileParser) ParseFile(filename string, mimeType string, content []byte) (*FileContent, error) {
-	// Try each parser in order
+	// Try each parser in order, documents first: HTML also counts as text
+	for _, parser := range fp.extractors {
+		if parser.CanParse(filename, mimeType) {
+			fc, err := parser.Parse(content)
+			if err != nil {
+				return nil, err
+			}
+			fc.Raw, fc.Truncated = truncateText(fc.Raw, MaxExtractedTextSize)
+			return fc, nil
+		}
+	}
 	for _, parser := range fp.parsers {
 		if parser.CanParse(filename, mimeType) {
 			return parser.Parse(content)
@@ -198,3 +239,14 @@ func (fp *FileParser) ParseFileFromReader(filename string, mimeType string, read
 
 	return fp.ParseFile(filename, mimeType, content)
 }
+
+// truncateText cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateText(s string, n int) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n], true
+}
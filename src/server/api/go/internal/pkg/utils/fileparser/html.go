@@ -0,0 +1,204 @@
+package fileparser
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// HTMLParser extracts the main text of HTML pages: the title, then the page's <main>, or its
+// <article>s, or else its <body> without navigation, header, footer and sidebars. Block
+// elements start new lines; scripts, styles and forms are dropped.
+type HTMLParser struct{}
+
+func (p *HTMLParser) CanParse(filename string, mimeType string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".html" || ext == ".htm" || ext == ".xhtml" {
+		return true
+	}
+	return strings.HasPrefix(mimeType, "text/html") || strings.HasPrefix(mimeType, "application/xhtml+xml")
+}
+
+func (p *HTMLParser) Parse(content []byte) (*FileContent, error) {
+	// Decode the page from the charset it declares, if any
+	r, err := charset.NewReader(bytes.NewReader(content), "text/html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	w := &htmlTextWriter{}
+	if title := findHTML(doc, atom.Title); title != nil {
+		w.walk(title, false)
+		w.newline()
+	}
+	if main := findHTML(doc, atom.Main); main != nil {
+		w.walk(main, false)
+	} else if articles := findAllHTML(doc, atom.Article); len(articles) > 0 {
+		for _, article := range articles {
+			w.walk(article, false)
+			w.newline()
+		}
+	} else if body := findHTML(doc, atom.Body); body != nil {
+		w.walk(body, true)
+	}
+
+	return &FileContent{
+		Type: "html",
+		Raw:  w.String(),
+	}, nil
+}
+
+// htmlSkipped are elements whose content is never main text.
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Canvas: true, atom.Iframe: true, atom.Object: true, atom.Form: true,
+	atom.Select: true, atom.Button: true,
+}
+
+// htmlBoilerplate are elements surrounding the main text of a page.
+var htmlBoilerplate = map[atom.Atom]bool{
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+}
+
+// htmlBlocks are elements that start a new line.
+var htmlBlocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Dd: true, atom.Details: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Figcaption: true, atom.Figure: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Summary: true, atom.Table: true, atom.Tr: true, atom.Ul: true,
+	atom.Title: true,
+}
+
+// htmlTextWriter collects text, collapsing whitespace outside <pre> and starting every block
+// on a new line.
+type htmlTextWriter struct {
+	b         strings.Builder
+	pendingWS bool // whitespace seen since the last word
+}
+
+func (w *htmlTextWriter) walk(n *html.Node, skipBoilerplate bool) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if htmlSkipped[n.DataAtom] || (skipBoilerplate && htmlBoilerplate[n.DataAtom]) {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Br:
+			w.newline()
+			return
+		case atom.Pre:
+			w.newline()
+			w.b.WriteString(strings.Trim(htmlNodeText(n), "\n"))
+			w.newline()
+			return
+		case atom.Td, atom.Th:
+			if w.midLine() {
+				w.b.WriteString("\t")
+			}
+			w.pendingWS = false
+		}
+	}
+
+	block := n.Type == html.ElementNode && htmlBlocks[n.DataAtom]
+	if block {
+		w.newline()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c, skipBoilerplate)
+	}
+	if block {
+		w.newline()
+	}
+}
+
+func (w *htmlTextWriter) text(s string) {
+	const space = " \t\n\r\f"
+	if s == "" {
+		return
+	}
+	if strings.ContainsRune(space, rune(s[0])) {
+		w.pendingWS = true
+	}
+	for i, word := range strings.Fields(s) {
+		if (i > 0 || w.pendingWS) && w.midLine() {
+			w.b.WriteString(" ")
+		}
+		w.b.WriteString(word)
+		w.pendingWS = false
+	}
+	if strings.ContainsRune(space, rune(s[len(s)-1])) {
+		w.pendingWS = true
+	}
+}
+
+// midLine reports whether the last written character is part of a line's text.
+func (w *htmlTextWriter) midLine() bool {
+	out := w.b.String()
+	return out != "" && !strings.HasSuffix(out, "\n") && !strings.HasSuffix(out, "\t")
+}
+
+func (w *htmlTextWriter) newline() {
+	w.pendingWS = false
+	if w.b.Len() > 0 && !strings.HasSuffix(w.b.String(), "\n") {
+		w.b.WriteString("\n")
+	}
+}
+
+func (w *htmlTextWriter) String() string {
+	return w.b.String()
+}
+
+// htmlNodeText returns the text below n as written.
+func htmlNodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func findHTML(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findHTML(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findAllHTML(n *html.Node, a atom.Atom) []*html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return []*html.Node{n}
+	}
+	var found []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findAllHTML(c, a)...)
+	}
+	return found
+}
@@ -0,0 +1,421 @@
+package fileparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxOOXMLPartSize bounds the decompressed size of one XML part of an Office document, and
+	// maxOOXMLSize that of all parts read from it, so a small archive cannot expand without limit.
+	maxOOXMLPartSize = 64 << 20
+	maxOOXMLSize     = 256 << 20
+
+	// maxXLSXColumns is the number of columns of a worksheet, A to XFD
+	maxXLSXColumns = 16384
+)
+
+const (
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	mimePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
+// DOCXParser extracts the text of Word documents, one line per paragraph
+type DOCXParser struct{}
+
+func (p *DOCXParser) CanParse(filename string, mimeType string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".docx" || strings.HasPrefix(mimeType, mimeDOCX)
+}
+
+func (p *DOCXParser) Parse(content []byte) (*FileContent, error) {
+	pkg, err := openOOXML(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DOCX: %w", err)
+	}
+	doc, err := pkg.read("word/document.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DOCX: %w", err)
+	}
+	text, err := ooxmlText(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DOCX: %w", err)
+	}
+	return &FileContent{
+		Type: "docx",
+		Raw:  text,
+	}, nil
+}
+
+// PPTXParser extracts the text of PowerPoint presentations, slide by slide
+type PPTXParser struct{}
+
+func (p *PPTXParser) CanParse(filename string, mimeType string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".pptx" || strings.HasPrefix(mimeType, mimePPTX)
+}
+
+func (p *PPTXParser) Parse(content []byte) (*FileContent, error) {
+	pkg, err := openOOXML(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PPTX: %w", err)
+	}
+
+	// Slides are numbered by their part name: ppt/slides/slide1.xml, slide2.xml, ...
+	type slide struct {
+		num  int
+		name string
+	}
+	var slides []slide
+	for _, f := range pkg.zr.File {
+		dir, base := path.Split(f.Name)
+		if dir != "ppt/slides/" || !strings.HasPrefix(base, "slide") || !strings.HasSuffix(base, ".xml") {
+			continue
+		}
+		num, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(base, "slide"), ".xml"))
+		if err != nil {
+			continue
+		}
+		slides = append(slides, slide{num: num, name: f.Name})
+	}
+	if len(slides) == 0 {
+		return nil, errors.New("failed to parse PPTX: no slides")
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].num < slides[j].num })
+
+	var b strings.Builder
+	for i, s := range slides {
+		if b.Len() > MaxExtractedTextSize {
+			// The rest would be truncated away
+			break
+		}
+		data, err := pkg.read(s.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PPTX: %w", err)
+		}
+		text, err := ooxmlText(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PPTX: %s: %w", s.name, err)
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# Slide %d\n%s", s.num, text)
+	}
+	return &FileContent{
+		Type: "pptx",
+		Raw:  b.String(),
+	}, nil
+}
+
+// XLSXParser converts each sheet of Excel workbooks to CSV
+type XLSXParser struct{}
+
+func (p *XLSXParser) CanParse(filename string, mimeType string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".xlsx" || strings.HasPrefix(mimeType, mimeXLSX)
+}
+
+func (p *XLSXParser) Parse(content []byte) (*FileContent, error) {
+	pkg, err := openOOXML(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XLSX: %w", err)
+	}
+	sheets, err := xlsxSheets(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XLSX: %w", err)
+	}
+	shared, err := xlsxSharedStrings(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XLSX: %w", err)
+	}
+
+	var b strings.Builder
+	for i, sheet := range sheets {
+		if b.Len() > MaxExtractedTextSize {
+			// The rest would be truncated away
+			break
+		}
+		data, err := pkg.read(sheet.part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XLSX: %w", err)
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# Sheet: %s\n", sheet.name)
+		if err := xlsxSheetCSV(&b, data, shared); err != nil {
+			return nil, fmt.Errorf("failed to parse XLSX: sheet %s: %w", sheet.name, err)
+		}
+	}
+	return &FileContent{
+		Type: "xlsx",
+		Raw:  b.String(),
+	}, nil
+}
+
+// ooxmlPackage is an Office document's ZIP archive, read within a budget of decompressed bytes.
+type ooxmlPackage struct {
+	zr        *zip.Reader
+	remaining int64 // decompressed bytes left to read
+}
+
+func openOOXML(content []byte) (*ooxmlPackage, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	return &ooxmlPackage{zr: zr, remaining: maxOOXMLSize}, nil
+}
+
+// read reads one part of the document, failing once the part or the document as a whole
+// decompresses to more than allowed.
+func (pkg *ooxmlPackage) read(name string) ([]byte, error) {
+	f, err := pkg.zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer f.Close()
+	limit := min(int64(maxOOXMLPartSize), pkg.remaining)
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if int64(len(data)) > limit {
+		if limit < maxOOXMLPartSize {
+			return nil, fmt.Errorf("document exceeds %d bytes decompressed", maxOOXMLSize)
+		}
+		return nil, fmt.Errorf("%s exceeds %d bytes", name, maxOOXMLPartSize)
+	}
+	pkg.remaining -= int64(len(data))
+	return data, nil
+}
+
+// ooxmlText returns the text runs of a WordprocessingML or DrawingML part, ending every
+// paragraph with a newline. Both name runs t, paragraphs p, tabs tab and breaks br.
+func ooxmlText(data []byte) (string, error) {
+	var b strings.Builder
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br", "cr":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+type xlsxSheet struct {
+	name string
+	part string
+}
+
+// xlsxSheets lists the workbook's sheets in tab order with the parts holding them.
+func xlsxSheets(pkg *ooxmlPackage) ([]xlsxSheet, error) {
+	data, err := pkg.read("xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(data, &workbook); err != nil {
+		return nil, fmt.Errorf("xl/workbook.xml: %w", err)
+	}
+
+	data, err = pkg.read("xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("xl/_rels/workbook.xml.rels: %w", err)
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		// Targets are relative to xl/, or absolute within the package
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for _, s := range workbook.Sheets {
+		part, ok := targets[s.RID]
+		if !ok {
+			return nil, fmt.Errorf("sheet %s has no part", s.Name)
+		}
+		sheets = append(sheets, xlsxSheet{name: s.Name, part: part})
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings returns the workbook's shared string table; workbooks without strings
+// have none.
+func xlsxSharedStrings(pkg *ooxmlPackage) ([]string, error) {
+	data, err := pkg.read("xl/sharedStrings.xml")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := xml.Unmarshal(data, &sst); err != nil {
+		return nil, fmt.Errorf("xl/sharedStrings.xml: %w", err)
+	}
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+// xlsxRichText is a string that is either plain or made of formatted runs.
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r xlsxRichText) String() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var b strings.Builder
+	for _, run := range r.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+// xlsxSheetCSV writes a worksheet's cell values as CSV, one record per stored row. Cells are
+// placed by their reference, so empty cells stay empty columns. Values are written as
+// stored: numbers and dates unformatted, formulas as their last computed value.
+func xlsxSheetCSV(w io.Writer, data []byte, shared []string) error {
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	for _, row := range sheet.Rows {
+		var record []string
+		for _, c := range row.Cells {
+			col := len(record)
+			if c.Ref != "" {
+				n, ok, err := xlsxColumn(c.Ref)
+				if err != nil {
+					return err
+				}
+				if ok && n >= col {
+					col = n
+				}
+			}
+			if col >= maxXLSXColumns {
+				return fmt.Errorf("row has more than %d columns", maxXLSXColumns)
+			}
+			for len(record) < col {
+				record = append(record, "")
+			}
+
+			value := c.Value
+			switch c.Type {
+			case "s":
+				i, err := strconv.Atoi(c.Value)
+				if err != nil || i < 0 || i >= len(shared) {
+					return fmt.Errorf("cell %s: bad shared string index %q", c.Ref, c.Value)
+				}
+				value = shared[i]
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = strconv.FormatBool(c.Value == "1")
+			}
+			record = append(record, value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// xlsxColumn returns the zero-based column of a cell reference such as "C7", reporting
+// whether ref names one. Columns past XFD, the last one a worksheet has, are an error.
+func xlsxColumn(ref string) (int, bool, error) {
+	n := 0
+	i := 0
+	for ; i < len(ref); i++ {
+		c := ref[i]
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		n = n*26 + int(c-'A'+1)
+		if n > maxXLSXColumns {
+			return 0, false, fmt.Errorf("cell %s: column is past XFD", ref)
+		}
+	}
+	if i == 0 {
+		return 0, false, nil
+	}
+	return n - 1, true, nil
+}
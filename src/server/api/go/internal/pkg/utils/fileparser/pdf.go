@@ -0,0 +1,59 @@
+package fileparser
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFParser extracts the text of PDF documents, page by page. Scanned pages without a text
+// layer yield no text.
+type PDFParser struct{}
+
+func (p *PDFParser) CanParse(filename string, mimeType string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".pdf" || strings.HasPrefix(mimeType, "application/pdf")
+}
+
+func (p *PDFParser) Parse(content []byte) (fc *FileContent, err error) {
+	// The reader panics on some malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			fc, err = nil, fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	var b strings.Builder
+	for i := 1; i <= r.NumPage() && b.Len() <= MaxExtractedTextSize; i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PDF: page %d: %w", i, err)
+		}
+		// Every text object starts on a new line
+		text = strings.TrimLeft(text, "\n")
+		if text == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return &FileContent{
+		Type: "pdf",
+		Raw:  b.String(),
+	}, nil
+}
@@ -0,0 +1,262 @@
+package path
+
+import (
+	"errors"
+	"fmt"
+	stdpath "path"
+	"strings"
+)
+
+var ErrBadGlob = errors.New("glob pattern is invalid")
+
+// maxGlobExpansions bounds how many patterns {a,b} alternation may expand to.
+const maxGlobExpansions = 256
+
+// ValidateGlob reports whether pattern is a well-formed glob for MatchGlob.
+func ValidateGlob(pattern string) error {
+	patterns, err := expandBraces(pattern)
+	if err != nil {
+		return err
+	}
+	for _, p := range patterns {
+		for _, seg := range strings.Split(p, "/") {
+			if _, err := stdpath.Match(shellClass(seg), ""); err != nil {
+				return fmt.Errorf("%w: %q", ErrBadGlob, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchGlob matches a file path against a shell-style glob. Both are taken relative to the
+// root, so a leading '/' is optional. A '*' matches any run of characters within one path
+// segment, and a '**' segment matches zero or more whole segments ("src/**/*.go"). A '?'
+// matches one character other than '/'. Character classes such as [abc] and [a-z] are
+// negated with [!abc] or [^abc]. Alternation {a,b} may contain '/' and nest. A backslash
+// makes the next character literal.
+//
+// Malformed patterns match nothing; use ValidateGlob to report them.
+func MatchGlob(pattern string, name string) bool {
+	patterns, err := expandBraces(pattern)
+	if err != nil {
+		return false
+	}
+	nameSegs := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	for _, p := range patterns {
+		if matchSegments(strings.Split(strings.TrimPrefix(p, "/"), "/"), nameSegs) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pat []string, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for len(pat) > 0 && pat[0] == "**" {
+				pat = pat[1:]
+			}
+			if len(pat) == 0 {
+				// a trailing ** matches everything below, but not the directory itself
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := stdpath.Match(shellClass(pat[0]), name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// shellClass rewrites shell negated classes [!...] into the [^...] form path.Match accepts.
+func shellClass(seg string) string {
+	if !strings.Contains(seg, "[!") {
+		return seg
+	}
+	var b strings.Builder
+	for i := 0; i < len(seg); i++ {
+		switch {
+		case seg[i] == '\\' && i+1 < len(seg):
+			b.WriteString(seg[i : i+2])
+			i++
+		case seg[i] == '[' && i+1 < len(seg) && seg[i+1] == '!':
+			b.WriteString("[^")
+			i++
+		default:
+			b.WriteByte(seg[i])
+		}
+	}
+	return b.String()
+}
+
+// expandBraces expands {a,b} alternation, innermost groups last, into plain glob patterns.
+func expandBraces(pattern string) ([]string, error) {
+	open := -1
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("%w: unmatched '}' in %q", ErrBadGlob, pattern)
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+			prefix, suffix := pattern[:open], pattern[i+1:]
+			var out []string
+			for _, alt := range splitAlternatives(pattern[open+1 : i]) {
+				expanded, err := expandBraces(prefix + alt + suffix)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, expanded...)
+				if len(out) > maxGlobExpansions {
+					return nil, fmt.Errorf("%w: too many alternatives in %q", ErrBadGlob, pattern)
+				}
+			}
+			return out, nil
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("%w: unmatched '{' in %q", ErrBadGlob, pattern)
+	}
+	return []string{pattern}, nil
+}
+
+// splitAlternatives splits the body of a brace group on its top-level commas.
+func splitAlternatives(body string) []string {
+	var alts []string
+	depth, start := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(alts, body[start:])
+}
+
+// GlobToLike converts a glob into a SQL LIKE pattern, escaped with '\', that matches a superset
+// of what MatchGlob accepts on the full path including its leading '/'. It narrows a database
+// query before exact matching.
+func GlobToLike(pattern string) string {
+	pattern = "/" + strings.TrimPrefix(pattern, "/")
+	var b strings.Builder
+	wildcard := func() {
+		if !strings.HasSuffix(b.String(), "%") {
+			b.WriteByte('%')
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				writeLikeLiteral(&b, pattern[i])
+			}
+		case '*':
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			// "**/" may match no directory at all, so the slash is optional too
+			if strings.HasSuffix(pattern[:i+1], "**") && i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+			}
+			wildcard()
+		case '?':
+			b.WriteByte('_')
+		case '[':
+			end := classEnd(pattern, i)
+			if end < 0 {
+				writeLikeLiteral(&b, c)
+				continue
+			}
+			b.WriteByte('_')
+			i = end
+		case '{':
+			end := braceEnd(pattern, i)
+			if end < 0 {
+				writeLikeLiteral(&b, c)
+				continue
+			}
+			wildcard()
+			i = end
+		default:
+			writeLikeLiteral(&b, c)
+		}
+	}
+	return b.String()
+}
+
+func writeLikeLiteral(b *strings.Builder, c byte) {
+	if c == '%' || c == '_' || c == '\\' {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
+
+// classEnd returns the index of the ']' closing the class opened at i, or -1.
+func classEnd(pattern string, i int) int {
+	j := i + 1
+	if j < len(pattern) && (pattern[j] == '!' || pattern[j] == '^') {
+		j++
+	}
+	if j < len(pattern) && pattern[j] == ']' {
+		j++
+	}
+	for ; j < len(pattern); j++ {
+		switch pattern[j] {
+		case '\\':
+			j++
+		case ']':
+			return j
+		}
+	}
+	return -1
+}
+
+// braceEnd returns the index of the '}' closing the group opened at i, or -1.
+func braceEnd(pattern string, i int) int {
+	depth := 0
+	for j := i; j < len(pattern); j++ {
+		switch pattern[j] {
+		case '\\':
+			j++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
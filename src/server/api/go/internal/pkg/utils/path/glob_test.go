@@ -0,0 +1,98 @@
+package path
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{name: "star matches file in root", pattern: "*.py", path: "/main.py", want: true},
+		{name: "star does not cross directories", pattern: "*.py", path: "/src/main.py", want: false},
+		{name: "star within segment", pattern: "src/*.py", path: "/src/main.py", want: true},
+		{name: "double star matches zero directories", pattern: "**/*.py", path: "/main.py", want: true},
+		{name: "double star matches nested directories", pattern: "**/*.py", path: "/a/b/c/main.py", want: true},
+		{name: "double star in the middle", pattern: "src/**/test_*.py", path: "/src/pkg/sub/test_io.py", want: true},
+		{name: "double star in the middle matches zero directories", pattern: "src/**/test_*.py", path: "/src/test_io.py", want: true},
+		{name: "trailing double star matches everything below", pattern: "src/**", path: "/src/a/b.txt", want: true},
+		{name: "trailing double star does not match the directory", pattern: "src/**", path: "/src", want: false},
+		{name: "leading slash is optional", pattern: "/src/main.py", path: "src/main.py", want: true},
+		{name: "question mark matches one character", pattern: "file?.txt", path: "/file1.txt", want: true},
+		{name: "question mark requires a character", pattern: "file?.txt", path: "/file.txt", want: false},
+		{name: "question mark does not match slash", pattern: "a?b", path: "/a/b", want: false},
+		{name: "character class", pattern: "log[0-9].txt", path: "/log7.txt", want: true},
+		{name: "negated class with bang", pattern: "log[!0-9].txt", path: "/log7.txt", want: false},
+		{name: "negated class with caret", pattern: "log[^0-9].txt", path: "/logx.txt", want: true},
+		{name: "alternation", pattern: "*.{go,py}", path: "/main.py", want: true},
+		{name: "alternation no match", pattern: "*.{go,py}", path: "/main.rs", want: false},
+		{name: "alternation across directories", pattern: "{src,docs/api}/*.md", path: "/docs/api/index.md", want: true},
+		{name: "nested alternation", pattern: "*.{t{s,sx},js}", path: "/app.tsx", want: true},
+		{name: "literal percent and underscore", pattern: "100%_done.txt", path: "/100%_done.txt", want: true},
+		{name: "percent is not a wildcard", pattern: "100%.txt", path: "/1000.txt", want: false},
+		{name: "escaped star is literal", pattern: `a\*b`, path: "/a*b", want: true},
+		{name: "escaped star does not match others", pattern: `a\*b`, path: "/axb", want: false},
+		{name: "malformed pattern matches nothing", pattern: "[a-", path: "/a", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		expectErr bool
+	}{
+		{name: "plain", pattern: "**/*.py"},
+		{name: "class and alternation", pattern: "src/{a,b}/[!x]*"},
+		{name: "unclosed class", pattern: "[a-", expectErr: true},
+		{name: "unclosed brace", pattern: "*.{go,py", expectErr: true},
+		{name: "unmatched closing brace", pattern: "*.go}", expectErr: true},
+		{name: "too many alternatives", pattern: strings.Repeat("{a,b,c}", 6), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGlob(tt.pattern)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrBadGlob)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGlobToLike(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "*.py", want: "/%.py"},
+		{pattern: "/src/main.py", want: "/src/main.py"},
+		{pattern: "**/*.txt", want: "/%.txt"},
+		{pattern: "src/**/test_?.py", want: `/src/%test\__.py`},
+		{pattern: "log[0-9].txt", want: "/log_.txt"},
+		{pattern: "*.{go,py}", want: "/%.%"},
+		{pattern: "100%.txt", want: `/100\%.txt`},
+		{pattern: `a\*b`, want: "/a*b"},
+		{pattern: `a\\b`, want: `/a\\b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.want, GlobToLike(tt.pattern))
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Package textedit applies line-oriented edits to text files.
+package textedit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidEdit is returned for malformed operations, such as out-of-range lines or an unparsable diff.
+	ErrInvalidEdit = errors.New("invalid edit")
+	// ErrMismatch is returned when the text does not match what an operation expects to change.
+	ErrMismatch = errors.New("text does not match edit")
+)
+
+// Edit operation types
+const (
+	OpReplace = "replace" // replace OldText with NewText
+	OpInsert  = "insert"  // insert Text before Line
+	OpDelete  = "delete"  // delete lines StartLine..EndLine
+	OpPatch   = "patch"   // apply the unified diff in Diff
+)
+
+// Edit is a single operation. Lines are numbered from 1.
+type Edit struct {
+	Op         string `json:"op" enums:"replace,insert,delete,patch" example:"replace"`
+	OldText    string `json:"old_text,omitempty" example:"TODO"`     // replace: exact text to find
+	NewText    string `json:"new_text,omitempty" example:"DONE"`     // replace: replacement text
+	ReplaceAll bool   `json:"replace_all,omitempty" example:"false"` // replace: replace every occurrence instead of requiring a unique one
+	Line       int    `json:"line,omitempty" example:"3"`            // insert: line to insert before; one past the last line appends
+	Text       string `json:"text,omitempty" example:"new line"`     // insert: text to insert
+	StartLine  int    `json:"start_line,omitempty" example:"3"`      // delete: first line to delete
+	EndLine    int    `json:"end_line,omitempty" example:"5"`        // delete: last line to delete, inclusive
+	Diff       string `json:"diff,omitempty"`                        // patch: unified diff against the text
+}
+
+// Apply runs edits against text in order and returns the result. Either every edit applies or
+// an error is returned.
+func Apply(text string, edits []Edit) (string, error) {
+	for i, e := range edits {
+		var err error
+		text, err = apply(text, e)
+		if err != nil {
+			return "", fmt.Errorf("edit %d (%s): %w", i+1, e.Op, err)
+		}
+	}
+	return text, nil
+}
+
+func apply(text string, e Edit) (string, error) {
+	switch e.Op {
+	case OpReplace:
+		return replace(text, e.OldText, e.NewText, e.ReplaceAll)
+	case OpInsert:
+		return insert(text, e.Line, e.Text)
+	case OpDelete:
+		return deleteLines(text, e.StartLine, e.EndLine)
+	case OpPatch:
+		return patch(text, e.Diff)
+	}
+	return "", fmt.Errorf("%w: unknown op %q", ErrInvalidEdit, e.Op)
+}
+
+func replace(text, oldText, newText string, all bool) (string, error) {
+	if oldText == "" {
+		return "", fmt.Errorf("%w: old_text is required", ErrInvalidEdit)
+	}
+	switch n := strings.Count(text, oldText); {
+	case n == 0:
+		return "", fmt.Errorf("%w: old_text not found", ErrMismatch)
+	case n > 1 && !all:
+		return "", fmt.Errorf("%w: old_text found %d times, add surrounding text to make it unique or set replace_all", ErrMismatch, n)
+	}
+	return strings.ReplaceAll(text, oldText, newText), nil
+}
+
+// document is text split into lines without their terminators.
+type document struct {
+	lines []string
+	eol   bool // whether the last line ends with a newline
+}
+
+func split(text string) document {
+	if text == "" {
+		return document{}
+	}
+	eol := strings.HasSuffix(text, "\n")
+	return document{lines: strings.Split(strings.TrimSuffix(text, "\n"), "\n"), eol: eol}
+}
+
+func (d document) String() string {
+	if len(d.lines) == 0 {
+		return ""
+	}
+	s := strings.Join(d.lines, "\n")
+	if d.eol {
+		s += "\n"
+	}
+	return s
+}
+
+func insert(text string, line int, ins string) (string, error) {
+	if ins == "" {
+		return "", fmt.Errorf("%w: text is required", ErrInvalidEdit)
+	}
+	d := split(text)
+	if line < 1 || line > len(d.lines)+1 {
+		return "", fmt.Errorf("%w: line %d is out of range 1-%d", ErrInvalidEdit, line, len(d.lines)+1)
+	}
+	added := split(ins)
+	if len(d.lines) == 0 {
+		return added.String(), nil
+	}
+	lines := make([]string, 0, len(d.lines)+len(added.lines))
+	lines = append(lines, d.lines[:line-1]...)
+	lines = append(lines, added.lines...)
+	lines = append(lines, d.lines[line-1:]...)
+	d.lines = lines
+	return d.String(), nil
+}
+
+func deleteLines(text string, start, end int) (string, error) {
+	d := split(text)
+	if start < 1 || end < start || end > len(d.lines) {
+		return "", fmt.Errorf("%w: line range %d-%d is out of range 1-%d", ErrInvalidEdit, start, end, len(d.lines))
+	}
+	d.lines = append(d.lines[:start-1:start-1], d.lines[end:]...)
+	return d.String(), nil
+}
+
+// hunk is one "@@ -a,b +c,d @@" section of a unified diff.
+type hunk struct {
+	oldStart int
+	oldLines []string // context and removed lines
+	newLines []string // context and added lines
+	newNoEOL bool     // the new side ends without a newline
+}
+
+func patch(text, diff string) (string, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+	d := split(text)
+	out := make([]string, 0, len(d.lines))
+	pos, offset := 0, 0
+	for i, h := range hunks {
+		at, ok := findHunk(d.lines, h.oldLines, h.oldStart-1+offset, pos)
+		if !ok {
+			return "", fmt.Errorf("%w: hunk %d does not apply at line %d", ErrMismatch, i+1, h.oldStart)
+		}
+		out = append(out, d.lines[pos:at]...)
+		out = append(out, h.newLines...)
+		pos = at + len(h.oldLines)
+		offset = at - (h.oldStart - 1)
+		if pos == len(d.lines) {
+			d.eol = !h.newNoEOL
+		}
+	}
+	d.lines = append(out, d.lines[pos:]...)
+	return d.String(), nil
+}
+
+// findHunk returns where old occurs in lines at or after min, preferring the position closest
+// to want, as patch(1) does when a file has shifted since the diff was made.
+func findHunk(lines, old []string, want, min int) (int, bool) {
+	matches := func(at int) bool {
+		if at < min || at+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	if want < min {
+		want = min
+	}
+	for delta := 0; want-delta >= min || want+delta <= len(lines); delta++ {
+		if matches(want - delta) {
+			return want - delta, true
+		}
+		if matches(want + delta) {
+			return want + delta, true
+		}
+	}
+	return 0, false
+}
+
+func parseUnifiedDiff(diff string) ([]hunk, error) {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	var hunks []hunk
+	for i := 0; i < len(lines); {
+		if !strings.HasPrefix(lines[i], "@@") {
+			// file headers and git extended headers
+			i++
+			continue
+		}
+		oldStart, oldCount, newCount, err := parseHunkHeader(lines[i])
+		if err != nil {
+			return nil, err
+		}
+		h := hunk{oldStart: oldStart}
+		if oldCount == 0 {
+			// a pure insertion names the line after which it goes
+			h.oldStart++
+		}
+		i++
+		last := byte(0)
+		for i < len(lines) && (len(h.oldLines) < oldCount || len(h.newLines) < newCount || strings.HasPrefix(lines[i], `\`)) {
+			l := lines[i]
+			i++
+			if l == "" {
+				// editors often strip the single space of an empty context line
+				l = " "
+			}
+			switch l[0] {
+			case ' ':
+				h.oldLines = append(h.oldLines, l[1:])
+				h.newLines = append(h.newLines, l[1:])
+			case '-':
+				h.oldLines = append(h.oldLines, l[1:])
+			case '+':
+				h.newLines = append(h.newLines, l[1:])
+			case '\\':
+				// "\ No newline at end of file" applies to the line before it
+				if last == ' ' || last == '+' {
+					h.newNoEOL = true
+				}
+				continue
+			default:
+				return nil, fmt.Errorf("%w: unexpected diff line %q", ErrInvalidEdit, l)
+			}
+			last = l[0]
+		}
+		if len(h.oldLines) != oldCount || len(h.newLines) != newCount {
+			return nil, fmt.Errorf("%w: hunk at line %d is truncated", ErrInvalidEdit, oldStart)
+		}
+		hunks = append(hunks, h)
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("%w: diff has no hunks", ErrInvalidEdit)
+	}
+	return hunks, nil
+}
+
+// parseHunkHeader parses "@@ -a[,b] +c[,d] @@", where an omitted count means 1.
+func parseHunkHeader(header string) (oldStart, oldCount, newCount int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("%w: malformed hunk header %q", ErrInvalidEdit, header)
+	}
+	parseRange := func(r string) (int, int, error) {
+		start, count, ok := strings.Cut(r, ",")
+		s, err := strconv.Atoi(start)
+		if err != nil || s < 0 {
+			return 0, 0, fmt.Errorf("%w: malformed hunk header %q", ErrInvalidEdit, header)
+		}
+		if !ok {
+			return s, 1, nil
+		}
+		c, err := strconv.Atoi(count)
+		if err != nil || c < 0 {
+			return 0, 0, fmt.Errorf("%w: malformed hunk header %q", ErrInvalidEdit, header)
+		}
+		return s, c, nil
+	}
+	if oldStart, oldCount, err = parseRange(fields[1][1:]); err != nil {
+		return 0, 0, 0, err
+	}
+	if _, newCount, err = parseRange(fields[2][1:]); err != nil {
+		return 0, 0, 0, err
+	}
+	return oldStart, oldCount, newCount, nil
+}
@@ -0,0 +1,195 @@
+package textedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	const text = "one\ntwo\nthree\n"
+
+	tests := []struct {
+		name    string
+		text    string
+		edits   []Edit
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "replace unique text",
+			text:  text,
+			edits: []Edit{{Op: OpReplace, OldText: "two", NewText: "2"}},
+			want:  "one\n2\nthree\n",
+		},
+		{
+			name:    "replace ambiguous text",
+			text:    "a a",
+			edits:   []Edit{{Op: OpReplace, OldText: "a", NewText: "b"}},
+			wantErr: ErrMismatch,
+		},
+		{
+			name:  "replace all",
+			text:  "a a",
+			edits: []Edit{{Op: OpReplace, OldText: "a", NewText: "b", ReplaceAll: true}},
+			want:  "b b",
+		},
+		{
+			name:    "replace missing text",
+			text:    text,
+			edits:   []Edit{{Op: OpReplace, OldText: "four", NewText: "4"}},
+			wantErr: ErrMismatch,
+		},
+		{
+			name:  "insert before a line",
+			text:  text,
+			edits: []Edit{{Op: OpInsert, Line: 2, Text: "one and a half\n"}},
+			want:  "one\none and a half\ntwo\nthree\n",
+		},
+		{
+			name:  "insert appends after the last line",
+			text:  "one\ntwo",
+			edits: []Edit{{Op: OpInsert, Line: 3, Text: "three"}},
+			want:  "one\ntwo\nthree",
+		},
+		{
+			name:  "insert into empty text",
+			text:  "",
+			edits: []Edit{{Op: OpInsert, Line: 1, Text: "hello\n"}},
+			want:  "hello\n",
+		},
+		{
+			name:    "insert out of range",
+			text:    text,
+			edits:   []Edit{{Op: OpInsert, Line: 5, Text: "x"}},
+			wantErr: ErrInvalidEdit,
+		},
+		{
+			name:  "delete a range",
+			text:  text,
+			edits: []Edit{{Op: OpDelete, StartLine: 1, EndLine: 2}},
+			want:  "three\n",
+		},
+		{
+			name:  "delete everything",
+			text:  text,
+			edits: []Edit{{Op: OpDelete, StartLine: 1, EndLine: 3}},
+			want:  "",
+		},
+		{
+			name:    "delete past the end",
+			text:    text,
+			edits:   []Edit{{Op: OpDelete, StartLine: 2, EndLine: 4}},
+			wantErr: ErrInvalidEdit,
+		},
+		{
+			name:  "edits apply in order",
+			text:  text,
+			edits: []Edit{{Op: OpDelete, StartLine: 1, EndLine: 1}, {Op: OpInsert, Line: 1, Text: "zero"}},
+			want:  "zero\ntwo\nthree\n",
+		},
+		{
+			name:    "unknown op",
+			text:    text,
+			edits:   []Edit{{Op: "rewrite"}},
+			wantErr: ErrInvalidEdit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.text, tt.edits)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApply_Patch(t *testing.T) {
+	const text = "a\nb\nc\nd\ne\nf\ng\n"
+
+	tests := []struct {
+		name    string
+		text    string
+		diff    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "single hunk",
+			text: text,
+			diff: "--- a/file.txt\n+++ b/file.txt\n@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n",
+			want: "a\nb\nC\nd\ne\nf\ng\n",
+		},
+		{
+			name: "two hunks",
+			text: text,
+			diff: "@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -6,2 +6,3 @@\n f\n g\n+h\n",
+			want: "A\nb\nc\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "hunk found at an offset",
+			text: "x\ny\n" + text,
+			diff: "@@ -2,3 +2,2 @@\n b\n-c\n d\n",
+			want: "x\ny\na\nb\nd\ne\nf\ng\n",
+		},
+		{
+			name: "pure insertion",
+			text: text,
+			diff: "@@ -1,0 +2 @@\n+a2\n",
+			want: "a\na2\nb\nc\nd\ne\nf\ng\n",
+		},
+		{
+			name: "stripped empty context line",
+			text: "a\n\nb\n",
+			diff: "@@ -1,3 +1,3 @@\n a\n\n-b\n+B\n",
+			want: "a\n\nB\n",
+		},
+		{
+			name: "no newline at end of file",
+			text: "a\nb\n",
+			diff: "@@ -2 +2 @@\n-b\n+b\n\\ No newline at end of file\n",
+			want: "a\nb",
+		},
+		{
+			name: "patch an empty file",
+			text: "",
+			diff: "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n",
+			want: "hello\nworld\n",
+		},
+		{
+			name:    "context does not match",
+			text:    text,
+			diff:    "@@ -2,2 +2,2 @@\n b\n-x\n+y\n",
+			wantErr: ErrMismatch,
+		},
+		{
+			name:    "no hunks",
+			text:    text,
+			diff:    "--- a/file.txt\n+++ b/file.txt\n",
+			wantErr: ErrInvalidEdit,
+		},
+		{
+			name:    "truncated hunk",
+			text:    text,
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n",
+			wantErr: ErrInvalidEdit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.text, []Edit{{Op: OpPatch, Diff: tt.diff}})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -18,22 +18,26 @@ import (
 )
 
 type RouterDeps struct {
-	Config               *config.Config
-	DB                   *gorm.DB
-	Redis                *redis.Client
-	Log                  *zap.Logger
-	SessionHandler       *handler.SessionHandler
-	DiskHandler          *handler.DiskHandler
-	ArtifactHandler      *handler.ArtifactHandler
-	TaskHandler          *handler.TaskHandler
-	AgentSkillsHandler   *handler.AgentSkillsHandler
-	UserHandler          *handler.UserHandler
-	SandboxHandler       *handler.SandboxHandler
-	LearningSpaceHandler *handler.LearningSpaceHandler
-	SessionEventHandler  *handler.SessionEventHandler
-	ProjectHandler       *handler.ProjectHandler
-	MaterialHandler      *handler.MaterialHandler
-	ProjectAuthOverride  gin.HandlerFunc // If set, used instead of default ProjectAuth for /api/v1
+	Config                 *config.Config
+	DB                     *gorm.DB
+	Redis                  *redis.Client
+	Log                    *zap.Logger
+	SessionHandler         *handler.SessionHandler
+	DiskHandler            *handler.DiskHandler
+	ArtifactHandler        *handler.ArtifactHandler
+	TaskHandler            *handler.TaskHandler
+	AgentSkillsHandler     *handler.AgentSkillsHandler
+	UserHandler            *handler.UserHandler
+	SandboxHandler         *handler.SandboxHandler
+	LearningSpaceHandler   *handler.LearningSpaceHandler
+	SessionEventHandler    *handler.SessionEventHandler
+	SessionBulkHandler     *handler.SessionBulkHandler
+	MessageFeedbackHandler *handler.MessageFeedbackHandler
+	ProjectHandler         *handler.ProjectHandler
+	MaterialHandler        *handler.MaterialHandler
+	BlobHandler            *handler.BlobHandler
+	UploadHandler          *handler.UploadHandler
+	ProjectAuthOverride    gin.HandlerFunc // If set, used instead of default ProjectAuth for /api/v1
 }
 
 func NewRouter(d RouterDeps) *gin.Engine {
@@ -64,6 +68,12 @@ func NewRouter(d RouterDeps) *gin.Engine {
 	// Material endpoint — public, no auth required
 	r.GET("/api/v1/material/:token", d.MaterialHandler.Serve)
 
+	// Signed URLs of the local blob store — authenticated by their signature
+	if d.BlobHandler != nil {
+		r.GET("/api/v1/blob/*key", d.BlobHandler.Get)
+		r.PUT("/api/v1/blob/*key", d.BlobHandler.Put)
+	}
+
 	v1 := r.Group("/api/v1")
 	{
 		projectAuth := d.ProjectAuthOverride
@@ -79,8 +89,14 @@ func NewRouter(d RouterDeps) *gin.Engine {
 		{
 			session.GET("", d.SessionHandler.GetSessions)
 			session.POST("", d.SessionHandler.CreateSession)
+			session.PATCH("/:session_id", d.SessionHandler.UpdateSession)
 			session.DELETE("/:session_id", d.SessionHandler.DeleteSession)
 
+			session.POST("/tags", d.SessionHandler.BulkTagSessions)
+			session.POST("/archive", d.SessionHandler.BulkArchiveSessions)
+			session.POST("/bulk", d.SessionBulkHandler.BulkSessions)
+			session.GET("/bulk/:job_id", d.SessionBulkHandler.GetBulkJob)
+
 			session.PUT("/:session_id/configs", d.SessionHandler.UpdateConfigs)
 			session.PATCH("/:session_id/configs", d.SessionHandler.PatchConfigs)
 			session.GET("/:session_id/configs", d.SessionHandler.GetConfigs)
@@ -88,11 +104,15 @@ func NewRouter(d RouterDeps) *gin.Engine {
 			session.POST("/:session_id/messages", d.SessionHandler.StoreMessage)
 			session.GET("/:session_id/messages", d.SessionHandler.GetMessages)
 			session.PATCH("/:session_id/messages/:message_id/meta", d.SessionHandler.PatchMessageMeta)
+			session.POST("/:session_id/messages/:message_id/feedback", d.MessageFeedbackHandler.SubmitFeedback)
+			session.GET("/:session_id/feedback", d.MessageFeedbackHandler.GetSessionFeedback)
 
 			session.GET("/:session_id/asset/download", d.SessionHandler.DownloadSessionAsset)
+			session.GET("/:session_id/pending_uploads", d.SessionHandler.GetPendingUploads)
 			session.POST("/:session_id/flush", d.SessionHandler.SessionFlush)
 
 			session.GET("/:session_id/token_counts", d.SessionHandler.GetTokenCounts)
+			session.GET("/:session_id/stats", d.SessionHandler.GetSessionStats)
 
 			session.GET("/:session_id/observing_status", d.SessionHandler.GetSessionObservingStatus)
 
@@ -112,15 +132,33 @@ func NewRouter(d RouterDeps) *gin.Engine {
 			disk.GET("", d.DiskHandler.ListDisks)
 			disk.POST("", d.DiskHandler.CreateDisk)
 			disk.DELETE("/:disk_id", d.DiskHandler.DeleteDisk)
+			disk.POST("/clone", d.DiskHandler.CloneDisk)
+			disk.POST("/:disk_id/snapshot", d.DiskHandler.CreateDiskSnapshot)
+			disk.GET("/:disk_id/snapshot", d.DiskHandler.ListDiskSnapshots)
+			disk.DELETE("/:disk_id/snapshot/:snapshot_id", d.DiskHandler.DeleteDiskSnapshot)
+			disk.POST("/:disk_id/restore/:snapshot_id", d.DiskHandler.RestoreDiskSnapshot)
+			disk.GET("/:disk_id/export", d.ArtifactHandler.ExportDisk)
+			disk.POST("/:disk_id/import", d.ArtifactHandler.ImportDisk)
 
 			artifact := disk.Group("/:disk_id/artifact")
 			{
 				artifact.POST("", d.ArtifactHandler.UpsertArtifact)
+				artifact.POST("/upload_url", d.ArtifactHandler.CreateArtifactUploadURL)
+				artifact.POST("/commit", d.ArtifactHandler.CommitArtifact)
 				artifact.GET("", d.ArtifactHandler.GetArtifact)
 				artifact.PUT("", d.ArtifactHandler.UpdateArtifact)
 				artifact.DELETE("", d.ArtifactHandler.DeleteArtifact)
+				artifact.PATCH("/edit", d.ArtifactHandler.EditArtifact)
 				artifact.GET("/download", d.ArtifactHandler.DownloadArtifact)
+				artifact.GET("/versions", d.ArtifactHandler.ListArtifactVersions)
+				artifact.POST("/restore", d.ArtifactHandler.RestoreArtifact)
+				artifact.POST("/move", d.ArtifactHandler.MoveArtifacts)
+				artifact.POST("/copy", d.ArtifactHandler.CopyArtifacts)
+				artifact.POST("/lock", d.ArtifactHandler.LockArtifacts)
+				artifact.POST("/lock/renew", d.ArtifactHandler.RenewArtifactLease)
+				artifact.DELETE("/lock", d.ArtifactHandler.ReleaseArtifactLease)
 				artifact.GET("/ls", d.ArtifactHandler.ListArtifacts)
+				artifact.GET("/du", d.ArtifactHandler.DiskUsage)
 
 				artifact.GET("/grep", d.ArtifactHandler.GrepArtifacts)
 				artifact.GET("/glob", d.ArtifactHandler.GlobArtifacts)
@@ -129,6 +167,15 @@ func NewRouter(d RouterDeps) *gin.Engine {
 			}
 		}
 
+		upload := v1.Group("/upload")
+		{
+			upload.POST("", d.UploadHandler.CreateUpload)
+			upload.GET("/:upload_id", d.UploadHandler.GetUpload)
+			upload.PATCH("/:upload_id", d.UploadHandler.AppendUpload)
+			upload.DELETE("/:upload_id", d.UploadHandler.AbortUpload)
+			upload.POST("/:upload_id/complete", d.UploadHandler.CompleteUpload)
+		}
+
 		agentSkills := v1.Group("/agent_skills")
 		{
 			agentSkills.GET("", d.AgentSkillsHandler.ListAgentSkills)
@@ -161,6 +208,7 @@ func NewRouter(d RouterDeps) *gin.Engine {
 			project.PATCH("/configs", d.ProjectHandler.PatchConfigs)
 			project.POST("/encrypt", d.ProjectHandler.EncryptProject)
 			project.POST("/decrypt", d.ProjectHandler.DecryptProject)
+			project.GET("/feedback", d.MessageFeedbackHandler.GetProjectFeedback)
 		}
 
 		learningSpaces := v1.Group("/learning_spaces")
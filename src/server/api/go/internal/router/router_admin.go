@@ -10,8 +10,10 @@ import (
 // AdminRouterDeps extends RouterDeps with admin-specific handlers.
 type AdminRouterDeps struct {
 	RouterDeps
-	AdminHandler   *handler.AdminHandler
-	MetricsHandler *handler.MetricsHandler
+	AdminHandler         *handler.AdminHandler
+	MetricsHandler       *handler.MetricsHandler
+	AssetGCHandler       *handler.AssetGCHandler
+	AssetRefCheckHandler *handler.AssetRefCheckHandler
 }
 
 // NewAdminRouter creates a Gin engine that includes all base routes
@@ -34,9 +36,11 @@ func NewAdminRouter(d AdminRouterDeps) *gin.Engine {
 		admin.GET("/project/:project_id/usages", d.AdminHandler.AnalyzeProjectUsages)
 		admin.GET("/project/:project_id/statistics", d.AdminHandler.AnalyzeProjectStatistics)
 		admin.GET("/project/:project_id/metrics", d.AdminHandler.AnalyzeProjectMetrics)
+
+		admin.POST("/asset_gc", d.AssetGCHandler.SweepAssets)
 	}
 
-	// Admin project encryption routes - protected by ProjectAuth (Bearer API key)
+	// Admin project routes that need the project's encryption key - protected by ProjectAuth (Bearer API key)
 	adminProject := r.Group("/admin/v1")
 	{
 		adminProject.Use(middleware.ProjectAuth(d.Config, d.DB, d.Redis))
@@ -44,6 +48,8 @@ func NewAdminRouter(d AdminRouterDeps) *gin.Engine {
 		adminProject.POST("/project/encrypt", d.AdminHandler.EncryptProject)
 		adminProject.POST("/project/decrypt", d.AdminHandler.DecryptProject)
 		adminProject.PUT("/project/secret_key", d.AdminHandler.RotateProjectSecretKey)
+
+		adminProject.POST("/project/asset_refs/check", d.AssetRefCheckHandler.CheckAssetRefs)
 	}
 
 	// Metrics routes - protected by API bearer token
@@ -0,0 +1,33 @@
+"""
+Decompression of objects written by the Go API.
+
+The Go API may compress text content before encrypting it. The algorithm is recorded in
+the object's "compression" metadata (absent or "none" for uncompressed objects) and the
+plaintext size in "plain-size". Decompress after decrypting.
+"""
+
+import gzip
+from typing import Dict
+
+COMPRESSION_GZIP = "gzip"
+COMPRESSION_ZSTD = "zstd"
+
+
+def decompress_object(content: bytes, metadata: Dict[str, str]) -> bytes:
+    """Decompress content according to its object metadata; uncompressed content is
+    returned unchanged."""
+    algo = metadata.get("compression", "")
+    if algo in ("", "none"):
+        return content
+    if algo == COMPRESSION_GZIP:
+        return gzip.decompress(content)
+    if algo == COMPRESSION_ZSTD:
+        try:
+            import zstandard
+        except ImportError as e:
+            raise RuntimeError(
+                "object is zstd-compressed but the zstandard package is not installed; "
+                "install it or set BLOB_COMPRESSION=gzip in the API"
+            ) from e
+        return zstandard.ZstdDecompressor().decompressobj().decompress(content)
+    raise ValueError(f"unknown compression algorithm in object metadata: {algo}")
@@ -4,6 +4,12 @@ Envelope encryption module compatible with the Go API implementation.
 Uses AES-256-GCM for encryption.
 All wrapped DEK and ciphertext formats are: nonce (12 bytes) + ciphertext.
 
+Objects written by the Go API may instead use the chunked format ("AES-256-GCM-CHUNKED"):
+the plaintext is split into chunks of enc-chunk-size bytes, each sealed on its own and
+stored as ciphertext + tag with nothing in between. A chunk's nonce is the 7-byte
+enc-nonce-prefix, the chunk index as 4 big-endian bytes, and a byte set to 1 for the last
+chunk only.
+
 The caller provides a pre-derived user KEK (passed via MQ from the Go API).
 """
 
@@ -15,6 +21,10 @@ from cryptography.hazmat.primitives.ciphers.aead import AESGCM
 
 KEY_SIZE = 32  # AES-256
 NONCE_SIZE = 12  # AES-GCM nonce
+TAG_SIZE = 16  # AES-GCM tag
+
+ALGO_GCM = "AES-256-GCM"
+ALGO_GCM_CHUNKED = "AES-256-GCM-CHUNKED"
 
 
 def generate_dek() -> bytes:
@@ -68,7 +78,7 @@ def encrypt_data(user_kek: bytes, plaintext: bytes) -> Tuple[bytes, Dict[str, st
     ciphertext = encrypt(dek, plaintext)
     user_wrapped = wrap_dek(user_kek, dek)
     meta = {
-        "enc-algo": "AES-256-GCM",
+        "enc-algo": ALGO_GCM,
         "enc-dek-user": base64.b64encode(user_wrapped).decode(),
     }
     return ciphertext, meta
@@ -84,16 +94,46 @@ def decrypt_data(user_kek: bytes, ciphertext: bytes, enc_meta: Dict[str, str]) -
         raise ValueError("enc-dek-user missing from encryption metadata")
     wrapped = base64.b64decode(wrapped_b64)
     dek = unwrap_dek(user_kek, wrapped)
+    if enc_meta.get("enc-algo") == ALGO_GCM_CHUNKED:
+        return decrypt_chunked(dek, ciphertext, enc_meta)
     return decrypt(dek, ciphertext)
 
 
+def decrypt_chunked(dek: bytes, ciphertext: bytes, enc_meta: Dict[str, str]) -> bytes:
+    """Decrypt ciphertext in the chunked format."""
+    chunk_size = int(enc_meta.get("enc-chunk-size", "0"))
+    if chunk_size <= 0:
+        raise ValueError("invalid enc-chunk-size in encryption metadata")
+    prefix = base64.b64decode(enc_meta.get("enc-nonce-prefix", ""))
+    if len(prefix) != NONCE_SIZE - 5:
+        raise ValueError("invalid enc-nonce-prefix in encryption metadata")
+
+    segment = chunk_size + TAG_SIZE
+    chunks = max(1, -(-len(ciphertext) // segment))
+    if len(ciphertext) - (chunks - 1) * segment < TAG_SIZE:
+        raise ValueError("chunked ciphertext is truncated")
+
+    aesgcm = AESGCM(dek)
+    out = bytearray()
+    for index in range(chunks):
+        last = index == chunks - 1
+        nonce = prefix + index.to_bytes(4, "big") + (b"\x01" if last else b"\x00")
+        seg = ciphertext[index * segment : (index + 1) * segment]
+        out += aesgcm.decrypt(nonce, seg, None)
+    return bytes(out)
+
+
 def metadata_from_map(metadata: Dict[str, str]) -> Optional[Dict[str, str]]:
     """Extract encryption metadata from S3 object metadata.
     Returns None if the object is not encrypted (no "enc-algo" key)."""
     algo = metadata.get("enc-algo", "")
     if not algo:
         return None
-    return {
+    meta = {
         "enc-algo": algo,
         "enc-dek-user": metadata.get("enc-dek-user", ""),
     }
+    if algo == ALGO_GCM_CHUNKED:
+        meta["enc-chunk-size"] = metadata.get("enc-chunk-size", "")
+        meta["enc-nonce-prefix"] = metadata.get("enc-nonce-prefix", "")
+    return meta
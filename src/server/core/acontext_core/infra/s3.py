@@ -12,6 +12,7 @@ from botocore.exceptions import ClientError, NoCredentialsError
 from ..env import LOG as logger
 from ..env import DEFAULT_CORE_CONFIG
 from .crypto import metadata_from_map, encrypt_data, decrypt_data
+from .compression import decompress_object
 
 
 def _handle_s3_client_error(
@@ -191,7 +192,8 @@ class S3Client:
     ) -> bytes:
         """
         Download S3 object content as bytes.
-        Auto-decrypts if the object has encryption metadata and user_kek is provided.
+        Auto-decrypts if the object has encryption metadata and user_kek is provided,
+        then decompresses it if the Go API stored it compressed.
 
         Args:
             key: The S3 object key
@@ -222,6 +224,7 @@ class S3Client:
                             f"Encrypted object found but no user_kek provided: {key}"
                         )
                     content = decrypt_data(user_kek, content, enc_meta)
+                content = decompress_object(content, s3_metadata)
 
                 logger.debug(
                     f"Downloaded object - bucket: {bucket_name}, key: {key}, size: {len(content)} bytes"
@@ -5,7 +5,7 @@ import struct
 from datetime import datetime, timezone
 from typing import List, Optional
 
-from sqlalchemy import select, func, or_
+from sqlalchemy import select, func
 from sqlalchemy.ext.asyncio import AsyncSession
 from sqlalchemy.dialects.postgresql import insert
 
@@ -240,20 +240,15 @@ async def grep_artifacts(
 
     Mirrors the API's GrepArtifacts implementation:
     - Uses PostgreSQL regex operator ``~*`` (case-insensitive, default) or ``~`` (case-sensitive)
-    - Filters by text-searchable MIME types (text/*, application/json, application/x-*)
-    - Requires asset_meta->>'content' IS NOT NULL
+    - Searches every artifact with stored text (asset_meta->>'content' is set and
+      non-empty), which covers text files and the extracted text of documents
     """
     content_col = Artifact.asset_meta["content"].astext  # type: ignore[index]
-    mime_col = Artifact.asset_meta["mime"].astext  # type: ignore[index]
 
     stmt = select(Artifact).where(
         Artifact.disk_id == disk_id,
         content_col.isnot(None),
-        or_(
-            mime_col.like("text/%"),
-            mime_col == "application/json",
-            mime_col.like("application/x-%"),
-        ),
+        content_col != "",
     )
     if case_sensitive:
         stmt = stmt.where(content_col.op("~")(query))